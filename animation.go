@@ -0,0 +1,696 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/lxn/win"
+)
+
+// animationInterval is the time between two frames of running animations.
+const animationInterval = 15 * time.Millisecond
+
+// EasingCurve maps the linear progress t of an animation, from 0 to 1, to the
+// progress of the animated value. Curves may overshoot, i.e. return values
+// outside of 0 and 1.
+type EasingCurve func(t float64) float64
+
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+func EaseOutQuad(t float64) float64 {
+	return t * (2 - t)
+}
+
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+
+	return -1 + (4-2*t)*t
+}
+
+func EaseInCubic(t float64) float64 {
+	return t * t * t
+}
+
+func EaseOutCubic(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+func EaseInOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// EaseOutBack overshoots the target a little before settling on it.
+func EaseOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+
+	t--
+	return 1 + c3*t*t*t + c1*t*t
+}
+
+// EaseOutBounce bounces off the target like a dropped ball.
+func EaseOutBounce(t float64) float64 {
+	const n1 = 7.5625
+	const d1 = 2.75
+
+	switch {
+	case t < 1/d1:
+		return n1 * t * t
+
+	case t < 2/d1:
+		t -= 1.5 / d1
+		return n1*t*t + 0.75
+
+	case t < 2.5/d1:
+		t -= 2.25 / d1
+		return n1*t*t + 0.9375
+
+	default:
+		t -= 2.625 / d1
+		return n1*t*t + 0.984375
+	}
+}
+
+// Animation is something that changes over time, driven by a timer on the UI
+// thread. Animations must be started and stopped from the UI thread.
+type Animation interface {
+	// Duration returns the total duration of the Animation.
+	Duration() time.Duration
+
+	// Start starts the Animation from the beginning. A running Animation is
+	// restarted.
+	Start() error
+
+	// Stop stops the Animation where it is, without publishing Finished.
+	Stop()
+
+	// Running returns if the Animation has been started and has neither
+	// finished nor been stopped yet.
+	Running() bool
+
+	// Finished returns the event that is published when the Animation
+	// reaches its end.
+	Finished() *Event
+
+	asAnimationBase() *animationBase
+}
+
+// animator is implemented by the concrete animations.
+type animator interface {
+	Animation
+
+	// begin prepares the animation for a run, e.g. by capturing start values.
+	begin() error
+
+	// update applies the state at elapsed time since begin.
+	update(elapsed time.Duration) error
+}
+
+type animationBase struct {
+	animator          animator
+	driver            *animationDriver // The driver of the UI thread the animation runs on, while it runs.
+	running           bool
+	startTime         time.Time
+	finishedPublisher EventPublisher
+}
+
+func (ab *animationBase) asAnimationBase() *animationBase {
+	return ab
+}
+
+// Start starts the animation from the beginning. It must be called on a UI
+// thread, which is the one the animation runs on.
+func (ab *animationBase) Start() error {
+	if ab.running {
+		ab.Stop()
+	}
+
+	group := wgm.Group(win.GetCurrentThreadId())
+	if group == nil {
+		return newError("animations must be started on a thread with windows")
+	}
+
+	if err := ab.animator.begin(); err != nil {
+		return err
+	}
+
+	if err := ab.animator.update(0); err != nil {
+		return err
+	}
+
+	ab.startTime = time.Now()
+	ab.running = true
+
+	return group.animationDriver().add(ab)
+}
+
+// Stop stops the animation where it is.
+func (ab *animationBase) Stop() {
+	if !ab.running {
+		return
+	}
+
+	ab.running = false
+
+	if ab.driver != nil {
+		ab.driver.remove(ab)
+	}
+}
+
+// Running returns if the animation is running.
+func (ab *animationBase) Running() bool {
+	return ab.running
+}
+
+// Finished returns the event that is published when the animation reaches
+// its end.
+func (ab *animationBase) Finished() *Event {
+	return ab.finishedPublisher.Event()
+}
+
+// tick advances a running top-level animation to now.
+func (ab *animationBase) tick(now time.Time) {
+	elapsed := now.Sub(ab.startTime)
+	duration := ab.animator.Duration()
+
+	if elapsed < duration {
+		if err := ab.animator.update(elapsed); err != nil {
+			ab.Stop()
+
+			log.Print("walk - animationBase.tick - Error: ", wrapError(err))
+		}
+
+		return
+	}
+
+	// Handlers of Finished may start the animation again, so it must not be
+	// running anymore when they are called.
+	ab.Stop()
+
+	if err := ab.animator.update(duration); err != nil {
+		log.Print("walk - animationBase.tick - Error: ", wrapError(err))
+	}
+
+	ab.finishedPublisher.Publish()
+}
+
+// animationDriver runs a thread timer while any animations of its UI thread
+// are running. Each WindowGroup has its own.
+type animationDriver struct {
+	running []*animationBase
+	timerID uintptr
+}
+
+var animationTimerProcPtr uintptr
+
+func init() {
+	AppendToWalkInit(func() {
+		animationTimerProcPtr = syscall.NewCallback(animationTimerProc)
+	})
+}
+
+// animationTimerProc is called on the thread that set the timer, so the
+// current window group is the one of the driver.
+func animationTimerProc(hwnd win.HWND, msg uint32, idEvent uintptr, dwTime uint32) uintptr {
+	if group := wgm.Group(win.GetCurrentThreadId()); group != nil && group.animations != nil {
+		group.animations.tick()
+	} else {
+		win.KillTimer(0, idEvent)
+	}
+
+	return 0
+}
+
+func (ad *animationDriver) add(ab *animationBase) error {
+	ad.running = append(ad.running, ab)
+	ab.driver = ad
+
+	if ad.timerID == 0 {
+		if ad.timerID = win.SetTimer(0, 0, uint32(animationInterval/time.Millisecond), animationTimerProcPtr); ad.timerID == 0 {
+			ad.remove(ab)
+			ab.running = false
+
+			return lastError("SetTimer")
+		}
+	}
+
+	return nil
+}
+
+func (ad *animationDriver) remove(ab *animationBase) {
+	ab.driver = nil
+
+	for i, running := range ad.running {
+		if running == ab {
+			ad.running = append(ad.running[:i], ad.running[i+1:]...)
+			break
+		}
+	}
+
+	ad.stopTimerIfIdle()
+}
+
+func (ad *animationDriver) stopTimerIfIdle() {
+	if len(ad.running) > 0 || ad.timerID == 0 {
+		return
+	}
+
+	win.KillTimer(0, ad.timerID)
+	ad.timerID = 0
+}
+
+// stopAll stops all running animations, e.g. because their UI thread has no
+// windows anymore.
+func (ad *animationDriver) stopAll() {
+	for _, ab := range append([]*animationBase(nil), ad.running...) {
+		ab.Stop()
+	}
+
+	ad.running = nil
+	ad.stopTimerIfIdle()
+}
+
+func (ad *animationDriver) tick() {
+	now := time.Now()
+
+	// Animations may be started or stopped while we are ticking, so we work on
+	// a copy.
+	running := append([]*animationBase(nil), ad.running...)
+
+	for _, ab := range running {
+		if ab.running {
+			ab.tick(now)
+		}
+	}
+}
+
+// PropertyAnimation animates the value of a Property from a start value to
+// an end value.
+//
+// Supported value types are the integer and floating point types, Color,
+// Point, Size, Rectangle and Margins.
+type PropertyAnimation struct {
+	animationBase
+	property   Property
+	from       interface{}
+	to         interface{}
+	startValue interface{}
+	endValue   interface{}
+	duration   time.Duration
+	easing     EasingCurve
+}
+
+// NewPropertyAnimation returns a *PropertyAnimation that animates property
+// from its value at start to the value to within duration.
+func NewPropertyAnimation(property Property, to interface{}, duration time.Duration) *PropertyAnimation {
+	pa := &PropertyAnimation{
+		property: property,
+		to:       to,
+		duration: duration,
+		easing:   EaseInOutQuad,
+	}
+
+	pa.animator = pa
+
+	return pa
+}
+
+// Property returns the animated Property.
+func (pa *PropertyAnimation) Property() Property {
+	return pa.property
+}
+
+// From returns the start value, or nil if the animation starts at the value
+// the Property has when the animation starts.
+func (pa *PropertyAnimation) From() interface{} {
+	return pa.from
+}
+
+// SetFrom sets the start value. Pass nil to start at the value the Property
+// has when the animation starts.
+func (pa *PropertyAnimation) SetFrom(from interface{}) {
+	pa.from = from
+}
+
+// To returns the end value.
+func (pa *PropertyAnimation) To() interface{} {
+	return pa.to
+}
+
+// SetTo sets the end value.
+func (pa *PropertyAnimation) SetTo(to interface{}) {
+	pa.to = to
+}
+
+// Duration returns the duration of the animation.
+func (pa *PropertyAnimation) Duration() time.Duration {
+	return pa.duration
+}
+
+// SetDuration sets the duration of the animation.
+func (pa *PropertyAnimation) SetDuration(duration time.Duration) {
+	pa.duration = duration
+}
+
+// Easing returns the EasingCurve of the animation.
+//
+// By default this is EaseInOutQuad.
+func (pa *PropertyAnimation) Easing() EasingCurve {
+	return pa.easing
+}
+
+// SetEasing sets the EasingCurve of the animation. Pass nil for EaseLinear.
+func (pa *PropertyAnimation) SetEasing(easing EasingCurve) {
+	pa.easing = easing
+}
+
+func (pa *PropertyAnimation) begin() error {
+	from := pa.from
+	if from == nil {
+		from = pa.property.Get()
+	}
+
+	to, err := convertAnimationValue(pa.to, from)
+	if err != nil {
+		return err
+	}
+
+	if _, err := interpolate(from, to, 0); err != nil {
+		return err
+	}
+
+	pa.startValue, pa.endValue = from, to
+
+	return nil
+}
+
+func (pa *PropertyAnimation) update(elapsed time.Duration) error {
+	if elapsed >= pa.duration {
+		return pa.property.Set(pa.endValue)
+	}
+
+	t := float64(elapsed) / float64(pa.duration)
+	if pa.easing != nil {
+		t = pa.easing(t)
+	}
+
+	value, err := interpolate(pa.startValue, pa.endValue, t)
+	if err != nil {
+		return err
+	}
+
+	return pa.property.Set(value)
+}
+
+// convertAnimationValue converts value to the type of like, if both are
+// numbers.
+func convertAnimationValue(value, like interface{}) (interface{}, error) {
+	v, l := reflect.ValueOf(value), reflect.ValueOf(like)
+	if !v.IsValid() || !l.IsValid() {
+		return nil, newError("cannot animate from or to nil")
+	}
+
+	if v.Type() == l.Type() {
+		return value, nil
+	}
+
+	if isNumberKind(v.Kind()) && isNumberKind(l.Kind()) && l.Type() != reflect.TypeOf(Color(0)) {
+		return v.Convert(l.Type()).Interface(), nil
+	}
+
+	return nil, newError(fmt.Sprintf("cannot animate from %T to %T", like, value))
+}
+
+func lerpInt(from, to int, t float64) int {
+	return int(math.Round(float64(from) + float64(to-from)*t))
+}
+
+func lerpByte(from, to byte, t float64) byte {
+	return byte(math.Max(0, math.Min(255, math.Round(float64(from)+(float64(to)-float64(from))*t))))
+}
+
+// interpolate returns the value at progress t between from and to, which
+// must be of the same type.
+func interpolate(from, to interface{}, t float64) (interface{}, error) {
+	switch f := from.(type) {
+	case Color:
+		to := to.(Color)
+		return RGB(lerpByte(f.R(), to.R(), t), lerpByte(f.G(), to.G(), t), lerpByte(f.B(), to.B(), t)), nil
+
+	case Point:
+		to := to.(Point)
+		return Point{lerpInt(f.X, to.X, t), lerpInt(f.Y, to.Y, t)}, nil
+
+	case Size:
+		to := to.(Size)
+		return Size{lerpInt(f.Width, to.Width, t), lerpInt(f.Height, to.Height, t)}, nil
+
+	case Rectangle:
+		to := to.(Rectangle)
+		return Rectangle{
+			lerpInt(f.X, to.X, t),
+			lerpInt(f.Y, to.Y, t),
+			lerpInt(f.Width, to.Width, t),
+			lerpInt(f.Height, to.Height, t),
+		}, nil
+
+	case Margins:
+		to := to.(Margins)
+		return Margins{
+			lerpInt(f.HNear, to.HNear, t),
+			lerpInt(f.VNear, to.VNear, t),
+			lerpInt(f.HFar, to.HFar, t),
+			lerpInt(f.VFar, to.VFar, t),
+		}, nil
+	}
+
+	fv, tv := reflect.ValueOf(from), reflect.ValueOf(to)
+
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v := math.Round(float64(fv.Int()) + float64(tv.Int()-fv.Int())*t)
+		return reflect.ValueOf(int64(v)).Convert(fv.Type()).Interface(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v := math.Round(float64(fv.Uint()) + (float64(tv.Uint())-float64(fv.Uint()))*t)
+		return reflect.ValueOf(uint64(math.Max(0, v))).Convert(fv.Type()).Interface(), nil
+
+	case reflect.Float32, reflect.Float64:
+		v := fv.Float() + (tv.Float()-fv.Float())*t
+		return reflect.ValueOf(v).Convert(fv.Type()).Interface(), nil
+	}
+
+	return nil, newError(fmt.Sprintf("cannot animate values of type %T", from))
+}
+
+// PauseAnimation does nothing for a while. Use it to add delays to a
+// sequential AnimationGroup.
+type PauseAnimation struct {
+	animationBase
+	duration time.Duration
+}
+
+// NewPauseAnimation returns a *PauseAnimation of duration.
+func NewPauseAnimation(duration time.Duration) *PauseAnimation {
+	pa := &PauseAnimation{duration: duration}
+
+	pa.animator = pa
+
+	return pa
+}
+
+func (pa *PauseAnimation) Duration() time.Duration {
+	return pa.duration
+}
+
+func (pa *PauseAnimation) begin() error {
+	return nil
+}
+
+func (pa *PauseAnimation) update(elapsed time.Duration) error {
+	return nil
+}
+
+// AnimationGroup runs several animations one after another or all at once.
+//
+// Animations in a group must not be started on their own while the group is
+// running. Their Finished events are published as they reach their ends.
+type AnimationGroup struct {
+	animationBase
+	sequential bool
+	animations []animator
+	begun      []bool
+	done       []bool
+}
+
+// NewSequentialAnimationGroup returns an *AnimationGroup that runs animations
+// one after another.
+func NewSequentialAnimationGroup(animations ...Animation) *AnimationGroup {
+	return newAnimationGroup(true, animations)
+}
+
+// NewParallelAnimationGroup returns an *AnimationGroup that runs animations
+// all at once.
+func NewParallelAnimationGroup(animations ...Animation) *AnimationGroup {
+	return newAnimationGroup(false, animations)
+}
+
+func newAnimationGroup(sequential bool, animations []Animation) *AnimationGroup {
+	ag := &AnimationGroup{sequential: sequential}
+
+	ag.animator = ag
+
+	for _, a := range animations {
+		ag.Add(a)
+	}
+
+	return ag
+}
+
+// Sequential returns if the animations of the group run one after another.
+func (ag *AnimationGroup) Sequential() bool {
+	return ag.sequential
+}
+
+// Add appends an Animation to the group.
+func (ag *AnimationGroup) Add(animation Animation) {
+	ag.animations = append(ag.animations, animation.asAnimationBase().animator)
+}
+
+// Len returns the number of animations in the group.
+func (ag *AnimationGroup) Len() int {
+	return len(ag.animations)
+}
+
+// At returns the Animation at index.
+func (ag *AnimationGroup) At(index int) Animation {
+	return ag.animations[index]
+}
+
+// Duration returns the sum of the durations of the animations of a sequential
+// group and the longest one of a parallel group.
+func (ag *AnimationGroup) Duration() time.Duration {
+	var d time.Duration
+
+	for _, a := range ag.animations {
+		if ag.sequential {
+			d += a.Duration()
+		} else if ad := a.Duration(); ad > d {
+			d = ad
+		}
+	}
+
+	return d
+}
+
+func (ag *AnimationGroup) begin() error {
+	ag.begun = make([]bool, len(ag.animations))
+	ag.done = make([]bool, len(ag.animations))
+
+	return nil
+}
+
+func (ag *AnimationGroup) update(elapsed time.Duration) error {
+	var offset time.Duration
+
+	for i, a := range ag.animations {
+		if ag.done[i] {
+			if ag.sequential {
+				offset += a.Duration()
+			}
+			continue
+		}
+
+		local := elapsed - offset
+		if local < 0 {
+			break
+		}
+
+		if !ag.begun[i] {
+			if err := a.begin(); err != nil {
+				return err
+			}
+			ag.begun[i] = true
+		}
+
+		d := a.Duration()
+		if local >= d {
+			local = d
+		}
+
+		if err := a.update(local); err != nil {
+			return err
+		}
+
+		if local == d {
+			ag.done[i] = true
+			a.asAnimationBase().finishedPublisher.Publish()
+		} else if ag.sequential {
+			break
+		}
+
+		if ag.sequential {
+			offset += d
+		}
+	}
+
+	return nil
+}
+
+// animateGeometry moves the widget to bounds, in native pixels, within
+// duration.
+func (wb *WidgetBase) animateGeometry(bounds Rectangle, duration time.Duration) {
+	if wb.geometryAnimation == nil {
+		widget := wb.window.(Widget)
+
+		wb.geometryAnimation = NewPropertyAnimation(NewProperty(
+			func() interface{} {
+				return widget.BoundsPixels()
+			},
+			func(v interface{}) error {
+				if err := widget.SetBoundsPixels(v.(Rectangle)); err != nil {
+					return err
+				}
+
+				if widget.GraphicsEffects().Len() > 0 {
+					wb.invalidateBorderInParent()
+				}
+
+				return nil
+			},
+			nil), bounds, duration)
+		wb.geometryAnimation.SetEasing(EaseOutCubic)
+	}
+
+	wb.geometryAnimation.SetTo(bounds)
+	wb.geometryAnimation.SetDuration(duration)
+
+	if err := wb.geometryAnimation.Start(); err != nil {
+		widget := wb.window.(Widget)
+		widget.SetBoundsPixels(bounds)
+	}
+}
@@ -9,6 +9,7 @@ package walk
 import (
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lxn/win"
@@ -41,6 +42,10 @@ type Application struct {
 	exiting            bool
 	exitCode           int
 	panickingPublisher ErrorEventPublisher
+
+	theme                 atomic.Value // *Theme, read without locking by every font lookup.
+	followSystemTheme     bool
+	themeChangedPublisher EventPublisher
 }
 
 var appSingleton *Application = new(Application)
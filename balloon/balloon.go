@@ -0,0 +1,154 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package balloon implements the queue behind the message balloons of a
+// notification area icon.
+//
+// The notification area shows one balloon per icon at a time and replaces it
+// if another one is requested meanwhile. A Queue holds the messages back until
+// the current balloon is gone. It does not depend on walk, so the logic can be
+// tested on any platform. walk.NotifyIcon feeds it the notifications it gets
+// from the shell.
+package balloon
+
+// ID identifies a message in a Queue.
+type ID uint64
+
+// State is the state of the balloon of a Queue.
+type State int
+
+const (
+	// Idle means no balloon is shown or requested.
+	Idle State = iota
+
+	// Requested means the current message was handed to the shell, but it
+	// did not report the balloon as shown yet.
+	Requested
+
+	// Showing means the balloon of the current message is visible.
+	Showing
+)
+
+func (s State) String() string {
+	switch s {
+	case Idle:
+		return "Idle"
+
+	case Requested:
+		return "Requested"
+
+	case Showing:
+		return "Showing"
+	}
+
+	return "State(?)"
+}
+
+// Queue decides which message is shown in the balloon and which ones have to
+// wait. The zero value is an empty, idle Queue.
+type Queue struct {
+	state   State
+	current ID
+	waiting []ID
+	nextID  ID
+}
+
+// Push adds a message to the end of the queue and returns its ID. Call Next
+// to find out if it can be shown right away.
+func (q *Queue) Push() ID {
+	q.nextID++
+
+	q.waiting = append(q.waiting, q.nextID)
+
+	return q.nextID
+}
+
+// Next makes the first waiting message the current one, if the Queue is idle.
+// ok is true if the caller should now show the message with id.
+func (q *Queue) Next() (id ID, ok bool) {
+	if q.state != Idle || len(q.waiting) == 0 {
+		return 0, false
+	}
+
+	q.current = q.waiting[0]
+	q.waiting = q.waiting[1:]
+	q.state = Requested
+
+	return q.current, true
+}
+
+// Shown records that the shell reports the balloon as visible. It returns
+// false if no message was requested.
+func (q *Queue) Shown() bool {
+	if q.state != Requested {
+		return false
+	}
+
+	q.state = Showing
+
+	return true
+}
+
+// Close records that the balloon of the current message is gone, whatever
+// the reason, and returns its ID. ok is false if there was no current message.
+func (q *Queue) Close() (id ID, ok bool) {
+	if q.state == Idle {
+		return 0, false
+	}
+
+	id = q.current
+
+	q.current = 0
+	q.state = Idle
+
+	return id, true
+}
+
+// Reset puts the current message back to the front of the queue, so Next
+// returns it again. This is for when the shell lost the balloon, e.g. because
+// it restarted.
+func (q *Queue) Reset() {
+	if q.state == Idle {
+		return
+	}
+
+	q.waiting = append([]ID{q.current}, q.waiting...)
+
+	q.current = 0
+	q.state = Idle
+}
+
+// Remove removes a waiting message. It returns false if no message with id
+// is waiting.
+func (q *Queue) Remove(id ID) bool {
+	for i, w := range q.waiting {
+		if w == id {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Clear removes all waiting messages. The current one is not affected.
+func (q *Queue) Clear() {
+	q.waiting = nil
+}
+
+// State returns the state of the balloon.
+func (q *Queue) State() State {
+	return q.state
+}
+
+// Current returns the ID of the message that is requested or showing. ok is
+// false if the Queue is idle.
+func (q *Queue) Current() (id ID, ok bool) {
+	return q.current, q.state != Idle
+}
+
+// Waiting returns the IDs of the waiting messages, next first.
+func (q *Queue) Waiting() []ID {
+	return append([]ID(nil), q.waiting...)
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package balloon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func checkState(t *testing.T, q *Queue, state State, current ID, waiting ...ID) {
+	t.Helper()
+
+	if q.State() != state {
+		t.Errorf("got state %v, want %v", q.State(), state)
+	}
+
+	if id, ok := q.Current(); id != current || ok != (state != Idle) {
+		t.Errorf("got current %d, %t, want %d", id, ok, current)
+	}
+
+	if got := q.Waiting(); len(got) != 0 || len(waiting) != 0 {
+		if !reflect.DeepEqual(got, waiting) {
+			t.Errorf("got waiting %v, want %v", got, waiting)
+		}
+	}
+}
+
+func TestQueueOrder(t *testing.T) {
+	var q Queue
+	checkState(t, &q, Idle, 0)
+
+	if _, ok := q.Next(); ok {
+		t.Error("empty queue returned a message")
+	}
+
+	a, b, c := q.Push(), q.Push(), q.Push()
+	checkState(t, &q, Idle, 0, a, b, c)
+
+	for _, want := range []ID{a, b, c} {
+		id, ok := q.Next()
+		if !ok || id != want {
+			t.Fatalf("Next: got %d, %t, want %d", id, ok, want)
+		}
+
+		// Only one message is handed out at a time.
+		if _, ok := q.Next(); ok {
+			t.Error("Next handed out a second message")
+		}
+
+		if id, ok := q.Close(); !ok || id != want {
+			t.Errorf("Close: got %d, %t, want %d", id, ok, want)
+		}
+	}
+
+	checkState(t, &q, Idle, 0)
+}
+
+func TestQueueTransitions(t *testing.T) {
+	var q Queue
+
+	if q.Shown() {
+		t.Error("Shown succeeded while idle")
+	}
+	if _, ok := q.Close(); ok {
+		t.Error("Close succeeded while idle")
+	}
+
+	a, b := q.Push(), q.Push()
+	q.Next()
+	checkState(t, &q, Requested, a, b)
+
+	if !q.Shown() {
+		t.Error("Shown failed while requested")
+	}
+	checkState(t, &q, Showing, a, b)
+
+	if q.Shown() {
+		t.Error("Shown succeeded twice")
+	}
+
+	// The balloon timed out or was clicked or closed by the user; the shell
+	// reports all of them the same way.
+	if id, ok := q.Close(); !ok || id != a {
+		t.Errorf("Close while showing: got %d, %t, want %d", id, ok, a)
+	}
+	checkState(t, &q, Idle, 0, b)
+
+	// The shell may drop a requested balloon without ever showing it.
+	q.Next()
+	if id, ok := q.Close(); !ok || id != b {
+		t.Errorf("Close while requested: got %d, %t, want %d", id, ok, b)
+	}
+	checkState(t, &q, Idle, 0)
+}
+
+func TestQueueReset(t *testing.T) {
+	var q Queue
+
+	// Resetting an idle queue does nothing.
+	a, b := q.Push(), q.Push()
+	q.Reset()
+	checkState(t, &q, Idle, 0, a, b)
+
+	// After the taskbar was recreated, the icon is added again and the
+	// current message is shown again before the waiting ones.
+	q.Next()
+	q.Shown()
+	q.Reset()
+	checkState(t, &q, Idle, 0, a, b)
+
+	if id, ok := q.Next(); !ok || id != a {
+		t.Errorf("Next after Reset: got %d, %t, want %d", id, ok, a)
+	}
+	checkState(t, &q, Requested, a, b)
+}
+
+func TestQueueRemoveAndClear(t *testing.T) {
+	var q Queue
+
+	a, b, c := q.Push(), q.Push(), q.Push()
+	q.Next()
+
+	if q.Remove(a) {
+		t.Error("removed the current message")
+	}
+	if !q.Remove(c) {
+		t.Error("removing a waiting message failed")
+	}
+	checkState(t, &q, Requested, a, b)
+
+	q.Push()
+	q.Clear()
+	checkState(t, &q, Requested, a)
+
+	if d := q.Push(); d <= c {
+		t.Errorf("got reused ID %d", d)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	for state, want := range map[State]string{Idle: "Idle", Requested: "Requested", Showing: "Showing", 42: "State(?)"} {
+		if got := state.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"syscall"
 	"unsafe"
 
+	"github.com/lxn/walk/vector"
 	"github.com/lxn/win"
 )
 
@@ -25,6 +26,7 @@ type Bitmap struct {
 	size               Size // in native pixels
 	dpi                int
 	transparencyStatus transparencyStatus
+	variants           []*Bitmap // For higher DPIs, ordered by size.
 }
 
 type transparencyStatus byte
@@ -35,7 +37,15 @@ const (
 	transparencyTransparent
 )
 
+// BitmapFrom returns a *Bitmap of the image of src, as ImageFrom returns it, rendered
+// for dpi. It stays valid until that image is disposed.
 func BitmapFrom(src interface{}, dpi int) (*Bitmap, error) {
+	return bitmapFrom(src, dpi, true)
+}
+
+// bitmapFrom is like BitmapFrom. Unless retain is true, the *Bitmap must be
+// used right away, see IconCache.bitmap.
+func bitmapFrom(src interface{}, dpi int, retain bool) (*Bitmap, error) {
 	if src == nil {
 		return nil, nil
 	}
@@ -45,7 +55,7 @@ func BitmapFrom(src interface{}, dpi int) (*Bitmap, error) {
 		return nil, err
 	}
 
-	return iconCache.Bitmap(img, dpi)
+	return iconCache.bitmap(img, dpi, retain)
 }
 
 // NewBitmap creates an opaque bitmap with given size in 1/96" units at screen DPI.
@@ -164,6 +174,30 @@ func NewBitmapFromImageForDPI(im image.Image, dpi int) (*Bitmap, error) {
 	return newBitmapFromHBITMAP(hBmp, dpi)
 }
 
+// NewBitmapFromVectorDrawing creates a transparent Bitmap of size in 1/96"
+// units at given DPI and lets draw paint on it anti-aliased. The user space
+// of the vector.Context is in 1/96" units, too.
+func NewBitmapFromVectorDrawing(size Size, dpi int, draw func(ctx *vector.Context) error) (*Bitmap, error) {
+	size = SizeFrom96DPI(size, dpi)
+
+	im := image.NewRGBA(image.Rect(0, 0, size.Width, size.Height))
+
+	if err := draw(newVectorContextForDPI(im, dpi)); err != nil {
+		return nil, err
+	}
+
+	return NewBitmapFromImageForDPI(im, dpi)
+}
+
+func newVectorContextForDPI(im *image.RGBA, dpi int) *vector.Context {
+	ctx := vector.NewContext(im)
+
+	scale := float64(dpi) / 96
+	ctx.Scale(scale, scale)
+
+	return ctx
+}
+
 // NewBitmapFromResource creates a Bitmap at 96dpi from resource by name.
 //
 // Deprecated: Newer applications should use NewBitmapFromResourceForDPI.
@@ -294,6 +328,45 @@ func (bmp *Bitmap) ToImage() (*image.RGBA, error) {
 	return img, nil
 }
 
+// DrawVector lets draw paint on the bitmap anti-aliased. The user space of
+// the vector.Context is in 1/96" units.
+func (bmp *Bitmap) DrawVector(draw func(ctx *vector.Context) error) error {
+	im, err := bmp.ToImage()
+	if err != nil {
+		return err
+	}
+
+	if err := draw(newVectorContextForDPI(im, bmp.dpi)); err != nil {
+		return err
+	}
+
+	return bmp.withPixels(func(bi *win.BITMAPINFO, hdc win.HDC, pixels *[maxPixels]bgraPixel, pixelsLen int) error {
+		width := int(bi.BmiHeader.BiWidth)
+		height := int(bi.BmiHeader.BiHeight)
+
+		// Like in ToImage, the rows of the bitmap are bottom-up.
+		for y := 0; y < height; y++ {
+			row := im.Pix[(height-y-1)*im.Stride:]
+
+			for x := 0; x < width; x++ {
+				p := &pixels[y*width+x]
+				p.R = row[x*4+0]
+				p.G = row[x*4+1]
+				p.B = row[x*4+2]
+				p.A = row[x*4+3]
+			}
+		}
+
+		if 0 == win.SetDIBits(hdc, bmp.hBmp, 0, uint32(height), &pixels[0].B, bi, win.DIB_RGB_COLORS) {
+			return newError("SetDIBits")
+		}
+
+		bmp.transparencyStatus = transparencyUnknown
+
+		return nil
+	})
+}
+
 func (bmp *Bitmap) hasTransparency() (bool, error) {
 	if bmp.transparencyStatus == transparencyUnknown {
 		if err := bmp.withPixels(func(bi *win.BITMAPINFO, hdc win.HDC, pixels *[maxPixels]bgraPixel, pixelsLen int) error {
@@ -379,6 +452,13 @@ func (bmp *Bitmap) withPixels(f func(bi *win.BITMAPINFO, hdc win.HDC, pixels *[m
 }
 
 func (bmp *Bitmap) Dispose() {
+	iconCache.Remove(bmp)
+
+	for _, variant := range bmp.variants {
+		variant.Dispose()
+	}
+	bmp.variants = nil
+
 	if bmp.hBmp != 0 {
 		win.DeleteObject(win.HGDIOBJ(bmp.hBmp))
 
@@ -404,7 +484,7 @@ func (bmp *Bitmap) draw(hdc win.HDC, location Point) error {
 }
 
 func (bmp *Bitmap) drawStretched(hdc win.HDC, bounds Rectangle) error {
-	return bmp.alphaBlend(hdc, bounds, 255)
+	return bmp.variantForSize(bounds.Size()).alphaBlend(hdc, bounds, 255)
 }
 
 // alphaBlend displays bitmaps that have transparent or semitransparent pixels. bounds is represented in native pixels.
@@ -28,6 +28,7 @@ type Button struct {
 	textChangedPublisher    EventPublisher
 	imageChangedPublisher   EventPublisher
 	image                   Image
+	imageKey                iconCacheKey // Of the cached bitmap of image, if any.
 	persistent              bool
 }
 
@@ -68,6 +69,13 @@ func (b *Button) init() {
 		b.textChangedPublisher.Event()))
 }
 
+func (b *Button) Dispose() {
+	b.WidgetBase.Dispose()
+
+	iconCache.release(b.imageKey)
+	b.imageKey = iconCacheKey{}
+}
+
 func (b *Button) ApplyDPI(dpi int) {
 	b.WidgetBase.ApplyDPI(dpi)
 
@@ -80,6 +88,7 @@ func (b *Button) Image() Image {
 
 func (b *Button) SetImage(image Image) error {
 	var typ, handle uintptr
+	var key iconCacheKey
 	switch img := image.(type) {
 	case nil:
 
@@ -99,10 +108,14 @@ func (b *Button) SetImage(image Image) error {
 
 		typ = win.IMAGE_BITMAP
 		handle = uintptr(bmp.hBmp)
+		key = iconCacheKey{image, b.DPI(), false}
 	}
 
 	b.SendMessage(win.BM_SETIMAGE, typ, handle)
 
+	iconCache.release(b.imageKey)
+	b.imageKey = key
+
 	b.image = image
 
 	b.RequestLayout()
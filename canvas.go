@@ -13,6 +13,9 @@ import (
 	"unsafe"
 
 	"github.com/lxn/win"
+	"golang.org/x/sys/windows"
+
+	"github.com/lxn/walk/displaylist"
 )
 
 // DrawText format flags
@@ -62,6 +65,7 @@ type Canvas struct {
 	recordingMetafile   *Metafile
 	measureTextMetafile *Metafile
 	doNotDispose        bool
+	recording           *displaylist.List
 }
 
 func NewCanvasFromImage(image Image) (*Canvas, error) {
@@ -148,6 +152,8 @@ func (c *Canvas) Dispose() {
 			if err := c.bitmap.postProcess(); err != nil {
 				log.Printf("*Canvas.Dispose - failed to post-process bitmap: %s", err.Error())
 			}
+		} else if c.recording != nil {
+			win.DeleteDC(c.hdc)
 		} else {
 			win.ReleaseDC(c.window.Handle(), c.hdc)
 		}
@@ -211,6 +217,10 @@ func (c *Canvas) Bounds() Rectangle {
 }
 
 func (c *Canvas) BoundsPixels() Rectangle {
+	if c.recording != nil {
+		return Rectangle{Width: int(c.recording.Width), Height: int(c.recording.Height)}
+	}
+
 	return Rectangle{
 		Width:  int(win.GetDeviceCaps(c.hdc, win.HORZRES)),
 		Height: int(win.GetDeviceCaps(c.hdc, win.VERTRES)),
@@ -236,6 +246,10 @@ func (c *Canvas) ellipse(brush Brush, pen Pen, bounds Rectangle, sizeCorrection
 
 // ellipsePixels draws an ellipse in native pixels.
 func (c *Canvas) ellipsePixels(brush Brush, pen Pen, bounds Rectangle, sizeCorrection int) error {
+	if c.recording != nil {
+		return c.recordEllipse(brush, pen, bounds)
+	}
+
 	return c.withBrushAndPen(brush, pen, func() error {
 		if !win.Ellipse(
 			c.hdc,
@@ -288,6 +302,11 @@ func (c *Canvas) DrawImagePixels(image Image, location Point) error {
 		return newError("image cannot be nil")
 	}
 
+	if c.recording != nil {
+		size := c.imageSizePixels(image)
+		return c.recordImage(image, Rectangle{location.X, location.Y, size.Width, size.Height}, nil, 0xFF)
+	}
+
 	return image.draw(c.hdc, location)
 }
 
@@ -304,6 +323,10 @@ func (c *Canvas) DrawImageStretchedPixels(image Image, bounds Rectangle) error {
 		return newError("image cannot be nil")
 	}
 
+	if c.recording != nil {
+		return c.recordImage(image, bounds, nil, 0xFF)
+	}
+
 	if dsoc, ok := image.(interface {
 		drawStretchedOnCanvasPixels(canvas *Canvas, bounds Rectangle) error
 	}); ok {
@@ -327,6 +350,10 @@ func (c *Canvas) DrawBitmapWithOpacityPixels(bmp *Bitmap, bounds Rectangle, opac
 		return newError("bmp cannot be nil")
 	}
 
+	if c.recording != nil {
+		return c.recordImage(bmp, bounds, nil, opacity)
+	}
+
 	return bmp.alphaBlend(c.hdc, bounds, opacity)
 }
 
@@ -349,6 +376,10 @@ func (c *Canvas) DrawBitmapPartWithOpacityPixels(bmp *Bitmap, dst, src Rectangle
 		return newError("bmp cannot be nil")
 	}
 
+	if c.recording != nil {
+		return c.recordImage(bmp, dst, &src, opacity)
+	}
+
 	return bmp.alphaBlendPart(c.hdc, dst, src, opacity)
 }
 
@@ -362,6 +393,10 @@ func (c *Canvas) DrawLine(pen Pen, from, to Point) error {
 
 // DrawLinePixels draws a line between two points in native pixels.
 func (c *Canvas) DrawLinePixels(pen Pen, from, to Point) error {
+	if c.recording != nil {
+		return c.recordPolyline(pen, []Point{from, to})
+	}
+
 	if !win.MoveToEx(c.hdc, int(from.X), int(from.Y), nil) {
 		return newError("MoveToEx failed")
 	}
@@ -385,9 +420,27 @@ func (c *Canvas) DrawPolyline(pen Pen, points []Point) error {
 
 	dpi := c.DPI()
 
+	pts := make([]Point, len(points))
+	for i, p := range points {
+		pts[i] = PointFrom96DPI(p, dpi)
+	}
+
+	return c.DrawPolylinePixels(pen, pts)
+}
+
+// DrawPolylinePixels draws a line between given points in native pixels.
+func (c *Canvas) DrawPolylinePixels(pen Pen, points []Point) error {
+	if len(points) < 1 {
+		return nil
+	}
+
+	if c.recording != nil {
+		return c.recordPolyline(pen, points)
+	}
+
 	pts := make([]win.POINT, len(points))
 	for i, p := range points {
-		pts[i] = PointFrom96DPI(p, dpi).toPOINT()
+		pts[i] = p.toPOINT()
 	}
 
 	return c.withPen(pen, func() error {
@@ -399,26 +452,49 @@ func (c *Canvas) DrawPolyline(pen Pen, points []Point) error {
 	})
 }
 
-// DrawPolylinePixels draws a line between given points in native pixels.
-func (c *Canvas) DrawPolylinePixels(pen Pen, points []Point) error {
-	if len(points) < 1 {
+var (
+	libgdi32    = windows.NewLazySystemDLL("gdi32.dll")
+	procPolygon = libgdi32.NewProc("Polygon")
+)
+
+// polygonPixels draws a closed polygon in native pixels.
+func (c *Canvas) polygonPixels(brush Brush, pen Pen, points []Point) error {
+	if len(points) < 2 {
 		return nil
 	}
 
+	if c.recording != nil {
+		return c.recordPolygon(brush, pen, points)
+	}
+
 	pts := make([]win.POINT, len(points))
 	for i, p := range points {
 		pts[i] = p.toPOINT()
 	}
 
-	return c.withPen(pen, func() error {
-		if !win.Polyline(c.hdc, unsafe.Pointer(&pts[0].X), int32(len(pts))) {
-			return newError("Polyline failed")
+	return c.withBrushAndPen(brush, pen, func() error {
+		if ret, _, _ := procPolygon.Call(
+			uintptr(c.hdc),
+			uintptr(unsafe.Pointer(&pts[0])),
+			uintptr(len(pts))); ret == 0 {
+
+			return newError("Polygon failed")
 		}
 
 		return nil
 	})
 }
 
+// DrawPolygonPixels draws the outline of a closed polygon in native pixels.
+func (c *Canvas) DrawPolygonPixels(pen Pen, points []Point) error {
+	return c.polygonPixels(nullBrushSingleton, pen, points)
+}
+
+// FillPolygonPixels draws a filled closed polygon in native pixels.
+func (c *Canvas) FillPolygonPixels(brush Brush, points []Point) error {
+	return c.polygonPixels(brush, nullPenSingleton, points)
+}
+
 // rectangle draws a rectangle in 1/96" units. sizeCorrection parameter is in native pixels.
 //
 // Deprecated: Newer applications should use rectanglePixels.
@@ -428,6 +504,10 @@ func (c *Canvas) rectangle(brush Brush, pen Pen, bounds Rectangle, sizeCorrectio
 
 // rectanglePixels draws a rectangle in native pixels.
 func (c *Canvas) rectanglePixels(brush Brush, pen Pen, bounds Rectangle, sizeCorrection int) error {
+	if c.recording != nil {
+		return c.recordRectangle(brush, pen, bounds, Size{})
+	}
+
 	return c.withBrushAndPen(brush, pen, func() error {
 		if !win.Rectangle_(
 			c.hdc,
@@ -478,6 +558,10 @@ func (c *Canvas) roundedRectangle(brush Brush, pen Pen, bounds Rectangle, ellips
 
 // roundedRectanglePixels draws a rounded rectangle in native pixels.
 func (c *Canvas) roundedRectanglePixels(brush Brush, pen Pen, bounds Rectangle, ellipseSize Size, sizeCorrection int) error {
+	if c.recording != nil {
+		return c.recordRectangle(brush, pen, bounds, ellipseSize)
+	}
+
 	return c.withBrushAndPen(brush, pen, func() error {
 		if !win.RoundRect(
 			c.hdc,
@@ -531,6 +615,10 @@ func (c *Canvas) GradientFillRectangle(color1, color2 Color, orientation Orienta
 
 // GradientFillRectanglePixels draws a gradient filled rectangle in native pixels.
 func (c *Canvas) GradientFillRectanglePixels(color1, color2 Color, orientation Orientation, bounds Rectangle) error {
+	if c.recording != nil {
+		return c.recordGradient(color1, color2, orientation, bounds)
+	}
+
 	vertices := [2]win.TRIVERTEX{
 		{
 			X:     int32(bounds.X),
@@ -575,6 +663,10 @@ func (c *Canvas) DrawText(text string, font *Font, color Color, bounds Rectangle
 
 // DrawTextPixels draws text at given location in native pixels.
 func (c *Canvas) DrawTextPixels(text string, font *Font, color Color, bounds Rectangle, format DrawTextFormat) error {
+	if c.recording != nil {
+		return c.recordText(text, font, color, bounds, format)
+	}
+
 	return c.withFontAndTextColor(font, color, func() error {
 		rect := bounds.toRECT()
 		ret := win.DrawTextEx(
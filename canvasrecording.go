@@ -0,0 +1,409 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"syscall"
+
+	"github.com/lxn/win"
+
+	"github.com/lxn/walk/displaylist"
+	"github.com/lxn/walk/pagination"
+)
+
+// NewRecordingCanvas returns a *Canvas of size in native pixels at dpi, that
+// records what is drawn on it instead of drawing it.
+//
+// Its DisplayList can be exported as SVG or PDF document. Text can still be
+// measured as usual.
+func NewRecordingCanvas(size Size, dpi int) (*Canvas, error) {
+	hdc := win.CreateCompatibleDC(0)
+	if hdc == 0 {
+		return nil, newError("CreateCompatibleDC failed")
+	}
+
+	c, err := (&Canvas{
+		hdc: hdc,
+		dpi: dpi,
+		recording: &displaylist.List{
+			Width:  float64(size.Width),
+			Height: float64(size.Height),
+			DPI:    float64(dpi),
+		},
+	}).init()
+	if err != nil {
+		win.DeleteDC(hdc)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// DisplayList returns what was drawn on a *Canvas from NewRecordingCanvas.
+// It returns nil for other canvases.
+func (c *Canvas) DisplayList() *displaylist.List {
+	return c.recording
+}
+
+func colorToNRGBA(col Color) color.NRGBA {
+	return color.NRGBA{col.R(), col.G(), col.B(), 0xFF}
+}
+
+// recordedFill returns the color that brush fills with, or nil if it doesn't
+// fill or has no single color.
+func recordedFill(brush Brush) *color.NRGBA {
+	var col Color
+
+	switch b := brush.(type) {
+	case *SolidColorBrush:
+		col = b.Color()
+
+	case *SystemColorBrush:
+		col = b.Color()
+
+	case *HatchBrush:
+		col = b.Color()
+
+	default:
+		return nil
+	}
+
+	fill := colorToNRGBA(col)
+	return &fill
+}
+
+// recordedStroke returns how pen draws outlines, or nil if it doesn't.
+func (c *Canvas) recordedStroke(pen Pen) *displaylist.Stroke {
+	if pen == nil || pen.Style()&win.PS_STYLE_MASK == PenNull {
+		return nil
+	}
+
+	var stroke displaylist.Stroke
+
+	switch p := pen.(type) {
+	case *CosmeticPen:
+		stroke.Color = colorToNRGBA(p.Color())
+		stroke.Width = 1
+
+	case *GeometricPen:
+		fill := recordedFill(p.Brush())
+		if fill == nil {
+			return nil
+		}
+		stroke.Color = *fill
+		stroke.Width = float64(maxi(1, IntFrom96DPI(p.Width(), c.DPI())))
+
+		switch pen.Style() & win.PS_ENDCAP_MASK {
+		case PenCapRound:
+			stroke.Cap = displaylist.RoundCap
+
+		case PenCapSquare:
+			stroke.Cap = displaylist.SquareCap
+		}
+
+		switch pen.Style() & win.PS_JOIN_MASK {
+		case PenJoinRound:
+			stroke.Join = displaylist.RoundJoin
+
+		case PenJoinBevel:
+			stroke.Join = displaylist.BevelJoin
+		}
+
+	default:
+		return nil
+	}
+
+	u := stroke.Width
+	switch pen.Style() & win.PS_STYLE_MASK {
+	case PenDash:
+		stroke.Dashes = []float64{3 * u, u}
+
+	case PenDot, PenAlternate:
+		stroke.Dashes = []float64{u, u}
+
+	case PenDashDot:
+		stroke.Dashes = []float64{3 * u, u, u, u}
+
+	case PenDashDotDot:
+		stroke.Dashes = []float64{3 * u, u, u, u, u, u}
+	}
+
+	return &stroke
+}
+
+// recordedRect returns bounds as shape outline. Stroked outlines go through
+// the centers of the pixels at the edges of bounds, like GDI draws them.
+func recordedRect(bounds Rectangle, stroke *displaylist.Stroke) displaylist.Rect {
+	r := displaylist.Rect{float64(bounds.X), float64(bounds.Y), float64(bounds.Width), float64(bounds.Height)}
+
+	if stroke != nil {
+		r.X += 0.5
+		r.Y += 0.5
+		r.Width--
+		r.Height--
+	}
+
+	return r
+}
+
+func recordedPoints(points []Point, stroke *displaylist.Stroke) []displaylist.Point {
+	var offset float64
+	if stroke != nil {
+		offset = 0.5
+	}
+
+	pts := make([]displaylist.Point, len(points))
+	for i, p := range points {
+		pts[i] = displaylist.Point{float64(p.X) + offset, float64(p.Y) + offset}
+	}
+
+	return pts
+}
+
+func (c *Canvas) recordEllipse(brush Brush, pen Pen, bounds Rectangle) error {
+	stroke := c.recordedStroke(pen)
+
+	c.recording.Add(&displaylist.Ellipse{
+		Bounds: recordedRect(bounds, stroke),
+		Fill:   recordedFill(brush),
+		Stroke: stroke,
+	})
+
+	return nil
+}
+
+func (c *Canvas) recordRectangle(brush Brush, pen Pen, bounds Rectangle, ellipseSize Size) error {
+	stroke := c.recordedStroke(pen)
+
+	c.recording.Add(&displaylist.Rectangle{
+		Bounds: recordedRect(bounds, stroke),
+		RX:     float64(ellipseSize.Width) / 2,
+		RY:     float64(ellipseSize.Height) / 2,
+		Fill:   recordedFill(brush),
+		Stroke: stroke,
+	})
+
+	return nil
+}
+
+func (c *Canvas) recordPolygon(brush Brush, pen Pen, points []Point) error {
+	stroke := c.recordedStroke(pen)
+
+	c.recording.Add(&displaylist.Polygon{
+		Points: recordedPoints(points, stroke),
+		Fill:   recordedFill(brush),
+		Stroke: stroke,
+	})
+
+	return nil
+}
+
+func (c *Canvas) recordPolyline(pen Pen, points []Point) error {
+	stroke := c.recordedStroke(pen)
+	if stroke == nil {
+		return nil
+	}
+
+	if len(points) == 2 {
+		pts := recordedPoints(points, stroke)
+		c.recording.Add(&displaylist.Line{From: pts[0], To: pts[1], Stroke: *stroke})
+	} else {
+		c.recording.Add(&displaylist.Polyline{Points: recordedPoints(points, stroke), Stroke: *stroke})
+	}
+
+	return nil
+}
+
+func (c *Canvas) recordGradient(color1, color2 Color, orientation Orientation, bounds Rectangle) error {
+	c.recording.Add(&displaylist.Gradient{
+		Bounds:   recordedRect(bounds, nil),
+		From:     colorToNRGBA(color1),
+		To:       colorToNRGBA(color2),
+		Vertical: orientation == Vertical,
+	})
+
+	return nil
+}
+
+// imageSizePixels returns the size of img in native pixels of the Canvas.
+func (c *Canvas) imageSizePixels(img Image) Size {
+	if bmp, ok := img.(*Bitmap); ok {
+		return bmp.size
+	}
+
+	return SizeFrom96DPI(img.Size(), c.DPI())
+}
+
+// recordImage records the part src of img, or all of it if src is nil,
+// stretched to bounds.
+func (c *Canvas) recordImage(img Image, bounds Rectangle, src *Rectangle, opacity byte) error {
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return nil
+	}
+
+	bmp, ok := img.(*Bitmap)
+	if !ok {
+		var err error
+		if bmp, err = NewBitmapFromImageWithSize(img, bounds.Size()); err != nil {
+			return err
+		}
+		defer bmp.Dispose()
+	}
+
+	rgba, err := bmp.ToImage()
+	if err != nil {
+		return err
+	}
+
+	var im image.Image = rgba
+	if src != nil {
+		im = rgba.SubImage(image.Rect(src.X, src.Y, src.X+src.Width, src.Y+src.Height))
+	}
+
+	c.recording.Add(&displaylist.Image{
+		Image:   im,
+		Bounds:  recordedRect(bounds, nil),
+		Opacity: float64(opacity) / 0xFF,
+	})
+
+	return nil
+}
+
+// textExtentPixels returns the size of the single line text in native pixels.
+func (c *Canvas) textExtentPixels(text string, font *Font) (size Size, err error) {
+	err = c.withFontAndTextColor(font, 0, func() error {
+		text16 := syscall.StringToUTF16(text)
+
+		var s win.SIZE
+		if !win.GetTextExtentPoint32(c.hdc, &text16[0], int32(len(text16)-1), &s) {
+			return newError("GetTextExtentPoint32 failed")
+		}
+
+		size = Size{int(s.CX), int(s.CY)}
+
+		return nil
+	})
+
+	return
+}
+
+// recordText records text laid out like DrawTextEx would, for the flags that
+// matter in a display list.
+func (c *Canvas) recordText(text string, font *Font, col Color, bounds Rectangle, format DrawTextFormat) error {
+	var tm win.TEXTMETRIC
+	if err := c.withFontAndTextColor(font, 0, func() error {
+		if !win.GetTextMetrics(c.hdc, &tm) {
+			return newError("GetTextMetrics failed")
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if format&TextNoPrefix == 0 {
+		text = withoutMnemonicPrefixes(text)
+	}
+	if format&TextExpandTabs != 0 {
+		text = strings.ReplaceAll(text, "\t", "        ")
+	}
+
+	measure := func(s string) int {
+		size, _ := c.textExtentPixels(s, font)
+		return size.Width
+	}
+
+	var lines []string
+	switch {
+	case format&TextSingleLine != 0:
+		lines = []string{strings.NewReplacer("\r\n", " ", "\n", " ").Replace(text)}
+
+	case format&TextWordbreak != 0:
+		lines = pagination.Wrap(text, bounds.Width, measure)
+
+	default:
+		lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	}
+
+	widths := make([]float64, len(lines))
+	for i, line := range lines {
+		if format&(TextEndEllipsis|TextWordEllipsis|TextPathEllipsis) != 0 {
+			line = withEllipsis(line, bounds.Width, measure)
+			lines[i] = line
+		}
+
+		widths[i] = float64(measure(line))
+	}
+
+	op := &displaylist.Text{
+		Lines:  lines,
+		Widths: widths,
+		Bounds: recordedRect(bounds, nil),
+		Font: displaylist.Font{
+			Family:    font.Family(),
+			Size:      float64(tm.TmHeight - tm.TmInternalLeading),
+			Bold:      font.Bold(),
+			Italic:    font.Italic(),
+			Underline: font.Underline(),
+			StrikeOut: font.StrikeOut(),
+		},
+		Color:      colorToNRGBA(col),
+		LineHeight: float64(tm.TmHeight),
+		Ascent:     float64(tm.TmAscent),
+	}
+
+	if format&TextCenter != 0 {
+		op.Align = displaylist.AlignCenter
+	} else if format&TextRight != 0 {
+		op.Align = displaylist.AlignRight
+	}
+
+	if format&TextVCenter != 0 {
+		op.VAlign = displaylist.AlignMiddle
+	} else if format&TextBottom != 0 {
+		op.VAlign = displaylist.AlignBottom
+	}
+
+	c.recording.Add(op)
+
+	return nil
+}
+
+// withoutMnemonicPrefixes returns text without the ampersands that DrawTextEx
+// turns into underlines. Double ampersands become single ones.
+func withoutMnemonicPrefixes(text string) string {
+	var sb strings.Builder
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '&' && i+1 < len(runes) {
+			i++
+		}
+
+		sb.WriteRune(runes[i])
+	}
+
+	return sb.String()
+}
+
+// withEllipsis returns line, shortened and ending in an ellipsis if it is
+// wider than width.
+func withEllipsis(line string, width int, measure func(s string) int) string {
+	if measure(line) <= width {
+		return line
+	}
+
+	runes := []rune(line)
+	for len(runes) > 0 && measure(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
@@ -0,0 +1,320 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chart implements the computations behind charts: tick generation,
+// scales, stacking and pie slices.
+//
+// It does not depend on walk, so the logic can be tested on any platform.
+// walk.ChartView draws the charts.
+package chart
+
+import (
+	"math"
+	"strconv"
+)
+
+// Ticks are the values at which an axis is labeled. They go from Min to Max
+// in steps of Step, which is 1, 2 or 5 times a power of ten.
+type Ticks struct {
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+// NiceTicks returns Ticks that cover the range from min to max with about
+// maxCount intervals at most. Min and Max of the result are multiples of
+// Step, so they may extend the range a bit.
+//
+// An empty range is widened, so there is always more than one tick.
+func NiceTicks(min, max float64, maxCount int) Ticks {
+	if math.IsNaN(min) || math.IsInf(min, 0) {
+		min = 0
+	}
+	if math.IsNaN(max) || math.IsInf(max, 0) {
+		max = 0
+	}
+	if min > max {
+		min, max = max, min
+	}
+	if min == max {
+		if min == 0 {
+			max = 1
+		} else {
+			d := math.Abs(min) / 2
+			min -= d
+			max += d
+		}
+	}
+	if maxCount < 1 {
+		maxCount = 1
+	}
+
+	step := niceStep((max - min) / float64(maxCount))
+
+	return Ticks{
+		Min:  math.Floor(min/step) * step,
+		Max:  math.Ceil(max/step) * step,
+		Step: step,
+	}
+}
+
+// niceStep returns the smallest of 1, 2 or 5 times a power of ten, that is
+// not smaller than step.
+func niceStep(step float64) float64 {
+	exp := math.Floor(math.Log10(step))
+	base := math.Pow(10, exp)
+
+	for _, f := range []float64{1, 2, 5} {
+		if f*base >= step*(1-1e-9) {
+			return f * base
+		}
+	}
+
+	return 10 * base
+}
+
+// Count returns the number of ticks.
+func (t Ticks) Count() int {
+	if t.Step <= 0 {
+		return 0
+	}
+
+	return int(math.Round((t.Max-t.Min)/t.Step)) + 1
+}
+
+// Values returns the tick values, from Min to Max.
+func (t Ticks) Values() []float64 {
+	values := make([]float64, t.Count())
+	for i := range values {
+		// Multiplying avoids the error that adding up steps accumulates.
+		values[i] = t.Min + float64(i)*t.Step
+	}
+
+	return values
+}
+
+// Decimals returns the number of decimals needed to tell the ticks apart.
+func (t Ticks) Decimals() int {
+	if t.Step <= 0 || t.Step >= 1 {
+		return 0
+	}
+
+	return int(math.Ceil(-math.Log10(t.Step) - 1e-9))
+}
+
+// Format formats v with the decimals of the ticks.
+func (t Ticks) Format(v float64) string {
+	s := strconv.FormatFloat(v, 'f', t.Decimals(), 64)
+
+	// Avoid "-0" for values that are zero but for rounding errors.
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f == 0 {
+		s = strconv.FormatFloat(0, 'f', t.Decimals(), 64)
+	}
+
+	return s
+}
+
+// Scale maps values from a domain to a range, linearly. The range may be
+// inverted, e.g. for a vertical axis whose values grow upwards.
+type Scale struct {
+	DomainMin float64
+	DomainMax float64
+	RangeMin  float64
+	RangeMax  float64
+}
+
+// Map returns the position of v in the range.
+func (s Scale) Map(v float64) float64 {
+	if s.DomainMax == s.DomainMin {
+		return s.RangeMin
+	}
+
+	return s.RangeMin + (v-s.DomainMin)/(s.DomainMax-s.DomainMin)*(s.RangeMax-s.RangeMin)
+}
+
+// Invert returns the value at position p in the range.
+func (s Scale) Invert(p float64) float64 {
+	if s.RangeMax == s.RangeMin {
+		return s.DomainMin
+	}
+
+	return s.DomainMin + (p-s.RangeMin)/(s.RangeMax-s.RangeMin)*(s.DomainMax-s.DomainMin)
+}
+
+// Extent returns the smallest and largest of values, which are indexed by
+// series, then by category. If includeZero is true, the result includes 0, as
+// is usual for bar charts.
+func Extent(values [][]float64, includeZero bool) (min, max float64) {
+	first := true
+	if includeZero {
+		first = false
+	}
+
+	for _, series := range values {
+		for _, v := range series {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+
+			if first {
+				min, max = v, v
+				first = false
+				continue
+			}
+
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	return
+}
+
+// Stack returns the lower and upper ends of the segments of a stacked chart.
+// values and the results are indexed by series, then by category. Positive
+// values stack upwards from 0 and negative ones downwards.
+func Stack(values [][]float64) (lower, upper [][]float64) {
+	lower = make([][]float64, len(values))
+	upper = make([][]float64, len(values))
+
+	var pos, neg []float64
+
+	for s, series := range values {
+		lower[s] = make([]float64, len(series))
+		upper[s] = make([]float64, len(series))
+
+		for len(pos) < len(series) {
+			pos = append(pos, 0)
+			neg = append(neg, 0)
+		}
+
+		for c, v := range series {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				v = 0
+			}
+
+			if v >= 0 {
+				lower[s][c] = pos[c]
+				pos[c] += v
+				upper[s][c] = pos[c]
+			} else {
+				upper[s][c] = neg[c]
+				neg[c] += v
+				lower[s][c] = neg[c]
+			}
+		}
+	}
+
+	return
+}
+
+// StackedExtent returns the smallest and largest ends of the segments that
+// Stack returns for values, including 0.
+func StackedExtent(values [][]float64) (min, max float64) {
+	lower, upper := Stack(values)
+
+	min, _ = Extent(lower, true)
+	_, max = Extent(upper, true)
+
+	return
+}
+
+// Slice is a pie slice. Angles are in radians, clockwise from 12 o'clock.
+type Slice struct {
+	Start    float64
+	Sweep    float64
+	Fraction float64
+}
+
+// End returns the angle where the slice ends.
+func (s Slice) End() float64 {
+	return s.Start + s.Sweep
+}
+
+// PieSlices returns the slices of a pie chart of values. Values that are not
+// positive get empty slices.
+func PieSlices(values []float64) []Slice {
+	var total float64
+	for _, v := range values {
+		if v > 0 && !math.IsInf(v, 0) {
+			total += v
+		}
+	}
+
+	slices := make([]Slice, len(values))
+
+	var start float64
+	last := -1
+	for i, v := range values {
+		slices[i].Start = start
+
+		if total > 0 && v > 0 && !math.IsInf(v, 0) {
+			slices[i].Fraction = v / total
+			slices[i].Sweep = slices[i].Fraction * 2 * math.Pi
+			start += slices[i].Sweep
+			last = i
+		}
+	}
+
+	// Make the last slice end at 12 o'clock exactly, despite rounding errors,
+	// so no angle falls between the slices.
+	if last >= 0 {
+		slices[last].Sweep = 2*math.Pi - slices[last].Start
+	}
+
+	return slices
+}
+
+// SliceAt returns the index of the slice that contains the point at dx, dy
+// from the center of the pie, with y growing downwards. It returns -1 if the
+// point is farther than radius from the center or no slice contains it.
+func SliceAt(slices []Slice, dx, dy, radius float64) int {
+	if dx*dx+dy*dy > radius*radius {
+		return -1
+	}
+
+	angle := math.Atan2(dx, -dy)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+
+	for i, s := range slices {
+		if s.Sweep > 0 && angle >= s.Start && angle < s.End() {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Arc returns points along the arc of a circle around cx, cy with radius
+// from angle start to end, clockwise from 12 o'clock with y growing
+// downwards. The points are at most maxStep radians apart, or 5 degrees if
+// maxStep is not positive.
+func Arc(cx, cy, radius, start, end, maxStep float64) (xs, ys []float64) {
+	if !(maxStep > 0) {
+		maxStep = math.Pi / 36
+	}
+
+	n := 1
+	if sweep := math.Abs(end - start); sweep > maxStep && !math.IsInf(sweep, 0) {
+		n = int(math.Ceil(sweep / maxStep))
+	}
+
+	xs = make([]float64, n+1)
+	ys = make([]float64, n+1)
+
+	for i := 0; i <= n; i++ {
+		a := start + (end-start)*float64(i)/float64(n)
+
+		xs[i] = cx + radius*math.Sin(a)
+		ys[i] = cy - radius*math.Cos(a)
+	}
+
+	return
+}
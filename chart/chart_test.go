@@ -0,0 +1,275 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chart
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNiceTicks(t *testing.T) {
+	inf := math.Inf(1)
+	nan := math.NaN()
+
+	tests := []struct {
+		min, max float64
+		maxCount int
+		want     Ticks
+	}{
+		{0, 100, 5, Ticks{0, 100, 20}},
+		{0, 100, 10, Ticks{0, 100, 10}},
+		{3, 97, 5, Ticks{0, 100, 20}},
+		{-13, 42, 6, Ticks{-20, 50, 10}},
+		{0, 0.7, 4, Ticks{0, 0.8, 0.2}},
+		{1000, 1050, 5, Ticks{1000, 1050, 10}},
+
+		// Inverted ranges are swapped.
+		{100, 0, 5, Ticks{0, 100, 20}},
+
+		// Empty ranges are widened.
+		{0, 0, 5, Ticks{0, 1, 0.2}},
+		{10, 10, 5, Ticks{4, 16, 2}},
+		{-10, -10, 5, Ticks{-16, -4, 2}},
+
+		// NaN and Inf count as 0.
+		{nan, 50, 5, Ticks{0, 50, 10}},
+		{-inf, inf, 5, Ticks{0, 1, 0.2}},
+		{nan, nan, 5, Ticks{0, 1, 0.2}},
+		{-50, inf, 5, Ticks{-50, 0, 10}},
+
+		// At least one interval.
+		{0, 10, 0, Ticks{0, 10, 10}},
+	}
+
+	for _, test := range tests {
+		got := NiceTicks(test.min, test.max, test.maxCount)
+		if !ticksEqual(got, test.want) {
+			t.Errorf("NiceTicks(%v, %v, %d): got %+v, want %+v", test.min, test.max, test.maxCount, got, test.want)
+		}
+		if got.Count() < 2 {
+			t.Errorf("NiceTicks(%v, %v, %d): got %d ticks, want at least 2", test.min, test.max, test.maxCount, got.Count())
+		}
+	}
+}
+
+func ticksEqual(a, b Ticks) bool {
+	return approxEqual(a.Min, b.Min) && approxEqual(a.Max, b.Max) && approxEqual(a.Step, b.Step)
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestTicksValues(t *testing.T) {
+	ticks := Ticks{-0.3, 0.3, 0.1}
+
+	if ticks.Count() != 7 {
+		t.Fatalf("got %d ticks, want 7", ticks.Count())
+	}
+
+	var formatted []string
+	for _, v := range ticks.Values() {
+		formatted = append(formatted, ticks.Format(v))
+	}
+
+	// The middle value is a rounding error away from 0, which must not be
+	// formatted as "-0.0".
+	if want := []string{"-0.3", "-0.2", "-0.1", "0.0", "0.1", "0.2", "0.3"}; !reflect.DeepEqual(formatted, want) {
+		t.Errorf("got %q, want %q", formatted, want)
+	}
+
+	if (Ticks{}).Count() != 0 || len((Ticks{}).Values()) != 0 {
+		t.Error("zero Ticks have values")
+	}
+}
+
+func TestTicksDecimals(t *testing.T) {
+	tests := []struct {
+		step float64
+		want int
+	}{
+		{0, 0},
+		{-1, 0},
+		{100, 0},
+		{1, 0},
+		{0.5, 1},
+		{0.2, 1},
+		{0.1, 1},
+		{0.05, 2},
+		{0.01, 2},
+		{0.0002, 4},
+	}
+
+	for _, test := range tests {
+		if got := (Ticks{Step: test.step}).Decimals(); got != test.want {
+			t.Errorf("step %v: got %d decimals, want %d", test.step, got, test.want)
+		}
+	}
+
+	if got := (Ticks{Step: 0.05}).Format(1.25); got != "1.25" {
+		t.Errorf("got %q, want %q", got, "1.25")
+	}
+	if got := (Ticks{Step: 20}).Format(-0.4); got != "0" {
+		t.Errorf("got %q, want %q", got, "0")
+	}
+}
+
+func TestScale(t *testing.T) {
+	s := Scale{DomainMin: 0, DomainMax: 100, RangeMin: 200, RangeMax: 0}
+
+	if got := s.Map(25); got != 150 {
+		t.Errorf("Map: got %v, want 150", got)
+	}
+	if got := s.Invert(150); got != 25 {
+		t.Errorf("Invert: got %v, want 25", got)
+	}
+
+	empty := Scale{DomainMin: 5, DomainMax: 5, RangeMin: 10, RangeMax: 10}
+	if got := empty.Map(7); got != 10 {
+		t.Errorf("Map of empty domain: got %v, want 10", got)
+	}
+	if got := empty.Invert(7); got != 5 {
+		t.Errorf("Invert of empty range: got %v, want 5", got)
+	}
+}
+
+func TestExtent(t *testing.T) {
+	values := [][]float64{{3, math.NaN(), 7}, {math.Inf(-1), 5}}
+
+	if min, max := Extent(values, false); min != 3 || max != 7 {
+		t.Errorf("got %v, %v, want 3, 7", min, max)
+	}
+	if min, max := Extent(values, true); min != 0 || max != 7 {
+		t.Errorf("including zero: got %v, %v, want 0, 7", min, max)
+	}
+	if min, max := Extent([][]float64{{-2, -8}}, true); min != -8 || max != 0 {
+		t.Errorf("negative including zero: got %v, %v, want -8, 0", min, max)
+	}
+}
+
+func TestStack(t *testing.T) {
+	values := [][]float64{
+		{2, -1, 4},
+		{3, -2},
+		{-1, 5, math.NaN()},
+	}
+
+	lower, upper := Stack(values)
+
+	wantLower := [][]float64{
+		{0, -1, 0},
+		{2, -3},
+		{-1, 0, 4},
+	}
+	wantUpper := [][]float64{
+		{2, 0, 4},
+		{5, -1},
+		{0, 5, 4},
+	}
+
+	if !reflect.DeepEqual(lower, wantLower) {
+		t.Errorf("lower: got %v, want %v", lower, wantLower)
+	}
+	if !reflect.DeepEqual(upper, wantUpper) {
+		t.Errorf("upper: got %v, want %v", upper, wantUpper)
+	}
+
+	if min, max := StackedExtent(values); min != -3 || max != 5 {
+		t.Errorf("StackedExtent: got %v, %v, want -3, 5", min, max)
+	}
+}
+
+func TestPieSlices(t *testing.T) {
+	slices := PieSlices([]float64{1, 0, 2, -5, math.Inf(1), 1})
+
+	wantFractions := []float64{0.25, 0, 0.5, 0, 0, 0.25}
+	for i, s := range slices {
+		if !approxEqual(s.Fraction, wantFractions[i]) {
+			t.Errorf("slice %d: got fraction %v, want %v", i, s.Fraction, wantFractions[i])
+		}
+		if !approxEqual(s.Sweep, wantFractions[i]*2*math.Pi) {
+			t.Errorf("slice %d: got sweep %v, want %v", i, s.Sweep, wantFractions[i]*2*math.Pi)
+		}
+	}
+
+	if slices[1].Start != slices[0].End() || slices[2].Start != slices[1].End() {
+		t.Error("slices are not contiguous")
+	}
+	if end := slices[5].End(); end != 2*math.Pi {
+		t.Errorf("last slice ends at %v, want 2π", end)
+	}
+
+	for i, s := range PieSlices([]float64{0, -1}) {
+		if s.Sweep != 0 {
+			t.Errorf("slice %d of an empty pie: got sweep %v", i, s.Sweep)
+		}
+	}
+}
+
+func TestSliceAt(t *testing.T) {
+	// Thirds with rounding errors.
+	slices := PieSlices([]float64{1, 0, 1, 1})
+
+	const r = 10
+	tests := []struct {
+		name   string
+		dx, dy float64
+		want   int
+	}{
+		{"12 o'clock", 0, -5, 0},
+		{"3 o'clock", 5, 0, 0},
+		{"4 o'clock, the start of the second third", 5 * math.Sin(2*math.Pi/3), -5 * math.Cos(2*math.Pi/3), 2},
+		{"6 o'clock", 0, 5, 2},
+		{"8 o'clock, the start of the last third", 5 * math.Sin(4*math.Pi/3), -5 * math.Cos(4*math.Pi/3), 3},
+		{"just before 12 o'clock", -1e-12, -5, 3},
+		{"on the rim", 0, r, 2},
+		{"outside", 0, r + 0.1, -1},
+	}
+
+	for _, test := range tests {
+		if got := SliceAt(slices, test.dx, test.dy, r); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	if got := SliceAt(PieSlices(nil), 0, -1, r); got != -1 {
+		t.Errorf("empty pie: got %d, want -1", got)
+	}
+}
+
+func TestArc(t *testing.T) {
+	xs, ys := Arc(10, 20, 5, 0, math.Pi, math.Pi/4)
+
+	if len(xs) != 5 || len(ys) != 5 {
+		t.Fatalf("got %d points, want 5", len(xs))
+	}
+
+	want := [][2]float64{{10, 15}, {10 + 5*math.Sqrt2/2, 20 - 5*math.Sqrt2/2}, {15, 20}, {10 + 5*math.Sqrt2/2, 20 + 5*math.Sqrt2/2}, {10, 25}}
+	for i, p := range want {
+		if !approxEqual(xs[i], p[0]) || !approxEqual(ys[i], p[1]) {
+			t.Errorf("point %d: got %v, %v, want %v, %v", i, xs[i], ys[i], p[0], p[1])
+		}
+	}
+
+	// Counterclockwise arcs are subdivided as well.
+	if xs, _ := Arc(0, 0, 1, math.Pi, 0, math.Pi/4); len(xs) != 5 {
+		t.Errorf("counterclockwise: got %d points, want 5", len(xs))
+	}
+
+	// Degenerate steps fall back to 5 degrees instead of dividing by zero.
+	for _, step := range []float64{0, -1, math.NaN()} {
+		if xs, _ := Arc(0, 0, 1, 0, math.Pi/2, step); len(xs) != 19 {
+			t.Errorf("step %v: got %d points, want 19", step, len(xs))
+		}
+	}
+
+	if xs, _ := Arc(0, 0, 1, 1, 1, 0.1); len(xs) != 2 {
+		t.Errorf("empty arc: got %d points, want 2", len(xs))
+	}
+	if xs, _ := Arc(0, 0, 1, 0, math.Inf(1), 0.1); len(xs) != 2 {
+		t.Errorf("infinite arc: got %d points, want 2", len(xs))
+	}
+}
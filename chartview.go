@@ -0,0 +1,882 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/lxn/walk/chart"
+)
+
+// ChartKind is the kind of chart a ChartView draws.
+type ChartKind int
+
+const (
+	// ChartLine draws a line per series through its values.
+	ChartLine ChartKind = iota
+
+	// ChartBar draws a group of bars per category, one bar per series.
+	ChartBar
+
+	// ChartStackedBar draws a bar per category, with the values of the
+	// series stacked on top of each other.
+	ChartStackedBar
+
+	// ChartPie draws a pie with a slice per category. Only the first series
+	// is used.
+	ChartPie
+)
+
+// ChartModel is the interface that a model must implement to support
+// ChartView.
+//
+// The data of a chart are the values of one or more series for a list of
+// categories, like the sales of several products per month.
+type ChartModel interface {
+	// SeriesCount returns the number of series.
+	SeriesCount() int
+
+	// SeriesName returns the name of a series, as shown in the legend.
+	SeriesName(series int) string
+
+	// CategoryCount returns the number of categories.
+	CategoryCount() int
+
+	// Category returns the label of a category.
+	Category(index int) string
+
+	// Value returns the value of a series for a category.
+	Value(series, index int) float64
+
+	// DataChanged returns the event that the model should publish when any
+	// of the above changed.
+	DataChanged() *Event
+}
+
+// ChartModelBase implements the DataChanged method of the ChartModel
+// interface.
+type ChartModelBase struct {
+	dataChangedPublisher EventPublisher
+}
+
+func (cmb *ChartModelBase) DataChanged() *Event {
+	return cmb.dataChangedPublisher.Event()
+}
+
+func (cmb *ChartModelBase) PublishDataChanged() {
+	cmb.dataChangedPublisher.Publish()
+}
+
+var defaultChartSeriesColors = []Color{
+	RGB(0x4E, 0x79, 0xA7),
+	RGB(0xF2, 0x8E, 0x2B),
+	RGB(0xE1, 0x57, 0x59),
+	RGB(0x76, 0xB7, 0xB2),
+	RGB(0x59, 0xA1, 0x4F),
+	RGB(0xED, 0xC9, 0x48),
+	RGB(0xB0, 0x7A, 0xA1),
+	RGB(0xFF, 0x9D, 0xA7),
+	RGB(0x9C, 0x75, 0x5F),
+	RGB(0xBA, 0xB0, 0xAC),
+}
+
+// ChartView is a widget that draws the data of a ChartModel as a line, bar,
+// stacked bar or pie chart, with axes and a legend. Hovering over a value
+// shows it in a tool tip.
+type ChartView struct {
+	*CustomWidget
+	model                ChartModel
+	dataChangedHandle    int
+	kind                 ChartKind
+	seriesColors         []Color
+	legendVisible        bool
+	layout               *chartLayout
+	toolTipItem          chartItem
+	kindChangedPublisher EventPublisher
+}
+
+// chartLayout remembers where the last paint put the values, for hit testing.
+type chartLayout struct {
+	items      []chartItem
+	itemBounds []Rectangle
+	pieCenter  Point
+	pieRadius  int
+	pieSlices  []chart.Slice
+}
+
+type chartItem struct {
+	series int
+	index  int
+}
+
+var noChartItem = chartItem{-1, -1}
+
+// NewChartView creates and returns a *ChartView as child of parent.
+func NewChartView(parent Container) (*ChartView, error) {
+	cv := &ChartView{
+		legendVisible: true,
+		toolTipItem:   noChartItem,
+	}
+
+	cw, err := NewCustomWidgetPixels(parent, 0, func(canvas *Canvas, updateBounds Rectangle) error {
+		return cv.paint(canvas, updateBounds)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cv.CustomWidget = cw
+
+	if err := InitWrapperWindow(cv); err != nil {
+		cv.Dispose()
+		return nil, err
+	}
+
+	cv.SetInvalidatesOnResize(true)
+	cv.SetPaintMode(PaintBuffered)
+
+	cv.MouseMove().Attach(cv.updateToolTip)
+
+	cv.MustRegisterProperty("Kind", NewProperty(
+		func() interface{} {
+			return cv.Kind()
+		},
+		func(v interface{}) error {
+			kind, ok := v.(ChartKind)
+			if !ok {
+				kind = ChartKind(assertIntOr(v, 0))
+			}
+
+			cv.SetKind(kind)
+
+			return nil
+		},
+		cv.kindChangedPublisher.Event()))
+
+	return cv, nil
+}
+
+// Dispose disposes the *ChartView and detaches it from its model.
+func (cv *ChartView) Dispose() {
+	if cv.model != nil {
+		cv.model.DataChanged().Detach(cv.dataChangedHandle)
+		cv.model = nil
+	}
+
+	cv.CustomWidget.Dispose()
+}
+
+// Model returns the model of the *ChartView.
+func (cv *ChartView) Model() ChartModel {
+	return cv.model
+}
+
+// SetModel sets the model of the *ChartView. The chart is redrawn whenever
+// the model publishes its DataChanged event.
+func (cv *ChartView) SetModel(model ChartModel) {
+	if model == cv.model {
+		return
+	}
+
+	if cv.model != nil {
+		cv.model.DataChanged().Detach(cv.dataChangedHandle)
+	}
+
+	cv.model = model
+
+	if model != nil {
+		cv.dataChangedHandle = model.DataChanged().Attach(func() {
+			cv.Invalidate()
+		})
+	}
+
+	cv.Invalidate()
+}
+
+// Kind returns the kind of chart the *ChartView draws.
+func (cv *ChartView) Kind() ChartKind {
+	return cv.kind
+}
+
+// SetKind sets the kind of chart the *ChartView draws.
+func (cv *ChartView) SetKind(kind ChartKind) {
+	if kind == cv.kind {
+		return
+	}
+
+	cv.kind = kind
+
+	cv.Invalidate()
+
+	cv.kindChangedPublisher.Publish()
+}
+
+// LegendVisible returns if the legend is shown.
+//
+// By default this is true.
+func (cv *ChartView) LegendVisible() bool {
+	return cv.legendVisible
+}
+
+// SetLegendVisible sets if the legend is shown.
+func (cv *ChartView) SetLegendVisible(visible bool) {
+	if visible == cv.legendVisible {
+		return
+	}
+
+	cv.legendVisible = visible
+
+	cv.Invalidate()
+}
+
+// SeriesColors returns the colors of the series, or of the slices of a pie
+// chart. They are used over and over again if there are more series.
+func (cv *ChartView) SeriesColors() []Color {
+	if cv.seriesColors == nil {
+		return defaultChartSeriesColors
+	}
+
+	return cv.seriesColors
+}
+
+// SetSeriesColors sets the colors of the series, or of the slices of a pie
+// chart. Pass nil for the default colors.
+func (cv *ChartView) SetSeriesColors(colors []Color) {
+	if len(colors) == 0 {
+		cv.seriesColors = nil
+	} else {
+		cv.seriesColors = append([]Color(nil), colors...)
+	}
+
+	cv.Invalidate()
+}
+
+func (cv *ChartView) seriesColor(i int) Color {
+	colors := cv.SeriesColors()
+
+	return colors[i%len(colors)]
+}
+
+// ItemAt returns the series and category index of the value drawn at x, y in
+// native pixels. ok is false if there is none. For pie charts, series is
+// always 0.
+func (cv *ChartView) ItemAt(x, y int) (series, index int, ok bool) {
+	item := cv.itemAt(x, y)
+
+	return item.series, item.index, item != noChartItem
+}
+
+func (cv *ChartView) itemAt(x, y int) chartItem {
+	l := cv.layout
+	if l == nil {
+		return noChartItem
+	}
+
+	if l.pieSlices != nil {
+		dx := float64(x - l.pieCenter.X)
+		dy := float64(y - l.pieCenter.Y)
+
+		if i := chart.SliceAt(l.pieSlices, dx, dy, float64(l.pieRadius)); i >= 0 {
+			return chartItem{0, i}
+		}
+
+		return noChartItem
+	}
+
+	// Later items are drawn on top of earlier ones.
+	for i := len(l.items) - 1; i >= 0; i-- {
+		b := l.itemBounds[i]
+		if x >= b.X && x < b.X+b.Width && y >= b.Y && y < b.Y+b.Height {
+			return l.items[i]
+		}
+	}
+
+	return noChartItem
+}
+
+func (cv *ChartView) updateToolTip(x, y int, button MouseButton) {
+	item := cv.itemAt(x, y)
+	if item == cv.toolTipItem {
+		return
+	}
+
+	cv.toolTipItem = item
+
+	var text string
+	if item != noChartItem && cv.model != nil {
+		m := cv.model
+		value := formatChartValue(m.Value(item.series, item.index))
+
+		if cv.kind == ChartPie {
+			text = fmt.Sprintf("%s: %s", m.Category(item.index), value)
+		} else if name := m.SeriesName(item.series); name != "" {
+			text = fmt.Sprintf("%s\n%s: %s", name, m.Category(item.index), value)
+		} else {
+			text = fmt.Sprintf("%s: %s", m.Category(item.index), value)
+		}
+	}
+
+	cv.SetToolTipText(text)
+}
+
+func formatChartValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// values returns the values of the model, indexed by series, then by
+// category.
+func (cv *ChartView) values() [][]float64 {
+	m := cv.model
+
+	values := make([][]float64, m.SeriesCount())
+	for s := range values {
+		values[s] = make([]float64, m.CategoryCount())
+		for c := range values[s] {
+			values[s][c] = m.Value(s, c)
+		}
+	}
+
+	return values
+}
+
+func (cv *ChartView) paint(canvas *Canvas, updateBounds Rectangle) error {
+	theme := App().Theme()
+
+	bounds := cv.ClientBoundsPixels()
+
+	bgColor := theme.Color(ThemeColorWindow)
+	bgBrush, err := NewSolidColorBrush(bgColor)
+	if err != nil {
+		return err
+	}
+	defer bgBrush.Dispose()
+
+	if err := canvas.FillRectanglePixels(bgBrush, bounds); err != nil {
+		return err
+	}
+
+	cv.layout = nil
+
+	if cv.model == nil {
+		return nil
+	}
+
+	dpi := cv.DPI()
+	padding := IntFrom96DPI(8, dpi)
+
+	area := Rectangle{
+		bounds.X + padding,
+		bounds.Y + padding,
+		bounds.Width - 2*padding,
+		bounds.Height - 2*padding,
+	}
+	if area.Width <= 0 || area.Height <= 0 {
+		return nil
+	}
+
+	cp := &chartPainter{
+		cv:        cv,
+		canvas:    canvas,
+		font:      cv.Font(),
+		textColor: theme.Color(ThemeColorWindowText),
+		gridColor: blendColors(bgColor, theme.Color(ThemeColorWindowText), 0.15),
+		axisColor: blendColors(bgColor, theme.Color(ThemeColorWindowText), 0.5),
+		dpi:       dpi,
+		padding:   padding,
+		layout:    new(chartLayout),
+	}
+
+	size, err := cp.measureText("Ag")
+	if err != nil {
+		return err
+	}
+	cp.lineHeight = size.Height
+
+	if cv.legendVisible {
+		if area, err = cp.drawLegend(area); err != nil {
+			return err
+		}
+	}
+
+	if cv.kind == ChartPie {
+		err = cp.drawPie(area)
+	} else {
+		err = cp.drawAxesChart(area)
+	}
+	if err != nil {
+		return err
+	}
+
+	cv.layout = cp.layout
+
+	return nil
+}
+
+// chartPainter holds what the parts of a chart need for painting.
+type chartPainter struct {
+	cv         *ChartView
+	canvas     *Canvas
+	font       *Font
+	textColor  Color
+	gridColor  Color
+	axisColor  Color
+	dpi        int
+	padding    int
+	lineHeight int
+	layout     *chartLayout
+}
+
+func (cp *chartPainter) measureText(text string) (Size, error) {
+	b, _, err := cp.canvas.MeasureTextPixels(text, cp.font, Rectangle{Width: 100000, Height: 100000}, TextCalcRect|TextSingleLine|TextNoPrefix)
+	if err != nil {
+		return Size{}, err
+	}
+
+	return b.Size(), nil
+}
+
+func (cp *chartPainter) drawText(text string, bounds Rectangle, format DrawTextFormat) error {
+	return cp.canvas.DrawTextPixels(text, cp.font, cp.textColor, bounds, format|TextSingleLine|TextNoPrefix)
+}
+
+func (cp *chartPainter) fillRectangle(color Color, bounds Rectangle) error {
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	return cp.canvas.FillRectanglePixels(brush, bounds)
+}
+
+func (cp *chartPainter) drawLine(color Color, from, to Point) error {
+	pen, err := NewCosmeticPen(PenSolid, color)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	return cp.canvas.DrawLinePixels(pen, from, to)
+}
+
+// drawLegend draws the legend at the right side of area and returns the
+// remaining area. The legend is left out if it would take too much space.
+func (cp *chartPainter) drawLegend(area Rectangle) (Rectangle, error) {
+	m := cp.cv.model
+
+	var names []string
+	if cp.cv.kind == ChartPie {
+		for i, n := 0, m.CategoryCount(); i < n; i++ {
+			names = append(names, m.Category(i))
+		}
+	} else {
+		for i, n := 0, m.SeriesCount(); i < n; i++ {
+			names = append(names, m.SeriesName(i))
+		}
+	}
+	if len(names) == 0 {
+		return area, nil
+	}
+
+	var textWidth int
+	for _, name := range names {
+		size, err := cp.measureText(name)
+		if err != nil {
+			return area, err
+		}
+		if size.Width > textWidth {
+			textWidth = size.Width
+		}
+	}
+
+	swatch := cp.lineHeight * 2 / 3
+	gap := cp.lineHeight / 3
+	width := swatch + gap + textWidth
+
+	if width > area.Width/3 {
+		return area, nil
+	}
+
+	x := area.X + area.Width - width
+	y := area.Y
+
+	for i, name := range names {
+		if y+cp.lineHeight > area.Y+area.Height {
+			break
+		}
+
+		swatchBounds := Rectangle{x, y + (cp.lineHeight-swatch)/2, swatch, swatch}
+		if err := cp.fillRectangle(cp.cv.seriesColor(i), swatchBounds); err != nil {
+			return area, err
+		}
+
+		if err := cp.drawText(name, Rectangle{x + swatch + gap, y, textWidth, cp.lineHeight}, TextLeft|TextVCenter); err != nil {
+			return area, err
+		}
+
+		y += cp.lineHeight + gap
+	}
+
+	area.Width -= width + cp.padding
+
+	return area, nil
+}
+
+// drawAxesChart draws a line, bar or stacked bar chart with its axes.
+func (cp *chartPainter) drawAxesChart(area Rectangle) error {
+	cv := cp.cv
+	m := cv.model
+
+	categoryCount := m.CategoryCount()
+	if categoryCount == 0 {
+		return nil
+	}
+
+	values := cv.values()
+
+	var lower, upper [][]float64
+	var min, max float64
+	switch cv.kind {
+	case ChartStackedBar:
+		lower, upper = chart.Stack(values)
+		min, max = chart.StackedExtent(values)
+
+	case ChartBar:
+		min, max = chart.Extent(values, true)
+
+	default:
+		min, max = chart.Extent(values, false)
+	}
+
+	tickLength := IntFrom96DPI(4, cp.dpi)
+
+	maxTicks := area.Height / (cp.lineHeight * 2)
+	if maxTicks < 2 {
+		maxTicks = 2
+	}
+	ticks := chart.NiceTicks(min, max, maxTicks)
+	tickValues := ticks.Values()
+
+	labels := make([]string, len(tickValues))
+	var labelWidth int
+	for i, v := range tickValues {
+		labels[i] = ticks.Format(v)
+
+		size, err := cp.measureText(labels[i])
+		if err != nil {
+			return err
+		}
+		if size.Width > labelWidth {
+			labelWidth = size.Width
+		}
+	}
+
+	plot := Rectangle{
+		X:      area.X + labelWidth + tickLength,
+		Y:      area.Y + cp.lineHeight/2,
+		Width:  area.Width - labelWidth - tickLength,
+		Height: area.Height - cp.lineHeight/2 - cp.lineHeight - tickLength,
+	}
+	if plot.Width <= 0 || plot.Height <= 0 {
+		return nil
+	}
+
+	yScale := chart.Scale{
+		DomainMin: ticks.Min,
+		DomainMax: ticks.Max,
+		RangeMin:  float64(plot.Y + plot.Height),
+		RangeMax:  float64(plot.Y),
+	}
+	mapY := func(v float64) int {
+		return int(math.Round(yScale.Map(v)))
+	}
+
+	// Grid and value axis labels
+	for i, v := range tickValues {
+		y := mapY(v)
+
+		if err := cp.drawLine(cp.gridColor, Point{plot.X, y}, Point{plot.X + plot.Width, y}); err != nil {
+			return err
+		}
+
+		if err := cp.drawText(labels[i], Rectangle{area.X, y - cp.lineHeight/2, labelWidth, cp.lineHeight}, TextRight|TextVCenter); err != nil {
+			return err
+		}
+	}
+
+	// Category axis labels, leaving out some if they don't fit.
+	bandWidth := float64(plot.Width) / float64(categoryCount)
+
+	var categoryLabelWidth int
+	for i := 0; i < categoryCount; i++ {
+		size, err := cp.measureText(m.Category(i))
+		if err != nil {
+			return err
+		}
+		if size.Width > categoryLabelWidth {
+			categoryLabelWidth = size.Width
+		}
+	}
+
+	labelStep := int(math.Ceil(float64(categoryLabelWidth+cp.padding) / bandWidth))
+	if labelStep < 1 {
+		labelStep = 1
+	}
+
+	for i := 0; i < categoryCount; i += labelStep {
+		cx := plot.X + int(bandWidth*(float64(i)+0.5))
+		bounds := Rectangle{
+			cx - categoryLabelWidth/2 - cp.padding/2,
+			plot.Y + plot.Height + tickLength,
+			categoryLabelWidth + cp.padding,
+			cp.lineHeight,
+		}
+
+		if err := cp.drawLine(cp.axisColor, Point{cx, plot.Y + plot.Height}, Point{cx, plot.Y + plot.Height + tickLength}); err != nil {
+			return err
+		}
+
+		if err := cp.drawText(m.Category(i), bounds, TextCenter|TextVCenter); err != nil {
+			return err
+		}
+	}
+
+	// Values
+	var err error
+	switch cv.kind {
+	case ChartLine:
+		err = cp.drawLines(values, plot, bandWidth, mapY)
+
+	case ChartBar:
+		err = cp.drawBars(values, plot, bandWidth, mapY)
+
+	case ChartStackedBar:
+		err = cp.drawStackedBars(lower, upper, plot, bandWidth, mapY)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Axes
+	baseline := plot.Y + plot.Height
+	if ticks.Min < 0 && ticks.Max > 0 {
+		baseline = mapY(0)
+	}
+
+	if err := cp.drawLine(cp.axisColor, Point{plot.X, plot.Y}, Point{plot.X, plot.Y + plot.Height}); err != nil {
+		return err
+	}
+
+	return cp.drawLine(cp.axisColor, Point{plot.X, baseline}, Point{plot.X + plot.Width, baseline})
+}
+
+func (cp *chartPainter) addItem(series, index int, bounds Rectangle) {
+	cp.layout.items = append(cp.layout.items, chartItem{series, index})
+	cp.layout.itemBounds = append(cp.layout.itemBounds, bounds)
+}
+
+// barBounds returns the bounds of a bar from value a to value b.
+func barBounds(x, width, ya, yb int) Rectangle {
+	if ya > yb {
+		ya, yb = yb, ya
+	}
+	if yb == ya {
+		yb++
+	}
+
+	return Rectangle{x, ya, width, yb - ya}
+}
+
+func (cp *chartPainter) drawLines(values [][]float64, plot Rectangle, bandWidth float64, mapY func(float64) int) error {
+	for s, series := range values {
+		if err := cp.drawSeriesLine(s, series, plot, bandWidth, mapY); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drawSeriesLine draws series s of a line chart. Values that are NaN or
+// infinite leave a gap in the line.
+func (cp *chartPainter) drawSeriesLine(s int, series []float64, plot Rectangle, bandWidth float64, mapY func(float64) int) error {
+	markerRadius := IntFrom96DPI(3, cp.dpi)
+	hitRadius := IntFrom96DPI(6, cp.dpi)
+
+	brush, err := NewSolidColorBrush(cp.cv.seriesColor(s))
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound|PenJoinRound, 2, brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	var points, run []Point
+	var categories []int
+
+	drawRun := func() error {
+		defer func() { run = run[:0] }()
+
+		if len(run) < 2 {
+			return nil
+		}
+
+		return cp.canvas.DrawPolylinePixels(pen, run)
+	}
+
+	for c, v := range series {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			if err := drawRun(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		p := Point{plot.X + int(bandWidth*(float64(c)+0.5)), mapY(v)}
+		points = append(points, p)
+		categories = append(categories, c)
+		run = append(run, p)
+	}
+	if err := drawRun(); err != nil {
+		return err
+	}
+
+	for i, p := range points {
+		marker := Rectangle{p.X - markerRadius, p.Y - markerRadius, 2 * markerRadius, 2 * markerRadius}
+		if err := cp.canvas.FillEllipsePixels(brush, marker); err != nil {
+			return err
+		}
+
+		cp.addItem(s, categories[i], Rectangle{p.X - hitRadius, p.Y - hitRadius, 2 * hitRadius, 2 * hitRadius})
+	}
+
+	return nil
+}
+
+func (cp *chartPainter) drawBars(values [][]float64, plot Rectangle, bandWidth float64, mapY func(float64) int) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	groupWidth := bandWidth * 0.8
+	barWidth := groupWidth / float64(len(values))
+	y0 := mapY(0)
+
+	for s, series := range values {
+		for c, v := range series {
+			x := plot.X + int(bandWidth*float64(c)+bandWidth*0.1+barWidth*float64(s))
+			w := int(math.Max(1, barWidth-1))
+
+			bounds := barBounds(x, w, y0, mapY(v))
+			if err := cp.fillRectangle(cp.cv.seriesColor(s), bounds); err != nil {
+				return err
+			}
+
+			cp.addItem(s, c, bounds)
+		}
+	}
+
+	return nil
+}
+
+func (cp *chartPainter) drawStackedBars(lower, upper [][]float64, plot Rectangle, bandWidth float64, mapY func(float64) int) error {
+	w := int(math.Max(1, bandWidth*0.6))
+
+	for s := range lower {
+		for c := range lower[s] {
+			if lower[s][c] == upper[s][c] {
+				continue
+			}
+
+			x := plot.X + int(bandWidth*float64(c)+bandWidth*0.2)
+
+			bounds := barBounds(x, w, mapY(lower[s][c]), mapY(upper[s][c]))
+			if err := cp.fillRectangle(cp.cv.seriesColor(s), bounds); err != nil {
+				return err
+			}
+
+			cp.addItem(s, c, bounds)
+		}
+	}
+
+	return nil
+}
+
+// drawPie draws a pie chart of the first series, centered in area.
+func (cp *chartPainter) drawPie(area Rectangle) error {
+	m := cp.cv.model
+	if m.SeriesCount() == 0 {
+		return nil
+	}
+
+	values := make([]float64, m.CategoryCount())
+	for i := range values {
+		values[i] = m.Value(0, i)
+	}
+
+	radius := area.Width
+	if area.Height < radius {
+		radius = area.Height
+	}
+	radius /= 2
+	if radius <= 0 {
+		return nil
+	}
+
+	center := Point{area.X + area.Width/2, area.Y + area.Height/2}
+	slices := chart.PieSlices(values)
+
+	for i, slice := range slices {
+		if slice.Sweep <= 0 {
+			continue
+		}
+
+		brush, err := NewSolidColorBrush(cp.cv.seriesColor(i))
+		if err != nil {
+			return err
+		}
+
+		if slice.Fraction >= 1 {
+			err = cp.canvas.FillEllipsePixels(brush, Rectangle{center.X - radius, center.Y - radius, 2 * radius, 2 * radius})
+		} else {
+			xs, ys := chart.Arc(float64(center.X), float64(center.Y), float64(radius), slice.Start, slice.End(), math.Pi/90)
+
+			points := make([]Point, 0, len(xs)+1)
+			points = append(points, center)
+			for j := range xs {
+				points = append(points, Point{int(math.Round(xs[j])), int(math.Round(ys[j]))})
+			}
+
+			err = cp.canvas.FillPolygonPixels(brush, points)
+		}
+		brush.Dispose()
+		if err != nil {
+			return err
+		}
+	}
+
+	cp.layout.pieCenter = center
+	cp.layout.pieRadius = radius
+	cp.layout.pieSlices = slices
+
+	return nil
+}
+
+// blendColors returns the color at f between a and b, with f from 0 to 1.
+func blendColors(a, b Color, f float64) Color {
+	mix := func(x, y byte) byte {
+		return byte(math.Round(float64(x) + (float64(y)-float64(x))*f))
+	}
+
+	return RGB(mix(a.R(), b.R()), mix(a.G(), b.G()), mix(a.B(), b.B()))
+}
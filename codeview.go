@@ -0,0 +1,1247 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/lxn/win"
+
+	"github.com/lxn/walk/highlight"
+)
+
+// codeViewWheelLines is how many lines a notch of the mouse wheel scrolls.
+const codeViewWheelLines = 3
+
+var (
+	codeViewBackgroundColor   = RGB(255, 255, 255)
+	codeViewGutterColor       = RGB(240, 240, 240)
+	codeViewLineNumberColor   = RGB(128, 128, 128)
+	codeViewSelectionColor    = RGB(204, 232, 255)
+	codeViewMatchColor        = RGB(255, 236, 139)
+	codeViewCurrentMatchColor = RGB(255, 165, 0)
+	codeViewBookmarkColor     = RGB(40, 110, 200)
+)
+
+// CodeView is a read-only view of lines of text, like source code or log
+// output, with syntax highlighting, line numbers, search and bookmarks.
+//
+// CodeView is virtualized: it fetches and highlights only the lines that
+// are visible, so that its LineProvider can hold millions of lines. It
+// assumes a fixed-pitch font.
+type CodeView struct {
+	*CustomWidget
+	provider                    LineProvider
+	providerResetHandle         int
+	providerInsertedHandle      int
+	providerRemovedHandle       int
+	highlighter                 highlight.Highlighter
+	tokenColors                 map[highlight.Kind]Color
+	lineNumbersVisible          bool
+	tabWidth                    int
+	topLine                     int
+	scrollX                     int // in native pixels
+	maxColumns                  int // of the widest line drawn so far
+	currentLine                 int
+	anchorLine                  int
+	currentLineChangedPublisher EventPublisher
+	selecting                   bool
+	bookmarks                   []int // sorted
+	bookmarksChangedPublisher   EventPublisher
+	searchText                  string
+	searchOptions               FindOptions
+	searchRegexp                *regexp.Regexp
+	match                       codeViewMatch
+	followTail                  bool
+	followTailChangedPublisher  EventPublisher
+	charSizeFont                *Font
+	charSizeDPI                 int
+	charSizeCache               Size
+}
+
+// codeViewMatch is the current search match. Start and End are byte offsets
+// into the line.
+type codeViewMatch struct {
+	line, start, end int
+}
+
+// NewCodeView creates and initializes a new CodeView.
+func NewCodeView(parent Container) (*CodeView, error) {
+	cv := &CodeView{
+		tokenColors: map[highlight.Kind]Color{
+			highlight.Plain:       RGB(0, 0, 0),
+			highlight.Keyword:     RGB(0, 0, 255),
+			highlight.Type:        RGB(43, 145, 175),
+			highlight.Literal:     RGB(0, 0, 255),
+			highlight.String:      RGB(163, 21, 21),
+			highlight.Number:      RGB(9, 134, 88),
+			highlight.Comment:     RGB(0, 128, 0),
+			highlight.Key:         RGB(4, 81, 165),
+			highlight.Punctuation: RGB(80, 80, 80),
+			highlight.Timestamp:   RGB(128, 128, 128),
+			highlight.Error:       RGB(205, 0, 0),
+			highlight.Warning:     RGB(190, 120, 0),
+			highlight.Info:        RGB(0, 100, 200),
+			highlight.Debug:       RGB(128, 128, 128),
+		},
+		lineNumbersVisible: true,
+		tabWidth:           4,
+		currentLine:        -1,
+		anchorLine:         -1,
+		match:              codeViewMatch{line: -1},
+	}
+
+	cw, err := NewCustomWidgetPixels(parent, win.WS_TABSTOP|win.WS_VSCROLL|win.WS_HSCROLL, func(canvas *Canvas, updateBounds Rectangle) error {
+		return cv.drawContent(canvas, updateBounds)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cv.CustomWidget = cw
+
+	if err := InitWrapperWindow(cv); err != nil {
+		cv.Dispose()
+		return nil, err
+	}
+
+	cv.SetPaintMode(PaintBuffered)
+
+	if font, err := NewFont("Consolas", 10, 0); err == nil {
+		cv.SetFont(font)
+	}
+
+	cv.MustRegisterProperty("FollowTail", NewBoolProperty(
+		func() bool {
+			return cv.FollowTail()
+		},
+		func(b bool) error {
+			cv.SetFollowTail(b)
+			return nil
+		},
+		cv.followTailChangedPublisher.Event()))
+
+	return cv, nil
+}
+
+func (cv *CodeView) Dispose() {
+	cv.detachProvider()
+
+	cv.CustomWidget.Dispose()
+}
+
+func (*CodeView) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return NewGreedyLayoutItem()
+}
+
+func (*CodeView) NeedsWmSize() bool {
+	return true
+}
+
+// LineProvider returns the LineProvider whose lines are shown.
+func (cv *CodeView) LineProvider() LineProvider {
+	return cv.provider
+}
+
+// SetLineProvider sets the LineProvider whose lines are shown.
+func (cv *CodeView) SetLineProvider(provider LineProvider) {
+	cv.detachProvider()
+
+	cv.provider = provider
+
+	if provider != nil {
+		cv.providerResetHandle = provider.LinesReset().Attach(cv.onLinesReset)
+		cv.providerInsertedHandle = provider.LinesInserted().Attach(cv.onLinesInserted)
+		cv.providerRemovedHandle = provider.LinesRemoved().Attach(cv.onLinesRemoved)
+	}
+
+	cv.onLinesReset()
+}
+
+func (cv *CodeView) detachProvider() {
+	if cv.provider == nil {
+		return
+	}
+
+	cv.provider.LinesReset().Detach(cv.providerResetHandle)
+	cv.provider.LinesInserted().Detach(cv.providerInsertedHandle)
+	cv.provider.LinesRemoved().Detach(cv.providerRemovedHandle)
+
+	cv.provider = nil
+}
+
+func (cv *CodeView) lineCount() int {
+	if cv.provider == nil {
+		return 0
+	}
+
+	return cv.provider.LineCount()
+}
+
+// Highlighter returns the Highlighter that splits lines into tokens, or nil
+// if lines are not highlighted.
+func (cv *CodeView) Highlighter() highlight.Highlighter {
+	return cv.highlighter
+}
+
+// SetHighlighter sets the Highlighter that splits lines into tokens.
+func (cv *CodeView) SetHighlighter(highlighter highlight.Highlighter) {
+	cv.highlighter = highlighter
+
+	cv.Invalidate()
+}
+
+// TokenColor returns the color of tokens of kind.
+func (cv *CodeView) TokenColor(kind highlight.Kind) Color {
+	if color, ok := cv.tokenColors[kind]; ok {
+		return color
+	}
+
+	return cv.tokenColors[highlight.Plain]
+}
+
+// SetTokenColor sets the color of tokens of kind. The color of
+// highlight.Plain is the one of all other text.
+func (cv *CodeView) SetTokenColor(kind highlight.Kind, color Color) {
+	cv.tokenColors[kind] = color
+
+	cv.Invalidate()
+}
+
+func (cv *CodeView) LineNumbersVisible() bool {
+	return cv.lineNumbersVisible
+}
+
+func (cv *CodeView) SetLineNumbersVisible(visible bool) {
+	cv.lineNumbersVisible = visible
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+// TabWidth returns the number of columns between tab stops.
+func (cv *CodeView) TabWidth() int {
+	return cv.tabWidth
+}
+
+// SetTabWidth sets the number of columns between tab stops.
+func (cv *CodeView) SetTabWidth(width int) {
+	cv.tabWidth = maxi(1, width)
+
+	cv.Invalidate()
+}
+
+// TopLine returns the index of the first visible line.
+func (cv *CodeView) TopLine() int {
+	return cv.topLine
+}
+
+// SetTopLine scrolls, so that the line at index is the first visible one,
+// or as close to it as possible.
+func (cv *CodeView) SetTopLine(index int) {
+	cv.scrollTo(index, false)
+}
+
+// EnsureLineVisible scrolls as little as needed to make the line at index
+// visible.
+func (cv *CodeView) EnsureLineVisible(index int) {
+	visible := cv.visibleLineCount()
+
+	if index < cv.topLine {
+		cv.scrollTo(index, false)
+	} else if index >= cv.topLine+visible {
+		cv.scrollTo(index-visible+1, false)
+	}
+}
+
+// CurrentLine returns the index of the current line, or -1 if there is
+// none.
+func (cv *CodeView) CurrentLine() int {
+	return cv.currentLine
+}
+
+// SetCurrentLine selects the line at index and makes it visible. Pass -1
+// to clear the selection.
+func (cv *CodeView) SetCurrentLine(index int) {
+	cv.setSelection(index, index)
+
+	if index >= 0 {
+		cv.EnsureLineVisible(index)
+	}
+}
+
+// CurrentLineChanged returns the event that is published when the current
+// line changed.
+func (cv *CodeView) CurrentLineChanged() *Event {
+	return cv.currentLineChangedPublisher.Event()
+}
+
+// SelectedLines returns the range of selected lines, or -1, -1 if no line
+// is selected.
+func (cv *CodeView) SelectedLines() (from, to int) {
+	if cv.currentLine < 0 {
+		return -1, -1
+	}
+
+	from, to = cv.anchorLine, cv.currentLine
+	if from > to {
+		from, to = to, from
+	}
+
+	return
+}
+
+// SetSelectedLines selects the lines from anchor to current, where current
+// becomes the current line.
+func (cv *CodeView) SetSelectedLines(anchor, current int) {
+	cv.setSelection(anchor, current)
+
+	if current >= 0 {
+		cv.EnsureLineVisible(current)
+	}
+}
+
+// SelectedText returns the selected lines, joined by line breaks.
+func (cv *CodeView) SelectedText() string {
+	from, to := cv.SelectedLines()
+	if from < 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for i := from; i <= to; i++ {
+		if i > from {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(cv.provider.Line(i))
+	}
+
+	return sb.String()
+}
+
+// Copy copies the selected lines to the clipboard.
+func (cv *CodeView) Copy() error {
+	if cv.currentLine < 0 {
+		return nil
+	}
+
+	return Clipboard().SetText(cv.SelectedText())
+}
+
+func (cv *CodeView) setSelection(anchor, current int) {
+	count := cv.lineCount()
+
+	if current < 0 || count == 0 {
+		anchor, current = -1, -1
+	} else {
+		anchor = maxi(0, mini(anchor, count-1))
+		current = maxi(0, mini(current, count-1))
+	}
+
+	changed := current != cv.currentLine
+
+	if anchor == cv.anchorLine && !changed {
+		return
+	}
+
+	cv.anchorLine = anchor
+	cv.currentLine = current
+
+	cv.Invalidate()
+
+	if changed {
+		cv.currentLineChangedPublisher.Publish()
+	}
+}
+
+// Bookmarks returns the indexes of the bookmarked lines in ascending order.
+func (cv *CodeView) Bookmarks() []int {
+	return append([]int(nil), cv.bookmarks...)
+}
+
+// IsBookmarked returns whether the line at index is bookmarked.
+func (cv *CodeView) IsBookmarked(index int) bool {
+	i := sort.SearchInts(cv.bookmarks, index)
+
+	return i < len(cv.bookmarks) && cv.bookmarks[i] == index
+}
+
+// SetBookmarked sets whether the line at index is bookmarked.
+func (cv *CodeView) SetBookmarked(index int, bookmarked bool) {
+	if index < 0 || index >= cv.lineCount() || bookmarked == cv.IsBookmarked(index) {
+		return
+	}
+
+	i := sort.SearchInts(cv.bookmarks, index)
+	if bookmarked {
+		cv.bookmarks = append(cv.bookmarks, 0)
+		copy(cv.bookmarks[i+1:], cv.bookmarks[i:])
+		cv.bookmarks[i] = index
+	} else {
+		cv.bookmarks = append(cv.bookmarks[:i], cv.bookmarks[i+1:]...)
+	}
+
+	cv.Invalidate()
+
+	cv.bookmarksChangedPublisher.Publish()
+}
+
+// ToggleBookmark toggles whether the line at index is bookmarked.
+func (cv *CodeView) ToggleBookmark(index int) {
+	cv.SetBookmarked(index, !cv.IsBookmarked(index))
+}
+
+// ClearBookmarks removes all bookmarks.
+func (cv *CodeView) ClearBookmarks() {
+	if len(cv.bookmarks) == 0 {
+		return
+	}
+
+	cv.bookmarks = nil
+
+	cv.Invalidate()
+
+	cv.bookmarksChangedPublisher.Publish()
+}
+
+// GoToNextBookmark makes the next bookmarked line after the current one
+// current, wrapping around at the end. It returns false if there are no
+// bookmarks.
+func (cv *CodeView) GoToNextBookmark() bool {
+	if len(cv.bookmarks) == 0 {
+		return false
+	}
+
+	i := sort.SearchInts(cv.bookmarks, cv.currentLine+1)
+	if i == len(cv.bookmarks) {
+		i = 0
+	}
+
+	cv.SetCurrentLine(cv.bookmarks[i])
+
+	return true
+}
+
+// GoToPreviousBookmark makes the previous bookmarked line before the
+// current one current, wrapping around at the start. It returns false if
+// there are no bookmarks.
+func (cv *CodeView) GoToPreviousBookmark() bool {
+	if len(cv.bookmarks) == 0 {
+		return false
+	}
+
+	current := cv.currentLine
+	if current < 0 {
+		current = cv.lineCount()
+	}
+
+	i := sort.SearchInts(cv.bookmarks, current) - 1
+	if i < 0 {
+		i = len(cv.bookmarks) - 1
+	}
+
+	cv.SetCurrentLine(cv.bookmarks[i])
+
+	return true
+}
+
+// BookmarksChanged returns the event that is published when lines were
+// bookmarked or their bookmarks removed.
+func (cv *CodeView) BookmarksChanged() *Event {
+	return cv.bookmarksChangedPublisher.Event()
+}
+
+// FollowTail returns whether the view keeps scrolled to the last line as
+// lines are appended.
+func (cv *CodeView) FollowTail() bool {
+	return cv.followTail
+}
+
+// SetFollowTail sets whether the view keeps scrolled to the last line as
+// lines are appended.
+//
+// The user ends following by scrolling up and resumes it by pressing
+// Ctrl+End.
+func (cv *CodeView) SetFollowTail(follow bool) {
+	if follow == cv.followTail {
+		return
+	}
+
+	cv.followTail = follow
+
+	if follow {
+		cv.scrollTo(cv.lineCount(), false)
+	}
+
+	cv.followTailChangedPublisher.Publish()
+}
+
+// FollowTailChanged returns the event that is published when following the
+// last line started or ended.
+func (cv *CodeView) FollowTailChanged() *Event {
+	return cv.followTailChangedPublisher.Event()
+}
+
+// SearchText returns the text whose matches are highlighted.
+func (cv *CodeView) SearchText() string {
+	return cv.searchText
+}
+
+// SetSearchText highlights all matches of text. Pass an empty text to
+// remove the highlights. The error is the one of FindRegexp.
+func (cv *CodeView) SetSearchText(text string, opts FindOptions) error {
+	opts.Backward = false
+
+	if text == cv.searchText && opts == cv.searchOptions {
+		return nil
+	}
+
+	re, err := FindRegexp(text, opts)
+
+	cv.searchText = text
+	cv.searchOptions = opts
+	cv.searchRegexp = re
+	cv.match.line = -1
+
+	cv.Invalidate()
+
+	return err
+}
+
+// lineMatches returns the non-empty matches of the search text in line.
+func (cv *CodeView) lineMatches(line string) [][]int {
+	var matches [][]int
+
+	for _, loc := range cv.searchRegexp.FindAllStringIndex(line, -1) {
+		if loc[0] < loc[1] {
+			matches = append(matches, loc)
+		}
+	}
+
+	return matches
+}
+
+// Find highlights all matches of text and goes to the next one after the
+// current match or line, or the previous one if opts.Backward is set. The
+// search wraps around. It returns whether there was a match.
+//
+// Find looks at every line until it finds a match, which can take a while
+// with a large LineProvider.
+func (cv *CodeView) Find(text string, opts FindOptions) bool {
+	cv.SetSearchText(text, opts)
+
+	count := cv.lineCount()
+	if cv.searchRegexp == nil || count == 0 {
+		return false
+	}
+
+	line, offset := cv.match.line, 0
+	if line >= 0 {
+		if opts.Backward {
+			offset = cv.match.start
+		} else {
+			offset = cv.match.end
+		}
+	} else {
+		line = maxi(cv.currentLine, cv.topLine)
+		if opts.Backward {
+			offset = len(cv.provider.Line(line))
+		}
+	}
+
+	for i := 0; i <= count; i++ {
+		text := cv.provider.Line(line)
+
+		var loc []int
+		for _, l := range cv.lineMatches(text) {
+			if opts.Backward && l[0] < offset {
+				loc = l
+			} else if !opts.Backward && l[0] >= offset {
+				loc = l
+				break
+			}
+		}
+
+		if loc != nil {
+			cv.goToMatch(codeViewMatch{line, loc[0], loc[1]})
+			return true
+		}
+
+		if opts.Backward {
+			line = (line + count - 1) % count
+			offset = len(cv.provider.Line(line))
+		} else {
+			line = (line + 1) % count
+			offset = 0
+		}
+	}
+
+	cv.match.line = -1
+	cv.Invalidate()
+
+	return false
+}
+
+func (cv *CodeView) goToMatch(match codeViewMatch) {
+	cv.match = match
+
+	// Appended lines would move the match out of view.
+	cv.SetFollowTail(false)
+
+	cv.SetCurrentLine(match.line)
+
+	// Scroll horizontally as needed to show the match.
+	cols := codeViewColumns(cv.provider.Line(match.line), cv.tabWidth)
+	cs := cv.charSize()
+	textWidth := cv.ClientBoundsPixels().Width - cv.textLeft()
+	left, right := cols[match.start]*cs.Width, cols[match.end]*cs.Width
+
+	cv.maxColumns = maxi(cv.maxColumns, cols[len(cols)-1])
+
+	if left < cv.scrollX {
+		cv.scrollX = left
+	} else if right > cv.scrollX+textWidth {
+		cv.scrollX = right - textWidth
+	}
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+func (cv *CodeView) onLinesReset() {
+	cv.topLine = 0
+	cv.scrollX = 0
+	cv.maxColumns = 0
+	cv.match.line = -1
+
+	if len(cv.bookmarks) > 0 {
+		cv.bookmarks = nil
+		cv.bookmarksChangedPublisher.Publish()
+	}
+
+	cv.setSelection(-1, -1)
+
+	if cv.followTail {
+		cv.topLine = cv.lineCount()
+	}
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+func (cv *CodeView) onLinesInserted(from, to int) {
+	n := to - from + 1
+
+	shift := func(index int) int {
+		if index >= from {
+			return index + n
+		}
+		return index
+	}
+
+	if cv.topLine > from {
+		cv.topLine += n
+	}
+
+	cv.shiftLines(shift)
+
+	if cv.followTail {
+		cv.topLine = cv.lineCount()
+	}
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+func (cv *CodeView) onLinesRemoved(from, to int) {
+	n := to - from + 1
+
+	// Lines that were removed go to -1.
+	shift := func(index int) int {
+		switch {
+		case index > to:
+			return index - n
+
+		case index >= from:
+			return -1
+		}
+		return index
+	}
+
+	if cv.topLine >= from {
+		cv.topLine = maxi(from, cv.topLine-n)
+	}
+
+	cv.shiftLines(shift)
+
+	if cv.followTail {
+		cv.topLine = cv.lineCount()
+	}
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+// shiftLines moves the line indexes that the view keeps, other than the top
+// line, after lines were inserted or removed.
+func (cv *CodeView) shiftLines(shift func(index int) int) {
+	if cv.match.line >= 0 {
+		cv.match.line = shift(cv.match.line)
+	}
+
+	if len(cv.bookmarks) > 0 {
+		bookmarks := cv.bookmarks[:0]
+		for _, b := range cv.bookmarks {
+			if b = shift(b); b >= 0 {
+				bookmarks = append(bookmarks, b)
+			}
+		}
+
+		removed := len(bookmarks) != len(cv.bookmarks)
+
+		cv.bookmarks = bookmarks
+
+		if removed {
+			cv.bookmarksChangedPublisher.Publish()
+		}
+	}
+
+	if cv.currentLine >= 0 {
+		anchor, current := shift(cv.anchorLine), shift(cv.currentLine)
+
+		if current < 0 {
+			cv.setSelection(-1, -1)
+		} else {
+			if anchor < 0 {
+				anchor = current
+			}
+
+			if current == cv.currentLine {
+				cv.anchorLine = anchor
+			} else {
+				cv.setSelection(anchor, current)
+			}
+		}
+	}
+}
+
+// charSize returns the size of a character of the font in native pixels.
+func (cv *CodeView) charSize() Size {
+	font, dpi := cv.Font(), cv.DPI()
+
+	if font != cv.charSizeFont || dpi != cv.charSizeDPI {
+		size := calculateTextSize("0", font, dpi, 0, cv.hWnd)
+
+		cv.charSizeCache = Size{maxi(1, size.Width), maxi(1, size.Height)}
+		cv.charSizeFont = font
+		cv.charSizeDPI = dpi
+	}
+
+	return cv.charSizeCache
+}
+
+// visibleLineCount returns the number of lines that fit completely.
+func (cv *CodeView) visibleLineCount() int {
+	return maxi(1, cv.ClientBoundsPixels().Height/cv.charSize().Height)
+}
+
+// gutterWidth returns the width of the bookmark margin and line numbers in
+// native pixels.
+func (cv *CodeView) gutterWidth() int {
+	cs := cv.charSize()
+
+	width := cs.Height
+	if cv.lineNumbersVisible {
+		width += (len(strconv.Itoa(cv.lineCount())) + 1) * cs.Width
+	}
+
+	return width
+}
+
+// textLeft returns where text starts when not scrolled, in native pixels.
+func (cv *CodeView) textLeft() int {
+	return cv.gutterWidth() + cv.charSize().Width/2
+}
+
+// scrollTo scrolls, so that the line at index is the first visible one, or
+// as close to it as possible. byUser tells whether the user scrolled, which
+// ends following the last line if it is scrolled out of view.
+func (cv *CodeView) scrollTo(index int, byUser bool) {
+	maxTopLine := maxi(0, cv.lineCount()-cv.visibleLineCount())
+	index = maxi(0, mini(index, maxTopLine))
+
+	if byUser && cv.followTail && index < maxTopLine {
+		cv.followTail = false
+		cv.followTailChangedPublisher.Publish()
+	}
+
+	if index == cv.topLine {
+		return
+	}
+
+	cv.topLine = index
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+func (cv *CodeView) scrollXTo(x int) {
+	cv.scrollX = x
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+// updateScrollBars clamps the scroll positions and updates the scroll bars
+// to match them.
+func (cv *CodeView) updateScrollBars() {
+	if cv.hWnd == 0 {
+		return
+	}
+
+	cs := cv.charSize()
+	cb := cv.ClientBoundsPixels()
+	count := cv.lineCount()
+	visible := cv.visibleLineCount()
+
+	cv.topLine = maxi(0, mini(cv.topLine, count-visible))
+
+	si := win.SCROLLINFO{
+		FMask: win.SIF_RANGE | win.SIF_PAGE | win.SIF_POS,
+		NMax:  int32(count - 1),
+		NPage: uint32(visible),
+		NPos:  int32(cv.topLine),
+	}
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	win.SetScrollInfo(cv.hWnd, win.SB_VERT, &si, true)
+
+	textWidth := maxi(0, cb.Width-cv.textLeft())
+	contentWidth := (cv.maxColumns + 1) * cs.Width
+
+	cv.scrollX = maxi(0, mini(cv.scrollX, contentWidth-textWidth))
+
+	si = win.SCROLLINFO{
+		FMask: win.SIF_RANGE | win.SIF_PAGE | win.SIF_POS,
+		NMax:  int32(contentWidth - 1),
+		NPage: uint32(textWidth),
+		NPos:  int32(cv.scrollX),
+	}
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	win.SetScrollInfo(cv.hWnd, win.SB_HORZ, &si, true)
+}
+
+// scrollBarPosition returns the position for a scroll bar request.
+func (cv *CodeView) scrollBarPosition(bar int32, request uint16, pos, line, page, end int) int {
+	switch request {
+	case win.SB_LINEUP:
+		return pos - line
+
+	case win.SB_LINEDOWN:
+		return pos + line
+
+	case win.SB_PAGEUP:
+		return pos - page
+
+	case win.SB_PAGEDOWN:
+		return pos + page
+
+	case win.SB_THUMBTRACK, win.SB_THUMBPOSITION:
+		// The 16-bit position of the message does not suffice for many
+		// lines.
+		si := win.SCROLLINFO{FMask: win.SIF_TRACKPOS}
+		si.CbSize = uint32(unsafe.Sizeof(si))
+		if win.GetScrollInfo(cv.hWnd, bar, &si) {
+			return int(si.NTrackPos)
+		}
+
+	case win.SB_TOP:
+		return 0
+
+	case win.SB_BOTTOM:
+		return end
+	}
+
+	return pos
+}
+
+// lineAt returns the index of the line at y, which may be out of range.
+func (cv *CodeView) lineAt(y int) int {
+	if y < 0 {
+		return cv.topLine - 1 - (-y-1)/cv.charSize().Height
+	}
+
+	return cv.topLine + y/cv.charSize().Height
+}
+
+func (cv *CodeView) handleKeyDown(key Key) {
+	count := cv.lineCount()
+	page := cv.visibleLineCount()
+	ctrl, shift := ControlDown(), ShiftDown()
+
+	moveTo := func(index int) {
+		if count == 0 {
+			return
+		}
+
+		anchor := index
+		if shift && cv.anchorLine >= 0 {
+			anchor = cv.anchorLine
+		}
+
+		cv.SetSelectedLines(anchor, index)
+
+		if index < count-1 {
+			cv.SetFollowTail(false)
+		}
+	}
+
+	switch key {
+	case KeyUp:
+		if ctrl {
+			cv.scrollTo(cv.topLine-1, true)
+		} else {
+			moveTo(maxi(0, cv.currentLine-1))
+		}
+
+	case KeyDown:
+		if ctrl {
+			cv.scrollTo(cv.topLine+1, true)
+		} else {
+			moveTo(cv.currentLine + 1)
+		}
+
+	case KeyPrior:
+		moveTo(maxi(0, cv.currentLine-page))
+
+	case KeyNext:
+		moveTo(cv.currentLine + page)
+
+	case KeyHome:
+		if ctrl {
+			moveTo(0)
+		} else {
+			cv.scrollXTo(0)
+		}
+
+	case KeyEnd:
+		if ctrl {
+			moveTo(count - 1)
+			cv.SetFollowTail(true)
+		} else {
+			cv.scrollXTo(cv.maxColumns * cv.charSize().Width)
+		}
+
+	case KeyLeft:
+		cv.scrollXTo(cv.scrollX - cv.charSize().Width)
+
+	case KeyRight:
+		cv.scrollXTo(cv.scrollX + cv.charSize().Width)
+
+	case KeyA:
+		if !ctrl {
+			return
+		}
+		cv.setSelection(0, count-1)
+
+	case KeyC, KeyInsert:
+		if !ctrl {
+			return
+		}
+		cv.Copy()
+
+	case KeyF2:
+		switch {
+		case ctrl:
+			cv.ToggleBookmark(cv.currentLine)
+
+		case shift:
+			cv.GoToPreviousBookmark()
+
+		default:
+			cv.GoToNextBookmark()
+		}
+
+	case KeyF3:
+		if cv.searchText == "" {
+			return
+		}
+		opts := cv.searchOptions
+		opts.Backward = shift
+		cv.Find(cv.searchText, opts)
+	}
+}
+
+func (cv *CodeView) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_SIZE:
+		cv.updateScrollBars()
+
+	case win.WM_VSCROLL:
+		page := cv.visibleLineCount()
+		cv.scrollTo(cv.scrollBarPosition(win.SB_VERT, win.LOWORD(uint32(wParam)), cv.topLine, 1, page, cv.lineCount()), true)
+		return 0
+
+	case win.WM_HSCROLL:
+		cs := cv.charSize()
+		page := maxi(cs.Width, cv.ClientBoundsPixels().Width-cv.textLeft())
+		cv.scrollXTo(cv.scrollBarPosition(win.SB_HORZ, win.LOWORD(uint32(wParam)), cv.scrollX, cs.Width, page, cv.maxColumns*cs.Width))
+		return 0
+
+	case win.WM_MOUSEWHEEL:
+		delta := int(int16(win.HIWORD(uint32(wParam))))
+
+		if ShiftDown() {
+			cv.scrollXTo(cv.scrollX - delta*codeViewWheelLines*cv.charSize().Width/120)
+		} else {
+			cv.scrollTo(cv.topLine-delta*codeViewWheelLines/120, true) // WHEEL_DELTA
+		}
+		return 0
+
+	case win.WM_LBUTTONDOWN:
+		cv.SetFocus()
+
+		x, y := int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))
+		line := cv.lineAt(y)
+
+		if line >= cv.lineCount() {
+			break
+		}
+
+		if x < cv.gutterWidth() {
+			cv.ToggleBookmark(line)
+			break
+		}
+
+		anchor := line
+		if wParam&win.MK_SHIFT != 0 && cv.anchorLine >= 0 {
+			anchor = cv.anchorLine
+		}
+
+		cv.setSelection(anchor, line)
+		cv.selecting = true
+
+	case win.WM_MOUSEMOVE:
+		if !cv.selecting {
+			break
+		}
+
+		line := cv.lineAt(int(win.GET_Y_LPARAM(lParam)))
+
+		cv.SetSelectedLines(cv.anchorLine, maxi(0, line))
+
+	case win.WM_LBUTTONUP, win.WM_CAPTURECHANGED:
+		cv.selecting = false
+
+	case win.WM_KEYDOWN:
+		cv.handleKeyDown(Key(wParam))
+
+	case win.WM_GETDLGCODE:
+		return win.DLGC_WANTARROWS
+	}
+
+	return cv.CustomWidget.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (cv *CodeView) applyFont(font *Font) {
+	cv.CustomWidget.applyFont(font)
+
+	cv.maxColumns = 0
+
+	cv.updateScrollBars()
+	cv.Invalidate()
+}
+
+func (cv *CodeView) drawContent(canvas *Canvas, updateBounds Rectangle) error {
+	cb := cv.ClientBoundsPixels()
+	cs := cv.charSize()
+	font := cv.Font()
+
+	brushes := make(map[Color]*SolidColorBrush)
+	defer func() {
+		for _, brush := range brushes {
+			brush.Dispose()
+		}
+	}()
+
+	fill := func(color Color, bounds Rectangle) error {
+		brush, ok := brushes[color]
+		if !ok {
+			var err error
+			if brush, err = NewSolidColorBrush(color); err != nil {
+				return err
+			}
+			brushes[color] = brush
+		}
+
+		return canvas.FillRectanglePixels(brush, bounds)
+	}
+
+	if err := fill(codeViewBackgroundColor, cb); err != nil {
+		return err
+	}
+
+	gutterWidth := cv.gutterWidth()
+	textLeft := cv.textLeft()
+	firstColumn := cv.scrollX / cs.Width
+	lastColumn := (cv.scrollX+cb.Width-textLeft)/cs.Width + 1
+	count := cv.lineCount()
+	maxColumns := cv.maxColumns
+	from, to := cv.SelectedLines()
+
+	for line, y := cv.topLine, 0; line < count && y < cb.Height; line, y = line+1, y+cs.Height {
+		if y+cs.Height <= updateBounds.Y || y >= updateBounds.Y+updateBounds.Height {
+			continue
+		}
+
+		text := cv.provider.Line(line)
+		display, cols := codeViewLayout(text, cv.tabWidth)
+		maxColumns = maxi(maxColumns, len(display))
+
+		row := Rectangle{gutterWidth, y, cb.Width - gutterWidth, cs.Height}
+
+		if line >= from && line <= to && from >= 0 {
+			if err := fill(codeViewSelectionColor, row); err != nil {
+				return err
+			}
+		}
+
+		// Highlight the matches of the search text.
+		if cv.searchRegexp != nil {
+			for _, loc := range cv.lineMatches(text) {
+				color := codeViewMatchColor
+				if line == cv.match.line && loc[0] == cv.match.start && loc[1] == cv.match.end {
+					color = codeViewCurrentMatchColor
+				}
+
+				x := textLeft - cv.scrollX + cols[loc[0]]*cs.Width
+				width := maxi(1, cols[loc[1]]-cols[loc[0]]) * cs.Width
+				if err := fill(color, Rectangle{x, y, width, cs.Height}); err != nil {
+					return err
+				}
+			}
+		}
+
+		drawRun := func(startCol, endCol int, kind highlight.Kind) error {
+			startCol, endCol = maxi(startCol, firstColumn), mini(endCol, lastColumn)
+			if startCol >= endCol {
+				return nil
+			}
+
+			bounds := Rectangle{textLeft - cv.scrollX + startCol*cs.Width, y, (endCol - startCol + 1) * cs.Width, cs.Height}
+
+			return canvas.DrawTextPixels(string(display[startCol:endCol]), font, cv.TokenColor(kind), bounds, TextLeft|TextSingleLine|TextNoPrefix|TextNoClip)
+		}
+
+		col := 0
+		if cv.highlighter != nil {
+			for _, token := range cv.highlighter.Highlight(text) {
+				start, end := cols[token.Start], cols[token.End]
+				if err := drawRun(col, start, highlight.Plain); err != nil {
+					return err
+				}
+				if err := drawRun(start, end, token.Kind); err != nil {
+					return err
+				}
+				col = end
+			}
+		}
+		if err := drawRun(col, len(display), highlight.Plain); err != nil {
+			return err
+		}
+	}
+
+	if err := cv.drawGutter(canvas, fill, Rectangle{0, 0, gutterWidth, cb.Height}); err != nil {
+		return err
+	}
+
+	if maxColumns > cv.maxColumns {
+		cv.maxColumns = maxColumns
+		cv.updateScrollBars()
+	}
+
+	return nil
+}
+
+func (cv *CodeView) drawGutter(canvas *Canvas, fill func(Color, Rectangle) error, bounds Rectangle) error {
+	if err := fill(codeViewGutterColor, bounds); err != nil {
+		return err
+	}
+
+	cs := cv.charSize()
+	count := cv.lineCount()
+
+	i := sort.SearchInts(cv.bookmarks, cv.topLine)
+
+	for line, y := cv.topLine, 0; line < count && y < bounds.Height; line, y = line+1, y+cs.Height {
+		if i < len(cv.bookmarks) && cv.bookmarks[i] == line {
+			i++
+
+			inset := cs.Height / 5
+			marker := Rectangle{inset, y + inset, cs.Height - inset*2, cs.Height - inset*2}
+			if err := fill(codeViewBookmarkColor, marker); err != nil {
+				return err
+			}
+		}
+
+		if cv.lineNumbersVisible {
+			numberBounds := Rectangle{cs.Height, y, bounds.Width - cs.Height - cs.Width/2, cs.Height}
+			if err := canvas.DrawTextPixels(strconv.Itoa(line+1), cv.Font(), codeViewLineNumberColor, numberBounds, TextRight|TextSingleLine|TextNoPrefix); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// codeViewLayout returns the characters of line as they are displayed, with
+// tabs expanded, and the column of each byte offset into line, including
+// the end.
+func codeViewLayout(line string, tabWidth int) (display []rune, cols []int) {
+	cols = make([]int, len(line)+1)
+
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+
+		for j := 0; j < size; j++ {
+			cols[i+j] = len(display)
+		}
+
+		switch {
+		case r == '\t':
+			for n := tabWidth - len(display)%tabWidth; n > 0; n-- {
+				display = append(display, ' ')
+			}
+
+		case r < ' ' || r == 0x7f:
+			// Control characters, including NUL, which Windows can't draw.
+			display = append(display, '·')
+
+		default:
+			display = append(display, r)
+		}
+
+		i += size
+	}
+
+	cols[len(line)] = len(display)
+
+	return display, cols
+}
+
+// codeViewColumns returns the column of each byte offset into line,
+// including the end.
+func codeViewColumns(line string, tabWidth int) []int {
+	_, cols := codeViewLayout(line, tabWidth)
+
+	return cols
+}
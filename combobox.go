@@ -42,6 +42,7 @@ type ComboBox struct {
 	editOrigWndProcPtr           uintptr
 	editing                      bool
 	persistent                   bool
+	completer                    *Completer
 }
 
 var comboBoxEditWndProcPtr uintptr
@@ -55,6 +56,12 @@ func init() {
 func comboBoxEditWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	cb := (*ComboBox)(unsafe.Pointer(win.GetWindowLongPtr(hwnd, win.GWLP_USERDATA)))
 
+	if cb.completer != nil {
+		if result, handled := cb.completer.handleEditMessage(msg, wParam, lParam); handled {
+			return result
+		}
+	}
+
 	switch msg {
 	case win.WM_GETDLGCODE:
 		if !cb.editing {
@@ -377,6 +384,10 @@ func (cb *ComboBox) SetModel(mdl interface{}) error {
 			return err
 		}
 
+		if obs, ok := mdl.(*ObservableSlice); ok {
+			obs.synchronizer.setWindow(cb)
+		}
+
 		if _, ok := mdl.([]string); !ok {
 			if badms, ok := model.(bindingAndDisplayMemberSetter); ok {
 				var bindingMember string
@@ -0,0 +1,260 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package complete matches and ranks completion candidates against the text
+// a user typed.
+//
+// Matching ignores case. Candidates that match better get a higher score;
+// among those that score the same, shorter candidates come first, then
+// the order of the candidates is kept.
+package complete
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Mode decides which candidates match a query.
+type Mode int
+
+const (
+	// Prefix matches candidates that start with the query.
+	Prefix Mode = iota
+
+	// Substring matches candidates that contain the query.
+	Substring
+
+	// Fuzzy matches candidates that contain the characters of the query in
+	// the same order, like "fb" matches "FooBar".
+	Fuzzy
+)
+
+// Match is a candidate that matched a query.
+type Match struct {
+	Index int // The index of the candidate.
+	Text  string
+	Score int
+}
+
+// Score returns how well candidate matches query in mode, and false if it
+// does not match. An empty query matches every candidate with a score of 0.
+func Score(mode Mode, query, candidate string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+
+	q := []rune(query)
+	c := []rune(candidate)
+
+	switch mode {
+	case Prefix:
+		return scorePrefix(q, c)
+
+	case Substring:
+		return scoreSubstring(q, c)
+
+	case Fuzzy:
+		return scoreFuzzy(q, c)
+	}
+
+	return 0, false
+}
+
+// Rank returns the candidates that match query in mode, best first. If query
+// is empty, all candidates are returned in their order, which suits a list
+// of recent entries. If max is greater than 0, at most max matches are
+// returned. Candidates that occur more than once are returned once, with the
+// index of the first.
+func Rank(mode Mode, query string, candidates []string, max int) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+
+	for i, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		if score, ok := Score(mode, query, candidate); ok {
+			matches = append(matches, Match{i, candidate, score})
+		}
+	}
+
+	if query != "" {
+		sortMatches(matches)
+	}
+
+	if max > 0 && len(matches) > max {
+		matches = matches[:max]
+	}
+
+	return matches
+}
+
+func sortMatches(matches []Match) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+
+		return len(a.Text) < len(b.Text)
+	})
+}
+
+const (
+	exactBonus     = 50 // The candidate is the query.
+	caseBonus      = 10 // The match has the same case as the query.
+	wordStartBonus = 25 // The match starts a word of the candidate.
+	positionWeight = 2  // Per character before the match, up to maxPenalty.
+	maxPenalty     = 50
+
+	fuzzyCharScore      = 10
+	fuzzyConsecutive    = 5
+	fuzzyWordStartBonus = 8
+	fuzzyGapPenalty     = 1
+	fuzzySameCase       = 1
+)
+
+func scorePrefix(q, c []rune) (int, bool) {
+	if !matchesAt(q, c, 0) {
+		return 0, false
+	}
+
+	score := 100
+	if len(q) == len(c) {
+		score += exactBonus
+	}
+	if sameCaseAt(q, c, 0) {
+		score += caseBonus
+	}
+
+	return score, true
+}
+
+func scoreSubstring(q, c []rune) (int, bool) {
+	// The first occurrence at the start of a word wins, else the first one.
+	best := -1
+	for i := 0; i+len(q) <= len(c); i++ {
+		if !matchesAt(q, c, i) {
+			continue
+		}
+
+		if best < 0 {
+			best = i
+		}
+		if isWordStart(c, i) {
+			best = i
+			break
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+
+	score := 100 - mini(best*positionWeight, maxPenalty)
+	if len(q) == len(c) {
+		score += exactBonus
+	}
+	if isWordStart(c, best) {
+		score += wordStartBonus
+	}
+	if sameCaseAt(q, c, best) {
+		score += caseBonus
+	}
+
+	return score, true
+}
+
+func scoreFuzzy(q, c []rune) (int, bool) {
+	score := 0
+	prev := -1
+
+	for _, r := range q {
+		i := prev + 1
+		for i < len(c) && !equalFold(r, c[i]) {
+			i++
+		}
+		if i == len(c) {
+			return 0, false
+		}
+
+		score += fuzzyCharScore
+		if r == c[i] {
+			score += fuzzySameCase
+		}
+		if i == prev+1 && prev >= 0 {
+			score += fuzzyConsecutive
+		}
+		if isWordStart(c, i) {
+			score += fuzzyWordStartBonus
+		}
+		if prev >= 0 {
+			score -= (i - prev - 1) * fuzzyGapPenalty
+		} else {
+			score -= mini(i*positionWeight, maxPenalty)
+		}
+
+		prev = i
+	}
+
+	if len(q) == len(c) {
+		score += exactBonus
+	}
+
+	return score, true
+}
+
+func matchesAt(q, c []rune, at int) bool {
+	if at+len(q) > len(c) {
+		return false
+	}
+
+	for i, r := range q {
+		if !equalFold(r, c[at+i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameCaseAt(q, c []rune, at int) bool {
+	for i, r := range q {
+		if r != c[at+i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalFold(a, b rune) bool {
+	return a == b || unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// isWordStart returns whether the rune at i starts a word of c, which is
+// after a separator or a change from lower to upper case.
+func isWordStart(c []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev, r := c[i-1], c[i]
+
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
+
+func mini(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
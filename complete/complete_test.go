@@ -0,0 +1,148 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package complete
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		mode      Mode
+		query     string
+		candidate string
+		score     int
+		ok        bool
+	}{
+		{Prefix, "", "anything", 0, true},
+		{Prefix, "foo", "Foobar", 100, true},
+		{Prefix, "Foo", "Foobar", 110, true},
+		{Prefix, "foo", "foo", 160, true},
+		{Prefix, "bar", "foobar", 0, false},
+		{Prefix, "foobarbaz", "foobar", 0, false},
+		{Prefix, "äö", "ÄÖÜ", 100, true},
+
+		{Substring, "bar", "fooBar", 119, true},
+		{Substring, "bar", "foobar", 104, true},
+		{Substring, "bar", "bar", 185, true},
+		{Substring, "bar", "foo bar", 127, true},
+		{Substring, "o", "foo", 108, true},
+		{Substring, "baz", "foobar", 0, false},
+
+		// Occurrences at the start of a word win over earlier ones.
+		{Substring, "ab", "xabAb", 119, true},
+
+		// The position penalty is limited.
+		{Substring, "z", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaz", 60, true},
+
+		{Fuzzy, "fb", "FooBar", 34, true},
+		{Fuzzy, "fo", "foo", 35, true},
+		{Fuzzy, "foo", "foo", 101, true},
+		{Fuzzy, "bf", "FooBar", 0, false},
+		{Fuzzy, "fbz", "FooBar", 0, false},
+	}
+
+	for _, test := range tests {
+		score, ok := Score(test.mode, test.query, test.candidate)
+		if ok != test.ok || ok && score != test.score {
+			t.Errorf("Score(%v, %q, %q): got %d, %t, want %d, %t", test.mode, test.query, test.candidate, score, ok, test.score, test.ok)
+		}
+	}
+}
+
+func TestScoreOrdering(t *testing.T) {
+	tests := []struct {
+		mode          Mode
+		query         string
+		better, worse string
+	}{
+		{Prefix, "foo", "foo", "foobar"},
+		{Prefix, "Foo", "Foobar", "foobar"},
+		{Substring, "bar", "barfoo", "foobar"},
+		{Substring, "bar", "fooBar", "foobar"},
+		{Substring, "bar", "xbar", "xxxxxxbar"},
+		{Fuzzy, "fb", "FooBar", "fooxbar"},
+		{Fuzzy, "fb", "fbar", "fxxxb"},
+	}
+
+	for _, test := range tests {
+		better, _ := Score(test.mode, test.query, test.better)
+		worse, _ := Score(test.mode, test.query, test.worse)
+		if better <= worse {
+			t.Errorf("%v %q: got %q scoring %d and %q scoring %d, want the first higher", test.mode, test.query, test.better, better, test.worse, worse)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := []string{"foobar", "Foo", "bar", "foo", "food", "Foo", "afoo"}
+
+	tests := []struct {
+		mode  Mode
+		query string
+		max   int
+		want  []Match
+	}{
+		{
+			// All unique candidates, in their order.
+			Prefix, "", 0,
+			[]Match{{0, "foobar", 0}, {1, "Foo", 0}, {2, "bar", 0}, {3, "foo", 0}, {4, "food", 0}, {6, "afoo", 0}},
+		},
+		{
+			Prefix, "", 2,
+			[]Match{{0, "foobar", 0}, {1, "Foo", 0}},
+		},
+		{
+			// The best score first, then shorter ones, then in order.
+			Prefix, "foo", 0,
+			[]Match{{3, "foo", 160}, {1, "Foo", 150}, {4, "food", 110}, {0, "foobar", 110}},
+		},
+		{
+			Prefix, "foo", 1,
+			[]Match{{3, "foo", 160}},
+		},
+		{
+			// Candidates that occur more than once count once.
+			Substring, "oo", 0,
+			[]Match{{1, "Foo", 108}, {3, "foo", 108}, {4, "food", 108}, {0, "foobar", 108}, {6, "afoo", 106}},
+		},
+		{
+			Fuzzy, "xyz", 0,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		got := Rank(test.mode, test.query, candidates, test.max)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Rank(%v, %q, %d): got %v, want %v", test.mode, test.query, test.max, got, test.want)
+		}
+	}
+}
+
+func TestIsWordStart(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []bool
+	}{
+		{"fooBar", []bool{true, false, false, true, false, false}},
+		{"foo_bar", []bool{true, false, false, false, true, false, false}},
+		{"a.b-c d", []bool{true, false, true, false, true, false, true}},
+		{"HTTPServer", []bool{true, false, false, false, false, false, false, false, false, false}},
+		{"v2Go", []bool{true, false, false, false}},
+		{"x 2", []bool{true, false, true}},
+		{"über Öl", []bool{true, false, false, false, false, true, false}},
+	}
+
+	for _, test := range tests {
+		c := []rune(test.s)
+		for i := range c {
+			if got := isWordStart(c, i); got != test.want[i] {
+				t.Errorf("isWordStart(%q, %d): got %t, want %t", test.s, i, got, test.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,789 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/lxn/walk/complete"
+	"github.com/lxn/win"
+)
+
+const completerPopupWindowClass = `\o/ Walk_CompleterPopup_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(completerPopupWindowClass)
+	})
+}
+
+const (
+	defaultMaxSuggestions        = 100
+	defaultMaxVisibleSuggestions = 8
+
+	completerPadding    = 4 // in 1/96" units
+	completerWheelLines = 3
+
+	maNoActivate = 3 // MA_NOACTIVATE
+)
+
+// CompletionFunc returns the candidates for the text of a Completer. It runs
+// on its own goroutine and should return soon after ctx is done.
+type CompletionFunc func(ctx context.Context, text string) ([]string, error)
+
+// completerEdit is implemented by the widgets a Completer can attach to.
+type completerEdit interface {
+	Widget
+	Text() string
+	SetText(text string) error
+	SetTextSelection(start, end int)
+	TextChanged() *Event
+	EditingFinished() *Event
+}
+
+// Completer suggests completions for the text of a LineEdit or an editable
+// ComboBox while the user types, in a popup list below it.
+//
+// Candidates come from a ListModel, a CompletionFunc or both, and are
+// matched and ranked with package complete. Up, Down, PageUp and PageDown
+// select a suggestion, Return or Tab accepts it and Escape hides the list.
+// Ctrl+Space shows the suggestions for the current text.
+type Completer struct {
+	edit                    completerEdit
+	hook                    **Completer
+	model                   ListModel
+	provider                CompletionFunc
+	mode                    complete.Mode
+	maxSuggestions          int
+	maxVisibleSuggestions   int
+	minTextLength           int
+	suggestions             []string
+	current                 int
+	top                     int
+	popup                   *completerPopup
+	cancelQuery             func()
+	updating                bool
+	suppressChar            bool
+	form                    Form
+	formBoundsChangedHandle int
+	textChangedHandle       int
+	editingFinishedHandle   int
+	acceptedPublisher       StringEventPublisher
+}
+
+// NewCompleter returns a new *Completer that completes the text of target,
+// which must be a *LineEdit or an editable *ComboBox.
+//
+// It has no candidates until a model or provider is set.
+func NewCompleter(target Widget) (*Completer, error) {
+	var hook **Completer
+	switch w := target.(type) {
+	case *LineEdit:
+		hook = &w.completer
+
+	case *ComboBox:
+		if !w.Editable() {
+			return nil, newError("ComboBox is not editable")
+		}
+		hook = &w.completer
+
+	default:
+		return nil, newError("target must be a *LineEdit or an editable *ComboBox")
+	}
+
+	if *hook != nil {
+		return nil, newError("target already has a Completer")
+	}
+
+	c := &Completer{
+		edit:                  target.(completerEdit),
+		hook:                  hook,
+		mode:                  complete.Prefix,
+		maxSuggestions:        defaultMaxSuggestions,
+		maxVisibleSuggestions: defaultMaxVisibleSuggestions,
+		minTextLength:         1,
+		current:               -1,
+	}
+
+	*hook = c
+
+	c.textChangedHandle = c.edit.TextChanged().Attach(c.onTextChanged)
+	c.editingFinishedHandle = c.edit.EditingFinished().Attach(c.onEditingFinished)
+
+	if c.form = ancestor(target); c.form != nil {
+		c.formBoundsChangedHandle = c.form.AsWindowBase().BoundsChanged().Attach(c.HideSuggestions)
+	}
+
+	target.Disposing().Attach(c.Dispose)
+
+	return c, nil
+}
+
+// NewPathCompleter returns a new *Completer that completes the paths of
+// files and directories, using PathCompletions.
+func NewPathCompleter(target Widget) (*Completer, error) {
+	c, err := NewCompleter(target)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetProvider(PathCompletions)
+
+	return c, nil
+}
+
+// NewHistoryCompleter returns a new *Completer that completes texts of
+// history that contain the text of target. For an empty text, all of history
+// is suggested, most recent first. Texts that are accepted or entered in
+// target are added to history.
+func NewHistoryCompleter(target Widget, history *HistoryModel) (*Completer, error) {
+	c, err := NewCompleter(target)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetModel(history)
+	c.SetMode(complete.Substring)
+	c.SetMinTextLength(0)
+
+	return c, nil
+}
+
+// Dispose detaches the Completer from its target.
+func (c *Completer) Dispose() {
+	if c.edit == nil {
+		return
+	}
+
+	c.stopQuery()
+
+	if c.popup != nil {
+		c.popup.Dispose()
+		c.popup = nil
+	}
+
+	c.edit.TextChanged().Detach(c.textChangedHandle)
+	c.edit.EditingFinished().Detach(c.editingFinishedHandle)
+
+	if c.form != nil {
+		c.form.AsWindowBase().BoundsChanged().Detach(c.formBoundsChangedHandle)
+		c.form = nil
+	}
+
+	*c.hook = nil
+	c.edit = nil
+}
+
+// Target returns the widget whose text is completed.
+func (c *Completer) Target() Widget {
+	if c.edit == nil {
+		return nil
+	}
+
+	return c.edit
+}
+
+// Model returns the ListModel whose values are candidates, or nil.
+func (c *Completer) Model() ListModel {
+	return c.model
+}
+
+// SetModel sets the ListModel whose values are candidates. Values that are
+// no strings are formatted with fmt.Sprint.
+func (c *Completer) SetModel(model ListModel) {
+	c.model = model
+}
+
+// Provider returns the CompletionFunc that returns candidates, or nil.
+func (c *Completer) Provider() CompletionFunc {
+	return c.provider
+}
+
+// SetProvider sets the CompletionFunc that returns candidates. Its
+// candidates follow those of the model.
+func (c *Completer) SetProvider(provider CompletionFunc) {
+	c.provider = provider
+}
+
+// Mode returns how candidates are matched against the text.
+//
+// By default this is complete.Prefix.
+func (c *Completer) Mode() complete.Mode {
+	return c.mode
+}
+
+// SetMode sets how candidates are matched against the text.
+func (c *Completer) SetMode(mode complete.Mode) {
+	c.mode = mode
+}
+
+// MaxSuggestions returns the maximum number of suggestions.
+//
+// By default this is 100.
+func (c *Completer) MaxSuggestions() int {
+	return c.maxSuggestions
+}
+
+// SetMaxSuggestions sets the maximum number of suggestions. 0 means any
+// number.
+func (c *Completer) SetMaxSuggestions(value int) {
+	c.maxSuggestions = value
+}
+
+// MaxVisibleSuggestions returns the number of suggestions the popup list
+// shows at a time.
+//
+// By default this is 8.
+func (c *Completer) MaxVisibleSuggestions() int {
+	return c.maxVisibleSuggestions
+}
+
+// SetMaxVisibleSuggestions sets the number of suggestions the popup list
+// shows at a time.
+func (c *Completer) SetMaxVisibleSuggestions(value int) {
+	c.maxVisibleSuggestions = maxi(1, value)
+}
+
+// MinTextLength returns the number of characters the text must have before
+// suggestions are shown while typing.
+//
+// By default this is 1.
+func (c *Completer) MinTextLength() int {
+	return c.minTextLength
+}
+
+// SetMinTextLength sets the number of characters the text must have before
+// suggestions are shown while typing.
+func (c *Completer) SetMinTextLength(value int) {
+	c.minTextLength = value
+}
+
+// Suggestions returns the suggestions for the text, best first.
+func (c *Completer) Suggestions() []string {
+	return c.suggestions
+}
+
+// SuggestionsVisible returns whether the popup list of suggestions is
+// shown.
+func (c *Completer) SuggestionsVisible() bool {
+	return c.popup != nil && c.popup.Visible()
+}
+
+// ShowSuggestions shows the suggestions for the text of the target, however
+// short it is.
+func (c *Completer) ShowSuggestions() {
+	if c.edit == nil {
+		return
+	}
+
+	c.query(c.edit.Text())
+}
+
+// HideSuggestions hides the popup list of suggestions.
+func (c *Completer) HideSuggestions() {
+	c.stopQuery()
+
+	if c.popup != nil {
+		c.popup.SetVisible(false)
+	}
+}
+
+// Accepted returns the event that is published with the suggestion that was
+// accepted, after it became the text of the target.
+func (c *Completer) Accepted() *StringEvent {
+	return c.acceptedPublisher.Event()
+}
+
+func (c *Completer) onTextChanged() {
+	if c.updating || !c.editFocused() {
+		return
+	}
+
+	text := c.edit.Text()
+
+	if utf8.RuneCountInString(text) < c.minTextLength {
+		c.HideSuggestions()
+		return
+	}
+
+	c.query(text)
+}
+
+func (c *Completer) onEditingFinished() {
+	if history, ok := c.model.(*HistoryModel); ok {
+		history.Add(c.edit.Text())
+	}
+}
+
+func (c *Completer) editFocused() bool {
+	focus := win.GetFocus()
+	hwnd := c.edit.Handle()
+
+	return focus == hwnd || win.GetParent(focus) == hwnd
+}
+
+// query shows the suggestions for text. With a provider, they are shown once
+// it returned, unless another query started meanwhile.
+func (c *Completer) query(text string) {
+	c.stopQuery()
+
+	candidates := c.modelCandidates()
+
+	if c.provider == nil {
+		c.showSuggestions(text, candidates)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelQuery = cancel
+
+	provider := c.provider
+	edit := c.edit
+
+	go func() {
+		provided, err := provider(ctx, text)
+
+		edit.Synchronize(func() {
+			if ctx.Err() != nil {
+				// Another query started or the Completer was disposed.
+				return
+			}
+
+			c.stopQuery()
+
+			if err != nil {
+				c.HideSuggestions()
+				return
+			}
+
+			c.showSuggestions(text, append(candidates, provided...))
+		})
+	}()
+}
+
+func (c *Completer) stopQuery() {
+	if c.cancelQuery != nil {
+		c.cancelQuery()
+		c.cancelQuery = nil
+	}
+}
+
+func (c *Completer) modelCandidates() []string {
+	if c.model == nil {
+		return nil
+	}
+
+	count := c.model.ItemCount()
+	candidates := make([]string, count)
+
+	for i := 0; i < count; i++ {
+		switch v := c.model.Value(i).(type) {
+		case string:
+			candidates[i] = v
+
+		default:
+			candidates[i] = fmt.Sprint(v)
+		}
+	}
+
+	return candidates
+}
+
+func (c *Completer) showSuggestions(text string, candidates []string) {
+	matches := complete.Rank(c.mode, text, candidates, c.maxSuggestions)
+
+	c.suggestions = make([]string, len(matches))
+	for i, m := range matches {
+		c.suggestions[i] = m.Text
+	}
+
+	c.current = -1
+	c.top = 0
+
+	// There is nothing to complete if the text is all that is suggested.
+	if len(c.suggestions) == 0 || len(c.suggestions) == 1 && c.suggestions[0] == text || !c.editFocused() {
+		c.HideSuggestions()
+		return
+	}
+
+	if c.popup == nil {
+		popup, err := newCompleterPopup(c)
+		if err != nil {
+			return
+		}
+		c.popup = popup
+	}
+
+	c.popup.showBelow(c.edit)
+}
+
+// setCurrent selects the suggestion at index, or none if index is -1.
+func (c *Completer) setCurrent(index int) {
+	c.current = maxi(-1, mini(index, len(c.suggestions)-1))
+
+	if c.current >= 0 {
+		visible := c.visibleCount()
+
+		if c.current < c.top {
+			c.top = c.current
+		} else if c.current >= c.top+visible {
+			c.top = c.current - visible + 1
+		}
+	}
+
+	c.popup.Invalidate()
+}
+
+func (c *Completer) scroll(rows int) {
+	c.top = maxi(0, mini(c.top+rows, len(c.suggestions)-c.visibleCount()))
+
+	c.popup.Invalidate()
+}
+
+func (c *Completer) visibleCount() int {
+	return mini(len(c.suggestions), c.maxVisibleSuggestions)
+}
+
+// accept makes the suggestion at index the text of the target.
+func (c *Completer) accept(index int) {
+	text := c.suggestions[index]
+
+	c.HideSuggestions()
+
+	c.updating = true
+	c.edit.SetText(text)
+	c.updating = false
+
+	end := len(utf16.Encode([]rune(text)))
+	c.edit.SetTextSelection(end, end)
+
+	if history, ok := c.model.(*HistoryModel); ok {
+		history.Add(text)
+	}
+
+	c.acceptedPublisher.Publish(text)
+}
+
+// handleEditMessage is called by the target before it handles a message. It
+// returns whether the message was handled, and the result then.
+func (c *Completer) handleEditMessage(msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	switch msg {
+	case win.WM_GETDLGCODE:
+		if c.SuggestionsVisible() {
+			return win.DLGC_WANTALLKEYS, true
+		}
+
+	case win.WM_KEYDOWN:
+		if c.handleKeyDown(Key(wParam)) {
+			return 0, true
+		}
+
+	case win.WM_CHAR:
+		if c.suppressChar {
+			c.suppressChar = false
+			return 0, true
+		}
+
+	case win.WM_MOUSEWHEEL:
+		if c.SuggestionsVisible() {
+			delta := int(int16(win.HIWORD(uint32(wParam))))
+			c.scroll(-delta * completerWheelLines / 120) // WHEEL_DELTA
+			return 0, true
+		}
+
+	case win.WM_KILLFOCUS:
+		c.HideSuggestions()
+	}
+
+	return 0, false
+}
+
+// handleKeyDown returns whether it consumed key. The character message that
+// follows a consumed key is suppressed.
+func (c *Completer) handleKeyDown(key Key) bool {
+	c.suppressChar = false
+
+	consumed := c.consumeKey(key)
+	if consumed {
+		c.suppressChar = true
+	}
+
+	return consumed
+}
+
+func (c *Completer) consumeKey(key Key) bool {
+	if !c.SuggestionsVisible() {
+		if key == KeySpace && ControlDown() {
+			c.ShowSuggestions()
+			return true
+		}
+
+		return false
+	}
+
+	switch key {
+	case KeyDown:
+		c.setCurrent(c.current + 1)
+
+	case KeyUp:
+		c.setCurrent(c.current - 1)
+
+	case KeyNext:
+		c.setCurrent(c.current + c.visibleCount())
+
+	case KeyPrior:
+		c.setCurrent(maxi(0, c.current-c.visibleCount()))
+
+	case KeyReturn, KeyTab:
+		if c.current < 0 {
+			// Without a selected suggestion, the key does what it does
+			// anyway.
+			c.HideSuggestions()
+			return false
+		}
+
+		c.accept(c.current)
+
+	case KeyEscape:
+		c.HideSuggestions()
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// completerPopup is the popup list of the suggestions of a Completer. It
+// never gets activated, so the target keeps the keyboard input focus.
+type completerPopup struct {
+	WindowBase
+	completer *Completer
+}
+
+func newCompleterPopup(c *Completer) (*completerPopup, error) {
+	p := &completerPopup{completer: c}
+
+	var owner Window
+	if c.form != nil {
+		owner = c.form
+	}
+
+	if err := InitWindow(
+		p,
+		owner,
+		completerPopupWindowClass,
+		win.WS_POPUP|win.WS_BORDER,
+		win.WS_EX_TOOLWINDOW|win.WS_EX_NOACTIVATE); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *completerPopup) rowHeight() int {
+	edit := p.completer.edit
+
+	return calculateTextSize("Ag", edit.Font(), edit.DPI(), 0, p.hWnd).Height + IntFrom96DPI(completerPadding, edit.DPI())
+}
+
+// showBelow shows the popup below target, or above it if there is no room on
+// the monitor below.
+func (p *completerPopup) showBelow(target Widget) {
+	c := p.completer
+	dpi := target.DPI()
+	padding := IntFrom96DPI(completerPadding, dpi)
+	border := 2 * int(win.GetSystemMetrics(win.SM_CYBORDER))
+
+	var r win.RECT
+	win.GetWindowRect(target.Handle(), &r)
+
+	width := int(r.Right - r.Left)
+	for _, s := range c.suggestions {
+		width = maxi(width, calculateTextSize(s, target.Font(), dpi, 0, p.hWnd).Width+2*padding+border)
+	}
+	width = mini(width, 2*int(r.Right-r.Left))
+
+	height := c.visibleCount()*p.rowHeight() + border
+
+	x, y := int(r.Left), int(r.Bottom)
+
+	var mi win.MONITORINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+	if win.GetMonitorInfo(win.MonitorFromWindow(target.Handle(), win.MONITOR_DEFAULTTONEAREST), &mi) {
+		work := mi.RcWork
+
+		if y+height > int(work.Bottom) {
+			y = int(r.Top) - height
+		}
+		if x+width > int(work.Right) {
+			x = maxi(int(work.Left), int(work.Right)-width)
+		}
+	}
+
+	win.SetWindowPos(
+		p.hWnd,
+		win.HWND_TOP,
+		int32(x),
+		int32(y),
+		int32(width),
+		int32(height),
+		win.SWP_NOACTIVATE|win.SWP_SHOWWINDOW)
+
+	p.visible = true
+
+	p.Invalidate()
+}
+
+// rowAt returns the index of the suggestion at the client coordinate y.
+func (p *completerPopup) rowAt(y int) int {
+	c := p.completer
+
+	row := c.top + y/p.rowHeight()
+	if row >= len(c.suggestions) {
+		return -1
+	}
+
+	return row
+}
+
+func (p *completerPopup) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_MOUSEACTIVATE:
+		return maNoActivate
+
+	case win.WM_MOUSEMOVE:
+		if row := p.rowAt(int(int16(win.HIWORD(uint32(lParam))))); row >= 0 && row != p.completer.current {
+			p.completer.setCurrent(row)
+		}
+		return 0
+
+	case win.WM_LBUTTONDOWN:
+		if row := p.rowAt(int(int16(win.HIWORD(uint32(lParam))))); row >= 0 {
+			p.completer.accept(row)
+		}
+		return 0
+
+	case win.WM_ERASEBKGND:
+		return 1
+
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		p.paint(canvas)
+
+		return 0
+	}
+
+	return p.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (p *completerPopup) paint(canvas *Canvas) error {
+	c := p.completer
+	edit := c.edit
+
+	bg, err := NewSolidColorBrush(Color(win.GetSysColor(win.COLOR_WINDOW)))
+	if err != nil {
+		return err
+	}
+	defer bg.Dispose()
+
+	hl, err := NewSolidColorBrush(Color(win.GetSysColor(win.COLOR_HIGHLIGHT)))
+	if err != nil {
+		return err
+	}
+	defer hl.Dispose()
+
+	cb := p.ClientBoundsPixels()
+	if err := canvas.FillRectanglePixels(bg, cb); err != nil {
+		return err
+	}
+
+	rowHeight := p.rowHeight()
+	padding := IntFrom96DPI(completerPadding, edit.DPI())
+
+	for i := 0; i < c.visibleCount() && c.top+i < len(c.suggestions); i++ {
+		index := c.top + i
+		bounds := Rectangle{0, i * rowHeight, cb.Width, rowHeight}
+
+		color := Color(win.GetSysColor(win.COLOR_WINDOWTEXT))
+		if index == c.current {
+			if err := canvas.FillRectanglePixels(hl, bounds); err != nil {
+				return err
+			}
+			color = Color(win.GetSysColor(win.COLOR_HIGHLIGHTTEXT))
+		}
+
+		bounds.X += padding
+		bounds.Width -= 2 * padding
+
+		if err := canvas.DrawTextPixels(c.suggestions[index], edit.Font(), color, bounds, TextLeft|TextVCenter|TextSingleLine|TextNoPrefix|TextEndEllipsis); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PathCompletions is a CompletionFunc that returns the paths of the files
+// and directories in the directory that text ends in, like all entries of
+// C:\Users\ for C:\Users\Jo. Paths of directories end with a separator.
+func PathCompletions(ctx context.Context, text string) ([]string, error) {
+	dir, _ := filepath.Split(text)
+	if dir == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer f.Close()
+
+	var paths []string
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		infos, err := f.Readdir(256)
+
+		for _, fi := range infos {
+			path := dir + fi.Name()
+			if fi.IsDir() {
+				path += string(filepath.Separator)
+			}
+
+			paths = append(paths, path)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
@@ -31,6 +31,8 @@ type ContainerBase struct {
 	dataBinder  *DataBinder
 	nextChildID int32
 	persistent  bool
+
+	layoutAnimationDuration time.Duration
 }
 
 func (cb *ContainerBase) AsWidgetBase() *WidgetBase {
@@ -183,6 +185,20 @@ func (cb *ContainerBase) SetPersistent(value bool) {
 	cb.persistent = value
 }
 
+// LayoutAnimationDuration returns the duration of the animation that moves
+// children of the *ContainerBase to their new bounds after a layout pass.
+//
+// By default this is 0, which means children are moved without animation.
+func (cb *ContainerBase) LayoutAnimationDuration() time.Duration {
+	return cb.layoutAnimationDuration
+}
+
+// SetLayoutAnimationDuration sets the duration of the animation that moves
+// children of the *ContainerBase to their new bounds after a layout pass.
+func (cb *ContainerBase) SetLayoutAnimationDuration(duration time.Duration) {
+	cb.layoutAnimationDuration = duration
+}
+
 func (cb *ContainerBase) SaveState() error {
 	return cb.forEachPersistableChild(func(p Persistable) error {
 		return p.SaveState()
@@ -195,6 +211,16 @@ func (cb *ContainerBase) RestoreState() error {
 	})
 }
 
+func (cb *ContainerBase) hasChildGraphicsEffects() bool {
+	for _, wb := range cb.children.items {
+		if wb.window.(Widget).GraphicsEffects().Len() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (cb *ContainerBase) doPaint() error {
 	var ps win.PAINTSTRUCT
 
@@ -277,7 +303,7 @@ func (cb *ContainerBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintp
 		}
 
 	case win.WM_PAINT:
-		if FocusEffect == nil && InteractionEffect == nil && ValidationErrorEffect == nil {
+		if FocusEffect == nil && InteractionEffect == nil && ValidationErrorEffect == nil && !cb.hasChildGraphicsEffects() {
 			break
 		}
 
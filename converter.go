@@ -0,0 +1,217 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Converter converts values between a DataBinder data source and a Property.
+//
+// Errors returned by ConvertBack are treated like validation errors, i.e.
+// they are presented by the ErrorPresenter of the DataBinder and prevent it
+// from submitting.
+type Converter interface {
+	// Convert converts a data source value to a value of the property.
+	Convert(value interface{}) (interface{}, error)
+
+	// ConvertBack converts a value of the property to a data source value.
+	ConvertBack(value interface{}) (interface{}, error)
+}
+
+// ScaleConverter converts between numbers that differ by a constant factor,
+// e.g. an int field holding cents and a NumberEdit showing euros.
+type ScaleConverter struct {
+	// Factor is what a data source value gets multiplied with. Zero is
+	// treated as 1.
+	Factor float64
+
+	// Round makes ConvertBack round to the nearest integer, which is what you
+	// want for integer data source fields.
+	Round bool
+}
+
+func (sc ScaleConverter) Convert(value interface{}) (interface{}, error) {
+	f64, err := float64FromNumber(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return f64 * sc.factor(), nil
+}
+
+func (sc ScaleConverter) ConvertBack(value interface{}) (interface{}, error) {
+	f64, err := float64FromNumber(value)
+	if err != nil {
+		return nil, err
+	}
+
+	f64 /= sc.factor()
+
+	if sc.Round {
+		f64 = math.Floor(f64 + 0.5)
+	}
+
+	return f64, nil
+}
+
+func (sc ScaleConverter) factor() float64 {
+	if sc.Factor == 0 {
+		return 1
+	}
+
+	return sc.Factor
+}
+
+// NumberStringConverter converts between numbers and their string
+// representation, e.g. for showing an int field in a LineEdit.
+type NumberStringConverter struct {
+	// Decimals is the number of decimal places of the string representation.
+	Decimals int
+}
+
+func (nsc NumberStringConverter) Convert(value interface{}) (interface{}, error) {
+	f64, err := float64FromNumber(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return strconv.FormatFloat(f64, 'f', nsc.Decimals, 64), nil
+}
+
+func (nsc NumberStringConverter) ConvertBack(value interface{}) (interface{}, error) {
+	s, _ := value.(string)
+
+	f64, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil, NewValidationError(
+			tr("Number Required", "walk"),
+			fmt.Sprintf(tr("'%s' is not a number.", "walk"), s))
+	}
+
+	return f64, nil
+}
+
+// TimeStringConverter converts between time.Time values and strings, using
+// the layout of the time package.
+type TimeStringConverter struct {
+	// Layout is the time layout, e.g. "2006-01-02". If empty, time.RFC3339
+	// is used.
+	Layout string
+}
+
+func (tsc TimeStringConverter) Convert(value interface{}) (interface{}, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return nil, newError(fmt.Sprintf("can't convert %T to string", value))
+	}
+
+	if t.IsZero() {
+		return "", nil
+	}
+
+	return t.Format(tsc.layout()), nil
+}
+
+func (tsc TimeStringConverter) ConvertBack(value interface{}) (interface{}, error) {
+	s, _ := value.(string)
+	if s = strings.TrimSpace(s); s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation(tsc.layout(), s, time.Local)
+	if err != nil {
+		return nil, NewValidationError(
+			tr("Invalid Date", "walk"),
+			fmt.Sprintf(tr("Please enter a date in the format '%s'.", "walk"), tsc.layout()))
+	}
+
+	return t, nil
+}
+
+func (tsc TimeStringConverter) layout() string {
+	if tsc.Layout == "" {
+		return time.RFC3339
+	}
+
+	return tsc.Layout
+}
+
+// UnixTimeConverter converts between integer Unix timestamps in seconds and
+// time.Time values, e.g. for showing an int64 field in a DateEdit.
+type UnixTimeConverter struct {
+}
+
+func (UnixTimeConverter) Convert(value interface{}) (interface{}, error) {
+	f64, err := float64FromNumber(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.Unix(int64(f64), 0), nil
+}
+
+func (UnixTimeConverter) ConvertBack(value interface{}) (interface{}, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return nil, newError(fmt.Sprintf("can't convert %T to time.Time", value))
+	}
+
+	return float64(t.Unix()), nil
+}
+
+// EnumConverter converts between the values of an enumeration type and
+// their names, e.g. for showing an enum field in a ComboBox with a []string
+// model.
+type EnumConverter struct {
+	// Names maps enumeration values to their names.
+	Names map[interface{}]string
+}
+
+func (ec EnumConverter) Convert(value interface{}) (interface{}, error) {
+	if name, ok := ec.Names[value]; ok {
+		return name, nil
+	}
+
+	return nil, newError(fmt.Sprintf("no name for enum value %v", value))
+}
+
+func (ec EnumConverter) ConvertBack(value interface{}) (interface{}, error) {
+	s, _ := value.(string)
+
+	for v, name := range ec.Names {
+		if name == s {
+			return v, nil
+		}
+	}
+
+	return nil, NewValidationError(
+		tr("Selection Required", "walk"),
+		fmt.Sprintf(tr("'%s' is not a valid choice.", "walk"), s))
+}
+
+func float64FromNumber(value interface{}) (float64, error) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	}
+
+	return 0, newError(fmt.Sprintf("can't convert %T to float64", value))
+}
@@ -10,6 +10,8 @@ import (
 	"unsafe"
 
 	"github.com/lxn/win"
+
+	"github.com/lxn/walk/displaylist"
 )
 
 const customWidgetWindowClass = `\o/ Walk_CustomWidget_Class \o/`
@@ -234,6 +236,33 @@ func (cw *CustomWidget) bufferedPaint(canvas *Canvas, updateBounds Rectangle) er
 	return err
 }
 
+// RecordPaint paints the widget on a recording *Canvas and returns what was
+// drawn, so it can be saved as SVG or PDF document.
+func (cw *CustomWidget) RecordPaint() (*displaylist.List, error) {
+	if cw.paint == nil && cw.paintPixels == nil {
+		return nil, newError("paint(Pixels) func is nil")
+	}
+
+	bounds := cw.ClientBoundsPixels()
+
+	canvas, err := NewRecordingCanvas(bounds.Size(), cw.DPI())
+	if err != nil {
+		return nil, err
+	}
+	defer canvas.Dispose()
+
+	if cw.paintPixels != nil {
+		err = cw.paintPixels(canvas, bounds)
+	} else {
+		err = cw.paint(canvas, RectangleTo96DPI(bounds, cw.DPI()))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return canvas.DisplayList(), nil
+}
+
 func (*CustomWidget) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	return NewGreedyLayoutItem()
 }
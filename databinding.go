@@ -9,13 +9,17 @@ package walk
 import (
 	"errors"
 	"fmt"
+	"log"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
 
 var (
 	errValidationFailed = errors.New("validation failed")
+	errIndexOutOfRange  = errors.New("index out of range")
+	errMapKeyNotFound   = errors.New("map key not found")
 )
 
 type ErrorPresenter interface {
@@ -32,11 +36,15 @@ type DataBinder struct {
 	path2Expression            map[string]Expression
 	errorPresenter             ErrorPresenter
 	dataSourceChangedPublisher EventPublisher
+	dataChangedPublisher       EventPublisher
 	canSubmitChangedPublisher  EventPublisher
 	submittedPublisher         EventPublisher
 	resetPublisher             EventPublisher
+	propertyChangedHandle      int
+	dataSourceGeneration       int
+	synchronizer               windowSynchronizer
 	autoSubmitDelay            time.Duration
-	autoSubmitTimer            *time.Timer
+	autoSubmitDebouncer        *Debouncer
 	autoSubmit                 bool
 	autoSubmitSuspended        bool
 	canSubmit                  bool
@@ -83,8 +91,8 @@ func (db *DataBinder) SetAutoSubmitSuspended(suspended bool) {
 	db.autoSubmitSuspended = suspended
 
 	if suspended {
-		if db.autoSubmitTimer != nil {
-			db.autoSubmitTimer.Stop()
+		if db.autoSubmitDebouncer != nil {
+			db.autoSubmitDebouncer.Cancel()
 		}
 	} else {
 		db.Submit()
@@ -111,7 +119,30 @@ func (db *DataBinder) SetDataSource(dataSource interface{}) error {
 		}
 	}
 
+	if notifier, ok := db.dataSource.(PropertyChangedNotifier); ok {
+		notifier.PropertyChanged().Detach(db.propertyChangedHandle)
+	}
+
 	db.dataSource = dataSource
+	db.dataSourceGeneration++
+
+	if notifier, ok := dataSource.(PropertyChangedNotifier); ok {
+		generation := db.dataSourceGeneration
+
+		db.propertyChangedHandle = notifier.PropertyChanged().Attach(func(name string) {
+			db.synchronizer.synchronize(func() {
+				// Ignore changes of a data source that has been replaced
+				// while this call was pending.
+				if generation != db.dataSourceGeneration {
+					return
+				}
+
+				if err := db.resetProperties(name); err != nil {
+					log.Print("walk - DataBinder - Error: ", err.Error())
+				}
+			})
+		})
+	}
 
 	db.dataSourceChangedPublisher.Publish()
 
@@ -127,7 +158,7 @@ func (dbre *dataBinderRootExpression) Value() interface{} {
 }
 
 func (dbre *dataBinderRootExpression) Changed() *Event {
-	return dbre.db.resetPublisher.Event()
+	return dbre.db.dataChangedPublisher.Event()
 }
 
 func (db *DataBinder) DataSourceChanged() *Event {
@@ -145,6 +176,12 @@ func (db *DataBinder) SetBoundWidgets(boundWidgets []Widget) {
 
 	db.boundWidgets = boundWidgets
 
+	if len(boundWidgets) > 0 {
+		db.synchronizer.setWindow(boundWidgets[0])
+	} else {
+		db.synchronizer.setWindow(nil)
+	}
+
 	db.property2Widget = make(map[Property]Widget)
 	db.property2ChangedHandle = make(map[Property]int)
 
@@ -165,15 +202,15 @@ func (db *DataBinder) SetBoundWidgets(boundWidgets []Widget) {
 
 				if db.autoSubmit && !db.autoSubmitSuspended {
 					if db.autoSubmitDelay > 0 {
-						if db.autoSubmitTimer == nil {
-							db.autoSubmitTimer = time.AfterFunc(db.autoSubmitDelay, func() {
-								widget.Synchronize(func() {
-									db.Submit()
-								})
+						if db.autoSubmitDebouncer == nil {
+							db.autoSubmitDebouncer = NewDebouncer(db.autoSubmitDelay, func() {
+								db.Submit()
 							})
 						} else {
-							db.autoSubmitTimer.Reset(db.autoSubmitDelay)
+							db.autoSubmitDebouncer.SetDelay(db.autoSubmitDelay)
 						}
+
+						db.autoSubmitDebouncer.Trigger()
 					} else {
 						v := reflect.ValueOf(db.dataSource)
 						field := db.fieldBoundToProperty(v, prop)
@@ -218,11 +255,20 @@ func (db *DataBinder) validateProperties() {
 
 	for _, prop := range db.properties {
 		validator := prop.Validator()
-		if validator == nil {
+		converter := propertyConverter(prop)
+		if validator == nil && converter == nil {
 			continue
 		}
 
-		err := validator.Validate(prop.Get())
+		var err error
+		if validator != nil {
+			err = validator.Validate(prop.Get())
+		}
+		if err == nil && converter != nil {
+			if value := prop.Get(); value != nil {
+				_, err = converter.ConvertBack(value)
+			}
+		}
 		if err != nil {
 			hasError = true
 		}
@@ -262,73 +308,119 @@ func (db *DataBinder) Reset() error {
 		db.inReset = false
 	}()
 
-	if err := db.forEach(func(prop Property, field DataField) error {
-		if f64, ok := prop.Get().(float64); ok {
-			switch v := field.Get().(type) {
-			case float32:
-				f64 = float64(v)
+	if err := db.forEach(db.resetProperty); err != nil {
+		return err
+	}
 
-			case float64:
-				f64 = v
+	db.validateProperties()
 
-			case int:
-				f64 = float64(v)
+	db.dirty = false
 
-			case int8:
-				f64 = float64(v)
+	db.dataChangedPublisher.Publish()
+	db.resetPublisher.Publish()
 
-			case int16:
-				f64 = float64(v)
+	return nil
+}
 
-			case int32:
-				f64 = float64(v)
+// resetProperties is like Reset, but only affects the properties bound to
+// the data source member with the specified name. This is used to handle
+// PropertyChangedNotifier notifications.
+func (db *DataBinder) resetProperties(name string) error {
+	if name == "" {
+		return db.Reset()
+	}
 
-			case int64:
-				f64 = float64(v)
+	db.inReset = true
+	dirty := db.dirty
+	defer func() {
+		db.inReset = false
+		db.dirty = dirty
+	}()
 
-			case uint:
-				f64 = float64(v)
+	dsv := reflect.ValueOf(db.dataSource)
 
-			case uint8:
-				f64 = float64(v)
+	for _, prop := range db.properties {
+		source, ok := prop.Source().(string)
+		if !ok || !pathStartsWith(source, name) {
+			continue
+		}
 
-			case uint16:
-				f64 = float64(v)
+		field := db.fieldBoundToProperty(dsv, prop)
+		if field == nil {
+			continue
+		}
 
-			case uint32:
-				f64 = float64(v)
+		if err := db.resetProperty(prop, field); err != nil {
+			return err
+		}
+	}
 
-			case uint64:
-				f64 = float64(v)
+	db.validateProperties()
 
-			case uintptr:
-				f64 = float64(v)
+	db.dataChangedPublisher.Publish()
 
-			default:
-				return newError(fmt.Sprintf("Field '%s': Can't convert %T to float64.", prop.Source().(string), field.Get()))
-			}
+	return nil
+}
 
-			if err := prop.Set(f64); err != nil {
-				return err
-			}
-		} else {
-			if err := prop.Set(field.Get()); err != nil {
-				return err
-			}
-		}
+func (db *DataBinder) resetProperty(prop Property, field DataField) error {
+	value := field.Get()
 
-		return nil
-	}); err != nil {
-		return err
+	if _, ok := field.(nilField); !ok && propertyConverter(prop) != nil {
+		var err error
+		if value, err = propertyConverter(prop).Convert(value); err != nil {
+			return newError(fmt.Sprintf("Field '%s': %s", prop.Source().(string), err.Error()))
+		}
 	}
 
-	db.validateProperties()
+	if f64, ok := prop.Get().(float64); ok {
+		switch v := value.(type) {
+		case float32:
+			f64 = float64(v)
 
-	db.dirty = false
+		case float64:
+			f64 = v
 
-	db.resetPublisher.Publish()
+		case int:
+			f64 = float64(v)
 
-	return nil
+		case int8:
+			f64 = float64(v)
+
+		case int16:
+			f64 = float64(v)
+
+		case int32:
+			f64 = float64(v)
+
+		case int64:
+			f64 = float64(v)
+
+		case uint:
+			f64 = float64(v)
+
+		case uint8:
+			f64 = float64(v)
+
+		case uint16:
+			f64 = float64(v)
+
+		case uint32:
+			f64 = float64(v)
+
+		case uint64:
+			f64 = float64(v)
+
+		case uintptr:
+			f64 = float64(v)
+
+		default:
+			return newError(fmt.Sprintf("Field '%s': Can't convert %T to float64.", prop.Source().(string), value))
+		}
+
+		return prop.Set(f64)
+	}
+
+	return prop.Set(value)
 }
 
 func (db *DataBinder) ResetFinished() *Event {
@@ -375,6 +467,13 @@ func (db *DataBinder) submitProperty(prop Property, field DataField) error {
 		return err
 	}
 
+	if converter := propertyConverter(prop); converter != nil {
+		var err error
+		if value, err = converter.ConvertBack(value); err != nil {
+			return err
+		}
+	}
+
 	return field.Set(value)
 }
 
@@ -413,7 +512,11 @@ func (db *DataBinder) fieldBoundToProperty(v reflect.Value, prop Property) DataF
 	}
 
 	f, err := dataFieldFromPath(v, source)
-	if err != nil {
+	if err == errIndexOutOfRange || err == errMapKeyNotFound {
+		// The item may be added to the slice or map later on, so we treat
+		// this like a missing data source.
+		return nilField{prop: prop}
+	} else if err != nil {
 		panic(fmt.Sprintf("invalid source '%s'", source))
 	}
 
@@ -433,20 +536,51 @@ type DataField interface {
 }
 
 func dataFieldFromPath(root reflect.Value, path string) (DataField, error) {
-	parent, value, err := reflectValueFromPath(root, path)
+	var writeBacks []mapWriteBack
+
+	parent, value, err := walkReflectPath(root, path, &writeBacks)
 	if err != nil {
 		return nil, err
 	}
 
 	// convert to DataField
-	if i, ok := value.Interface().(DataField); ok {
-		return i, nil
+	if value.IsValid() {
+		if i, ok := value.Interface().(DataField); ok {
+			return i, nil
+		}
+	}
+
+	field := &reflectField{parent: parent, value: value, writeBacks: writeBacks}
+
+	if parent.IsValid() && parent.Kind() == reflect.Map {
+		var name string
+		for p := path; p != ""; {
+			name, p = nextPathPart(p)
+		}
+
+		if field.key, err = mapKeyFromPathPart(parent.Type().Key(), name); err != nil {
+			return nil, err
+		}
 	}
 
-	return &reflectField{parent: parent, value: value, key: path[strings.LastIndexByte(path, '.')+1:]}, nil
+	return field, nil
 }
 
 func reflectValueFromPath(root reflect.Value, path string) (parent, value reflect.Value, err error) {
+	return walkReflectPath(root, path, nil)
+}
+
+// mapWriteBack stores an addressable copy of a struct map element back into
+// its map, as map elements themselves can't be modified.
+type mapWriteBack struct {
+	m, key, elem reflect.Value
+}
+
+// walkReflectPath follows path from root. If writeBacks is not nil, struct
+// elements of maps that the path continues into are replaced with
+// addressable copies, so their members can be set, and the copies are
+// appended to writeBacks, outermost first.
+func walkReflectPath(root reflect.Value, path string, writeBacks *[]mapWriteBack) (parent, value reflect.Value, err error) {
 	fullPath := path
 	value = root
 
@@ -460,10 +594,56 @@ func reflectValueFromPath(root reflect.Value, path string) (parent, value reflec
 			value = value.Elem()
 		}
 
+		if strings.HasPrefix(name, "[") {
+			switch value.Kind() {
+			case reflect.Array, reflect.Slice:
+				index, err := strconv.Atoi(name[1 : len(name)-1])
+				if err != nil {
+					return parent, value, fmt.Errorf("bad index: '%s', path: '%s'", name, fullPath)
+				}
+				if index < 0 || index >= value.Len() {
+					return parent, reflect.Value{}, errIndexOutOfRange
+				}
+
+				parent = value
+				value = value.Index(index)
+
+				continue
+
+			case reflect.Map:
+				// Handled below, together with the dotted notation.
+
+			default:
+				return parent, value, fmt.Errorf("can't index %s: '%s', path: '%s'", value.Kind(), name, fullPath)
+			}
+		}
+
 		switch value.Kind() {
 		case reflect.Map:
+			key, err := mapKeyFromPathPart(value.Type().Key(), name)
+			if err != nil {
+				return parent, value, fmt.Errorf("%s, path: '%s'", err.Error(), fullPath)
+			}
+
 			parent = value
-			value = value.MapIndex(reflect.ValueOf(name))
+			value = value.MapIndex(key)
+
+			if path == "" {
+				break
+			}
+
+			if !value.IsValid() {
+				// There is nothing to follow the rest of the path into.
+				return parent, value, errMapKeyNotFound
+			}
+
+			if writeBacks != nil && value.Kind() == reflect.Struct {
+				elem := reflect.New(value.Type()).Elem()
+				elem.Set(value)
+
+				*writeBacks = append(*writeBacks, mapWriteBack{parent, key, elem})
+				value = elem
+			}
 
 		case reflect.Struct:
 			parent = value
@@ -530,15 +710,105 @@ func reflectValueFromPath(root reflect.Value, path string) (parent, value reflec
 	return parent, value, nil
 }
 
+// nextPathPart splits off the first part of a binding path. A part is either
+// a member name, as in "Address.City", or an index expression in square
+// brackets, as in "Addresses[0]" or `Extra["color"]`, in which case the
+// returned part includes the brackets.
 func nextPathPart(p string) (next, remaining string) {
+	if strings.HasPrefix(p, "[") {
+		var quoted, escaped bool
+
+		for i, r := range p {
+			switch {
+			case escaped:
+				escaped = false
+
+			case quoted && r == '\\':
+				escaped = true
+
+			case r == '"':
+				quoted = !quoted
+
+			case !quoted && r == ']':
+				return p[:i+1], strings.TrimPrefix(p[i+1:], ".")
+			}
+		}
+
+		return p, ""
+	}
+
 	for i, r := range p {
-		if r == '.' {
+		switch r {
+		case '.':
 			return p[:i], p[i+1:]
+
+		case '[':
+			if i > 0 {
+				return p[:i], p[i:]
+			}
 		}
 	}
 	return p, ""
 }
 
+// pathStartsWith reports whether path refers to the member prefix or
+// something inside of it, as in "Address.City" or "Addresses[0]" for prefix
+// "Address" or "Addresses", respectively.
+func pathStartsWith(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+
+	rest := path[len(prefix):]
+
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+// mapKeyFromPathPart converts a path part, which may be a bare member name or
+// an index expression like [42] or ["color"], to a value of keyType.
+func mapKeyFromPathPart(keyType reflect.Type, part string) (reflect.Value, error) {
+	if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+		part = part[1 : len(part)-1]
+
+		if strings.HasPrefix(part, `"`) {
+			s, err := strconv.Unquote(part)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("bad map key: '%s'", part)
+			}
+			part = s
+		}
+	}
+
+	key := reflect.New(keyType).Elem()
+
+	switch keyType.Kind() {
+	case reflect.String:
+		key.SetString(part)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(part, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("bad map key: '%s'", part)
+		}
+		key.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(part, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("bad map key: '%s'", part)
+		}
+		key.SetUint(u)
+
+	case reflect.Interface:
+		key.Set(reflect.ValueOf(part))
+
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported map key type: %s", keyType)
+	}
+
+	return key, nil
+}
+
 type nilField struct {
 	prop Property
 }
@@ -560,9 +830,10 @@ func (f nilField) Zero() interface{} {
 }
 
 type reflectField struct {
-	parent reflect.Value
-	value  reflect.Value
-	key    string
+	parent     reflect.Value
+	value      reflect.Value
+	key        reflect.Value
+	writeBacks []mapWriteBack
 }
 
 func (f *reflectField) CanSet() bool {
@@ -574,38 +845,97 @@ func (f *reflectField) CanSet() bool {
 }
 
 func (f *reflectField) Get() interface{} {
+	if !f.value.IsValid() {
+		// A missing map entry yields the zero value of the map element type.
+		return f.Zero()
+	}
+
 	return f.value.Interface()
 }
 
 func (f *reflectField) Set(value interface{}) error {
 	if f.parent.IsValid() && f.parent.Kind() == reflect.Map {
-		f.parent.SetMapIndex(reflect.ValueOf(f.key), reflect.ValueOf(value))
+		elem := reflect.New(f.parent.Type().Elem()).Elem()
+		if err := setReflectValue(elem, value); err != nil {
+			return err
+		}
+
+		f.parent.SetMapIndex(f.key, elem)
+	} else if err := setReflectValue(f.value, value); err != nil {
+		return err
+	}
+
+	// Store the modified copies of struct map elements, innermost first.
+	for i := len(f.writeBacks) - 1; i >= 0; i-- {
+		wb := f.writeBacks[i]
+		wb.m.SetMapIndex(wb.key, wb.elem)
+	}
+
+	return nil
+}
+
+func (f *reflectField) Zero() interface{} {
+	if !f.value.IsValid() {
+		if f.parent.IsValid() && f.parent.Kind() == reflect.Map {
+			return reflect.Zero(f.parent.Type().Elem()).Interface()
+		}
+
+		return nil
+	}
+
+	return reflect.Zero(f.value.Type()).Interface()
+}
+
+func setReflectValue(v reflect.Value, value interface{}) error {
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
 		return nil
 	}
 
 	if f64, ok := value.(float64); ok {
-		switch f.value.Kind() {
+		switch v.Kind() {
 		case reflect.Float32, reflect.Float64:
-			f.value.SetFloat(f64)
+			v.SetFloat(f64)
+			return nil
 
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			f.value.SetInt(int64(f64))
+			v.SetInt(int64(f64))
+			return nil
 
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			f.value.SetUint(uint64(f64))
+			v.SetUint(uint64(f64))
+			return nil
+
+		case reflect.Interface:
+			// Assigned below.
 
 		default:
-			return newError(fmt.Sprintf("Can't convert float64 to %s.", f.value.Type().Name()))
+			return newError(fmt.Sprintf("Can't convert float64 to %s.", v.Type().Name()))
 		}
+	}
 
-		return nil
+	rv := reflect.ValueOf(value)
+	if !rv.Type().AssignableTo(v.Type()) {
+		if !isNumberKind(rv.Kind()) || !isNumberKind(v.Kind()) {
+			return newError(fmt.Sprintf("Can't convert %s to %s.", rv.Type(), v.Type()))
+		}
+
+		rv = rv.Convert(v.Type())
 	}
 
-	f.value.Set(reflect.ValueOf(value))
+	v.Set(rv)
 
 	return nil
 }
 
-func (f *reflectField) Zero() interface{} {
-	return reflect.Zero(f.value.Type()).Interface()
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+
+		return true
+	}
+
+	return false
 }
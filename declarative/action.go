@@ -39,7 +39,7 @@ func (a Action) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, e
 	if err := action.SetText(a.Text); err != nil {
 		return nil, err
 	}
-	if err := setActionImage(action, a.Image, builder.dpi); err != nil {
+	if err := setActionImage(action, a.Image); err != nil {
 		return nil, err
 	}
 
@@ -116,7 +116,7 @@ func (m Menu) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, err
 	if err := action.SetText(m.Text); err != nil {
 		return nil, err
 	}
-	if err := setActionImage(action, m.Image, builder.dpi); err != nil {
+	if err := setActionImage(action, m.Image); err != nil {
 		return nil, err
 	}
 
@@ -172,24 +172,12 @@ func addToActionList(list *walk.ActionList, actions []*walk.Action) error {
 	return nil
 }
 
-func setActionImage(action *walk.Action, image interface{}, dpi int) (err error) {
-	var img walk.Image
-
-	switch image.(type) {
-	case *walk.Bitmap:
-		if img, err = walk.BitmapFrom(image, dpi); err != nil {
-			return
-		}
-
-	case walk.ExtractableIcon, *walk.Icon:
-		if img, err = walk.IconFrom(image, dpi); err != nil {
-			return
-		}
-
-	default:
-		if img, err = walk.ImageFrom(image); err != nil {
-			return
-		}
+// setActionImage sets the image of action. Menus and tool bars render it for
+// their DPI, so it is not converted here.
+func setActionImage(action *walk.Action, image interface{}) error {
+	img, err := walk.ImageFrom(image)
+	if err != nil {
+		return err
 	}
 
 	return action.SetImage(img)
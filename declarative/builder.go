@@ -20,11 +20,13 @@ import (
 var (
 	conditionsByName = make(map[string]walk.Condition)
 	propertyRE       *regexp.Regexp
+	indexedPathRE    *regexp.Regexp
 )
 
 func init() {
 	walk.AppendToWalkInit(func() {
 		propertyRE = regexp.MustCompile("[A-Za-z]+[0-9A-Za-z]*(\\.[A-Za-z]+[0-9A-Za-z]*)+")
+		indexedPathRE = regexp.MustCompile(`^[A-Za-z]+[0-9A-Za-z]*(\.[A-Za-z]+[0-9A-Za-z]*|\[([0-9]+|"([^"\\]|\\.)*")\])*$`)
 	})
 }
 
@@ -326,6 +328,7 @@ func (b *Builder) InitWidget(d Widget, w walk.Window, customInit func() error) e
 
 	// Container
 	var db *walk.DataBinder
+	var dbDataSource Property
 	if wc, ok := w.(walk.Container); ok {
 		var layout Layout
 		if val := b.widgetValue.FieldByName("Layout"); val.IsValid() {
@@ -393,6 +396,7 @@ func (b *Builder) InitWidget(d Widget, w walk.Window, customInit func() error) e
 				return err
 			} else {
 				db = dataB
+				dbDataSource = dataBinder.DataSource
 
 				b.name2DataBinder[dataBinder.Name] = db
 
@@ -428,6 +432,10 @@ func (b *Builder) InitWidget(d Widget, w walk.Window, customInit func() error) e
 				// FIXME: Currently SetDataBinder must be called after initProperties.
 				wc.SetDataBinder(db)
 
+				if bd, ok := dbDataSource.(bindData); ok {
+					return b.bindDataSource(db, bd)
+				}
+
 				if db.DataSource() == nil {
 					return nil
 				}
@@ -643,6 +651,16 @@ func (b *Builder) initProperties() error {
 							return err
 						}
 					}
+
+					if val.converter != nil {
+						cp, ok := prop.(walk.ConvertibleProperty)
+						if !ok {
+							return fmt.Errorf("property %s does not support converters", sf.Name)
+						}
+						if err := cp.SetConverter(val.converter); err != nil {
+							return err
+						}
+					}
 				}
 
 				if err := prop.SetSource(src); err != nil {
@@ -663,7 +681,7 @@ func (b *Builder) initProperties() error {
 					continue
 				}
 
-				if err := prop.Set(val); err != nil {
+				if err := prop.Set(convertNumber(val, prop.Get())); err != nil {
 					return err
 				}
 			}
@@ -673,6 +691,51 @@ func (b *Builder) initProperties() error {
 	return nil
 }
 
+// bindDataSource makes the data source of db follow an expression like
+// Bind("peopleTV.CurrentItem"), so that a detail form shows the current item
+// of a TableView or ListBox.
+func (b *Builder) bindDataSource(db *walk.DataBinder, bd bindData) error {
+	expr, ok := b.conditionOrProperty(bd).(walk.Expression)
+	if !ok {
+		return fmt.Errorf(`invalid DataBinder.DataSource expression: "%s"`, bd.expression)
+	}
+
+	update := func() error {
+		if err := db.SetDataSource(expr.Value()); err != nil {
+			return err
+		}
+
+		return db.Reset()
+	}
+
+	expr.Changed().Attach(func() {
+		if err := update(); err != nil {
+			log.Printf(`walk - failed to update data source from "%s": %s`, bd.expression, err.Error())
+		}
+	})
+
+	return update()
+}
+
+// convertNumber converts val to the type of like, if both are numbers of
+// different types, e.g. an int from a UI document for a float64 property.
+func convertNumber(val, like interface{}) interface{} {
+	v, l := reflect.ValueOf(val), reflect.ValueOf(like)
+	if !v.IsValid() || !l.IsValid() || v.Type() == l.Type() {
+		return val
+	}
+
+	isNumber := func(kind reflect.Kind) bool {
+		return kind >= reflect.Int && kind <= reflect.Float64
+	}
+
+	if isNumber(v.Kind()) && isNumber(l.Kind()) {
+		return v.Convert(l.Type()).Interface()
+	}
+
+	return val
+}
+
 func (b *Builder) conditionOrProperty(data Property) interface{} {
 	switch val := data.(type) {
 	case bindData:
@@ -680,6 +743,12 @@ func (b *Builder) conditionOrProperty(data Property) interface{} {
 			return nil
 		}
 
+		if strings.ContainsRune(val.expression, '[') && indexedPathRE.MatchString(val.expression) {
+			// Something like Addresses[0].City or Extra["color"], which
+			// only a DataBinder can resolve.
+			return nil
+		}
+
 		e := &expression{
 			text:           val.expression,
 			subExprsByPath: subExpressions(make(map[string]walk.Expression)),
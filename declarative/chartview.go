@@ -0,0 +1,87 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type ChartKind int
+
+const (
+	ChartLine       = ChartKind(walk.ChartLine)
+	ChartBar        = ChartKind(walk.ChartBar)
+	ChartStackedBar = ChartKind(walk.ChartStackedBar)
+	ChartPie        = ChartKind(walk.ChartPie)
+)
+
+type ChartView struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// ChartView
+
+	AssignTo     **walk.ChartView
+	Kind         ChartKind
+	LegendHidden bool
+	Model        walk.ChartModel
+	SeriesColors []walk.Color
+}
+
+func (cv ChartView) Create(builder *Builder) error {
+	w, err := walk.NewChartView(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if cv.AssignTo != nil {
+		*cv.AssignTo = w
+	}
+
+	return builder.InitWidget(cv, w, func() error {
+		w.SetKind(walk.ChartKind(cv.Kind))
+		w.SetLegendVisible(!cv.LegendHidden)
+		w.SetModel(cv.Model)
+
+		if cv.SeriesColors != nil {
+			w.SetSeriesColors(cv.SeriesColors)
+		}
+
+		return nil
+	})
+}
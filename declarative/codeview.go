@@ -0,0 +1,94 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+	"github.com/lxn/walk/highlight"
+)
+
+type CodeView struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// CodeView
+
+	AssignTo             **walk.CodeView
+	FollowTail           Property
+	Highlighter          highlight.Highlighter
+	LineNumbersHidden    bool
+	LineProvider         walk.LineProvider
+	OnBookmarksChanged   walk.EventHandler
+	OnCurrentLineChanged walk.EventHandler
+	TabWidth             int
+}
+
+func (cv CodeView) Create(builder *Builder) error {
+	w, err := walk.NewCodeView(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if cv.AssignTo != nil {
+		*cv.AssignTo = w
+	}
+
+	return builder.InitWidget(cv, w, func() error {
+		w.SetHighlighter(cv.Highlighter)
+		w.SetLineNumbersVisible(!cv.LineNumbersHidden)
+
+		if cv.TabWidth > 0 {
+			w.SetTabWidth(cv.TabWidth)
+		}
+
+		if cv.LineProvider != nil {
+			w.SetLineProvider(cv.LineProvider)
+		}
+
+		if cv.OnBookmarksChanged != nil {
+			w.BookmarksChanged().Attach(cv.OnBookmarksChanged)
+		}
+
+		if cv.OnCurrentLineChanged != nil {
+			w.CurrentLineChanged().Attach(cv.OnCurrentLineChanged)
+		}
+
+		return nil
+	})
+}
@@ -40,7 +40,10 @@ func (db DataBinder) create() (*walk.DataBinder, error) {
 		b.SetErrorPresenter(ep)
 	}
 
-	b.SetDataSource(db.DataSource)
+	if _, ok := db.DataSource.(bindData); !ok {
+		// A Bind expression is resolved by the Builder, once all widgets exist.
+		b.SetDataSource(db.DataSource)
+	}
 
 	b.SetAutoSubmit(db.AutoSubmit)
 	b.SetAutoSubmitDelay(db.AutoSubmitDelay)
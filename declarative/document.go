@@ -0,0 +1,814 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxn/walk"
+)
+
+// A UI document describes the same trees of declarative values that are
+// usually written as Go composite literals, in a format like JSON or YAML:
+//
+//	{
+//		"type": "MainWindow",
+//		"AssignTo": "mainWindow",
+//		"Title": "People",
+//		"Layout": {"type": "VBox"},
+//		"Children": [
+//			{"type": "LineEdit", "Text": {"bind": "Name"}},
+//			{"type": "PushButton", "Text": "OK", "OnClicked": "okClicked"}
+//		]
+//	}
+//
+// Objects map to structs, with keys named like the struct fields. The "type"
+// key selects the struct type for fields of interface type, like Children
+// or Layout. Event handlers, AssignTo targets and other fields of func or
+// pointer type take the name of a value registered with a DocumentRegistry.
+// Such values may also be used for any other field by means of
+// {"ref": "name"}. Binding expressions are written as
+// {"bind": "Expression", "validators": [...], "converter": "name"}.
+// Durations may be given as strings like "500ms" and colors as "#rrggbb".
+
+// DocumentRegistry maps the names used in UI documents to Go types and values,
+// so that documents can be loaded without recompiling the application.
+type DocumentRegistry struct {
+	name2Type  map[string]reflect.Type
+	name2Value map[string]interface{}
+}
+
+// NewDocumentRegistry returns a new DocumentRegistry that knows all types of
+// this package.
+func NewDocumentRegistry() *DocumentRegistry {
+	r := &DocumentRegistry{
+		name2Type:  make(map[string]reflect.Type),
+		name2Value: make(map[string]interface{}),
+	}
+
+	for _, prototype := range documentPrototypes {
+		r.RegisterType(prototype)
+	}
+
+	return r
+}
+
+var documentPrototypes = []interface{}{
+	// Widgets
+	ChartView{}, CheckBox{}, CodeView{}, ComboBox{}, Composite{},
+	CustomWidget{}, DateEdit{}, DateLabel{}, FindBar{}, GradientComposite{},
+	GroupBox{}, HSeparator{}, HSpacer{}, HSplitter{}, ImageView{}, Label{},
+	LineEdit{}, LinkLabel{}, ListBox{}, NumberEdit{}, NumberLabel{},
+	PrintPreview{}, ProgressBar{}, PushButton{}, RadioButton{},
+	RadioButtonGroup{}, RadioButtonGroupBox{}, RichTextEdit{}, ScrollView{},
+	Slider{}, SplitButton{}, TabPage{}, TabWidget{}, TableView{},
+	TextEdit{}, TextLabel{}, ToolBar{}, ToolButton{}, TreeView{},
+	VSeparator{}, VSpacer{}, VSplitter{}, WebView{},
+
+	// Forms
+	Dialog{}, MainWindow{},
+
+	// Layouts
+	Flow{}, Grid{}, HBox{}, VBox{},
+
+	// Brushes
+	BitmapBrush{}, GradientBrush{}, HorizontalGradientBrush{},
+	SolidColorBrush{}, SystemColorBrush{}, TransparentBrush{},
+	VerticalGradientBrush{},
+
+	// Menu items
+	Action{}, ActionRef{}, Menu{}, Separator{},
+
+	// Validators and error presenters
+	Range{}, Regexp{}, SelRequired{}, ValidatorRef{}, ErrorPresenterRef{},
+	ToolTipErrorPresenter{},
+
+	// Images
+	SysDLLIcon{},
+}
+
+// RegisterType makes the type of prototype, which must be a struct, known by
+// its name, e.g. to use custom widgets in documents.
+func (r *DocumentRegistry) RegisterType(prototype interface{}) {
+	t := reflect.TypeOf(prototype)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("prototype must be a struct")
+	}
+
+	r.name2Type[t.Name()] = t
+}
+
+// Register makes value known by name. Typical values are event handlers,
+// AssignTo targets, models, data sources, images, conditions and converters.
+func (r *DocumentRegistry) Register(name string, value interface{}) {
+	if name == "" {
+		panic(`name == ""`)
+	}
+
+	r.name2Value[name] = value
+}
+
+// Value returns the value registered by name, or nil.
+func (r *DocumentRegistry) Value(name string) interface{} {
+	return r.name2Value[name]
+}
+
+// LoadJSON decodes a JSON UI document, e.g. into a MainWindow or Dialog that
+// can then be run as usual.
+func (r *DocumentRegistry) LoadJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return r.Decode(doc)
+}
+
+// LoadYAML decodes a YAML UI document, using the Unmarshal function of a YAML
+// package like gopkg.in/yaml.v2.
+func (r *DocumentRegistry) LoadYAML(data []byte, unmarshal func(in []byte, out interface{}) error) (interface{}, error) {
+	var doc interface{}
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return r.Decode(doc)
+}
+
+// SaveJSON encodes v, which typically is a MainWindow or Dialog, as a JSON UI
+// document.
+func (r *DocumentRegistry) SaveJSON(v interface{}) ([]byte, error) {
+	doc, err := r.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(doc, "", "\t")
+}
+
+// SaveYAML encodes v as a YAML UI document, using the Marshal function of a
+// YAML package like gopkg.in/yaml.v2.
+func (r *DocumentRegistry) SaveYAML(v interface{}, marshal func(in interface{}) ([]byte, error)) ([]byte, error) {
+	doc, err := r.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(doc)
+}
+
+// Decode builds a value of a registered type from doc, which is a UI document
+// in its generic form, as produced by decoders of formats like JSON or YAML.
+func (r *DocumentRegistry) Decode(doc interface{}) (interface{}, error) {
+	m, ok := documentMap(doc)
+	if !ok {
+		return nil, fmt.Errorf("document root must be an object")
+	}
+
+	v, err := r.decodeTyped(m, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Interface(), nil
+}
+
+// Encode converts v, a value of a registered type, to the generic form of a
+// UI document, that can be marshaled to formats like JSON or YAML. Event
+// handlers, pointers and other values that can't be represented otherwise
+// must have been registered by name.
+func (r *DocumentRegistry) Encode(v interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Struct || r.name2Type[rv.Type().Name()] != rv.Type() {
+		return nil, fmt.Errorf("can't encode %T, type not registered", v)
+	}
+
+	doc, err := r.encodeInterface(rv, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.(map[string]interface{}), nil
+}
+
+func (r *DocumentRegistry) decodeTyped(m map[string]interface{}, path string) (reflect.Value, error) {
+	typeName, _ := m["type"].(string)
+	if typeName == "" {
+		return reflect.Value{}, fmt.Errorf(`%s: missing "type"`, documentPath(path))
+	}
+
+	t, ok := r.name2Type[typeName]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf(`%s: unknown type "%s"`, documentPath(path), typeName)
+	}
+
+	v := reflect.New(t).Elem()
+
+	if err := r.decodeStruct(v, m, path); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return v, nil
+}
+
+func (r *DocumentRegistry) decodeStruct(v reflect.Value, m map[string]interface{}, path string) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "type" {
+			continue
+		}
+
+		fieldPath := documentFieldPath(path, key)
+
+		sf, ok := v.Type().FieldByName(key)
+		if !ok || sf.PkgPath != "" {
+			return fmt.Errorf("%s: unknown field", fieldPath)
+		}
+
+		if err := r.decodeValue(v.FieldByIndex(sf.Index), m[key], fieldPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *DocumentRegistry) decodeValue(v reflect.Value, doc interface{}, path string) error {
+	if doc == nil {
+		return nil
+	}
+
+	t := v.Type()
+
+	if m, ok := documentMap(doc); ok {
+		if name, ok := m["ref"].(string); ok && len(m) == 1 {
+			return r.assignRef(v, name, path)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Func, reflect.Ptr:
+		name, ok := doc.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected the name of a registered value", path)
+		}
+
+		return r.assignRef(v, name, path)
+
+	case reflect.Interface:
+		return r.decodeInterface(v, doc, path)
+
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			s, _ := doc.(string)
+			tm, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return fmt.Errorf("%s: %s", path, err.Error())
+			}
+
+			v.Set(reflect.ValueOf(tm))
+			return nil
+		}
+
+		m, ok := documentMap(doc)
+		if !ok {
+			return fmt.Errorf("%s: expected an object", path)
+		}
+
+		return r.decodeStruct(v, m, path)
+
+	case reflect.Slice:
+		items, ok := doc.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected a list", path)
+		}
+
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := r.decodeValue(s.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+		v.Set(s)
+		return nil
+
+	case reflect.Map:
+		m, ok := documentMap(doc)
+		if !ok || t.Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: expected an object", path)
+		}
+
+		mv := reflect.MakeMap(t)
+		for key, item := range m {
+			ev := reflect.New(t.Elem()).Elem()
+			if err := r.decodeValue(ev, item, documentFieldPath(path, key)); err != nil {
+				return err
+			}
+
+			mv.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), ev)
+		}
+
+		v.Set(mv)
+		return nil
+	}
+
+	return decodeScalar(v, doc, path)
+}
+
+func (r *DocumentRegistry) decodeInterface(v reflect.Value, doc interface{}, path string) error {
+	t := v.Type()
+
+	if m, ok := documentMap(doc); ok {
+		if _, ok := m["type"]; ok {
+			tv, err := r.decodeTyped(m, path)
+			if err != nil {
+				return err
+			}
+			if !tv.Type().Implements(t) {
+				return fmt.Errorf("%s: %s is not a %s", path, tv.Type().Name(), t.Name())
+			}
+
+			v.Set(tv)
+			return nil
+		}
+
+		if t.NumMethod() == 0 {
+			if expr, ok := m["bind"].(string); ok {
+				return r.decodeBind(v, m, expr, path)
+			}
+		}
+
+		return fmt.Errorf(`%s: expected "type", "ref" or "bind"`, path)
+	}
+
+	if t.NumMethod() > 0 {
+		return fmt.Errorf("%s: expected an object", path)
+	}
+
+	// Property and other interface{} fields get scalar values as is.
+	switch val := doc.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			v.Set(reflect.ValueOf(int(i)))
+		} else if f, err := val.Float64(); err == nil {
+			v.Set(reflect.ValueOf(f))
+		} else {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+
+	case string, bool, int, float64:
+		v.Set(reflect.ValueOf(val))
+
+	case int64:
+		v.Set(reflect.ValueOf(int(val)))
+
+	case uint64:
+		v.Set(reflect.ValueOf(int(val)))
+
+	default:
+		return fmt.Errorf("%s: unexpected %T", path, doc)
+	}
+
+	return nil
+}
+
+func (r *DocumentRegistry) decodeBind(v reflect.Value, m map[string]interface{}, expr string, path string) error {
+	var validators []Validator
+	if doc, ok := m["validators"]; ok {
+		vv := reflect.ValueOf(&validators).Elem()
+		if err := r.decodeValue(vv, doc, path+".validators"); err != nil {
+			return err
+		}
+	}
+
+	var converter walk.Converter
+	if doc, ok := m["converter"]; ok {
+		name, _ := doc.(string)
+		if err := r.assignRef(reflect.ValueOf(&converter).Elem(), name, path+".converter"); err != nil {
+			return err
+		}
+	}
+
+	for key := range m {
+		switch key {
+		case "bind", "validators", "converter":
+
+		default:
+			return fmt.Errorf("%s.%s: unknown binding option", path, key)
+		}
+	}
+
+	v.Set(reflect.ValueOf(BindConverted(expr, converter, validators...)))
+
+	return nil
+}
+
+func (r *DocumentRegistry) assignRef(v reflect.Value, name, path string) error {
+	value, ok := r.name2Value[name]
+	if !ok {
+		return fmt.Errorf(`%s: no value registered as "%s"`, path, name)
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+
+	case rv.Kind() == reflect.Func && rv.Type().ConvertibleTo(v.Type()):
+		rv = rv.Convert(v.Type())
+
+	default:
+		return fmt.Errorf(`%s: "%s" is a %s, not a %s`, path, name, rv.Type(), v.Type())
+	}
+
+	v.Set(rv)
+
+	return nil
+}
+
+func decodeScalar(v reflect.Value, doc interface{}, path string) error {
+	t := v.Type()
+
+	if s, ok := doc.(string); ok {
+		switch {
+		case t == reflect.TypeOf(time.Duration(0)):
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("%s: %s", path, err.Error())
+			}
+
+			v.SetInt(int64(d))
+			return nil
+
+		case t == reflect.TypeOf(walk.Color(0)) && strings.HasPrefix(s, "#") && len(s) == 7:
+			rgb, err := strconv.ParseUint(s[1:], 16, 32)
+			if err != nil {
+				return fmt.Errorf("%s: %s", path, err.Error())
+			}
+
+			v.Set(reflect.ValueOf(walk.RGB(byte(rgb>>16), byte(rgb>>8), byte(rgb))))
+			return nil
+
+		case t.Kind() == reflect.String:
+			v.SetString(s)
+			return nil
+		}
+
+		return fmt.Errorf("%s: unexpected string", path)
+	}
+
+	if b, ok := doc.(bool); ok {
+		if t.Kind() != reflect.Bool {
+			return fmt.Errorf("%s: unexpected bool", path)
+		}
+
+		v.SetBool(b)
+		return nil
+	}
+
+	var f64 float64
+	switch val := doc.(type) {
+	case json.Number:
+		var err error
+		if f64, err = val.Float64(); err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+
+	case int:
+		f64 = float64(val)
+
+	case int64:
+		f64 = float64(val)
+
+	case uint64:
+		f64 = float64(val)
+
+	case float64:
+		f64 = val
+
+	default:
+		return fmt.Errorf("%s: unexpected %T", path, doc)
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(f64))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(f64))
+
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f64)
+
+	default:
+		return fmt.Errorf("%s: unexpected number", path)
+	}
+
+	return nil
+}
+
+func (r *DocumentRegistry) encodeValue(v reflect.Value, path string) (interface{}, error) {
+	if isZeroValue(v) {
+		return nil, nil
+	}
+
+	t := v.Type()
+
+	switch t.Kind() {
+	case reflect.Func, reflect.Ptr:
+		name, ok := r.nameOf(v)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s value is not registered", path, t)
+		}
+
+		return name, nil
+
+	case reflect.Interface:
+		return r.encodeInterface(v.Elem(), path)
+
+	case reflect.Struct:
+		if tm, ok := v.Interface().(time.Time); ok {
+			return tm.Format(time.RFC3339), nil
+		}
+
+		m := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			fv, err := r.encodeValue(v.Field(i), documentFieldPath(path, sf.Name))
+			if err != nil {
+				return nil, err
+			}
+			if fv != nil {
+				m[sf.Name] = fv
+			}
+		}
+
+		if len(m) == 0 {
+			return nil, nil
+		}
+
+		return m, nil
+
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := r.encodeValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			if item == nil {
+				// Keeps the type of an otherwise empty item, like VSpacer{}.
+				item = map[string]interface{}{}
+			}
+
+			items[i] = item
+		}
+
+		return items, nil
+
+	case reflect.Map:
+		m := make(map[string]interface{})
+		for _, key := range v.MapKeys() {
+			item, err := r.encodeValue(v.MapIndex(key), documentFieldPath(path, key.String()))
+			if err != nil {
+				return nil, err
+			}
+
+			m[key.String()] = item
+		}
+
+		return m, nil
+
+	case reflect.Bool:
+		return v.Bool(), nil
+
+	case reflect.String:
+		return v.String(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(v.Int()).String(), nil
+		}
+
+		return v.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if c, ok := v.Interface().(walk.Color); ok {
+			return fmt.Sprintf("#%02x%02x%02x", c.R(), c.G(), c.B()), nil
+		}
+
+		return v.Uint(), nil
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+
+	return nil, fmt.Errorf("%s: can't encode %s", path, t)
+}
+
+func (r *DocumentRegistry) encodeInterface(v reflect.Value, path string) (interface{}, error) {
+	if bd, ok := v.Interface().(bindData); ok {
+		m := map[string]interface{}{"bind": bd.expression}
+
+		if bd.validator != nil {
+			var validators []Validator
+			if mv, ok := bd.validator.(dMultiValidator); ok {
+				validators = mv.validators
+			} else {
+				validators = []Validator{bd.validator}
+			}
+
+			vs, err := r.encodeValue(reflect.ValueOf(validators), path+".validators")
+			if err != nil {
+				return nil, err
+			}
+
+			m["validators"] = vs
+		}
+
+		if bd.converter != nil {
+			name, ok := r.nameOf(reflect.ValueOf(bd.converter))
+			if !ok {
+				return nil, fmt.Errorf("%s.converter: value is not registered", path)
+			}
+
+			m["converter"] = name
+		}
+
+		return m, nil
+	}
+
+	if t := v.Type(); t.Kind() == reflect.Struct {
+		if typ, ok := r.name2Type[t.Name()]; ok && typ == t {
+			doc, err := r.encodeValue(v, path)
+			if err != nil {
+				return nil, err
+			}
+
+			m, _ := doc.(map[string]interface{})
+			if m == nil {
+				m = make(map[string]interface{})
+			}
+			m["type"] = t.Name()
+
+			return m, nil
+		}
+	}
+
+	// Numbers decode as int or float64, whatever their kind was.
+	switch v.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Float64:
+		return v.Interface(), nil
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+
+	case reflect.Float32:
+		return v.Float(), nil
+	}
+
+	if name, ok := r.nameOf(v); ok {
+		return map[string]interface{}{"ref": name}, nil
+	}
+
+	return nil, fmt.Errorf("%s: %s value is not registered", path, v.Type())
+}
+
+// nameOf returns the name of the registered value v. Funcs are compared by
+// their code pointer, so distinct closures of the same function literal
+// can't be told apart.
+func (r *DocumentRegistry) nameOf(v reflect.Value) (string, bool) {
+	names := make([]string, 0, len(r.name2Value))
+	for name := range r.name2Value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rv := reflect.ValueOf(r.name2Value[name])
+		if !rv.IsValid() {
+			continue
+		}
+
+		switch {
+		case v.Kind() == reflect.Func && rv.Kind() == reflect.Func:
+			if v.Pointer() == rv.Pointer() {
+				return name, true
+			}
+
+		case rv.Type() == v.Type() && v.Type().Comparable():
+			if rv.Interface() == v.Interface() {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Func, reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZeroValue(v.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZeroValue(v.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+
+	case reflect.Bool:
+		return !v.Bool()
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+
+	case reflect.String:
+		return v.Len() == 0
+	}
+
+	return false
+}
+
+// documentMap returns doc as a map with string keys. YAML decoders may
+// produce maps with keys of type interface{}.
+func documentMap(doc interface{}) (map[string]interface{}, bool) {
+	switch m := doc.(type) {
+	case map[string]interface{}:
+		return m, true
+
+	case map[interface{}]interface{}:
+		sm := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			sm[fmt.Sprint(k)] = v
+		}
+
+		return sm, true
+	}
+
+	return nil, false
+}
+
+func documentFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func documentPath(path string) string {
+	if path == "" {
+		return "document root"
+	}
+
+	return path
+}
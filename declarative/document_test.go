@@ -0,0 +1,363 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lxn/walk"
+)
+
+const testDocument = `{
+	"type": "MainWindow",
+	"AssignTo": "mainWindow",
+	"Title": "People",
+	"Layout": {"type": "VBox", "Spacing": 4},
+	"Children": [
+		{
+			"type": "LineEdit",
+			"MaxLength": 20,
+			"Text": {
+				"bind": "Name",
+				"validators": [{"type": "Range", "Min": 1, "Max": 10.5}],
+				"converter": "upper"
+			}
+		},
+		{
+			"type": "Composite",
+			"Layout": {"type": "HBox"},
+			"Children": [
+				{"type": "HSpacer"},
+				{"type": "PushButton", "Text": "OK", "OnClicked": "okClicked"},
+				{"type": "NumberEdit", "Value": {"ref": "answer"}}
+			]
+		}
+	]
+}`
+
+type testConverter struct{}
+
+func (testConverter) Convert(value interface{}) (interface{}, error) {
+	return value, nil
+}
+
+func (testConverter) ConvertBack(value interface{}) (interface{}, error) {
+	return value, nil
+}
+
+func newTestRegistry(mw **walk.MainWindow, clicked *bool) *DocumentRegistry {
+	r := NewDocumentRegistry()
+
+	r.Register("mainWindow", mw)
+	r.Register("okClicked", func() { *clicked = true })
+	r.Register("upper", testConverter{})
+	r.Register("answer", 42)
+
+	return r
+}
+
+// yamlUnmarshal stands in for the Unmarshal function of gopkg.in/yaml.v2. It
+// only reads the JSON subset of YAML, but produces the same generic values,
+// i.e. maps with keys of type interface{} and plain ints.
+func yamlUnmarshal(in []byte, out interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(in))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	var convert func(doc interface{}) interface{}
+	convert = func(doc interface{}) interface{} {
+		switch val := doc.(type) {
+		case map[string]interface{}:
+			m := make(map[interface{}]interface{}, len(val))
+			for k, v := range val {
+				m[k] = convert(v)
+			}
+			return m
+
+		case []interface{}:
+			for i, v := range val {
+				val[i] = convert(v)
+			}
+			return val
+
+		case json.Number:
+			if i, err := val.Int64(); err == nil {
+				return int(i)
+			}
+			f, _ := val.Float64()
+			return f
+		}
+
+		return doc
+	}
+
+	*out.(*interface{}) = convert(doc)
+
+	return nil
+}
+
+func checkTestDocument(t *testing.T, v interface{}, mw **walk.MainWindow, clicked *bool) {
+	t.Helper()
+
+	main, ok := v.(MainWindow)
+	if !ok {
+		t.Fatalf("got %T, want MainWindow", v)
+	}
+
+	if main.AssignTo != mw {
+		t.Errorf("AssignTo: got %p, want %p", main.AssignTo, mw)
+	}
+	if main.Title != "People" {
+		t.Errorf("Title: got %v, want People", main.Title)
+	}
+	if got, want := main.Layout, (VBox{Spacing: 4}); got != want {
+		t.Errorf("Layout: got %#v, want %#v", got, want)
+	}
+	if len(main.Children) != 2 {
+		t.Fatalf("len(Children): got %d, want 2", len(main.Children))
+	}
+
+	le, ok := main.Children[0].(LineEdit)
+	if !ok {
+		t.Fatalf("Children[0]: got %T, want LineEdit", main.Children[0])
+	}
+	if le.MaxLength != 20 {
+		t.Errorf("MaxLength: got %d, want 20", le.MaxLength)
+	}
+	bd, ok := le.Text.(bindData)
+	if !ok {
+		t.Fatalf("Text: got %T, want bindData", le.Text)
+	}
+	if bd.expression != "Name" {
+		t.Errorf("Text expression: got %q, want Name", bd.expression)
+	}
+	if got, want := bd.validator, (Range{Min: 1, Max: 10.5}); got != want {
+		t.Errorf("Text validator: got %#v, want %#v", got, want)
+	}
+	if _, ok := bd.converter.(testConverter); !ok {
+		t.Errorf("Text converter: got %T, want testConverter", bd.converter)
+	}
+
+	comp, ok := main.Children[1].(Composite)
+	if !ok {
+		t.Fatalf("Children[1]: got %T, want Composite", main.Children[1])
+	}
+	if len(comp.Children) != 3 {
+		t.Fatalf("len(Children[1].Children): got %d, want 3", len(comp.Children))
+	}
+	if _, ok := comp.Children[0].(HSpacer); !ok {
+		t.Errorf("Children[1].Children[0]: got %T, want HSpacer", comp.Children[0])
+	}
+
+	pb, ok := comp.Children[1].(PushButton)
+	if !ok {
+		t.Fatalf("Children[1].Children[1]: got %T, want PushButton", comp.Children[1])
+	}
+	if pb.Text != "OK" {
+		t.Errorf("PushButton Text: got %v, want OK", pb.Text)
+	}
+	if pb.OnClicked == nil {
+		t.Fatal("PushButton OnClicked: got nil")
+	}
+	*clicked = false
+	pb.OnClicked()
+	if !*clicked {
+		t.Error("PushButton OnClicked: registered handler not called")
+	}
+
+	ne, ok := comp.Children[2].(NumberEdit)
+	if !ok {
+		t.Fatalf("Children[1].Children[2]: got %T, want NumberEdit", comp.Children[2])
+	}
+	if ne.Value != 42 {
+		t.Errorf("NumberEdit Value: got %v, want 42", ne.Value)
+	}
+}
+
+func TestDocumentLoadJSON(t *testing.T) {
+	var mw *walk.MainWindow
+	var clicked bool
+	r := newTestRegistry(&mw, &clicked)
+
+	v, err := r.LoadJSON([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTestDocument(t, v, &mw, &clicked)
+}
+
+func TestDocumentLoadYAML(t *testing.T) {
+	var mw *walk.MainWindow
+	var clicked bool
+	r := newTestRegistry(&mw, &clicked)
+
+	v, err := r.LoadYAML([]byte(testDocument), yamlUnmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTestDocument(t, v, &mw, &clicked)
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	var mw *walk.MainWindow
+	var clicked bool
+	r := newTestRegistry(&mw, &clicked)
+
+	v, err := r.LoadJSON([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := r.SaveJSON(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = r.LoadJSON(data)
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, data)
+	}
+
+	checkTestDocument(t, v, &mw, &clicked)
+
+	// Saving again must produce the same document.
+	again, err := r.SaveJSON(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("SaveJSON after LoadJSON: got\n%s\nwant\n%s", again, data)
+	}
+
+	// The injected marshal function gets the generic form of the document.
+	marshaled, err := r.SaveYAML(v, func(in interface{}) ([]byte, error) {
+		return json.MarshalIndent(in, "", "\t")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = r.LoadYAML(marshaled, yamlUnmarshal)
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, marshaled)
+	}
+
+	checkTestDocument(t, v, &mw, &clicked)
+}
+
+func TestDocumentRoundTripNumbers(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{int(-3), int(-3)},
+		{int8(-8), int(-8)},
+		{int16(16), int(16)},
+		{int32(-32), int(-32)},
+		{int64(1) << 40, int(1) << 40},
+		{uint(7), int(7)},
+		{uint8(255), int(255)},
+		{uint16(16), int(16)},
+		{uint32(32), int(32)},
+		{uint64(64), int(64)},
+		{float32(1.5), float64(1.5)},
+		{float64(-2.25), float64(-2.25)},
+		{"text", "text"},
+		{true, true},
+	}
+
+	r := NewDocumentRegistry()
+
+	for _, test := range tests {
+		data, err := r.SaveJSON(NumberEdit{Value: test.value})
+		if err != nil {
+			t.Errorf("SaveJSON(%T(%v)): %v", test.value, test.value, err)
+			continue
+		}
+
+		v, err := r.LoadJSON(data)
+		if err != nil {
+			t.Errorf("LoadJSON for %T(%v): %v", test.value, test.value, err)
+			continue
+		}
+
+		if got := v.(NumberEdit).Value; got != test.want {
+			t.Errorf("%T(%v): got %T(%v), want %T(%v)", test.value, test.value, got, got, test.want, test.want)
+		}
+	}
+}
+
+func TestDocumentLoadErrors(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{`[]`, "document root must be an object"},
+		{`{"Title": "x"}`, `document root: missing "type"`},
+		{`{"type": "Nope"}`, `document root: unknown type "Nope"`},
+		{`{"type": "MainWindow", "Bogus": 1}`, "Bogus: unknown field"},
+		{`{"type": "MainWindow", "Children": {}}`, "Children: expected a list"},
+		{`{"type": "MainWindow", "Children": [{"Text": "x"}]}`, `Children[0]: expected "type", "ref" or "bind"`},
+		{`{"type": "MainWindow", "Layout": {"type": "LineEdit"}}`, "Layout: LineEdit is not a Layout"},
+		{`{"type": "MainWindow", "Layout": "VBox"}`, "Layout: expected an object"},
+		{`{"type": "MainWindow", "Title": {"Text": "x"}}`, `Title: expected "type", "ref" or "bind"`},
+		{`{"type": "LineEdit", "Text": {"bind": "Name", "bogus": 1}}`, "Text.bogus: unknown binding option"},
+		{`{"type": "LineEdit", "Text": {"bind": "Name", "converter": "nope"}}`, `Text.converter: no value registered as "nope"`},
+		{`{"type": "LineEdit", "MaxLength": "x"}`, "MaxLength: unexpected string"},
+		{`{"type": "LineEdit", "MaxLength": true}`, "MaxLength: unexpected bool"},
+		{`{"type": "PushButton", "OnClicked": "missing"}`, `OnClicked: no value registered as "missing"`},
+		{`{"type": "PushButton", "OnClicked": "answer"}`, `OnClicked: "answer" is a int, not a walk.EventHandler`},
+		{`{"type": "PushButton", "OnClicked": 1}`, "OnClicked: expected the name of a registered value"},
+		{`{"type": "Composite", "Children": [{"type": "PushButton", "Text": {"ref": "nope"}}]}`, `Children[0].Text: no value registered as "nope"`},
+	}
+
+	var mw *walk.MainWindow
+	var clicked bool
+	r := newTestRegistry(&mw, &clicked)
+
+	for _, test := range tests {
+		_, err := r.LoadJSON([]byte(test.doc))
+		if err == nil {
+			t.Errorf("%s: got no error, want %q", test.doc, test.want)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %q, want %q", test.doc, err, test.want)
+		}
+	}
+}
+
+func TestDocumentSaveErrors(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{42, "can't encode int, type not registered"},
+		{PushButton{OnClicked: func() {}}, "OnClicked: walk.EventHandler value is not registered"},
+		{LineEdit{Text: BindConverted("Name", testConverter{})}, "Text.converter: value is not registered"},
+		{Composite{Children: []Widget{PushButton{Text: reflect.ValueOf(0)}}}, "Children[0].Text: reflect.Value value is not registered"},
+	}
+
+	r := NewDocumentRegistry()
+
+	for _, test := range tests {
+		_, err := r.SaveJSON(test.value)
+		if err == nil {
+			t.Errorf("%#v: got no error, want %q", test.value, test.want)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%#v: got error %q, want %q", test.value, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type FindBar struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// FindBar
+
+	AssignTo   **walk.FindBar
+	Controller walk.FindController
+	Options    walk.FindOptions
+}
+
+func (fb FindBar) Create(builder *Builder) error {
+	w, err := walk.NewFindBar(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if fb.AssignTo != nil {
+		*fb.AssignTo = w
+	}
+
+	return builder.InitWidget(fb, w, func() error {
+		w.SetOptions(fb.Options)
+
+		if fb.Controller != nil {
+			w.SetController(fb.Controller)
+		}
+
+		return nil
+	})
+}
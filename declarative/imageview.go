@@ -13,12 +13,13 @@ import (
 type ImageViewMode int
 
 const (
-	ImageViewModeIdeal   = ImageViewMode(walk.ImageViewModeIdeal)
-	ImageViewModeCorner  = ImageViewMode(walk.ImageViewModeCorner)
-	ImageViewModeCenter  = ImageViewMode(walk.ImageViewModeCenter)
-	ImageViewModeShrink  = ImageViewMode(walk.ImageViewModeShrink)
-	ImageViewModeZoom    = ImageViewMode(walk.ImageViewModeZoom)
-	ImageViewModeStretch = ImageViewMode(walk.ImageViewModeStretch)
+	ImageViewModeIdeal    = ImageViewMode(walk.ImageViewModeIdeal)
+	ImageViewModeCorner   = ImageViewMode(walk.ImageViewModeCorner)
+	ImageViewModeCenter   = ImageViewMode(walk.ImageViewModeCenter)
+	ImageViewModeShrink   = ImageViewMode(walk.ImageViewModeShrink)
+	ImageViewModeZoom     = ImageViewMode(walk.ImageViewModeZoom)
+	ImageViewModeStretch  = ImageViewMode(walk.ImageViewModeStretch)
+	ImageViewModeViewport = ImageViewMode(walk.ImageViewModeViewport)
 )
 
 type ImageView struct {
@@ -59,10 +60,16 @@ type ImageView struct {
 
 	// ImageView
 
-	AssignTo **walk.ImageView
-	Image    Property
-	Margin   Property
-	Mode     ImageViewMode
+	AssignTo          **walk.ImageView
+	Image             Property
+	ImageFile         string
+	Margin            Property
+	Mode              ImageViewMode
+	OnImageLoadFailed walk.ErrorEventHandler
+	OnLoadingChanged  walk.EventHandler
+	OnZoomChanged     walk.EventHandler
+	PixelGridVisible  bool
+	Placeholder       Property
 }
 
 func (iv ImageView) Create(builder *Builder) error {
@@ -77,6 +84,23 @@ func (iv ImageView) Create(builder *Builder) error {
 
 	return builder.InitWidget(iv, w, func() error {
 		w.SetMode(walk.ImageViewMode(iv.Mode))
+		w.SetPixelGridVisible(iv.PixelGridVisible)
+
+		if iv.OnImageLoadFailed != nil {
+			w.ImageLoadFailed().Attach(iv.OnImageLoadFailed)
+		}
+
+		if iv.OnLoadingChanged != nil {
+			w.LoadingChanged().Attach(iv.OnLoadingChanged)
+		}
+
+		if iv.OnZoomChanged != nil {
+			w.ZoomChanged().Attach(iv.OnZoomChanged)
+		}
+
+		if iv.ImageFile != "" {
+			w.LoadImage(iv.ImageFile)
+		}
 
 		return nil
 	})
@@ -25,6 +25,7 @@ type Property interface{}
 type bindData struct {
 	expression string
 	validator  Validator
+	converter  walk.Converter
 }
 
 func Bind(expression string, validators ...Validator) Property {
@@ -43,6 +44,17 @@ func Bind(expression string, validators ...Validator) Property {
 	return bd
 }
 
+// BindConverted is like Bind, but values are converted by converter on their
+// way between the data source and the property, e.g.
+//
+//	Value: BindConverted("PriceCents", walk.ScaleConverter{Factor: 0.01, Round: true}),
+func BindConverted(expression string, converter walk.Converter, validators ...Validator) Property {
+	bd := Bind(expression, validators...).(bindData)
+	bd.converter = converter
+
+	return bd
+}
+
 type SysDLLIcon struct {
 	FileName string
 	Index    int
@@ -0,0 +1,94 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type PrintPreview struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// PrintPreview
+
+	AssignTo             **walk.PrintPreview
+	CurrentPage          Property
+	Document             walk.Printable
+	Margins              Margins
+	OnCurrentPageChanged walk.EventHandler
+	OnPageCountChanged   walk.EventHandler
+	Printer              *walk.Printer
+}
+
+func (pp PrintPreview) Create(builder *Builder) error {
+	w, err := walk.NewPrintPreview(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if pp.AssignTo != nil {
+		*pp.AssignTo = w
+	}
+
+	return builder.InitWidget(pp, w, func() error {
+		if pp.OnCurrentPageChanged != nil {
+			w.CurrentPageChanged().Attach(pp.OnCurrentPageChanged)
+		}
+
+		if pp.OnPageCountChanged != nil {
+			w.PageCountChanged().Attach(pp.OnPageCountChanged)
+		}
+
+		if pp.Printer != nil {
+			if err := w.SetPrinter(pp.Printer); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetMargins(pp.Margins.toW()); err != nil {
+			return err
+		}
+
+		if pp.Document != nil {
+			return w.SetDocument(pp.Document)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type RichTextEdit struct {
+	// Window
+
+	Accessibility      Accessibility
+	Background         Brush
+	ContextMenuItems   []MenuItem
+	DoubleBuffering    bool
+	Enabled            Property
+	Font               Font
+	MaxSize            Size
+	MinSize            Size
+	Name               string
+	OnBoundsChanged    walk.EventHandler
+	OnKeyDown          walk.KeyEventHandler
+	OnKeyPress         walk.KeyEventHandler
+	OnKeyUp            walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	Persistent         bool
+	RightToLeftReading bool
+	ToolTipText        Property
+	Visible            Property
+
+	// Widget
+
+	Alignment          Alignment2D
+	AlwaysConsumeSpace bool
+	Column             int
+	ColumnSpan         int
+	GraphicsEffects    []walk.WidgetGraphicsEffect
+	Row                int
+	RowSpan            int
+	StretchFactor      int
+
+	// RichTextEdit
+
+	AssignTo           **walk.RichTextEdit
+	HTML               string
+	OnSelectionChanged walk.EventHandler
+	OnTextChanged      walk.EventHandler
+	ReadOnly           Property
+	RTF                string
+	Text               Property
+	Zoom               float64
+}
+
+func (rte RichTextEdit) Create(builder *Builder) error {
+	w, err := walk.NewRichTextEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	if rte.AssignTo != nil {
+		*rte.AssignTo = w
+	}
+
+	return builder.InitWidget(rte, w, func() error {
+		if rte.RTF != "" {
+			if err := w.SetRTF(rte.RTF); err != nil {
+				return err
+			}
+		} else if rte.HTML != "" {
+			if err := w.SetHTML(rte.HTML); err != nil {
+				return err
+			}
+		}
+
+		if rte.Zoom > 0 {
+			if err := w.SetZoom(rte.Zoom); err != nil {
+				return err
+			}
+		}
+
+		if rte.OnSelectionChanged != nil {
+			w.SelectionChanged().Attach(rte.OnSelectionChanged)
+		}
+
+		if rte.OnTextChanged != nil {
+			w.TextChanged().Attach(rte.OnTextChanged)
+		}
+
+		return nil
+	})
+}
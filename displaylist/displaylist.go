@@ -0,0 +1,246 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package displaylist records drawing operations and exports them as SVG or
+// PDF documents.
+//
+// A recording walk.Canvas fills a List with what is drawn on it. A List can
+// also be built by hand, so exports can be tested on any platform.
+package displaylist
+
+import (
+	"image"
+	"image/color"
+)
+
+// Point is a point in pixels.
+type Point struct {
+	X, Y float64
+}
+
+// Rect is a rectangle in pixels.
+type Rect struct {
+	X, Y, Width, Height float64
+}
+
+// Cap is how the ends of stroked lines look.
+type Cap int
+
+const (
+	ButtCap Cap = iota
+	RoundCap
+	SquareCap
+)
+
+// Join is how the segments of stroked lines meet.
+type Join int
+
+const (
+	MiterJoin Join = iota
+	RoundJoin
+	BevelJoin
+)
+
+// Stroke describes how outlines are drawn.
+type Stroke struct {
+	Color color.NRGBA
+	Width float64
+
+	// Dashes are the lengths of alternating dashes and gaps. Without any,
+	// lines are solid.
+	Dashes []float64
+
+	Cap  Cap
+	Join Join
+}
+
+// TextAlign is how lines of text are aligned horizontally in their bounds.
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextVAlign is how a block of text is aligned vertically in its bounds.
+type TextVAlign int
+
+const (
+	AlignTop TextVAlign = iota
+	AlignMiddle
+	AlignBottom
+)
+
+// Font describes the font of Text.
+type Font struct {
+	Family string
+
+	// Size is the height of the em box in pixels.
+	Size float64
+
+	Bold      bool
+	Italic    bool
+	Underline bool
+	StrikeOut bool
+}
+
+// Op is a drawing operation. It is one of *Line, *Polyline, *Polygon,
+// *Rectangle, *Ellipse, *Gradient, *Text or *Image.
+type Op interface {
+	isOp()
+}
+
+// Line is a straight line.
+type Line struct {
+	From, To Point
+	Stroke   Stroke
+}
+
+// Polyline is a series of connected lines.
+type Polyline struct {
+	Points []Point
+	Stroke Stroke
+}
+
+// Polygon is a closed shape. Fill or Stroke may be nil.
+type Polygon struct {
+	Points []Point
+	Fill   *color.NRGBA
+	Stroke *Stroke
+}
+
+// Rectangle is a rectangle, with corners rounded by the radii RX and RY.
+// Fill or Stroke may be nil.
+type Rectangle struct {
+	Bounds Rect
+	RX, RY float64
+	Fill   *color.NRGBA
+	Stroke *Stroke
+}
+
+// Ellipse is the ellipse that fits into Bounds. Fill or Stroke may be nil.
+type Ellipse struct {
+	Bounds Rect
+	Fill   *color.NRGBA
+	Stroke *Stroke
+}
+
+// Gradient fills Bounds with a linear gradient from one color to another,
+// from left to right, or from top to bottom if it is Vertical.
+type Gradient struct {
+	Bounds   Rect
+	From, To color.NRGBA
+	Vertical bool
+}
+
+// Text is a block of lines of text.
+type Text struct {
+	Lines []string
+
+	// Widths are the widths of Lines, as the recorder measured them. They
+	// may be missing, then widths are estimated.
+	Widths []float64
+
+	Bounds     Rect
+	Font       Font
+	Color      color.NRGBA
+	Align      TextAlign
+	VAlign     TextVAlign
+	LineHeight float64 // Defaults to 1.2 times the font size.
+	Ascent     float64 // Defaults to 0.8 times the font size.
+}
+
+// Image is an image, stretched to Bounds.
+type Image struct {
+	Image  image.Image
+	Bounds Rect
+
+	// Opacity goes from 0, which is invisible, to 1, which is opaque.
+	Opacity float64
+}
+
+func (*Line) isOp()      {}
+func (*Polyline) isOp()  {}
+func (*Polygon) isOp()   {}
+func (*Rectangle) isOp() {}
+func (*Ellipse) isOp()   {}
+func (*Gradient) isOp()  {}
+func (*Text) isOp()      {}
+func (*Image) isOp()     {}
+
+// List is a list of drawing operations on a canvas of Width by Height pixels.
+type List struct {
+	Width, Height float64
+
+	// DPI is the resolution of the pixels, which decides the physical size
+	// of exported documents. 0 means 96.
+	DPI float64
+
+	Ops []Op
+}
+
+// Add appends op to the List.
+func (l *List) Add(op Op) {
+	l.Ops = append(l.Ops, op)
+}
+
+func (l *List) dpi() float64 {
+	if l.DPI <= 0 {
+		return 96
+	}
+
+	return l.DPI
+}
+
+func (t *Text) lineHeight() float64 {
+	if t.LineHeight > 0 {
+		return t.LineHeight
+	}
+
+	return t.Font.Size * 1.2
+}
+
+func (t *Text) ascent() float64 {
+	if t.Ascent > 0 {
+		return t.Ascent
+	}
+
+	return t.Font.Size * 0.8
+}
+
+// top returns where the first line of t starts.
+func (t *Text) top() float64 {
+	height := float64(len(t.Lines)) * t.lineHeight()
+
+	switch t.VAlign {
+	case AlignMiddle:
+		return t.Bounds.Y + (t.Bounds.Height-height)/2
+
+	case AlignBottom:
+		return t.Bounds.Y + t.Bounds.Height - height
+	}
+
+	return t.Bounds.Y
+}
+
+// lineX returns where line i of t starts.
+func (t *Text) lineX(i int) float64 {
+	var width float64
+	if i < len(t.Widths) {
+		width = t.Widths[i]
+	} else {
+		width = float64(len([]rune(t.Lines[i]))) * t.Font.Size / 2
+	}
+
+	switch t.Align {
+	case AlignCenter:
+		return t.Bounds.X + (t.Bounds.Width-width)/2
+
+	case AlignRight:
+		return t.Bounds.X + t.Bounds.Width - width
+	}
+
+	return t.Bounds.X
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package displaylist
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func newTestList() *List {
+	red := color.NRGBA{0xFF, 0, 0, 0xFF}
+	translucentBlue := color.NRGBA{0, 0, 0xFF, 0x80}
+
+	icon := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	icon.Set(0, 0, color.NRGBA{0xFF, 0, 0, 0xFF})
+	icon.Set(1, 0, color.NRGBA{0, 0xFF, 0, 0xFF})
+	icon.Set(0, 1, color.NRGBA{0, 0, 0xFF, 0x80})
+
+	l := &List{Width: 200, Height: 150, DPI: 192}
+
+	l.Add(&Gradient{
+		Bounds:   Rect{0, 0, 200, 20},
+		From:     color.NRGBA{0xEE, 0xEE, 0xEE, 0xFF},
+		To:       color.NRGBA{0x99, 0x99, 0x99, 0xFF},
+		Vertical: true,
+	})
+	l.Add(&Line{
+		From:   Point{10, 30},
+		To:     Point{190, 30},
+		Stroke: Stroke{Color: red, Width: 2, Dashes: []float64{4, 2}, Cap: RoundCap},
+	})
+	l.Add(&Polyline{
+		Points: []Point{{10, 40}, {50, 60}, {90, 40}},
+		Stroke: Stroke{Color: color.NRGBA{0, 0x80, 0, 0xFF}, Width: 1.5, Join: BevelJoin},
+	})
+	l.Add(&Polygon{
+		Points: []Point{{100, 40}, {140, 60}, {100, 60}},
+		Fill:   &translucentBlue,
+		Stroke: &Stroke{Color: red, Width: 1, Join: RoundJoin, Cap: SquareCap},
+	})
+	l.Add(&Rectangle{Bounds: Rect{10, 70, 60, 30}, RX: 5, RY: 5, Fill: &red})
+	l.Add(&Rectangle{Bounds: Rect{80, 70, 60, 30}, Stroke: &Stroke{Color: translucentBlue, Width: 0.5}})
+	l.Add(&Ellipse{Bounds: Rect{150, 70, 40, 30}, Fill: &translucentBlue, Stroke: &Stroke{Color: red, Width: 1}})
+	l.Add(&Text{
+		Lines:  []string{"Total: 42 €", `<a href="x">&</a>`},
+		Widths: []float64{60},
+		Bounds: Rect{10, 105, 180, 40},
+		Font:   Font{Family: "Segoe UI", Size: 12, Bold: true, Underline: true},
+		Color:  color.NRGBA{0x20, 0x20, 0x20, 0xFF},
+		Align:  AlignCenter,
+		VAlign: AlignMiddle,
+	})
+	l.Add(&Image{Image: icon, Bounds: Rect{170, 120, 20, 20}, Opacity: 0.5})
+	l.Add(&Image{Bounds: Rect{0, 0, 10, 10}})
+
+	return l
+}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to create it)", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		gotPath := path + ".got"
+		ioutil.WriteFile(gotPath, got, 0644)
+		t.Errorf("%s differs from the golden file, see %s", name, gotPath)
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestList().WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "list.svg", buf.Bytes())
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestList().WritePDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkPDFStructure(t, buf.Bytes())
+
+	checkGolden(t, "list.pdf", buf.Bytes())
+}
+
+// checkPDFStructure checks that the cross-reference table points at the
+// objects.
+func checkPDFStructure(t *testing.T, pdf []byte) {
+	t.Helper()
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d doesn't point at the cross-reference table", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(pdf[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatal("empty cross-reference table")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("object %d isn't at offset %d", i+1, offset)
+		}
+	}
+}
+
+func TestUnknownCapsAndJoins(t *testing.T) {
+	l := &List{Width: 10, Height: 10}
+	l.Add(&Line{To: Point{10, 10}, Stroke: Stroke{Width: 1, Cap: Cap(7), Join: Join(-1)}})
+
+	var svg bytes.Buffer
+	if err := l.WriteSVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+	if s := svg.String(); !strings.Contains(s, `stroke-linecap="butt" stroke-linejoin="miter"`) {
+		t.Errorf("got SVG %s, want butt caps and miter joins", s)
+	}
+
+	c := &pdfContent{}
+	c.setStrokeStyle(&l.Ops[0].(*Line).Stroke)
+	if s := c.buf.String(); !strings.Contains(s, " 0 J 0 j") {
+		t.Errorf("got PDF content %q, want butt caps and miter joins", s)
+	}
+}
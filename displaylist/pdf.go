@@ -0,0 +1,516 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package displaylist
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+	"strings"
+)
+
+// kappa is the distance of the control points of a cubic Bézier curve that
+// approximates a quarter circle of radius 1.
+const kappa = 0.5522847498
+
+// pdfContent collects the content stream of a page and the resources it
+// uses.
+type pdfContent struct {
+	buf       bytes.Buffer
+	fonts     map[string]string // Base font name to resource name.
+	images    []*Image
+	alphas    map[[2]float64]string // Fill and stroke alpha to resource name.
+	shadings  []*Gradient
+	pageWidth float64
+}
+
+// WritePDF writes the List to w as a single page PDF document.
+//
+// Text is set in the standard PDF fonts Helvetica, Times or Courier, whatever
+// is closest to the recorded font, and can only use the characters of the
+// Windows-1252 code page.
+func (l *List) WritePDF(w io.Writer) error {
+	c := &pdfContent{
+		fonts:  make(map[string]string),
+		alphas: make(map[[2]float64]string),
+	}
+
+	// Pixels, with y growing downwards, are mapped to points.
+	scale := 72 / l.dpi()
+	pageWidth, pageHeight := l.Width*scale, l.Height*scale
+	fmt.Fprintf(&c.buf, "%s 0 0 %s 0 %s cm\n", num(scale), num(-scale), num(pageHeight))
+
+	for _, op := range l.Ops {
+		c.buf.WriteString("q\n")
+		c.op(op)
+		c.buf.WriteString("Q\n")
+	}
+
+	var doc pdfDocument
+
+	fontNames := make([]string, 0, len(c.fonts))
+	for name := range c.fonts {
+		fontNames = append(fontNames, name)
+	}
+	sort.Strings(fontNames)
+
+	var resources strings.Builder
+
+	resources.WriteString("/Font <<")
+	for _, name := range fontNames {
+		ref := doc.add(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&resources, " /%s %d 0 R", c.fonts[name], ref)
+	}
+	resources.WriteString(" >>\n/XObject <<")
+	for i, img := range c.images {
+		ref, err := doc.addImage(img.Image)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&resources, " /Im%d %d 0 R", i, ref)
+	}
+	resources.WriteString(" >>\n/ExtGState <<")
+	alphaKeys := make([][2]float64, 0, len(c.alphas))
+	for key := range c.alphas {
+		alphaKeys = append(alphaKeys, key)
+	}
+	sort.Slice(alphaKeys, func(i, j int) bool {
+		return c.alphas[alphaKeys[i]] < c.alphas[alphaKeys[j]]
+	})
+	for _, key := range alphaKeys {
+		fmt.Fprintf(&resources, " /%s << /ca %s /CA %s >>", c.alphas[key], num(key[0]), num(key[1]))
+	}
+	resources.WriteString(" >>\n/Shading <<")
+	for i, g := range c.shadings {
+		b := g.Bounds
+		x1, y1 := b.X+b.Width, b.Y
+		if g.Vertical {
+			x1, y1 = b.X, b.Y+b.Height
+		}
+
+		fmt.Fprintf(&resources, " /Sh%d << /ShadingType 2 /ColorSpace /DeviceRGB /Coords [%s %s %s %s]"+
+			" /Function << /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >> >>",
+			i, num(b.X), num(b.Y), num(x1), num(y1), pdfRGB(g.From), pdfRGB(g.To))
+	}
+	resources.WriteString(" >>")
+
+	content, err := deflate(c.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	contentRef := doc.addStream("/Filter /FlateDecode", content)
+
+	// The page tree and catalog come last, when all references are known.
+	pagesRef := len(doc.objects) + 2
+	pageRef := doc.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s]\n/Resources << %s >>\n/Contents %d 0 R >>",
+		pagesRef, num(pageWidth), num(pageHeight), resources.String(), contentRef))
+	doc.add(fmt.Sprintf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", pageRef))
+	rootRef := doc.add(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesRef))
+
+	_, err = w.Write(doc.bytes(rootRef))
+	return err
+}
+
+func (c *pdfContent) op(op Op) {
+	b := &c.buf
+
+	switch op := op.(type) {
+	case *Line:
+		c.setStroke(&op.Stroke)
+		fmt.Fprintf(b, "%s %s m %s %s l S\n", num(op.From.X), num(op.From.Y), num(op.To.X), num(op.To.Y))
+
+	case *Polyline:
+		if len(op.Points) == 0 {
+			return
+		}
+		c.setStroke(&op.Stroke)
+		c.points(op.Points)
+		b.WriteString("S\n")
+
+	case *Polygon:
+		if len(op.Points) == 0 {
+			return
+		}
+		c.setPaint(op.Fill, op.Stroke)
+		c.points(op.Points)
+		b.WriteString("h " + paintOperator(op.Fill, op.Stroke) + "\n")
+
+	case *Rectangle:
+		c.setPaint(op.Fill, op.Stroke)
+		r := op.Bounds
+		if op.RX > 0 && op.RY > 0 {
+			c.roundedRect(r, op.RX, op.RY)
+		} else {
+			fmt.Fprintf(b, "%s %s %s %s re\n", num(r.X), num(r.Y), num(r.Width), num(r.Height))
+		}
+		b.WriteString(paintOperator(op.Fill, op.Stroke) + "\n")
+
+	case *Ellipse:
+		c.setPaint(op.Fill, op.Stroke)
+		c.roundedRect(op.Bounds, op.Bounds.Width/2, op.Bounds.Height/2)
+		b.WriteString(paintOperator(op.Fill, op.Stroke) + "\n")
+
+	case *Gradient:
+		r := op.Bounds
+		fmt.Fprintf(b, "%s %s %s %s re W n /Sh%d sh\n", num(r.X), num(r.Y), num(r.Width), num(r.Height), len(c.shadings))
+		c.shadings = append(c.shadings, op)
+
+	case *Text:
+		c.text(op)
+
+	case *Image:
+		if op.Image == nil {
+			return
+		}
+		if op.Opacity < 1 {
+			fmt.Fprintf(b, "/%s gs\n", c.alpha(op.Opacity, 1))
+		}
+		r := op.Bounds
+		fmt.Fprintf(b, "%s 0 0 %s %s %s cm /Im%d Do\n", num(r.Width), num(-r.Height), num(r.X), num(r.Y+r.Height), len(c.images))
+		c.images = append(c.images, op)
+	}
+}
+
+func (c *pdfContent) points(points []Point) {
+	for i, p := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&c.buf, "%s %s %s\n", num(p.X), num(p.Y), op)
+	}
+}
+
+// roundedRect adds a rectangle with elliptic corners, which is an ellipse if
+// the radii are half the size of r.
+func (c *pdfContent) roundedRect(r Rect, rx, ry float64) {
+	rx, ry = minf(rx, r.Width/2), minf(ry, r.Height/2)
+	kx, ky := rx*kappa, ry*kappa
+	left, top, right, bottom := r.X, r.Y, r.X+r.Width, r.Y+r.Height
+
+	b := &c.buf
+	fmt.Fprintf(b, "%s %s m\n", num(left+rx), num(top))
+	fmt.Fprintf(b, "%s %s l\n", num(right-rx), num(top))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", num(right-rx+kx), num(top), num(right), num(top+ry-ky), num(right), num(top+ry))
+	fmt.Fprintf(b, "%s %s l\n", num(right), num(bottom-ry))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", num(right), num(bottom-ry+ky), num(right-rx+kx), num(bottom), num(right-rx), num(bottom))
+	fmt.Fprintf(b, "%s %s l\n", num(left+rx), num(bottom))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", num(left+rx-kx), num(bottom), num(left), num(bottom-ry+ky), num(left), num(bottom-ry))
+	fmt.Fprintf(b, "%s %s l\n", num(left), num(top+ry))
+	fmt.Fprintf(b, "%s %s %s %s %s %s c\n", num(left), num(top+ry-ky), num(left+rx-kx), num(top), num(left+rx), num(top))
+	b.WriteString("h\n")
+}
+
+func (c *pdfContent) setPaint(fill *color.NRGBA, stroke *Stroke) {
+	fillAlpha, strokeAlpha := 1.0, 1.0
+
+	if fill != nil {
+		fmt.Fprintf(&c.buf, "%s rg\n", pdfRGB(*fill))
+		fillAlpha = float64(fill.A) / 0xFF
+	}
+
+	if stroke != nil {
+		c.setStrokeStyle(stroke)
+		strokeAlpha = float64(stroke.Color.A) / 0xFF
+	}
+
+	if fillAlpha < 1 || strokeAlpha < 1 {
+		fmt.Fprintf(&c.buf, "/%s gs\n", c.alpha(fillAlpha, strokeAlpha))
+	}
+}
+
+func (c *pdfContent) setStroke(stroke *Stroke) {
+	c.setPaint(nil, stroke)
+}
+
+func (c *pdfContent) setStrokeStyle(stroke *Stroke) {
+	b := &c.buf
+
+	// Unknown caps and joins are butt caps and miter joins, like in SVG.
+	lineCap, lineJoin := stroke.Cap, stroke.Join
+	if lineCap < ButtCap || lineCap > SquareCap {
+		lineCap = ButtCap
+	}
+	if lineJoin < MiterJoin || lineJoin > BevelJoin {
+		lineJoin = MiterJoin
+	}
+
+	fmt.Fprintf(b, "%s RG %s w %d J %d j\n", pdfRGB(stroke.Color), num(stroke.Width), lineCap, lineJoin)
+
+	if len(stroke.Dashes) > 0 {
+		dashes := make([]string, len(stroke.Dashes))
+		for i, d := range stroke.Dashes {
+			dashes[i] = num(d)
+		}
+		fmt.Fprintf(b, "[%s] 0 d\n", strings.Join(dashes, " "))
+	}
+}
+
+func paintOperator(fill *color.NRGBA, stroke *Stroke) string {
+	switch {
+	case fill != nil && stroke != nil:
+		return "B"
+
+	case fill != nil:
+		return "f"
+
+	case stroke != nil:
+		return "S"
+	}
+
+	return "n"
+}
+
+// alpha returns the name of the graphics state for the given alphas.
+func (c *pdfContent) alpha(fill, stroke float64) string {
+	key := [2]float64{fill, stroke}
+
+	name, ok := c.alphas[key]
+	if !ok {
+		name = fmt.Sprintf("GS%d", len(c.alphas))
+		c.alphas[key] = name
+	}
+
+	return name
+}
+
+func (c *pdfContent) text(t *Text) {
+	if len(t.Lines) == 0 {
+		return
+	}
+
+	b := &c.buf
+
+	name := baseFont(t.Font)
+	res, ok := c.fonts[name]
+	if !ok {
+		res = fmt.Sprintf("F%d", len(c.fonts))
+		c.fonts[name] = res
+	}
+
+	color := t.Color
+	c.setPaint(&color, nil)
+
+	y := t.top() + t.ascent()
+
+	for i, line := range t.Lines {
+		x := t.lineX(i)
+
+		// The text matrix flips y back, or glyphs would be upside down.
+		fmt.Fprintf(b, "BT /%s %s Tf 1 0 0 -1 %s %s Tm (%s) Tj ET\n", res, num(t.Font.Size), num(x), num(y), pdfString(line))
+
+		if t.Font.Underline || t.Font.StrikeOut {
+			width := t.Font.Size / 2 * float64(len([]rune(line)))
+			if i < len(t.Widths) {
+				width = t.Widths[i]
+			}
+			thickness := t.Font.Size / 14
+
+			if t.Font.Underline {
+				fmt.Fprintf(b, "%s %s %s %s re f\n", num(x), num(y+thickness), num(width), num(thickness))
+			}
+			if t.Font.StrikeOut {
+				fmt.Fprintf(b, "%s %s %s %s re f\n", num(x), num(y-t.ascent()/3), num(width), num(thickness))
+			}
+		}
+
+		y += t.lineHeight()
+	}
+}
+
+// baseFont returns the name of the standard PDF font closest to f.
+func baseFont(f Font) string {
+	family := strings.ToLower(f.Family)
+
+	var name string
+	var bold, italic, boldItalic string
+
+	switch {
+	case strings.Contains(family, "courier") || strings.Contains(family, "mono") || strings.Contains(family, "consol"):
+		name, bold, italic, boldItalic = "Courier", "Courier-Bold", "Courier-Oblique", "Courier-BoldOblique"
+
+	case strings.Contains(family, "times") || strings.Contains(family, "georgia") ||
+		(strings.Contains(family, "serif") && !strings.Contains(family, "sans")):
+		name, bold, italic, boldItalic = "Times-Roman", "Times-Bold", "Times-Italic", "Times-BoldItalic"
+
+	default:
+		name, bold, italic, boldItalic = "Helvetica", "Helvetica-Bold", "Helvetica-Oblique", "Helvetica-BoldOblique"
+	}
+
+	switch {
+	case f.Bold && f.Italic:
+		return boldItalic
+
+	case f.Bold:
+		return bold
+
+	case f.Italic:
+		return italic
+	}
+
+	return name
+}
+
+// winAnsi maps the characters of Windows-1252 that differ from Latin-1.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfString encodes s as the content of a PDF literal string in
+// Windows-1252. Characters it lacks become question marks.
+func pdfString(s string) string {
+	var sb strings.Builder
+
+	for _, r := range s {
+		var ch byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			ch = byte(r)
+
+		case r >= 0x20 && r < 0x7F || r >= 0xA0 && r <= 0xFF:
+			ch = byte(r)
+
+		case r == '\t':
+			ch = ' '
+
+		default:
+			var ok bool
+			if ch, ok = winAnsi[r]; !ok {
+				ch = '?'
+			}
+		}
+
+		if ch >= 0x80 {
+			fmt.Fprintf(&sb, "\\%03o", ch)
+		} else {
+			sb.WriteByte(ch)
+		}
+	}
+
+	return sb.String()
+}
+
+func pdfRGB(c color.NRGBA) string {
+	return fmt.Sprintf("%s %s %s", num(float64(c.R)/0xFF), num(float64(c.G)/0xFF), num(float64(c.B)/0xFF))
+}
+
+func minf(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// pdfDocument collects the objects of a PDF file, numbered from 1.
+type pdfDocument struct {
+	objects [][]byte
+}
+
+// add adds an object and returns its number.
+func (doc *pdfDocument) add(object string) int {
+	doc.objects = append(doc.objects, []byte(object))
+
+	return len(doc.objects)
+}
+
+func (doc *pdfDocument) addStream(dict string, data []byte) int {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+
+	doc.objects = append(doc.objects, buf.Bytes())
+
+	return len(doc.objects)
+}
+
+// addImage adds im as RGB image, with a soft mask if it isn't opaque, and
+// returns its number.
+func (doc *pdfDocument) addImage(im image.Image) (int, error) {
+	bounds := im.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	rgb := make([]byte, 0, w*h*3)
+	alpha := make([]byte, 0, w*h)
+	opaque := true
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(im.At(x, y)).(color.NRGBA)
+
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			if c.A != 0xFF {
+				opaque = false
+			}
+		}
+	}
+
+	var smask string
+	if !opaque {
+		data, err := deflate(alpha)
+		if err != nil {
+			return 0, err
+		}
+
+		ref := doc.addStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode", w, h), data)
+		smask = fmt.Sprintf(" /SMask %d 0 R", ref)
+	}
+
+	data, err := deflate(rgb)
+	if err != nil {
+		return 0, err
+	}
+
+	return doc.addStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode%s", w, h, smask), data), nil
+}
+
+// bytes returns the PDF file, with the object numbered root as catalog.
+func (doc *pdfDocument) bytes(root int) []byte {
+	var buf bytes.Buffer
+
+	// The comment with bytes above 127 marks the file as binary.
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	offsets := make([]int, len(doc.objects))
+	for i, object := range doc.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(object)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(doc.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(doc.objects)+1, root, xref)
+
+	return buf.Bytes()
+}
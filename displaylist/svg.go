@@ -0,0 +1,236 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package displaylist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteSVG writes the List to w as an SVG document.
+func (l *List) WriteSVG(w io.Writer) error {
+	var buf bytes.Buffer
+
+	scale := 96 / l.dpi()
+
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%s" height="%s" viewBox="0 0 %s %s">
+`, num(l.Width*scale), num(l.Height*scale), num(l.Width), num(l.Height))
+
+	var gradientCount int
+
+	for _, op := range l.Ops {
+		switch op := op.(type) {
+		case *Line:
+			fmt.Fprintf(&buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" %s/>`+"\n",
+				num(op.From.X), num(op.From.Y), num(op.To.X), num(op.To.Y), svgPaint(nil, &op.Stroke))
+
+		case *Polyline:
+			fmt.Fprintf(&buf, `<polyline points="%s" %s/>`+"\n", svgPoints(op.Points), svgPaint(nil, &op.Stroke))
+
+		case *Polygon:
+			fmt.Fprintf(&buf, `<polygon points="%s" %s/>`+"\n", svgPoints(op.Points), svgPaint(op.Fill, op.Stroke))
+
+		case *Rectangle:
+			var radii string
+			if op.RX > 0 && op.RY > 0 {
+				radii = fmt.Sprintf(`rx="%s" ry="%s" `, num(op.RX), num(op.RY))
+			}
+
+			fmt.Fprintf(&buf, `<rect %s%s%s/>`+"\n", svgRect(op.Bounds), radii, svgPaint(op.Fill, op.Stroke))
+
+		case *Ellipse:
+			b := op.Bounds
+			fmt.Fprintf(&buf, `<ellipse cx="%s" cy="%s" rx="%s" ry="%s" %s/>`+"\n",
+				num(b.X+b.Width/2), num(b.Y+b.Height/2), num(b.Width/2), num(b.Height/2), svgPaint(op.Fill, op.Stroke))
+
+		case *Gradient:
+			gradientCount++
+			id := fmt.Sprintf("gradient%d", gradientCount)
+
+			x2, y2 := 1, 0
+			if op.Vertical {
+				x2, y2 = 0, 1
+			}
+
+			fmt.Fprintf(&buf, `<defs><linearGradient id="%s" x1="0" y1="0" x2="%d" y2="%d">`, id, x2, y2)
+			fmt.Fprintf(&buf, `<stop offset="0" stop-color="%s"/>`, hexColor(op.From))
+			fmt.Fprintf(&buf, `<stop offset="1" stop-color="%s"/>`, hexColor(op.To))
+			fmt.Fprintf(&buf, "</linearGradient></defs>\n")
+			fmt.Fprintf(&buf, `<rect %sfill="url(#%s)"/>`+"\n", svgRect(op.Bounds), id)
+
+		case *Text:
+			writeSVGText(&buf, op)
+
+		case *Image:
+			if op.Image == nil {
+				continue
+			}
+
+			var pngBuf bytes.Buffer
+			if err := png.Encode(&pngBuf, op.Image); err != nil {
+				return err
+			}
+
+			var opacity string
+			if op.Opacity < 1 {
+				opacity = fmt.Sprintf(`opacity="%s" `, num(op.Opacity))
+			}
+
+			fmt.Fprintf(&buf, `<image %s%spreserveAspectRatio="none" xlink:href="data:image/png;base64,%s"/>`+"\n",
+				svgRect(op.Bounds), opacity, base64.StdEncoding.EncodeToString(pngBuf.Bytes()))
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeSVGText(buf *bytes.Buffer, t *Text) {
+	var attrs strings.Builder
+
+	fmt.Fprintf(&attrs, `font-family="%s" font-size="%s" fill="%s"`, escape(t.Font.Family), num(t.Font.Size), hexColor(t.Color))
+	if t.Color.A < 0xFF {
+		fmt.Fprintf(&attrs, ` fill-opacity="%s"`, num(float64(t.Color.A)/0xFF))
+	}
+	if t.Font.Bold {
+		attrs.WriteString(` font-weight="bold"`)
+	}
+	if t.Font.Italic {
+		attrs.WriteString(` font-style="italic"`)
+	}
+
+	var decorations []string
+	if t.Font.Underline {
+		decorations = append(decorations, "underline")
+	}
+	if t.Font.StrikeOut {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		fmt.Fprintf(&attrs, ` text-decoration="%s"`, strings.Join(decorations, " "))
+	}
+
+	anchor, x := "start", t.Bounds.X
+	switch t.Align {
+	case AlignCenter:
+		anchor, x = "middle", t.Bounds.X+t.Bounds.Width/2
+
+	case AlignRight:
+		anchor, x = "end", t.Bounds.X+t.Bounds.Width
+	}
+
+	y := t.top() + t.ascent()
+
+	for _, line := range t.Lines {
+		fmt.Fprintf(buf, `<text x="%s" y="%s" text-anchor="%s" xml:space="preserve" %s>%s</text>`+"\n",
+			num(x), num(y), anchor, attrs.String(), escape(line))
+
+		y += t.lineHeight()
+	}
+}
+
+func svgPaint(fill *color.NRGBA, stroke *Stroke) string {
+	var sb strings.Builder
+
+	if fill != nil {
+		fmt.Fprintf(&sb, `fill="%s"`, hexColor(*fill))
+		if fill.A < 0xFF {
+			fmt.Fprintf(&sb, ` fill-opacity="%s"`, num(float64(fill.A)/0xFF))
+		}
+	} else {
+		sb.WriteString(`fill="none"`)
+	}
+
+	if stroke != nil {
+		fmt.Fprintf(&sb, ` stroke="%s" stroke-width="%s"`, hexColor(stroke.Color), num(stroke.Width))
+		if stroke.Color.A < 0xFF {
+			fmt.Fprintf(&sb, ` stroke-opacity="%s"`, num(float64(stroke.Color.A)/0xFF))
+		}
+		if len(stroke.Dashes) > 0 {
+			dashes := make([]string, len(stroke.Dashes))
+			for i, d := range stroke.Dashes {
+				dashes[i] = num(d)
+			}
+			fmt.Fprintf(&sb, ` stroke-dasharray="%s"`, strings.Join(dashes, " "))
+		}
+		sb.WriteString(` stroke-linecap="` + svgLineCap(stroke.Cap) + `"`)
+		sb.WriteString(` stroke-linejoin="` + svgLineJoin(stroke.Join) + `"`)
+	}
+
+	return sb.String()
+}
+
+// svgLineCap returns the SVG name of cap. Unknown caps are butt caps.
+func svgLineCap(cap Cap) string {
+	switch cap {
+	case RoundCap:
+		return "round"
+
+	case SquareCap:
+		return "square"
+	}
+
+	return "butt"
+}
+
+// svgLineJoin returns the SVG name of join. Unknown joins are miter joins.
+func svgLineJoin(join Join) string {
+	switch join {
+	case RoundJoin:
+		return "round"
+
+	case BevelJoin:
+		return "bevel"
+	}
+
+	return "miter"
+}
+
+func svgRect(r Rect) string {
+	return fmt.Sprintf(`x="%s" y="%s" width="%s" height="%s" `, num(r.X), num(r.Y), num(r.Width), num(r.Height))
+}
+
+func svgPoints(points []Point) string {
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = num(p.X) + "," + num(p.Y)
+	}
+
+	return strings.Join(coords, " ")
+}
+
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// num formats v with at most 3 decimals, so documents don't change with
+// insignificant rounding errors.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+
+	return sb.String()
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"path"
 	"strings"
@@ -39,6 +40,32 @@ func main() {
 					},
 				},
 			},
+			Menu{
+				Text: "&View",
+				Items: []MenuItem{
+					Action{
+						Text:        "Zoom to &Fit",
+						Shortcut:    Shortcut{walk.ModControl, walk.Key0},
+						OnTriggered: func() { mw.withImageView((*walk.ImageView).ZoomToFit) },
+					},
+					Action{
+						Text:        "&Actual Size",
+						Shortcut:    Shortcut{walk.ModControl, walk.Key1},
+						OnTriggered: func() { mw.withImageView((*walk.ImageView).ZoomToActualSize) },
+					},
+					Action{
+						Text:        "F&ill",
+						OnTriggered: func() { mw.withImageView((*walk.ImageView).ZoomToFill) },
+					},
+					Separator{},
+					Action{
+						AssignTo:    &mw.pixelGridAction,
+						Text:        "&Pixel Grid",
+						Checkable:   true,
+						OnTriggered: mw.pixelGridAction_Triggered,
+					},
+				},
+			},
 			Menu{
 				Text: "&Help",
 				Items: []MenuItem{
@@ -57,7 +84,28 @@ func main() {
 		Layout:  VBox{MarginsZero: true},
 		Children: []Widget{
 			TabWidget{
-				AssignTo: &mw.tabWidget,
+				AssignTo:              &mw.tabWidget,
+				OnCurrentIndexChanged: mw.updateZoomSlider,
+			},
+			Composite{
+				Layout: HBox{},
+				Children: []Widget{
+					Label{
+						Text: "Zoom:",
+					},
+					Slider{
+						AssignTo:       &mw.zoomSlider,
+						MinValue:       5,
+						MaxValue:       1600,
+						Value:          100,
+						OnValueChanged: mw.zoomSlider_ValueChanged,
+					},
+					Label{
+						AssignTo: &mw.zoomLabel,
+						MinSize:  Size{Width: 40},
+						Text:     "100 %",
+					},
+				},
 			},
 		},
 	}.Run()); err != nil {
@@ -67,8 +115,66 @@ func main() {
 
 type MyMainWindow struct {
 	*walk.MainWindow
-	tabWidget    *walk.TabWidget
-	prevFilePath string
+	tabWidget       *walk.TabWidget
+	zoomSlider      *walk.Slider
+	zoomLabel       *walk.Label
+	pixelGridAction *walk.Action
+	prevFilePath    string
+}
+
+// currentImageView returns the ImageView of the current tab page, if any.
+func (mw *MyMainWindow) currentImageView() *walk.ImageView {
+	index := mw.tabWidget.CurrentIndex()
+	if index < 0 {
+		return nil
+	}
+
+	page := mw.tabWidget.Pages().At(index)
+	if page.Children().Len() == 0 {
+		return nil
+	}
+
+	imageView, _ := page.Children().At(0).(*walk.ImageView)
+
+	return imageView
+}
+
+func (mw *MyMainWindow) withImageView(f func(iv *walk.ImageView)) {
+	if imageView := mw.currentImageView(); imageView != nil {
+		f(imageView)
+	}
+}
+
+func (mw *MyMainWindow) updateZoomSlider() {
+	imageView := mw.currentImageView()
+	if imageView == nil {
+		return
+	}
+
+	percent := int(imageView.Zoom()*100 + 0.5)
+
+	if percent != mw.zoomSlider.Value() {
+		mw.zoomSlider.SetValue(percent)
+	}
+	mw.zoomLabel.SetText(fmt.Sprintf("%d %%", percent))
+
+	mw.pixelGridAction.SetChecked(imageView.PixelGridVisible())
+}
+
+func (mw *MyMainWindow) zoomSlider_ValueChanged() {
+	percent := mw.zoomSlider.Value()
+
+	mw.withImageView(func(iv *walk.ImageView) {
+		if int(iv.Zoom()*100+0.5) != percent {
+			iv.SetZoom(float64(percent) / 100)
+		}
+	})
+}
+
+func (mw *MyMainWindow) pixelGridAction_Triggered() {
+	mw.withImageView(func(iv *walk.ImageView) {
+		iv.SetPixelGridVisible(mw.pixelGridAction.Checked())
+	})
 }
 
 func (mw *MyMainWindow) openAction_Triggered() {
@@ -131,12 +237,17 @@ func (mw *MyMainWindow) openImage() error {
 		}
 	}()
 
-	imageView.SetMode(walk.ImageViewModeShrink)
-
 	if err := imageView.SetImage(img); err != nil {
 		return err
 	}
 
+	imageView.ZoomChanged().Attach(func() {
+		if imageView == mw.currentImageView() {
+			mw.updateZoomSlider()
+		}
+	})
+	imageView.ZoomToFit()
+
 	if err := mw.tabWidget.Pages().Add(page); err != nil {
 		return err
 	}
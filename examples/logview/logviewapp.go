@@ -5,40 +5,134 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"math/rand"
+	"sync"
 	"time"
 )
 
 import (
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
+	"github.com/lxn/walk/highlight"
 )
 
+// logWriter collects log output from any goroutine until it is flushed to
+// the UI thread.
+type logWriter struct {
+	mutex   sync.Mutex
+	pending []byte
+}
+
+func (lw *logWriter) Write(p []byte) (int, error) {
+	lw.mutex.Lock()
+	defer lw.mutex.Unlock()
+
+	lw.pending = append(lw.pending, p...)
+
+	return len(p), nil
+}
+
+func (lw *logWriter) take() string {
+	lw.mutex.Lock()
+	defer lw.mutex.Unlock()
+
+	s := string(lw.pending)
+	lw.pending = lw.pending[:0]
+
+	return s
+}
+
 func main() {
 	var mw *walk.MainWindow
+	var cv *walk.CodeView
+	var followCB *walk.CheckBox
+	var searchLE *walk.LineEdit
+
+	buffer := walk.NewLineBuffer(1000000)
+
+	find := func(backward bool) {
+		cv.Find(searchLE.Text(), walk.FindOptions{Backward: backward})
+	}
 
 	if err := (MainWindow{
 		AssignTo: &mw,
 		Title:    "Walk LogView Example",
 		MinSize:  Size{320, 240},
-		Size:     Size{400, 600},
-		Layout:   VBox{MarginsZero: true},
+		Size:     Size{800, 600},
+		Layout:   VBox{},
+		Children: []Widget{
+			Composite{
+				Layout: HBox{MarginsZero: true},
+				Children: []Widget{
+					LineEdit{
+						AssignTo: &searchLE,
+						OnKeyDown: func(key walk.Key) {
+							if key == walk.KeyReturn {
+								find(walk.ShiftDown())
+							}
+						},
+					},
+					PushButton{
+						Text:      "Previous",
+						OnClicked: func() { find(true) },
+					},
+					PushButton{
+						Text:      "Next",
+						OnClicked: func() { find(false) },
+					},
+					CheckBox{
+						AssignTo: &followCB,
+						Text:     "Follow",
+						OnCheckedChanged: func() {
+							cv.SetFollowTail(followCB.Checked())
+						},
+					},
+				},
+			},
+			CodeView{
+				AssignTo:     &cv,
+				Highlighter:  highlight.Log(),
+				LineProvider: buffer,
+				OnBookmarksChanged: func() {
+					mw.SetTitle(fmt.Sprintf("Walk LogView Example - %d Bookmarks", len(cv.Bookmarks())))
+				},
+			},
+		},
 	}.Create()); err != nil {
 		log.Fatal(err)
 	}
 
-	lv, err := NewLogView(mw)
-	if err != nil {
-		log.Fatal(err)
-	}
+	cv.FollowTailChanged().Attach(func() {
+		followCB.SetChecked(cv.FollowTail())
+	})
+	cv.SetFollowTail(true)
+
+	lw := new(logWriter)
+	log.SetOutput(lw)
 
-	lv.PostAppendText("XXX")
-	log.SetOutput(lv)
+	// Flush the log output to the view a few times per second, instead of
+	// for each line.
+	go func() {
+		for range time.Tick(100 * time.Millisecond) {
+			if text := lw.take(); text != "" {
+				mw.Synchronize(func() {
+					buffer.AppendText(text)
+				})
+			}
+		}
+	}()
 
 	go func() {
-		for i := 0; i < 10000; i++ {
-			time.Sleep(100 * time.Millisecond)
-			log.Println("Text" + "\r\n")
+		levels := []string{"DEBUG", "INFO", "INFO", "INFO", "WARN", "ERROR"}
+
+		for i := 0; ; i++ {
+			log.Printf("%s request %d took %d ms", levels[rand.Intn(len(levels))], i, rand.Intn(500))
+
+			if i%1000 == 999 {
+				time.Sleep(100 * time.Millisecond)
+			}
 		}
 	}()
 
@@ -0,0 +1,223 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// FindOptions control how text is searched.
+type FindOptions struct {
+	MatchCase bool
+	WholeWord bool
+	Regexp    bool // The text is a regular expression in the syntax of package regexp.
+	Backward  bool
+}
+
+// FindController is implemented by widgets whose content can be searched,
+// like TextEdit, RichTextEdit, TableView, TreeView and CodeView.
+//
+// Matches are found in the text or model of a widget, not in what its
+// window shows.
+type FindController interface {
+	// Find goes to the next match of text after the current one, or the
+	// previous one if opts.Backward is set, wrapping around. It returns
+	// whether there was a match.
+	Find(text string, opts FindOptions) bool
+}
+
+// ReplaceController is a FindController whose matches can be replaced,
+// like TextEdit and RichTextEdit.
+//
+// With FindOptions.Regexp, replacements can refer to submatches, like $1,
+// as in regexp.Regexp.Expand.
+type ReplaceController interface {
+	FindController
+
+	// Replace replaces the current match of text with replacement, then
+	// goes to the next one like Find. It returns whether there was one.
+	Replace(text, replacement string, opts FindOptions) bool
+
+	// ReplaceAll replaces all matches of text with replacement and returns
+	// how many there were. opts.Backward is ignored.
+	ReplaceAll(text, replacement string, opts FindOptions) int
+}
+
+// FindRegexp returns the regular expression that matches text with opts,
+// or nil if text is empty. The error is the one of regexp.Compile if
+// opts.Regexp is set and text is no valid regular expression.
+func FindRegexp(text string, opts FindOptions) (*regexp.Regexp, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	pattern := text
+	if !opts.Regexp {
+		pattern = regexp.QuoteMeta(text)
+	}
+	if opts.WholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if !opts.MatchCase {
+		pattern = `(?i)` + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// textFindTarget is a widget with editable text, whose positions are in
+// UTF-16 code units, like those of edit controls.
+type textFindTarget interface {
+	// findText returns the text in which the positions of the selection
+	// are.
+	findText() string
+
+	TextSelection() (start, end int)
+	SetTextSelection(start, end int)
+	ReplaceSelectedText(text string, canUndo bool)
+	ScrollToCaret()
+	SetSuspended(suspend bool)
+}
+
+// textMatch is a match in the text of a textFindTarget.
+type textMatch struct {
+	start, end int   // in UTF-16 code units
+	loc        []int // The byte offsets of the match and its submatches.
+}
+
+// findTextMatches returns the non-empty matches of re in text.
+func findTextMatches(text string, re *regexp.Regexp) []textMatch {
+	var matches []textMatch
+
+	// The UTF-16 position of the byte offset pos.
+	pos, pos16 := 0, 0
+	advance := func(to int) int {
+		for pos < to {
+			r, size := utf8.DecodeRuneInString(text[pos:])
+			pos += size
+			pos16++
+			if r >= 0x10000 {
+				pos16++
+			}
+		}
+		return pos16
+	}
+
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+
+		start := advance(loc[0])
+		end := advance(loc[1])
+
+		matches = append(matches, textMatch{start, end, loc})
+	}
+
+	return matches
+}
+
+// nextTextMatch returns the first match after the selection, or the last
+// one before it if backward is set, wrapping around.
+func nextTextMatch(matches []textMatch, selStart, selEnd int, backward bool) textMatch {
+	if backward {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i].start < selStart {
+				return matches[i]
+			}
+		}
+
+		return matches[len(matches)-1]
+	}
+
+	for _, m := range matches {
+		if m.start >= selEnd {
+			return m
+		}
+	}
+
+	return matches[0]
+}
+
+func findInTextTarget(t textFindTarget, text string, opts FindOptions) bool {
+	re, _ := FindRegexp(text, opts)
+	if re == nil {
+		return false
+	}
+
+	matches := findTextMatches(t.findText(), re)
+	if len(matches) == 0 {
+		return false
+	}
+
+	start, end := t.TextSelection()
+	m := nextTextMatch(matches, start, end, opts.Backward)
+
+	t.SetTextSelection(m.start, m.end)
+	t.ScrollToCaret()
+
+	return true
+}
+
+func replaceInTextTarget(t textFindTarget, text, replacement string, opts FindOptions) bool {
+	re, _ := FindRegexp(text, opts)
+	if re == nil {
+		return false
+	}
+
+	content := t.findText()
+	start, end := t.TextSelection()
+
+	for _, m := range findTextMatches(content, re) {
+		if m.start == start && m.end == end {
+			t.ReplaceSelectedText(expandReplacement(re, content, replacement, m, opts), true)
+
+			if opts.Backward {
+				t.SetTextSelection(start, start)
+			}
+			break
+		}
+	}
+
+	return findInTextTarget(t, text, opts)
+}
+
+func replaceAllInTextTarget(t textFindTarget, text, replacement string, opts FindOptions) int {
+	re, _ := FindRegexp(text, opts)
+	if re == nil {
+		return 0
+	}
+
+	content := t.findText()
+	matches := findTextMatches(content, re)
+	if len(matches) == 0 {
+		return 0
+	}
+
+	t.SetSuspended(true)
+	defer t.SetSuspended(false)
+
+	// From the last to the first, so that the positions of the others stay
+	// valid.
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+
+		t.SetTextSelection(m.start, m.end)
+		t.ReplaceSelectedText(expandReplacement(re, content, replacement, m, opts), true)
+	}
+
+	return len(matches)
+}
+
+func expandReplacement(re *regexp.Regexp, content, replacement string, m textMatch, opts FindOptions) string {
+	if !opts.Regexp {
+		return replacement
+	}
+
+	return string(re.ExpandString(nil, replacement, content, m.loc))
+}
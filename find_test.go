@@ -0,0 +1,241 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestFindRegexp(t *testing.T) {
+	tests := []struct {
+		text    string
+		opts    FindOptions
+		subject string
+		want    []string
+	}{
+		{"go", FindOptions{}, "Go gopher GO", []string{"Go", "go", "GO"}},
+		{"go", FindOptions{MatchCase: true}, "Go gopher GO", []string{"go"}},
+		{"go", FindOptions{WholeWord: true}, "Go gopher GO", []string{"Go", "GO"}},
+		{"go", FindOptions{WholeWord: true, MatchCase: true}, "Go gopher GO", nil},
+		{"go", FindOptions{WholeWord: true, MatchCase: true}, "go Go gopher", []string{"go"}},
+		{"a.b", FindOptions{}, "a.b axb", []string{"a.b"}},
+		{"a.b", FindOptions{Regexp: true}, "a.b axb", []string{"a.b", "axb"}},
+		// WholeWord applies to all alternatives.
+		{"cat|dog", FindOptions{Regexp: true, WholeWord: true}, "cats dog", []string{"dog"}},
+		{"cat|dog", FindOptions{WholeWord: true}, "cat|dog cat", []string{"cat|dog"}},
+		{"Straße", FindOptions{}, "STRASSE straße STRAßE", []string{"straße", "STRAßE"}},
+	}
+
+	for _, test := range tests {
+		re, err := FindRegexp(test.text, test.opts)
+		if err != nil {
+			t.Errorf("FindRegexp(%q, %+v): %v", test.text, test.opts, err)
+			continue
+		}
+
+		if got := re.FindAllString(test.subject, -1); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FindRegexp(%q, %+v) in %q: got %q, want %q", test.text, test.opts, test.subject, got, test.want)
+		}
+	}
+
+	if re, err := FindRegexp("", FindOptions{}); re != nil || err != nil {
+		t.Errorf("FindRegexp of empty text: got %v, %v, want nil, nil", re, err)
+	}
+	if _, err := FindRegexp("(", FindOptions{Regexp: true}); err == nil {
+		t.Error("FindRegexp of invalid regexp: got no error")
+	}
+	if _, err := FindRegexp("(", FindOptions{}); err != nil {
+		t.Errorf("FindRegexp of ( as plain text: %v", err)
+	}
+}
+
+func TestFindTextMatches(t *testing.T) {
+	type span struct{ start, end int }
+
+	tests := []struct {
+		pattern string
+		text    string
+		want    []span
+	}{
+		{"a", "abca", []span{{0, 1}, {3, 4}}},
+		// Runes outside of the BMP take two UTF-16 code units.
+		{"a", "a😀b a", []span{{0, 1}, {5, 6}}},
+		{"😀", "é😀x😀", []span{{1, 3}, {4, 6}}},
+		{"x", "é😀x", []span{{3, 4}}},
+		{"b+", "😀😀bb😀b", []span{{4, 6}, {8, 9}}},
+		// Empty matches are skipped.
+		{"x*", "axb", []span{{1, 2}}},
+		{"q", "😀", nil},
+	}
+
+	for _, test := range tests {
+		re, err := FindRegexp(test.pattern, FindOptions{Regexp: true, MatchCase: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []span
+		for _, m := range findTextMatches(test.text, re) {
+			got = append(got, span{m.start, m.end})
+
+			if want := test.text[m.loc[0]:m.loc[1]]; string(utf16.Decode(utf16.Encode([]rune(test.text))[m.start:m.end])) != want {
+				t.Errorf("%q in %q: UTF-16 range %d-%d does not match %q", test.pattern, test.text, m.start, m.end, want)
+			}
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q in %q: got %v, want %v", test.pattern, test.text, got, test.want)
+		}
+	}
+}
+
+func TestNextTextMatch(t *testing.T) {
+	matches := []textMatch{{start: 0, end: 1}, {start: 4, end: 5}, {start: 8, end: 9}}
+
+	tests := []struct {
+		selStart, selEnd int
+		backward         bool
+		want             int
+	}{
+		{0, 0, false, 0},
+		{0, 1, false, 4},
+		{2, 2, false, 4},
+		{4, 5, false, 8},
+		{8, 9, false, 0}, // wraps around
+		{9, 9, false, 0}, // wraps around
+		{8, 9, true, 4},
+		{4, 5, true, 0},
+		{2, 2, true, 0},
+		{0, 1, true, 8}, // wraps around
+		{0, 0, true, 8}, // wraps around
+		{9, 9, true, 8},
+	}
+
+	for _, test := range tests {
+		if got := nextTextMatch(matches, test.selStart, test.selEnd, test.backward); got.start != test.want {
+			t.Errorf("selection %d-%d, backward %t: got %d, want %d", test.selStart, test.selEnd, test.backward, got.start, test.want)
+		}
+	}
+}
+
+// testFindTarget is a textFindTarget that keeps its text in UTF-16, like an
+// edit control.
+type testFindTarget struct {
+	text             []uint16
+	selStart, selEnd int
+}
+
+func newTestFindTarget(text string) *testFindTarget {
+	return &testFindTarget{text: utf16.Encode([]rune(text))}
+}
+
+func (tft *testFindTarget) findText() string {
+	return string(utf16.Decode(tft.text))
+}
+
+func (tft *testFindTarget) TextSelection() (start, end int) {
+	return tft.selStart, tft.selEnd
+}
+
+func (tft *testFindTarget) SetTextSelection(start, end int) {
+	tft.selStart, tft.selEnd = start, end
+}
+
+func (tft *testFindTarget) ReplaceSelectedText(text string, canUndo bool) {
+	replacement := utf16.Encode([]rune(text))
+
+	var buf []uint16
+	buf = append(buf, tft.text[:tft.selStart]...)
+	buf = append(buf, replacement...)
+	buf = append(buf, tft.text[tft.selEnd:]...)
+
+	tft.text = buf
+	tft.selStart += len(replacement)
+	tft.selEnd = tft.selStart
+}
+
+func (tft *testFindTarget) ScrollToCaret() {
+}
+
+func (tft *testFindTarget) SetSuspended(suspend bool) {
+}
+
+func TestFindInTextTarget(t *testing.T) {
+	tft := newTestFindTarget("😀 foo 😀 Foo")
+
+	steps := []struct {
+		opts       FindOptions
+		start, end int
+	}{
+		{FindOptions{Backward: true}, 10, 13}, // wraps around
+		{FindOptions{Backward: true}, 3, 6},
+		{FindOptions{Backward: true}, 10, 13}, // wraps around
+		{FindOptions{}, 3, 6},                 // wraps around
+		{FindOptions{}, 10, 13},
+		{FindOptions{MatchCase: true}, 3, 6}, // wraps around
+		{FindOptions{MatchCase: true}, 3, 6}, // the only match
+		{FindOptions{MatchCase: true, Backward: true}, 3, 6},
+	}
+
+	for i, step := range steps {
+		if !findInTextTarget(tft, "foo", step.opts) {
+			t.Fatalf("step %d: no match", i)
+		}
+
+		if start, end := tft.TextSelection(); start != step.start || end != step.end {
+			t.Errorf("step %d, %+v: got %d-%d, want %d-%d", i, step.opts, start, end, step.start, step.end)
+		}
+	}
+
+	if findInTextTarget(tft, "FOO", FindOptions{MatchCase: true}) {
+		t.Error("FOO with MatchCase: got a match")
+	}
+	if findInTextTarget(tft, "", FindOptions{}) {
+		t.Error("empty text: got a match")
+	}
+}
+
+func TestReplaceInTextTarget(t *testing.T) {
+	tft := newTestFindTarget("😀 foo 😀 Foo")
+
+	// Without a selected match, Replace only finds the next one.
+	if !replaceInTextTarget(tft, "foo", "bär", FindOptions{}) {
+		t.Fatal("no match")
+	}
+	if got, want := tft.findText(), "😀 foo 😀 Foo"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+
+	if !replaceInTextTarget(tft, "foo", "bär", FindOptions{}) {
+		t.Fatal("no match")
+	}
+	if got, want := tft.findText(), "😀 bär 😀 Foo"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+	if start, end := tft.TextSelection(); start != 10 || end != 13 {
+		t.Errorf("selection: got %d-%d, want 10-13", start, end)
+	}
+
+	tft = newTestFindTarget("😀 foo 😀 fooo")
+	if got := replaceAllInTextTarget(tft, "(f)(o+)", "$2$1", FindOptions{Regexp: true}); got != 2 {
+		t.Errorf("ReplaceAll count: got %d, want 2", got)
+	}
+	if got, want := tft.findText(), "😀 oof 😀 ooof"; got != want {
+		t.Errorf("ReplaceAll text: got %q, want %q", got, want)
+	}
+
+	// Without Regexp, $ in the replacement is no reference.
+	tft = newTestFindTarget("a.b a.b")
+	if got := replaceAllInTextTarget(tft, "a.b", "$1", FindOptions{WholeWord: true}); got != 2 {
+		t.Errorf("ReplaceAll count: got %d, want 2", got)
+	}
+	if got, want := tft.findText(), "$1 $1"; got != want {
+		t.Errorf("ReplaceAll text: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,450 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+)
+
+// searchTextSetter is implemented by FindControllers that highlight all
+// matches of the search text, like CodeView.
+type searchTextSetter interface {
+	SetSearchText(text string, opts FindOptions) error
+}
+
+// FindBar is a bar with the search text and options for a FindController,
+// and, if the controller is a ReplaceController, the replacement.
+//
+// Its actions show the bar and go to the next and previous match. For
+// their shortcuts to work, add them to a menu or tool bar, or to the
+// ShortcutActions of the form.
+type FindBar struct {
+	*Composite
+	controller         FindController
+	textEdit           *LineEdit
+	matchCaseCheckBox  *CheckBox
+	wholeWordCheckBox  *CheckBox
+	regexpCheckBox     *CheckBox
+	statusLabel        *Label
+	replaceComposite   *Composite
+	replaceEdit        *LineEdit
+	findAction         *Action
+	findNextAction     *Action
+	findPreviousAction *Action
+	replaceAction      *Action
+}
+
+// NewFindBar creates and initializes a new FindBar.
+func NewFindBar(parent Container) (*FindBar, error) {
+	composite, err := NewComposite(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	fb := &FindBar{Composite: composite}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			fb.Dispose()
+		}
+	}()
+
+	if err := InitWrapperWindow(fb); err != nil {
+		return nil, err
+	}
+
+	if err := fb.createChildren(); err != nil {
+		return nil, err
+	}
+
+	if err := fb.createActions(); err != nil {
+		return nil, err
+	}
+
+	fb.updateActions()
+
+	succeeded = true
+
+	return fb, nil
+}
+
+func (fb *FindBar) createChildren() error {
+	layout := NewVBoxLayout()
+	layout.SetMargins(Margins{4, 4, 4, 4})
+	layout.SetSpacing(4)
+	if err := fb.SetLayout(layout); err != nil {
+		return err
+	}
+
+	findComposite, err := newFindBarRow(fb)
+	if err != nil {
+		return err
+	}
+
+	if fb.textEdit, err = NewLineEdit(findComposite); err != nil {
+		return err
+	}
+	fb.textEdit.SetCueBanner(tr("Find", "walk"))
+	fb.textEdit.TextChanged().Attach(fb.searchChanged)
+	fb.textEdit.KeyDown().Attach(func(key Key) {
+		fb.editKeyDown(key, fb.FindNext)
+	})
+
+	if err := newFindBarButton(findComposite, tr("Previous", "walk"), func() { fb.FindPrevious() }); err != nil {
+		return err
+	}
+
+	if err := newFindBarButton(findComposite, tr("Next", "walk"), func() { fb.FindNext() }); err != nil {
+		return err
+	}
+
+	for _, cb := range []struct {
+		checkBox **CheckBox
+		text     string
+	}{
+		{&fb.matchCaseCheckBox, tr("Match case", "walk")},
+		{&fb.wholeWordCheckBox, tr("Whole word", "walk")},
+		{&fb.regexpCheckBox, tr("Regular expression", "walk")},
+	} {
+		checkBox, err := NewCheckBox(findComposite)
+		if err != nil {
+			return err
+		}
+		checkBox.SetText(cb.text)
+		checkBox.CheckedChanged().Attach(fb.searchChanged)
+
+		*cb.checkBox = checkBox
+	}
+
+	if fb.statusLabel, err = NewLabel(findComposite); err != nil {
+		return err
+	}
+
+	if _, err := NewHSpacer(findComposite); err != nil {
+		return err
+	}
+
+	if err := newFindBarButton(findComposite, "×", fb.Close); err != nil {
+		return err
+	}
+
+	if fb.replaceComposite, err = newFindBarRow(fb); err != nil {
+		return err
+	}
+	fb.replaceComposite.SetVisible(false)
+
+	if fb.replaceEdit, err = NewLineEdit(fb.replaceComposite); err != nil {
+		return err
+	}
+	fb.replaceEdit.SetCueBanner(tr("Replace", "walk"))
+	fb.replaceEdit.KeyDown().Attach(func(key Key) {
+		fb.editKeyDown(key, fb.Replace)
+	})
+
+	if err := newFindBarButton(fb.replaceComposite, tr("Replace", "walk"), func() { fb.Replace() }); err != nil {
+		return err
+	}
+
+	if err := newFindBarButton(fb.replaceComposite, tr("Replace All", "walk"), func() { fb.ReplaceAll() }); err != nil {
+		return err
+	}
+
+	_, err = NewHSpacer(fb.replaceComposite)
+
+	return err
+}
+
+func newFindBarRow(parent Container) (*Composite, error) {
+	composite, err := NewComposite(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := NewHBoxLayout()
+	layout.SetMargins(Margins{})
+	if err := composite.SetLayout(layout); err != nil {
+		return nil, err
+	}
+
+	return composite, nil
+}
+
+func newFindBarButton(parent Container, text string, clicked func()) error {
+	button, err := NewPushButton(parent)
+	if err != nil {
+		return err
+	}
+
+	if err := button.SetText(text); err != nil {
+		return err
+	}
+
+	button.Clicked().Attach(clicked)
+
+	return nil
+}
+
+func (fb *FindBar) createActions() error {
+	for _, a := range []struct {
+		action    **Action
+		text      string
+		shortcut  Shortcut
+		triggered func()
+	}{
+		{&fb.findAction, tr("&Find...", "walk"), Shortcut{ModControl, KeyF}, fb.ShowFind},
+		{&fb.findNextAction, tr("Find &Next", "walk"), Shortcut{0, KeyF3}, func() { fb.FindNext() }},
+		{&fb.findPreviousAction, tr("Find &Previous", "walk"), Shortcut{ModShift, KeyF3}, func() { fb.FindPrevious() }},
+		{&fb.replaceAction, tr("&Replace...", "walk"), Shortcut{ModControl, KeyH}, fb.ShowReplace},
+	} {
+		action := NewAction()
+
+		if err := action.SetText(a.text); err != nil {
+			return err
+		}
+		if err := action.SetShortcut(a.shortcut); err != nil {
+			return err
+		}
+		action.Triggered().Attach(a.triggered)
+
+		*a.action = action
+	}
+
+	return nil
+}
+
+// Controller returns the FindController that is searched.
+func (fb *FindBar) Controller() FindController {
+	return fb.controller
+}
+
+// SetController sets the FindController that is searched.
+func (fb *FindBar) SetController(controller FindController) {
+	fb.clearSearchText()
+
+	fb.controller = controller
+
+	if _, ok := controller.(ReplaceController); !ok {
+		fb.replaceComposite.SetVisible(false)
+	}
+
+	fb.searchChanged()
+}
+
+// Text returns the search text.
+func (fb *FindBar) Text() string {
+	return fb.textEdit.Text()
+}
+
+// SetText sets the search text.
+func (fb *FindBar) SetText(text string) error {
+	return fb.textEdit.SetText(text)
+}
+
+// Options returns the search options that are checked. Backward is never
+// set.
+func (fb *FindBar) Options() FindOptions {
+	return FindOptions{
+		MatchCase: fb.matchCaseCheckBox.Checked(),
+		WholeWord: fb.wholeWordCheckBox.Checked(),
+		Regexp:    fb.regexpCheckBox.Checked(),
+	}
+}
+
+// SetOptions checks the search options. Backward is ignored.
+func (fb *FindBar) SetOptions(opts FindOptions) {
+	fb.matchCaseCheckBox.SetChecked(opts.MatchCase)
+	fb.wholeWordCheckBox.SetChecked(opts.WholeWord)
+	fb.regexpCheckBox.SetChecked(opts.Regexp)
+}
+
+// FindAction returns the action that shows the bar for searching. Its
+// shortcut is Ctrl+F.
+func (fb *FindBar) FindAction() *Action {
+	return fb.findAction
+}
+
+// FindNextAction returns the action that goes to the next match. Its
+// shortcut is F3.
+func (fb *FindBar) FindNextAction() *Action {
+	return fb.findNextAction
+}
+
+// FindPreviousAction returns the action that goes to the previous match.
+// Its shortcut is Shift+F3.
+func (fb *FindBar) FindPreviousAction() *Action {
+	return fb.findPreviousAction
+}
+
+// ReplaceAction returns the action that shows the bar for replacing. Its
+// shortcut is Ctrl+H.
+func (fb *FindBar) ReplaceAction() *Action {
+	return fb.replaceAction
+}
+
+// ShowFind shows the bar and focuses the search text.
+func (fb *FindBar) ShowFind() {
+	fb.SetVisible(true)
+
+	fb.textEdit.SetFocus()
+	fb.textEdit.SetTextSelection(0, -1)
+}
+
+// ShowReplace shows the bar with the replacement, if the controller is a
+// ReplaceController, and focuses the search text.
+func (fb *FindBar) ShowReplace() {
+	if _, ok := fb.controller.(ReplaceController); ok {
+		fb.replaceComposite.SetVisible(true)
+	}
+
+	fb.ShowFind()
+}
+
+// Close hides the bar, removes the highlights of the search text and
+// focuses the controller, if it is a Widget.
+func (fb *FindBar) Close() {
+	fb.clearSearchText()
+
+	fb.replaceComposite.SetVisible(false)
+	fb.SetVisible(false)
+
+	if widget, ok := fb.controller.(Widget); ok {
+		widget.SetFocus()
+	}
+}
+
+// FindNext goes to the next match and returns whether there was one.
+func (fb *FindBar) FindNext() bool {
+	return fb.find(false)
+}
+
+// FindPrevious goes to the previous match and returns whether there was
+// one.
+func (fb *FindBar) FindPrevious() bool {
+	return fb.find(true)
+}
+
+func (fb *FindBar) find(backward bool) bool {
+	opts, ok := fb.validOptions()
+	if !ok {
+		return false
+	}
+	opts.Backward = backward
+
+	found := fb.controller.Find(fb.Text(), opts)
+
+	fb.setFound(found)
+
+	return found
+}
+
+// Replace replaces the current match and goes to the next one. It returns
+// whether there was one.
+func (fb *FindBar) Replace() bool {
+	rc, ok := fb.controller.(ReplaceController)
+	if !ok {
+		return false
+	}
+
+	opts, ok := fb.validOptions()
+	if !ok {
+		return false
+	}
+
+	found := rc.Replace(fb.Text(), fb.replaceEdit.Text(), opts)
+
+	fb.setFound(found)
+
+	return found
+}
+
+// ReplaceAll replaces all matches and returns how many there were.
+func (fb *FindBar) ReplaceAll() int {
+	rc, ok := fb.controller.(ReplaceController)
+	if !ok {
+		return 0
+	}
+
+	opts, ok := fb.validOptions()
+	if !ok {
+		return 0
+	}
+
+	count := rc.ReplaceAll(fb.Text(), fb.replaceEdit.Text(), opts)
+
+	fb.statusLabel.SetText(fmt.Sprintf(tr("%d replaced", "walk"), count))
+
+	return count
+}
+
+// validOptions returns the options, and false if there is nothing to search
+// or the search text is invalid.
+func (fb *FindBar) validOptions() (FindOptions, bool) {
+	opts := fb.Options()
+
+	if fb.controller == nil {
+		return opts, false
+	}
+
+	re, err := FindRegexp(fb.Text(), opts)
+	if err != nil {
+		fb.statusLabel.SetText(err.Error())
+	}
+
+	return opts, re != nil
+}
+
+func (fb *FindBar) setFound(found bool) {
+	if found {
+		fb.statusLabel.SetText("")
+	} else {
+		fb.statusLabel.SetText(tr("No matches", "walk"))
+	}
+}
+
+func (fb *FindBar) searchChanged() {
+	fb.statusLabel.SetText("")
+
+	if sts, ok := fb.controller.(searchTextSetter); ok {
+		if err := sts.SetSearchText(fb.Text(), fb.Options()); err != nil {
+			fb.statusLabel.SetText(err.Error())
+		}
+	} else if _, err := FindRegexp(fb.Text(), fb.Options()); err != nil {
+		fb.statusLabel.SetText(err.Error())
+	}
+
+	fb.updateActions()
+}
+
+func (fb *FindBar) clearSearchText() {
+	if sts, ok := fb.controller.(searchTextSetter); ok {
+		sts.SetSearchText("", FindOptions{})
+	}
+}
+
+func (fb *FindBar) updateActions() {
+	canFind := fb.controller != nil && fb.Text() != ""
+	_, canReplace := fb.controller.(ReplaceController)
+
+	fb.findNextAction.SetEnabled(canFind)
+	fb.findPreviousAction.SetEnabled(canFind)
+	fb.replaceAction.SetEnabled(canReplace)
+}
+
+func (fb *FindBar) editKeyDown(key Key, enter func() bool) {
+	switch key {
+	case KeyReturn:
+		if ShiftDown() {
+			fb.FindPrevious()
+		} else {
+			enter()
+		}
+
+	case KeyEscape:
+		fb.Close()
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/lxn/walk/stylesheet"
 	"github.com/lxn/win"
 )
 
@@ -92,6 +93,7 @@ type FormBase struct {
 	iconChangedPublisher        EventPublisher
 	progressIndicator           *ProgressIndicator
 	icon                        Image
+	iconKeys                    [2]iconCacheKey // Of the small and big cached icons of icon.
 	prevFocusHWnd               win.HWND
 	proposedSize                Size // in native pixels
 	closeReason                 CloseReason
@@ -100,6 +102,9 @@ type FormBase struct {
 	isInRestoreState            bool
 	started                     bool
 	layoutScheduled             bool
+	themeBackground             Brush
+	styleSheet                  *stylesheet.StyleSheet
+	styled                      bool
 }
 
 func (fb *FormBase) init(form Form) error {
@@ -117,19 +122,13 @@ func (fb *FormBase) init(form Form) error {
 			return fb.Icon()
 		},
 		func(v interface{}) error {
-			icon, err := IconFrom(v, fb.DPI())
+			// SetIcon retains the icons it renders for the DPIs it needs.
+			img, err := ImageFrom(v)
 			if err != nil {
 				return err
 			}
 
-			var img Image
-			if icon != nil {
-				img = icon
-			}
-
-			fb.SetIcon(img)
-
-			return nil
+			return fb.SetIcon(img)
 		},
 		fb.iconChangedPublisher.Event()))
 
@@ -149,6 +148,10 @@ func (fb *FormBase) init(form Form) error {
 
 	fb.performLayout, fb.layoutResults, fb.inSizeLoop, fb.updateStopwatch, fb.quitLayoutPerformer = startLayoutPerformer(fb)
 
+	if theme := App().Theme(); theme != LightTheme() {
+		fb.applyTheme(theme)
+	}
+
 	return nil
 }
 
@@ -158,6 +161,15 @@ func (fb *FormBase) Dispose() {
 	}
 
 	fb.WindowBase.Dispose()
+
+	fb.releaseIcons()
+}
+
+func (fb *FormBase) releaseIcons() {
+	for i, key := range fb.iconKeys {
+		iconCache.release(key)
+		fb.iconKeys[i] = iconCacheKey{}
+	}
 }
 
 func (fb *FormBase) AsContainerBase() *ContainerBase {
@@ -358,6 +370,8 @@ func (fb *FormBase) Run() int {
 		defer invalidateDescendentBorders()
 	}
 
+	fb.ApplyStyleSheet()
+
 	fb.started = true
 	fb.startingPublisher.Publish()
 
@@ -518,6 +532,7 @@ func (fb *FormBase) Icon() Image {
 
 func (fb *FormBase) SetIcon(icon Image) error {
 	var hIconSmall, hIconBig uintptr
+	var keys [2]iconCacheKey
 
 	if icon != nil {
 		dpi := fb.DPI()
@@ -529,20 +544,26 @@ func (fb *FormBase) SetIcon(icon Image) error {
 		if err != nil {
 			return err
 		}
+		keys[0] = iconCacheKey{icon, smallDPI, true}
 		hIconSmall = uintptr(smallIcon.handleForDPI(smallDPI))
 
 		bigHeight := int(win.GetSystemMetricsForDpi(win.SM_CYICON, uint32(dpi)))
 		bigDPI := int(math.Round(float64(bigHeight) / float64(size96dpi.Height) * 96.0))
 		bigIcon, err := iconCache.Icon(icon, bigDPI)
 		if err != nil {
+			iconCache.release(keys[0])
 			return err
 		}
+		keys[1] = iconCacheKey{icon, bigDPI, true}
 		hIconBig = uintptr(bigIcon.handleForDPI(bigDPI))
 	}
 
 	fb.SendMessage(win.WM_SETICON, 0, hIconSmall)
 	fb.SendMessage(win.WM_SETICON, 1, hIconBig)
 
+	fb.releaseIcons()
+	fb.iconKeys = keys
+
 	fb.icon = icon
 
 	fb.iconChangedPublisher.Publish()
@@ -559,6 +580,8 @@ func (fb *FormBase) Hide() {
 }
 
 func (fb *FormBase) Show() {
+	fb.ApplyStyleSheet()
+
 	fb.proposedSize = maxSize(SizeFrom96DPI(fb.minSize96dpi, fb.DPI()), fb.SizePixels())
 
 	if p, ok := fb.window.(Persistable); ok && p.Persistent() && App().Settings() != nil {
@@ -592,6 +615,18 @@ func (fb *FormBase) SetPersistent(value bool) {
 	fb.clientComposite.persistent = value
 }
 
+// LayoutAnimationDuration returns the duration of the animation that moves
+// children of the *FormBase to their new bounds after a layout pass.
+func (fb *FormBase) LayoutAnimationDuration() time.Duration {
+	return fb.clientComposite.layoutAnimationDuration
+}
+
+// SetLayoutAnimationDuration sets the duration of the animation that moves
+// children of the *FormBase to their new bounds after a layout pass.
+func (fb *FormBase) SetLayoutAnimationDuration(duration time.Duration) {
+	fb.clientComposite.layoutAnimationDuration = duration
+}
+
 func (fb *FormBase) SaveState() error {
 	if err := fb.clientComposite.SaveState(); err != nil {
 		return err
@@ -825,6 +860,9 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 	case win.WM_SYSCOLORCHANGE:
 		fb.ApplySysColors()
 
+	case win.WM_SETTINGCHANGE:
+		App().handleSettingChange(lParam)
+
 	case win.WM_DPICHANGED:
 		wasSuspended := fb.Suspended()
 		fb.SetSuspended(true)
@@ -845,6 +883,7 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 			fb.progressIndicator.SetOverlayIcon(fb.progressIndicator.overlayIcon, fb.progressIndicator.overlayIconDescription)
 		}
 		applyDPIToDescendants(fb.window, dpi)
+		fb.ApplyStyleSheet()
 
 		fb.SetSuspended(wasSuspended)
 
@@ -885,8 +924,12 @@ func (fb *FormBase) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) u
 		}
 
 	case taskbarCreatedMsgId:
+		// Every top-level window gets this message, so each form only
+		// takes care of its own NotifyIcons.
 		for ni := range notifyIcons {
-			ni.readdToTaskbar()
+			if ni.hWnd == hwnd {
+				ni.readdToTaskbar()
+			}
 		}
 	}
 
@@ -17,6 +17,8 @@ type GradientComposite struct {
 	vertical                 bool
 	color1                   Color
 	color2                   Color
+	color1Set                bool
+	color2Set                bool
 	verticalChangedPublisher EventPublisher
 	color1ChangedPublisher   EventPublisher
 	color2ChangedPublisher   EventPublisher
@@ -139,24 +141,30 @@ func (gc *GradientComposite) SetVertical(vertical bool) (err error) {
 	return
 }
 
+// Color1 returns the first color of the gradient. Unless it has been set,
+// this is the ThemeColorGradient1 color of the current theme.
 func (gc *GradientComposite) Color1() Color {
+	if !gc.color1Set {
+		return App().Theme().Color(ThemeColorGradient1)
+	}
+
 	return gc.color1
 }
 
 func (gc *GradientComposite) SetColor1(c Color) (err error) {
-	if c == gc.color1 {
+	if gc.color1Set && c == gc.color1 {
 		return nil
 	}
 
-	old := gc.color1
+	old, oldSet := gc.color1, gc.color1Set
 
 	defer func() {
 		if err != nil {
-			gc.color1 = old
+			gc.color1, gc.color1Set = old, oldSet
 		}
 	}()
 
-	gc.color1 = c
+	gc.color1, gc.color1Set = c, true
 
 	if err = gc.updateBackground(); err != nil {
 		return
@@ -167,24 +175,30 @@ func (gc *GradientComposite) SetColor1(c Color) (err error) {
 	return
 }
 
+// Color2 returns the second color of the gradient. Unless it has been set,
+// this is the ThemeColorGradient2 color of the current theme.
 func (gc *GradientComposite) Color2() Color {
+	if !gc.color2Set {
+		return App().Theme().Color(ThemeColorGradient2)
+	}
+
 	return gc.color2
 }
 
 func (gc *GradientComposite) SetColor2(c Color) (err error) {
-	if c == gc.color2 {
+	if gc.color2Set && c == gc.color2 {
 		return nil
 	}
 
-	old := gc.color2
+	old, oldSet := gc.color2, gc.color2Set
 
 	defer func() {
 		if err != nil {
-			gc.color2 = old
+			gc.color2, gc.color2Set = old, oldSet
 		}
 	}()
 
-	gc.color2 = c
+	gc.color2, gc.color2Set = c, true
 
 	if err = gc.updateBackground(); err != nil {
 		return
@@ -195,6 +209,14 @@ func (gc *GradientComposite) SetColor2(c Color) (err error) {
 	return
 }
 
+func (gc *GradientComposite) ApplySysColors() {
+	gc.Composite.ApplySysColors()
+
+	if !gc.color1Set || !gc.color2Set {
+		gc.updateBackground()
+	}
+}
+
 func (gc *GradientComposite) updateBackground() error {
 	bounds := gc.ClientBoundsPixels()
 	if bounds.Width < 1 || bounds.Height < 1 {
@@ -236,7 +258,7 @@ func (gc *GradientComposite) updateBackground() error {
 		orientation = Horizontal
 	}
 
-	if err := canvas.GradientFillRectanglePixels(gc.color1, gc.color2, orientation, bounds); err != nil {
+	if err := canvas.GradientFillRectanglePixels(gc.Color1(), gc.Color2(), orientation, bounds); err != nil {
 		return err
 	}
 
@@ -0,0 +1,240 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package highlight splits lines of source code or log output into tokens
+// for syntax highlighting.
+//
+// Highlighters work on single lines, so that a view can highlight any line
+// without looking at the ones before it. Constructs that span lines, like
+// block comments or raw strings, are highlighted per line.
+package highlight
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind classifies a token.
+type Kind int
+
+const (
+	Plain Kind = iota
+	Keyword
+	Type
+	Literal // Like true, false, nil or null.
+	String
+	Number
+	Comment
+	Key // An object key, like in JSON.
+	Punctuation
+	Timestamp
+	Error
+	Warning
+	Info
+	Debug
+)
+
+// Token is a highlighted part of a line. Start and End are byte offsets.
+type Token struct {
+	Start, End int
+	Kind       Kind
+}
+
+// Highlighter splits a line into tokens.
+//
+// The tokens must be sorted and must not overlap. Parts of the line that are
+// not covered by a token are Plain.
+type Highlighter interface {
+	Highlight(line string) []Token
+}
+
+// Rule is a regular expression for a kind of token.
+//
+// If the expression has a capturing group, only the text of the first group
+// is the token and the remainder of the match is left to the other rules.
+// This stands in for lookahead, which package regexp does not support.
+type Rule struct {
+	Pattern string
+	Kind    Kind
+}
+
+// RegexpHighlighter is a Highlighter that tries its rules in order at each
+// position of a line. It is safe for concurrent use.
+type RegexpHighlighter struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	re   *regexp.Regexp
+	kind Kind
+}
+
+// NewRegexpHighlighter returns a RegexpHighlighter with the given rules.
+func NewRegexpHighlighter(rules ...Rule) (*RegexpHighlighter, error) {
+	rh := &RegexpHighlighter{rules: make([]compiledRule, len(rules))}
+
+	for i, rule := range rules {
+		re, err := regexp.Compile(`^(?:` + rule.Pattern + `)`)
+		if err != nil {
+			return nil, err
+		}
+
+		rh.rules[i] = compiledRule{re, rule.Kind}
+	}
+
+	return rh, nil
+}
+
+func mustRegexpHighlighter(rules ...Rule) *RegexpHighlighter {
+	rh, err := NewRegexpHighlighter(rules...)
+	if err != nil {
+		panic(err)
+	}
+
+	return rh
+}
+
+// Highlight implements the Highlighter interface.
+func (rh *RegexpHighlighter) Highlight(line string) []Token {
+	var tokens []Token
+
+	for pos := 0; pos < len(line); {
+		start, end, kind, next := rh.match(line, pos)
+
+		if next > pos {
+			if kind != Plain && end > start {
+				tokens = append(tokens, Token{start, end, kind})
+			}
+
+			pos = next
+			continue
+		}
+
+		// Skip the rest of a word, so that rules don't match inside of it.
+		r, size := utf8.DecodeRuneInString(line[pos:])
+		pos += size
+		if isWordRune(r) {
+			for pos < len(line) {
+				r, size := utf8.DecodeRuneInString(line[pos:])
+				if !isWordRune(r) {
+					break
+				}
+				pos += size
+			}
+		}
+	}
+
+	return tokens
+}
+
+// match tries the rules at pos and returns the token of the first one that
+// matches, along with the position to continue at.
+func (rh *RegexpHighlighter) match(line string, pos int) (start, end int, kind Kind, next int) {
+	rest := line[pos:]
+
+	for _, rule := range rh.rules {
+		m := rule.re.FindStringSubmatchIndex(rest)
+		if m == nil || m[1] == 0 {
+			continue
+		}
+
+		if len(m) >= 4 && m[2] >= 0 {
+			if m[3] == 0 {
+				continue
+			}
+
+			return pos + m[2], pos + m[3], rule.kind, pos + m[3]
+		}
+
+		return pos, pos + m[1], rule.kind, pos + m[1]
+	}
+
+	return 0, 0, Plain, pos
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func words(words ...string) string {
+	return `\b(?:` + strings.Join(words, "|") + `)\b`
+}
+
+var (
+	jsonHighlighter = mustRegexpHighlighter(
+		Rule{`("(?:[^"\\]|\\.)*")\s*:`, Key},
+		Rule{`"(?:[^"\\]|\\.)*"?`, String},
+		Rule{`-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?`, Number},
+		Rule{words("true", "false", "null"), Literal},
+		Rule{`[{}\[\],:]`, Punctuation},
+	)
+
+	goHighlighter = mustRegexpHighlighter(
+		Rule{`//.*`, Comment},
+		Rule{`/\*.*?(?:\*/|$)`, Comment},
+		Rule{"`[^`]*`?", String},
+		Rule{`"(?:[^"\\]|\\.)*"?`, String},
+		Rule{`'(?:[^'\\]|\\.)*'`, String},
+		Rule{words(
+			"break", "case", "chan", "const", "continue", "default", "defer",
+			"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+			"interface", "map", "package", "range", "return", "select",
+			"struct", "switch", "type", "var"), Keyword},
+		Rule{words(
+			"bool", "byte", "complex64", "complex128", "error", "float32",
+			"float64", "int", "int8", "int16", "int32", "int64", "rune",
+			"string", "uint", "uint8", "uint16", "uint32", "uint64",
+			"uintptr"), Type},
+		Rule{words("true", "false", "nil", "iota"), Literal},
+		Rule{`0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO][0-7_]+|(?:\d[\d_]*(?:\.[\d_]*)?|\.\d[\d_]*)(?:[eE][+-]?\d+)?i?`, Number},
+		Rule{`[{}()\[\],;.]`, Punctuation},
+	)
+
+	logHighlighter = mustRegexpHighlighter(
+		Rule{`\d{4}[-/]\d{2}[-/]\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?`, Timestamp},
+		Rule{`\d{2}:\d{2}:\d{2}(?:[.,]\d+)?`, Timestamp},
+		Rule{words("FATAL", "PANIC", "CRITICAL", "CRIT", "ERROR", "ERR"), Error},
+		Rule{words("WARNING", "WARN"), Warning},
+		Rule{words("INFO", "NOTICE"), Info},
+		Rule{words("DEBUG", "TRACE", "DBG"), Debug},
+		Rule{`"(?:[^"\\]|\\.)*"`, String},
+		Rule{`-?\d+(?:\.\d+)?`, Number},
+	)
+)
+
+// JSON returns a Highlighter for JSON.
+func JSON() Highlighter {
+	return jsonHighlighter
+}
+
+// Go returns a Highlighter for Go source code.
+func Go() Highlighter {
+	return goHighlighter
+}
+
+// Log returns a Highlighter for log output. It highlights timestamps and
+// log levels like ERROR, WARN, INFO and DEBUG.
+func Log() Highlighter {
+	return logHighlighter
+}
+
+// ForFile returns a Highlighter for the file name extension of filePath,
+// or nil if there is none.
+func ForFile(filePath string) Highlighter {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return JSON()
+
+	case ".go":
+		return Go()
+
+	case ".log":
+		return Log()
+	}
+
+	return nil
+}
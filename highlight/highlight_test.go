@@ -0,0 +1,163 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var kindNames = map[Kind]string{
+	Plain:       "Plain",
+	Keyword:     "Keyword",
+	Type:        "Type",
+	Literal:     "Literal",
+	String:      "String",
+	Number:      "Number",
+	Comment:     "Comment",
+	Key:         "Key",
+	Punctuation: "Punctuation",
+	Timestamp:   "Timestamp",
+	Error:       "Error",
+	Warning:     "Warning",
+	Info:        "Info",
+	Debug:       "Debug",
+}
+
+// describe returns the tokens as kind:text strings, which make failures
+// readable.
+func describe(line string, tokens []Token) []string {
+	var descs []string
+	for _, t := range tokens {
+		descs = append(descs, fmt.Sprintf("%s:%s", kindNames[t.Kind], line[t.Start:t.End]))
+	}
+
+	return descs
+}
+
+type highlightTest struct {
+	line string
+	want []string
+}
+
+func checkHighlighter(t *testing.T, name string, h Highlighter, tests []highlightTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		tokens := h.Highlight(test.line)
+
+		for i, tok := range tokens {
+			if tok.Start >= tok.End || i > 0 && tok.Start < tokens[i-1].End {
+				t.Errorf("%s %q: tokens %v are empty, unsorted or overlap", name, test.line, tokens)
+				break
+			}
+		}
+
+		if got := describe(test.line, tokens); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s %q: got %q, want %q", name, test.line, got, test.want)
+		}
+	}
+}
+
+func TestGo(t *testing.T) {
+	checkHighlighter(t, "Go", Go(), []highlightTest{
+		{"", nil},
+		{"func main() {", []string{"Keyword:func", "Punctuation:(", "Punctuation:)", "Punctuation:{"}},
+		// Keywords and types inside of identifiers are no tokens.
+		{"funcs := interfaces + myint + int_", nil},
+		{"x1 := 2", []string{"Number:2"}},
+		{"ñint int", []string{"Type:int"}},
+		{"var i int64 = 0x1F + 1.5e3i", []string{"Keyword:var", "Type:int64", "Number:0x1F", "Number:1.5e3i"}},
+		{"n := 1_000 + .5 + 0b1010", []string{"Number:1_000", "Number:.5", "Number:0b1010"}},
+		{"return nil, err", []string{"Keyword:return", "Literal:nil", "Punctuation:,"}},
+		{`s := "a\"b" // c "d"`, []string{`String:"a\"b"`, `Comment:// c "d"`}},
+		{`s := "unterminated`, []string{`String:"unterminated`}},
+		{`s := "ends with \"`, []string{`String:"ends with \"`}},
+		{"s := `raw", []string{"String:`raw"}},
+		{"r := '\\''", []string{`String:'\''`}},
+		{"a /* b */ c /* d", []string{"Comment:/* b */", "Comment:/* d"}},
+		{`x := "// no comment"`, []string{`String:"// no comment"`}},
+	})
+}
+
+func TestJSON(t *testing.T) {
+	checkHighlighter(t, "JSON", JSON(), []highlightTest{
+		{`{"name": "x", "n": -1.5e3, "ok": true, "v": null}`, []string{
+			"Punctuation:{",
+			`Key:"name"`, "Punctuation::", `String:"x"`, "Punctuation:,",
+			`Key:"n"`, "Punctuation::", "Number:-1.5e3", "Punctuation:,",
+			`Key:"ok"`, "Punctuation::", "Literal:true", "Punctuation:,",
+			`Key:"v"`, "Punctuation::", "Literal:null",
+			"Punctuation:}",
+		}},
+		// Strings are keys only if a colon follows them.
+		{`["a:b", "c"]`, []string{"Punctuation:[", `String:"a:b"`, "Punctuation:,", `String:"c"`, "Punctuation:]"}},
+		{`"key"  :1`, []string{`Key:"key"`, "Punctuation::", "Number:1"}},
+		{`"a\"b": 0`, []string{`Key:"a\"b"`, "Punctuation::", "Number:0"}},
+		{`"unterminated`, []string{`String:"unterminated`}},
+		{`"unterminated: 1`, []string{`String:"unterminated: 1`}},
+		{`truex nullable 01`, []string{"Number:0", "Number:1"}},
+	})
+}
+
+func TestLog(t *testing.T) {
+	checkHighlighter(t, "Log", Log(), []highlightTest{
+		{`2019-03-04 12:34:56.789 ERROR failed: "x" 42`, []string{
+			"Timestamp:2019-03-04 12:34:56.789", "Error:ERROR", `String:"x"`, "Number:42",
+		}},
+		{"2019-03-04T12:34:56Z WARN low disk", []string{"Timestamp:2019-03-04T12:34:56Z", "Warning:WARN"}},
+		{"2019-03-04T12:34:56+01:00 [INFO]", []string{"Timestamp:2019-03-04T12:34:56+01:00", "Info:INFO"}},
+		{"2019/03/04 trace", []string{"Timestamp:2019/03/04"}},
+		{"12:34:56,5 NOTICE started", []string{"Timestamp:12:34:56,5", "Info:NOTICE"}},
+		{"FATAL PANIC CRIT ERR", []string{"Error:FATAL", "Error:PANIC", "Error:CRIT", "Error:ERR"}},
+		{"WARNING DEBUG TRACE DBG", []string{"Warning:WARNING", "Debug:DEBUG", "Debug:TRACE", "Debug:DBG"}},
+		// Levels inside of words are no tokens.
+		{"ERRORS in MyINFO and INFO_X", nil},
+		{"took 12.5ms, id123, -5", []string{"Number:12.5", "Number:-5"}},
+		{`msg="unterminated`, nil},
+	})
+}
+
+func TestRegexpHighlighterGroups(t *testing.T) {
+	h, err := NewRegexpHighlighter(
+		// The group is the token, the rest is left to the other rules.
+		Rule{`(\w+)=`, Key},
+		// An empty group is skipped.
+		Rule{`()x`, Keyword},
+		Rule{`=`, Punctuation},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkHighlighter(t, "groups", h, []highlightTest{
+		{"a=b x", []string{"Key:a", "Punctuation:="}},
+	})
+
+	if _, err := NewRegexpHighlighter(Rule{`(`, Plain}); err == nil {
+		t.Error("got no error for an invalid pattern")
+	}
+}
+
+func TestForFile(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     Highlighter
+	}{
+		{"a.json", JSON()},
+		{`C:\dir\A.JSON`, JSON()},
+		{"main.go", Go()},
+		{"app.log", Log()},
+		{"readme.txt", nil},
+		{"go", nil},
+	}
+
+	for _, test := range tests {
+		if got := ForFile(test.filePath); got != test.want {
+			t.Errorf("ForFile(%q): got %v, want %v", test.filePath, got, test.want)
+		}
+	}
+}
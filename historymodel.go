@@ -0,0 +1,150 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"encoding/json"
+)
+
+// HistoryModel is a ListModel of recently used texts, most recent first.
+// It suits a ComboBox or a Completer.
+type HistoryModel struct {
+	ListModelBase
+	items    []string
+	maxItems int
+}
+
+// NewHistoryModel returns a new *HistoryModel that keeps at most maxItems
+// texts, or any number of texts if maxItems is 0.
+func NewHistoryModel(maxItems int) *HistoryModel {
+	return &HistoryModel{maxItems: maxItems}
+}
+
+func (hm *HistoryModel) ItemCount() int {
+	return len(hm.items)
+}
+
+func (hm *HistoryModel) Value(index int) interface{} {
+	return hm.items[index]
+}
+
+// Items returns the texts, most recent first.
+func (hm *HistoryModel) Items() []string {
+	return hm.items
+}
+
+// SetItems replaces the texts with items, which are most recent first.
+// Empty texts and repetitions are dropped.
+func (hm *HistoryModel) SetItems(items []string) {
+	hm.items = nil
+
+	for i := len(items) - 1; i >= 0; i-- {
+		hm.add(items[i])
+	}
+	hm.trim()
+
+	hm.PublishItemsReset()
+}
+
+// MaxItems returns the maximum number of texts, or 0 if there is none.
+func (hm *HistoryModel) MaxItems() int {
+	return hm.maxItems
+}
+
+// SetMaxItems sets the maximum number of texts, dropping the oldest ones if
+// there are more.
+func (hm *HistoryModel) SetMaxItems(maxItems int) {
+	hm.maxItems = maxItems
+
+	if hm.trim() {
+		hm.PublishItemsReset()
+	}
+}
+
+// Add makes text the most recent one. Empty texts are ignored.
+func (hm *HistoryModel) Add(text string) {
+	if text == "" || len(hm.items) > 0 && hm.items[0] == text {
+		return
+	}
+
+	hm.add(text)
+	hm.trim()
+
+	hm.PublishItemsReset()
+}
+
+// Clear removes all texts.
+func (hm *HistoryModel) Clear() {
+	hm.items = nil
+
+	hm.PublishItemsReset()
+}
+
+// SaveState stores the texts in the settings of the application under key.
+func (hm *HistoryModel) SaveState(key string) error {
+	settings := App().Settings()
+	if settings == nil {
+		return newError("App().Settings() must not be nil")
+	}
+
+	state, err := json.Marshal(hm.items)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return settings.Put(key, string(state))
+}
+
+// RestoreState replaces the texts with those stored in the settings of the
+// application under key, if any.
+func (hm *HistoryModel) RestoreState(key string) error {
+	settings := App().Settings()
+	if settings == nil {
+		return newError("App().Settings() must not be nil")
+	}
+
+	state, ok := settings.Get(key)
+	if !ok {
+		return nil
+	}
+
+	var items []string
+	if err := json.Unmarshal([]byte(state), &items); err != nil {
+		return wrapError(err)
+	}
+
+	hm.SetItems(items)
+
+	return nil
+}
+
+func (hm *HistoryModel) add(text string) {
+	if text == "" {
+		return
+	}
+
+	for i, item := range hm.items {
+		if item == text {
+			hm.items = append(hm.items[:i], hm.items[i+1:]...)
+			break
+		}
+	}
+
+	hm.items = append([]string{text}, hm.items...)
+}
+
+// trim drops the oldest texts beyond the maximum and returns whether there
+// were any.
+func (hm *HistoryModel) trim() bool {
+	if hm.maxItems <= 0 || len(hm.items) <= hm.maxItems {
+		return false
+	}
+
+	hm.items = hm.items[:hm.maxItems]
+
+	return true
+}
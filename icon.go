@@ -8,21 +8,29 @@ package walk
 
 import (
 	"image"
+	"math"
+	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
 
+	"github.com/lxn/walk/svg"
 	"github.com/lxn/win"
 )
 
 // Icon is a bitmap that supports transparency and combining multiple
 // variants of an image in different resolutions.
+//
+// Icons loaded from .svg files are rendered for each resolution, so they
+// are sharp at any DPI.
 type Icon struct {
 	filePath  string
 	index     int
 	res       *uint16
+	svg       *svg.Document
 	dpi2hIcon map[int]win.HICON
 	size96dpi Size
 	isStock   bool
@@ -35,7 +43,15 @@ type ExtractableIcon interface {
 	Size_() int
 }
 
+// IconFrom returns an *Icon of the image of src, as ImageFrom returns it, rendered
+// for dpi. It stays valid until that image is disposed.
 func IconFrom(src interface{}, dpi int) (*Icon, error) {
+	return iconFrom(src, dpi, true)
+}
+
+// iconFrom is like IconFrom. Unless retain is true, the *Icon must be used
+// right away, see IconCache.icon.
+func iconFrom(src interface{}, dpi int, retain bool) (*Icon, error) {
 	if src == nil {
 		return nil, nil
 	}
@@ -45,7 +61,7 @@ func IconFrom(src interface{}, dpi int) (*Icon, error) {
 		return nil, err
 	}
 
-	return iconCache.Icon(img, dpi)
+	return iconCache.icon(img, dpi, retain)
 }
 
 func IconApplication() *Icon {
@@ -81,12 +97,18 @@ func stockIcon(id uintptr) *Icon {
 }
 
 // NewIconFromFile returns a new Icon, using the specified icon image file and default size.
+//
+// The default size of .svg files is the size of the document.
 func NewIconFromFile(filePath string) (*Icon, error) {
 	return NewIconFromFileWithSize(filePath, Size{})
 }
 
 // NewIconFromFileWithSize returns a new Icon, using the specified icon image file and size.
 func NewIconFromFileWithSize(filePath string, size Size) (*Icon, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".svg") {
+		return newIconFromSVGFile(filePath, size)
+	}
+
 	if size.Width == 0 || size.Height == 0 {
 		size = defaultIconSize()
 	}
@@ -94,6 +116,31 @@ func NewIconFromFileWithSize(filePath string, size Size) (*Icon, error) {
 	return checkNewIcon(&Icon{filePath: filePath, size96dpi: size})
 }
 
+func newIconFromSVGFile(filePath string, size Size) (*Icon, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer f.Close()
+
+	doc, err := svg.Parse(f)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return NewIconFromSVG(doc, size)
+}
+
+// NewIconFromSVG returns a new Icon of size in 1/96" units, that renders doc
+// at any DPI. If size is empty, the size of doc is used.
+func NewIconFromSVG(doc *svg.Document, size Size) (*Icon, error) {
+	if size.Width == 0 || size.Height == 0 {
+		size = Size{int(math.Ceil(doc.Width)), int(math.Ceil(doc.Height))}
+	}
+
+	return checkNewIcon(&Icon{svg: doc, size96dpi: size})
+}
+
 // NewIconFromResource returns a new Icon of default size, using the specified icon resource.
 func NewIconFromResource(name string) (*Icon, error) {
 	return NewIconFromResourceWithSize(name, Size{})
@@ -244,6 +291,19 @@ func (i *Icon) handleForDPIWithError(dpi int) (win.HICON, error) {
 		return handle, nil
 	}
 
+	if i.svg != nil {
+		size := SizeFrom96DPI(i.size96dpi, dpi)
+
+		hIcon, err := createAlphaCursorOrIconFromImage(i.svg.Render(maxi(1, size.Width), maxi(1, size.Height)), image.Pt(0, 0), true)
+		if err != nil {
+			return 0, err
+		}
+
+		i.dpi2hIcon[dpi] = hIcon
+
+		return hIcon, nil
+	}
+
 	var hInst win.HINSTANCE
 	var name *uint16
 	if i.filePath != "" {
@@ -302,6 +362,8 @@ func (i *Icon) handleForDPIWithError(dpi int) (win.HICON, error) {
 
 // Dispose releases the operating system resources associated with the Icon.
 func (i *Icon) Dispose() {
+	iconCache.Remove(i)
+
 	if i.isStock || len(i.dpi2hIcon) == 0 {
 		return
 	}
@@ -6,6 +6,13 @@
 
 package walk
 
+import (
+	"container/list"
+)
+
+// DefaultIconCacheMaxBytes is the default for IconCache.MaxBytes.
+const DefaultIconCacheMaxBytes = 32 << 20
+
 var iconCache *IconCache
 
 func init() {
@@ -14,31 +21,67 @@ func init() {
 	})
 }
 
+// IconCache caches the bitmaps and icons that images are rendered to for
+// specific DPIs.
+//
+// Bitmaps and icons returned by Bitmap and Icon stay valid until they are
+// released with ReleaseBitmap and ReleaseIcon, their image is disposed or the
+// cache is cleared. Once released, they are disposed when the cache grows
+// beyond MaxBytes, least recently used first.
 type IconCache struct {
-	imageAndDPI2Bitmap map[imageAndDPI]*Bitmap
-	imageAndDPI2Icon   map[imageAndDPI]*Icon
+	entries  map[iconCacheKey]*iconCacheEntry
+	byImage  map[Image][]*iconCacheEntry
+	lru      list.List // of *iconCacheEntry, most recently used at the front
+	bytes    int
+	maxBytes int
 }
 
-type imageAndDPI struct {
+type iconCacheKey struct {
 	image Image
 	dpi   int
+	icon  bool
 }
 
+type iconCacheEntry struct {
+	key    iconCacheKey
+	bitmap *Bitmap
+	icon   *Icon
+	bytes  int
+	owned  bool // Whether the cache must dispose bitmap or icon.
+	refs   int  // The unreleased results of Bitmap and Icon.
+	elem   *list.Element
+}
+
+// NewIconCache returns a new *IconCache, limited to DefaultIconCacheMaxBytes.
 func NewIconCache() *IconCache {
 	return &IconCache{
-		imageAndDPI2Bitmap: make(map[imageAndDPI]*Bitmap),
-		imageAndDPI2Icon:   make(map[imageAndDPI]*Icon),
+		entries:  make(map[iconCacheKey]*iconCacheEntry),
+		byImage:  make(map[Image][]*iconCacheEntry),
+		maxBytes: DefaultIconCacheMaxBytes,
 	}
 }
 
+// Bytes returns the approximate memory used by the cached bitmaps and icons.
+func (ic *IconCache) Bytes() int {
+	return ic.bytes
+}
+
+// MaxBytes returns the memory that the cache tries not to exceed.
+func (ic *IconCache) MaxBytes() int {
+	return ic.maxBytes
+}
+
+// SetMaxBytes sets the memory that the cache tries not to exceed. Bitmaps
+// and icons that must stay valid may exceed it.
+func (ic *IconCache) SetMaxBytes(maxBytes int) {
+	ic.maxBytes = maxBytes
+
+	ic.evict(nil)
+}
+
 func (ic *IconCache) Clear() {
-	for key, bmp := range ic.imageAndDPI2Bitmap {
-		bmp.Dispose()
-		delete(ic.imageAndDPI2Bitmap, key)
-	}
-	for key, ico := range ic.imageAndDPI2Icon {
-		ico.Dispose()
-		delete(ic.imageAndDPI2Icon, key)
+	for e := ic.lru.Front(); e != nil; e = ic.lru.Front() {
+		ic.remove(e.Value.(*iconCacheEntry))
 	}
 }
 
@@ -46,11 +89,76 @@ func (ic *IconCache) Dispose() {
 	ic.Clear()
 }
 
+// Remove disposes the bitmaps and icons that were made from image.
+//
+// The images of walk call it when they are disposed. Other Image
+// implementations should do the same.
+func (ic *IconCache) Remove(image Image) {
+	if ic == nil {
+		return
+	}
+
+	entries := ic.byImage[image]
+	if len(entries) == 0 {
+		return
+	}
+
+	for _, entry := range append([]*iconCacheEntry(nil), entries...) {
+		ic.remove(entry)
+	}
+}
+
+// Bitmap returns a *Bitmap of image rendered for dpi. It stays valid until
+// it is released with ReleaseBitmap, image is disposed or the cache is
+// cleared.
 func (ic *IconCache) Bitmap(image Image, dpi int) (*Bitmap, error) {
-	key := imageAndDPI{image, dpi}
+	return ic.bitmap(image, dpi, true)
+}
 
-	if bmp, ok := ic.imageAndDPI2Bitmap[key]; ok {
-		return bmp, nil
+// Icon returns an *Icon of image rendered for dpi. It stays valid until it is
+// released with ReleaseIcon, image is disposed or the cache is cleared.
+func (ic *IconCache) Icon(image Image, dpi int) (*Icon, error) {
+	return ic.icon(image, dpi, true)
+}
+
+// ReleaseBitmap releases a *Bitmap that Bitmap returned for image and dpi.
+// Once all are released, the cache may dispose it.
+func (ic *IconCache) ReleaseBitmap(image Image, dpi int) {
+	ic.release(iconCacheKey{image, dpi, false})
+}
+
+// ReleaseIcon releases an *Icon that Icon returned for image and dpi. Once
+// all are released, the cache may dispose it.
+func (ic *IconCache) ReleaseIcon(image Image, dpi int) {
+	ic.release(iconCacheKey{image, dpi, true})
+}
+
+// release is like ReleaseBitmap and ReleaseIcon. The zero key, that windows
+// hold while they use no cached bitmap or icon, is ignored, like keys of
+// entries that were removed already.
+func (ic *IconCache) release(key iconCacheKey) {
+	if ic == nil || key.image == nil {
+		return
+	}
+
+	entry, ok := ic.entries[key]
+	if !ok || entry.refs == 0 {
+		return
+	}
+
+	entry.refs--
+
+	ic.evict(nil)
+}
+
+// bitmap is like Bitmap. Unless retain is true, the *Bitmap needn't be
+// released, but may be disposed by any later call, so it must be used right
+// away.
+func (ic *IconCache) bitmap(image Image, dpi int, retain bool) (*Bitmap, error) {
+	key := iconCacheKey{image, dpi, false}
+
+	if entry := ic.lookup(key, retain); entry != nil {
+		return entry.bitmap, nil
 	}
 
 	size := SizeFrom96DPI(image.Size(), dpi)
@@ -60,21 +168,28 @@ func (ic *IconCache) Bitmap(image Image, dpi int) (*Bitmap, error) {
 		return nil, err
 	}
 
-	ic.imageAndDPI2Bitmap[key] = bmp
+	ic.add(&iconCacheEntry{
+		key:    key,
+		bitmap: bmp,
+		bytes:  bmp.size.Width * bmp.size.Height * 4,
+		owned:  true,
+	}, retain)
 
 	return bmp, nil
 }
 
-func (ic *IconCache) Icon(image Image, dpi int) (*Icon, error) {
-	key := imageAndDPI{image, dpi}
+// icon is like Icon. Unless retain is true, the *Icon needn't be released,
+// but may be disposed by any later call, so it must be used right away.
+func (ic *IconCache) icon(image Image, dpi int, retain bool) (*Icon, error) {
+	key := iconCacheKey{image, dpi, true}
 
-	if ico, ok := ic.imageAndDPI2Icon[key]; ok {
-		return ico, nil
+	if entry := ic.lookup(key, retain); entry != nil {
+		return entry.icon, nil
 	}
 
 	if ico, ok := image.(*Icon); ok {
 		if ico.handleForDPI(dpi) != 0 {
-			ic.imageAndDPI2Icon[key] = ico
+			ic.add(&iconCacheEntry{key: key, icon: ico}, retain)
 			return ico, nil
 		}
 	}
@@ -84,7 +199,90 @@ func (ic *IconCache) Icon(image Image, dpi int) (*Icon, error) {
 		return nil, err
 	}
 
-	ic.imageAndDPI2Icon[key] = ico
+	size := SizeFrom96DPI(ico.Size(), dpi)
+
+	ic.add(&iconCacheEntry{
+		key:   key,
+		icon:  ico,
+		bytes: size.Width * size.Height * 4,
+		owned: true,
+	}, retain)
 
 	return ico, nil
 }
+
+func (ic *IconCache) lookup(key iconCacheKey, retain bool) *iconCacheEntry {
+	entry, ok := ic.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if retain {
+		entry.refs++
+	}
+
+	ic.lru.MoveToFront(entry.elem)
+
+	return entry
+}
+
+func (ic *IconCache) add(entry *iconCacheEntry, retain bool) {
+	if retain {
+		entry.refs = 1
+	}
+
+	entry.elem = ic.lru.PushFront(entry)
+
+	ic.entries[entry.key] = entry
+	ic.byImage[entry.key.image] = append(ic.byImage[entry.key.image], entry)
+	ic.bytes += entry.bytes
+
+	ic.evict(entry)
+}
+
+// evict removes least recently used entries that aren't referenced, until the
+// cache fits into maxBytes. It keeps entry, which is about to be returned.
+func (ic *IconCache) evict(entry *iconCacheEntry) {
+	for e := ic.lru.Back(); e != nil && ic.bytes > ic.maxBytes; {
+		victim := e.Value.(*iconCacheEntry)
+		e = e.Prev()
+
+		if victim != entry && victim.refs == 0 {
+			ic.remove(victim)
+		}
+	}
+}
+
+func (ic *IconCache) remove(entry *iconCacheEntry) {
+	if ic.entries[entry.key] != entry {
+		// Already removed, while disposing another entry.
+		return
+	}
+
+	delete(ic.entries, entry.key)
+	ic.lru.Remove(entry.elem)
+	ic.bytes -= entry.bytes
+
+	image := entry.key.image
+	entries := ic.byImage[image]
+	for i, e := range entries {
+		if e == entry {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(ic.byImage, image)
+	} else {
+		ic.byImage[image] = entries
+	}
+
+	if entry.owned {
+		if entry.bitmap != nil {
+			entry.bitmap.Dispose()
+		}
+		if entry.icon != nil {
+			entry.icon.Dispose()
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"testing"
+
+	"github.com/lxn/win"
+)
+
+// testImage is an Image that draws nothing, so the cache can render it
+// without any resources.
+type testImage struct {
+	size Size
+}
+
+func (ti *testImage) draw(hdc win.HDC, location Point) error {
+	return nil
+}
+
+func (ti *testImage) drawStretched(hdc win.HDC, bounds Rectangle) error {
+	return nil
+}
+
+func (ti *testImage) Dispose() {
+}
+
+func (ti *testImage) Size() Size {
+	return ti.size
+}
+
+// testImageBytes is the size of the cache entry of a 16x16 testImage at 96dpi.
+const testImageBytes = 16 * 16 * 4
+
+func TestIconCacheRefCounting(t *testing.T) {
+	ic := NewIconCache()
+	defer ic.Dispose()
+	ic.SetMaxBytes(0)
+
+	img := &testImage{Size{16, 16}}
+
+	bmp1, err := ic.Bitmap(img, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmp2, err := ic.Bitmap(img, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bmp1 != bmp2 {
+		t.Fatal("Bitmap returned distinct bitmaps for the same image and DPI")
+	}
+	if got, want := bmp1.Size(), (Size{16, 16}); got != want {
+		t.Errorf("Size: got %v, want %v", got, want)
+	}
+
+	// Over MaxBytes, but still referenced twice.
+	if got := ic.Bytes(); got != testImageBytes {
+		t.Errorf("Bytes: got %d, want %d", got, testImageBytes)
+	}
+
+	ic.ReleaseBitmap(img, 96)
+	if bmp1.hBmp == 0 {
+		t.Fatal("bitmap disposed while still referenced")
+	}
+
+	ic.ReleaseBitmap(img, 96)
+	if bmp1.hBmp != 0 {
+		t.Error("bitmap not disposed after the last release")
+	}
+	if got := ic.Bytes(); got != 0 {
+		t.Errorf("Bytes after the last release: got %d, want 0", got)
+	}
+
+	// Releasing too often or with the zero key must be harmless.
+	ic.ReleaseBitmap(img, 96)
+	ic.release(iconCacheKey{})
+}
+
+func TestIconCacheEviction(t *testing.T) {
+	ic := NewIconCache()
+	defer ic.Dispose()
+	ic.SetMaxBytes(2 * testImageBytes)
+
+	a, b, c := &testImage{Size{16, 16}}, &testImage{Size{16, 16}}, &testImage{Size{16, 16}}
+
+	bmpA, err := ic.bitmap(a, 96, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmpB, err := ic.bitmap(b, 96, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Using a again makes b the least recently used entry.
+	if bmp, _ := ic.bitmap(a, 96, false); bmp != bmpA {
+		t.Fatal("bitmap of a not returned from the cache")
+	}
+
+	bmpC, err := ic.bitmap(c, 96, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bmpB.hBmp != 0 {
+		t.Error("least recently used bitmap of b not evicted")
+	}
+	if bmpA.hBmp == 0 || bmpC.hBmp == 0 {
+		t.Error("recently used bitmaps of a or c evicted")
+	}
+	if got, want := ic.Bytes(), 2*testImageBytes; got != want {
+		t.Errorf("Bytes: got %d, want %d", got, want)
+	}
+
+	// Retained entries are never evicted, even if the cache outgrows
+	// MaxBytes.
+	if _, err := ic.Bitmap(a, 96); err != nil {
+		t.Fatal(err)
+	}
+	ic.SetMaxBytes(0)
+
+	if bmpA.hBmp == 0 {
+		t.Error("retained bitmap of a evicted")
+	}
+	if bmpC.hBmp != 0 {
+		t.Error("bitmap of c not evicted after SetMaxBytes(0)")
+	}
+
+	ic.ReleaseBitmap(a, 96)
+	if bmpA.hBmp != 0 {
+		t.Error("bitmap of a not evicted after its release")
+	}
+}
+
+func TestIconCacheRemove(t *testing.T) {
+	ic := NewIconCache()
+	defer ic.Dispose()
+
+	img := &testImage{Size{16, 16}}
+
+	bmp96, err := ic.Bitmap(img, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmp192, err := ic.bitmap(img, 192, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := bmp192.Size(), (Size{16, 16}); got != want {
+		t.Errorf("Size at 192dpi: got %v, want %v", got, want)
+	}
+
+	// Remove disposes all entries of the image, even retained ones.
+	ic.Remove(img)
+
+	if bmp96.hBmp != 0 || bmp192.hBmp != 0 {
+		t.Error("bitmaps not disposed by Remove")
+	}
+	if got := ic.Bytes(); got != 0 {
+		t.Errorf("Bytes after Remove: got %d, want 0", got)
+	}
+
+	// Releasing a removed entry is ignored, and a new one is made on demand.
+	ic.ReleaseBitmap(img, 96)
+
+	bmp, err := ic.Bitmap(img, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bmp == bmp96 || bmp.hBmp == 0 {
+		t.Error("no new bitmap after Remove")
+	}
+}
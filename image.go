@@ -57,16 +57,19 @@ func NewImageFromFile(filePath string) (Image, error) {
 	return NewImageFromFileForDPI(filePath, 96)
 }
 
-// NewImageFromFileForDPI loads image from file at given DPI. Supported types are .ico, .emf,
-// .bmp, .png...
+// NewImageFromFileForDPI loads image from file at given DPI. Supported types are .ico, .svg,
+// .emf, .bmp, .png...
+//
+// Variants of bitmap files for higher DPIs are loaded, too, if they are found next to them, like
+// open@2x.png and open@3x.png next to open.png. The *Bitmap draws them when they fit better.
 func NewImageFromFileForDPI(filePath string, dpi int) (Image, error) {
-	if strings.HasSuffix(filePath, ".ico") {
+	if strings.HasSuffix(filePath, ".ico") || strings.HasSuffix(filePath, ".svg") {
 		return NewIconFromFile(filePath)
 	} else if strings.HasSuffix(filePath, ".emf") {
 		return NewMetafileFromFile(filePath)
 	}
 
-	return NewBitmapFromFileForDPI(filePath, dpi)
+	return newBitmapWithVariantsFromFile(filePath, dpi)
 }
 
 type PaintFuncImage struct {
@@ -129,6 +132,8 @@ func (pfi *PaintFuncImage) drawStretchedOnCanvasPixels(canvas *Canvas, bounds Re
 }
 
 func (pfi *PaintFuncImage) Dispose() {
+	iconCache.Remove(pfi)
+
 	if pfi.dispose != nil {
 		pfi.dispose()
 		pfi.dispose = nil
@@ -7,6 +7,7 @@
 package walk
 
 import (
+	"reflect"
 	"syscall"
 	"unsafe"
 
@@ -132,9 +133,11 @@ func (il *ImageList) AddIcon(icon *Icon) (int32, error) {
 }
 
 func (il *ImageList) AddImage(image interface{}) (int32, error) {
+	// The image list keeps copies, so the cache needn't retain the bitmap or
+	// icon.
 	switch image.(type) {
 	case ExtractableIcon, *Icon:
-		icon, err := IconFrom(image, il.dpi)
+		icon, err := iconFrom(image, il.dpi, false)
 		if err != nil {
 			return 0, err
 		}
@@ -142,7 +145,7 @@ func (il *ImageList) AddImage(image interface{}) (int32, error) {
 		return il.AddIcon(icon)
 
 	default:
-		bmp, err := BitmapFrom(image, il.dpi)
+		bmp, err := bitmapFrom(image, il.dpi, false)
 		if err != nil {
 			return 0, err
 		}
@@ -241,6 +244,12 @@ func imageIndexAddIfNotExists(image interface{}, hIml win.HIMAGELIST, imageUintp
 
 		case *Icon:
 			ptr = uintptr(unsafe.Pointer(img))
+
+		case Image:
+			// Like metafiles, that are added as bitmaps.
+			if v := reflect.ValueOf(img); v.Kind() == reflect.Ptr {
+				ptr = v.Pointer()
+			}
 		}
 
 		if ptr == 0 {
@@ -257,6 +266,12 @@ func imageIndexAddIfNotExists(image interface{}, hIml win.HIMAGELIST, imageUintp
 
 		case *Icon:
 			imageIndex = win.ImageList_ReplaceIcon(hIml, -1, img.handleForDPI(dpi))
+
+		case Image:
+			// The image list copies the bitmap, so it needn't stay valid.
+			if bmp, err := iconCache.bitmap(img, dpi, false); err == nil {
+				imageIndex = win.ImageList_AddMasked(hIml, bmp.hBmp, 0)
+			}
 		}
 
 		if imageIndex > -1 {
@@ -0,0 +1,116 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxn/walk/svg"
+)
+
+// decodedImage is an image file, decoded as far as possible without the UI
+// thread.
+type decodedImage struct {
+	filePath string
+	svg      *svg.Document
+	images   []image.Image // Variants for increasing DPIs.
+	scales   []int
+}
+
+// decodeImageFile decodes the image file at filePath. It may be called from
+// any goroutine.
+//
+// SVG files, and PNG, JPEG and GIF files along with their variants for higher
+// DPIs are decoded. Other formats are left to the UI thread.
+func decodeImageFile(filePath string) (*decodedImage, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".svg":
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		doc, err := svg.Parse(f)
+		if err != nil {
+			return nil, err
+		}
+
+		return &decodedImage{filePath: filePath, svg: doc}, nil
+
+	case ".png", ".jpg", ".jpeg", ".gif":
+		im, err := decodeImageFileWithGo(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		di := &decodedImage{filePath: filePath, images: []image.Image{im}, scales: []int{1}}
+
+		for _, scale := range imageVariantScales {
+			variantPath := imageVariantFilePath(filePath, scale)
+			if _, err := os.Stat(variantPath); err != nil {
+				continue
+			}
+
+			im, err := decodeImageFileWithGo(variantPath)
+			if err != nil {
+				return nil, err
+			}
+
+			di.images = append(di.images, im)
+			di.scales = append(di.scales, scale)
+		}
+
+		return di, nil
+	}
+
+	return &decodedImage{filePath: filePath}, nil
+}
+
+func decodeImageFileWithGo(filePath string) (image.Image, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	im, _, err := image.Decode(f)
+
+	return im, err
+}
+
+// image returns the Image for dpi. It must be called from the UI thread.
+func (di *decodedImage) image(dpi int) (Image, error) {
+	if di.svg != nil {
+		return NewIconFromSVG(di.svg, Size{})
+	}
+
+	if len(di.images) == 0 {
+		return NewImageFromFileForDPI(di.filePath, dpi)
+	}
+
+	bitmaps := make([]*Bitmap, 0, len(di.images))
+	for i, im := range di.images {
+		bmp, err := NewBitmapFromImageForDPI(im, dpi*di.scales[i])
+		if err != nil {
+			for _, bmp := range bitmaps {
+				bmp.Dispose()
+			}
+			return nil, err
+		}
+
+		bitmaps = append(bitmaps, bmp)
+	}
+
+	return newBitmapWithVariants(bitmaps), nil
+}
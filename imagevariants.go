@@ -0,0 +1,103 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// imageVariantScales are the scale factors of the variants of image files
+// for higher DPIs, that are found next to them, like open@2x.png next to
+// open.png.
+var imageVariantScales = []int{2, 3}
+
+// imageVariantFilePath returns the path of the variant of filePath for scale.
+func imageVariantFilePath(filePath string, scale int) string {
+	ext := filepath.Ext(filePath)
+
+	return strings.TrimSuffix(filePath, ext) + "@" + strconv.Itoa(scale) + "x" + ext
+}
+
+// imageVariantFilePaths returns the paths of the existing variants of
+// filePath by scale.
+func imageVariantFilePaths(filePath string) map[int]string {
+	var scale2FilePath map[int]string
+
+	for _, scale := range imageVariantScales {
+		variantPath := imageVariantFilePath(filePath, scale)
+
+		if fi, err := os.Stat(variantPath); err == nil && !fi.IsDir() {
+			if scale2FilePath == nil {
+				scale2FilePath = make(map[int]string)
+			}
+			scale2FilePath[scale] = variantPath
+		}
+	}
+
+	return scale2FilePath
+}
+
+// newBitmapWithVariantsFromFile loads the bitmap file at filePath, assuming
+// dpi, together with its variants for higher DPIs, if there are any.
+func newBitmapWithVariantsFromFile(filePath string, dpi int) (*Bitmap, error) {
+	base, err := NewBitmapFromFileForDPI(filePath, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmaps := []*Bitmap{base}
+
+	for scale, variantPath := range imageVariantFilePaths(filePath) {
+		bmp, err := NewBitmapFromFileForDPI(variantPath, dpi*scale)
+		if err != nil {
+			for _, bmp := range bitmaps {
+				bmp.Dispose()
+			}
+			return nil, err
+		}
+
+		bitmaps = append(bitmaps, bmp)
+	}
+
+	return newBitmapWithVariants(bitmaps), nil
+}
+
+// newBitmapWithVariants returns the smallest of bitmaps, which should all
+// have the same size in 1/96" units, with the others as its variants.
+func newBitmapWithVariants(bitmaps []*Bitmap) *Bitmap {
+	for i := 1; i < len(bitmaps); i++ {
+		for j := i; j > 0 && bitmaps[j].size.Width < bitmaps[j-1].size.Width; j-- {
+			bitmaps[j], bitmaps[j-1] = bitmaps[j-1], bitmaps[j]
+		}
+	}
+
+	base := bitmaps[0]
+	if len(bitmaps) > 1 {
+		base.variants = bitmaps[1:]
+	}
+
+	return base
+}
+
+// variantForSize returns the smallest of bmp and its variants that is at
+// least as large as size in native pixels, or the largest one.
+func (bmp *Bitmap) variantForSize(size Size) *Bitmap {
+	if bmp.size.Width >= size.Width && bmp.size.Height >= size.Height || len(bmp.variants) == 0 {
+		return bmp
+	}
+
+	for _, variant := range bmp.variants {
+		if variant.size.Width >= size.Width && variant.size.Height >= size.Height {
+			return variant
+		}
+	}
+
+	return bmp.variants[len(bmp.variants)-1]
+}
@@ -9,9 +9,18 @@ package walk
 import (
 	"math"
 
+	"github.com/lxn/walk/viewport"
 	"github.com/lxn/win"
 )
 
+const (
+	// The zoom changes by this factor per notch of the mouse wheel.
+	imageViewWheelZoomFactor = 1.25
+
+	// The pixel grid is drawn when pixels are shown at least this large.
+	imageViewPixelGridMinScale = 8
+)
+
 type ImageViewMode int
 
 const (
@@ -21,19 +30,41 @@ const (
 	ImageViewModeShrink
 	ImageViewModeZoom
 	ImageViewModeStretch
+	ImageViewModeViewport
+)
+
+type imageViewFit int
+
+const (
+	imageViewFitNone imageViewFit = iota
+	imageViewFitWhole
+	imageViewFitFill
 )
 
 type ImageView struct {
 	*CustomWidget
-	image                  Image
-	imageChangedPublisher  EventPublisher
-	margin96dpi            int
-	marginChangedPublisher EventPublisher
-	mode                   ImageViewMode
+	image                       Image
+	ownsImage                   bool
+	imageChangedPublisher       EventPublisher
+	placeholder                 Image
+	placeholderChangedPublisher EventPublisher
+	loading                     bool
+	loadGeneration              int
+	loadingChangedPublisher     EventPublisher
+	imageLoadFailedPublisher    ErrorEventPublisher
+	margin96dpi                 int
+	marginChangedPublisher      EventPublisher
+	mode                        ImageViewMode
+	viewport                    viewport.Viewport // in native pixels
+	fit                         imageViewFit
+	zoomChangedPublisher        EventPublisher
+	pixelGridVisible            bool
+	panning                     bool
+	panOrigin                   Point // in native pixels
 }
 
 func NewImageView(parent Container) (*ImageView, error) {
-	iv := new(ImageView)
+	iv := &ImageView{viewport: viewport.New()}
 
 	cw, err := NewCustomWidgetPixels(parent, 0, func(canvas *Canvas, updateBounds Rectangle) error {
 		return iv.drawImage(canvas, updateBounds)
@@ -53,6 +84,8 @@ func NewImageView(parent Container) (*ImageView, error) {
 
 	iv.SetBackground(NullBrush())
 
+	iv.SizeChanged().Attach(iv.updateViewport)
+
 	iv.MustRegisterProperty("Image", NewProperty(
 		func() interface{} {
 			return iv.Image()
@@ -67,6 +100,20 @@ func NewImageView(parent Container) (*ImageView, error) {
 		},
 		iv.imageChangedPublisher.Event()))
 
+	iv.MustRegisterProperty("Placeholder", NewProperty(
+		func() interface{} {
+			return iv.Placeholder()
+		},
+		func(v interface{}) error {
+			img, err := ImageFrom(v)
+			if err != nil {
+				return err
+			}
+
+			return iv.SetPlaceholder(img)
+		},
+		iv.placeholderChangedPublisher.Event()))
+
 	iv.MustRegisterProperty("Margin", NewProperty(
 		func() interface{} {
 			return iv.Margin()
@@ -76,6 +123,22 @@ func NewImageView(parent Container) (*ImageView, error) {
 		},
 		iv.MarginChanged()))
 
+	iv.MustRegisterProperty("Zoom", NewProperty(
+		func() interface{} {
+			return iv.Zoom()
+		},
+		func(v interface{}) error {
+			zoom := assertFloat64Or(v, 1)
+			if n, ok := v.(int); ok {
+				zoom = float64(n)
+			}
+
+			iv.SetZoom(zoom)
+
+			return nil
+		},
+		iv.zoomChangedPublisher.Event()))
+
 	return iv, nil
 }
 
@@ -90,6 +153,8 @@ func (iv *ImageView) SetMode(mode ImageViewMode) {
 
 	iv.mode = mode
 
+	iv.updateViewport()
+
 	iv.Invalidate()
 
 	iv.RequestLayout()
@@ -98,20 +163,50 @@ func (iv *ImageView) SetMode(mode ImageViewMode) {
 func (iv *ImageView) applyDPI(dpi int) {
 	iv.CustomWidget.ApplyDPI(dpi)
 
+	iv.updateViewport()
+
 	iv.Invalidate()
 
 	iv.RequestLayout()
 }
 
+func (iv *ImageView) Dispose() {
+	iv.loadGeneration++
+
+	if iv.ownsImage {
+		iv.image.Dispose()
+		iv.image = nil
+		iv.ownsImage = false
+	}
+
+	iv.CustomWidget.Dispose()
+}
+
+// Image returns the image that is shown, which is the placeholder while an
+// image is loading.
 func (iv *ImageView) Image() Image {
 	return iv.image
 }
 
+// SetImage shows image. It cancels loading an image.
 func (iv *ImageView) SetImage(image Image) error {
+	iv.cancelLoading()
+
+	return iv.setImage(image, false)
+}
+
+// setImage shows image. If owned is true, the ImageView disposes it when it
+// is replaced or the ImageView is disposed.
+func (iv *ImageView) setImage(image Image, owned bool) error {
 	if image == iv.image {
 		return nil
 	}
 
+	if iv.ownsImage {
+		defer iv.image.Dispose()
+	}
+	iv.ownsImage = owned
+
 	var oldSize, newSize Size // in 1/96" units
 	if iv.image != nil {
 		oldSize = iv.image.Size()
@@ -122,6 +217,9 @@ func (iv *ImageView) SetImage(image Image) error {
 
 	iv.image = image
 
+	iv.viewport.CenterX, iv.viewport.CenterY = 0.5, 0.5
+	iv.updateViewport()
+
 	_, isMetafile := image.(*Metafile)
 	iv.SetClearsBackground(isMetafile)
 
@@ -140,6 +238,103 @@ func (iv *ImageView) ImageChanged() *Event {
 	return iv.imageChangedPublisher.Event()
 }
 
+// LoadImage loads the image file at filePath in the background and shows it
+// when it is ready. Until then, the placeholder is shown. It must be called
+// from the UI thread.
+//
+// SVG, PNG, JPEG and GIF files are decoded in the background, along with
+// their variants for higher DPIs, like open@2x.png. Other formats are loaded
+// on the UI thread. The loaded image is disposed by the ImageView when it is
+// replaced.
+func (iv *ImageView) LoadImage(filePath string) {
+	iv.loadGeneration++
+	generation := iv.loadGeneration
+
+	iv.setImage(iv.placeholder, false)
+	iv.setLoading(true)
+
+	go func() {
+		decoded, err := decodeImageFile(filePath)
+
+		iv.Synchronize(func() {
+			if generation != iv.loadGeneration || iv.IsDisposed() {
+				return
+			}
+
+			var img Image
+			if err == nil {
+				img, err = decoded.image(96)
+			}
+
+			iv.setLoading(false)
+
+			if err != nil {
+				iv.imageLoadFailedPublisher.Publish(err)
+				return
+			}
+
+			iv.setImage(img, true)
+		})
+	}()
+}
+
+func (iv *ImageView) cancelLoading() {
+	iv.loadGeneration++
+
+	iv.setLoading(false)
+}
+
+// Loading returns if an image is being loaded by LoadImage.
+func (iv *ImageView) Loading() bool {
+	return iv.loading
+}
+
+func (iv *ImageView) setLoading(loading bool) {
+	if loading == iv.loading {
+		return
+	}
+
+	iv.loading = loading
+
+	iv.loadingChangedPublisher.Publish()
+}
+
+// LoadingChanged returns the event that is published when loading an image
+// starts or ends.
+func (iv *ImageView) LoadingChanged() *Event {
+	return iv.loadingChangedPublisher.Event()
+}
+
+// ImageLoadFailed returns the event that is published when LoadImage failed.
+// The placeholder stays visible then.
+func (iv *ImageView) ImageLoadFailed() *ErrorEvent {
+	return iv.imageLoadFailedPublisher.Event()
+}
+
+// Placeholder returns the image that is shown while an image is loading.
+func (iv *ImageView) Placeholder() Image {
+	return iv.placeholder
+}
+
+// SetPlaceholder sets the image that is shown while an image is loading.
+func (iv *ImageView) SetPlaceholder(placeholder Image) error {
+	if placeholder == iv.placeholder {
+		return nil
+	}
+
+	shown := iv.loading && iv.image == iv.placeholder
+
+	iv.placeholder = placeholder
+
+	iv.placeholderChangedPublisher.Publish()
+
+	if shown {
+		return iv.setImage(placeholder, false)
+	}
+
+	return nil
+}
+
 func (iv *ImageView) Margin() int {
 	return iv.margin96dpi
 }
@@ -151,6 +346,8 @@ func (iv *ImageView) SetMargin(margin int) error {
 
 	iv.margin96dpi = margin
 
+	iv.updateViewport()
+
 	err := iv.Invalidate()
 
 	if iv.mode == ImageViewModeIdeal {
@@ -166,11 +363,184 @@ func (iv *ImageView) MarginChanged() *Event {
 	return iv.marginChangedPublisher.Event()
 }
 
+// Zoom returns the factor by which the image is scaled in
+// ImageViewModeViewport, where 1 shows it at its actual size.
+func (iv *ImageView) Zoom() float64 {
+	return iv.viewport.Zoom
+}
+
+// SetZoom scales the image by zoom, keeping its center where it is. It
+// switches to ImageViewModeViewport.
+func (iv *ImageView) SetZoom(zoom float64) {
+	iv.fit = imageViewFitNone
+
+	viewSize := iv.viewport.ViewSize
+	iv.zoomAround(zoom, Point{viewSize.Width / 2, viewSize.Height / 2})
+}
+
+// ZoomChanged returns the event that is published when the zoom changes, by
+// the user or because the image is kept fitting the ImageView.
+func (iv *ImageView) ZoomChanged() *Event {
+	return iv.zoomChangedPublisher.Event()
+}
+
+// ZoomToFit scales the image to fit into the ImageView as a whole, and keeps
+// it fitting when the ImageView is resized, until the zoom is changed
+// otherwise. It switches to ImageViewModeViewport.
+func (iv *ImageView) ZoomToFit() {
+	iv.zoomToFit(imageViewFitWhole)
+}
+
+// ZoomToFill scales the image to cover the whole ImageView, and keeps it
+// covering it when the ImageView is resized, until the zoom is changed
+// otherwise. It switches to ImageViewModeViewport.
+func (iv *ImageView) ZoomToFill() {
+	iv.zoomToFit(imageViewFitFill)
+}
+
+// ZoomToActualSize shows the image at its actual size. It switches to
+// ImageViewModeViewport.
+func (iv *ImageView) ZoomToActualSize() {
+	iv.SetZoom(1)
+}
+
+func (iv *ImageView) zoomToFit(fit imageViewFit) {
+	iv.fit = fit
+
+	iv.SetMode(ImageViewModeViewport)
+
+	iv.updateViewport()
+
+	iv.Invalidate()
+}
+
+// zoomAround sets the zoom, keeping the point p of the view where it is.
+func (iv *ImageView) zoomAround(zoom float64, p Point) {
+	iv.SetMode(ImageViewModeViewport)
+
+	oldZoom := iv.viewport.Zoom
+
+	iv.viewport.SetZoomAround(zoom, viewport.Point{X: p.X, Y: p.Y})
+
+	iv.Invalidate()
+
+	if iv.viewport.Zoom != oldZoom {
+		iv.zoomChangedPublisher.Publish()
+	}
+}
+
+// PixelGridVisible returns if a grid between the pixels of the image is
+// drawn at high zoom levels.
+func (iv *ImageView) PixelGridVisible() bool {
+	return iv.pixelGridVisible
+}
+
+// SetPixelGridVisible sets if a grid between the pixels of the image is drawn
+// at high zoom levels.
+func (iv *ImageView) SetPixelGridVisible(visible bool) {
+	if visible == iv.pixelGridVisible {
+		return
+	}
+
+	iv.pixelGridVisible = visible
+
+	iv.Invalidate()
+}
+
+// updateViewport updates the viewport to the image, size, margin and DPI of
+// the ImageView, keeping the image fitting if requested.
+func (iv *ImageView) updateViewport() {
+	dpi := iv.DPI()
+	margin := IntFrom96DPI(iv.margin96dpi, dpi)
+
+	cb := iv.ClientBoundsPixels()
+	iv.viewport.ViewSize = viewport.Size{Width: maxi(0, cb.Width-margin*2), Height: maxi(0, cb.Height-margin*2)}
+
+	if iv.image != nil {
+		s := SizeFrom96DPI(iv.image.Size(), dpi)
+		iv.viewport.ImageSize = viewport.Size{Width: s.Width, Height: s.Height}
+	} else {
+		iv.viewport.ImageSize = viewport.Size{}
+	}
+
+	oldZoom := iv.viewport.Zoom
+
+	switch iv.fit {
+	case imageViewFitWhole:
+		iv.viewport.Zoom = iv.viewport.FitZoom()
+
+	case imageViewFitFill:
+		iv.viewport.Zoom = iv.viewport.FillZoom()
+	}
+
+	iv.viewport.Clamp()
+
+	if iv.viewport.Zoom != oldZoom {
+		iv.zoomChangedPublisher.Publish()
+	}
+}
+
+func (iv *ImageView) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_MOUSEWHEEL:
+		if iv.mode != ImageViewModeViewport || iv.image == nil {
+			break
+		}
+
+		// The position of wheel messages is in screen coordinates.
+		pt := win.POINT{X: win.GET_X_LPARAM(lParam), Y: win.GET_Y_LPARAM(lParam)}
+		win.ScreenToClient(iv.hWnd, &pt)
+
+		margin := IntFrom96DPI(iv.margin96dpi, iv.DPI())
+		delta := float64(int16(win.HIWORD(uint32(wParam)))) / 120 // WHEEL_DELTA
+
+		iv.fit = imageViewFitNone
+		iv.zoomAround(iv.viewport.Zoom*math.Pow(imageViewWheelZoomFactor, delta), Point{int(pt.X) - margin, int(pt.Y) - margin})
+
+	case win.WM_LBUTTONDOWN:
+		if iv.mode != ImageViewModeViewport || iv.image == nil {
+			break
+		}
+
+		iv.panning = true
+		iv.panOrigin = Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}
+
+		win.SetCursor(CursorSizeAll().handle())
+
+	case win.WM_MOUSEMOVE:
+		if !iv.panning {
+			break
+		}
+
+		p := Point{int(win.GET_X_LPARAM(lParam)), int(win.GET_Y_LPARAM(lParam))}
+
+		iv.viewport.PanBy(p.X-iv.panOrigin.X, p.Y-iv.panOrigin.Y)
+		iv.panOrigin = p
+
+		iv.Invalidate()
+
+	case win.WM_LBUTTONUP, win.WM_CAPTURECHANGED:
+		iv.panning = false
+
+	case win.WM_SETCURSOR:
+		if iv.panning {
+			win.SetCursor(CursorSizeAll().handle())
+			return 1
+		}
+	}
+
+	return iv.CustomWidget.WndProc(hwnd, msg, wParam, lParam)
+}
+
 func (iv *ImageView) drawImage(canvas *Canvas, _ Rectangle) error {
 	if iv.image == nil {
 		return nil
 	}
 
+	if iv.mode == ImageViewModeViewport {
+		return iv.drawViewport(canvas)
+	}
+
 	cb := iv.ClientBoundsPixels()
 
 	dpi := iv.DPI()
@@ -230,6 +600,72 @@ func (iv *ImageView) drawImage(canvas *Canvas, _ Rectangle) error {
 	return canvas.DrawImageStretchedPixels(iv.image, bounds)
 }
 
+// drawViewport draws the part of the zoomed image that is in the viewport.
+func (iv *ImageView) drawViewport(canvas *Canvas) error {
+	margin := IntFrom96DPI(iv.margin96dpi, iv.DPI())
+
+	view := viewport.Rect{X: margin, Y: margin, Width: iv.viewport.ViewSize.Width, Height: iv.viewport.ViewSize.Height}
+	if view.Width == 0 || view.Height == 0 {
+		return nil
+	}
+
+	win.IntersectClipRect(canvas.hdc, int32(view.X), int32(view.Y), int32(view.X+view.Width), int32(view.Y+view.Height))
+
+	bounds := iv.viewport.Bounds()
+	bounds.X += margin
+	bounds.Y += margin
+
+	bmp, ok := iv.image.(*Bitmap)
+	if !ok {
+		return canvas.DrawImageStretchedPixels(iv.image, Rectangle{bounds.X, bounds.Y, bounds.Width, bounds.Height})
+	}
+	bmp = bmp.variantForSize(Size{bounds.Width, bounds.Height})
+
+	// Only draw the visible part of bitmaps, which may be huge when zoomed.
+	vdst, vsrc, ok := viewport.VisiblePart(bounds, view, viewport.Size{Width: bmp.size.Width, Height: bmp.size.Height})
+	if !ok {
+		return nil
+	}
+	dst := Rectangle{vdst.X, vdst.Y, vdst.Width, vdst.Height}
+	src := Rectangle{vsrc.X, vsrc.Y, vsrc.Width, vsrc.Height}
+
+	if err := canvas.DrawBitmapPartWithOpacityPixels(bmp, dst, src, 255); err != nil {
+		return err
+	}
+
+	if iv.pixelGridVisible && float64(bounds.Width)/float64(bmp.size.Width) >= imageViewPixelGridMinScale {
+		return iv.drawPixelGrid(canvas, dst, src)
+	}
+
+	return nil
+}
+
+// drawPixelGrid draws lines between the pixels of the part src of a bitmap
+// that is drawn to dst.
+func (iv *ImageView) drawPixelGrid(canvas *Canvas, dst, src Rectangle) error {
+	pen, err := NewCosmeticPen(PenSolid, RGB(192, 192, 192))
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	for x := 1; x < src.Width; x++ {
+		px := dst.X + x*dst.Width/src.Width
+		if err := canvas.DrawLinePixels(pen, Point{px, dst.Y}, Point{px, dst.Y + dst.Height}); err != nil {
+			return err
+		}
+	}
+
+	for y := 1; y < src.Height; y++ {
+		py := dst.Y + y*dst.Height/src.Height
+		if err := canvas.DrawLinePixels(pen, Point{dst.X, py}, Point{dst.X + dst.Width, py}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (iv *ImageView) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
 	var layoutFlags LayoutFlags
 	if iv.mode != ImageViewModeIdeal {
@@ -0,0 +1,902 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lxn/win"
+)
+
+const inspectorHighlightWindowClass = `\o/ Walk_InspectorHighlight_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(inspectorHighlightWindowClass)
+	})
+}
+
+// inspectorHighlightWidth is the width in native pixels of the frame drawn
+// around the selected widget.
+const inspectorHighlightWidth = 3
+
+// Inspector is a developer tool that shows the widget tree of a Form in a
+// window of its own.
+//
+// For the selected widget, it lists the registered properties with their
+// values, DataBinder bindings and attached Conditions and shows the layout
+// geometry. Property values can be edited and the selected widget is
+// highlighted on screen.
+//
+// The Inspector window is created when it is first shown.
+type Inspector struct {
+	form           Form
+	mw             *MainWindow
+	treeView       *TreeView
+	treeModel      *inspectorTreeModel
+	propsView      *TableView
+	propsModel     *inspectorPropertyModel
+	valueEdit      *LineEdit
+	detailsEdit    *TextEdit
+	highlights     [4]*inspectorHighlight
+	highlightBrush *SolidColorBrush
+	current        Window
+	changedHandles []inspectorChangedHandle
+	disposed       bool
+}
+
+type inspectorChangedHandle struct {
+	event  *Event
+	handle int
+}
+
+// NewInspector returns a new Inspector for form. It is disposed of together
+// with form.
+func NewInspector(form Form) *Inspector {
+	ins := &Inspector{form: form}
+
+	form.Disposing().Attach(ins.Dispose)
+
+	return ins
+}
+
+// AttachInspector returns a new Inspector for form, that is toggled when
+// shortcut is pressed while form has the keyboard focus.
+//
+// It is meant for development builds, e.g.
+//
+//	walk.AttachInspector(mw, walk.Shortcut{walk.ModControl | walk.ModShift, walk.KeyI})
+func AttachInspector(form Form, shortcut Shortcut) (*Inspector, error) {
+	ins := NewInspector(form)
+
+	action := NewAction()
+	if err := action.SetShortcut(shortcut); err != nil {
+		return nil, err
+	}
+	action.Triggered().Attach(func() {
+		if err := ins.Toggle(); err != nil {
+			MsgBox(form, "Walk Inspector", err.Error(), MsgBoxIconError)
+		}
+	})
+
+	if err := form.AsFormBase().ShortcutActions().Add(action); err != nil {
+		return nil, err
+	}
+
+	return ins, nil
+}
+
+// Dispose releases the Inspector window and the highlight.
+func (ins *Inspector) Dispose() {
+	if ins.disposed {
+		return
+	}
+	ins.disposed = true
+
+	ins.detachPropertyChanged()
+
+	for _, h := range ins.highlights {
+		if h != nil {
+			h.Dispose()
+		}
+	}
+
+	if ins.highlightBrush != nil {
+		ins.highlightBrush.Dispose()
+	}
+
+	if ins.mw != nil {
+		ins.mw.Dispose()
+	}
+}
+
+// Form returns the Form that is inspected.
+func (ins *Inspector) Form() Form {
+	return ins.form
+}
+
+// Visible returns if the Inspector window is visible.
+func (ins *Inspector) Visible() bool {
+	return ins.mw != nil && ins.mw.Visible()
+}
+
+// Show shows the Inspector window, creating it if necessary, and refreshes
+// the widget tree.
+func (ins *Inspector) Show() error {
+	if ins.disposed {
+		return newError("inspector has been disposed")
+	}
+
+	if ins.mw == nil {
+		if err := ins.create(); err != nil {
+			return err
+		}
+	}
+
+	if err := ins.Refresh(); err != nil {
+		return err
+	}
+
+	ins.mw.Show()
+	ins.mw.startLayout()
+
+	return nil
+}
+
+// Hide hides the Inspector window and the highlight.
+func (ins *Inspector) Hide() {
+	if ins.mw == nil {
+		return
+	}
+
+	ins.mw.Hide()
+	ins.hideHighlight()
+}
+
+// Toggle shows the Inspector window if it is hidden and hides it otherwise.
+func (ins *Inspector) Toggle() error {
+	if ins.Visible() {
+		ins.Hide()
+		return nil
+	}
+
+	return ins.Show()
+}
+
+// Refresh rebuilds the widget tree, e.g. after widgets have been added or
+// removed. The selected widget stays selected if it still exists.
+func (ins *Inspector) Refresh() error {
+	if ins.mw == nil {
+		return nil
+	}
+
+	current := ins.current
+
+	ins.treeModel.reset(ins.form)
+
+	if current != nil {
+		if item := ins.treeModel.itemForWindow(current); item != nil {
+			return ins.treeView.SetCurrentItem(item)
+		}
+	}
+
+	return ins.setCurrent(nil)
+}
+
+// Current returns the selected widget, or nil.
+func (ins *Inspector) Current() Window {
+	return ins.current
+}
+
+func (ins *Inspector) create() (err error) {
+	if ins.mw, err = NewMainWindowWithCfg(&MainWindowCfg{
+		Name:   "walkInspector",
+		Bounds: Rectangle{Width: 900, Height: 600},
+	}); err != nil {
+		return err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			ins.mw.Dispose()
+			ins.mw = nil
+		}
+	}()
+
+	ins.mw.SetTitle("Walk Inspector")
+	ins.mw.Closing().Attach(func(canceled *bool, reason CloseReason) {
+		*canceled = true
+		ins.Hide()
+	})
+
+	ins.mw.SetLayout(NewVBoxLayout())
+
+	toolbar, err := NewComposite(ins.mw)
+	if err != nil {
+		return err
+	}
+	toolbarLayout := NewHBoxLayout()
+	toolbarLayout.SetMargins(Margins{})
+	toolbar.SetLayout(toolbarLayout)
+
+	refreshPB, err := NewPushButton(toolbar)
+	if err != nil {
+		return err
+	}
+	refreshPB.SetText(tr("Refresh", "walk"))
+	refreshPB.Clicked().Attach(func() {
+		ins.showError(ins.Refresh())
+	})
+
+	if _, err := NewHSpacer(toolbar); err != nil {
+		return err
+	}
+
+	splitter, err := NewHSplitter(ins.mw)
+	if err != nil {
+		return err
+	}
+
+	if ins.treeView, err = NewTreeView(splitter); err != nil {
+		return err
+	}
+	ins.treeModel = new(inspectorTreeModel)
+	if err := ins.treeView.SetModel(ins.treeModel); err != nil {
+		return err
+	}
+	ins.treeView.CurrentItemChanged().Attach(func() {
+		var window Window
+		if item, ok := ins.treeView.CurrentItem().(*inspectorTreeItem); ok {
+			window = item.window
+		}
+
+		ins.showError(ins.setCurrent(window))
+	})
+
+	details, err := NewComposite(splitter)
+	if err != nil {
+		return err
+	}
+	detailsLayout := NewVBoxLayout()
+	detailsLayout.SetMargins(Margins{})
+	details.SetLayout(detailsLayout)
+
+	if ins.propsView, err = NewTableView(details); err != nil {
+		return err
+	}
+	for _, c := range []struct {
+		title string
+		width int
+	}{
+		{"Property", 120},
+		{"Value", 180},
+		{"Source", 220},
+	} {
+		col := NewTableViewColumn()
+		col.SetTitle(c.title)
+		col.SetWidth(c.width)
+		if err := ins.propsView.Columns().Add(col); err != nil {
+			return err
+		}
+	}
+	ins.propsModel = new(inspectorPropertyModel)
+	if err := ins.propsView.SetModel(ins.propsModel); err != nil {
+		return err
+	}
+	ins.propsView.CurrentIndexChanged().Attach(ins.updateValueEdit)
+
+	editor, err := NewComposite(details)
+	if err != nil {
+		return err
+	}
+	editorLayout := NewHBoxLayout()
+	editorLayout.SetMargins(Margins{})
+	editor.SetLayout(editorLayout)
+
+	if ins.valueEdit, err = NewLineEdit(editor); err != nil {
+		return err
+	}
+	ins.valueEdit.SetCueBanner("New value of the selected property")
+	ins.valueEdit.KeyDown().Attach(func(key Key) {
+		if key == KeyReturn {
+			ins.showError(ins.applyValue())
+		}
+	})
+
+	setPB, err := NewPushButton(editor)
+	if err != nil {
+		return err
+	}
+	setPB.SetText(tr("Set", "walk"))
+	setPB.Clicked().Attach(func() {
+		ins.showError(ins.applyValue())
+	})
+
+	if ins.detailsEdit, err = NewTextEdit(details); err != nil {
+		return err
+	}
+	ins.detailsEdit.SetReadOnly(true)
+
+	if ins.highlightBrush, err = NewSolidColorBrush(RGB(255, 0, 0)); err != nil {
+		return err
+	}
+	for i := range ins.highlights {
+		if ins.highlights[i], err = newInspectorHighlight(ins.highlightBrush); err != nil {
+			return err
+		}
+	}
+
+	succeeded = true
+
+	return nil
+}
+
+func (ins *Inspector) showError(err error) {
+	if err != nil {
+		MsgBox(ins.mw, "Walk Inspector", err.Error(), MsgBoxIconError)
+	}
+}
+
+func (ins *Inspector) setCurrent(window Window) error {
+	ins.detachPropertyChanged()
+
+	ins.current = window
+
+	if window == nil || window.IsDisposed() {
+		ins.current = nil
+		ins.propsModel.reset(nil)
+		ins.detailsEdit.SetText("")
+		ins.hideHighlight()
+		return nil
+	}
+
+	ins.propsModel.reset(window)
+
+	// Keep the values live while the widget is selected.
+	for _, row := range ins.propsModel.rows {
+		if event := row.prop.Changed(); event != nil {
+			handle := event.Attach(ins.refreshProperties)
+			ins.changedHandles = append(ins.changedHandles, inspectorChangedHandle{event, handle})
+		}
+	}
+
+	ins.updateValueEdit()
+	ins.refreshDetails()
+	ins.showHighlight()
+
+	return nil
+}
+
+func (ins *Inspector) detachPropertyChanged() {
+	for _, h := range ins.changedHandles {
+		h.event.Detach(h.handle)
+	}
+
+	ins.changedHandles = nil
+}
+
+func (ins *Inspector) refreshProperties() {
+	if ins.current == nil || ins.current.IsDisposed() {
+		return
+	}
+
+	ins.propsModel.refresh()
+	ins.refreshDetails()
+	ins.showHighlight()
+}
+
+func (ins *Inspector) refreshDetails() {
+	ins.detailsEdit.SetText(inspectorDetails(ins.current))
+}
+
+func (ins *Inspector) updateValueEdit() {
+	index := ins.propsView.CurrentIndex()
+	if index < 0 || index >= len(ins.propsModel.rows) {
+		ins.valueEdit.SetText("")
+		ins.valueEdit.SetEnabled(false)
+		return
+	}
+
+	row := ins.propsModel.rows[index]
+
+	ins.valueEdit.SetText(inspectorEditText(row.prop.Get()))
+	ins.valueEdit.SetEnabled(!row.prop.ReadOnly())
+}
+
+func (ins *Inspector) applyValue() error {
+	index := ins.propsView.CurrentIndex()
+	if index < 0 || index >= len(ins.propsModel.rows) {
+		return nil
+	}
+
+	row := ins.propsModel.rows[index]
+
+	value, err := inspectorParseValue(ins.valueEdit.Text(), row.prop.Get())
+	if err != nil {
+		return err
+	}
+
+	if err := row.prop.Set(value); err != nil {
+		return err
+	}
+
+	ins.refreshProperties()
+
+	return nil
+}
+
+func (ins *Inspector) showHighlight() {
+	if ins.current == nil || !ins.current.Visible() || !ins.mw.Visible() {
+		ins.hideHighlight()
+		return
+	}
+
+	var r win.RECT
+	if !win.GetWindowRect(ins.current.Handle(), &r) {
+		ins.hideHighlight()
+		return
+	}
+
+	const w = inspectorHighlightWidth
+	left, top, right, bottom := int(r.Left)-w, int(r.Top)-w, int(r.Right)+w, int(r.Bottom)+w
+
+	edges := [4]Rectangle{
+		{left, top, right - left, w},
+		{left, bottom - w, right - left, w},
+		{left, top, w, bottom - top},
+		{right - w, top, w, bottom - top},
+	}
+
+	for i, h := range ins.highlights {
+		h.showAt(edges[i])
+	}
+}
+
+func (ins *Inspector) hideHighlight() {
+	for _, h := range ins.highlights {
+		if h != nil {
+			win.ShowWindow(h.hWnd, win.SW_HIDE)
+		}
+	}
+}
+
+// inspectorHighlight is one edge of the frame around the selected widget. It
+// is a top level window, so it can draw above all widgets of the Form.
+type inspectorHighlight struct {
+	WindowBase
+	brush Brush
+}
+
+func newInspectorHighlight(brush Brush) (*inspectorHighlight, error) {
+	h := &inspectorHighlight{brush: brush}
+
+	if err := InitWindow(
+		h,
+		nil,
+		inspectorHighlightWindowClass,
+		win.WS_POPUP,
+		win.WS_EX_TOOLWINDOW|win.WS_EX_TOPMOST|win.WS_EX_NOACTIVATE); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (h *inspectorHighlight) showAt(bounds Rectangle) {
+	win.SetWindowPos(
+		h.hWnd,
+		win.HWND_TOPMOST,
+		int32(bounds.X),
+		int32(bounds.Y),
+		int32(bounds.Width),
+		int32(bounds.Height),
+		win.SWP_NOACTIVATE|win.SWP_SHOWWINDOW)
+}
+
+func (h *inspectorHighlight) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_NCHITTEST:
+		// Let clicks through to the widgets below.
+		ht := win.HTTRANSPARENT
+		return uintptr(ht)
+
+	case win.WM_ERASEBKGND:
+		return 1
+
+	case win.WM_PAINT:
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		defer win.EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return 0
+		}
+		defer canvas.Dispose()
+
+		canvas.FillRectanglePixels(h.brush, h.ClientBoundsPixels())
+
+		return 0
+	}
+
+	return h.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+type inspectorTreeItem struct {
+	parent   *inspectorTreeItem
+	window   Window
+	children []*inspectorTreeItem
+}
+
+func newInspectorTreeItem(parent *inspectorTreeItem, window Window) *inspectorTreeItem {
+	item := &inspectorTreeItem{parent: parent, window: window}
+
+	for _, wb := range childWidgets(window) {
+		item.children = append(item.children, newInspectorTreeItem(item, wb.window))
+	}
+
+	return item
+}
+
+func (item *inspectorTreeItem) Text() string {
+	class := inspectorClassName(item.window)
+
+	if name := item.window.Name(); name != "" {
+		return fmt.Sprintf("%s %q", class, name)
+	}
+
+	return class
+}
+
+func (item *inspectorTreeItem) Parent() TreeItem {
+	if item.parent == nil {
+		// We can't return item.parent directly, because then
+		// the interface would be not nil.
+		return nil
+	}
+
+	return item.parent
+}
+
+func (item *inspectorTreeItem) ChildCount() int {
+	return len(item.children)
+}
+
+func (item *inspectorTreeItem) ChildAt(index int) TreeItem {
+	return item.children[index]
+}
+
+type inspectorTreeModel struct {
+	TreeModelBase
+	root *inspectorTreeItem
+}
+
+func (tm *inspectorTreeModel) reset(form Form) {
+	if form == nil || form.IsDisposed() {
+		tm.root = nil
+	} else {
+		tm.root = newInspectorTreeItem(nil, form)
+	}
+
+	tm.PublishItemsReset(nil)
+}
+
+func (tm *inspectorTreeModel) RootCount() int {
+	if tm.root == nil {
+		return 0
+	}
+
+	return 1
+}
+
+func (tm *inspectorTreeModel) RootAt(index int) TreeItem {
+	return tm.root
+}
+
+func (tm *inspectorTreeModel) itemForWindow(window Window) *inspectorTreeItem {
+	var find func(item *inspectorTreeItem) *inspectorTreeItem
+	find = func(item *inspectorTreeItem) *inspectorTreeItem {
+		if item.window == window {
+			return item
+		}
+
+		for _, child := range item.children {
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+
+		return nil
+	}
+
+	if tm.root == nil {
+		return nil
+	}
+
+	return find(tm.root)
+}
+
+type inspectorPropertyRow struct {
+	name string
+	prop Property
+}
+
+type inspectorPropertyModel struct {
+	TableModelBase
+	window Window
+	rows   []inspectorPropertyRow
+}
+
+func (m *inspectorPropertyModel) reset(window Window) {
+	m.window = window
+	m.rows = nil
+
+	if window != nil {
+		for name, prop := range window.AsWindowBase().name2Property {
+			m.rows = append(m.rows, inspectorPropertyRow{name, prop})
+		}
+
+		sort.Slice(m.rows, func(i, j int) bool {
+			return m.rows[i].name < m.rows[j].name
+		})
+	}
+
+	m.PublishRowsReset()
+}
+
+func (m *inspectorPropertyModel) refresh() {
+	if len(m.rows) > 0 {
+		m.PublishRowsChanged(0, len(m.rows)-1)
+	}
+}
+
+func (m *inspectorPropertyModel) RowCount() int {
+	return len(m.rows)
+}
+
+func (m *inspectorPropertyModel) Value(row, col int) interface{} {
+	r := m.rows[row]
+
+	switch col {
+	case 0:
+		return r.name
+
+	case 1:
+		return inspectorFormatValue(r.prop.Get())
+
+	case 2:
+		return inspectorDescribeSource(m.window, r.prop)
+	}
+
+	panic("unexpected col")
+}
+
+// inspectorDescribeSource describes what a property is bound to: a
+// DataBinder field, a Condition or another Expression.
+func inspectorDescribeSource(window Window, prop Property) string {
+	var desc string
+
+	switch source := prop.Source().(type) {
+	case nil:
+		return ""
+
+	case string:
+		desc = "binding: " + source
+
+		if db := inspectorDataBinder(window); db != nil && db.DataSource() != nil {
+			desc += " = " + inspectorFormatValue(db.Expression(source).Value())
+		}
+
+		if c := propertyConverter(prop); c != nil {
+			desc += fmt.Sprintf(" via %T", c)
+		}
+
+	case Condition:
+		desc = fmt.Sprintf("condition: %T, satisfied: %t", source, source.Satisfied())
+
+	case Expression:
+		desc = fmt.Sprintf("expression: %T = %s", source, inspectorFormatValue(source.Value()))
+
+	default:
+		desc = fmt.Sprintf("%T", source)
+	}
+
+	return desc
+}
+
+// inspectorDataBinder returns the DataBinder responsible for window, which is
+// the one of the nearest ancestor Container that has one.
+func inspectorDataBinder(window Window) *DataBinder {
+	widget, ok := window.(Widget)
+	if !ok {
+		return nil
+	}
+
+	for parent := widget.Parent(); parent != nil; {
+		if db := parent.DataBinder(); db != nil {
+			return db
+		}
+
+		w, ok := parent.(Widget)
+		if !ok {
+			break
+		}
+		parent = w.Parent()
+	}
+
+	return nil
+}
+
+func inspectorDetails(window Window) string {
+	if window == nil {
+		return ""
+	}
+
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	add("Type: %T", window)
+	add("Name: %s", window.Name())
+	add("Visible: %t, Enabled: %t, DPI: %d", window.Visible(), window.Enabled(), window.DPI())
+	add("Bounds: %s", inspectorFormatRectangle(window.Bounds()))
+	add("Bounds (pixels): %s", inspectorFormatRectangle(window.BoundsPixels()))
+	add("Client bounds (pixels): %s", inspectorFormatRectangle(window.ClientBoundsPixels()))
+	add("Min size: %s, Max size: %s", inspectorFormatSize(window.MinSize()), inspectorFormatSize(window.MaxSize()))
+
+	if widget, ok := window.(Widget); ok {
+		add("Size hint: %s, Min size hint: %s",
+			inspectorFormatSize(widget.SizeHint()), inspectorFormatSize(widget.MinSizeHint()))
+		add("Alignment: %v", widget.Alignment())
+
+		if parent := widget.Parent(); parent != nil {
+			switch layout := parent.Layout().(type) {
+			case *BoxLayout:
+				add("Stretch factor: %d", layout.StretchFactor(widget))
+
+			case *GridLayout:
+				if r, ok := layout.Range(widget); ok {
+					add("Grid range: row %d, column %d, row span %d, column span %d",
+						r.Y, r.X, r.Height, r.Width)
+				}
+			}
+		}
+	}
+
+	if container, ok := window.(Container); ok && container.Layout() != nil {
+		layout := container.Layout()
+		m := layout.Margins()
+
+		add("Layout: %T", layout)
+		add("Layout margins: %d, %d, %d, %d, spacing: %d", m.HNear, m.VNear, m.HFar, m.VFar, layout.Spacing())
+	}
+
+	if container, ok := window.(Container); ok && container.DataBinder() != nil {
+		db := container.DataBinder()
+
+		add("DataBinder: data source %T, %d bound widgets, dirty: %t, can submit: %t",
+			db.DataSource(), len(db.BoundWidgets()), db.Dirty(), db.CanSubmit())
+	}
+
+	return strings.Join(lines, "\r\n")
+}
+
+func inspectorClassName(window Window) string {
+	t := reflect.TypeOf(window)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+func inspectorFormatRectangle(r Rectangle) string {
+	return fmt.Sprintf("x %d, y %d, width %d, height %d", r.X, r.Y, r.Width, r.Height)
+}
+
+func inspectorFormatSize(s Size) string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+func inspectorFormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+
+	case string:
+		return v
+
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// inspectorEditText returns the text for editing value, which, unlike the one
+// of inspectorFormatValue, can be parsed by inspectorParseValue.
+func inspectorEditText(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	}
+
+	return inspectorFormatValue(value)
+}
+
+// inspectorParseValue parses text as a value of the same type as current.
+func inspectorParseValue(text string, current interface{}) (interface{}, error) {
+	if current == nil {
+		return text, nil
+	}
+
+	t := reflect.TypeOf(current)
+	v := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(text)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 0, t.Bits())
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(text, 0, t.Bits())
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		v.SetFloat(f)
+
+	default:
+		return nil, newError(fmt.Sprintf("Can't edit values of type %s.", t))
+	}
+
+	return v.Interface(), nil
+}
@@ -8,6 +8,7 @@ package walk
 
 import (
 	"sync"
+	"time"
 
 	"github.com/lxn/win"
 )
@@ -305,10 +306,12 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 		}
 
 		var maybeInvalidate bool
+		var animationDuration time.Duration
 		if wnd := windowFromHandle(result.container.Handle()); wnd != nil {
 			if ctr, ok := wnd.(Container); ok {
 				if cb := ctr.AsContainerBase(); cb != nil {
 					maybeInvalidate = cb.hasComplexBackground()
+					animationDuration = cb.layoutAnimationDuration
 				}
 			}
 		}
@@ -335,6 +338,15 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 				}
 
 				widget := window.(Widget)
+				wb := widget.AsWidgetBase()
+
+				if ga := wb.geometryAnimation; ga != nil && ga.Running() {
+					if ga.To() == ri.Bounds {
+						continue
+					}
+
+					ga.Stop()
+				}
 
 				oldBounds := widget.BoundsPixels()
 
@@ -342,6 +354,11 @@ func applyLayoutResults(results []LayoutResult, stopwatch *stopwatch) error {
 					continue
 				}
 
+				if animationDuration > 0 && oldBounds.Width > 0 && oldBounds.Height > 0 && win.IsWindowVisible(ri.Item.Handle()) {
+					wb.animateGeometry(ri.Bounds, animationDuration)
+					continue
+				}
+
 				if ri.Bounds.X == oldBounds.X && ri.Bounds.Y == oldBounds.Y && ri.Bounds.Width == oldBounds.Width {
 					if _, ok := widget.(*ComboBox); ok {
 						if ri.Bounds.Height == oldBounds.Height+1 {
@@ -36,6 +36,7 @@ type LineEdit struct {
 	charWidthFont            *Font
 	charWidth                int // in native pixels
 	textColor                Color
+	completer                *Completer
 }
 
 func newLineEdit(parent Window) (*LineEdit, error) {
@@ -304,6 +305,12 @@ func (*LineEdit) NeedsWmSize() bool {
 }
 
 func (le *LineEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if le.completer != nil {
+		if result, handled := le.completer.handleEditMessage(msg, wParam, lParam); handled {
+			return result
+		}
+	}
+
 	switch msg {
 	case win.WM_COMMAND:
 		switch win.HIWORD(uint32(wParam)) {
@@ -0,0 +1,379 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// LineProvider provides the lines of text that a CodeView shows.
+//
+// Lines are fetched only while they are visible or searched, so a
+// LineProvider may hold millions of them. It must only be changed and
+// accessed from the UI thread.
+type LineProvider interface {
+	// LineCount returns the number of lines.
+	LineCount() int
+
+	// Line returns the line at index, without a line break.
+	Line(index int) string
+
+	// LinesReset returns the event that the provider should publish when
+	// all of its lines changed.
+	LinesReset() *Event
+
+	// LinesInserted returns the event that the provider should publish
+	// when lines were inserted.
+	LinesInserted() *IntRangeEvent
+
+	// LinesRemoved returns the event that the provider should publish when
+	// lines were removed.
+	LinesRemoved() *IntRangeEvent
+}
+
+// LineProviderBase implements the event part of the LineProvider interface.
+type LineProviderBase struct {
+	linesResetPublisher    EventPublisher
+	linesInsertedPublisher IntRangeEventPublisher
+	linesRemovedPublisher  IntRangeEventPublisher
+}
+
+func (lpb *LineProviderBase) LinesReset() *Event {
+	return lpb.linesResetPublisher.Event()
+}
+
+func (lpb *LineProviderBase) LinesInserted() *IntRangeEvent {
+	return lpb.linesInsertedPublisher.Event()
+}
+
+func (lpb *LineProviderBase) LinesRemoved() *IntRangeEvent {
+	return lpb.linesRemovedPublisher.Event()
+}
+
+func (lpb *LineProviderBase) PublishLinesReset() {
+	lpb.linesResetPublisher.Publish()
+}
+
+func (lpb *LineProviderBase) PublishLinesInserted(from, to int) {
+	lpb.linesInsertedPublisher.Publish(from, to)
+}
+
+func (lpb *LineProviderBase) PublishLinesRemoved(from, to int) {
+	lpb.linesRemovedPublisher.Publish(from, to)
+}
+
+// LineBuffer is a LineProvider that keeps its lines in memory.
+//
+// If it has a maximum line count, the oldest lines are dropped as new ones
+// are appended, which suits log output.
+type LineBuffer struct {
+	LineProviderBase
+	lines    []string
+	maxLines int
+	partial  bool // Whether the last line has no line break yet.
+}
+
+// NewLineBuffer returns a new LineBuffer that keeps at most maxLines lines,
+// or any number of lines if maxLines is 0.
+func NewLineBuffer(maxLines int) *LineBuffer {
+	return &LineBuffer{maxLines: maxLines}
+}
+
+func (lb *LineBuffer) LineCount() int {
+	return len(lb.lines)
+}
+
+func (lb *LineBuffer) Line(index int) string {
+	return lb.lines[index]
+}
+
+// MaxLines returns the maximum number of lines, or 0 if there is none.
+func (lb *LineBuffer) MaxLines() int {
+	return lb.maxLines
+}
+
+// SetMaxLines sets the maximum number of lines, dropping the oldest lines
+// if there are more.
+func (lb *LineBuffer) SetMaxLines(maxLines int) {
+	lb.maxLines = maxLines
+
+	lb.trim()
+}
+
+// SetText replaces the lines with those of text.
+func (lb *LineBuffer) SetText(text string) {
+	lb.lines = nil
+	lb.partial = false
+
+	lb.appendText(text)
+	lb.trim()
+
+	lb.PublishLinesReset()
+}
+
+// AppendLines appends lines, which must not contain line breaks.
+func (lb *LineBuffer) AppendLines(lines ...string) {
+	if len(lines) == 0 {
+		return
+	}
+
+	from := len(lb.lines)
+
+	// A partial line ends here.
+	lb.partial = false
+
+	lb.lines = append(lb.lines, lines...)
+	lb.PublishLinesInserted(from, len(lb.lines)-1)
+
+	lb.trim()
+}
+
+// AppendText appends text, which is split into lines at line breaks. Text
+// after the last line break stays a partial line that following calls
+// append to.
+func (lb *LineBuffer) AppendText(text string) {
+	if text == "" {
+		return
+	}
+
+	from := len(lb.lines)
+
+	if lb.partial {
+		from--
+		lb.PublishLinesRemoved(from, from)
+	}
+
+	lb.appendText(text)
+	lb.PublishLinesInserted(from, len(lb.lines)-1)
+
+	lb.trim()
+}
+
+// Write implements the io.Writer interface by appending p as text.
+func (lb *LineBuffer) Write(p []byte) (int, error) {
+	lb.AppendText(string(p))
+
+	return len(p), nil
+}
+
+// Clear removes all lines.
+func (lb *LineBuffer) Clear() {
+	lb.lines = nil
+	lb.partial = false
+
+	lb.PublishLinesReset()
+}
+
+func (lb *LineBuffer) appendText(text string) {
+	parts := strings.Split(text, "\n")
+
+	if lb.partial {
+		last := len(lb.lines) - 1
+		lb.lines[last] += trimCR(parts[0])
+		parts = parts[1:]
+
+		if len(parts) == 0 {
+			return
+		}
+	}
+
+	lb.partial = parts[len(parts)-1] != ""
+	if !lb.partial {
+		parts = parts[:len(parts)-1]
+	}
+
+	for _, part := range parts {
+		lb.lines = append(lb.lines, trimCR(part))
+	}
+}
+
+// trim drops the oldest lines beyond the maximum.
+func (lb *LineBuffer) trim() {
+	if lb.maxLines <= 0 || len(lb.lines) <= lb.maxLines {
+		return
+	}
+
+	n := len(lb.lines) - lb.maxLines
+
+	// Release the dropped lines. Once append runs out of capacity, it moves
+	// the remaining ones to a new array.
+	for i := 0; i < n; i++ {
+		lb.lines[i] = ""
+	}
+	lb.lines = lb.lines[n:]
+
+	lb.PublishLinesRemoved(0, n-1)
+}
+
+func trimCR(s string) string {
+	return strings.TrimSuffix(s, "\r")
+}
+
+// fileLineProviderBlockSize is how much FileLineProvider reads at once.
+const fileLineProviderBlockSize = 64 * 1024
+
+// fileLineProviderMaxLineLength is the length in bytes after which
+// FileLineProvider cuts off lines.
+const fileLineProviderMaxLineLength = 64 * 1024
+
+// FileLineProvider is a LineProvider for the lines of a file, which is read
+// as UTF-8. It keeps only the offsets of the lines in memory, so that it
+// can provide large files.
+type FileLineProvider struct {
+	LineProviderBase
+	file        *os.File
+	offsets     []int64 // The start of each line, and the end of the last one.
+	partial     bool    // Whether the last line has no line break yet.
+	block       []byte
+	blockOffset int64
+}
+
+// NewFileLineProvider opens the file at filePath and indexes its lines.
+func NewFileLineProvider(filePath string) (*FileLineProvider, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	flp := &FileLineProvider{file: file, offsets: []int64{0}}
+
+	if err := flp.index(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return flp, nil
+}
+
+// Close closes the file.
+func (flp *FileLineProvider) Close() error {
+	return flp.file.Close()
+}
+
+func (flp *FileLineProvider) LineCount() int {
+	return len(flp.offsets) - 1
+}
+
+func (flp *FileLineProvider) Line(index int) string {
+	start, end := flp.offsets[index], flp.offsets[index+1]
+	if end-start > fileLineProviderMaxLineLength {
+		end = start + fileLineProviderMaxLineLength
+	}
+
+	if start < flp.blockOffset || end > flp.blockOffset+int64(len(flp.block)) {
+		size := int64(fileLineProviderBlockSize)
+		if end-start > size {
+			size = end - start
+		}
+
+		if cap(flp.block) < int(size) {
+			flp.block = make([]byte, size)
+		}
+		flp.block = flp.block[:size]
+
+		n, err := flp.file.ReadAt(flp.block, start)
+		if err != nil && err != io.EOF {
+			flp.block = flp.block[:0]
+			return ""
+		}
+
+		flp.block = flp.block[:n]
+		flp.blockOffset = start
+
+		if end > start+int64(n) {
+			end = start + int64(n)
+		}
+	}
+
+	line := flp.block[start-flp.blockOffset : end-flp.blockOffset]
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+
+	return string(line)
+}
+
+// Refresh indexes lines that were appended to the file since it was last
+// indexed, like a log file that is still being written. If the file got
+// shorter, it is indexed anew.
+func (flp *FileLineProvider) Refresh() error {
+	fi, err := flp.file.Stat()
+	if err != nil {
+		return wrapError(err)
+	}
+
+	flp.block = flp.block[:0]
+
+	if fi.Size() < flp.offsets[len(flp.offsets)-1] {
+		flp.offsets = []int64{0}
+
+		err := flp.index()
+
+		flp.PublishLinesReset()
+
+		return err
+	}
+
+	from := flp.LineCount()
+
+	if flp.partial {
+		// The partial line is indexed again, as it may have grown.
+		from--
+		flp.offsets = flp.offsets[:len(flp.offsets)-1]
+		flp.PublishLinesRemoved(from, from)
+	}
+
+	if err := flp.index(); err != nil {
+		return err
+	}
+
+	if to := flp.LineCount() - 1; to >= from {
+		flp.PublishLinesInserted(from, to)
+	}
+
+	return nil
+}
+
+// index adds the lines from the end of the last indexed line to the end of
+// the file.
+func (flp *FileLineProvider) index() error {
+	offset := flp.offsets[len(flp.offsets)-1]
+
+	buf := make([]byte, fileLineProviderBlockSize)
+
+	for {
+		n, err := flp.file.ReadAt(buf, offset)
+
+		for i := 0; i < n; {
+			j := bytes.IndexByte(buf[i:n], '\n')
+			if j < 0 {
+				break
+			}
+
+			i += j + 1
+			flp.offsets = append(flp.offsets, offset+int64(i))
+		}
+
+		offset += int64(n)
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+
+	flp.partial = offset > flp.offsets[len(flp.offsets)-1]
+	if flp.partial {
+		flp.offsets = append(flp.offsets, offset)
+	}
+
+	return nil
+}
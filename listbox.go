@@ -172,6 +172,13 @@ func (lb *ListBox) ApplySysColors() {
 	lb.themeSelectedBGColor = Color(win.GetSysColor(win.COLOR_HIGHLIGHT))
 	lb.themeSelectedTextColor = Color(win.GetSysColor(win.COLOR_HIGHLIGHTTEXT))
 	lb.themeSelectedNotFocusedBGColor = Color(win.GetSysColor(win.COLOR_BTNFACE))
+
+	theme := App().Theme()
+	theme.overrideColor(ThemeColorWindow, &lb.themeNormalBGColor)
+	theme.overrideColor(ThemeColorWindowText, &lb.themeNormalTextColor)
+	theme.overrideColor(ThemeColorHighlight, &lb.themeSelectedBGColor)
+	theme.overrideColor(ThemeColorHighlightText, &lb.themeSelectedTextColor)
+	theme.overrideColor(ThemeColorInactiveHighlight, &lb.themeSelectedNotFocusedBGColor)
 }
 
 func (lb *ListBox) ApplyDPI(dpi int) {
@@ -392,6 +399,10 @@ func (lb *ListBox) SetModel(mdl interface{}) error {
 			return err
 		}
 
+		if obs, ok := mdl.(*ObservableSlice); ok {
+			obs.synchronizer.setWindow(lb)
+		}
+
 		if _, ok := mdl.([]string); !ok {
 			if badms, ok := model.(bindingAndDisplayMemberSetter); ok {
 				badms.setBindingMember(lb.bindingMember)
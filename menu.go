@@ -15,10 +15,11 @@ import (
 )
 
 type Menu struct {
-	hMenu   win.HMENU
-	window  Window
-	actions *ActionList
-	getDPI  func() int
+	hMenu     win.HMENU
+	window    Window
+	actions   *ActionList
+	getDPI    func() int
+	imageKeys map[*Action]iconCacheKey // Of the cached bitmaps of the items.
 }
 
 func newMenuBar(window Window) (*Menu, error) {
@@ -102,6 +103,7 @@ func (m *Menu) updateItemsWithImageForWindow(window Window) {
 func (m *Menu) initMenuItemInfoFromAction(mii *win.MENUITEMINFO, action *Action) {
 	mii.CbSize = uint32(unsafe.Sizeof(*mii))
 	mii.FMask = win.MIIM_FTYPE | win.MIIM_ID | win.MIIM_STATE | win.MIIM_STRING
+	var imageKey iconCacheKey
 	if action.image != nil {
 		mii.FMask |= win.MIIM_BITMAP
 		dpi := 96
@@ -114,8 +116,10 @@ func (m *Menu) initMenuItemInfoFromAction(mii *win.MENUITEMINFO, action *Action)
 		}
 		if bmp, err := iconCache.Bitmap(action.image, dpi); err == nil {
 			mii.HbmpItem = bmp.hBmp
+			imageKey = iconCacheKey{action.image, dpi, false}
 		}
 	}
+	m.setImageKey(action, imageKey)
 	if action.IsSeparator() {
 		mii.FType |= win.MFT_SEPARATOR
 	} else {
@@ -271,6 +275,8 @@ func (m *Menu) removeAction(action *Action, visibleChanged bool) error {
 		return lastError("RemoveMenu")
 	}
 
+	m.setImageKey(action, iconCacheKey{})
+
 	if !visibleChanged {
 		action.removeChangedHandler(m)
 	}
@@ -280,6 +286,22 @@ func (m *Menu) removeAction(action *Action, visibleChanged bool) error {
 	return nil
 }
 
+// setImageKey releases the cached bitmap of the item of action and keeps key
+// to release instead.
+func (m *Menu) setImageKey(action *Action, key iconCacheKey) {
+	iconCache.release(m.imageKeys[action])
+
+	if key.image == nil {
+		delete(m.imageKeys, action)
+		return
+	}
+
+	if m.imageKeys == nil {
+		m.imageKeys = make(map[*Action]iconCacheKey)
+	}
+	m.imageKeys[action] = key
+}
+
 func (m *Menu) ensureMenuBarRedrawn() {
 	if m.window != nil {
 		if mw, ok := m.window.(*MainWindow); ok && mw != nil {
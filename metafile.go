@@ -49,6 +49,8 @@ func NewMetafileFromFile(filePath string) (*Metafile, error) {
 }
 
 func (mf *Metafile) Dispose() {
+	iconCache.Remove(mf)
+
 	mf.ensureFinished()
 
 	if mf.hemf != 0 {
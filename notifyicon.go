@@ -7,12 +7,22 @@
 package walk
 
 import (
+	"math"
 	"syscall"
+	"time"
 	"unsafe"
 
+	"github.com/lxn/walk/balloon"
 	"github.com/lxn/win"
 )
 
+const (
+	// If the shell doesn't report a requested balloon as shown or a shown
+	// one as gone within these durations, we move on to the next message.
+	notifyIconBalloonShowTimeout = 5 * time.Second
+	notifyIconBalloonMaxDuration = time.Minute
+)
+
 var notifyIcons = make(map[*NotifyIcon]bool)
 
 func notifyIconWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (result uintptr) {
@@ -27,6 +37,12 @@ func notifyIconWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (resul
 	case win.WM_LBUTTONUP:
 		ni.publishMouseEvent(&ni.mouseUpPublisher, LeftButton)
 
+	case win.WM_LBUTTONDBLCLK:
+		ni.publishMouseEvent(&ni.doubleClickedPublisher, LeftButton)
+
+	case win.WM_RBUTTONDBLCLK:
+		ni.publishMouseEvent(&ni.doubleClickedPublisher, RightButton)
+
 	case win.WM_RBUTTONDOWN:
 		ni.publishMouseEvent(&ni.mouseDownPublisher, RightButton)
 
@@ -63,14 +79,31 @@ func notifyIconWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) (resul
 		}
 
 		return 0
+
+	case win.NIN_BALLOONSHOW:
+		if ni.balloons.Shown() {
+			ni.startBalloonWatchdog(notifyIconBalloonMaxDuration)
+		}
+
 	case win.NIN_BALLOONUSERCLICK:
-		ni.messageClickedPublisher.Publish()
+		if !ni.closeBalloon(&ni.messageClickedPublisher) {
+			ni.messageClickedPublisher.Publish()
+		}
+
+	case win.NIN_BALLOONTIMEOUT:
+		ni.closeBalloon(&ni.balloonTimeoutPublisher)
+
+	case win.NIN_BALLOONHIDE:
+		ni.closeBalloon(nil)
 	}
 
 	return win.DefWindowProc(hwnd, msg, wParam, lParam)
 }
 
 // NotifyIcon represents an icon in the taskbar notification area.
+//
+// Message balloons are shown one after the other. The icon can be animated,
+// and it is added to the taskbar again if Explorer restarts.
 type NotifyIcon struct {
 	id                      uint32
 	hWnd                    win.HWND
@@ -79,9 +112,27 @@ type NotifyIcon struct {
 	icon                    Image
 	toolTip                 string
 	visible                 bool
+	clock                   Clock
+	balloons                balloon.Queue
+	id2Message              map[balloon.ID]*notifyIconMessage
+	cancelBalloonWatchdog   func()
+	animationFrames         []Image
+	animationFrame          int
+	animationFrameKeys      map[iconCacheKey]bool // Retained in the IconCache
+	animationTimer          *Timer
 	mouseDownPublisher      MouseEventPublisher
 	mouseUpPublisher        MouseEventPublisher
+	doubleClickedPublisher  MouseEventPublisher
 	messageClickedPublisher EventPublisher
+	balloonTimeoutPublisher EventPublisher
+	balloonClosedPublisher  EventPublisher
+}
+
+type notifyIconMessage struct {
+	title    string
+	info     string
+	iconType uint32
+	icon     Image
 }
 
 // NewNotifyIcon creates and returns a new NotifyIcon.
@@ -121,6 +172,8 @@ func NewNotifyIcon(form Form) (*NotifyIcon, error) {
 		id:          nid.UID,
 		hWnd:        fb.hWnd,
 		contextMenu: menu,
+		clock:       defaultClock,
+		id2Message:  make(map[balloon.ID]*notifyIconMessage),
 	}
 
 	menu.getDPI = ni.DPI
@@ -158,15 +211,14 @@ func (ni *NotifyIcon) readdToTaskbar() error {
 		return newError("Shell_NotifyIcon")
 	}
 
-	icon := ni.icon
-	ni.icon = nil
-	err := ni.SetIcon(icon)
-	if err != nil {
-		return err
+	if ni.displayedIcon() != nil {
+		if err := ni.applyDisplayedIcon(); err != nil {
+			return err
+		}
 	}
 	visible := ni.visible
 	ni.visible = false
-	err = ni.SetVisible(visible)
+	err := ni.SetVisible(visible)
 	if err != nil {
 		return err
 	}
@@ -176,7 +228,13 @@ func (ni *NotifyIcon) readdToTaskbar() error {
 	if err != nil {
 		return err
 	}
-	return nil
+
+	// A balloon that was requested or showing went away with the old
+	// taskbar, so we request it again.
+	ni.stopBalloonWatchdog()
+	ni.balloons.Reset()
+
+	return ni.showNextMessage()
 }
 
 func (ni *NotifyIcon) applyDPI() {
@@ -190,10 +248,8 @@ func (ni *NotifyIcon) applyDPI() {
 			ni.contextMenu.onActionChanged(action)
 		}
 	}
-	icon := ni.icon
-	ni.icon = nil
-	if icon != nil {
-		ni.SetIcon(icon)
+	if ni.displayedIcon() != nil {
+		ni.applyDisplayedIcon()
 	}
 }
 
@@ -217,48 +273,143 @@ func (ni *NotifyIcon) Dispose() error {
 	}
 	delete(notifyIcons, ni)
 
+	ni.stopAnimationTimer()
+	ni.stopBalloonWatchdog()
+
 	nid := ni.notifyIconData()
 
 	if !win.Shell_NotifyIcon(win.NIM_DELETE, nid) {
 		return newError("Shell_NotifyIcon")
 	}
 
+	ni.releaseAnimationFrames(nil)
+
 	ni.hWnd = 0
 
 	return nil
 }
 
 func (ni *NotifyIcon) showMessage(title, info string, iconType uint32, icon Image) error {
+	id := ni.balloons.Push()
+	ni.id2Message[id] = &notifyIconMessage{title, info, iconType, icon}
+
+	return ni.showNextMessage()
+}
+
+// showNextMessage hands the next waiting message to the shell, if no balloon
+// is requested or showing. Messages the shell refuses are dropped.
+func (ni *NotifyIcon) showNextMessage() error {
+	var firstErr error
+
+	for {
+		id, ok := ni.balloons.Next()
+		if !ok {
+			return firstErr
+		}
+
+		if err := ni.sendMessage(ni.id2Message[id]); err != nil {
+			ni.balloons.Close()
+			delete(ni.id2Message, id)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		ni.startBalloonWatchdog(notifyIconBalloonShowTimeout)
+
+		return firstErr
+	}
+}
+
+func (ni *NotifyIcon) sendMessage(msg *notifyIconMessage) error {
 	nid := ni.notifyIconData()
 	nid.UFlags = win.NIF_INFO
-	nid.DwInfoFlags = iconType
-	var oldIcon Image
-	if iconType == win.NIIF_USER && icon != nil {
-		oldIcon = ni.icon
-		if err := ni.setNIDIcon(nid, icon); err != nil {
+	nid.DwInfoFlags = msg.iconType
+	var customIcon bool
+	if msg.iconType == win.NIIF_USER && msg.icon != nil {
+		if err := ni.setNIDIcon(nid, msg.icon); err != nil {
 			return err
 		}
 		nid.UFlags |= win.NIF_ICON
+		customIcon = true
 	}
-	if title16, err := syscall.UTF16FromString(title); err == nil {
+	if title16, err := syscall.UTF16FromString(msg.title); err == nil {
 		copy(nid.SzInfoTitle[:], title16)
 	}
-	if info16, err := syscall.UTF16FromString(info); err == nil {
+	if info16, err := syscall.UTF16FromString(msg.info); err == nil {
 		copy(nid.SzInfo[:], info16)
 	}
 	if !win.Shell_NotifyIcon(win.NIM_MODIFY, nid) {
 		return newError("Shell_NotifyIcon")
 	}
-	if oldIcon != nil {
-		ni.icon = nil
-		ni.SetIcon(oldIcon)
+	if customIcon {
+		ni.applyDisplayedIcon()
 	}
 
 	return nil
 }
 
+// closeBalloon records that the current balloon is gone, publishes reason and
+// BalloonClosed and shows the next message. It returns false if there was no
+// current balloon.
+func (ni *NotifyIcon) closeBalloon(reason *EventPublisher) bool {
+	id, ok := ni.balloons.Close()
+	if !ok {
+		return false
+	}
+
+	ni.stopBalloonWatchdog()
+	delete(ni.id2Message, id)
+
+	if reason != nil {
+		reason.Publish()
+	}
+	ni.balloonClosedPublisher.Publish()
+
+	ni.showNextMessage()
+
+	return true
+}
+
+func (ni *NotifyIcon) startBalloonWatchdog(d time.Duration) {
+	ni.stopBalloonWatchdog()
+
+	ni.cancelBalloonWatchdog = ni.clock.AfterFunc(d, func() {
+		ni.cancelBalloonWatchdog = nil
+
+		ni.closeBalloon(nil)
+	})
+}
+
+func (ni *NotifyIcon) stopBalloonWatchdog() {
+	if ni.cancelBalloonWatchdog != nil {
+		ni.cancelBalloonWatchdog()
+		ni.cancelBalloonWatchdog = nil
+	}
+}
+
+// PendingMessages returns the number of messages that wait for the current
+// balloon to go away.
+func (ni *NotifyIcon) PendingMessages() int {
+	return len(ni.balloons.Waiting())
+}
+
+// ClearPendingMessages discards the messages that wait for the current
+// balloon to go away.
+func (ni *NotifyIcon) ClearPendingMessages() {
+	for _, id := range ni.balloons.Waiting() {
+		delete(ni.id2Message, id)
+	}
+
+	ni.balloons.Clear()
+}
+
 // ShowMessage displays a neutral message balloon above the NotifyIcon.
 //
+// If a balloon is already showing, the message is shown after it went away.
+//
 // The NotifyIcon must be visible before calling this method.
 func (ni *NotifyIcon) ShowMessage(title, info string) error {
 	return ni.showMessage(title, info, win.NIIF_NONE, nil)
@@ -266,6 +417,8 @@ func (ni *NotifyIcon) ShowMessage(title, info string) error {
 
 // ShowInfo displays an info message balloon above the NotifyIcon.
 //
+// If a balloon is already showing, the message is shown after it went away.
+//
 // The NotifyIcon must be visible before calling this method.
 func (ni *NotifyIcon) ShowInfo(title, info string) error {
 	return ni.showMessage(title, info, win.NIIF_INFO, nil)
@@ -273,6 +426,8 @@ func (ni *NotifyIcon) ShowInfo(title, info string) error {
 
 // ShowWarning displays a warning message balloon above the NotifyIcon.
 //
+// If a balloon is already showing, the message is shown after it went away.
+//
 // The NotifyIcon must be visible before calling this method.
 func (ni *NotifyIcon) ShowWarning(title, info string) error {
 	return ni.showMessage(title, info, win.NIIF_WARNING, nil)
@@ -280,6 +435,8 @@ func (ni *NotifyIcon) ShowWarning(title, info string) error {
 
 // ShowError displays an error message balloon above the NotifyIcon.
 //
+// If a balloon is already showing, the message is shown after it went away.
+//
 // The NotifyIcon must be visible before calling this method.
 func (ni *NotifyIcon) ShowError(title, info string) error {
 	return ni.showMessage(title, info, win.NIIF_ERROR, nil)
@@ -288,6 +445,8 @@ func (ni *NotifyIcon) ShowError(title, info string) error {
 // ShowCustom displays a custom icon message balloon above the NotifyIcon.
 // If icon is nil, the main notification icon is used instead of a custom one.
 //
+// If a balloon is already showing, the message is shown after it went away.
+//
 // The NotifyIcon must be visible before calling this method.
 func (ni *NotifyIcon) ShowCustom(title, info string, icon Image) error {
 	return ni.showMessage(title, info, win.NIIF_USER, icon)
@@ -304,11 +463,59 @@ func (ni *NotifyIcon) Icon() Image {
 }
 
 // SetIcon sets the Icon of the NotifyIcon.
+//
+// While an icon animation is running, the Icon is shown after it stopped.
 func (ni *NotifyIcon) SetIcon(icon Image) error {
 	if icon == ni.icon {
 		return nil
 	}
 
+	if ni.animationFrames == nil {
+		if err := ni.applyIcon(icon); err != nil {
+			return err
+		}
+	}
+
+	ni.icon = icon
+
+	return nil
+}
+
+// displayedIcon returns the current animation frame or the Icon.
+func (ni *NotifyIcon) displayedIcon() Image {
+	if ni.animationFrames != nil {
+		return ni.animationFrames[ni.animationFrame]
+	}
+
+	return ni.icon
+}
+
+// applyDisplayedIcon applies the current animation frame or the Icon. The
+// icons rendered for animation frames are retained in the IconCache, so they
+// needn't be rendered again each time the animation shows them.
+func (ni *NotifyIcon) applyDisplayedIcon() error {
+	if ni.animationFrames == nil {
+		return ni.applyIcon(ni.icon)
+	}
+
+	frame := ni.animationFrames[ni.animationFrame]
+
+	key := iconCacheKey{frame, ni.DPI(), true}
+	if !ni.animationFrameKeys[key] {
+		if _, err := iconCache.Icon(frame, key.dpi); err != nil {
+			return err
+		}
+
+		if ni.animationFrameKeys == nil {
+			ni.animationFrameKeys = make(map[iconCacheKey]bool)
+		}
+		ni.animationFrameKeys[key] = true
+	}
+
+	return ni.applyIcon(frame)
+}
+
+func (ni *NotifyIcon) applyIcon(icon Image) error {
 	nid := ni.notifyIconData()
 	nid.UFlags = win.NIF_ICON
 	if icon == nil {
@@ -323,14 +530,204 @@ func (ni *NotifyIcon) SetIcon(icon Image) error {
 		return newError("Shell_NotifyIcon")
 	}
 
-	ni.icon = icon
+	return nil
+}
+
+// StartIconAnimation shows frames in turn instead of the Icon, each one for
+// interval, until StopIconAnimation is called. A running animation is
+// replaced.
+//
+// When the animation stops, is replaced or the NotifyIcon is disposed, the
+// icons rendered for frames are released from the IconCache.
+//
+// Use NewSpinningOverlayIconFrames to indicate that something is in progress.
+func (ni *NotifyIcon) StartIconAnimation(frames []Image, interval time.Duration) error {
+	if len(frames) == 0 {
+		return newError("frames must not be empty")
+	}
+	if interval <= 0 {
+		return newError("interval must be positive")
+	}
+
+	ni.stopAnimationTimer()
+
+	ni.releaseAnimationFrames(frames)
+	ni.animationFrames = append([]Image(nil), frames...)
+	ni.animationFrame = 0
+
+	if err := ni.applyDisplayedIcon(); err != nil {
+		ni.releaseAnimationFrames(nil)
+		ni.applyIcon(ni.icon)
+		return err
+	}
+
+	ni.animationTimer = NewTimer(interval)
+	ni.animationTimer.SetClock(ni.clock)
+	ni.animationTimer.Timeout().Attach(func() {
+		ni.animationFrame = (ni.animationFrame + 1) % len(ni.animationFrames)
+
+		ni.applyDisplayedIcon()
+	})
+	ni.animationTimer.Start()
 
 	return nil
 }
 
+// StopIconAnimation stops a running icon animation and shows the Icon again.
+func (ni *NotifyIcon) StopIconAnimation() error {
+	if ni.animationFrames == nil {
+		return nil
+	}
+
+	ni.stopAnimationTimer()
+
+	err := ni.applyIcon(ni.icon)
+
+	ni.releaseAnimationFrames(nil)
+
+	return err
+}
+
+// IconAnimationRunning returns if an icon animation is running.
+func (ni *NotifyIcon) IconAnimationRunning() bool {
+	return ni.animationFrames != nil
+}
+
+// releaseAnimationFrames releases the icons that applyDisplayedIcon retained
+// for the frames of the animation, except for those in keep, and forgets the
+// frames. Others that use the same images keep their icons.
+func (ni *NotifyIcon) releaseAnimationFrames(keep []Image) {
+outer:
+	for key := range ni.animationFrameKeys {
+		for _, k := range keep {
+			if key.image == k {
+				continue outer
+			}
+		}
+
+		iconCache.release(key)
+		delete(ni.animationFrameKeys, key)
+	}
+
+	ni.animationFrames = nil
+}
+
+func (ni *NotifyIcon) stopAnimationTimer() {
+	if ni.animationTimer != nil {
+		ni.animationTimer.Stop()
+		ni.animationTimer = nil
+	}
+}
+
+// Clock returns the Clock that drives icon animations and balloon timeouts.
+func (ni *NotifyIcon) Clock() Clock {
+	return ni.clock
+}
+
+// SetClock sets the Clock that drives icon animations and balloon timeouts.
+// Pass nil for SystemClock.
+func (ni *NotifyIcon) SetClock(clock Clock) {
+	if clock == nil {
+		clock = defaultClock
+	}
+
+	ni.clock = clock
+
+	if ni.animationTimer != nil {
+		ni.animationTimer.SetClock(clock)
+	}
+
+	switch ni.balloons.State() {
+	case balloon.Requested:
+		ni.startBalloonWatchdog(notifyIconBalloonShowTimeout)
+
+	case balloon.Showing:
+		ni.startBalloonWatchdog(notifyIconBalloonMaxDuration)
+	}
+}
+
+// NewSpinningOverlayIconFrames returns count frames for StartIconAnimation,
+// that show icon with a spinner in its lower right corner. If icon is nil,
+// the spinner fills the whole frame.
+func NewSpinningOverlayIconFrames(icon Image, count int) ([]Image, error) {
+	if count < 1 {
+		return nil, newError("count must be positive")
+	}
+
+	const dots = 8
+
+	size := Size{16, 16}
+	if icon != nil {
+		size = icon.Size()
+	}
+
+	frames := make([]Image, count)
+	for i := range frames {
+		head := i * dots / count
+
+		frames[i] = NewPaintFuncImagePixels(size, func(canvas *Canvas, bounds Rectangle) error {
+			spinner := bounds
+			if icon != nil {
+				if err := canvas.DrawImageStretchedPixels(icon, bounds); err != nil {
+					return err
+				}
+
+				spinner.Width = bounds.Width * 5 / 8
+				spinner.Height = bounds.Height * 5 / 8
+				spinner.X = bounds.X + bounds.Width - spinner.Width
+				spinner.Y = bounds.Y + bounds.Height - spinner.Height
+
+				backBrush, err := NewSolidColorBrush(RGB(0xFF, 0xFF, 0xFF))
+				if err != nil {
+					return err
+				}
+				defer backBrush.Dispose()
+
+				if err := canvas.FillEllipsePixels(backBrush, spinner); err != nil {
+					return err
+				}
+			}
+
+			dotSize := spinner.Width / 4
+			if dotSize < 2 {
+				dotSize = 2
+			}
+			cx := float64(spinner.X) + float64(spinner.Width)/2
+			cy := float64(spinner.Y) + float64(spinner.Height)/2
+			r := float64(spinner.Width-dotSize)/2 - 1
+
+			for d := 0; d < dots; d++ {
+				// The head is darkest, the dots behind it fade out.
+				age := (head - d + dots) % dots
+				shade := byte(0x20 + age*0x18)
+
+				brush, err := NewSolidColorBrush(RGB(shade, shade, shade))
+				if err != nil {
+					return err
+				}
+
+				angle := 2 * math.Pi * float64(d) / dots
+				x := int(math.Round(cx + r*math.Sin(angle) - float64(dotSize)/2))
+				y := int(math.Round(cy - r*math.Cos(angle) - float64(dotSize)/2))
+
+				err = canvas.FillEllipsePixels(brush, Rectangle{x, y, dotSize, dotSize})
+				brush.Dispose()
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return frames, nil
+}
+
 func (ni *NotifyIcon) setNIDIcon(nid *win.NOTIFYICONDATA, icon Image) error {
 	dpi := ni.DPI()
-	ic, err := iconCache.Icon(icon, dpi)
+	// The shell copies the icon, so it needn't stay valid.
+	ic, err := iconCache.icon(icon, dpi, false)
 	if err != nil {
 		return err
 	}
@@ -411,8 +808,27 @@ func (ni *NotifyIcon) MouseUp() *MouseEvent {
 	return ni.mouseUpPublisher.Event()
 }
 
+// DoubleClicked returns the event that is published when the NotifyIcon is
+// double clicked.
+func (ni *NotifyIcon) DoubleClicked() *MouseEvent {
+	return ni.doubleClickedPublisher.Event()
+}
+
 // MessageClicked occurs when the user clicks a message shown with ShowMessage or
 // one of its iconed variants.
 func (ni *NotifyIcon) MessageClicked() *Event {
 	return ni.messageClickedPublisher.Event()
 }
+
+// BalloonTimeout returns the event that is published when a message balloon
+// timed out or the user closed it.
+func (ni *NotifyIcon) BalloonTimeout() *Event {
+	return ni.balloonTimeoutPublisher.Event()
+}
+
+// BalloonClosed returns the event that is published when a message balloon
+// went away, for whatever reason. It follows MessageClicked or
+// BalloonTimeout, if one of these applies.
+func (ni *NotifyIcon) BalloonClosed() *Event {
+	return ni.balloonClosedPublisher.Event()
+}
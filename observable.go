@@ -0,0 +1,323 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/lxn/win"
+)
+
+// PropertyChangedNotifier may be implemented by data sources, like the ones of
+// a DataBinder or the items of an ObservableSlice, to report changes of their
+// properties that do not originate from the UI.
+type PropertyChangedNotifier interface {
+	// PropertyChanged returns the event that the data source should publish
+	// when one of its properties changed. The event argument is the name of
+	// the property, or "" if any number of properties may have changed.
+	PropertyChanged() *StringEvent
+}
+
+// PropertyChangedNotifierBase implements the PropertyChangedNotifier
+// interface. Embed it in your data source structs.
+//
+// PublishPropertyChanged may be called from any goroutine. A DataBinder or an
+// ObservableSlice will update its widgets on the UI thread.
+type PropertyChangedNotifierBase struct {
+	propertyChangedPublisher StringEventPublisher
+}
+
+func (pcnb *PropertyChangedNotifierBase) PropertyChanged() *StringEvent {
+	return pcnb.propertyChangedPublisher.Event()
+}
+
+func (pcnb *PropertyChangedNotifierBase) PublishPropertyChanged(name string) {
+	pcnb.propertyChangedPublisher.Publish(name)
+}
+
+// ObservableSlice wraps a slice and publishes an event for each modification
+// made through its methods. Pass it to the SetModel method of a ComboBox,
+// ListBox or TableView to have them update automatically.
+//
+// If the items implement PropertyChangedNotifier, changes of their properties
+// are published as item changes as well. Like with a DataBinder, those
+// changes may be published from any goroutine; the ItemChanged event is
+// published on the UI thread of the widget the slice is the model of.
+//
+// The methods of ObservableSlice itself are not safe for concurrent use. Call
+// them from the UI thread only, e.g. by means of Synchronize.
+type ObservableSlice struct {
+	value                  reflect.Value
+	synchronizer           windowSynchronizer
+	item2ChangedHandle     map[interface{}]int
+	itemsResetPublisher    EventPublisher
+	itemChangedPublisher   IntEventPublisher
+	itemsInsertedPublisher IntRangeEventPublisher
+	itemsRemovedPublisher  IntRangeEventPublisher
+}
+
+// NewObservableSlice returns a new ObservableSlice that initially holds the
+// elements of items, which must be a slice.
+func NewObservableSlice(items interface{}) (*ObservableSlice, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return nil, newError("items must be a slice")
+	}
+
+	obs := &ObservableSlice{
+		value:              reflect.New(v.Type()).Elem(),
+		item2ChangedHandle: make(map[interface{}]int),
+	}
+
+	obs.value.Set(reflect.AppendSlice(obs.value, v))
+
+	obs.attachItems(0, obs.value.Len()-1)
+
+	return obs, nil
+}
+
+// Items returns the current slice. Do not modify it directly, or the
+// modification will go unnoticed.
+func (obs *ObservableSlice) Items() interface{} {
+	return obs.value.Interface()
+}
+
+// Len returns the number of items.
+func (obs *ObservableSlice) Len() int {
+	return obs.value.Len()
+}
+
+// At returns the item at index.
+func (obs *ObservableSlice) At(index int) interface{} {
+	return obs.value.Index(index).Interface()
+}
+
+// Append adds items to the end of the slice.
+func (obs *ObservableSlice) Append(items ...interface{}) error {
+	return obs.Insert(obs.value.Len(), items...)
+}
+
+// Insert inserts items at index.
+func (obs *ObservableSlice) Insert(index int, items ...interface{}) error {
+	if index < 0 || index > obs.value.Len() {
+		return newError("index out of range")
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	values := reflect.MakeSlice(obs.value.Type(), len(items), len(items))
+	for i, item := range items {
+		v, err := obs.itemValue(item)
+		if err != nil {
+			return err
+		}
+
+		values.Index(i).Set(v)
+	}
+
+	tail := reflect.AppendSlice(values, obs.value.Slice(index, obs.value.Len()))
+	obs.value.Set(reflect.AppendSlice(obs.value.Slice(0, index), tail))
+
+	from, to := index, index+len(items)-1
+
+	obs.attachItems(from, to)
+
+	obs.itemsInsertedPublisher.Publish(from, to)
+
+	return nil
+}
+
+// RemoveAt removes the item at index.
+func (obs *ObservableSlice) RemoveAt(index int) error {
+	return obs.RemoveRange(index, index)
+}
+
+// RemoveRange removes the items from index from to index to, inclusive.
+func (obs *ObservableSlice) RemoveRange(from, to int) error {
+	if from < 0 || to >= obs.value.Len() || from > to {
+		return newError("index out of range")
+	}
+
+	removed := make([]interface{}, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		removed = append(removed, obs.value.Index(i).Interface())
+	}
+
+	n := obs.value.Len()
+	reflect.Copy(obs.value.Slice(from, n), obs.value.Slice(to+1, n))
+	for i := n - (to - from + 1); i < n; i++ {
+		// Don't keep removed items alive.
+		obs.value.Index(i).Set(reflect.Zero(obs.value.Type().Elem()))
+	}
+	obs.value.SetLen(n - (to - from + 1))
+
+	for _, item := range removed {
+		obs.detachItem(item)
+	}
+
+	obs.itemsRemovedPublisher.Publish(from, to)
+
+	return nil
+}
+
+// Set replaces the item at index.
+func (obs *ObservableSlice) Set(index int, item interface{}) error {
+	if index < 0 || index >= obs.value.Len() {
+		return newError("index out of range")
+	}
+
+	v, err := obs.itemValue(item)
+	if err != nil {
+		return err
+	}
+
+	old := obs.value.Index(index).Interface()
+	obs.value.Index(index).Set(v)
+
+	obs.detachItem(old)
+	obs.attachItems(index, index)
+
+	obs.itemChangedPublisher.Publish(index)
+
+	return nil
+}
+
+// SetItems replaces all items with the elements of items, which must be a
+// slice of the same type as the one passed to NewObservableSlice.
+func (obs *ObservableSlice) SetItems(items interface{}) error {
+	v := reflect.ValueOf(items)
+	if v.Type() != obs.value.Type() {
+		return newError("items has wrong type")
+	}
+
+	for item, handle := range obs.item2ChangedHandle {
+		item.(PropertyChangedNotifier).PropertyChanged().Detach(handle)
+	}
+	obs.item2ChangedHandle = make(map[interface{}]int)
+
+	obs.value.Set(reflect.AppendSlice(reflect.MakeSlice(v.Type(), 0, v.Len()), v))
+
+	obs.attachItems(0, obs.value.Len()-1)
+
+	obs.itemsResetPublisher.Publish()
+
+	return nil
+}
+
+// PublishItemChanged publishes the ItemChanged event for the item at index.
+// Call it after modifying an item that does not implement
+// PropertyChangedNotifier.
+func (obs *ObservableSlice) PublishItemChanged(index int) {
+	obs.itemChangedPublisher.Publish(index)
+}
+
+func (obs *ObservableSlice) ItemsReset() *Event {
+	return obs.itemsResetPublisher.Event()
+}
+
+func (obs *ObservableSlice) ItemChanged() *IntEvent {
+	return obs.itemChangedPublisher.Event()
+}
+
+func (obs *ObservableSlice) ItemsInserted() *IntRangeEvent {
+	return obs.itemsInsertedPublisher.Event()
+}
+
+func (obs *ObservableSlice) ItemsRemoved() *IntRangeEvent {
+	return obs.itemsRemovedPublisher.Event()
+}
+
+func (obs *ObservableSlice) itemValue(item interface{}) (reflect.Value, error) {
+	elemType := obs.value.Type().Elem()
+
+	if item == nil {
+		return reflect.Zero(elemType), nil
+	}
+
+	v := reflect.ValueOf(item)
+	if !v.Type().AssignableTo(elemType) {
+		return reflect.Value{}, newError("item has wrong type")
+	}
+
+	return v, nil
+}
+
+func (obs *ObservableSlice) attachItems(from, to int) {
+	for i := from; i <= to; i++ {
+		item := obs.value.Index(i).Interface()
+
+		notifier, ok := item.(PropertyChangedNotifier)
+		if !ok || !reflect.TypeOf(item).Comparable() {
+			continue
+		}
+		if _, ok := obs.item2ChangedHandle[item]; ok {
+			continue
+		}
+
+		obs.item2ChangedHandle[item] = notifier.PropertyChanged().Attach(func(string) {
+			obs.synchronizer.synchronize(func() {
+				// The item may have been removed in the meantime.
+				if index := obs.indexOf(item); index > -1 {
+					obs.itemChangedPublisher.Publish(index)
+				}
+			})
+		})
+	}
+}
+
+func (obs *ObservableSlice) detachItem(item interface{}) {
+	handle, ok := obs.item2ChangedHandle[item]
+	if !ok || obs.indexOf(item) > -1 {
+		return
+	}
+
+	item.(PropertyChangedNotifier).PropertyChanged().Detach(handle)
+	delete(obs.item2ChangedHandle, item)
+}
+
+func (obs *ObservableSlice) indexOf(item interface{}) int {
+	for i, n := 0, obs.value.Len(); i < n; i++ {
+		if obs.value.Index(i).Interface() == item {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// windowSynchronizer calls functions on the UI thread of a window, so that
+// changes published from other goroutines update widgets safely.
+type windowSynchronizer struct {
+	mutex  sync.Mutex
+	window Window
+}
+
+func (ws *windowSynchronizer) setWindow(window Window) {
+	ws.mutex.Lock()
+	ws.window = window
+	ws.mutex.Unlock()
+}
+
+// synchronize calls f right away if there is no window or the current thread
+// is the one of the window, or else by means of the Synchronize method of the
+// window.
+func (ws *windowSynchronizer) synchronize(f func()) {
+	ws.mutex.Lock()
+	window := ws.window
+	ws.mutex.Unlock()
+
+	if window != nil {
+		if wb := window.AsWindowBase(); wb.group != nil && wb.group.ThreadID() != win.GetCurrentThreadId() {
+			window.Synchronize(f)
+			return
+		}
+	}
+
+	f()
+}
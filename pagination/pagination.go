@@ -0,0 +1,184 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pagination splits content into pages, for printing.
+//
+// It knows nothing about devices or fonts. Heights and widths are in whatever
+// unit the caller measures in, so the logic can be tested on any platform.
+package pagination
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Page is the range of items from Start up to, but not including, End that
+// go on one page.
+type Page struct {
+	Start, End int
+}
+
+// Len returns the number of items on the Page.
+func (p Page) Len() int {
+	return p.End - p.Start
+}
+
+// Rows splits rows of the given heights into pages that are pageHeight high,
+// with a header of headerHeight repeated at the top of each page.
+//
+// A row that doesn't fit on an otherwise empty page gets a page of its own,
+// where it will be clipped. Without rows, there is a single empty page, so
+// the header still gets printed.
+func Rows(heights []int, pageHeight, headerHeight int) []Page {
+	if len(heights) == 0 {
+		return []Page{{}}
+	}
+
+	available := pageHeight - headerHeight
+
+	var pages []Page
+	page := Page{}
+	used := 0
+
+	for i, h := range heights {
+		if page.Len() > 0 && used+h > available {
+			pages = append(pages, page)
+			page = Page{Start: i, End: i}
+			used = 0
+		}
+
+		page.End = i + 1
+		used += h
+	}
+
+	return append(pages, page)
+}
+
+// Lines splits count lines that are lineHeight high into pages that are
+// pageHeight high. Each page holds at least one line.
+func Lines(count, lineHeight, pageHeight int) []Page {
+	if count <= 0 {
+		return []Page{{}}
+	}
+
+	perPage := 1
+	if lineHeight > 0 && pageHeight/lineHeight > 1 {
+		perPage = pageHeight / lineHeight
+	}
+
+	pages := make([]Page, 0, (count+perPage-1)/perPage)
+	for start := 0; start < count; start += perPage {
+		end := start + perPage
+		if end > count {
+			end = count
+		}
+
+		pages = append(pages, Page{start, end})
+	}
+
+	return pages
+}
+
+// Wrap breaks text into lines that measure returns a width of at most width
+// for.
+//
+// Lines are broken at line breaks and spaces. Words that are too wide on
+// their own are broken between characters. Each line holds at least one
+// character, however narrow width is.
+func Wrap(text string, width int, measure func(s string) int) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var lines []string
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		if paragraph == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.SplitAfter(paragraph, " ") {
+			if candidate := line + word; measure(strings.TrimRight(candidate, " ")) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, strings.TrimRight(line, " "))
+				line = ""
+			}
+
+			for measure(strings.TrimRight(word, " ")) > width {
+				n := fitting(word, width, measure)
+				if n >= len(strings.TrimRight(word, " ")) {
+					// The last character starts the next line, however wide.
+					break
+				}
+
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+
+			line = word
+		}
+
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	return lines
+}
+
+// fitting returns the byte length of the longest prefix of s, of at least one
+// character, that fits into width.
+func fitting(s string, width int, measure func(s string) int) int {
+	_, n := utf8.DecodeRuneInString(s)
+
+	for n < len(s) {
+		_, size := utf8.DecodeRuneInString(s[n:])
+		if measure(s[:n+size]) > width {
+			break
+		}
+
+		n += size
+	}
+
+	return n
+}
+
+// Range is a range of page numbers From to To, including both and starting at
+// 1, like print dialogs show them.
+type Range struct {
+	From, To int
+}
+
+// Select returns the indices, starting at 0, of the pages out of pageCount
+// that ranges cover, in the order of ranges. Pages outside of pageCount are
+// skipped. Without ranges, all pages are selected.
+func Select(pageCount int, ranges []Range) []int {
+	var indices []int
+
+	if len(ranges) == 0 {
+		for i := 0; i < pageCount; i++ {
+			indices = append(indices, i)
+		}
+
+		return indices
+	}
+
+	for _, r := range ranges {
+		from, to := r.From, r.To
+		if from < 1 {
+			from = 1
+		}
+		if to > pageCount {
+			to = pageCount
+		}
+
+		for page := from; page <= to; page++ {
+			indices = append(indices, page-1)
+		}
+	}
+
+	return indices
+}
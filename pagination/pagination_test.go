@@ -0,0 +1,112 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pagination
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRows(t *testing.T) {
+	tests := []struct {
+		name                     string
+		heights                  []int
+		pageHeight, headerHeight int
+		want                     []Page
+	}{
+		{"no rows", nil, 100, 10, []Page{{0, 0}}},
+		{"one page", []int{10, 20, 30}, 100, 10, []Page{{0, 3}}},
+		{"exact fit", []int{30, 30, 30, 30}, 100, 10, []Page{{0, 3}, {3, 4}}},
+		{"header repeated", []int{40, 40, 40, 40}, 100, 20, []Page{{0, 2}, {2, 4}}},
+		{"too high row", []int{10, 200, 10}, 100, 10, []Page{{0, 1}, {1, 2}, {2, 3}}},
+		{"too high first row", []int{200, 10}, 100, 10, []Page{{0, 1}, {1, 2}}},
+		{"header higher than page", []int{10, 10}, 10, 20, []Page{{0, 1}, {1, 2}}},
+	}
+
+	for _, test := range tests {
+		if got := Rows(test.heights, test.pageHeight, test.headerHeight); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		count, lineHeight, pageHeight int
+		want                          []Page
+	}{
+		{0, 10, 100, []Page{{0, 0}}},
+		{-1, 10, 100, []Page{{0, 0}}},
+		{5, 10, 100, []Page{{0, 5}}},
+		{25, 10, 100, []Page{{0, 10}, {10, 20}, {20, 25}}},
+		{20, 10, 105, []Page{{0, 10}, {10, 20}}},
+		{3, 10, 5, []Page{{0, 1}, {1, 2}, {2, 3}}},
+		{2, 0, 100, []Page{{0, 1}, {1, 2}}},
+	}
+
+	for _, test := range tests {
+		if got := Lines(test.count, test.lineHeight, test.pageHeight); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Lines(%d, %d, %d): got %v, want %v", test.count, test.lineHeight, test.pageHeight, got, test.want)
+		}
+	}
+
+	if n := (Page{3, 7}).Len(); n != 4 {
+		t.Errorf("got length %d, want 4", n)
+	}
+}
+
+// runeWidth measures one unit per character.
+func runeWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 10, []string{""}},
+		{"short", 10, []string{"short"}},
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"the quick brown fox", 9, []string{"the quick", "brown fox"}},
+		{"the  quick   ", 10, []string{"the  quick"}},
+		{"the  quick   ", 9, []string{"the", "quick"}},
+		{"one\ntwo\r\n\nthree", 10, []string{"one", "two", "", "three"}},
+		{"abcdefghij klm", 4, []string{"abcd", "efgh", "ij", "klm"}},
+		{"a abcdefgh", 4, []string{"a", "abcd", "efgh"}},
+		{"äöü€ß", 2, []string{"äö", "ü€", "ß"}},
+		{"wide", 0, []string{"w", "i", "d", "e"}},
+		{"wide x", 0, []string{"w", "i", "d", "e", "x"}},
+	}
+
+	for _, test := range tests {
+		if got := Wrap(test.text, test.width, runeWidth); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Wrap(%q, %d): got %q, want %q", test.text, test.width, got, test.want)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		pageCount int
+		ranges    []Range
+		want      []int
+	}{
+		{3, nil, []int{0, 1, 2}},
+		{0, nil, nil},
+		{10, []Range{{2, 4}}, []int{1, 2, 3}},
+		{10, []Range{{8, 9}, {1, 1}}, []int{7, 8, 0}},
+		{5, []Range{{0, 2}, {4, 99}}, []int{0, 1, 3, 4}},
+		{5, []Range{{7, 9}, {3, 2}}, nil},
+	}
+
+	for _, test := range tests {
+		if got := Select(test.pageCount, test.ranges); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Select(%d, %v): got %v, want %v", test.pageCount, test.ranges, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"github.com/lxn/walk/pagination"
+)
+
+const textMeasureFormat = TextSingleLine | TextNoPrefix | TextExpandTabs
+
+// measureTextWidth returns the width of text in native pixels of canvas.
+func measureTextWidth(canvas *Canvas, text string, font *Font) int {
+	bounds, _, err := canvas.MeasureTextPixels(text, font, Rectangle{Width: 1 << 20, Height: 1 << 20}, textMeasureFormat|TextCalcRect)
+	if err != nil {
+		return 0
+	}
+
+	return bounds.Width
+}
+
+type textPrintable struct {
+	text       string
+	font       *Font
+	lines      []string
+	lineHeight int
+	pages      []pagination.Page
+}
+
+// NewTextPrintable returns a Printable that prints text with font, wrapping
+// lines at the width of the page.
+func NewTextPrintable(text string, font *Font) Printable {
+	if font == nil {
+		font = defaultFont
+	}
+
+	return &textPrintable{text: text, font: font}
+}
+
+// NewTextEditPrintable returns a Printable that prints the text of te with its
+// font.
+func NewTextEditPrintable(te *TextEdit) Printable {
+	return NewTextPrintable(te.Text(), te.Font())
+}
+
+func (tp *textPrintable) Paginate(canvas *Canvas, layout PageLayout) (int, error) {
+	lineHeight, err := canvas.fontHeight(tp.font)
+	if err != nil {
+		return 0, err
+	}
+
+	tp.lineHeight = lineHeight
+	tp.lines = pagination.Wrap(tp.text, layout.Bounds.Width, func(s string) int {
+		return measureTextWidth(canvas, s, tp.font)
+	})
+	tp.pages = pagination.Lines(len(tp.lines), lineHeight, layout.Bounds.Height)
+
+	return len(tp.pages), nil
+}
+
+func (tp *textPrintable) PrintPage(canvas *Canvas, layout PageLayout, index int) error {
+	page := tp.pages[index]
+	bounds := layout.Bounds
+
+	for i, line := range tp.lines[page.Start:page.End] {
+		lineBounds := Rectangle{bounds.X, bounds.Y + i*tp.lineHeight, bounds.Width, tp.lineHeight}
+
+		if err := canvas.DrawTextPixels(line, tp.font, RGB(0, 0, 0), lineBounds, textMeasureFormat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type tableViewPrintable struct {
+	tv           *TableView
+	font         *Font
+	columns      []*TableViewColumn
+	widths       []int
+	rowHeight    int
+	headerHeight int
+	padding      int
+	pages        []pagination.Page
+}
+
+// NewTableViewPrintable returns a Printable that prints the visible columns of
+// tv in display order, with their titles repeated at the top of each page.
+//
+// The columns keep the proportions they have in tv. They shrink to fit the
+// width of the page, if necessary.
+func NewTableViewPrintable(tv *TableView) Printable {
+	return &tableViewPrintable{tv: tv, font: tv.Font()}
+}
+
+func (tvp *tableViewPrintable) Paginate(canvas *Canvas, layout PageLayout) (int, error) {
+	lineHeight, err := canvas.fontHeight(tvp.font)
+	if err != nil {
+		return 0, err
+	}
+
+	tvp.padding = lineHeight / 4
+	tvp.rowHeight = lineHeight + 2*tvp.padding
+	tvp.headerHeight = tvp.rowHeight
+
+	tvp.columns = tvp.tv.VisibleColumnsInDisplayOrder()
+	tvp.widths = make([]int, len(tvp.columns))
+
+	var total int
+	for i, col := range tvp.columns {
+		tvp.widths[i] = IntFrom96DPI(col.Width(), layout.DPI)
+		total += tvp.widths[i]
+	}
+	if total > layout.Bounds.Width {
+		for i := range tvp.widths {
+			tvp.widths[i] = tvp.widths[i] * layout.Bounds.Width / total
+		}
+	}
+
+	var rowCount int
+	if model := tvp.tv.TableModel(); model != nil {
+		rowCount = model.RowCount()
+	}
+
+	heights := make([]int, rowCount)
+	for i := range heights {
+		heights[i] = tvp.rowHeight
+	}
+
+	tvp.pages = pagination.Rows(heights, layout.Bounds.Height, tvp.headerHeight)
+
+	return len(tvp.pages), nil
+}
+
+func (tvp *tableViewPrintable) PrintPage(canvas *Canvas, layout PageLayout, index int) error {
+	bounds := layout.Bounds
+
+	var width int
+	for _, w := range tvp.widths {
+		width += w
+	}
+
+	headerBrush, err := NewSolidColorBrush(RGB(0xE8, 0xE8, 0xE8))
+	if err != nil {
+		return err
+	}
+	defer headerBrush.Dispose()
+
+	gridBrush, err := NewSolidColorBrush(RGB(0xC0, 0xC0, 0xC0))
+	if err != nil {
+		return err
+	}
+	defer gridBrush.Dispose()
+
+	gridPen, err := NewGeometricPen(PenSolid|PenCapFlat, 1, gridBrush)
+	if err != nil {
+		return err
+	}
+	defer gridPen.Dispose()
+
+	if err := canvas.FillRectanglePixels(headerBrush, Rectangle{bounds.X, bounds.Y, width, tvp.headerHeight}); err != nil {
+		return err
+	}
+
+	drawRow := func(y int, text func(col *TableViewColumn) string) error {
+		x := bounds.X
+
+		for i, col := range tvp.columns {
+			format := textMeasureFormat | TextVCenter | TextEndEllipsis
+			switch col.Alignment() {
+			case AlignCenter:
+				format |= TextCenter
+
+			case AlignFar:
+				format |= TextRight
+			}
+
+			cellBounds := Rectangle{x + tvp.padding, y, tvp.widths[i] - 2*tvp.padding, tvp.rowHeight}
+			if err := canvas.DrawTextPixels(text(col), tvp.font, RGB(0, 0, 0), cellBounds, format); err != nil {
+				return err
+			}
+
+			x += tvp.widths[i]
+		}
+
+		return canvas.DrawLinePixels(gridPen, Point{bounds.X, y + tvp.rowHeight}, Point{bounds.X + width, y + tvp.rowHeight})
+	}
+
+	if err := drawRow(bounds.Y, func(col *TableViewColumn) string {
+		return col.TitleEffective()
+	}); err != nil {
+		return err
+	}
+
+	page := tvp.pages[index]
+	y := bounds.Y + tvp.headerHeight
+
+	for row := page.Start; row < page.End; row++ {
+		if err := drawRow(y, func(col *TableViewColumn) string {
+			return tvp.tv.cellText(row, tvp.tv.columns.Index(col))
+		}); err != nil {
+			return err
+		}
+
+		y += tvp.rowHeight
+	}
+
+	return nil
+}
@@ -0,0 +1,571 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"golang.org/x/sys/windows"
+
+	"github.com/lxn/walk/pagination"
+)
+
+var (
+	libwinspool            = windows.NewLazySystemDLL("winspool.drv")
+	procGetDefaultPrinter  = libwinspool.NewProc("GetDefaultPrinterW")
+	procOpenPrinter        = libwinspool.NewProc("OpenPrinterW")
+	procClosePrinter       = libwinspool.NewProc("ClosePrinter")
+	procDocumentProperties = libwinspool.NewProc("DocumentPropertiesW")
+
+	libcomdlg32      = windows.NewLazySystemDLL("comdlg32.dll")
+	procPageSetupDlg = libcomdlg32.NewProc("PageSetupDlgW")
+)
+
+// Printer is a printer, together with the settings to print with, like the
+// paper size, the orientation and the number of copies.
+type Printer struct {
+	name    string
+	devMode []byte // A DEVMODE, followed by data specific to the driver.
+}
+
+// DefaultPrinter returns the default printer of the user, with its default
+// settings.
+func DefaultPrinter() (*Printer, error) {
+	var size uint32
+	procGetDefaultPrinter.Call(0, uintptr(unsafe.Pointer(&size)))
+	if size == 0 {
+		return nil, newError("no default printer")
+	}
+
+	buf := make([]uint16, size)
+	if ret, _, _ := procGetDefaultPrinter.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size))); ret == 0 {
+		return nil, lastError("GetDefaultPrinter")
+	}
+
+	return NewPrinter(syscall.UTF16ToString(buf))
+}
+
+// NewPrinter returns the printer named name, with its default settings.
+func NewPrinter(name string) (*Printer, error) {
+	name16, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var hPrinter uintptr
+	if ret, _, _ := procOpenPrinter.Call(uintptr(unsafe.Pointer(name16)), uintptr(unsafe.Pointer(&hPrinter)), 0); ret == 0 {
+		return nil, lastError("OpenPrinter")
+	}
+	defer procClosePrinter.Call(hPrinter)
+
+	size, _, _ := procDocumentProperties.Call(0, hPrinter, uintptr(unsafe.Pointer(name16)), 0, 0, 0)
+	if int32(size) <= 0 {
+		return nil, newError("DocumentProperties failed")
+	}
+
+	devMode := make([]byte, size)
+	if ret, _, _ := procDocumentProperties.Call(
+		0,
+		hPrinter,
+		uintptr(unsafe.Pointer(name16)),
+		uintptr(unsafe.Pointer(&devMode[0])),
+		0,
+		win.DM_OUT_BUFFER); int32(ret) < 0 {
+		return nil, newError("DocumentProperties failed")
+	}
+
+	return &Printer{name: name, devMode: devMode}, nil
+}
+
+// Name returns the name of the Printer.
+func (p *Printer) Name() string {
+	return p.name
+}
+
+func (p *Printer) devModePtr() *win.DEVMODE {
+	if len(p.devMode) == 0 {
+		return nil
+	}
+
+	return (*win.DEVMODE)(unsafe.Pointer(&p.devMode[0]))
+}
+
+// Copies returns the number of copies to print.
+func (p *Printer) Copies() int {
+	if dm := p.devModePtr(); dm != nil && dm.DmFields&win.DM_COPIES != 0 && dm.DmCopies > 0 {
+		return int(dm.DmCopies)
+	}
+
+	return 1
+}
+
+// SetCopies sets the number of copies to print.
+func (p *Printer) SetCopies(copies int) {
+	if dm := p.devModePtr(); dm != nil {
+		dm.DmFields |= win.DM_COPIES
+		dm.DmCopies = int16(maxi(1, copies))
+	}
+}
+
+// Landscape returns if pages are printed in landscape orientation.
+func (p *Printer) Landscape() bool {
+	dm := p.devModePtr()
+
+	return dm != nil && dm.DmFields&win.DM_ORIENTATION != 0 && dm.DmOrientation == win.DMORIENT_LANDSCAPE
+}
+
+// SetLandscape sets if pages are printed in landscape orientation.
+func (p *Printer) SetLandscape(landscape bool) {
+	if dm := p.devModePtr(); dm != nil {
+		dm.DmFields |= win.DM_ORIENTATION
+		if landscape {
+			dm.DmOrientation = win.DMORIENT_LANDSCAPE
+		} else {
+			dm.DmOrientation = win.DMORIENT_PORTRAIT
+		}
+	}
+}
+
+// PaperSize returns the size of the paper, in 1/96" units.
+func (p *Printer) PaperSize() (Size, error) {
+	hdc := p.createIC()
+	if hdc == 0 {
+		return Size{}, newError("CreateIC failed")
+	}
+	defer win.DeleteDC(hdc)
+
+	m := pageMetricsForHDC(hdc)
+
+	return Size{
+		IntTo96DPI(m.paper.Width, m.dpi.Width),
+		IntTo96DPI(m.paper.Height, m.dpi.Height),
+	}, nil
+}
+
+// createIC returns an information context for the Printer, that can measure
+// but not print.
+func (p *Printer) createIC() win.HDC {
+	return win.CreateIC(nil, syscall.StringToUTF16Ptr(p.name), nil, p.devModePtr())
+}
+
+func (p *Printer) createDC() win.HDC {
+	return win.CreateDC(nil, syscall.StringToUTF16Ptr(p.name), nil, p.devModePtr())
+}
+
+// globalDevMode returns a copy of the DEVMODE of the Printer in global memory,
+// as common dialogs expect it.
+func (p *Printer) globalDevMode() win.HGLOBAL {
+	if len(p.devMode) == 0 {
+		return 0
+	}
+
+	return globalAllocBytes(p.devMode)
+}
+
+// globalDevNames returns a DEVNAMES for the Printer in global memory, as
+// common dialogs expect it.
+func (p *Printer) globalDevNames() win.HGLOBAL {
+	name := syscall.StringToUTF16(p.name)
+
+	// The DEVNAMES header is followed by the empty driver name, the device
+	// name and the empty output port name. Offsets are in characters.
+	buf := make([]uint16, 4+1+len(name)+1)
+	buf[0] = 4
+	buf[1] = 5
+	buf[2] = uint16(5 + len(name))
+	copy(buf[5:], name)
+
+	return globalAllocBytes((*[1 << 30]byte)(unsafe.Pointer(&buf[0]))[: len(buf)*2 : len(buf)*2])
+}
+
+// printerFromGlobals returns the Printer that common dialogs return in
+// hDevNames and hDevMode.
+func printerFromGlobals(hDevNames, hDevMode win.HGLOBAL) *Printer {
+	if hDevNames == 0 {
+		return nil
+	}
+
+	p := new(Printer)
+
+	if names := win.GlobalLock(hDevNames); names != nil {
+		dn := (*win.DEVNAMES)(names)
+		p.name = win.UTF16PtrToString((*uint16)(unsafe.Pointer(uintptr(names) + uintptr(dn.WDeviceOffset)*2)))
+		win.GlobalUnlock(hDevNames)
+	}
+
+	if hDevMode != 0 {
+		if mode := win.GlobalLock(hDevMode); mode != nil {
+			dm := (*win.DEVMODE)(mode)
+			size := int(dm.DmSize) + int(dm.DmDriverExtra)
+			p.devMode = append([]byte(nil), (*[1 << 30]byte)(mode)[:size:size]...)
+			win.GlobalUnlock(hDevMode)
+		}
+	}
+
+	return p
+}
+
+func globalAllocBytes(data []byte) win.HGLOBAL {
+	hMem := win.GlobalAlloc(win.GMEM_MOVEABLE, uintptr(len(data)))
+	if hMem == 0 {
+		return 0
+	}
+
+	dst := win.GlobalLock(hMem)
+	if dst == nil {
+		win.GlobalFree(hMem)
+		return 0
+	}
+	copy((*[1 << 30]byte)(dst)[:len(data):len(data)], data)
+	win.GlobalUnlock(hMem)
+
+	return hMem
+}
+
+// PageLayout describes the pages that a Printable is printed on, in native
+// pixels of the Canvas it is printed with.
+type PageLayout struct {
+	// Paper is the whole sheet of paper. Its origin is negative if the
+	// printer can't print to the edge of the paper, because the Canvas
+	// starts where the printable area does.
+	Paper Rectangle
+
+	// Bounds is the area inside the margins, that content should go into.
+	Bounds Rectangle
+
+	// DPI is the resolution of the Canvas.
+	DPI int
+}
+
+// Printable is the interface that documents must implement to be printed by a
+// PrintJob or shown by a PrintPreview.
+type Printable interface {
+	// Paginate lays out the document on pages with the given layout and
+	// returns the number of pages. canvas can be used to measure text, but
+	// not to draw.
+	Paginate(canvas *Canvas, layout PageLayout) (pageCount int, err error)
+
+	// PrintPage draws the page with index, starting at 0, into
+	// layout.Bounds of canvas. layout is the one last passed to Paginate.
+	PrintPage(canvas *Canvas, layout PageLayout, index int) error
+}
+
+// pageMetrics are the dimensions of the pages of a device, in its native
+// pixels.
+type pageMetrics struct {
+	dpi       Size
+	paper     Size
+	printable Rectangle // Relative to the paper.
+}
+
+func pageMetricsForHDC(hdc win.HDC) pageMetrics {
+	m := pageMetrics{
+		dpi: Size{
+			int(win.GetDeviceCaps(hdc, win.LOGPIXELSX)),
+			int(win.GetDeviceCaps(hdc, win.LOGPIXELSY)),
+		},
+		paper: Size{
+			int(win.GetDeviceCaps(hdc, win.PHYSICALWIDTH)),
+			int(win.GetDeviceCaps(hdc, win.PHYSICALHEIGHT)),
+		},
+		printable: Rectangle{
+			int(win.GetDeviceCaps(hdc, win.PHYSICALOFFSETX)),
+			int(win.GetDeviceCaps(hdc, win.PHYSICALOFFSETY)),
+			int(win.GetDeviceCaps(hdc, win.HORZRES)),
+			int(win.GetDeviceCaps(hdc, win.VERTRES)),
+		},
+	}
+
+	if m.paper.Width == 0 || m.paper.Height == 0 {
+		// Not a printer, so assume US Letter without unprintable border.
+		m.paper = Size{m.dpi.Width * 17 / 2, m.dpi.Height * 11}
+		m.printable = Rectangle{0, 0, m.paper.Width, m.paper.Height}
+	}
+
+	return m
+}
+
+// layout returns the PageLayout for margins in 1/96" units, for a Canvas
+// whose origin is at origin on the paper. Margins are enlarged to the
+// printable area.
+func (m pageMetrics) layout(margins Margins, origin Point) PageLayout {
+	left := maxi(IntFrom96DPI(margins.HNear, m.dpi.Width), m.printable.X)
+	top := maxi(IntFrom96DPI(margins.VNear, m.dpi.Height), m.printable.Y)
+	right := mini(m.paper.Width-IntFrom96DPI(margins.HFar, m.dpi.Width), m.printable.Right())
+	bottom := mini(m.paper.Height-IntFrom96DPI(margins.VFar, m.dpi.Height), m.printable.Bottom())
+
+	return PageLayout{
+		Paper:  Rectangle{-origin.X, -origin.Y, m.paper.Width, m.paper.Height},
+		Bounds: Rectangle{left - origin.X, top - origin.Y, maxi(0, right-left), maxi(0, bottom-top)},
+		DPI:    m.dpi.Width,
+	}
+}
+
+// PrintJob prints a Printable.
+type PrintJob struct {
+	// Printer is the printer to print with. If it is nil, the default
+	// printer is used.
+	Printer *Printer
+
+	// DocumentName is the name of the job in the print queue.
+	DocumentName string
+
+	// Margins are the margins of the pages in 1/96" units. Margins smaller
+	// than the unprintable border of the printer are enlarged to it.
+	Margins Margins
+
+	// PageRanges are the pages to print. If there are none, all pages are
+	// printed.
+	PageRanges []pagination.Range
+}
+
+// Print prints doc.
+func (job *PrintJob) Print(doc Printable) (err error) {
+	printer := job.Printer
+	if printer == nil {
+		if printer, err = DefaultPrinter(); err != nil {
+			return
+		}
+	}
+
+	hdc := printer.createDC()
+	if hdc == 0 {
+		return newError("CreateDC failed")
+	}
+	defer win.DeleteDC(hdc)
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	m := pageMetricsForHDC(hdc)
+	layout := m.layout(job.Margins, m.printable.Location())
+
+	pageCount, err := doc.Paginate(canvas, layout)
+	if err != nil {
+		return
+	}
+
+	var di win.DOCINFO
+	di.CbSize = int32(unsafe.Sizeof(di))
+	di.LpszDocName = syscall.StringToUTF16Ptr(job.DocumentName)
+
+	if win.StartDoc(hdc, &di) <= 0 {
+		return newError("StartDoc failed")
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			win.AbortDoc(hdc)
+		}
+	}()
+
+	for _, index := range pagination.Select(pageCount, job.PageRanges) {
+		if win.StartPage(hdc) <= 0 {
+			return newError("StartPage failed")
+		}
+
+		if err = doc.PrintPage(canvas, layout, index); err != nil {
+			return
+		}
+
+		if win.EndPage(hdc) <= 0 {
+			return newError("EndPage failed")
+		}
+	}
+
+	if win.EndDoc(hdc) <= 0 {
+		return newError("EndDoc failed")
+	}
+
+	succeeded = true
+
+	return
+}
+
+// PrintDialog is the standard dialog to choose a printer, its settings and the
+// pages to print.
+type PrintDialog struct {
+	// Printer is the printer that is selected initially. If it is nil, the
+	// default printer is. After the dialog was accepted, it is the printer
+	// and settings the user chose.
+	Printer *Printer
+
+	// MinPage and MaxPage are the page numbers the user can choose from,
+	// starting at 1. If MaxPage is 0, the user can only print all pages.
+	MinPage, MaxPage int
+
+	// PageRanges are the pages to print. If there are none, all pages are
+	// printed.
+	PageRanges []pagination.Range
+}
+
+// Show shows the dialog and returns if the user chose to print.
+func (dlg *PrintDialog) Show(owner Form) (accepted bool, err error) {
+	var pd win.PRINTDLGEX
+	pd.LStructSize = uint32(unsafe.Sizeof(pd))
+	if owner != nil {
+		pd.HwndOwner = owner.Handle()
+	} else {
+		// PrintDlgEx requires an owner.
+		pd.HwndOwner = win.GetDesktopWindow()
+	}
+	pd.Flags = win.PD_NOSELECTION | win.PD_NOCURRENTPAGE | win.PD_USEDEVMODECOPIESANDCOLLATE
+	pd.NStartPage = win.START_PAGE_GENERAL
+
+	if dlg.Printer != nil {
+		pd.HDevMode = dlg.Printer.globalDevMode()
+		pd.HDevNames = dlg.Printer.globalDevNames()
+	}
+	defer func() {
+		if pd.HDevMode != 0 {
+			win.GlobalFree(pd.HDevMode)
+		}
+		if pd.HDevNames != 0 {
+			win.GlobalFree(pd.HDevNames)
+		}
+	}()
+
+	ranges := make([]win.PRINTPAGERANGE, maxi(10, len(dlg.PageRanges)))
+	if dlg.MaxPage > 0 {
+		pd.NMinPage = uint32(maxi(1, dlg.MinPage))
+		pd.NMaxPage = uint32(dlg.MaxPage)
+		pd.NMaxPageRanges = uint32(len(ranges))
+		pd.LpPageRanges = &ranges[0]
+
+		for i, r := range dlg.PageRanges {
+			ranges[i] = win.PRINTPAGERANGE{NFromPage: uint32(r.From), NToPage: uint32(r.To)}
+		}
+		if len(dlg.PageRanges) > 0 {
+			pd.Flags |= win.PD_PAGENUMS
+			pd.NPageRanges = uint32(len(dlg.PageRanges))
+		}
+	} else {
+		pd.Flags |= win.PD_NOPAGENUMS
+	}
+
+	if hr := win.PrintDlgEx(&pd); hr != win.S_OK {
+		return false, newError(fmt.Sprintf("PrintDlgEx failed: 0x%x", uint32(hr)))
+	}
+
+	if pd.DwResultAction == win.PD_RESULT_CANCEL {
+		return false, nil
+	}
+
+	if printer := printerFromGlobals(pd.HDevNames, pd.HDevMode); printer != nil {
+		dlg.Printer = printer
+	}
+
+	dlg.PageRanges = nil
+	if pd.Flags&win.PD_PAGENUMS != 0 {
+		for _, r := range ranges[:pd.NPageRanges] {
+			dlg.PageRanges = append(dlg.PageRanges, pagination.Range{From: int(r.NFromPage), To: int(r.NToPage)})
+		}
+	}
+
+	return pd.DwResultAction == win.PD_RESULT_PRINT, nil
+}
+
+const (
+	psdMargins               = 0x00000002
+	psdInThousandthsOfInches = 0x00000004
+)
+
+type pageSetupDlg struct {
+	lStructSize             uint32
+	hwndOwner               win.HWND
+	hDevMode                win.HGLOBAL
+	hDevNames               win.HGLOBAL
+	flags                   uint32
+	ptPaperSize             win.POINT
+	rtMinMargin             win.RECT
+	rtMargin                win.RECT
+	hInstance               win.HINSTANCE
+	lCustData               uintptr
+	lpfnPageSetupHook       uintptr
+	lpfnPagePaintHook       uintptr
+	lpPageSetupTemplateName *uint16
+	hPageSetupTemplate      win.HGLOBAL
+}
+
+// PageSetupDialog is the standard dialog to choose the paper, its orientation
+// and the margins of printed pages.
+type PageSetupDialog struct {
+	// Printer is the printer whose paper is chosen. If it is nil, the
+	// default printer is used. After the dialog was accepted, it is the
+	// printer with the chosen paper and orientation.
+	Printer *Printer
+
+	// Margins are the margins in 1/96" units. If they are zero, the dialog
+	// starts with default margins.
+	Margins Margins
+}
+
+// Show shows the dialog and returns if the user accepted it.
+func (dlg *PageSetupDialog) Show(owner Form) (accepted bool, err error) {
+	var psd pageSetupDlg
+	psd.lStructSize = uint32(unsafe.Sizeof(psd))
+	if owner != nil {
+		psd.hwndOwner = owner.Handle()
+	}
+	psd.flags = psdInThousandthsOfInches
+
+	if dlg.Printer != nil {
+		psd.hDevMode = dlg.Printer.globalDevMode()
+		psd.hDevNames = dlg.Printer.globalDevNames()
+	}
+	defer func() {
+		if psd.hDevMode != 0 {
+			win.GlobalFree(psd.hDevMode)
+		}
+		if psd.hDevNames != 0 {
+			win.GlobalFree(psd.hDevNames)
+		}
+	}()
+
+	if !dlg.Margins.isZero() {
+		psd.flags |= psdMargins
+		psd.rtMargin = win.RECT{
+			Left:   int32(dlg.Margins.HNear * 1000 / 96),
+			Top:    int32(dlg.Margins.VNear * 1000 / 96),
+			Right:  int32(dlg.Margins.HFar * 1000 / 96),
+			Bottom: int32(dlg.Margins.VFar * 1000 / 96),
+		}
+	}
+
+	if ret, _, _ := procPageSetupDlg.Call(uintptr(unsafe.Pointer(&psd))); ret == 0 {
+		if errno := win.CommDlgExtendedError(); errno != 0 {
+			err = newError(fmt.Sprintf("Error %d", errno))
+		}
+		return
+	}
+
+	if printer := printerFromGlobals(psd.hDevNames, psd.hDevMode); printer != nil {
+		dlg.Printer = printer
+	}
+
+	fromThousandths := func(v int32) int {
+		return int((v*96 + 500) / 1000)
+	}
+
+	dlg.Margins = Margins{
+		HNear: fromThousandths(psd.rtMargin.Left),
+		VNear: fromThousandths(psd.rtMargin.Top),
+		HFar:  fromThousandths(psd.rtMargin.Right),
+		VFar:  fromThousandths(psd.rtMargin.Bottom),
+	}
+
+	accepted = true
+
+	return
+}
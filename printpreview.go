@@ -0,0 +1,410 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"github.com/lxn/win"
+)
+
+// PrintPreview is a widget that shows the pages of a Printable one at a time,
+// as they would be printed.
+//
+// Pages are laid out for the printer they will be printed with, or the
+// default printer if none is set, so the preview breaks pages exactly like
+// printing does. Without any printer, they are laid out for US Letter paper.
+//
+// Page Up, Page Down, Home and End switch pages.
+type PrintPreview struct {
+	*CustomWidget
+	document                    Printable
+	printer                     *Printer
+	defaultPrinter              *Printer
+	margins                     Margins
+	layout                      PageLayout
+	pageCount                   int
+	currentPage                 int
+	pageBitmap                  *Bitmap
+	pageBitmapIndex             int
+	currentPageChangedPublisher EventPublisher
+	pageCountChangedPublisher   EventPublisher
+}
+
+// NewPrintPreview creates and returns a *PrintPreview as child of parent.
+func NewPrintPreview(parent Container) (*PrintPreview, error) {
+	pp := new(PrintPreview)
+
+	cw, err := NewCustomWidgetPixels(parent, 0, func(canvas *Canvas, updateBounds Rectangle) error {
+		return pp.paint(canvas, updateBounds)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pp.CustomWidget = cw
+
+	if err := InitWrapperWindow(pp); err != nil {
+		pp.Dispose()
+		return nil, err
+	}
+
+	pp.SetInvalidatesOnResize(true)
+	pp.SetPaintMode(PaintBuffered)
+
+	pp.KeyDown().Attach(func(key Key) {
+		switch key {
+		case KeyPrior:
+			pp.SetCurrentPage(pp.currentPage - 1)
+
+		case KeyNext:
+			pp.SetCurrentPage(pp.currentPage + 1)
+
+		case KeyHome:
+			pp.SetCurrentPage(0)
+
+		case KeyEnd:
+			pp.SetCurrentPage(pp.pageCount - 1)
+		}
+	})
+
+	pp.MustRegisterProperty("CurrentPage", NewProperty(
+		func() interface{} {
+			return pp.CurrentPage()
+		},
+		func(v interface{}) error {
+			pp.SetCurrentPage(assertIntOr(v, 0))
+
+			return nil
+		},
+		pp.currentPageChangedPublisher.Event()))
+
+	return pp, nil
+}
+
+func (pp *PrintPreview) Dispose() {
+	pp.disposePageBitmap()
+
+	pp.CustomWidget.Dispose()
+}
+
+func (pp *PrintPreview) disposePageBitmap() {
+	if pp.pageBitmap != nil {
+		pp.pageBitmap.Dispose()
+		pp.pageBitmap = nil
+	}
+}
+
+// Document returns the Printable that is previewed.
+func (pp *PrintPreview) Document() Printable {
+	return pp.document
+}
+
+// SetDocument sets the Printable to preview and shows its first page.
+func (pp *PrintPreview) SetDocument(document Printable) error {
+	pp.document = document
+
+	if err := pp.Repaginate(); err != nil {
+		return err
+	}
+
+	pp.SetCurrentPage(0)
+
+	return nil
+}
+
+// Printer returns the printer that pages are laid out for. It is nil if the
+// default printer is used.
+func (pp *PrintPreview) Printer() *Printer {
+	return pp.printer
+}
+
+// SetPrinter sets the printer that pages are laid out for. Pass nil for the
+// default printer.
+func (pp *PrintPreview) SetPrinter(printer *Printer) error {
+	pp.printer = printer
+
+	return pp.Repaginate()
+}
+
+// Margins returns the margins of the pages in 1/96" units.
+func (pp *PrintPreview) Margins() Margins {
+	return pp.margins
+}
+
+// SetMargins sets the margins of the pages in 1/96" units, as they are passed
+// to PrintJob.
+func (pp *PrintPreview) SetMargins(margins Margins) error {
+	pp.margins = margins
+
+	return pp.Repaginate()
+}
+
+// PageCount returns the number of pages of the document.
+func (pp *PrintPreview) PageCount() int {
+	return pp.pageCount
+}
+
+// PageCountChanged returns the event that is published when the number of
+// pages changed.
+func (pp *PrintPreview) PageCountChanged() *Event {
+	return pp.pageCountChangedPublisher.Event()
+}
+
+// CurrentPage returns the index of the page that is shown, starting at 0.
+func (pp *PrintPreview) CurrentPage() int {
+	return pp.currentPage
+}
+
+// SetCurrentPage shows the page with index, starting at 0. index is clamped
+// to the pages of the document.
+func (pp *PrintPreview) SetCurrentPage(index int) {
+	index = maxi(0, mini(index, pp.pageCount-1))
+	if index == pp.currentPage {
+		return
+	}
+
+	pp.currentPage = index
+
+	pp.Invalidate()
+
+	pp.currentPageChangedPublisher.Publish()
+}
+
+// CurrentPageChanged returns the event that is published when another page
+// is shown.
+func (pp *PrintPreview) CurrentPageChanged() *Event {
+	return pp.currentPageChangedPublisher.Event()
+}
+
+// Repaginate lays out the document again. Call it after the document
+// changed.
+func (pp *PrintPreview) Repaginate() error {
+	pp.disposePageBitmap()
+
+	pageCount := 0
+
+	if pp.document != nil {
+		hdc, err := pp.createReferenceHDC()
+		if err != nil {
+			return err
+		}
+		defer win.DeleteDC(hdc)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			return err
+		}
+		defer canvas.Dispose()
+
+		pp.layout = pageMetricsForHDC(hdc).layout(pp.margins, Point{})
+
+		if pageCount, err = pp.document.Paginate(canvas, pp.layout); err != nil {
+			return err
+		}
+	}
+
+	if pageCount != pp.pageCount {
+		pp.pageCount = pageCount
+
+		pp.pageCountChangedPublisher.Publish()
+	}
+
+	if pp.currentPage >= pageCount {
+		pp.SetCurrentPage(pageCount - 1)
+	}
+
+	pp.Invalidate()
+
+	return nil
+}
+
+// createReferenceHDC returns a device context that pages are laid out for.
+// The caller must delete it.
+func (pp *PrintPreview) createReferenceHDC() (win.HDC, error) {
+	printer := pp.printer
+	if printer == nil {
+		if pp.defaultPrinter == nil {
+			pp.defaultPrinter, _ = DefaultPrinter()
+		}
+		printer = pp.defaultPrinter
+	}
+
+	if printer != nil {
+		if hdc := printer.createIC(); hdc != 0 {
+			return hdc, nil
+		}
+	}
+
+	hdc := win.CreateCompatibleDC(0)
+	if hdc == 0 {
+		return 0, newError("CreateCompatibleDC failed")
+	}
+
+	return hdc, nil
+}
+
+// RenderPage renders the page with index, starting at 0, into a new *Bitmap
+// at dpi. The caller must dispose it.
+func (pp *PrintPreview) RenderPage(index, dpi int) (*Bitmap, error) {
+	if index < 0 || index >= pp.pageCount {
+		return nil, newError("invalid page index")
+	}
+
+	paper := pp.layout.Paper
+
+	size := Size{
+		scaleInt(paper.Width, float64(dpi)/float64(pp.layout.DPI)),
+		scaleInt(paper.Height, float64(dpi)/float64(pp.layout.DPI)),
+	}
+
+	return pp.renderPage(index, size, dpi)
+}
+
+func (pp *PrintPreview) renderPage(index int, size Size, dpi int) (*Bitmap, error) {
+	whiteBrush, err := NewSolidColorBrush(RGB(0xFF, 0xFF, 0xFF))
+	if err != nil {
+		return nil, err
+	}
+	defer whiteBrush.Dispose()
+
+	// The page is recorded for the printer first, so it looks like it will
+	// when printed, and then scaled to size.
+	hdc, err := pp.createReferenceHDC()
+	if err != nil {
+		return nil, err
+	}
+	defer win.DeleteDC(hdc)
+
+	refCanvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return nil, err
+	}
+	defer refCanvas.Dispose()
+
+	mf, err := NewMetafile(refCanvas)
+	if err != nil {
+		return nil, err
+	}
+	defer mf.Dispose()
+
+	mfCanvas, err := NewCanvasFromImage(mf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filling the paper makes it the frame of the metafile, which is what
+	// gets scaled to size.
+	err = mfCanvas.FillRectanglePixels(whiteBrush, pp.layout.Paper)
+	if err == nil {
+		err = pp.document.PrintPage(mfCanvas, pp.layout, index)
+	}
+	mfCanvas.Dispose()
+	if err != nil {
+		return nil, err
+	}
+
+	bmp, err := NewBitmapForDPI(size, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	canvas, err := NewCanvasFromImage(bmp)
+	if err != nil {
+		bmp.Dispose()
+		return nil, err
+	}
+	defer canvas.Dispose()
+
+	bounds := Rectangle{0, 0, size.Width, size.Height}
+
+	if err := canvas.FillRectanglePixels(whiteBrush, bounds); err != nil {
+		bmp.Dispose()
+		return nil, err
+	}
+
+	if err := canvas.DrawImageStretchedPixels(mf, bounds); err != nil {
+		bmp.Dispose()
+		return nil, err
+	}
+
+	return bmp, nil
+}
+
+// pageBounds returns where the current page goes, as large as fits.
+func (pp *PrintPreview) pageBounds() Rectangle {
+	bounds := pp.ClientBoundsPixels()
+
+	gap := pp.IntFrom96DPI(12)
+	bounds.X += gap
+	bounds.Y += gap
+	bounds.Width -= 2 * gap
+	bounds.Height -= 2 * gap
+
+	paper := pp.layout.Paper
+	if bounds.Width <= 0 || bounds.Height <= 0 || paper.Width <= 0 || paper.Height <= 0 {
+		return Rectangle{}
+	}
+
+	width := bounds.Width
+	height := scaleInt(paper.Height, float64(width)/float64(paper.Width))
+	if height > bounds.Height {
+		height = bounds.Height
+		width = scaleInt(paper.Width, float64(height)/float64(paper.Height))
+	}
+
+	return Rectangle{
+		bounds.X + (bounds.Width-width)/2,
+		bounds.Y + (bounds.Height-height)/2,
+		width,
+		height,
+	}
+}
+
+func (pp *PrintPreview) paint(canvas *Canvas, updateBounds Rectangle) error {
+	bgBrush, err := NewSystemColorBrush(SysColorAppWorkspace)
+	if err != nil {
+		return err
+	}
+	defer bgBrush.Dispose()
+
+	if err := canvas.FillRectanglePixels(bgBrush, pp.ClientBoundsPixels()); err != nil {
+		return err
+	}
+
+	if pp.pageCount == 0 {
+		return nil
+	}
+
+	bounds := pp.pageBounds()
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return nil
+	}
+
+	if pp.pageBitmap == nil || pp.pageBitmapIndex != pp.currentPage || pp.pageBitmap.size != bounds.Size() {
+		pp.disposePageBitmap()
+
+		bmp, err := pp.renderPage(pp.currentPage, bounds.Size(), canvas.DPI())
+		if err != nil {
+			return err
+		}
+
+		pp.pageBitmap = bmp
+		pp.pageBitmapIndex = pp.currentPage
+	}
+
+	shadowBrush, err := NewSolidColorBrush(RGB(0x40, 0x40, 0x40))
+	if err != nil {
+		return err
+	}
+	defer shadowBrush.Dispose()
+
+	offset := pp.IntFrom96DPI(3)
+	if err := canvas.FillRectanglePixels(shadowBrush, Rectangle{bounds.X + offset, bounds.Y + offset, bounds.Width, bounds.Height}); err != nil {
+		return err
+	}
+
+	return canvas.DrawImageStretchedPixels(pp.pageBitmap, bounds)
+}
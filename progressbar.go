@@ -26,6 +26,16 @@ func NewProgressBar(parent Container) (*ProgressBar, error) {
 		return nil, err
 	}
 
+	pb.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return pb.Value()
+		},
+		func(v interface{}) error {
+			pb.SetValue(assertIntOr(v, 0))
+			return nil
+		},
+		nil))
+
 	return pb, nil
 }
 
@@ -28,6 +28,24 @@ type Property interface {
 	SetValidator(validator Validator) error
 }
 
+// ConvertibleProperty is a Property whose values can be converted on their
+// way between the widget and the data source of a DataBinder. The properties
+// of walk widgets implement it.
+type ConvertibleProperty interface {
+	Property
+	Converter() Converter
+	SetConverter(converter Converter) error
+}
+
+// propertyConverter returns the Converter of prop, or nil if it has none.
+func propertyConverter(prop Property) Converter {
+	if cp, ok := prop.(ConvertibleProperty); ok {
+		return cp.Converter()
+	}
+
+	return nil
+}
+
 type property struct {
 	get                 func() interface{}
 	set                 func(v interface{}) error
@@ -35,6 +53,7 @@ type property struct {
 	source              interface{}
 	sourceChangedHandle int
 	validator           Validator
+	converter           Converter
 }
 
 func NewProperty(get func() interface{}, set func(v interface{}) error, changed *Event) Property {
@@ -135,6 +154,20 @@ func (p *property) SetValidator(validator Validator) error {
 	return nil
 }
 
+func (p *property) Converter() Converter {
+	return p.converter
+}
+
+func (p *property) SetConverter(converter Converter) error {
+	if p.ReadOnly() {
+		return ErrPropertyReadOnly
+	}
+
+	p.converter = converter
+
+	return nil
+}
+
 type readOnlyProperty struct {
 	get     func() interface{}
 	changed *Event
@@ -184,12 +217,21 @@ func (*readOnlyProperty) SetValidator(validator Validator) error {
 	return ErrPropertyReadOnly
 }
 
+func (*readOnlyProperty) Converter() Converter {
+	return nil
+}
+
+func (*readOnlyProperty) SetConverter(converter Converter) error {
+	return ErrPropertyReadOnly
+}
+
 type boolProperty struct {
 	get                 func() bool
 	set                 func(v bool) error
 	changed             *Event
 	source              interface{}
 	sourceChangedHandle int
+	converter           Converter
 }
 
 func NewBoolProperty(get func() bool, set func(b bool) error, changed *Event) Property {
@@ -295,6 +337,20 @@ func (*boolProperty) SetValidator(validator Validator) error {
 	return ErrPropertyNotValidatable
 }
 
+func (bp *boolProperty) Converter() Converter {
+	return bp.converter
+}
+
+func (bp *boolProperty) SetConverter(converter Converter) error {
+	if bp.ReadOnly() {
+		return ErrPropertyReadOnly
+	}
+
+	bp.converter = converter
+
+	return nil
+}
+
 func (bp *boolProperty) Satisfied() bool {
 	return bp.get()
 }
@@ -348,6 +404,14 @@ func (*readOnlyBoolProperty) SetValidator(validator Validator) error {
 	return ErrPropertyNotValidatable
 }
 
+func (*readOnlyBoolProperty) Converter() Converter {
+	return nil
+}
+
+func (*readOnlyBoolProperty) SetConverter(converter Converter) error {
+	return ErrPropertyReadOnly
+}
+
 func (robp *readOnlyBoolProperty) Satisfied() bool {
 	return robp.get()
 }
@@ -69,6 +69,31 @@ func newReflectListModel(dataSource interface{}) (ListModel, error) {
 			m.items = rlm.Items()
 			m.value = reflect.ValueOf(m.items)
 
+			m.PublishItemsRemoved(from, to)
+		})
+	} else if obs, ok := dataSource.(*ObservableSlice); ok {
+		obs.ItemChanged().Attach(func(index int) {
+			m.PublishItemChanged(index)
+		})
+
+		obs.ItemsReset().Attach(func() {
+			m.items = obs.Items()
+			m.value = reflect.ValueOf(m.items)
+
+			m.PublishItemsReset()
+		})
+
+		obs.ItemsInserted().Attach(func(from, to int) {
+			m.items = obs.Items()
+			m.value = reflect.ValueOf(m.items)
+
+			m.PublishItemsInserted(from, to)
+		})
+
+		obs.ItemsRemoved().Attach(func(from, to int) {
+			m.items = obs.Items()
+			m.value = reflect.ValueOf(m.items)
+
 			m.PublishItemsRemoved(from, to)
 		})
 	}
@@ -166,6 +191,35 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 		})
 	} else {
 		m.sorterBase = new(SorterBase)
+
+		if obs, ok := dataSource.(*ObservableSlice); ok {
+			obs.ItemChanged().Attach(func(index int) {
+				m.PublishRowChanged(index)
+			})
+
+			obs.ItemsReset().Attach(func() {
+				m.items = obs.Items()
+				m.value = reflect.ValueOf(m.items)
+
+				m.PublishRowsReset()
+
+				m.sort(m.sorterBase.SortedColumn(), m.sorterBase.SortOrder())
+			})
+
+			obs.ItemsInserted().Attach(func(from, to int) {
+				m.items = obs.Items()
+				m.value = reflect.ValueOf(m.items)
+
+				m.PublishRowsInserted(from, to)
+			})
+
+			obs.ItemsRemoved().Attach(func(from, to int) {
+				m.items = obs.Items()
+				m.value = reflect.ValueOf(m.items)
+
+				m.PublishRowsRemoved(from, to)
+			})
+		}
 	}
 
 	if is, ok := dataSource.(interceptedSorter); ok {
@@ -371,7 +425,9 @@ func itemsFromReflectModelDataSource(dataSource interface{}, requiredInterfaceNa
 	}
 
 	if requiredInterfaceName == "ReflectListModel" {
-		if _, ok := dataSource.([]string); ok {
+		// This includes an ObservableSlice of strings, whose items we got
+		// above.
+		if _, ok := items.([]string); ok {
 			return items, nil
 		}
 	}
@@ -390,7 +446,7 @@ func itemsFromReflectModelDataSource(dataSource interface{}, requiredInterfaceNa
 
 func valueFromSlice(dataSource interface{}, itemsValue reflect.Value, member string, index int) interface{} {
 	if member == "" {
-		if strs, ok := dataSource.([]string); ok {
+		if strs, ok := itemsValue.Interface().([]string); ok {
 			return strs[index]
 		}
 
@@ -58,12 +58,15 @@ func (rm *ResourceManager) Bitmap(name string) (*Bitmap, error) {
 
 // BitmapForDPI loads a bitmap from file or resource identified by name, or an error if it could
 // not be found. When bitmap is loaded, given DPI is assumed.
+//
+// Variants of bitmap files for higher DPIs, like open@2x.png and open@3x.png next to open.png,
+// are loaded along with them.
 func (rm *ResourceManager) BitmapForDPI(name string, dpi int) (*Bitmap, error) {
 	if bm := rm.bitmaps[name]; bm != nil {
 		return bm, nil
 	}
 
-	if bm, err := NewBitmapFromFileForDPI(filepath.Join(rm.rootDirPath, name), dpi); err == nil {
+	if bm, err := newBitmapWithVariantsFromFile(filepath.Join(rm.rootDirPath, name), dpi); err == nil {
 		rm.bitmaps[name] = bm
 		return bm, nil
 	}
@@ -110,6 +113,8 @@ func (rm *ResourceManager) Icon(name string) (*Icon, error) {
 }
 
 // Image returns the Image identified by name, or an error if it could not be found.
+//
+// Icons can also be loaded from .svg files. Bitmaps are loaded like by BitmapForDPI.
 func (rm *ResourceManager) Image(name string) (Image, error) {
 	if icon, err := rm.Icon(name); err == nil {
 		return icon, nil
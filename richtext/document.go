@@ -0,0 +1,117 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package richtext converts formatted text between RTF, a safe subset of HTML
+// and a simple document model.
+//
+// walk.RichTextEdit uses it to import and export HTML. It does not depend on
+// any platform, so conversions can be tested anywhere.
+package richtext
+
+import (
+	"image/color"
+	"strings"
+)
+
+// Alignment is how the lines of a paragraph are aligned horizontally.
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+	AlignJustify
+)
+
+// Style is the character formatting of a Run.
+type Style struct {
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Strikeout bool
+
+	// Color is the color of the text. A zero alpha means the default color.
+	Color color.NRGBA
+
+	// Font is the font family. Empty means the default font.
+	Font string
+
+	// Size is the font size in points. Zero means the default size.
+	Size float64
+}
+
+// Run is text of a paragraph with the same Style.
+type Run struct {
+	// Text may contain line breaks and tabs, but no paragraph breaks.
+	Text  string
+	Style Style
+}
+
+// Paragraph is a block of runs, with paragraph formatting.
+type Paragraph struct {
+	Alignment Alignment
+	Bullet    bool
+	Runs      []Run
+}
+
+// Document is formatted text, made of paragraphs.
+type Document struct {
+	Paragraphs []Paragraph
+}
+
+// AppendText appends text with style to the paragraph, merging it into the
+// last run if that has the same style.
+func (p *Paragraph) AppendText(text string, style Style) {
+	if text == "" {
+		return
+	}
+
+	if n := len(p.Runs); n > 0 && p.Runs[n-1].Style == style {
+		p.Runs[n-1].Text += text
+		return
+	}
+
+	p.Runs = append(p.Runs, Run{Text: text, Style: style})
+}
+
+// Text returns the text of the paragraph, without formatting.
+func (p *Paragraph) Text() string {
+	var b strings.Builder
+
+	for _, run := range p.Runs {
+		b.WriteString(run.Text)
+	}
+
+	return b.String()
+}
+
+// Text returns the text of the document, without formatting. Paragraphs are
+// separated by newlines.
+func (d *Document) Text() string {
+	texts := make([]string, len(d.Paragraphs))
+
+	for i := range d.Paragraphs {
+		texts[i] = d.Paragraphs[i].Text()
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// SetDefaultFont replaces the default font family and size in all runs with
+// font and size, so the document keeps its look when it is shown somewhere
+// with other defaults.
+func (d *Document) SetDefaultFont(font string, size float64) {
+	for i := range d.Paragraphs {
+		runs := d.Paragraphs[i].Runs
+
+		for j := range runs {
+			if runs[j].Style.Font == "" {
+				runs[j].Style.Font = font
+			}
+			if runs[j].Style.Size == 0 {
+				runs[j].Style.Size = size
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"bytes"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHTMLRoundTrip(t *testing.T) {
+	green := color.NRGBA{0, 0x80, 0, 0xff}
+
+	doc := &Document{Paragraphs: []Paragraph{
+		{Runs: []Run{
+			{Text: "Plain, "},
+			{Text: "bold", Style: Style{Bold: true}},
+			{Text: " and ", Style: Style{Italic: true, Underline: true, Strikeout: true}},
+			{Text: "green", Style: Style{Color: green, Font: "Courier New", Size: 10.5}},
+		}},
+		{Alignment: AlignCenter, Runs: []Run{
+			{Text: "中文 😀 <tags> & \"quotes\""},
+		}},
+		{Alignment: AlignRight, Runs: []Run{
+			{Text: "two\nlines"},
+		}},
+		{Bullet: true, Runs: []Run{{Text: "first"}}},
+		{Bullet: true, Alignment: AlignJustify, Runs: []Run{{Text: "second"}}},
+		{Runs: []Run{{Text: "after the list"}}},
+	}}
+
+	var buf bytes.Buffer
+	if err := doc.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadHTML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("got %+v, want %+v", got, doc)
+	}
+}
+
+func TestReadHTMLDropsActiveContent(t *testing.T) {
+	const html = `<p onclick="alert(1)">Safe <a href="javascript:alert(2)" onmouseover="alert(3)">link</a>` +
+		`<script>alert(4)</script><SCRIPT type="text/javascript">alert(5)</ScRiPt>` +
+		`<style>p { background: url(javascript:alert(6)) }</style>` +
+		`<img src="x" onerror="alert(7)"><iframe src="javascript:alert(8)">frame</iframe>` +
+		`<span style="color:red;background-image:url(javascript:alert(9))">text</span></p>`
+
+	doc, err := ReadHTML(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := doc.Text(), "Safe linktext"; got != want {
+		t.Errorf("got text %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.ToLower(buf.String())
+	for _, bad := range []string{"alert", "javascript", "script", "onclick", "onerror", "href", "src=", "url("} {
+		if strings.Contains(out, bad) {
+			t.Errorf("got %q in HTML %s", bad, buf.String())
+		}
+	}
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	doc := &Document{Paragraphs: []Paragraph{{Runs: []Run{
+		{Text: `</p><script>alert(1)</script>`, Style: Style{Font: `x'; } </style><script>alert(2)</script>`}},
+	}}}}
+
+	var buf bytes.Buffer
+	if err := doc.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := buf.String(); strings.Contains(out, "<script") || strings.Contains(out, "</style") {
+		t.Errorf("got unescaped markup in HTML %s", out)
+	}
+
+	got, err := ReadHTML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := got.Text(); text != doc.Text() {
+		t.Errorf("got text %q, want %q", text, doc.Text())
+	}
+}
+
+func TestReadHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want *Document
+	}{
+		{
+			"whitespace",
+			"<p>  a \n\t b  </p>\n\n<p>c</p>",
+			&Document{Paragraphs: []Paragraph{
+				{Runs: []Run{{Text: "a b"}}},
+				{Runs: []Run{{Text: "c"}}},
+			}},
+		},
+		{
+			"pre",
+			"<pre>a\n  b</pre>",
+			&Document{Paragraphs: []Paragraph{{Runs: []Run{{Text: "a\n  b"}}}}},
+		},
+		{
+			"headings and font",
+			`<h2>Title</h2><font color="#00f" face="Arial, sans-serif" size="5">big</font>`,
+			&Document{Paragraphs: []Paragraph{
+				{Runs: []Run{{Text: "Title", Style: Style{Bold: true, Size: 18}}}},
+				{Runs: []Run{{Text: "big", Style: Style{Color: color.NRGBA{0, 0, 0xff, 0xff}, Font: "Arial", Size: 18}}}},
+			}},
+		},
+		{
+			"styles",
+			`<div align="center"><span style="font-weight:700; font-style:italic; text-decoration:underline line-through; font-size:16px; color:rgb(1, 2, 3)">x</span></div>`,
+			&Document{Paragraphs: []Paragraph{
+				{Alignment: AlignCenter, Runs: []Run{{Text: "x", Style: Style{Bold: true, Italic: true, Underline: true, Strikeout: true, Size: 12, Color: color.NRGBA{1, 2, 3, 0xff}}}}},
+			}},
+		},
+		{
+			"implicitly closed",
+			"<ul><li>one<li>two</ul><p>a<p>b",
+			&Document{Paragraphs: []Paragraph{
+				{Bullet: true, Runs: []Run{{Text: "one"}}},
+				{Bullet: true, Runs: []Run{{Text: "two"}}},
+				{Runs: []Run{{Text: "a"}}},
+				{Runs: []Run{{Text: "b"}}},
+			}},
+		},
+		{
+			"comments and entities",
+			"<!DOCTYPE html><!-- <b>hidden</b> -->a &amp; b &lt;c&gt; &#8364;",
+			&Document{Paragraphs: []Paragraph{{Runs: []Run{{Text: "a & b <c> €"}}}}},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ReadHTML(strings.NewReader(test.html))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
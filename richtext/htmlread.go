@@ -0,0 +1,606 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"html"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ReadHTML reads an HTML document or fragment from r.
+//
+// Only a safe subset is understood: paragraphs and other blocks, line breaks,
+// bold, italic, underlined and struck out text, colors, font families and
+// sizes from font elements and style attributes, headings, alignment and
+// bulleted lists. The text of links and unknown elements is kept, while
+// scripts, styles, embedded content and images are dropped.
+func ReadHTML(r io.Reader) (*Document, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var hr htmlReader
+	hr.read(string(b))
+
+	return &hr.doc, nil
+}
+
+// htmlFrame is an open element.
+type htmlFrame struct {
+	tag      string
+	style    Style
+	align    Alignment
+	hasAlign bool
+	pre      bool
+}
+
+type htmlReader struct {
+	doc          Document
+	para         Paragraph
+	paraHasText  bool
+	pendingSpace bool
+	spaceStyle   Style // Of the pending space, which is where it was found.
+	stack        []htmlFrame
+}
+
+// htmlBlockTags are the elements that start and end paragraphs.
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"center": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "tr": true, "ul": true,
+}
+
+// htmlVoidTags are the elements that have no content and no end tag.
+var htmlVoidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlDroppedTags are the elements whose content is dropped.
+var htmlDroppedTags = map[string]bool{
+	"audio": true, "canvas": true, "head": true, "iframe": true, "math": true,
+	"noscript": true, "object": true, "script": true, "select": true,
+	"style": true, "svg": true, "template": true, "textarea": true,
+	"title": true, "video": true,
+}
+
+// htmlHeadingSizes are the font sizes of h1 to h6 in points.
+var htmlHeadingSizes = map[string]float64{
+	"h1": 24, "h2": 18, "h3": 14, "h4": 12, "h5": 10, "h6": 8,
+}
+
+// htmlFontSizes are the font sizes of the size attribute of font elements in
+// points.
+var htmlFontSizes = [...]float64{7.5, 10, 12, 13.5, 18, 24, 36}
+
+func (hr *htmlReader) read(s string) {
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			hr.text(s)
+			break
+		}
+		if i > 0 {
+			hr.text(s[:i])
+			s = s[i:]
+		}
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			s = skipPast(s[4:], "-->")
+
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			s = skipPast(s, ">")
+
+		case strings.HasPrefix(s, "</"):
+			var name string
+			name, s = tagName(s[2:])
+			s = skipPast(s, ">")
+			hr.endTag(name)
+
+		case len(s) > 1 && isLetter(s[1]):
+			var name string
+			var attrs map[string]string
+			name, attrs, s = startTag(s[1:])
+
+			if htmlDroppedTags[name] {
+				s = skipElement(s, name)
+				break
+			}
+
+			hr.startTag(name, attrs)
+
+		default:
+			hr.text("<")
+			s = s[1:]
+		}
+	}
+
+	hr.flush()
+}
+
+// skipPast returns what follows the first sep in s, or nothing.
+func skipPast(s, sep string) string {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[i+len(sep):]
+	}
+
+	return ""
+}
+
+// skipElement returns what follows the end tag of the element name in s.
+func skipElement(s, name string) string {
+	lower := strings.ToLower(s)
+
+	if i := strings.Index(lower, "</"+name); i >= 0 {
+		return skipPast(s[i:], ">")
+	}
+
+	return ""
+}
+
+func tagName(s string) (name, rest string) {
+	i := 0
+	for i < len(s) && (isLetter(s[i]) || isDigit(s[i]) || s[i] == '-' || s[i] == ':') {
+		i++
+	}
+
+	return strings.ToLower(s[:i]), s[i:]
+}
+
+// startTag parses a start tag after its '<' and returns what follows it.
+func startTag(s string) (name string, attrs map[string]string, rest string) {
+	name, s = tagName(s)
+	attrs = make(map[string]string)
+
+	for {
+		s = strings.TrimLeft(s, " \t\r\n\f/")
+		if s == "" {
+			return name, attrs, ""
+		}
+		if s[0] == '>' {
+			return name, attrs, s[1:]
+		}
+
+		i := strings.IndexAny(s, " \t\r\n\f/>=")
+		if i == 0 {
+			// A stray character.
+			s = s[1:]
+			continue
+		}
+		if i < 0 {
+			i = len(s)
+		}
+		attrName := strings.ToLower(s[:i])
+		s = strings.TrimLeft(s[i:], " \t\r\n\f")
+
+		var value string
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\r\n\f")
+
+			if s != "" && (s[0] == '"' || s[0] == '\'') {
+				quote := s[0]
+				end := strings.IndexByte(s[1:], quote)
+				if end < 0 {
+					value, s = s[1:], ""
+				} else {
+					value, s = s[1:end+1], s[end+2:]
+				}
+			} else {
+				end := strings.IndexAny(s, " \t\r\n\f>")
+				if end < 0 {
+					end = len(s)
+				}
+				value, s = s[:end], s[end:]
+			}
+		}
+
+		attrs[attrName] = html.UnescapeString(value)
+	}
+}
+
+func (hr *htmlReader) top() htmlFrame {
+	if len(hr.stack) == 0 {
+		return htmlFrame{}
+	}
+
+	return hr.stack[len(hr.stack)-1]
+}
+
+func (hr *htmlReader) startTag(name string, attrs map[string]string) {
+	switch name {
+	case "br":
+		hr.para.AppendText("\n", hr.top().style)
+		hr.paraHasText = true
+		hr.pendingSpace = false
+		return
+
+	case "li", "p":
+		// These end the previous ones implicitly.
+		hr.closeOpen(name)
+	}
+
+	if htmlBlockTags[name] {
+		hr.flush()
+	}
+
+	if htmlVoidTags[name] {
+		return
+	}
+
+	frame := hr.top()
+	frame.tag = name
+
+	style := &frame.style
+
+	switch name {
+	case "b", "strong":
+		style.Bold = true
+
+	case "i", "em", "cite", "dfn", "var":
+		style.Italic = true
+
+	case "u", "ins":
+		style.Underline = true
+
+	case "s", "strike", "del":
+		style.Strikeout = true
+
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		style.Bold = true
+		style.Size = htmlHeadingSizes[name]
+
+	case "center":
+		frame.align, frame.hasAlign = AlignCenter, true
+
+	case "pre":
+		frame.pre = true
+
+	case "font":
+		if c, ok := parseHTMLColor(attrs["color"]); ok {
+			style.Color = c
+		}
+		if face := firstFontFamily(attrs["face"]); face != "" {
+			style.Font = face
+		}
+		if size, err := strconv.Atoi(attrs["size"]); err == nil {
+			if strings.HasPrefix(attrs["size"], "+") || strings.HasPrefix(attrs["size"], "-") {
+				size += 3
+			}
+			if size < 1 {
+				size = 1
+			} else if size > len(htmlFontSizes) {
+				size = len(htmlFontSizes)
+			}
+			style.Size = htmlFontSizes[size-1]
+		}
+	}
+
+	if align, ok := parseHTMLAlign(attrs["align"]); ok && htmlBlockTags[name] {
+		frame.align, frame.hasAlign = align, true
+	}
+
+	if css, ok := attrs["style"]; ok {
+		applyCSS(&frame, css, htmlBlockTags[name])
+	}
+
+	hr.stack = append(hr.stack, frame)
+
+	if htmlBlockTags[name] {
+		hr.formatParagraph()
+	}
+}
+
+// closeOpen closes an open element name, unless a list is opened after it.
+func (hr *htmlReader) closeOpen(name string) {
+	for i := len(hr.stack) - 1; i >= 0; i-- {
+		switch hr.stack[i].tag {
+		case name:
+			hr.endTag(name)
+			return
+
+		case "ul", "ol", "table":
+			return
+		}
+	}
+}
+
+func (hr *htmlReader) endTag(name string) {
+	for i := len(hr.stack) - 1; i >= 0; i-- {
+		if hr.stack[i].tag == name {
+			hr.stack = hr.stack[:i]
+
+			if htmlBlockTags[name] {
+				hr.flush()
+			}
+			return
+		}
+	}
+}
+
+func (hr *htmlReader) text(s string) {
+	s = html.UnescapeString(s)
+
+	frame := hr.top()
+
+	if frame.pre {
+		if s != "" {
+			hr.para.AppendText(strings.Replace(s, "\r\n", "\n", -1), frame.style)
+			hr.paraHasText = true
+		}
+		return
+	}
+
+	if s != "" && isHTMLSpace(rune(s[0])) {
+		hr.space(frame.style)
+	}
+
+	for i, word := range strings.FieldsFunc(s, isHTMLSpace) {
+		if i > 0 {
+			hr.space(frame.style)
+		}
+		if hr.pendingSpace && hr.paraHasText {
+			hr.para.AppendText(" ", hr.spaceStyle)
+		}
+
+		hr.para.AppendText(word, frame.style)
+		hr.paraHasText = true
+		hr.pendingSpace = false
+	}
+
+	if s != "" && isHTMLSpace(rune(s[len(s)-1])) {
+		hr.space(frame.style)
+	}
+}
+
+// space adds whitespace with style, which collapses with any pending one.
+func (hr *htmlReader) space(style Style) {
+	if !hr.pendingSpace {
+		hr.pendingSpace = true
+		hr.spaceStyle = style
+	}
+}
+
+func isHTMLSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// flush ends the current paragraph, if it has any text.
+func (hr *htmlReader) flush() {
+	if hr.paraHasText {
+		// A line break at the end of a paragraph shows no empty line.
+		if n := len(hr.para.Runs); n > 0 {
+			last := &hr.para.Runs[n-1]
+			last.Text = strings.TrimSuffix(last.Text, "\n")
+			if last.Text == "" {
+				hr.para.Runs = hr.para.Runs[:n-1]
+			}
+		}
+
+		hr.doc.Paragraphs = append(hr.doc.Paragraphs, hr.para)
+	}
+
+	hr.para = Paragraph{}
+	hr.paraHasText = false
+	hr.pendingSpace = false
+
+	hr.formatParagraph()
+}
+
+// formatParagraph sets the paragraph formatting of the current paragraph from
+// the open elements.
+func (hr *htmlReader) formatParagraph() {
+	hr.para.Alignment = AlignLeft
+	hr.para.Bullet = false
+
+	for _, frame := range hr.stack {
+		if frame.hasAlign {
+			hr.para.Alignment = frame.align
+		}
+		if frame.tag == "li" {
+			hr.para.Bullet = true
+		}
+	}
+}
+
+// applyCSS applies the supported declarations of the style attribute css to
+// frame.
+func applyCSS(frame *htmlFrame, css string, block bool) {
+	style := &frame.style
+
+	for _, decl := range strings.Split(css, ";") {
+		colon := strings.IndexByte(decl, ':')
+		if colon < 0 {
+			continue
+		}
+
+		prop := strings.ToLower(strings.TrimSpace(decl[:colon]))
+		value := strings.ToLower(strings.TrimSpace(decl[colon+1:]))
+		value = strings.TrimSpace(strings.TrimSuffix(value, "!important"))
+
+		switch prop {
+		case "color":
+			if c, ok := parseHTMLColor(value); ok {
+				style.Color = c
+			}
+
+		case "font-weight":
+			if n, err := strconv.Atoi(value); err == nil {
+				style.Bold = n >= 600
+			} else {
+				style.Bold = value == "bold" || value == "bolder"
+			}
+
+		case "font-style":
+			style.Italic = value == "italic" || value == "oblique"
+
+		case "text-decoration", "text-decoration-line":
+			if value == "none" {
+				style.Underline, style.Strikeout = false, false
+			}
+			for _, v := range strings.Fields(value) {
+				switch v {
+				case "underline":
+					style.Underline = true
+
+				case "line-through":
+					style.Strikeout = true
+				}
+			}
+
+		case "font-family":
+			// Keep the case of family names.
+			if face := firstFontFamily(strings.TrimSpace(decl[colon+1:])); face != "" {
+				style.Font = face
+			}
+
+		case "font-size":
+			if size, ok := parseCSSFontSize(value); ok {
+				style.Size = size
+			}
+
+		case "text-align":
+			if align, ok := parseHTMLAlign(value); ok && block {
+				frame.align, frame.hasAlign = align, true
+			}
+		}
+	}
+}
+
+// firstFontFamily returns the first family of a list of font families.
+func firstFontFamily(families string) string {
+	family := families
+	if i := strings.IndexByte(family, ','); i >= 0 {
+		family = family[:i]
+	}
+
+	family = strings.TrimSpace(family)
+	family = strings.Trim(family, `"'`)
+
+	switch strings.ToLower(family) {
+	case "serif", "sans-serif", "monospace", "cursive", "fantasy", "system-ui", "inherit", "initial":
+		return ""
+	}
+
+	return family
+}
+
+// parseCSSFontSize parses a font size in pt, px or em, returning points.
+func parseCSSFontSize(value string) (float64, bool) {
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"pt", 1},
+		{"px", 0.75},
+		{"em", 12},
+		{"rem", 12},
+	}
+
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), 64)
+			if err != nil || v <= 0 {
+				return 0, false
+			}
+			return v * unit.factor, true
+		}
+	}
+
+	return 0, false
+}
+
+func parseHTMLAlign(value string) (Alignment, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "left", "start":
+		return AlignLeft, true
+
+	case "center":
+		return AlignCenter, true
+
+	case "right", "end":
+		return AlignRight, true
+
+	case "justify":
+		return AlignJustify, true
+	}
+
+	return AlignLeft, false
+}
+
+// parseHTMLColor parses colors like #rgb, #rrggbb, rgb(r, g, b) and the
+// basic color names.
+func parseHTMLColor(value string) (color.NRGBA, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return color.NRGBA{}, false
+		}
+
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+
+		return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, true
+	}
+
+	if strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")") {
+		parts := strings.Split(value[4:len(value)-1], ",")
+		if len(parts) != 3 {
+			return color.NRGBA{}, false
+		}
+
+		var c [3]uint8
+		for i, part := range parts {
+			v, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || v < 0 || v > 255 {
+				return color.NRGBA{}, false
+			}
+			c[i] = uint8(v)
+		}
+
+		return color.NRGBA{c[0], c[1], c[2], 0xff}, true
+	}
+
+	c, ok := htmlColorNames[value]
+
+	return c, ok
+}
+
+var htmlColorNames = map[string]color.NRGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"silver":  {0xc0, 0xc0, 0xc0, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"maroon":  {0x80, 0x00, 0x00, 0xff},
+	"red":     {0xff, 0x00, 0x00, 0xff},
+	"purple":  {0x80, 0x00, 0x80, 0xff},
+	"fuchsia": {0xff, 0x00, 0xff, 0xff},
+	"green":   {0x00, 0x80, 0x00, 0xff},
+	"lime":    {0x00, 0xff, 0x00, 0xff},
+	"olive":   {0x80, 0x80, 0x00, 0xff},
+	"yellow":  {0xff, 0xff, 0x00, 0xff},
+	"navy":    {0x00, 0x00, 0x80, 0xff},
+	"blue":    {0x00, 0x00, 0xff, 0xff},
+	"teal":    {0x00, 0x80, 0x80, 0xff},
+	"aqua":    {0x00, 0xff, 0xff, 0xff},
+	"orange":  {0xff, 0xa5, 0x00, 0xff},
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteHTML writes the document to w as an HTML fragment, using only the
+// elements and styles that ReadHTML understands.
+func (d *Document) WriteHTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	inList := false
+
+	for _, p := range d.Paragraphs {
+		if p.Bullet != inList {
+			if inList {
+				bw.WriteString("</ul>\n")
+			} else {
+				bw.WriteString("<ul>\n")
+			}
+			inList = p.Bullet
+		}
+
+		tag := "p"
+		if p.Bullet {
+			tag = "li"
+		}
+
+		bw.WriteString("<" + tag)
+		if align := htmlAlignNames[p.Alignment]; align != "" {
+			fmt.Fprintf(bw, ` style="text-align:%s"`, align)
+		}
+		bw.WriteString(">")
+
+		if len(p.Runs) == 0 {
+			bw.WriteString("<br>")
+		}
+
+		for _, run := range p.Runs {
+			writeHTMLRun(bw, run)
+		}
+
+		bw.WriteString("</" + tag + ">\n")
+	}
+
+	if inList {
+		bw.WriteString("</ul>\n")
+	}
+
+	return bw.Flush()
+}
+
+var htmlAlignNames = map[Alignment]string{
+	AlignCenter:  "center",
+	AlignRight:   "right",
+	AlignJustify: "justify",
+}
+
+func writeHTMLRun(bw *bufio.Writer, run Run) {
+	style := run.Style
+
+	var tags []string
+	if style.Bold {
+		tags = append(tags, "b")
+	}
+	if style.Italic {
+		tags = append(tags, "i")
+	}
+	if style.Underline {
+		tags = append(tags, "u")
+	}
+	if style.Strikeout {
+		tags = append(tags, "s")
+	}
+
+	var css []string
+	if style.Color.A != 0 {
+		css = append(css, fmt.Sprintf("color:#%02x%02x%02x", style.Color.R, style.Color.G, style.Color.B))
+	}
+	if family := cssFontFamily(style.Font); family != "" {
+		css = append(css, "font-family:"+family)
+	}
+	if style.Size > 0 {
+		css = append(css, "font-size:"+strconv.FormatFloat(style.Size, 'f', -1, 64)+"pt")
+	}
+
+	if len(css) > 0 {
+		fmt.Fprintf(bw, `<span style="%s">`, html.EscapeString(strings.Join(css, ";")))
+	}
+	for _, tag := range tags {
+		bw.WriteString("<" + tag + ">")
+	}
+
+	lines := strings.Split(run.Text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			bw.WriteString("<br>")
+		}
+		bw.WriteString(html.EscapeString(line))
+	}
+
+	for i := len(tags) - 1; i >= 0; i-- {
+		bw.WriteString("</" + tags[i] + ">")
+	}
+	if len(css) > 0 {
+		bw.WriteString("</span>")
+	}
+}
+
+// cssFontFamily returns font quoted for CSS, without characters that could
+// end the declaration or attribute.
+func cssFontFamily(font string) string {
+	font = strings.Map(func(r rune) rune {
+		switch r {
+		case '"', '\'', ';', '<', '>', '\\', '{', '}':
+			return -1
+		}
+		return r
+	}, font)
+
+	font = strings.TrimSpace(font)
+	if font == "" {
+		return ""
+	}
+
+	return "'" + font + "'"
+}
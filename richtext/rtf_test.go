@@ -0,0 +1,118 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"bytes"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRTFRoundTrip(t *testing.T) {
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+	blue := color.NRGBA{0, 0, 0xff, 0xff}
+
+	doc := &Document{Paragraphs: []Paragraph{
+		{Runs: []Run{
+			{Text: "Plain, "},
+			{Text: "bold", Style: Style{Bold: true}},
+			{Text: " and ", Style: Style{Italic: true, Underline: true, Strikeout: true}},
+			{Text: "red", Style: Style{Color: red, Font: "Consolas", Size: 10.5}},
+		}},
+		{Alignment: AlignCenter, Runs: []Run{
+			{Text: "中文 日本語 한국어", Style: Style{Font: "MS Mincho", Color: blue}},
+		}},
+		{Alignment: AlignRight, Runs: []Run{
+			{Text: "Emoji 😀 and 𝄞 need surrogate pairs"},
+		}},
+		{},
+		{Alignment: AlignJustify, Bullet: true, Runs: []Run{
+			{Text: `Escapes: \ { } and a tab	and a line` + "\nbreak"},
+		}},
+		{Bullet: true, Runs: []Run{
+			{Text: "€ “quoted” – dashed", Style: Style{Color: red}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := doc.WriteRTF(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range buf.String() {
+		if r >= 0x80 {
+			t.Fatalf("got non-ASCII character %q in RTF %s", r, buf.String())
+		}
+	}
+
+	got, err := ReadRTF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("got %+v, want %+v", got, doc)
+	}
+}
+
+func TestReadRTF(t *testing.T) {
+	tests := []struct {
+		name string
+		rtf  string
+		want string
+	}{
+		{"escapes", `{\rtf1 a\'e9\~b\{\}\\\par}`, "aé\u00a0b{}\\"},
+		{"unicode with fallback", `{\rtf1\uc1 \u20013?\u25991?\par}`, "中文"},
+		{"unicode without fallback", `{\rtf1\uc0 \u-10179\u-8704 !\par}`, "😀!"},
+		{"longer fallback", `{\rtf1\uc2 \u8364\'80\'80 EUR\par}`, "€ EUR"},
+		{"uc is scoped", `{\rtf1{\uc2 \u8364 xx}\u8364 y\par}`, "€€"},
+		{"skipped destinations", `{\rtf1{\*\unknown hidden}{\info{\title T}}{\fonttbl{\f0 Arial;}}shown\par}`, "shown"},
+		{"binary data", `{\rtf1 x\bin3 {}\ y{\pict\bin5 }}{{{} z\par}`, "x y z"},
+		{"binary data in ignored group", `{\rtf1{\*\blipuid\bin2 \}}ok\par}`, "ok"},
+		{"field results", `{\rtf1{\field{\*\fldinst HYPERLINK "x"}{\fldrslt link}}\par}`, "link"},
+		{"paragraphs", `{\rtf1 one\par two\line three\par}`, "one\ntwo\nthree"},
+	}
+
+	for _, test := range tests {
+		doc, err := ReadRTF(strings.NewReader(test.rtf))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if got := doc.Text(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestReadRTFFormatting(t *testing.T) {
+	rtf := `{\rtf1{\fonttbl{\f0 Arial;}{\f1\fnil Courier New;}}{\colortbl ;\red0\green128\blue0;}` +
+		`\pard\qc{\b\i0 bold}{\f1\fs18\cf1 code}\b0\par}`
+
+	doc, err := ReadRTF(strings.NewReader(rtf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Document{Paragraphs: []Paragraph{
+		{Alignment: AlignCenter, Runs: []Run{
+			{Text: "bold", Style: Style{Bold: true}},
+			{Text: "code", Style: Style{Font: "Courier New", Size: 9, Color: color.NRGBA{0, 128, 0, 0xff}}},
+		}},
+	}}
+
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("got %+v, want %+v", doc, want)
+	}
+}
+
+func TestReadRTFRejectsOtherFormats(t *testing.T) {
+	if _, err := ReadRTF(strings.NewReader("<p>HTML</p>")); err != errNoRTF {
+		t.Errorf("got %v, want %v", err, errNoRTF)
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"bufio"
+	"errors"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ReadRTF reads an RTF document from r.
+//
+// Only what Document can represent is kept: character formatting, alignment,
+// bullets and the text. Pictures, objects, tables and other destinations
+// are skipped, except for their text where it is shown.
+func ReadRTF(r io.Reader) (*Document, error) {
+	rr := rtfReader{r: bufio.NewReader(r), fonts: make(map[int]string)}
+
+	if err := rr.read(); err != nil {
+		return nil, err
+	}
+
+	return &rr.doc, nil
+}
+
+var errNoRTF = errors.New("richtext: not an RTF document")
+
+// rtfDestination is what the text of a group goes to.
+type rtfDestination int
+
+const (
+	rtfText rtfDestination = iota
+	rtfFontTable
+	rtfColorTable
+	rtfSkip
+)
+
+// rtfState is the state that RTF groups save and restore.
+type rtfState struct {
+	dest        rtfDestination
+	style       Style
+	fontIndex   int
+	skipPerChar int // The number of characters to skip after \u.
+}
+
+type rtfReader struct {
+	r      *bufio.Reader
+	doc    Document
+	para   Paragraph
+	state  rtfState
+	stack  []rtfState
+	fonts  map[int]string
+	colors []color.NRGBA // Index 0 is the default color.
+
+	// While reading the font or color table.
+	fontName strings.Builder
+	color    color.NRGBA
+
+	skipChars  int // The number of characters left to skip after \u.
+	highSurr   rune
+	ignoreNext bool // After \*, the group is skipped unless known.
+}
+
+func (rr *rtfReader) read() error {
+	if b, err := rr.r.Peek(5); err != nil || string(b) != `{\rtf` {
+		return errNoRTF
+	}
+
+	rr.state.skipPerChar = 1
+
+loop:
+	for {
+		c, err := rr.r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch c {
+		case '{':
+			rr.stack = append(rr.stack, rr.state)
+
+		case '}':
+			rr.endGroup()
+
+			rr.state = rr.stack[len(rr.stack)-1]
+			rr.stack = rr.stack[:len(rr.stack)-1]
+
+			if len(rr.stack) == 0 {
+				// The end of the document.
+				break loop
+			}
+
+		case '\\':
+			if err := rr.control(); err != nil {
+				return err
+			}
+
+		case '\r', '\n':
+
+		default:
+			rr.char(rune(c))
+		}
+	}
+
+	if len(rr.para.Runs) > 0 {
+		rr.endParagraph()
+	}
+
+	return nil
+}
+
+// endGroup finishes the font or color table entry of a group, if any.
+func (rr *rtfReader) endGroup() {
+	if rr.state.dest == rtfFontTable && rr.fontName.Len() > 0 {
+		rr.addFont()
+	}
+}
+
+func (rr *rtfReader) addFont() {
+	name := strings.TrimSpace(strings.TrimSuffix(rr.fontName.String(), ";"))
+	if _, ok := rr.fonts[rr.state.fontIndex]; !ok && name != "" {
+		rr.fonts[rr.state.fontIndex] = name
+	}
+
+	rr.fontName.Reset()
+}
+
+// control reads and handles a control word or symbol, after its backslash.
+func (rr *rtfReader) control() error {
+	c, err := rr.r.ReadByte()
+	if err != nil {
+		return nil
+	}
+
+	if !isLetter(c) {
+		switch c {
+		case '\'':
+			hex := make([]byte, 2)
+			if _, err := io.ReadFull(rr.r, hex); err != nil {
+				return nil
+			}
+			if v, err := strconv.ParseUint(string(hex), 16, 8); err == nil {
+				rr.char(cp1252ToRune(byte(v)))
+			}
+
+		case '*':
+			rr.ignoreNext = true
+
+		case '~':
+			rr.char(' ')
+
+		case '_':
+			rr.char('‑')
+
+		case '\\', '{', '}':
+			rr.char(rune(c))
+
+		case '\r', '\n':
+			rr.paragraphBreak()
+		}
+
+		return nil
+	}
+
+	word := []byte{c}
+	for {
+		c, err := rr.r.ReadByte()
+		if err != nil {
+			break
+		}
+		if !isLetter(c) {
+			rr.r.UnreadByte()
+			break
+		}
+		word = append(word, c)
+	}
+
+	param, hasParam := 0, false
+	var digits []byte
+	if c, err := rr.r.ReadByte(); err == nil {
+		if c == '-' || isDigit(c) {
+			digits = append(digits, c)
+			for {
+				c, err := rr.r.ReadByte()
+				if err != nil {
+					break
+				}
+				if !isDigit(c) {
+					rr.r.UnreadByte()
+					break
+				}
+				digits = append(digits, c)
+			}
+			param, err = strconv.Atoi(string(digits))
+			hasParam = err == nil
+		} else {
+			rr.r.UnreadByte()
+		}
+	}
+
+	// A space delimits the control word and is no text.
+	if c, err := rr.r.ReadByte(); err == nil && c != ' ' {
+		rr.r.UnreadByte()
+	}
+
+	rr.word(string(word), param, hasParam)
+
+	return nil
+}
+
+// word handles a control word.
+func (rr *rtfReader) word(word string, param int, hasParam bool) {
+	ignore := rr.ignoreNext
+	rr.ignoreNext = false
+
+	if word == "bin" {
+		// Binary data, like of pictures, which may contain anything, even
+		// braces and backslashes.
+		if param > 0 {
+			rr.r.Discard(param)
+		}
+		return
+	}
+
+	if rr.state.dest == rtfSkip {
+		return
+	}
+
+	on := !hasParam || param != 0
+
+	switch word {
+	case "fonttbl":
+		rr.state.dest = rtfFontTable
+
+	case "colortbl":
+		rr.state.dest = rtfColorTable
+		rr.color = color.NRGBA{}
+
+	case "red":
+		rr.color.R = uint8(param)
+
+	case "green":
+		rr.color.G = uint8(param)
+
+	case "blue":
+		rr.color.B = uint8(param)
+		rr.color.A = 0xff
+
+	case "pntext", "listtext":
+		// The bullet or number, as text for readers without list support.
+		rr.para.Bullet = true
+		rr.state.dest = rtfSkip
+
+	case "pn":
+		rr.state.dest = rtfSkip
+
+	case "pnlvlblt":
+		rr.para.Bullet = true
+
+	case "ls":
+		rr.para.Bullet = param > 0
+
+	case "stylesheet", "info", "pict", "object", "header", "footer",
+		"headerl", "headerr", "headerf", "footerl", "footerr", "footerf",
+		"footnote", "annotation", "fldinst", "themedata", "colorschememapping",
+		"latentstyles", "datastore", "xmlnstbl", "listtable", "listoverridetable",
+		"rsidtbl", "generator", "mmathPr", "bkmkstart", "bkmkend":
+		rr.state.dest = rtfSkip
+
+	case "fldrslt":
+		// The shown result of a field, like the text of a link.
+
+	case "par":
+		rr.paragraphBreak()
+
+	case "pard":
+		rr.para.Alignment = AlignLeft
+		rr.para.Bullet = false
+
+	case "ql":
+		rr.para.Alignment = AlignLeft
+
+	case "qc":
+		rr.para.Alignment = AlignCenter
+
+	case "qr":
+		rr.para.Alignment = AlignRight
+
+	case "qj":
+		rr.para.Alignment = AlignJustify
+
+	case "plain":
+		rr.state.style = Style{}
+
+	case "b":
+		rr.state.style.Bold = on
+
+	case "i":
+		rr.state.style.Italic = on
+
+	case "ul":
+		rr.state.style.Underline = on
+
+	case "ulnone":
+		rr.state.style.Underline = false
+
+	case "strike":
+		rr.state.style.Strikeout = on
+
+	case "cf":
+		if param > 0 && param < len(rr.colors) {
+			rr.state.style.Color = rr.colors[param]
+		} else {
+			rr.state.style.Color = color.NRGBA{}
+		}
+
+	case "f":
+		if rr.state.dest == rtfFontTable {
+			if rr.fontName.Len() > 0 {
+				rr.addFont()
+			}
+			rr.state.fontIndex = param
+		} else {
+			rr.state.style.Font = rr.fonts[param]
+		}
+
+	case "fs":
+		rr.state.style.Size = float64(param) / 2
+
+	case "line":
+		rr.char('\n')
+
+	case "tab":
+		rr.char('\t')
+
+	case "emdash":
+		rr.char('—')
+
+	case "endash":
+		rr.char('–')
+
+	case "bullet":
+		rr.char('•')
+
+	case "lquote":
+		rr.char('‘')
+
+	case "rquote":
+		rr.char('’')
+
+	case "ldblquote":
+		rr.char('“')
+
+	case "rdblquote":
+		rr.char('”')
+
+	case "uc":
+		rr.state.skipPerChar = param
+
+	case "u":
+		r := rune(uint16(int16(param)))
+		rr.char(r)
+		rr.skipChars = rr.state.skipPerChar
+
+	default:
+		if ignore {
+			rr.state.dest = rtfSkip
+		}
+	}
+}
+
+// char handles a character of text.
+func (rr *rtfReader) char(r rune) {
+	if rr.skipChars > 0 {
+		rr.skipChars--
+		return
+	}
+
+	switch rr.state.dest {
+	case rtfFontTable:
+		rr.fontName.WriteRune(r)
+		if r == ';' {
+			rr.addFont()
+		}
+
+	case rtfColorTable:
+		if r == ';' {
+			rr.colors = append(rr.colors, rr.color)
+			rr.color = color.NRGBA{}
+		}
+
+	case rtfText:
+		if utf16.IsSurrogate(r) {
+			if rr.highSurr == 0 {
+				rr.highSurr = r
+				return
+			}
+			r = utf16.DecodeRune(rr.highSurr, r)
+		}
+		rr.highSurr = 0
+
+		rr.para.AppendText(string(r), rr.state.style)
+	}
+}
+
+func (rr *rtfReader) paragraphBreak() {
+	if rr.state.dest == rtfText {
+		rr.endParagraph()
+	}
+}
+
+// endParagraph adds the current paragraph to the document and starts a new
+// one with the same paragraph formatting.
+func (rr *rtfReader) endParagraph() {
+	rr.doc.Paragraphs = append(rr.doc.Paragraphs, rr.para)
+
+	rr.para = Paragraph{Alignment: rr.para.Alignment, Bullet: rr.para.Bullet}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// cp1252 are the characters of Windows code page 1252 from 0x80 to 0x9f,
+// where it differs from Latin-1.
+var cp1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+func cp1252ToRune(b byte) rune {
+	if b >= 0x80 && b < 0xa0 {
+		return cp1252[b-0x80]
+	}
+
+	return rune(b)
+}
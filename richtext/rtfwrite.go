@@ -0,0 +1,193 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// WriteRTF writes the document to w as RTF.
+func (d *Document) WriteRTF(w io.Writer) error {
+	rw := rtfWriter{w: bufio.NewWriter(w)}
+
+	return rw.write(d)
+}
+
+type rtfWriter struct {
+	w      *bufio.Writer
+	fonts  []string
+	colors []color.NRGBA
+}
+
+func (rw *rtfWriter) write(d *Document) error {
+	// The first font is the default one, the last one draws bullets.
+	rw.fonts = []string{""}
+	rw.colors = nil
+
+	for _, p := range d.Paragraphs {
+		for _, run := range p.Runs {
+			rw.fontIndex(run.Style.Font)
+			rw.colorIndex(run.Style.Color)
+		}
+	}
+
+	bulletFont := len(rw.fonts)
+
+	rw.printf(`{\rtf1\ansi\ansicpg1252\deff0\uc1{\fonttbl`)
+	for i, font := range rw.fonts {
+		if font == "" {
+			font = "Arial"
+		}
+		rw.printf(`{\f%d\fnil\fcharset0 `, i)
+		rw.text(font)
+		rw.printf(`;}`)
+	}
+	rw.printf(`{\f%d\fnil\fcharset2 Symbol;}}`, bulletFont)
+
+	rw.printf(`{\colortbl ;`)
+	for _, c := range rw.colors {
+		rw.printf(`\red%d\green%d\blue%d;`, c.R, c.G, c.B)
+	}
+	rw.printf("}\n")
+
+	for _, p := range d.Paragraphs {
+		rw.printf(`\pard`)
+
+		switch p.Alignment {
+		case AlignCenter:
+			rw.printf(`\qc`)
+
+		case AlignRight:
+			rw.printf(`\qr`)
+
+		case AlignJustify:
+			rw.printf(`\qj`)
+		}
+
+		if p.Bullet {
+			rw.printf(`{\pntext\f%d\'B7\tab}{\*\pn\pnlvlblt\pnf%d\pnindent0{\pntxtb\'B7}}\fi-360\li720`, bulletFont, bulletFont)
+		}
+
+		rw.printf(" ")
+
+		for _, run := range p.Runs {
+			rw.run(run)
+		}
+
+		rw.printf("\\par\n")
+	}
+
+	rw.printf("}\n")
+
+	return rw.w.Flush()
+}
+
+func (rw *rtfWriter) run(run Run) {
+	style := run.Style
+
+	var controls []string
+	if style.Bold {
+		controls = append(controls, `\b`)
+	}
+	if style.Italic {
+		controls = append(controls, `\i`)
+	}
+	if style.Underline {
+		controls = append(controls, `\ul`)
+	}
+	if style.Strikeout {
+		controls = append(controls, `\strike`)
+	}
+	if style.Color.A != 0 {
+		controls = append(controls, fmt.Sprintf(`\cf%d`, rw.colorIndex(style.Color)))
+	}
+	if style.Font != "" {
+		controls = append(controls, fmt.Sprintf(`\f%d`, rw.fontIndex(style.Font)))
+	}
+	if style.Size > 0 {
+		controls = append(controls, fmt.Sprintf(`\fs%d`, int(math.Round(style.Size*2))))
+	}
+
+	rw.printf(`{`)
+	if len(controls) > 0 {
+		// The space delimits the last control word.
+		rw.printf("%s ", strings.Join(controls, ""))
+	}
+	rw.text(run.Text)
+	rw.printf(`}`)
+}
+
+// text writes s, escaping what RTF does not allow literally.
+func (rw *rtfWriter) text(s string) {
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '{' || r == '}':
+			rw.w.WriteByte('\\')
+			rw.w.WriteRune(r)
+
+		case r == '\n':
+			rw.printf(`\line `)
+
+		case r == '\t':
+			rw.printf(`\tab `)
+
+		case r == '\r':
+
+		case r < 0x80:
+			rw.w.WriteRune(r)
+
+		default:
+			// \u takes a signed 16-bit value, followed by one character for
+			// readers that don't know it.
+			for _, u := range utf16.Encode([]rune{r}) {
+				rw.printf(`\u%d?`, int16(u))
+			}
+		}
+	}
+}
+
+func (rw *rtfWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(rw.w, format, args...)
+}
+
+// fontIndex returns the index of font in the font table, adding it if
+// needed.
+func (rw *rtfWriter) fontIndex(font string) int {
+	for i, f := range rw.fonts {
+		if f == font {
+			return i
+		}
+	}
+
+	rw.fonts = append(rw.fonts, font)
+
+	return len(rw.fonts) - 1
+}
+
+// colorIndex returns the index of c in the color table, adding it if needed.
+// Index 0 is the default color.
+func (rw *rtfWriter) colorIndex(c color.NRGBA) int {
+	if c.A == 0 {
+		return 0
+	}
+
+	c.A = 0xff
+
+	for i, col := range rw.colors {
+		if col == c {
+			return i + 1
+		}
+	}
+
+	rw.colors = append(rw.colors, c)
+
+	return len(rw.colors)
+}
@@ -0,0 +1,657 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/lxn/walk/richtext"
+	"github.com/lxn/win"
+)
+
+var libmsftedit = windows.NewLazySystemDLL("msftedit.dll")
+
+var richTextEditStreamCallbackPtr uintptr
+
+func init() {
+	AppendToWalkInit(func() {
+		richTextEditStreamCallbackPtr = syscall.NewCallback(richTextEditStreamCallback)
+	})
+}
+
+// RichTextEdit is a multiline text editor for formatted text, based on the
+// RichEdit control.
+//
+// It edits the character formatting and paragraph formatting of the
+// selection, reads and writes RTF, and converts a safe subset of HTML.
+type RichTextEdit struct {
+	WidgetBase
+	readOnlyChangedPublisher  EventPublisher
+	textChangedPublisher      EventPublisher
+	selectionChangedPublisher EventPublisher
+}
+
+func NewRichTextEdit(parent Container) (*RichTextEdit, error) {
+	return NewRichTextEditWithStyle(parent, 0)
+}
+
+func NewRichTextEditWithStyle(parent Container, style uint32) (*RichTextEdit, error) {
+	if err := libmsftedit.Load(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	rte := new(RichTextEdit)
+
+	if err := InitWidget(
+		rte,
+		parent,
+		win.MSFTEDIT_CLASS,
+		win.WS_TABSTOP|win.WS_VISIBLE|win.WS_VSCROLL|win.ES_MULTILINE|win.ES_WANTRETURN|win.ES_AUTOVSCROLL|style,
+		win.WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	rte.SendMessage(win.EM_SETEVENTMASK, 0, win.ENM_CHANGE|win.ENM_SELCHANGE)
+
+	rte.GraphicsEffects().Add(InteractionEffect)
+	rte.GraphicsEffects().Add(FocusEffect)
+
+	rte.MustRegisterProperty("ReadOnly", NewProperty(
+		func() interface{} {
+			return rte.ReadOnly()
+		},
+		func(v interface{}) error {
+			return rte.SetReadOnly(v.(bool))
+		},
+		rte.readOnlyChangedPublisher.Event()))
+
+	rte.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return rte.Text()
+		},
+		func(v interface{}) error {
+			return rte.SetText(assertStringOr(v, ""))
+		},
+		rte.textChangedPublisher.Event()))
+
+	return rte, nil
+}
+
+// Text returns the text without formatting, with paragraphs separated by
+// "\r\n".
+func (rte *RichTextEdit) Text() string {
+	gtl := win.GETTEXTLENGTHEX{Flags: win.GTL_USECRLF | win.GTL_PRECISE | win.GTL_NUMCHARS, Codepage: 1200}
+	length := int(rte.SendMessage(win.EM_GETTEXTLENGTHEX, uintptr(unsafe.Pointer(&gtl)), 0))
+	if length <= 0 {
+		return ""
+	}
+
+	buf := make([]uint16, length+1)
+	gt := win.GETTEXTEX{Cb: uint32(len(buf) * 2), Flags: win.GT_USECRLF, Codepage: 1200}
+	rte.SendMessage(win.EM_GETTEXTEX, uintptr(unsafe.Pointer(&gt)), uintptr(unsafe.Pointer(&buf[0])))
+
+	return syscall.UTF16ToString(buf)
+}
+
+// SetText replaces the content with text, without formatting. Unlike with
+// SetRTF, text that looks like RTF is not interpreted.
+func (rte *RichTextEdit) SetText(text string) error {
+	u := utf16.Encode([]rune(text))
+
+	// SF_UNICODE expects UTF-16LE.
+	b := make([]byte, len(u)*2)
+	for i, c := range u {
+		b[i*2] = byte(c)
+		b[i*2+1] = byte(c >> 8)
+	}
+
+	return rte.streamIn(bytes.NewReader(b), win.SF_TEXT|win.SF_UNICODE)
+}
+
+func (rte *RichTextEdit) TextLength() int {
+	gtl := win.GETTEXTLENGTHEX{Flags: win.GTL_PRECISE | win.GTL_NUMCHARS, Codepage: 1200}
+
+	return int(rte.SendMessage(win.EM_GETTEXTLENGTHEX, uintptr(unsafe.Pointer(&gtl)), 0))
+}
+
+// RTF returns the content as RTF.
+func (rte *RichTextEdit) RTF() (string, error) {
+	var buf bytes.Buffer
+
+	if err := rte.WriteRTF(&buf); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// SetRTF replaces the content with the RTF document rtf.
+func (rte *RichTextEdit) SetRTF(rtf string) error {
+	return rte.ReadRTF(strings.NewReader(rtf))
+}
+
+// ReadRTF replaces the content with the RTF document read from r.
+func (rte *RichTextEdit) ReadRTF(r io.Reader) error {
+	return rte.streamIn(r, win.SF_RTF)
+}
+
+// WriteRTF writes the content to w as RTF.
+func (rte *RichTextEdit) WriteRTF(w io.Writer) error {
+	return rte.streamOut(w, win.SF_RTF)
+}
+
+// Document returns the content as a richtext.Document.
+func (rte *RichTextEdit) Document() (*richtext.Document, error) {
+	var buf bytes.Buffer
+
+	if err := rte.WriteRTF(&buf); err != nil {
+		return nil, err
+	}
+
+	doc, err := richtext.ReadRTF(&buf)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return doc, nil
+}
+
+// SetDocument replaces the content with doc. Runs in the default font are
+// shown in the font of the RichTextEdit.
+func (rte *RichTextEdit) SetDocument(doc *richtext.Document) error {
+	if font := rte.Font(); font != nil {
+		d := *doc
+		d.Paragraphs = make([]richtext.Paragraph, len(doc.Paragraphs))
+		for i, p := range doc.Paragraphs {
+			d.Paragraphs[i] = p
+			d.Paragraphs[i].Runs = append([]richtext.Run(nil), p.Runs...)
+		}
+		d.SetDefaultFont(font.Family(), float64(font.PointSize()))
+
+		doc = &d
+	}
+
+	var buf bytes.Buffer
+
+	if err := doc.WriteRTF(&buf); err != nil {
+		return wrapError(err)
+	}
+
+	return rte.ReadRTF(&buf)
+}
+
+// HTML returns the content as an HTML fragment.
+func (rte *RichTextEdit) HTML() (string, error) {
+	doc, err := rte.Document()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err := doc.WriteHTML(&buf); err != nil {
+		return "", wrapError(err)
+	}
+
+	return buf.String(), nil
+}
+
+// SetHTML replaces the content with the HTML document or fragment html.
+//
+// Only the safe subset of HTML that richtext.ReadHTML understands is
+// converted. Scripts, styles, images and links are dropped.
+func (rte *RichTextEdit) SetHTML(html string) error {
+	doc, err := richtext.ReadHTML(strings.NewReader(html))
+	if err != nil {
+		return wrapError(err)
+	}
+
+	return rte.SetDocument(doc)
+}
+
+// richTextEditStream is the reader or writer of a running stream operation.
+type richTextEditStream struct {
+	r   io.Reader
+	w   io.Writer
+	err error
+}
+
+// richTextEditStreams are the running stream operations by cookie.
+var richTextEditStreams = make(map[uintptr]*richTextEditStream)
+var richTextEditStreamCookie uintptr
+
+func (rte *RichTextEdit) streamIn(r io.Reader, format uint32) error {
+	if err := rte.stream(win.EM_STREAMIN, &richTextEditStream{r: r}, format); err != nil {
+		return err
+	}
+
+	rte.textChangedPublisher.Publish()
+
+	return nil
+}
+
+func (rte *RichTextEdit) streamOut(w io.Writer, format uint32) error {
+	return rte.stream(win.EM_STREAMOUT, &richTextEditStream{w: w}, format)
+}
+
+func (rte *RichTextEdit) stream(msg uint32, stream *richTextEditStream, format uint32) error {
+	richTextEditStreamCookie++
+	cookie := richTextEditStreamCookie
+
+	richTextEditStreams[cookie] = stream
+	defer delete(richTextEditStreams, cookie)
+
+	es := win.EDITSTREAM{
+		DwCookie:    cookie,
+		PfnCallback: richTextEditStreamCallbackPtr,
+	}
+
+	rte.SendMessage(msg, uintptr(format), uintptr(unsafe.Pointer(&es)))
+
+	if es.DwError != 0 {
+		if stream.err != nil {
+			return wrapError(stream.err)
+		}
+
+		return newError("streaming failed")
+	}
+
+	return nil
+}
+
+func richTextEditStreamCallback(cookie uintptr, buf *byte, cb int32, pcb *int32) uintptr {
+	data := (*[1 << 30]byte)(unsafe.Pointer(buf))[:cb:cb]
+
+	stream := richTextEditStreams[cookie]
+
+	var n int
+	var err error
+
+	if stream.r != nil {
+		n, err = io.ReadFull(stream.r, data)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+	} else {
+		n, err = stream.w.Write(data)
+	}
+
+	*pcb = int32(n)
+
+	if err != nil {
+		// The control reports failing as DwError.
+		stream.err = err
+		return 1
+	}
+
+	return 0
+}
+
+func (rte *RichTextEdit) TextSelection() (start, end int) {
+	var cr win.CHARRANGE
+	rte.SendMessage(win.EM_EXGETSEL, 0, uintptr(unsafe.Pointer(&cr)))
+
+	return int(cr.CpMin), int(cr.CpMax)
+}
+
+func (rte *RichTextEdit) SetTextSelection(start, end int) {
+	cr := win.CHARRANGE{CpMin: int32(start), CpMax: int32(end)}
+	rte.SendMessage(win.EM_EXSETSEL, 0, uintptr(unsafe.Pointer(&cr)))
+}
+
+func (rte *RichTextEdit) ReplaceSelectedText(text string, canUndo bool) {
+	rte.SendMessage(win.EM_REPLACESEL,
+		uintptr(win.BoolToBOOL(canUndo)),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text))))
+}
+
+func (rte *RichTextEdit) ScrollToCaret() {
+	rte.SendMessage(win.EM_SCROLLCARET, 0, 0)
+}
+
+func (rte *RichTextEdit) ReadOnly() bool {
+	return rte.hasStyleBits(win.ES_READONLY)
+}
+
+func (rte *RichTextEdit) SetReadOnly(readOnly bool) error {
+	if 0 == rte.SendMessage(win.EM_SETREADONLY, uintptr(win.BoolToBOOL(readOnly)), 0) {
+		return newError("SendMessage(EM_SETREADONLY)")
+	}
+
+	rte.readOnlyChangedPublisher.Publish()
+
+	return nil
+}
+
+func (rte *RichTextEdit) selectionCharFormat() *win.CHARFORMAT2 {
+	cf := new(win.CHARFORMAT2)
+	cf.CbSize = uint32(unsafe.Sizeof(*cf))
+
+	rte.SendMessage(win.EM_GETCHARFORMAT, win.SCF_SELECTION, uintptr(unsafe.Pointer(cf)))
+
+	return cf
+}
+
+func (rte *RichTextEdit) setSelectionCharFormat(cf *win.CHARFORMAT2) error {
+	cf.CbSize = uint32(unsafe.Sizeof(*cf))
+
+	if 0 == rte.SendMessage(win.EM_SETCHARFORMAT, win.SCF_SELECTION, uintptr(unsafe.Pointer(cf))) {
+		return newError("SendMessage(EM_SETCHARFORMAT)")
+	}
+
+	return nil
+}
+
+var fontStyle2CharFormatEffect = map[FontStyle]uint32{
+	FontBold:      win.CFE_BOLD,
+	FontItalic:    win.CFE_ITALIC,
+	FontUnderline: win.CFE_UNDERLINE,
+	FontStrikeOut: win.CFE_STRIKEOUT,
+}
+
+// SelectionFontStyle returns the font styles that all of the selection has.
+func (rte *RichTextEdit) SelectionFontStyle() FontStyle {
+	cf := rte.selectionCharFormat()
+
+	var style FontStyle
+	for fs, effect := range fontStyle2CharFormatEffect {
+		if cf.DwMask&effect != 0 && cf.DwEffects&effect != 0 {
+			style |= fs
+		}
+	}
+
+	return style
+}
+
+// SetSelectionFontStyle turns the font styles style of the selection on or
+// off.
+func (rte *RichTextEdit) SetSelectionFontStyle(style FontStyle, on bool) error {
+	var cf win.CHARFORMAT2
+
+	for fs, effect := range fontStyle2CharFormatEffect {
+		if style&fs != 0 {
+			cf.DwMask |= effect
+			if on {
+				cf.DwEffects |= effect
+			}
+		}
+	}
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+// SelectionFontFamily returns the font family of the selection, or an empty
+// string if it has several.
+func (rte *RichTextEdit) SelectionFontFamily() string {
+	cf := rte.selectionCharFormat()
+	if cf.DwMask&win.CFM_FACE == 0 {
+		return ""
+	}
+
+	return syscall.UTF16ToString(cf.SzFaceName[:])
+}
+
+func (rte *RichTextEdit) SetSelectionFontFamily(family string) error {
+	var cf win.CHARFORMAT2
+	cf.DwMask = win.CFM_FACE
+
+	face := syscall.StringToUTF16(family)
+	if len(face) > len(cf.SzFaceName) {
+		return newError("family name too long")
+	}
+	copy(cf.SzFaceName[:], face)
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+// SelectionFontPointSize returns the font size of the selection in points,
+// or 0 if it has several.
+func (rte *RichTextEdit) SelectionFontPointSize() int {
+	cf := rte.selectionCharFormat()
+	if cf.DwMask&win.CFM_SIZE == 0 {
+		return 0
+	}
+
+	// YHeight is in twips.
+	return int(cf.YHeight+10) / 20
+}
+
+func (rte *RichTextEdit) SetSelectionFontPointSize(pointSize int) error {
+	var cf win.CHARFORMAT2
+	cf.DwMask = win.CFM_SIZE
+	cf.YHeight = int32(pointSize * 20)
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+// SelectionTextColor returns the text color of the selection. ok is false if
+// it has several or the default one.
+func (rte *RichTextEdit) SelectionTextColor() (color Color, ok bool) {
+	cf := rte.selectionCharFormat()
+	if cf.DwMask&win.CFM_COLOR == 0 || cf.DwEffects&win.CFE_AUTOCOLOR != 0 {
+		return 0, false
+	}
+
+	return Color(cf.CrTextColor), true
+}
+
+func (rte *RichTextEdit) SetSelectionTextColor(color Color) error {
+	var cf win.CHARFORMAT2
+	cf.DwMask = win.CFM_COLOR
+	cf.CrTextColor = win.COLORREF(color)
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+// ResetSelectionTextColor makes the selection use the default text color.
+func (rte *RichTextEdit) ResetSelectionTextColor() error {
+	var cf win.CHARFORMAT2
+	cf.DwMask = win.CFM_COLOR
+	cf.DwEffects = win.CFE_AUTOCOLOR
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+func (rte *RichTextEdit) selectionParaFormat() *win.PARAFORMAT2 {
+	pf := new(win.PARAFORMAT2)
+	pf.CbSize = uint32(unsafe.Sizeof(*pf))
+
+	rte.SendMessage(win.EM_GETPARAFORMAT, 0, uintptr(unsafe.Pointer(pf)))
+
+	return pf
+}
+
+func (rte *RichTextEdit) setSelectionParaFormat(pf *win.PARAFORMAT2) error {
+	pf.CbSize = uint32(unsafe.Sizeof(*pf))
+
+	if 0 == rte.SendMessage(win.EM_SETPARAFORMAT, 0, uintptr(unsafe.Pointer(pf))) {
+		return newError("SendMessage(EM_SETPARAFORMAT)")
+	}
+
+	return nil
+}
+
+// SelectionAlignment returns the alignment of the paragraphs of the
+// selection.
+func (rte *RichTextEdit) SelectionAlignment() Alignment1D {
+	switch rte.selectionParaFormat().WAlignment {
+	case win.PFA_CENTER:
+		return AlignCenter
+
+	case win.PFA_RIGHT:
+		return AlignFar
+	}
+
+	return AlignNear
+}
+
+// SetSelectionAlignment aligns the paragraphs of the selection.
+func (rte *RichTextEdit) SetSelectionAlignment(alignment Alignment1D) error {
+	var pf win.PARAFORMAT2
+	pf.DwMask = win.PFM_ALIGNMENT
+
+	switch alignment {
+	case AlignCenter:
+		pf.WAlignment = win.PFA_CENTER
+
+	case AlignFar:
+		pf.WAlignment = win.PFA_RIGHT
+
+	default:
+		pf.WAlignment = win.PFA_LEFT
+	}
+
+	return rte.setSelectionParaFormat(&pf)
+}
+
+// SelectionBulleted returns if the paragraphs of the selection are bulleted.
+func (rte *RichTextEdit) SelectionBulleted() bool {
+	return rte.selectionParaFormat().WNumbering == win.PFN_BULLET
+}
+
+// SetSelectionBulleted makes the paragraphs of the selection bulleted or not.
+func (rte *RichTextEdit) SetSelectionBulleted(bulleted bool) error {
+	var pf win.PARAFORMAT2
+	pf.DwMask = win.PFM_NUMBERING | win.PFM_OFFSET | win.PFM_STARTINDENT
+
+	if bulleted {
+		pf.WNumbering = win.PFN_BULLET
+		// 360 twips, a quarter inch, like other editors.
+		pf.DxStartIndent = 360
+		pf.DxOffset = 360
+	}
+
+	return rte.setSelectionParaFormat(&pf)
+}
+
+// Find selects the next match of text after the selection, or the previous
+// one before it if opts.Backward is set. The search wraps around. It
+// returns whether there was a match.
+func (rte *RichTextEdit) Find(text string, opts FindOptions) bool {
+	return findInTextTarget(rte, text, opts)
+}
+
+// Replace replaces the selection with replacement if it is a match of text,
+// then selects the next one like Find. It returns whether there was one.
+func (rte *RichTextEdit) Replace(text, replacement string, opts FindOptions) bool {
+	return replaceInTextTarget(rte, text, replacement, opts)
+}
+
+// ReplaceAll replaces all matches of text with replacement, returning how
+// many were replaced. opts.Backward is ignored.
+func (rte *RichTextEdit) ReplaceAll(text, replacement string, opts FindOptions) int {
+	return replaceAllInTextTarget(rte, text, replacement, opts)
+}
+
+// findText returns the text with paragraphs ending in a single CR, as the
+// positions of the selection count them.
+func (rte *RichTextEdit) findText() string {
+	gtl := win.GETTEXTLENGTHEX{Flags: win.GTL_PRECISE | win.GTL_NUMCHARS, Codepage: 1200}
+	length := int(rte.SendMessage(win.EM_GETTEXTLENGTHEX, uintptr(unsafe.Pointer(&gtl)), 0))
+	if length <= 0 {
+		return ""
+	}
+
+	buf := make([]uint16, length+1)
+	gt := win.GETTEXTEX{Cb: uint32(len(buf) * 2), Codepage: 1200}
+	rte.SendMessage(win.EM_GETTEXTEX, uintptr(unsafe.Pointer(&gt)), uintptr(unsafe.Pointer(&buf[0])))
+
+	return syscall.UTF16ToString(buf)
+}
+
+func (rte *RichTextEdit) CanUndo() bool {
+	return rte.SendMessage(win.EM_CANUNDO, 0, 0) != 0
+}
+
+func (rte *RichTextEdit) Undo() bool {
+	return rte.SendMessage(win.EM_UNDO, 0, 0) != 0
+}
+
+func (rte *RichTextEdit) CanRedo() bool {
+	return rte.SendMessage(win.EM_CANREDO, 0, 0) != 0
+}
+
+func (rte *RichTextEdit) Redo() bool {
+	return rte.SendMessage(win.EM_REDO, 0, 0) != 0
+}
+
+// Zoom returns the factor by which the content is scaled.
+func (rte *RichTextEdit) Zoom() float64 {
+	var numerator, denominator int32
+	rte.SendMessage(win.EM_GETZOOM, uintptr(unsafe.Pointer(&numerator)), uintptr(unsafe.Pointer(&denominator)))
+
+	if numerator == 0 || denominator == 0 {
+		return 1
+	}
+
+	return float64(numerator) / float64(denominator)
+}
+
+// SetZoom scales the content by zoom, which must be between 1/64 and 64.
+func (rte *RichTextEdit) SetZoom(zoom float64) error {
+	if zoom <= 1.0/64 || zoom >= 64 {
+		return newError("zoom out of range")
+	}
+
+	const denominator = 1000
+
+	if 0 == rte.SendMessage(win.EM_SETZOOM, uintptr(zoom*denominator+0.5), denominator) {
+		return newError("SendMessage(EM_SETZOOM)")
+	}
+
+	return nil
+}
+
+func (rte *RichTextEdit) TextChanged() *Event {
+	return rte.textChangedPublisher.Event()
+}
+
+func (rte *RichTextEdit) SelectionChanged() *Event {
+	return rte.selectionChangedPublisher.Event()
+}
+
+func (*RichTextEdit) NeedsWmSize() bool {
+	return true
+}
+
+func (rte *RichTextEdit) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case win.WM_COMMAND:
+		switch win.HIWORD(uint32(wParam)) {
+		case win.EN_CHANGE:
+			rte.textChangedPublisher.Publish()
+		}
+
+	case win.WM_NOTIFY:
+		switch ((*win.NMHDR)(unsafe.Pointer(lParam))).Code {
+		case win.EN_SELCHANGE:
+			rte.selectionChangedPublisher.Publish()
+		}
+
+		return 0
+
+	case win.WM_GETDLGCODE:
+		if wParam == win.VK_RETURN {
+			return win.DLGC_WANTALLKEYS
+		}
+
+		return win.DLGC_HASSETSEL | win.DLGC_WANTARROWS | win.DLGC_WANTCHARS
+	}
+
+	return rte.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (*RichTextEdit) CreateLayoutItem(ctx *LayoutContext) LayoutItem {
+	return NewGreedyLayoutItem()
+}
@@ -0,0 +1,280 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"reflect"
+
+	"github.com/lxn/walk/stylesheet"
+)
+
+// StyleSheet returns the style sheet of the *FormBase, or nil if there is none.
+func (fb *FormBase) StyleSheet() *stylesheet.StyleSheet {
+	return fb.styleSheet
+}
+
+// SetStyleSheet sets the style sheet of the *FormBase and applies it to the
+// form and all of its descendants.
+//
+// Pass nil to remove the styles applied by a previous style sheet.
+func (fb *FormBase) SetStyleSheet(ss *stylesheet.StyleSheet) {
+	fb.styleSheet = ss
+
+	fb.ApplyStyleSheet()
+}
+
+// ApplyStyleSheet applies the style sheet of the *FormBase again.
+//
+// Call this after adding widgets to a form that is already visible. It is
+// called automatically when the form is shown and when its DPI changes.
+func (fb *FormBase) ApplyStyleSheet() {
+	if fb.styleSheet == nil && !fb.styled {
+		return
+	}
+
+	applyStyleSheet(fb.styleSheet, fb.window)
+
+	fb.styled = fb.styleSheet != nil
+}
+
+// StyleClasses returns the class tags of the *WindowBase that style sheet
+// selectors like ".primary" match.
+func (wb *WindowBase) StyleClasses() []string {
+	return wb.styleClasses
+}
+
+// SetStyleClasses sets the class tags of the *WindowBase and applies the style
+// sheet of its form again to the *WindowBase and its descendants.
+func (wb *WindowBase) SetStyleClasses(classes ...string) {
+	wb.styleClasses = classes
+
+	if form := wb.window.Form(); form != nil {
+		if ss := form.AsFormBase().styleSheet; ss != nil {
+			applyStyleSheet(ss, wb.window)
+		}
+	}
+}
+
+func applyStyleSheet(ss *stylesheet.StyleSheet, window Window) {
+	walkDescendants(window, func(w Window) bool {
+		wb := w.AsWindowBase()
+
+		wb.resetStyle()
+
+		if ss != nil {
+			wb.applyStyle(ss.Resolve(styleElement{w}))
+		}
+
+		return true
+	})
+}
+
+// appliedStyle remembers what a style sheet changed on a window, so it can be
+// undone before applying another style.
+type appliedStyle struct {
+	brush          Brush
+	prevBackground Brush
+	textColor      bool
+	prevTextColor  Color
+	font           *Font
+	prevFont       *Font
+	margins        bool
+	prevMargins    Margins
+	border         *styleBorderEffect
+}
+
+type textColorer interface {
+	TextColor() Color
+	SetTextColor(c Color)
+}
+
+func (wb *WindowBase) applyStyle(style *stylesheet.Style) {
+	as := new(appliedStyle)
+
+	if bg := style.Background; bg != nil {
+		var brush Brush
+		if bg.None {
+			brush = NullBrush()
+		} else if scb, err := NewSolidColorBrush(styleColor(bg.Color)); err == nil {
+			brush = scb
+		}
+
+		if brush != nil {
+			as.brush = brush
+			as.prevBackground = wb.window.Background()
+			wb.window.SetBackground(brush)
+		}
+	}
+
+	if style.Color != nil {
+		if tc, ok := wb.window.(textColorer); ok {
+			as.textColor = true
+			as.prevTextColor = tc.TextColor()
+			tc.SetTextColor(styleColor(*style.Color))
+		}
+	}
+
+	if f := style.Font; f != nil {
+		base := wb.window.Font()
+
+		family := f.Family
+		if family == "" {
+			family = base.Family()
+		}
+
+		pointSize := f.PointSize
+		if pointSize == 0 {
+			pointSize = base.PointSize()
+		}
+
+		var fontStyle FontStyle
+		if f.Bold {
+			fontStyle |= FontBold
+		}
+		if f.Italic {
+			fontStyle |= FontItalic
+		}
+		if f.Underline {
+			fontStyle |= FontUnderline
+		}
+		if f.StrikeOut {
+			fontStyle |= FontStrikeOut
+		}
+
+		if font, err := NewFont(family, pointSize, fontStyle); err == nil {
+			as.font = font
+			as.prevFont = wb.font
+			wb.window.SetFont(font)
+		}
+	}
+
+	if m := style.Margin; m != nil {
+		if c, ok := wb.window.(Container); ok && c.Layout() != nil {
+			as.margins = true
+			as.prevMargins = c.Layout().Margins()
+			c.Layout().SetMargins(Margins{HNear: m.Left, VNear: m.Top, HFar: m.Right, VFar: m.Bottom})
+		}
+	}
+
+	if b := style.Border; b != nil {
+		if widget, ok := wb.window.(Widget); ok {
+			as.border = &styleBorderEffect{color: styleColor(b.Color), width: b.Width}
+			widget.GraphicsEffects().Add(as.border)
+			wb.invalidateStyleBorder()
+		}
+	}
+
+	wb.appliedStyle = as
+}
+
+func (wb *WindowBase) resetStyle() {
+	as := wb.appliedStyle
+	if as == nil {
+		return
+	}
+	wb.appliedStyle = nil
+
+	if as.brush != nil {
+		if wb.window.Background() == as.brush {
+			wb.window.SetBackground(as.prevBackground)
+		}
+		as.brush.Dispose()
+	}
+
+	if as.textColor {
+		if tc, ok := wb.window.(textColorer); ok {
+			tc.SetTextColor(as.prevTextColor)
+		}
+	}
+
+	if as.font != nil && wb.font == as.font {
+		wb.font = as.prevFont
+		wb.window.(applyFonter).applyFont(wb.window.Font())
+	}
+
+	if as.margins {
+		if c, ok := wb.window.(Container); ok && c.Layout() != nil {
+			c.Layout().SetMargins(as.prevMargins)
+		}
+	}
+
+	if as.border != nil {
+		if widget, ok := wb.window.(Widget); ok {
+			widget.GraphicsEffects().Remove(as.border)
+			wb.invalidateStyleBorder()
+		}
+	}
+}
+
+func (wb *WindowBase) invalidateStyleBorder() {
+	if widget, ok := wb.window.(Widget); ok {
+		if parent := widget.Parent(); parent != nil {
+			parent.Invalidate()
+		}
+	}
+}
+
+func styleColor(c stylesheet.Color) Color {
+	return RGB(c.R, c.G, c.B)
+}
+
+// styleElement makes a Window available to style sheet selectors.
+type styleElement struct {
+	window Window
+}
+
+func (e styleElement) TypeName() string {
+	return reflect.TypeOf(e.window).Elem().Name()
+}
+
+func (e styleElement) Name() string {
+	return e.window.Name()
+}
+
+func (e styleElement) Classes() []string {
+	return e.window.AsWindowBase().styleClasses
+}
+
+func (e styleElement) Parent() stylesheet.Element {
+	widget, ok := e.window.(Widget)
+	if !ok {
+		return nil
+	}
+
+	parent := widget.Parent()
+	if parent == nil {
+		return nil
+	}
+
+	// The client area of a form is an implementation detail, so children
+	// of a form are styled as children of the form itself.
+	if form := parent.Form(); form != nil && form.AsFormBase().clientComposite == parent {
+		return styleElement{form}
+	}
+
+	return styleElement{parent}
+}
+
+// styleBorderEffect draws the border property of a style sheet around a
+// widget.
+type styleBorderEffect struct {
+	color Color
+	width int // in 1/96" units
+}
+
+func (sbe *styleBorderEffect) Draw(widget Widget, canvas *Canvas) error {
+	brush, err := NewSolidColorBrush(sbe.color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	b := widget.BoundsPixels()
+	w := IntFrom96DPI(sbe.width, canvas.DPI())
+
+	return canvas.FillRectanglePixels(brush, Rectangle{b.X - w, b.Y - w, b.Width + 2*w, b.Height + 2*w})
+}
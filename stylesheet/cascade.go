@@ -0,0 +1,306 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stylesheet
+
+import (
+	"strings"
+)
+
+// Element is something a style sheet can be applied to, usually a widget.
+type Element interface {
+	// TypeName returns the name of the type, like "PushButton".
+	TypeName() string
+
+	// Name returns the name, or "" if there is none.
+	Name() string
+
+	// Classes returns the class tags.
+	Classes() []string
+
+	// Parent returns the parent element, or nil for the root.
+	Parent() Element
+}
+
+type combinator int
+
+const (
+	descendant combinator = iota
+	child
+)
+
+type compound struct {
+	any        bool
+	typeName   string
+	name       string
+	classes    []string
+	combinator combinator // Relation to the compound before.
+}
+
+func (c *compound) matches(e Element) bool {
+	if !c.any && c.typeName != e.TypeName() {
+		return false
+	}
+
+	if c.name != "" && c.name != e.Name() {
+		return false
+	}
+
+	if len(c.classes) > 0 {
+		classes := e.Classes()
+
+	outer:
+		for _, want := range c.classes {
+			for _, class := range classes {
+				if class == want {
+					continue outer
+				}
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *compound) String() string {
+	var b strings.Builder
+
+	if c.typeName != "" {
+		b.WriteString(c.typeName)
+	} else if c.name == "" && len(c.classes) == 0 {
+		b.WriteByte('*')
+	}
+
+	if c.name != "" {
+		b.WriteByte('#')
+		b.WriteString(c.name)
+	}
+
+	for _, class := range c.classes {
+		b.WriteByte('.')
+		b.WriteString(class)
+	}
+
+	return b.String()
+}
+
+// Selector selects the elements a rule applies to.
+type Selector struct {
+	compounds []*compound
+}
+
+// String returns the selector in style sheet syntax.
+func (s *Selector) String() string {
+	var b strings.Builder
+
+	for i, c := range s.compounds {
+		if i > 0 {
+			if c.combinator == child {
+				b.WriteString(" > ")
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(c.String())
+	}
+
+	return b.String()
+}
+
+// Specificity returns the number of names, class tags and type names of the
+// selector. Selectors with more names win, then those with more class tags,
+// then those with more type names.
+func (s *Selector) Specificity() (names, classes, types int) {
+	for _, c := range s.compounds {
+		if c.name != "" {
+			names++
+		}
+		classes += len(c.classes)
+		if c.typeName != "" {
+			types++
+		}
+	}
+
+	return
+}
+
+func (s *Selector) specificity() int {
+	names, classes, types := s.Specificity()
+
+	return names<<20 | classes<<10 | types
+}
+
+// Matches returns if the selector matches e.
+func (s *Selector) Matches(e Element) bool {
+	return s.matchesAt(e, len(s.compounds)-1)
+}
+
+func (s *Selector) matchesAt(e Element, i int) bool {
+	c := s.compounds[i]
+
+	if !c.matches(e) {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	if c.combinator == child {
+		if parent := e.Parent(); parent != nil {
+			return s.matchesAt(parent, i-1)
+		}
+		return false
+	}
+
+	for ancestor := e.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+		if s.matchesAt(ancestor, i-1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Font is the font of a Style. Zero values mean that the default of the
+// element applies.
+type Font struct {
+	Family    string
+	PointSize int
+	Bold      bool
+	Italic    bool
+	Underline bool
+	StrikeOut bool
+}
+
+// Style is the result of resolving a style sheet for an element. Fields are
+// nil if no rule sets them.
+type Style struct {
+	Background *Paint
+	Color      *Color
+	Font       *Font
+	Margin     *Margins
+
+	// Border is nil if no border should be drawn.
+	Border *Border
+}
+
+var inheritedProperties = []string{
+	"font-family",
+	"font-size",
+	"font-weight",
+	"font-style",
+	"text-decoration",
+}
+
+// Resolve returns the style of e.
+func (ss *StyleSheet) Resolve(e Element) *Style {
+	values := ss.declared(e)
+
+	for _, property := range inheritedProperties {
+		if _, ok := values[property]; ok {
+			continue
+		}
+
+		for ancestor := e.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+			if value, ok := ss.declared(ancestor)[property]; ok {
+				values[property] = value
+				break
+			}
+		}
+	}
+
+	style := new(Style)
+
+	if v, ok := values["background"]; ok {
+		paint := v.(Paint)
+		style.Background = &paint
+	}
+
+	if v, ok := values["color"]; ok {
+		color := v.(Color)
+		style.Color = &color
+	}
+
+	for _, property := range inheritedProperties {
+		v, ok := values[property]
+		if !ok {
+			continue
+		}
+
+		if style.Font == nil {
+			style.Font = new(Font)
+		}
+
+		switch property {
+		case "font-family":
+			style.Font.Family = v.(string)
+
+		case "font-size":
+			style.Font.PointSize = v.(int)
+
+		case "font-weight":
+			style.Font.Bold = v.(bool)
+
+		case "font-style":
+			style.Font.Italic = v.(bool)
+
+		case "text-decoration":
+			td := v.(textDecoration)
+			style.Font.Underline = td.underline
+			style.Font.StrikeOut = td.strikeOut
+		}
+	}
+
+	if v, ok := values["margin"]; ok {
+		margins := v.(Margins)
+		style.Margin = &margins
+	}
+
+	if v, ok := values["border-style"]; ok && v.(bool) {
+		border := Border{Width: 1, Solid: true}
+		if v, ok := values["border-width"]; ok {
+			border.Width = v.(int)
+		}
+		if v, ok := values["border-color"]; ok {
+			border.Color = v.(Color)
+		}
+		if border.Width > 0 {
+			style.Border = &border
+		}
+	}
+
+	return style
+}
+
+// declared returns the winning declared value of each property for e,
+// without inheritance.
+func (ss *StyleSheet) declared(e Element) map[string]interface{} {
+	values := make(map[string]interface{})
+	specificities := make(map[string]int)
+
+	for _, r := range ss.rules {
+		specificity := -1
+		for _, sel := range r.selectors {
+			if s := sel.specificity(); s > specificity && sel.Matches(e) {
+				specificity = s
+			}
+		}
+		if specificity < 0 {
+			continue
+		}
+
+		for _, d := range r.declarations {
+			if s, ok := specificities[d.property]; ok && s > specificity {
+				continue
+			}
+
+			values[d.property] = d.value
+			specificities[d.property] = specificity
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,171 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stylesheet
+
+import (
+	"reflect"
+	"testing"
+)
+
+type element struct {
+	typeName string
+	name     string
+	classes  []string
+	parent   *element
+}
+
+func (e *element) TypeName() string  { return e.typeName }
+func (e *element) Name() string      { return e.name }
+func (e *element) Classes() []string { return e.classes }
+
+func (e *element) Parent() Element {
+	if e.parent == nil {
+		return nil
+	}
+
+	return e.parent
+}
+
+func newTestTree() (form, toolbar, label, nested *element) {
+	form = &element{typeName: "MainWindow", name: "mainWindow"}
+	toolbar = &element{typeName: "Composite", classes: []string{"toolbar", "dark"}, parent: form}
+	label = &element{typeName: "Label", name: "titleLabel", parent: toolbar}
+	group := &element{typeName: "GroupBox", parent: toolbar}
+	nested = &element{typeName: "Label", parent: group}
+
+	return
+}
+
+func TestSelectorMatches(t *testing.T) {
+	_, toolbar, label, nested := newTestTree()
+
+	tests := []struct {
+		selector string
+		e        Element
+		want     bool
+	}{
+		{"Label", label, true},
+		{"*", label, true},
+		{"PushButton", label, false},
+		{"#titleLabel", label, true},
+		{"Label#titleLabel", label, true},
+		{"#otherLabel", label, false},
+		{".toolbar", toolbar, true},
+		{".toolbar.dark", toolbar, true},
+		{"Composite.toolbar.light", toolbar, false},
+		{".toolbar Label", label, true},
+		{".toolbar Label", nested, true},
+		{".toolbar > Label", label, true},
+		{".toolbar > Label", nested, false},
+		{".toolbar > * > Label", nested, true},
+		{"MainWindow > Label", label, false},
+		{"#mainWindow Composite GroupBox Label", nested, true},
+		{"GroupBox Composite Label", nested, false},
+	}
+
+	for _, test := range tests {
+		sel := MustParse(test.selector + " {}").rules[0].selectors[0]
+		if got := sel.Matches(test.e); got != test.want {
+			t.Errorf("%s matches %s: got %t, want %t", test.selector, test.e.TypeName(), got, test.want)
+		}
+	}
+}
+
+func TestSelectorSpecificity(t *testing.T) {
+	sel := MustParse("Composite.toolbar.dark > #titleLabel * {}").rules[0].selectors[0]
+
+	names, classes, types := sel.Specificity()
+	if names != 1 || classes != 2 || types != 1 {
+		t.Errorf("got %d, %d, %d, want 1, 2, 1", names, classes, types)
+	}
+}
+
+func TestResolveSpecificity(t *testing.T) {
+	_, _, label, _ := newTestTree()
+
+	ss := MustParse(`
+		#titleLabel { color: red }
+		.toolbar Label { color: green; background: blue }
+		Label { color: yellow; background: white }
+		* { background: black }`)
+
+	style := ss.Resolve(label)
+
+	if style.Color == nil || *style.Color != (Color{255, 0, 0}) {
+		t.Errorf("got color %v, want red of the name selector", style.Color)
+	}
+	if style.Background == nil || *style.Background != (Paint{Color: Color{0, 0, 255}}) {
+		t.Errorf("got background %v, want blue of the class selector", style.Background)
+	}
+}
+
+func TestResolveLaterRuleWinsTie(t *testing.T) {
+	_, _, label, _ := newTestTree()
+
+	ss := MustParse(`
+		Label, PushButton { margin: 1px }
+		Label { margin: 2px 4px }`)
+
+	style := ss.Resolve(label)
+
+	if want := (Margins{2, 4, 2, 4}); style.Margin == nil || *style.Margin != want {
+		t.Errorf("got margin %v, want %v", style.Margin, want)
+	}
+}
+
+func TestResolveInheritance(t *testing.T) {
+	form, toolbar, label, nested := newTestTree()
+
+	ss := MustParse(`
+		MainWindow { font: 9pt "Segoe UI"; color: red; border: 1px solid }
+		.toolbar { font-weight: bold; text-decoration: underline }
+		GroupBox { font-style: italic; font-size: 12pt }`)
+
+	tests := []struct {
+		e    *element
+		want Font
+	}{
+		{form, Font{Family: "Segoe UI", PointSize: 9}},
+		{toolbar, Font{Family: "Segoe UI", PointSize: 9, Bold: true, Underline: true}},
+		{label, Font{Family: "Segoe UI", PointSize: 9, Bold: true, Underline: true}},
+		{nested, Font{Family: "Segoe UI", PointSize: 12, Bold: true, Italic: true, Underline: true}},
+	}
+
+	for _, test := range tests {
+		style := ss.Resolve(test.e)
+
+		if style.Font == nil || *style.Font != test.want {
+			t.Errorf("%s: got font %+v, want %+v", test.e.TypeName(), style.Font, test.want)
+		}
+
+		if test.e != form && (style.Color != nil || style.Border != nil) {
+			t.Errorf("%s: non-font properties were inherited", test.e.TypeName())
+		}
+	}
+}
+
+func TestResolveBorder(t *testing.T) {
+	e := &element{typeName: "Label"}
+
+	tests := []struct {
+		src  string
+		want *Border
+	}{
+		{"Label {}", nil},
+		{"Label { border: 2px solid #f00 }", &Border{Width: 2, Solid: true, Color: Color{255, 0, 0}}},
+		{"Label { border: solid }", &Border{Width: 1, Solid: true}},
+		{"Label { border: 2px red }", nil},
+		{"Label { border: 1px solid; border: none }", nil},
+		{"Label { border: solid 0 }", nil},
+		{"Label { border-style: solid; border-color: navy }", &Border{Width: 1, Solid: true, Color: Color{0, 0, 128}}},
+	}
+
+	for _, test := range tests {
+		got := MustParse(test.src).Resolve(e).Border
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.src, got, test.want)
+		}
+	}
+}
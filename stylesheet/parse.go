@@ -0,0 +1,347 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stylesheet
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) *Error {
+	return p.errorAt(p.line, p.col, format, args...)
+}
+
+func (p *parser) errorAt(line, col int, format string, args ...interface{}) *Error {
+	return &Error{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+func (p *parser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+
+	return r
+}
+
+// skipSpace skips whitespace and comments and returns if it skipped any.
+func (p *parser) skipSpace() (bool, error) {
+	skipped := false
+
+	for !p.eof() {
+		switch {
+		case unicode.IsSpace(p.peek()):
+			p.next()
+
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			line, col := p.line, p.col
+			end := strings.Index(p.src[p.pos+2:], "*/")
+			if end < 0 {
+				return false, p.errorAt(line, col, "unterminated comment")
+			}
+			for stop := p.pos + 2 + end + 2; p.pos < stop; {
+				p.next()
+			}
+
+		default:
+			return skipped, nil
+		}
+
+		skipped = true
+	}
+
+	return skipped, nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *parser) ident() (string, error) {
+	if p.eof() || !isIdentStart(p.peek()) {
+		return "", p.errorf("expected identifier")
+	}
+
+	start := p.pos
+	for !p.eof() && isIdentPart(p.peek()) {
+		p.next()
+	}
+
+	return p.src[start:p.pos], nil
+}
+
+func (p *parser) parseStyleSheet() (*StyleSheet, error) {
+	ss := new(StyleSheet)
+
+	for {
+		if _, err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return ss, nil
+		}
+
+		r, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+
+		ss.rules = append(ss.rules, r)
+	}
+}
+
+func (p *parser) parseRule() (*rule, error) {
+	r := new(rule)
+
+	for {
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		r.selectors = append(r.selectors, sel)
+
+		if _, err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		if p.peek() == ',' {
+			p.next()
+			if _, err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if p.peek() != '{' {
+			return nil, p.errorf("expected ',' or '{'")
+		}
+		p.next()
+		break
+	}
+
+	for {
+		if _, err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		if p.eof() {
+			return nil, p.errorf("expected '}'")
+		}
+
+		if p.peek() == '}' {
+			p.next()
+			return r, nil
+		}
+
+		if p.peek() == ';' {
+			p.next()
+			continue
+		}
+
+		decls, err := p.parseDeclaration()
+		if err != nil {
+			return nil, err
+		}
+
+		r.declarations = append(r.declarations, decls...)
+	}
+}
+
+func (p *parser) parseSelector() (*Selector, error) {
+	sel := new(Selector)
+
+	combinator := descendant
+
+	for {
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		c.combinator = combinator
+		sel.compounds = append(sel.compounds, c)
+
+		skipped, err := p.skipSpace()
+		if err != nil {
+			return nil, err
+		}
+
+		switch r := p.peek(); {
+		case r == '>':
+			p.next()
+			if _, err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			combinator = child
+
+		case r == ',' || r == '{' || p.eof():
+			return sel, nil
+
+		case skipped:
+			combinator = descendant
+
+		default:
+			return nil, p.errorf("unexpected %q in selector", r)
+		}
+	}
+}
+
+func (p *parser) parseCompound() (*compound, error) {
+	c := new(compound)
+
+	switch r := p.peek(); {
+	case r == '*':
+		p.next()
+		c.any = true
+
+	case isIdentStart(r):
+		c.typeName, _ = p.ident()
+
+	case r == '#' || r == '.':
+		c.any = true
+
+	default:
+		return nil, p.errorf("expected selector")
+	}
+
+	for !p.eof() {
+		switch p.peek() {
+		case '#':
+			p.next()
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if c.name != "" && c.name != name {
+				return nil, p.errorf("compound selector with two names")
+			}
+			c.name = name
+
+		case '.':
+			p.next()
+			class, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			c.classes = append(c.classes, class)
+
+		default:
+			return c, nil
+		}
+	}
+
+	return c, nil
+}
+
+func (p *parser) parseDeclaration() ([]declaration, error) {
+	line, col := p.line, p.col
+
+	property, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	property = strings.ToLower(property)
+
+	if _, err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.peek() != ':' {
+		return nil, p.errorf("expected ':'")
+	}
+	p.next()
+
+	if _, err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+
+	valueLine, valueCol := p.line, p.col
+
+	value, err := p.rawValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, p.errorAt(valueLine, valueCol, "missing value of %s", property)
+	}
+
+	decls, err := parseProperty(property, value, line)
+	if err != nil {
+		if _, ok := err.(unknownPropertyError); ok {
+			return nil, p.errorAt(line, col, "%v", err)
+		}
+
+		return nil, p.errorAt(valueLine, valueCol, "%s: %v", property, err)
+	}
+
+	return decls, nil
+}
+
+// rawValue returns the text up to the next ';' or '}', outside of quotes
+// and comments, with whitespace trimmed.
+func (p *parser) rawValue() (string, error) {
+	var b strings.Builder
+
+	for !p.eof() {
+		switch r := p.peek(); {
+		case r == ';' || r == '}':
+			return strings.TrimSpace(b.String()), nil
+
+		case r == '"' || r == '\'':
+			line, col := p.line, p.col
+			b.WriteRune(p.next())
+			for {
+				if p.eof() || p.peek() == '\n' {
+					return "", p.errorAt(line, col, "unterminated string")
+				}
+				c := p.next()
+				b.WriteRune(c)
+				if c == r {
+					break
+				}
+			}
+
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			if _, err := p.skipSpace(); err != nil {
+				return "", err
+			}
+			b.WriteByte(' ')
+
+		default:
+			b.WriteRune(p.next())
+		}
+	}
+
+	return "", p.errorf("expected ';' or '}'")
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stylesheet implements a small CSS-like language for styling walk
+// widgets.
+//
+// A style sheet consists of rules. Each rule has a comma separated list of
+// selectors and a block of declarations:
+//
+//	/* All push buttons */
+//	PushButton {
+//		font: bold 10pt "Segoe UI";
+//	}
+//
+//	Composite.toolbar, #statusComposite {
+//		background: #2d2d30;
+//		margin: 4px 8px;
+//	}
+//
+//	.toolbar Label {
+//		color: rgb(240, 240, 240);
+//		border: 1px solid silver;
+//	}
+//
+// A selector is a sequence of compound selectors, separated by whitespace for
+// descendants or by ">" for children. A compound selector matches a type name
+// like "PushButton" or any type with "*", followed by any number of names
+// ("#okPB") and class tags (".primary").
+//
+// Supported properties are background, color, font, font-family, font-size,
+// font-weight, font-style, text-decoration, margin, border, border-width,
+// border-style and border-color.
+//
+// For each property, the declaration of the matching rule with the highest
+// specificity wins, like in CSS. Rules that come later win ties. Font
+// properties are inherited from the parent element, all others are not.
+//
+// This package does not depend on walk, so style sheets can be parsed and
+// resolved on any platform. walk.FormBase.SetStyleSheet applies them to
+// widgets.
+package stylesheet
+
+import (
+	"fmt"
+)
+
+// StyleSheet is a parsed style sheet.
+type StyleSheet struct {
+	rules []*rule
+}
+
+type rule struct {
+	selectors    []*Selector
+	declarations []declaration
+}
+
+type declaration struct {
+	property string
+	value    interface{}
+	line     int
+}
+
+// Error is a syntax error of a style sheet.
+type Error struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Parse parses a style sheet. Errors are of type *Error.
+func Parse(src string) (*StyleSheet, error) {
+	p := &parser{src: src, line: 1, col: 1}
+
+	return p.parseStyleSheet()
+}
+
+// MustParse is like Parse but panics if src can't be parsed.
+func MustParse(src string) *StyleSheet {
+	ss, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+
+	return ss
+}
+
+// Append appends the rules of other to ss, so they win ties against the
+// rules of ss.
+func (ss *StyleSheet) Append(other *StyleSheet) {
+	ss.rules = append(ss.rules, other.rules...)
+}
+
+// Len returns the number of rules.
+func (ss *StyleSheet) Len() int {
+	return len(ss.rules)
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stylesheet
+
+import (
+	"testing"
+)
+
+func TestParseSelectors(t *testing.T) {
+	ss := MustParse(`
+		/* comment */
+		PushButton, Composite.toolbar > Label#titleLabel.primary.large,
+		*#statusComposite .hint {
+			color: red;
+		}
+		.toolbar{}`)
+
+	if ss.Len() != 2 {
+		t.Fatalf("got %d rules, want 2", ss.Len())
+	}
+
+	want := []string{
+		"PushButton",
+		"Composite.toolbar > Label#titleLabel.primary.large",
+		"#statusComposite .hint",
+	}
+
+	selectors := ss.rules[0].selectors
+	if len(selectors) != len(want) {
+		t.Fatalf("got %d selectors, want %d", len(selectors), len(want))
+	}
+	for i, sel := range selectors {
+		if got := sel.String(); got != want[i] {
+			t.Errorf("selector %d: got %q, want %q", i, got, want[i])
+		}
+	}
+
+	if got := ss.rules[1].selectors[0].String(); got != ".toolbar" {
+		t.Errorf("got %q, want %q", got, ".toolbar")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src          string
+		line, column int
+	}{
+		{"PushButton {", 1, 13},
+		{"PushButton { color: red }\n/* open", 2, 1},
+		{"PushButton ~ Label {}", 1, 12},
+		{"#a#b {}", 1, 5},
+		{"PushButton {\n  colour: red;\n}", 2, 3},
+		{"PushButton {\n  color: reddish;\n}", 2, 10},
+		{"PushButton { color: ; }", 1, 21},
+		{"PushButton { font-family: \"Segoe UI; }", 1, 27},
+		{"PushButton { color red }", 1, 20},
+		{"PushButton color: red }", 1, 17},
+		{"{ color: red }", 1, 1},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.src)
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%q: got error %v, want *Error", test.src, err)
+			continue
+		}
+		if e.Line != test.line || e.Column != test.column {
+			t.Errorf("%q: got error at %d:%d (%v), want %d:%d", test.src, e.Line, e.Column, e, test.line, test.column)
+		}
+	}
+}
+
+func TestMustParsePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse did not panic")
+		}
+	}()
+
+	MustParse("PushButton {")
+}
+
+func TestAppend(t *testing.T) {
+	ss := MustParse("Label { color: red }")
+	ss.Append(MustParse("Label { color: blue } PushButton {}"))
+
+	if ss.Len() != 3 {
+		t.Fatalf("got %d rules, want 3", ss.Len())
+	}
+
+	style := ss.Resolve(&element{typeName: "Label"})
+	if style.Color == nil || *style.Color != (Color{0, 0, 255}) {
+		t.Errorf("got color %v, want the appended blue", style.Color)
+	}
+}
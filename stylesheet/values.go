@@ -0,0 +1,483 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stylesheet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is a color in RGB.
+type Color struct {
+	R, G, B uint8
+}
+
+// Margins are the margins of a container in pixels at 96 DPI.
+type Margins struct {
+	Top, Right, Bottom, Left int
+}
+
+// Paint is the value of the background property.
+type Paint struct {
+	// None is true for "background: none", which makes the background
+	// transparent.
+	None  bool
+	Color Color
+}
+
+// Border is the value of the border properties.
+type Border struct {
+	// Width is the width of the border in pixels at 96 DPI.
+	Width int
+
+	// Solid is false for "border-style: none".
+	Solid bool
+
+	Color Color
+}
+
+type textDecoration struct {
+	underline bool
+	strikeOut bool
+}
+
+var namedColors = map[string]Color{
+	"black":   {0, 0, 0},
+	"white":   {255, 255, 255},
+	"gray":    {128, 128, 128},
+	"grey":    {128, 128, 128},
+	"silver":  {192, 192, 192},
+	"red":     {255, 0, 0},
+	"maroon":  {128, 0, 0},
+	"orange":  {255, 165, 0},
+	"yellow":  {255, 255, 0},
+	"olive":   {128, 128, 0},
+	"lime":    {0, 255, 0},
+	"green":   {0, 128, 0},
+	"aqua":    {0, 255, 255},
+	"teal":    {0, 128, 128},
+	"blue":    {0, 0, 255},
+	"navy":    {0, 0, 128},
+	"fuchsia": {255, 0, 255},
+	"purple":  {128, 0, 128},
+}
+
+type unknownPropertyError string
+
+func (e unknownPropertyError) Error() string {
+	return fmt.Sprintf("unknown property %q", string(e))
+}
+
+// parseProperty parses the value of a property and returns the resulting
+// declarations. Shorthand properties like font result in several ones.
+func parseProperty(property, value string, line int) ([]declaration, error) {
+	var decls []declaration
+	add := func(property string, value interface{}) {
+		decls = append(decls, declaration{property, value, line})
+	}
+
+	fields, err := splitFields(value)
+	if err != nil {
+		return nil, err
+	}
+
+	single := func() (string, error) {
+		if len(fields) != 1 {
+			return "", errors.New("expected a single value")
+		}
+		return fields[0], nil
+	}
+
+	switch property {
+	case "background":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(f, "none") || strings.EqualFold(f, "transparent") {
+			add(property, Paint{None: true})
+			break
+		}
+		c, err := parseColor(f)
+		if err != nil {
+			return nil, err
+		}
+		add(property, Paint{Color: c})
+
+	case "color", "border-color":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		c, err := parseColor(f)
+		if err != nil {
+			return nil, err
+		}
+		add(property, c)
+
+	case "font-family":
+		add(property, parseFamily(fields))
+
+	case "font-size":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		size, err := parseFontSize(f)
+		if err != nil {
+			return nil, err
+		}
+		add(property, size)
+
+	case "font-weight":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		bold, ok := parseFontWeight(f)
+		if !ok {
+			return nil, fmt.Errorf("invalid font weight %q", f)
+		}
+		add(property, bold)
+
+	case "font-style":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		italic, ok := parseFontStyle(f)
+		if !ok {
+			return nil, fmt.Errorf("invalid font style %q", f)
+		}
+		add(property, italic)
+
+	case "text-decoration":
+		var td textDecoration
+		for _, f := range fields {
+			switch strings.ToLower(f) {
+			case "none":
+			case "underline":
+				td.underline = true
+			case "line-through":
+				td.strikeOut = true
+			default:
+				return nil, fmt.Errorf("invalid text decoration %q", f)
+			}
+		}
+		add(property, td)
+
+	case "font":
+		return parseFontShorthand(fields, line)
+
+	case "margin":
+		if len(fields) > 4 {
+			return nil, errors.New("expected one to four lengths")
+		}
+		var v [4]int
+		for i, f := range fields {
+			if v[i], err = parseLength(f); err != nil {
+				return nil, err
+			}
+		}
+		var m Margins
+		switch len(fields) {
+		case 1:
+			m = Margins{v[0], v[0], v[0], v[0]}
+		case 2:
+			m = Margins{v[0], v[1], v[0], v[1]}
+		case 3:
+			m = Margins{v[0], v[1], v[2], v[1]}
+		case 4:
+			m = Margins{v[0], v[1], v[2], v[3]}
+		}
+		add(property, m)
+
+	case "border-width":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		width, err := parseLength(f)
+		if err != nil {
+			return nil, err
+		}
+		add(property, width)
+
+	case "border-style":
+		f, err := single()
+		if err != nil {
+			return nil, err
+		}
+		solid, ok := parseBorderStyle(f)
+		if !ok {
+			return nil, fmt.Errorf("invalid border style %q", f)
+		}
+		add(property, solid)
+
+	case "border":
+		return parseBorderShorthand(fields, line)
+
+	default:
+		return nil, unknownPropertyError(property)
+	}
+
+	return decls, nil
+}
+
+// parseFontShorthand parses "font: [italic] [bold] <size> [<family>]". Omitted
+// styles are reset to normal.
+func parseFontShorthand(fields []string, line int) ([]declaration, error) {
+	var bold, italic bool
+
+	i := 0
+	for ; i < len(fields); i++ {
+		if b, ok := parseFontWeight(fields[i]); ok && strings.ToLower(fields[i]) != "normal" {
+			bold = b
+		} else if it, ok := parseFontStyle(fields[i]); ok {
+			italic = it
+		} else {
+			break
+		}
+	}
+
+	if i == len(fields) {
+		return nil, errors.New("missing font size")
+	}
+
+	size, err := parseFontSize(fields[i])
+	if err != nil {
+		return nil, err
+	}
+
+	decls := []declaration{
+		{"font-style", italic, line},
+		{"font-weight", bold, line},
+		{"font-size", size, line},
+	}
+
+	if family := parseFamily(fields[i+1:]); family != "" {
+		decls = append(decls, declaration{"font-family", family, line})
+	}
+
+	return decls, nil
+}
+
+// parseBorderShorthand parses "border: none" and "border: <width> <style>
+// <color>", with the parts in any order.
+func parseBorderShorthand(fields []string, line int) ([]declaration, error) {
+	var decls []declaration
+	seen := make(map[string]bool)
+
+	for _, f := range fields {
+		var property string
+		var value interface{}
+
+		if solid, ok := parseBorderStyle(f); ok {
+			property, value = "border-style", solid
+		} else if width, err := parseLength(f); err == nil {
+			property, value = "border-width", width
+		} else if c, err := parseColor(f); err == nil {
+			property, value = "border-color", c
+		} else {
+			return nil, fmt.Errorf("invalid border part %q", f)
+		}
+
+		if seen[property] {
+			return nil, fmt.Errorf("more than one %s", property)
+		}
+		seen[property] = true
+
+		decls = append(decls, declaration{property, value, line})
+	}
+
+	// Like in CSS, a width or color alone doesn't show a border.
+	if !seen["border-style"] {
+		decls = append(decls, declaration{"border-style", false, line})
+	}
+	if !seen["border-width"] {
+		decls = append(decls, declaration{"border-width", 1, line})
+	}
+	if !seen["border-color"] {
+		decls = append(decls, declaration{"border-color", Color{}, line})
+	}
+
+	return decls, nil
+}
+
+// splitFields splits a value at whitespace outside of quotes and parentheses.
+func splitFields(value string) ([]string, error) {
+	var fields []string
+	var quote rune
+	depth := 0
+	start := -1
+
+	for i, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+
+		case r == '"' || r == '\'':
+			quote = r
+
+		case r == '(':
+			depth++
+
+		case r == ')':
+			if depth == 0 {
+				return nil, errors.New("unbalanced parentheses")
+			}
+			depth--
+
+		case (r == ' ' || r == '\t' || r == '\n' || r == '\r') && depth == 0:
+			if start >= 0 {
+				fields = append(fields, value[start:i])
+				start = -1
+			}
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated string")
+	}
+	if depth != 0 {
+		return nil, errors.New("unbalanced parentheses")
+	}
+
+	if start >= 0 {
+		fields = append(fields, value[start:])
+	}
+
+	return fields, nil
+}
+
+func parseColor(s string) (Color, error) {
+	lower := strings.ToLower(s)
+
+	if c, ok := namedColors[lower]; ok {
+		return c, nil
+	}
+
+	if strings.HasPrefix(lower, "#") {
+		hex := lower[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 6 {
+			if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+				return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+			}
+		}
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	if strings.HasPrefix(lower, "rgb(") && strings.HasSuffix(lower, ")") {
+		parts := strings.Split(lower[len("rgb("):len(lower)-1], ",")
+		if len(parts) == 3 {
+			var v [3]uint8
+			for i, part := range parts {
+				n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+				if err != nil {
+					return Color{}, fmt.Errorf("invalid color %q", s)
+				}
+				v[i] = uint8(n)
+			}
+			return Color{v[0], v[1], v[2]}, nil
+		}
+	}
+
+	return Color{}, fmt.Errorf("invalid color %q", s)
+}
+
+// parseLength parses a non-negative length in pixels, with an optional "px"
+// unit.
+func parseLength(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(s), "px"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid length %q", s)
+	}
+
+	return n, nil
+}
+
+// parseFontSize parses a font size in points, with an optional "pt" unit.
+// Sizes in pixels are converted to points at 96 DPI.
+func parseFontSize(s string) (int, error) {
+	lower := strings.ToLower(s)
+
+	var n int
+	var err error
+	if strings.HasSuffix(lower, "px") {
+		if n, err = strconv.Atoi(strings.TrimSuffix(lower, "px")); err == nil {
+			n = (n*72 + 48) / 96
+		}
+	} else {
+		n, err = strconv.Atoi(strings.TrimSuffix(lower, "pt"))
+	}
+
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid font size %q", s)
+	}
+
+	return n, nil
+}
+
+func parseFontWeight(s string) (bold, ok bool) {
+	switch strings.ToLower(s) {
+	case "normal":
+		return false, true
+
+	case "bold":
+		return true, true
+	}
+
+	if n, err := strconv.Atoi(s); err == nil && n >= 100 && n <= 900 && n%100 == 0 {
+		return n >= 600, true
+	}
+
+	return false, false
+}
+
+func parseFontStyle(s string) (italic, ok bool) {
+	switch strings.ToLower(s) {
+	case "normal":
+		return false, true
+
+	case "italic", "oblique":
+		return true, true
+	}
+
+	return false, false
+}
+
+func parseBorderStyle(s string) (solid, ok bool) {
+	switch strings.ToLower(s) {
+	case "none", "hidden":
+		return false, true
+
+	case "solid":
+		return true, true
+	}
+
+	return false, false
+}
+
+// parseFamily joins the fields of a font family, removing quotes.
+func parseFamily(fields []string) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = strings.Trim(f, `"'`)
+	}
+
+	return strings.Join(names, " ")
+}
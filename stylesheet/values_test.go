@@ -0,0 +1,140 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stylesheet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Color
+		ok   bool
+	}{
+		{"Silver", Color{192, 192, 192}, true},
+		{"#2d2d30", Color{45, 45, 48}, true},
+		{"#F0a", Color{255, 0, 170}, true},
+		{"rgb(240, 16,0)", Color{240, 16, 0}, true},
+		{"#2d2d3", Color{}, false},
+		{"#gggggg", Color{}, false},
+		{"rgb(256, 0, 0)", Color{}, false},
+		{"rgb(1, 2)", Color{}, false},
+		{"reddish", Color{}, false},
+	}
+
+	for _, test := range tests {
+		got, err := parseColor(test.s)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("%s: got %v, %v, want %v", test.s, got, err, test.want)
+		}
+	}
+}
+
+func TestParseFontSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+		ok   bool
+	}{
+		{"10", 10, true},
+		{"10pt", 10, true},
+		{"16px", 12, true},
+		{"13PX", 10, true},
+		{"0", 0, false},
+		{"-1pt", 0, false},
+		{"large", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := parseFontSize(test.s)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("%s: got %d, %v, want %d", test.s, got, err, test.want)
+		}
+	}
+}
+
+func TestParseProperty(t *testing.T) {
+	tests := []struct {
+		property, value string
+		want            []declaration
+	}{
+		{"background", "transparent", []declaration{{"background", Paint{None: true}, 1}}},
+		{"font-family", `"Segoe UI"`, []declaration{{"font-family", "Segoe UI", 1}}},
+		{"font-weight", "700", []declaration{{"font-weight", true, 1}}},
+		{"font-weight", "normal", []declaration{{"font-weight", false, 1}}},
+		{"text-decoration", "underline line-through", []declaration{{"text-decoration", textDecoration{true, true}, 1}}},
+		{"margin", "1px 2px 3px", []declaration{{"margin", Margins{1, 2, 3, 2}, 1}}},
+		{"margin", "1 2 3 4", []declaration{{"margin", Margins{1, 2, 3, 4}, 1}}},
+		{"font", `italic bold 10pt "Segoe UI"`, []declaration{
+			{"font-style", true, 1},
+			{"font-weight", true, 1},
+			{"font-size", 10, 1},
+			{"font-family", "Segoe UI", 1},
+		}},
+		{"font", "12px", []declaration{
+			{"font-style", false, 1},
+			{"font-weight", false, 1},
+			{"font-size", 9, 1},
+		}},
+		{"border", "red 3px", []declaration{
+			{"border-color", Color{255, 0, 0}, 1},
+			{"border-width", 3, 1},
+			{"border-style", false, 1},
+		}},
+	}
+
+	for _, test := range tests {
+		got, err := parseProperty(test.property, test.value, 1)
+		if err != nil {
+			t.Errorf("%s: %s: %v", test.property, test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: %s: got %v, want %v", test.property, test.value, got, test.want)
+		}
+	}
+}
+
+func TestParsePropertyErrors(t *testing.T) {
+	tests := []struct{ property, value string }{
+		{"color", "red blue"},
+		{"font-weight", "heavy"},
+		{"font-weight", "650"},
+		{"font-style", "slanted"},
+		{"text-decoration", "blink"},
+		{"font", "bold italic"},
+		{"margin", "1 2 3 4 5"},
+		{"margin", "-1px"},
+		{"border", "1px 2px"},
+		{"border", "dotted"},
+		{"border-style", "dashed"},
+		{"background", "rgb(1, 2, 3"},
+		{"font-family", `"Segoe UI`},
+	}
+
+	for _, test := range tests {
+		if _, err := parseProperty(test.property, test.value, 1); err == nil {
+			t.Errorf("%s: %s: got no error", test.property, test.value)
+		}
+	}
+
+	if _, err := parseProperty("colour", "red", 1); err != unknownPropertyError("colour") {
+		t.Errorf("got error %v, want unknownPropertyError", err)
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	got, err := splitFields(" bold  10pt\t\"Segoe UI\" rgb(1, 2, 3) ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"bold", "10pt", `"Segoe UI"`, "rgb(1, 2, 3)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lxn/walk/vector"
+)
+
+// scanner reads numbers from attribute values like path data, point lists
+// and transforms, where numbers may be separated by whitespace, commas or
+// nothing at all, as in "1-2.5.5".
+type scanner struct {
+	s string
+	i int
+}
+
+func (sc *scanner) skipSeparators() {
+	for sc.i < len(sc.s) {
+		switch sc.s[sc.i] {
+		case ' ', '\t', '\r', '\n', ',':
+			sc.i++
+
+		default:
+			return
+		}
+	}
+}
+
+func (sc *scanner) done() bool {
+	sc.skipSeparators()
+
+	return sc.i >= len(sc.s)
+}
+
+// number returns the next number. ok is false if there is none.
+func (sc *scanner) number() (v float64, ok bool) {
+	sc.skipSeparators()
+
+	start := sc.i
+	i := sc.i
+
+	if i < len(sc.s) && (sc.s[i] == '+' || sc.s[i] == '-') {
+		i++
+	}
+
+	digits := 0
+	for i < len(sc.s) && isDigit(sc.s[i]) {
+		i++
+		digits++
+	}
+	if i < len(sc.s) && sc.s[i] == '.' {
+		i++
+		for i < len(sc.s) && isDigit(sc.s[i]) {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, false
+	}
+
+	if i < len(sc.s) && (sc.s[i] == 'e' || sc.s[i] == 'E') {
+		j := i + 1
+		if j < len(sc.s) && (sc.s[j] == '+' || sc.s[j] == '-') {
+			j++
+		}
+		if j < len(sc.s) && isDigit(sc.s[j]) {
+			for j < len(sc.s) && isDigit(sc.s[j]) {
+				j++
+			}
+			i = j
+		}
+	}
+
+	v, err := strconv.ParseFloat(sc.s[start:i], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	sc.i = i
+
+	return v, true
+}
+
+// flag returns the next arc flag, which is a single 0 or 1.
+func (sc *scanner) flag() (v bool, ok bool) {
+	sc.skipSeparators()
+
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '0' || sc.s[sc.i] == '1') {
+		v = sc.s[sc.i] == '1'
+		sc.i++
+		return v, true
+	}
+
+	return false, false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// numbers returns the list of numbers in s.
+func numbers(s string) []float64 {
+	sc := scanner{s: s}
+
+	var values []float64
+	for {
+		v, ok := sc.number()
+		if !ok {
+			return values
+		}
+
+		values = append(values, v)
+	}
+}
+
+// number returns the number s, or def if s is no number. Percentages are
+// returned as fractions.
+func number(s string, def float64) float64 {
+	sc := scanner{s: s}
+
+	v, ok := sc.number()
+	if !ok {
+		return def
+	}
+
+	if strings.TrimSpace(s[sc.i:]) == "%" {
+		v /= 100
+	}
+
+	return v
+}
+
+// length returns the length s in pixels, or 0 if it is invalid or relative
+// to something that isn't known, like a percentage.
+func length(s string) float64 {
+	sc := scanner{s: s}
+
+	v, ok := sc.number()
+	if !ok {
+		return 0
+	}
+
+	switch strings.TrimSpace(s[sc.i:]) {
+	case "", "px":
+		return v
+
+	case "pt":
+		return v * 96 / 72
+
+	case "pc":
+		return v * 16
+
+	case "mm":
+		return v * 96 / 25.4
+
+	case "cm":
+		return v * 96 / 2.54
+
+	case "in":
+		return v * 96
+
+	case "em":
+		return v * 16
+	}
+
+	return 0
+}
+
+// parseTransform returns the matrix of the transform list s.
+func parseTransform(s string) vector.Matrix {
+	m := vector.Identity()
+
+	for {
+		s = strings.TrimLeft(s, " \t\r\n,")
+
+		open := strings.IndexByte(s, '(')
+		end := strings.IndexByte(s, ')')
+		if open < 0 || end < open {
+			return m
+		}
+
+		name := strings.TrimSpace(s[:open])
+		args := numbers(s[open+1 : end])
+		s = s[end+1:]
+
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+
+		switch name {
+		case "matrix":
+			if len(args) == 6 {
+				m = m.Multiply(vector.Matrix{A: args[0], B: args[1], C: args[2], D: args[3], E: args[4], F: args[5]})
+			}
+
+		case "translate":
+			m = m.Translate(arg(0, 0), arg(1, 0))
+
+		case "scale":
+			sx := arg(0, 1)
+			m = m.Scale(sx, arg(1, sx))
+
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0)
+			m = m.Translate(cx, cy).Rotate(arg(0, 0)*math.Pi/180).Translate(-cx, -cy)
+
+		case "skewX":
+			m = m.Multiply(vector.Matrix{A: 1, C: math.Tan(arg(0, 0) * math.Pi / 180), D: 1})
+
+		case "skewY":
+			m = m.Multiply(vector.Matrix{A: 1, B: math.Tan(arg(0, 0) * math.Pi / 180), D: 1})
+		}
+	}
+}
+
+// parseColor returns the paint s. It returns nil for "none" and paints that
+// aren't supported and have no fallback color. ok is false if s is empty or
+// invalid.
+func parseColor(s string, current color.Color) (c color.Color, ok bool) {
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, "url(") {
+		// Paint servers aren't supported, but may have a fallback color.
+		if i := strings.IndexByte(s, ')'); i >= 0 {
+			if fallback, ok := parseColor(s[i+1:], current); ok {
+				return fallback, true
+			}
+		}
+
+		return nil, true
+	}
+
+	switch strings.ToLower(s) {
+	case "":
+		return nil, false
+
+	case "none", "transparent":
+		return nil, true
+
+	case "currentcolor":
+		return current, true
+	}
+
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s[1:])
+	}
+
+	if i := strings.IndexByte(s, '('); i > 0 && strings.HasSuffix(s, ")") {
+		switch strings.ToLower(strings.TrimSpace(s[:i])) {
+		case "rgb", "rgba":
+			return parseRGBColor(s[i+1 : len(s)-1])
+		}
+
+		return nil, false
+	}
+
+	if rgb, ok := namedColors[strings.ToLower(s)]; ok {
+		return color.NRGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xFF}, true
+	}
+
+	return nil, false
+}
+
+func parseHexColor(hex string) (color.Color, bool) {
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+
+	nibble := func(shift uint) uint8 {
+		n := uint8(v>>shift) & 0xF
+		return n<<4 | n
+	}
+
+	switch len(hex) {
+	case 3:
+		return color.NRGBA{nibble(8), nibble(4), nibble(0), 0xFF}, true
+
+	case 4:
+		return color.NRGBA{nibble(12), nibble(8), nibble(4), nibble(0)}, true
+
+	case 6:
+		return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xFF}, true
+
+	case 8:
+		return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+	}
+
+	return nil, false
+}
+
+func parseRGBColor(args string) (color.Color, bool) {
+	parts := strings.FieldsFunc(args, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/'
+	})
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, false
+	}
+
+	var c [4]uint8
+	c[3] = 0xFF
+
+	for i, part := range parts {
+		v := number(part, -1)
+		if v < 0 {
+			return nil, false
+		}
+
+		if strings.HasSuffix(part, "%") || i == 3 {
+			v *= 0xFF
+		}
+
+		c[i] = uint8(math.Round(math.Min(v, 0xFF)))
+	}
+
+	return color.NRGBA{c[0], c[1], c[2], c[3]}, true
+}
+
+// namedColors are the most common of the CSS color keywords.
+var namedColors = map[string]uint32{
+	"aqua":      0x00FFFF,
+	"black":     0x000000,
+	"blue":      0x0000FF,
+	"brown":     0xA52A2A,
+	"crimson":   0xDC143C,
+	"cyan":      0x00FFFF,
+	"darkblue":  0x00008B,
+	"darkgray":  0xA9A9A9,
+	"darkgreen": 0x006400,
+	"darkgrey":  0xA9A9A9,
+	"darkred":   0x8B0000,
+	"dimgray":   0x696969,
+	"dimgrey":   0x696969,
+	"fuchsia":   0xFF00FF,
+	"gold":      0xFFD700,
+	"gray":      0x808080,
+	"green":     0x008000,
+	"grey":      0x808080,
+	"indigo":    0x4B0082,
+	"lightblue": 0xADD8E6,
+	"lightgray": 0xD3D3D3,
+	"lightgrey": 0xD3D3D3,
+	"lime":      0x00FF00,
+	"magenta":   0xFF00FF,
+	"maroon":    0x800000,
+	"navy":      0x000080,
+	"olive":     0x808000,
+	"orange":    0xFFA500,
+	"pink":      0xFFC0CB,
+	"purple":    0x800080,
+	"red":       0xFF0000,
+	"silver":    0xC0C0C0,
+	"teal":      0x008080,
+	"violet":    0xEE82EE,
+	"white":     0xFFFFFF,
+	"yellow":    0xFFFF00,
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"math"
+
+	"github.com/lxn/walk/vector"
+)
+
+// parsePathData adds the subpaths of the path data d to p. Like browsers do,
+// it keeps what was parsed up to the first error.
+func parsePathData(p *vector.Path, d string) {
+	sc := scanner{s: d}
+
+	var cmd byte
+	var x, y float64           // The current point.
+	var startX, startY float64 // The start of the current subpath.
+	var ctrlX, ctrlY float64   // The last control point, for smooth curves.
+	var lastCmd byte
+	closed := false
+
+	for !sc.done() {
+		if c := sc.s[sc.i]; isCommand(c) {
+			cmd = c
+			sc.i++
+		} else if cmd == 0 {
+			return
+		} else if cmd == 'M' {
+			// Coordinates after a move are implicit lines.
+			cmd = 'L'
+		} else if cmd == 'm' {
+			cmd = 'l'
+		}
+
+		upper := cmd &^ 0x20
+		relative := cmd != upper
+
+		var args [7]float64
+		for i := 0; i < argCounts[upper]; i++ {
+			var ok bool
+			if upper == 'A' && (i == 3 || i == 4) {
+				var f bool
+				f, ok = sc.flag()
+				if f {
+					args[i] = 1
+				}
+			} else {
+				args[i], ok = sc.number()
+			}
+			if !ok {
+				return
+			}
+		}
+
+		// After closing, drawing continues from the start of the closed
+		// subpath.
+		if closed && upper != 'M' && upper != 'Z' {
+			p.MoveTo(startX, startY)
+		}
+		closed = false
+
+		abs := func(i int) (float64, float64) {
+			if relative {
+				return x + args[i], y + args[i+1]
+			}
+			return args[i], args[i+1]
+		}
+
+		switch upper {
+		case 'M':
+			x, y = abs(0)
+			startX, startY = x, y
+			p.MoveTo(x, y)
+
+		case 'L':
+			x, y = abs(0)
+			p.LineTo(x, y)
+
+		case 'H':
+			if relative {
+				x += args[0]
+			} else {
+				x = args[0]
+			}
+			p.LineTo(x, y)
+
+		case 'V':
+			if relative {
+				y += args[0]
+			} else {
+				y = args[0]
+			}
+			p.LineTo(x, y)
+
+		case 'C':
+			c1x, c1y := abs(0)
+			c2x, c2y := abs(2)
+			ex, ey := abs(4)
+			p.CubeTo(c1x, c1y, c2x, c2y, ex, ey)
+			ctrlX, ctrlY, x, y = c2x, c2y, ex, ey
+
+		case 'S':
+			c1x, c1y := x, y
+			if l := lastCmd &^ 0x20; l == 'C' || l == 'S' {
+				c1x, c1y = 2*x-ctrlX, 2*y-ctrlY
+			}
+			c2x, c2y := abs(0)
+			ex, ey := abs(2)
+			p.CubeTo(c1x, c1y, c2x, c2y, ex, ey)
+			ctrlX, ctrlY, x, y = c2x, c2y, ex, ey
+
+		case 'Q':
+			cx, cy := abs(0)
+			ex, ey := abs(2)
+			p.QuadTo(cx, cy, ex, ey)
+			ctrlX, ctrlY, x, y = cx, cy, ex, ey
+
+		case 'T':
+			cx, cy := x, y
+			if l := lastCmd &^ 0x20; l == 'Q' || l == 'T' {
+				cx, cy = 2*x-ctrlX, 2*y-ctrlY
+			}
+			ex, ey := abs(0)
+			p.QuadTo(cx, cy, ex, ey)
+			ctrlX, ctrlY, x, y = cx, cy, ex, ey
+
+		case 'A':
+			ex, ey := abs(5)
+			arcTo(p, x, y, args[0], args[1], args[2]*math.Pi/180, args[3] != 0, args[4] != 0, ex, ey)
+			x, y = ex, ey
+
+		case 'Z':
+			p.Close()
+			x, y = startX, startY
+			closed = true
+		}
+
+		lastCmd = cmd
+	}
+}
+
+// argCounts are the numbers of arguments of the path commands.
+var argCounts = map[byte]int{'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0}
+
+func isCommand(c byte) bool {
+	_, ok := argCounts[c&^0x20]
+	return ok
+}
+
+// arcTo adds an elliptical arc from x0, y0 to x, y to p, as specified by the
+// SVG arc command, approximated with cubic Béziers.
+func arcTo(p *vector.Path, x0, y0, rx, ry, phi float64, largeArc, sweep bool, x, y float64) {
+	if x0 == x && y0 == y {
+		return
+	}
+
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.LineTo(x, y)
+		return
+	}
+
+	// Convert from endpoint to center parameterization, see
+	// https://www.w3.org/TR/SVG/implnote.html#ArcConversionEndpointToCenter
+	sinPhi, cosPhi := math.Sincos(phi)
+
+	dx, dy := (x0-x)/2, (y0-y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// Scale up radii that are too small to reach the end point.
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx *= s
+		ry *= s
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+
+	cx := cosPhi*cx1 - sinPhi*cy1 + (x0+x)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (y0+y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+
+	theta1 := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	dTheta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && dTheta > 0 {
+		dTheta -= 2 * math.Pi
+	} else if sweep && dTheta < 0 {
+		dTheta += 2 * math.Pi
+	}
+
+	point := func(t float64) (float64, float64) {
+		sin, cos := math.Sincos(t)
+		return cx + rx*cos*cosPhi - ry*sin*sinPhi, cy + rx*cos*sinPhi + ry*sin*cosPhi
+	}
+	derivative := func(t float64) (float64, float64) {
+		sin, cos := math.Sincos(t)
+		return -rx*sin*cosPhi - ry*cos*sinPhi, -rx*sin*sinPhi + ry*cos*cosPhi
+	}
+
+	// Segments of at most 90° each.
+	n := int(math.Ceil(math.Abs(dTheta) / (math.Pi / 2)))
+	delta := dTheta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(delta/4)
+
+	t := theta1
+	for i := 0; i < n; i++ {
+		ax, ay := point(t)
+		adx, ady := derivative(t)
+		bx, by := point(t + delta)
+		bdx, bdy := derivative(t + delta)
+
+		if i == n-1 {
+			bx, by = x, y
+		}
+
+		p.CubeTo(ax+k*adx, ay+k*ady, bx-k*bdx, by-k*bdy, bx, by)
+
+		t += delta
+	}
+}
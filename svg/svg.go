@@ -0,0 +1,411 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package svg renders SVG documents of the kind that icons are made of.
+//
+// It supports the shapes path, rect, circle, ellipse, line, polyline and
+// polygon, nested in groups with transforms, painted with solid colors,
+// opacity, fill rules, stroke widths, joins, caps and dashes. Presentation
+// attributes and style attributes are both understood. Gradients, patterns,
+// text, images, masks, filters, <use> and CSS style sheets are not; paints
+// that reference them fall back to their fallback color, if any.
+//
+// The vector package does the rendering, so documents can be rendered on any
+// platform.
+package svg
+
+import (
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/lxn/walk/vector"
+)
+
+// Document is a parsed SVG document.
+type Document struct {
+	// Width and Height are the size of the document in pixels at 96dpi.
+	Width, Height float64
+
+	// CurrentColor is the color that "currentColor" refers to. It defaults
+	// to black.
+	CurrentColor color.Color
+
+	root             *node
+	viewBoxTransform vector.Matrix
+}
+
+type node struct {
+	attrs     map[string]string
+	transform vector.Matrix
+	path      *vector.Path
+	children  []*node
+}
+
+// Parse reads an SVG document from r.
+func Parse(r io.Reader) (*Document, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+
+	var stack []*node
+	var root *node
+	var viewBox []float64
+	var width, height float64
+	var skipDepth int
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+
+			name := tok.Name.Local
+
+			attrs := make(map[string]string, len(tok.Attr))
+			for _, a := range tok.Attr {
+				if a.Name.Space == "" || a.Name.Space == "http://www.w3.org/2000/svg" {
+					attrs[a.Name.Local] = strings.TrimSpace(a.Value)
+				}
+			}
+			if style, ok := attrs["style"]; ok {
+				for _, decl := range strings.Split(style, ";") {
+					if i := strings.IndexByte(decl, ':'); i > 0 {
+						attrs[strings.TrimSpace(decl[:i])] = strings.TrimSpace(decl[i+1:])
+					}
+				}
+			}
+
+			if root == nil {
+				if name != "svg" {
+					return nil, errors.New("svg: root element is not <svg>")
+				}
+
+				viewBox = numbers(attrs["viewBox"])
+				width = length(attrs["width"])
+				height = length(attrs["height"])
+			}
+
+			n := &node{attrs: attrs, transform: parseTransform(attrs["transform"])}
+
+			switch name {
+			case "svg", "g", "a", "switch":
+
+			case "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+				n.path = shapePath(name, attrs)
+
+			default:
+				// Everything else is either not rendered directly, like
+				// <defs>, or not supported.
+				skipDepth = 1
+				continue
+			}
+
+			if root == nil {
+				root = n
+			} else if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("svg: no <svg> element")
+	}
+
+	doc := &Document{root: root, CurrentColor: color.Black}
+
+	if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 {
+		if width <= 0 && height <= 0 {
+			width, height = viewBox[2], viewBox[3]
+		} else if width <= 0 {
+			width = height * viewBox[2] / viewBox[3]
+		} else if height <= 0 {
+			height = width * viewBox[3] / viewBox[2]
+		}
+
+		// Fit the view box into the document, centered, preserving its
+		// aspect ratio.
+		scale := math.Min(width/viewBox[2], height/viewBox[3])
+		doc.viewBoxTransform = vector.Translation(
+			(width-viewBox[2]*scale)/2,
+			(height-viewBox[3]*scale)/2).
+			Scale(scale, scale).
+			Translate(-viewBox[0], -viewBox[1])
+	} else {
+		if width <= 0 {
+			width = 16
+		}
+		if height <= 0 {
+			height = 16
+		}
+
+		doc.viewBoxTransform = vector.Identity()
+	}
+
+	doc.Width, doc.Height = width, height
+
+	return doc, nil
+}
+
+// Render returns the Document rendered into a transparent image of width by
+// height pixels. The image is premultiplied, like all *image.RGBA.
+func (d *Document) Render(width, height int) *image.RGBA {
+	im := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	ctx := vector.NewContext(im)
+	ctx.Scale(float64(width)/d.Width, float64(height)/d.Height)
+
+	d.Draw(ctx)
+
+	return im
+}
+
+// Draw draws the Document with ctx, with its top left corner at the origin
+// of the user space of ctx and one pixel of the document per unit.
+func (d *Document) Draw(ctx *vector.Context) {
+	ctx.Save()
+	defer ctx.Restore()
+
+	ctx.SetTransform(ctx.Transform().Multiply(d.viewBoxTransform))
+
+	d.drawNode(ctx, d.root, defaultAttrs, 1)
+}
+
+// defaultAttrs are the initial values of the inherited properties.
+var defaultAttrs = map[string]string{
+	"fill":              "black",
+	"fill-opacity":      "1",
+	"fill-rule":         "nonzero",
+	"stroke":            "none",
+	"stroke-opacity":    "1",
+	"stroke-width":      "1",
+	"stroke-linecap":    "butt",
+	"stroke-linejoin":   "miter",
+	"stroke-miterlimit": "4",
+	"stroke-dasharray":  "none",
+	"stroke-dashoffset": "0",
+	"visibility":        "visible",
+	"color":             "",
+}
+
+func (d *Document) drawNode(ctx *vector.Context, n *node, inherited map[string]string, opacity float64) {
+	if n.attrs["display"] == "none" {
+		return
+	}
+
+	attrs := make(map[string]string, len(inherited))
+	for k, v := range inherited {
+		attrs[k] = v
+	}
+	for k := range defaultAttrs {
+		if v, ok := n.attrs[k]; ok && v != "inherit" {
+			attrs[k] = v
+		}
+	}
+
+	// Group opacity is approximated by applying it to each shape.
+	if v, ok := n.attrs["opacity"]; ok {
+		opacity *= clampUnit(number(v, 1))
+	}
+
+	ctx.Save()
+	defer ctx.Restore()
+
+	ctx.SetTransform(ctx.Transform().Multiply(n.transform))
+
+	if n.path != nil && attrs["visibility"] == "visible" {
+		d.drawShape(ctx, n.path, attrs, opacity)
+	}
+
+	for _, child := range n.children {
+		d.drawNode(ctx, child, attrs, opacity)
+	}
+}
+
+func (d *Document) drawShape(ctx *vector.Context, path *vector.Path, attrs map[string]string, opacity float64) {
+	ctx.SetAlpha(opacity)
+
+	current := d.CurrentColor
+	if c, ok := parseColor(attrs["color"], nil); ok && c != nil {
+		current = c
+	}
+
+	if fill, ok := parseColor(attrs["fill"], current); ok && fill != nil {
+		ctx.SetFillColor(withOpacity(fill, number(attrs["fill-opacity"], 1)))
+		if attrs["fill-rule"] == "evenodd" {
+			ctx.SetFillRule(vector.EvenOdd)
+		} else {
+			ctx.SetFillRule(vector.NonZero)
+		}
+
+		ctx.Fill(path)
+	}
+
+	stroke, ok := parseColor(attrs["stroke"], current)
+	if !ok || stroke == nil {
+		return
+	}
+
+	width := length(attrs["stroke-width"])
+	if width <= 0 {
+		return
+	}
+
+	ctx.SetStrokeColor(withOpacity(stroke, number(attrs["stroke-opacity"], 1)))
+	ctx.SetLineWidth(width)
+	ctx.SetMiterLimit(number(attrs["stroke-miterlimit"], 4))
+
+	switch attrs["stroke-linecap"] {
+	case "round":
+		ctx.SetLineCap(vector.RoundCap)
+
+	case "square":
+		ctx.SetLineCap(vector.SquareCap)
+
+	default:
+		ctx.SetLineCap(vector.ButtCap)
+	}
+
+	switch attrs["stroke-linejoin"] {
+	case "round":
+		ctx.SetLineJoin(vector.RoundJoin)
+
+	case "bevel":
+		ctx.SetLineJoin(vector.BevelJoin)
+
+	default:
+		ctx.SetLineJoin(vector.MiterJoin)
+	}
+
+	dashes := numbers(attrs["stroke-dasharray"])
+	if len(dashes)%2 == 1 {
+		dashes = append(dashes, dashes...)
+	}
+	var total float64
+	for _, d := range dashes {
+		if d < 0 {
+			total = 0
+			break
+		}
+		total += d
+	}
+	if total > 0 {
+		ctx.SetDash(number(attrs["stroke-dashoffset"], 0), dashes...)
+	} else {
+		ctx.SetDash(0)
+	}
+
+	ctx.Stroke(path)
+}
+
+func withOpacity(c color.Color, opacity float64) color.Color {
+	opacity = clampUnit(opacity)
+	if opacity == 1 {
+		return c
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(math.Round(float64(n.A) * opacity))
+
+	return n
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(v, 1))
+}
+
+// shapePath returns the outline of the basic shape or path element name, or
+// an empty path if it has invalid geometry.
+func shapePath(name string, attrs map[string]string) *vector.Path {
+	p := new(vector.Path)
+
+	num := func(key string) float64 {
+		return length(attrs[key])
+	}
+
+	switch name {
+	case "path":
+		parsePathData(p, attrs["d"])
+
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			break
+		}
+
+		rx, hasRX := attrs["rx"]
+		ry, hasRY := attrs["ry"]
+		if !hasRX {
+			rx = ry
+		}
+		if !hasRY {
+			ry = rx
+		}
+		roundedRect(p, x, y, w, h, math.Min(length(rx), w/2), math.Min(length(ry), h/2))
+
+	case "circle":
+		if r := num("r"); r > 0 {
+			p.Ellipse(num("cx"), num("cy"), r, r)
+		}
+
+	case "ellipse":
+		if rx, ry := num("rx"), num("ry"); rx > 0 && ry > 0 {
+			p.Ellipse(num("cx"), num("cy"), rx, ry)
+		}
+
+	case "line":
+		p.MoveTo(num("x1"), num("y1"))
+		p.LineTo(num("x2"), num("y2"))
+
+	case "polyline", "polygon":
+		points := numbers(attrs["points"])
+		for i := 0; i+1 < len(points); i += 2 {
+			p.LineTo(points[i], points[i+1])
+		}
+		if name == "polygon" {
+			p.Close()
+		}
+	}
+
+	return p
+}
+
+func roundedRect(p *vector.Path, x, y, w, h, rx, ry float64) {
+	if rx <= 0 || ry <= 0 {
+		p.Rect(x, y, w, h)
+		return
+	}
+
+	p.EllipticArc(x+w-rx, y+ry, rx, ry, -math.Pi/2, 0)
+	p.EllipticArc(x+w-rx, y+h-ry, rx, ry, 0, math.Pi/2)
+	p.EllipticArc(x+rx, y+h-ry, rx, ry, math.Pi/2, math.Pi)
+	p.EllipticArc(x+rx, y+ry, rx, ry, math.Pi, 3*math.Pi/2)
+	p.Close()
+}
@@ -0,0 +1,301 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image/color"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lxn/walk/vector"
+)
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []float64
+	}{
+		{"", nil},
+		{"1 2,3", []float64{1, 2, 3}},
+		{" 1 , 2 ", []float64{1, 2}},
+		{"1-2.5.5", []float64{1, -2.5, 0.5}},
+		{"+1e2,3E-1", []float64{100, 0.3}},
+		{"1e", []float64{1}},
+		{"1 x 2", []float64{1}},
+	}
+
+	for _, test := range tests {
+		if got := numbers(test.s); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("numbers(%q): got %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"10", 10},
+		{"10px", 10},
+		{" 10 px ", 10},
+		{"72pt", 96},
+		{"1pc", 16},
+		{"25.4mm", 96},
+		{"2.54cm", 96},
+		{"1in", 96},
+		{"2em", 32},
+		{"50%", 0},
+		{"10furlongs", 0},
+		{"abc", 0},
+		{"", 0},
+	}
+
+	for _, test := range tests {
+		if got := length(test.s); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("length(%q): got %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		s    string
+		def  float64
+		want float64
+	}{
+		{"0.5", 1, 0.5},
+		{"50%", 1, 0.5},
+		{"", 1, 1},
+		{"x", 2, 2},
+	}
+
+	for _, test := range tests {
+		if got := number(test.s, test.def); got != test.want {
+			t.Errorf("number(%q, %v): got %v, want %v", test.s, test.def, got, test.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	current := color.NRGBA{1, 2, 3, 4}
+
+	tests := []struct {
+		s      string
+		want   color.Color
+		wantOK bool
+	}{
+		{"#f00", color.NRGBA{0xFF, 0, 0, 0xFF}, true},
+		{"#f008", color.NRGBA{0xFF, 0, 0, 0x88}, true},
+		{"#00ff00", color.NRGBA{0, 0xFF, 0, 0xFF}, true},
+		{"#0000ff80", color.NRGBA{0, 0, 0xFF, 0x80}, true},
+		{"rgb(255, 128, 0)", color.NRGBA{0xFF, 0x80, 0, 0xFF}, true},
+		{"rgb(100%,0%,50%)", color.NRGBA{0xFF, 0, 0x80, 0xFF}, true},
+		{"rgba(0 0 255 / 0.5)", color.NRGBA{0, 0, 0xFF, 0x80}, true},
+		{"RGB(300,0,0)", color.NRGBA{0xFF, 0, 0, 0xFF}, true},
+		{"Red", color.NRGBA{0xFF, 0, 0, 0xFF}, true},
+		{" navy ", color.NRGBA{0, 0, 0x80, 0xFF}, true},
+		{"currentColor", current, true},
+		{"none", nil, true},
+		{"transparent", nil, true},
+		{"url(#gradient)", nil, true},
+		{"url(#gradient) blue", color.NRGBA{0, 0, 0xFF, 0xFF}, true},
+		{"", nil, false},
+		{"#ggg", nil, false},
+		{"#12345", nil, false},
+		{"rgb(1,2)", nil, false},
+		{"rgb(-1,2,3)", nil, false},
+		{"hsl(0, 100%, 50%)", nil, false},
+		{"notacolor", nil, false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseColor(test.s, current)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("parseColor(%q): got %v, %t, want %v, %t", test.s, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		s    string
+		p    vector.Point
+		want vector.Point
+	}{
+		{"", vector.Point{X: 1, Y: 2}, vector.Point{X: 1, Y: 2}},
+		{"translate(10,20)", vector.Point{X: 1, Y: 2}, vector.Point{X: 11, Y: 22}},
+		{"translate(10)", vector.Point{X: 1, Y: 2}, vector.Point{X: 11, Y: 2}},
+		{"scale(2)", vector.Point{X: 1, Y: 2}, vector.Point{X: 2, Y: 4}},
+		{"scale(2 3)", vector.Point{X: 1, Y: 2}, vector.Point{X: 2, Y: 6}},
+		{"rotate(90)", vector.Point{X: 1, Y: 0}, vector.Point{X: 0, Y: 1}},
+		{"rotate(90, 1, 1)", vector.Point{X: 2, Y: 1}, vector.Point{X: 1, Y: 2}},
+		{"skewX(45)", vector.Point{X: 0, Y: 1}, vector.Point{X: 1, Y: 1}},
+		{"skewY(45)", vector.Point{X: 1, Y: 0}, vector.Point{X: 1, Y: 1}},
+		{"matrix(1 0 0 1 5 6)", vector.Point{X: 0, Y: 0}, vector.Point{X: 5, Y: 6}},
+		{"matrix(1 0 0 1 5)", vector.Point{X: 0, Y: 0}, vector.Point{X: 0, Y: 0}},
+		// The transforms of a list apply from right to left.
+		{"translate(10) scale(2)", vector.Point{X: 1, Y: 1}, vector.Point{X: 12, Y: 2}},
+		{"scale(2),translate(10)", vector.Point{X: 1, Y: 1}, vector.Point{X: 22, Y: 2}},
+		{"bogus(1) translate(1 1)", vector.Point{X: 0, Y: 0}, vector.Point{X: 1, Y: 1}},
+	}
+
+	for _, test := range tests {
+		got := parseTransform(test.s).Apply(test.p)
+		if math.Abs(got.X-test.want.X) > 1e-9 || math.Abs(got.Y-test.want.Y) > 1e-9 {
+			t.Errorf("parseTransform(%q) applied to %v: got %v, want %v", test.s, test.p, got, test.want)
+		}
+	}
+}
+
+func TestParsePathData(t *testing.T) {
+	tests := []struct {
+		d      string
+		want   vector.Point
+		wantOK bool
+	}{
+		{"", vector.Point{}, false},
+		// Like a move, the first line starts a subpath.
+		{"L1 1", vector.Point{X: 1, Y: 1}, true},
+		{"1 1", vector.Point{}, false},
+		{"M1,2 l3,4", vector.Point{X: 4, Y: 6}, true},
+		{"M1 2 3 4", vector.Point{X: 3, Y: 4}, true},
+		{"m1 2 3 4", vector.Point{X: 4, Y: 6}, true},
+		{"M0 0 h5 v-2", vector.Point{X: 5, Y: -2}, true},
+		{"M0 0 H5 V7", vector.Point{X: 5, Y: 7}, true},
+		{"M0 0 C1 1 2 2 3 3 s1 1 2 2", vector.Point{X: 5, Y: 5}, true},
+		{"M0 0 Q1 1 2 0 t2 0", vector.Point{X: 4, Y: 0}, true},
+		{"M0 0 A5 5 0 1 0 10 0", vector.Point{X: 10, Y: 0}, true},
+		{"M0 0 a5 5 0 1010 0", vector.Point{X: 10, Y: 0}, true},
+		{"M0-1.5.5.25", vector.Point{X: 0.5, Y: 0.25}, true},
+		// Drawing continues from the start of a closed subpath.
+		{"M1 1 L5 1 5 5 Z l2 0", vector.Point{X: 3, Y: 1}, true},
+		// What was parsed up to an error is kept.
+		{"M0 0 L1 1 x 5 5", vector.Point{X: 1, Y: 1}, true},
+		{"M0 0 L1 1 L2", vector.Point{X: 1, Y: 1}, true},
+	}
+
+	for _, test := range tests {
+		var p vector.Path
+		parsePathData(&p, test.d)
+
+		x, y, ok := p.CurrentPoint()
+		if ok != test.wantOK || (ok && (math.Abs(x-test.want.X) > 1e-9 || math.Abs(y-test.want.Y) > 1e-9)) {
+			t.Errorf("parsePathData(%q): got current point %v, %v, %t, want %v, %t", test.d, x, y, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		svg           string
+		width, height float64
+	}{
+		{`<svg/>`, 16, 16},
+		{`<svg width="32" height="24"/>`, 32, 24},
+		{`<svg width="1in" height="72pt"/>`, 96, 96},
+		{`<svg viewBox="0 0 16 8"/>`, 16, 8},
+		{`<svg width="32" viewBox="0 0 16 8"/>`, 32, 16},
+		{`<svg height="32" viewBox="0,0,16,8"/>`, 64, 32},
+		{`<svg width="100%" height="100%" viewBox="0 0 24 24"/>`, 24, 24},
+	}
+
+	for _, test := range tests {
+		doc, err := Parse(strings.NewReader(test.svg))
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.svg, err)
+			continue
+		}
+
+		if doc.Width != test.width || doc.Height != test.height {
+			t.Errorf("Parse(%q): got size %vx%v, want %vx%v", test.svg, doc.Width, doc.Height, test.width, test.height)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		svg  string
+		want string
+	}{
+		{``, "svg: no <svg> element"},
+		{`<?xml version="1.0"?>`, "svg: no <svg> element"},
+		{`<html><svg/></html>`, "svg: root element is not <svg>"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.svg))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Parse(%q): got error %v, want %q", test.svg, err, test.want)
+		}
+	}
+}
+
+// checkPixel checks the premultiplied color of a rendered pixel, allowing
+// for rounding.
+func checkPixel(t *testing.T, what string, got, want color.RGBA) {
+	t.Helper()
+
+	diff := func(a, b uint8) bool {
+		return math.Abs(float64(a)-float64(b)) > 1
+	}
+
+	if diff(got.R, want.R) || diff(got.G, want.G) || diff(got.B, want.B) || diff(got.A, want.A) {
+		t.Errorf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	const doc = `<svg xmlns="http://www.w3.org/2000/svg" width="8" height="8" viewBox="0 0 4 4">
+	<defs><linearGradient id="g"><stop offset="0" stop-color="red"/></linearGradient></defs>
+	<rect width="2" height="1" fill="#f00"/>
+	<rect y="1" width="2" height="1" style="fill: blue; fill-opacity: 0.5"/>
+	<g fill="lime" transform="translate(2 0)">
+		<rect width="2" height="1"/>
+		<rect y="1" width="2" height="1" fill="inherit" opacity="0.5"/>
+	</g>
+	<g display="none"><rect y="2" width="4" height="2"/></g>
+	<rect y="2" width="2" height="2" fill="url(#g)"/>
+	<rect x="2" y="2" width="2" height="2" visibility="hidden"/>
+	<path d="M0 0" fill="red"/>
+</svg>`
+
+	d, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	im := d.Render(8, 8)
+
+	checkPixel(t, "fill", im.RGBAAt(1, 1), color.RGBA{0xFF, 0, 0, 0xFF})
+	checkPixel(t, "style fill with fill-opacity", im.RGBAAt(1, 3), color.RGBA{0, 0, 0x80, 0x80})
+	checkPixel(t, "inherited fill with transform", im.RGBAAt(5, 1), color.RGBA{0, 0xFF, 0, 0xFF})
+	checkPixel(t, "opacity", im.RGBAAt(5, 3), color.RGBA{0, 0x80, 0, 0x80})
+	checkPixel(t, "display none and paint server without fallback", im.RGBAAt(1, 5), color.RGBA{})
+	checkPixel(t, "visibility hidden", im.RGBAAt(5, 5), color.RGBA{})
+}
+
+func TestRenderStroke(t *testing.T) {
+	const doc = `<svg width="16" height="16">
+	<line x1="0" y1="8" x2="16" y2="8" stroke="currentColor" stroke-width="2"/>
+	<polyline points="8,0 8,4" stroke="red" stroke-width="0"/>
+</svg>`
+
+	d, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.CurrentColor = color.NRGBA{0, 0, 0xFF, 0xFF}
+
+	im := d.Render(16, 16)
+
+	checkPixel(t, "stroke of current color", im.RGBAAt(4, 7), color.RGBA{0, 0, 0xFF, 0xFF})
+	checkPixel(t, "stroke of current color", im.RGBAAt(4, 8), color.RGBA{0, 0, 0xFF, 0xFF})
+	checkPixel(t, "outside of stroke", im.RGBAAt(4, 5), color.RGBA{})
+	checkPixel(t, "stroke of zero width", im.RGBAAt(8, 2), color.RGBA{})
+}
@@ -108,6 +108,7 @@ type TableView struct {
 	alternatingRowBGColor              Color
 	alternatingRowTextColor            Color
 	alternatingRowBG                   bool
+	listViewsDarkThemed                bool
 	delayedCurrentIndexChangedCanceled bool
 	sortedColumnIndex                  int
 	sortOrder                          SortOrder
@@ -416,6 +417,8 @@ func (tv *TableView) ApplyDPI(dpi int) {
 func (tv *TableView) ApplySysColors() {
 	tv.WidgetBase.ApplySysColors()
 
+	tv.applyListViewThemes()
+
 	// As some combinations of property and state may be invalid for any theme,
 	// we set some defaults here.
 	tv.themeNormalBGColor = Color(win.GetSysColor(win.COLOR_WINDOW))
@@ -467,10 +470,41 @@ func (tv *TableView) ApplySysColors() {
 		})
 	}
 
+	// These are also the defaults of the cell styles a CellStyler gets.
+	theme := App().Theme()
+	theme.overrideColor(ThemeColorWindow, &tv.themeNormalBGColor)
+	theme.overrideColor(ThemeColorWindowText, &tv.themeNormalTextColor)
+	theme.overrideColor(ThemeColorHighlight, &tv.themeSelectedBGColor)
+	theme.overrideColor(ThemeColorHighlightText, &tv.themeSelectedTextColor)
+	theme.overrideColor(ThemeColorInactiveHighlight, &tv.themeSelectedNotFocusedBGColor)
+	theme.overrideColor(ThemeColorAlternateRow, &tv.alternatingRowBGColor)
+	theme.overrideColor(ThemeColorAlternateRowText, &tv.alternatingRowTextColor)
+
 	win.SendMessage(tv.hwndNormalLV, win.LVM_SETBKCOLOR, 0, uintptr(tv.themeNormalBGColor))
 	win.SendMessage(tv.hwndFrozenLV, win.LVM_SETBKCOLOR, 0, uintptr(tv.themeNormalBGColor))
 }
 
+// applyListViewThemes sets the visual styles of the list views and their
+// headers according to the theme of the application.
+func (tv *TableView) applyListViewThemes() {
+	theme := App().Theme()
+	if !theme.Dark() && !tv.listViewsDarkThemed {
+		return
+	}
+
+	tv.listViewsDarkThemed = theme.Dark()
+
+	setWindowThemeForTheme(tv.hwndFrozenLV, "Explorer", theme)
+	setWindowThemeForTheme(tv.hwndNormalLV, "Explorer", theme)
+
+	var hdrAppName *uint16
+	if theme.Dark() {
+		hdrAppName = syscall.StringToUTF16Ptr("DarkMode_ItemsView")
+	}
+	win.SetWindowTheme(tv.hwndFrozenHdr, hdrAppName, nil)
+	win.SetWindowTheme(tv.hwndNormalHdr, hdrAppName, nil)
+}
+
 // ColumnsOrderable returns if the user can reorder columns by dragging and
 // dropping column headers.
 func (tv *TableView) ColumnsOrderable() bool {
@@ -837,6 +871,10 @@ func (tv *TableView) SetModel(mdl interface{}) error {
 				return err
 			}
 		}
+
+		if obs, ok := mdl.(*ObservableSlice); ok {
+			obs.synchronizer.setWindow(tv)
+		}
 	}
 
 	tv.SetSuspended(true)
@@ -926,6 +964,57 @@ func (tv *TableView) SetCellStyler(styler CellStyler) {
 	tv.styler = styler
 }
 
+// cellText returns the text that the cell at row and col displays, col being
+// an index into tv.columns.
+func (tv *TableView) cellText(row, col int) string {
+	value := tv.model.Value(row, col)
+	var text string
+	if format := tv.columns.items[col].formatFunc; format != nil {
+		text = format(value)
+	} else {
+		switch val := value.(type) {
+		case string:
+			text = val
+
+		case float32:
+			prec := tv.columns.items[col].precision
+			if prec == 0 {
+				prec = 2
+			}
+			text = FormatFloatGrouped(float64(val), prec)
+
+		case float64:
+			prec := tv.columns.items[col].precision
+			if prec == 0 {
+				prec = 2
+			}
+			text = FormatFloatGrouped(val, prec)
+
+		case time.Time:
+			if val.Year() > 1601 {
+				text = val.Format(tv.columns.items[col].format)
+			}
+
+		case bool:
+			if val {
+				text = checkmark
+			}
+
+		case *big.Rat:
+			prec := tv.columns.items[col].precision
+			if prec == 0 {
+				prec = 2
+			}
+			text = formatBigRatGrouped(val, prec)
+
+		default:
+			text = fmt.Sprintf(tv.columns.items[col].format, val)
+		}
+	}
+
+	return text
+}
+
 func (tv *TableView) setItemCount() error {
 	var count int
 
@@ -1282,6 +1371,45 @@ func (tv *TableView) EnsureItemVisible(index int) {
 	win.SendMessage(tv.hwndNormalLV, win.LVM_ENSUREVISIBLE, uintptr(index), 0)
 }
 
+// Find makes the next row after the current one current whose text in a
+// visible column matches text, or the previous row if opts.Backward is set.
+// The search wraps around. It returns whether there was a match.
+//
+// The text of cells is formatted like for display, but taken from the
+// model, so that rows are found whether they are visible or not.
+func (tv *TableView) Find(text string, opts FindOptions) bool {
+	re, _ := FindRegexp(text, opts)
+	if re == nil || tv.model == nil {
+		return false
+	}
+
+	count := tv.model.RowCount()
+
+	row := tv.currentIndex
+	if row < 0 && opts.Backward {
+		row = 0
+	}
+
+	for i := 0; i < count; i++ {
+		if opts.Backward {
+			row = (row + count - 1) % count
+		} else {
+			row = (row + 1) % count
+		}
+
+		for col, tvc := range tv.columns.items {
+			if tvc.visible && re.MatchString(tv.cellText(row, col)) {
+				tv.SetCurrentIndex(row)
+				tv.EnsureItemVisible(row)
+
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // SelectionHiddenWithoutFocus returns whether selection indicators are hidden
 // when the TableView does not have the keyboard input focus.
 func (tv *TableView) SelectionHiddenWithoutFocus() bool {
@@ -2072,50 +2200,7 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			}
 
 			if di.Item.Mask&win.LVIF_TEXT > 0 {
-				value := tv.model.Value(row, col)
-				var text string
-				if format := tv.columns.items[col].formatFunc; format != nil {
-					text = format(value)
-				} else {
-					switch val := value.(type) {
-					case string:
-						text = val
-
-					case float32:
-						prec := tv.columns.items[col].precision
-						if prec == 0 {
-							prec = 2
-						}
-						text = FormatFloatGrouped(float64(val), prec)
-
-					case float64:
-						prec := tv.columns.items[col].precision
-						if prec == 0 {
-							prec = 2
-						}
-						text = FormatFloatGrouped(val, prec)
-
-					case time.Time:
-						if val.Year() > 1601 {
-							text = val.Format(tv.columns.items[col].format)
-						}
-
-					case bool:
-						if val {
-							text = checkmark
-						}
-
-					case *big.Rat:
-						prec := tv.columns.items[col].precision
-						if prec == 0 {
-							prec = 2
-						}
-						text = formatBigRatGrouped(val, prec)
-
-					default:
-						text = fmt.Sprintf(tv.columns.items[col].format, val)
-					}
-				}
+				text := tv.cellText(row, col)
 
 				utf16 := syscall.StringToUTF16(text)
 				buf := (*[264]uint16)(unsafe.Pointer(di.Item.PszText))
@@ -457,7 +457,7 @@ func tabWidgetTabWndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uint
 					y := rc.Top
 					s := int32(IntFrom96DPI(16, dpi))
 
-					bmp, err := iconCache.Bitmap(page.image, dpi)
+					bmp, err := iconCache.bitmap(page.image, dpi, false)
 					if err == nil {
 						if imageCanvas, err := NewCanvasFromImage(bmp); err == nil {
 							defer imageCanvas.Dispose()
@@ -649,7 +649,8 @@ func (tw *TabWidget) onClearedPages(pages []*TabPage) (err error) {
 func (tw *TabWidget) tcitemFromPage(page *TabPage) *win.TCITEM {
 	var imageIndex int32 = -1
 	if page.image != nil {
-		if bmp, err := iconCache.Bitmap(page.image, tw.DPI()); err == nil {
+		// The image list copies the bitmap, so it needn't stay valid.
+		if bmp, err := iconCache.bitmap(page.image, tw.DPI(), false); err == nil {
 			imageIndex, _ = tw.imageIndex(bmp)
 		}
 	}
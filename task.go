@@ -0,0 +1,435 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"context"
+	"sync"
+)
+
+// TaskFunc is the work of a Task. It runs on its own goroutine and should
+// return soon after ctx is done.
+type TaskFunc func(ctx context.Context, progress *TaskProgress) error
+
+// TaskProgress reports the progress of a running Task. Its methods may be
+// called from any goroutine. Updates are coalesced and applied on the UI
+// thread.
+type TaskProgress struct {
+	task            *Task
+	mutex           sync.Mutex
+	total           int
+	completed       int
+	text            string
+	updateScheduled bool
+}
+
+// SetTotal sets the amount of work. A total of 0 means the amount is unknown,
+// which shows indeterminate progress.
+func (tp *TaskProgress) SetTotal(total int) {
+	tp.update(func() {
+		tp.total = total
+	})
+}
+
+// SetCompleted sets the amount of work done so far.
+func (tp *TaskProgress) SetCompleted(completed int) {
+	tp.update(func() {
+		tp.completed = completed
+	})
+}
+
+// Add adds delta to the amount of work done so far.
+func (tp *TaskProgress) Add(delta int) {
+	tp.update(func() {
+		tp.completed += delta
+	})
+}
+
+// SetText sets a description of what the task is currently doing.
+func (tp *TaskProgress) SetText(text string) {
+	tp.update(func() {
+		tp.text = text
+	})
+}
+
+func (tp *TaskProgress) update(f func()) {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	f()
+
+	if !tp.updateScheduled {
+		tp.updateScheduled = true
+
+		tp.task.form.Synchronize(tp.apply)
+	}
+}
+
+func (tp *TaskProgress) snapshot() (total, completed int, text string) {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	tp.updateScheduled = false
+
+	return tp.total, tp.completed, tp.text
+}
+
+func (tp *TaskProgress) apply() {
+	total, completed, text := tp.snapshot()
+
+	t := tp.task
+	if t.progress != tp {
+		// A late update of a finished run.
+		return
+	}
+
+	if pb := t.progressBar; pb != nil {
+		t.applyToProgressBar(pb, total, completed)
+	}
+
+	if t.showsTaskbarProgress {
+		if pi := t.form.AsFormBase().ProgressIndicator(); pi != nil {
+			if total > 0 {
+				pi.SetState(PINormal)
+				pi.SetTotal(uint32(total))
+				pi.SetCompleted(uint32(completed))
+			} else {
+				pi.SetState(PIIndeterminate)
+			}
+		}
+	}
+
+	if t.dialog != nil {
+		t.applyToProgressBar(t.dialogProgressBar, total, completed)
+		t.dialogLabel.SetText(text)
+	}
+
+	t.progressChangedPublisher.Publish()
+}
+
+// Task runs a TaskFunc in the background and reports its progress and result
+// on the UI thread of a Form.
+//
+// A *Task is a Condition that is satisfied while it is running, so actions
+// and widgets can be disabled meanwhile. Start, Run and Cancel must be called
+// from the UI thread.
+type Task struct {
+	form                     Form
+	f                        TaskFunc
+	running                  bool
+	cancel                   context.CancelFunc
+	canceled                 bool
+	err                      error
+	progress                 *TaskProgress
+	progressBar              *ProgressBar
+	showsTaskbarProgress     bool
+	dialogTitle              string
+	dialog                   *Dialog
+	dialogLabel              *Label
+	dialogProgressBar        *ProgressBar
+	dialogCancelPB           *PushButton
+	runningChangedPublisher  EventPublisher
+	progressChangedPublisher EventPublisher
+	finishedPublisher        EventPublisher
+	canceledPublisher        EventPublisher
+	failedPublisher          ErrorEventPublisher
+}
+
+// NewTask returns a *Task that runs f and reports on the UI thread of form.
+func NewTask(form Form, f TaskFunc) *Task {
+	return &Task{
+		form:                 form,
+		f:                    f,
+		showsTaskbarProgress: true,
+	}
+}
+
+// ProgressBar returns the *ProgressBar that shows the progress, or nil.
+func (t *Task) ProgressBar() *ProgressBar {
+	return t.progressBar
+}
+
+// SetProgressBar sets the *ProgressBar that shows the progress.
+func (t *Task) SetProgressBar(pb *ProgressBar) {
+	t.progressBar = pb
+}
+
+// ShowsTaskbarProgress returns if the progress is shown on the taskbar button
+// of the form.
+//
+// By default this is true.
+func (t *Task) ShowsTaskbarProgress() bool {
+	return t.showsTaskbarProgress
+}
+
+// SetShowsTaskbarProgress sets if the progress is shown on the taskbar button
+// of the form.
+func (t *Task) SetShowsTaskbarProgress(value bool) {
+	t.showsTaskbarProgress = value
+}
+
+// DialogTitle returns the title of the progress dialog that Run shows.
+func (t *Task) DialogTitle() string {
+	return t.dialogTitle
+}
+
+// SetDialogTitle sets the title of the progress dialog that Run shows.
+func (t *Task) SetDialogTitle(title string) {
+	t.dialogTitle = title
+}
+
+// Start starts the task in the background and returns immediately.
+func (t *Task) Start() error {
+	if t.running {
+		return newError("task already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t.cancel = cancel
+	t.canceled = false
+	t.err = nil
+	t.progress = &TaskProgress{task: t}
+
+	if pb := t.progressBar; pb != nil {
+		t.applyToProgressBar(pb, 0, 0)
+	}
+
+	t.setRunning(true)
+
+	progress := t.progress
+
+	go func() {
+		err := t.f(ctx, progress)
+
+		t.form.Synchronize(func() {
+			t.finish(err)
+		})
+	}()
+
+	return nil
+}
+
+// Run starts the task and shows a modal dialog with its progress and a Cancel
+// button, until the task is done. It returns the error of the task.
+func (t *Task) Run() error {
+	dlg, err := t.createDialog()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		dlg.Dispose()
+		t.dialog = nil
+	}()
+
+	if err := t.Start(); err != nil {
+		return err
+	}
+
+	dlg.Run()
+
+	return t.err
+}
+
+// Cancel asks the running task to stop, by canceling its context.
+func (t *Task) Cancel() {
+	if !t.running || t.canceled {
+		return
+	}
+
+	t.canceled = true
+	t.cancel()
+
+	if t.dialogCancelPB != nil {
+		t.dialogCancelPB.SetEnabled(false)
+	}
+}
+
+// Running returns if the task is running.
+func (t *Task) Running() bool {
+	return t.running
+}
+
+// Err returns the error of the last run, or nil.
+func (t *Task) Err() error {
+	return t.err
+}
+
+// Value returns if the task is running.
+func (t *Task) Value() interface{} {
+	return t.running
+}
+
+// Satisfied returns if the task is running.
+func (t *Task) Satisfied() bool {
+	return t.running
+}
+
+// Changed returns the event that is published when the task starts or
+// stops running.
+func (t *Task) Changed() *Event {
+	return t.runningChangedPublisher.Event()
+}
+
+// ProgressChanged returns the event that is published on the UI thread after
+// the task reported progress.
+func (t *Task) ProgressChanged() *Event {
+	return t.progressChangedPublisher.Event()
+}
+
+// Finished returns the event that is published on the UI thread when a run
+// ends, whether it succeeded, failed or was canceled.
+func (t *Task) Finished() *Event {
+	return t.finishedPublisher.Event()
+}
+
+// Canceled returns the event that is published on the UI thread when a run
+// ends after Cancel was called.
+func (t *Task) Canceled() *Event {
+	return t.canceledPublisher.Event()
+}
+
+// Failed returns the event that is published on the UI thread when a run
+// ends with an error, unless it was canceled.
+func (t *Task) Failed() *ErrorEvent {
+	return t.failedPublisher.Event()
+}
+
+func (t *Task) setRunning(running bool) {
+	t.running = running
+
+	t.runningChangedPublisher.Publish()
+}
+
+func (t *Task) finish(err error) {
+	t.cancel()
+	t.err = err
+
+	progress := t.progress
+	t.progress = nil
+
+	if pb := t.progressBar; pb != nil {
+		pb.SetMarqueeMode(false)
+		if err == nil {
+			total, _, _ := progress.snapshot()
+			if total > 0 {
+				pb.SetValue(total)
+			}
+		}
+	}
+
+	if t.showsTaskbarProgress {
+		if pi := t.form.AsFormBase().ProgressIndicator(); pi != nil {
+			pi.SetState(PINoProgress)
+		}
+	}
+
+	t.setRunning(false)
+
+	if t.dialog != nil {
+		if t.canceled {
+			t.dialog.Cancel()
+		} else {
+			t.dialog.Accept()
+		}
+	}
+
+	if t.canceled {
+		t.canceledPublisher.Publish()
+	} else if err != nil {
+		t.failedPublisher.Publish(err)
+	}
+
+	t.finishedPublisher.Publish()
+}
+
+func (t *Task) applyToProgressBar(pb *ProgressBar, total, completed int) {
+	if total > 0 {
+		pb.SetMarqueeMode(false)
+		pb.SetRange(0, total)
+		pb.SetValue(completed)
+	} else {
+		pb.SetMarqueeMode(true)
+	}
+}
+
+func (t *Task) createDialog() (dlg *Dialog, err error) {
+	if dlg, err = NewDialogWithFixedSize(t.form); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			dlg.Dispose()
+		}
+	}()
+
+	if err := dlg.SetTitle(t.dialogTitle); err != nil {
+		return nil, err
+	}
+
+	if err := dlg.SetLayout(NewVBoxLayout()); err != nil {
+		return nil, err
+	}
+
+	dlg.SetMinMaxSize(Size{350, 0}, Size{})
+
+	if t.dialogLabel, err = NewLabel(dlg); err != nil {
+		return nil, err
+	}
+
+	if t.dialogProgressBar, err = NewProgressBar(dlg); err != nil {
+		return nil, err
+	}
+
+	buttons, err := NewComposite(dlg)
+	if err != nil {
+		return nil, err
+	}
+
+	hbl := NewHBoxLayout()
+	hbl.SetMargins(Margins{})
+	if err := buttons.SetLayout(hbl); err != nil {
+		return nil, err
+	}
+
+	if _, err := NewHSpacer(buttons); err != nil {
+		return nil, err
+	}
+
+	if t.dialogCancelPB, err = NewPushButton(buttons); err != nil {
+		return nil, err
+	}
+	t.dialogCancelPB.SetText(tr("Cancel", "walk"))
+	t.dialogCancelPB.Clicked().Attach(t.Cancel)
+
+	if err := dlg.SetCancelButton(t.dialogCancelPB); err != nil {
+		return nil, err
+	}
+
+	dlg.Closing().Attach(func(canceled *bool, reason CloseReason) {
+		// The dialog closes when the task is done, so closing it
+		// before only asks the task to stop.
+		if t.running {
+			*canceled = true
+			t.Cancel()
+		}
+	})
+
+	dlg.Disposing().Attach(func() {
+		t.dialogLabel = nil
+		t.dialogProgressBar = nil
+		t.dialogCancelPB = nil
+	})
+
+	t.dialog = dlg
+
+	succeeded = true
+
+	return dlg, nil
+}
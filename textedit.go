@@ -245,6 +245,29 @@ func (te *TextEdit) AppendText(value string) {
 	te.SetTextSelection(s, e)
 }
 
+// Find selects the next match of text after the selection, or the previous
+// one before it if opts.Backward is set. The search wraps around. It
+// returns whether there was a match.
+func (te *TextEdit) Find(text string, opts FindOptions) bool {
+	return findInTextTarget(te, text, opts)
+}
+
+// Replace replaces the selection with replacement if it is a match of text,
+// then selects the next one like Find. It returns whether there was one.
+func (te *TextEdit) Replace(text, replacement string, opts FindOptions) bool {
+	return replaceInTextTarget(te, text, replacement, opts)
+}
+
+// ReplaceAll replaces all matches of text with replacement, returning how
+// many were replaced. opts.Backward is ignored.
+func (te *TextEdit) ReplaceAll(text, replacement string, opts FindOptions) int {
+	return replaceAllInTextTarget(te, text, replacement, opts)
+}
+
+func (te *TextEdit) findText() string {
+	return te.Text()
+}
+
 func (te *TextEdit) ReadOnly() bool {
 	return te.hasStyleBits(win.ES_READONLY)
 }
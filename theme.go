@@ -0,0 +1,511 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+	"golang.org/x/sys/windows"
+)
+
+// Names of the colors a Theme provides to widgets.
+const (
+	// ThemeColorWindow is the background of content areas like table and
+	// list views.
+	ThemeColorWindow = "Window"
+
+	// ThemeColorWindowText is the text color of content areas.
+	ThemeColorWindowText = "WindowText"
+
+	// ThemeColorControl is the background of forms and containers.
+	ThemeColorControl = "Control"
+
+	// ThemeColorControlText is the text color of labels and other controls.
+	ThemeColorControlText = "ControlText"
+
+	// ThemeColorHighlight is the background of selected items.
+	ThemeColorHighlight = "Highlight"
+
+	// ThemeColorHighlightText is the text color of selected items.
+	ThemeColorHighlightText = "HighlightText"
+
+	// ThemeColorInactiveHighlight is the background of selected items of
+	// widgets that don't have the keyboard focus.
+	ThemeColorInactiveHighlight = "InactiveHighlight"
+
+	// ThemeColorGrayText is the text color of disabled items.
+	ThemeColorGrayText = "GrayText"
+
+	// ThemeColorAlternateRow is the background of every other row of table
+	// views with alternating row colors.
+	ThemeColorAlternateRow = "AlternateRow"
+
+	// ThemeColorAlternateRowText is the text color of every other row of table
+	// views with alternating row colors.
+	ThemeColorAlternateRowText = "AlternateRowText"
+
+	// ThemeColorGradient1 and ThemeColorGradient2 are the default colors of
+	// GradientComposite.
+	ThemeColorGradient1 = "Gradient1"
+	ThemeColorGradient2 = "Gradient2"
+)
+
+// Names of the fonts a Theme provides to widgets.
+const (
+	// ThemeFontDefault is the font of windows that have no font of their own
+	// and no parent with one.
+	ThemeFontDefault = "Default"
+)
+
+// themeSystemColors maps color names to the system colors used for them, if
+// a Theme doesn't provide a color itself.
+var themeSystemColors = map[string]SystemColor{
+	ThemeColorWindow:            SysColorWindow,
+	ThemeColorWindowText:        SysColorWindowText,
+	ThemeColorControl:           SysColorBtnFace,
+	ThemeColorControlText:       SysColorBtnText,
+	ThemeColorHighlight:         SysColorHighlight,
+	ThemeColorHighlightText:     SysColorHighlightText,
+	ThemeColorInactiveHighlight: SysColorBtnFace,
+	ThemeColorGrayText:          SysColorGrayText,
+	ThemeColorAlternateRow:      SysColorBtnFace,
+	ThemeColorAlternateRowText:  SysColorBtnText,
+	ThemeColorGradient1:         SysColorGradientActiveCaption,
+	ThemeColorGradient2:         SysColorActiveCaption,
+}
+
+// Theme provides named colors, brushes and fonts for drawing widgets.
+//
+// Colors a Theme doesn't provide itself fall back to the corresponding system
+// colors, so the empty theme returned by LightTheme looks like walk did before
+// themes existed. Custom drawn parts, like the default cell colors of
+// TableView or the colors of GradientComposite, use the colors of the current
+// theme of the application, see Application.SetTheme.
+//
+// Themes are read from any UI thread without locking, so only modify a
+// Theme before it becomes the current theme, or on the UI thread of an
+// application with a single one. After modifying the current theme, call
+// Application.SetTheme again to apply the changes.
+type Theme struct {
+	name         string
+	dark         bool
+	colors       map[string]Color
+	brushMutex   sync.Mutex
+	brushes      map[string]Brush
+	staleBrushes []Brush // Replaced by SetColor, but maybe still in use
+	fonts        map[string]*Font
+}
+
+// NewTheme returns a new Theme without any colors or fonts of its own.
+//
+// If dark is true, windows get dark frames and dark scroll bars, where the
+// system supports it.
+func NewTheme(name string, dark bool) *Theme {
+	return &Theme{
+		name:    name,
+		dark:    dark,
+		colors:  make(map[string]Color),
+		brushes: make(map[string]Brush),
+		fonts:   make(map[string]*Font),
+	}
+}
+
+var (
+	lightTheme, darkTheme         *Theme
+	lightThemeOnce, darkThemeOnce sync.Once
+)
+
+// LightTheme returns the built-in light theme, which uses the system colors.
+func LightTheme() *Theme {
+	lightThemeOnce.Do(func() {
+		lightTheme = NewTheme("Light", false)
+	})
+
+	return lightTheme
+}
+
+// DarkTheme returns the built-in dark theme.
+func DarkTheme() *Theme {
+	darkThemeOnce.Do(func() {
+		t := NewTheme("Dark", true)
+
+		t.SetColor(ThemeColorWindow, RGB(32, 32, 32))
+		t.SetColor(ThemeColorWindowText, RGB(240, 240, 240))
+		t.SetColor(ThemeColorControl, RGB(43, 43, 43))
+		t.SetColor(ThemeColorControlText, RGB(240, 240, 240))
+		t.SetColor(ThemeColorHighlight, RGB(0, 120, 215))
+		t.SetColor(ThemeColorHighlightText, RGB(255, 255, 255))
+		t.SetColor(ThemeColorInactiveHighlight, RGB(70, 70, 70))
+		t.SetColor(ThemeColorGrayText, RGB(128, 128, 128))
+		t.SetColor(ThemeColorAlternateRow, RGB(40, 40, 40))
+		t.SetColor(ThemeColorAlternateRowText, RGB(240, 240, 240))
+		t.SetColor(ThemeColorGradient1, RGB(60, 60, 64))
+		t.SetColor(ThemeColorGradient2, RGB(32, 32, 32))
+
+		darkTheme = t
+	})
+
+	return darkTheme
+}
+
+// Name returns the name of the Theme.
+func (t *Theme) Name() string {
+	return t.name
+}
+
+// Dark returns if the Theme is a dark one.
+func (t *Theme) Dark() bool {
+	return t.dark
+}
+
+// Color returns the color named name. Colors the Theme doesn't provide
+// itself fall back to the corresponding system colors.
+func (t *Theme) Color(name string) Color {
+	if c, ok := t.colors[name]; ok {
+		return c
+	}
+
+	if sc, ok := themeSystemColors[name]; ok {
+		return Color(win.GetSysColor(int(sc)))
+	}
+
+	return 0
+}
+
+// ProvidesColor returns if the Theme provides the color named name itself,
+// as opposed to falling back to a system color.
+func (t *Theme) ProvidesColor(name string) bool {
+	_, ok := t.colors[name]
+	return ok
+}
+
+// overrideColor sets *color to the color named name, if the Theme provides
+// it itself.
+func (t *Theme) overrideColor(name string, color *Color) {
+	if c, ok := t.colors[name]; ok {
+		*color = c
+	}
+}
+
+// SetColor sets the color named name.
+//
+// A brush of the old color that Brush returned is disposed of by the next
+// call of Application.SetTheme, after the forms stopped using it.
+func (t *Theme) SetColor(name string, color Color) {
+	t.colors[name] = color
+
+	t.brushMutex.Lock()
+	defer t.brushMutex.Unlock()
+
+	if b, ok := t.brushes[name]; ok {
+		t.staleBrushes = append(t.staleBrushes, b)
+		delete(t.brushes, name)
+	}
+}
+
+// Brush returns a solid brush of the color named name. The Theme owns the
+// brush, so don't dispose of it.
+//
+// Brush may be called from any UI thread.
+func (t *Theme) Brush(name string) Brush {
+	t.brushMutex.Lock()
+	defer t.brushMutex.Unlock()
+
+	if b, ok := t.brushes[name]; ok {
+		return b
+	}
+
+	var b Brush
+	if c, ok := t.colors[name]; ok {
+		if scb, err := NewSolidColorBrush(c); err == nil {
+			b = scb
+		}
+	} else if sc, ok := themeSystemColors[name]; ok {
+		if scb, err := NewSystemColorBrush(sc); err == nil {
+			b = scb
+		}
+	}
+
+	if b == nil {
+		return nil
+	}
+
+	t.brushes[name] = b
+
+	return b
+}
+
+// takeStaleBrushes returns the brushes replaced by SetColor and forgets them.
+func (t *Theme) takeStaleBrushes() []Brush {
+	t.brushMutex.Lock()
+	defer t.brushMutex.Unlock()
+
+	brushes := t.staleBrushes
+	t.staleBrushes = nil
+
+	return brushes
+}
+
+// Font returns the font named name, or nil if the Theme doesn't provide one.
+func (t *Theme) Font(name string) *Font {
+	return t.fonts[name]
+}
+
+// SetFont sets the font named name.
+func (t *Theme) SetFont(name string, font *Font) {
+	if font == nil {
+		delete(t.fonts, name)
+	} else {
+		t.fonts[name] = font
+	}
+}
+
+// themeDefaultFont returns the default font of the current theme, or the
+// default font of walk if it has none.
+func themeDefaultFont() *Font {
+	if font := App().Theme().Font(ThemeFontDefault); font != nil {
+		return font
+	}
+
+	return defaultFont
+}
+
+// SystemUsesDarkTheme returns if the user chose the dark app mode in the
+// Windows settings.
+func SystemUsesDarkTheme() bool {
+	light, err := RegistryKeyUint32(
+		CurrentUserKey(),
+		`Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`,
+		"AppsUseLightTheme")
+
+	return err == nil && light == 0
+}
+
+// Theme returns the current theme of the application. By default, this is
+// LightTheme().
+func (app *Application) Theme() *Theme {
+	if theme, ok := app.theme.Load().(*Theme); ok {
+		return theme
+	}
+
+	return LightTheme()
+}
+
+// SetTheme sets the current theme of the application, applies it to all
+// forms and publishes the ThemeChanged event.
+//
+// It must be called on the UI thread. Forms of other threads are updated by
+// means of Synchronize.
+//
+// Brushes replaced by Theme.SetColor, of both the previous and the new theme,
+// are disposed of once all forms have been updated.
+func (app *Application) SetTheme(theme *Theme) {
+	if theme == nil {
+		theme = LightTheme()
+	}
+
+	previous := app.Theme()
+
+	app.theme.Store(theme)
+
+	stale := theme.takeStaleBrushes()
+	if previous != theme {
+		stale = append(stale, previous.takeStaleBrushes()...)
+	}
+
+	// The last form to be updated disposes of the stale brushes.
+	pending := int32(1)
+	done := func() {
+		if atomic.AddInt32(&pending, -1) == 0 {
+			for _, b := range stale {
+				b.Dispose()
+			}
+		}
+	}
+
+	tid := win.GetCurrentThreadId()
+
+	for hwnd, wb := range hwnd2WindowBase {
+		form, ok := wb.window.(Form)
+		if !ok {
+			continue
+		}
+
+		fb := form.AsFormBase()
+
+		if win.GetWindowThreadProcessId(hwnd, nil) == tid {
+			fb.applyTheme(theme)
+		} else {
+			atomic.AddInt32(&pending, 1)
+
+			form.Synchronize(func() {
+				fb.applyTheme(theme)
+				done()
+			})
+		}
+	}
+
+	done()
+
+	app.themeChangedPublisher.Publish()
+}
+
+// FollowSystemTheme returns if the application switches between LightTheme
+// and DarkTheme when the user changes the app mode in the Windows settings.
+func (app *Application) FollowSystemTheme() bool {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+
+	return app.followSystemTheme
+}
+
+// SetFollowSystemTheme sets if the application switches between LightTheme
+// and DarkTheme when the user changes the app mode in the Windows settings.
+//
+// Setting it to true also applies the matching theme right away.
+func (app *Application) SetFollowSystemTheme(follow bool) {
+	app.mutex.Lock()
+	app.followSystemTheme = follow
+	app.mutex.Unlock()
+
+	if follow {
+		app.applySystemTheme()
+	}
+}
+
+// ThemeChanged returns the event that is published after the theme of the
+// application changed, either by means of SetTheme or, if FollowSystemTheme
+// is true, because the user changed the app mode in the Windows settings.
+func (app *Application) ThemeChanged() *Event {
+	return app.themeChangedPublisher.Event()
+}
+
+func (app *Application) applySystemTheme() {
+	theme := LightTheme()
+	if SystemUsesDarkTheme() {
+		theme = DarkTheme()
+	}
+
+	if theme != app.Theme() {
+		app.SetTheme(theme)
+	}
+}
+
+// handleSettingChange is called by forms for WM_SETTINGCHANGE.
+func (app *Application) handleSettingChange(lParam uintptr) {
+	if lParam == 0 || !app.FollowSystemTheme() {
+		return
+	}
+
+	if win.UTF16PtrToString((*uint16)(unsafe.Pointer(lParam))) != "ImmersiveColorSet" {
+		return
+	}
+
+	// Every top level window gets the message, but applySystemTheme only
+	// does something for the first one.
+	app.applySystemTheme()
+}
+
+func (fb *FormBase) applyTheme(theme *Theme) {
+	if fb.hWnd == 0 {
+		return
+	}
+
+	setDarkWindowFrame(fb.hWnd, theme.Dark())
+
+	// Only replace backgrounds set by a theme.
+	if bg := fb.clientComposite.background; bg == nil || bg == fb.themeBackground {
+		if theme.ProvidesColor(ThemeColorControl) {
+			fb.themeBackground = theme.Brush(ThemeColorControl)
+		} else {
+			fb.themeBackground = nil
+		}
+
+		fb.clientComposite.background = fb.themeBackground
+	}
+
+	walkDescendants(fb.window, func(w Window) bool {
+		w.AsWindowBase().applyWindowTheme(theme)
+		return true
+	})
+
+	if fb.font == nil {
+		fb.window.(applyFonter).applyFont(themeDefaultFont())
+	}
+
+	fb.window.(ApplySysColorser).ApplySysColors()
+
+	win.RedrawWindow(fb.hWnd, nil, 0, win.RDW_ERASE|win.RDW_FRAME|win.RDW_INVALIDATE|win.RDW_ALLCHILDREN)
+}
+
+// applyWindowTheme sets the visual style of the window according to theme.
+// Dark themes use the dark variant of the Explorer style, which has dark
+// scroll bars.
+func (wb *WindowBase) applyWindowTheme(theme *Theme) {
+	if _, ok := wb.window.(Form); ok {
+		return
+	}
+
+	if !theme.Dark() && !wb.darkThemed {
+		return
+	}
+
+	wb.darkThemed = theme.Dark()
+
+	setWindowThemeForTheme(wb.hWnd, wb.themeAppName, theme)
+}
+
+func setWindowThemeForTheme(hwnd win.HWND, appName string, theme *Theme) {
+	if theme.Dark() {
+		appName = "DarkMode_Explorer"
+	}
+
+	var pszSubAppName *uint16
+	if appName != "" {
+		pszSubAppName = syscall.StringToUTF16Ptr(appName)
+	}
+
+	win.SetWindowTheme(hwnd, pszSubAppName, nil)
+}
+
+var (
+	libdwmapi                 = windows.NewLazySystemDLL("dwmapi.dll")
+	procDwmSetWindowAttribute = libdwmapi.NewProc("DwmSetWindowAttribute")
+)
+
+const (
+	dwmwaUseImmersiveDarkModeBefore20H1 = 19
+	dwmwaUseImmersiveDarkMode           = 20
+)
+
+// setDarkWindowFrame makes the title bar and frame of a top level window dark
+// or light. It does nothing on versions of Windows that don't support it.
+func setDarkWindowFrame(hwnd win.HWND, dark bool) {
+	if procDwmSetWindowAttribute.Find() != nil {
+		return
+	}
+
+	var value win.BOOL
+	if dark {
+		value = win.TRUE
+	}
+
+	for _, attr := range []uintptr{dwmwaUseImmersiveDarkMode, dwmwaUseImmersiveDarkModeBefore20H1} {
+		hr, _, _ := procDwmSetWindowAttribute.Call(
+			uintptr(hwnd),
+			attr,
+			uintptr(unsafe.Pointer(&value)),
+			unsafe.Sizeof(value))
+		if !win.FAILED(win.HRESULT(hr)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lxn/win"
+)
+
+// Clock is the source of time for Timer and Debouncer.
+//
+// The default clock uses Win32 timers of the UI thread. Tests can replace it
+// with a *ManualClock to control time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc arranges for f to be called on the UI thread once d has
+	// elapsed. Calling the returned function cancels the call, if it didn't
+	// happen yet.
+	AfterFunc(d time.Duration, f func()) (cancel func())
+}
+
+// systemClock uses thread timers, whose ids are unique within the process,
+// so one map serves all UI threads. Its mutex guards the map.
+type systemClock struct {
+	mutex   sync.Mutex
+	id2Func map[uintptr]func()
+}
+
+var (
+	defaultClock            = &systemClock{id2Func: make(map[uintptr]func())}
+	systemClockTimerProcPtr uintptr
+)
+
+func init() {
+	AppendToWalkInit(func() {
+		systemClockTimerProcPtr = syscall.NewCallback(systemClockTimerProc)
+	})
+}
+
+// SystemClock returns the default Clock, which is backed by Win32 timers.
+//
+// Its AfterFunc must be called from a UI thread, which is the one f is called
+// on.
+func SystemClock() Clock {
+	return defaultClock
+}
+
+func (*systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (sc *systemClock) AfterFunc(d time.Duration, f func()) (cancel func()) {
+	ms := uint32(d / time.Millisecond)
+	if d > 0 && ms == 0 {
+		ms = 1
+	}
+
+	id := win.SetTimer(0, 0, ms, systemClockTimerProcPtr)
+	if id == 0 {
+		lastError("SetTimer")
+		return func() {}
+	}
+
+	sc.mutex.Lock()
+	sc.id2Func[id] = f
+	sc.mutex.Unlock()
+
+	return func() {
+		if sc.take(id) != nil {
+			win.KillTimer(0, id)
+		}
+	}
+}
+
+// take removes the function for the timer id from the map and returns it,
+// or nil if it was called or canceled already.
+func (sc *systemClock) take(id uintptr) func() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	f := sc.id2Func[id]
+	delete(sc.id2Func, id)
+
+	return f
+}
+
+func systemClockTimerProc(hwnd win.HWND, msg uint32, idEvent uintptr, dwTime uint32) uintptr {
+	win.KillTimer(0, idEvent)
+
+	if f := defaultClock.take(idEvent); f != nil {
+		f()
+	}
+
+	return 0
+}
+
+// ManualClock is a Clock that only moves when told to. It is meant for
+// testing code that uses Timer or Debouncer.
+type ManualClock struct {
+	now     time.Time
+	pending []*manualClockFunc
+	nextSeq int
+}
+
+type manualClockFunc struct {
+	due time.Time
+	seq int
+	f   func()
+}
+
+// NewManualClock returns a *ManualClock set to now.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (mc *ManualClock) Now() time.Time {
+	return mc.now
+}
+
+func (mc *ManualClock) AfterFunc(d time.Duration, f func()) (cancel func()) {
+	mcf := &manualClockFunc{due: mc.now.Add(d), seq: mc.nextSeq, f: f}
+	mc.nextSeq++
+
+	mc.pending = append(mc.pending, mcf)
+
+	return func() {
+		for i, p := range mc.pending {
+			if p == mcf {
+				mc.pending = append(mc.pending[:i], mc.pending[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Advance moves the clock forward by d and calls the functions that are due
+// in order. Functions scheduled by them are called as well, if they are due.
+func (mc *ManualClock) Advance(d time.Duration) {
+	end := mc.now.Add(d)
+
+	for {
+		sort.SliceStable(mc.pending, func(i, j int) bool {
+			a, b := mc.pending[i], mc.pending[j]
+			if a.due.Equal(b.due) {
+				return a.seq < b.seq
+			}
+			return a.due.Before(b.due)
+		})
+
+		if len(mc.pending) == 0 || mc.pending[0].due.After(end) {
+			break
+		}
+
+		mcf := mc.pending[0]
+		mc.pending = mc.pending[1:]
+
+		if mcf.due.After(mc.now) {
+			mc.now = mcf.due
+		}
+
+		mcf.f()
+	}
+
+	mc.now = end
+}
+
+// Pending returns the number of scheduled functions that were neither called
+// nor canceled yet.
+func (mc *ManualClock) Pending() int {
+	return len(mc.pending)
+}
+
+// Timer publishes its Timeout event on the UI thread after its interval
+// elapsed, once or repeatedly.
+//
+// A *Timer is a Condition that is satisfied while it is running, so it can
+// enable widgets or actions. A Timer must be used from the UI thread.
+type Timer struct {
+	clock                         Clock
+	interval                      time.Duration
+	singleShot                    bool
+	running                       bool
+	due                           time.Time
+	cancel                        func()
+	timeoutPublisher              EventPublisher
+	runningChangedPublisher       EventPublisher
+	runningCondition              Condition
+	runningConditionChangedHandle int
+	action                        *Action
+}
+
+// NewTimer returns a new, stopped *Timer with interval.
+func NewTimer(interval time.Duration) *Timer {
+	return &Timer{clock: defaultClock, interval: interval}
+}
+
+// Clock returns the Clock of the *Timer.
+func (t *Timer) Clock() Clock {
+	return t.clock
+}
+
+// SetClock sets the Clock of the *Timer. Pass nil for SystemClock.
+//
+// A running *Timer is restarted.
+func (t *Timer) SetClock(clock Clock) {
+	if clock == nil {
+		clock = defaultClock
+	}
+
+	t.clock = clock
+
+	t.restartIfRunning()
+}
+
+// Interval returns the time between two Timeout events.
+func (t *Timer) Interval() time.Duration {
+	return t.interval
+}
+
+// SetInterval sets the time between two Timeout events.
+//
+// A running *Timer is restarted.
+func (t *Timer) SetInterval(interval time.Duration) {
+	t.interval = interval
+
+	t.restartIfRunning()
+}
+
+// SingleShot returns if the *Timer stops after the first Timeout.
+func (t *Timer) SingleShot() bool {
+	return t.singleShot
+}
+
+// SetSingleShot sets if the *Timer stops after the first Timeout.
+func (t *Timer) SetSingleShot(singleShot bool) {
+	t.singleShot = singleShot
+}
+
+// Running returns if the *Timer is running.
+func (t *Timer) Running() bool {
+	return t.running
+}
+
+// Start starts the *Timer. A running *Timer is restarted.
+func (t *Timer) Start() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+
+	t.due = t.clock.Now().Add(t.interval)
+	t.cancel = t.clock.AfterFunc(t.interval, t.timeout)
+
+	t.setRunning(true)
+}
+
+// Stop stops the *Timer.
+func (t *Timer) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+
+	t.setRunning(false)
+}
+
+func (t *Timer) restartIfRunning() {
+	if t.running {
+		t.Start()
+	}
+}
+
+func (t *Timer) setRunning(running bool) {
+	if running == t.running {
+		return
+	}
+
+	t.running = running
+
+	if t.action != nil {
+		t.action.SetChecked(running)
+	}
+
+	t.runningChangedPublisher.Publish()
+}
+
+func (t *Timer) timeout() {
+	t.cancel = nil
+
+	if t.singleShot {
+		t.setRunning(false)
+	} else {
+		// Schedule relative to when the timeout was due, so the timer
+		// doesn't drift, but don't try to catch up on missed timeouts.
+		now := t.clock.Now()
+		t.due = t.due.Add(t.interval)
+		if t.due.Before(now) {
+			t.due = now
+		}
+
+		t.cancel = t.clock.AfterFunc(t.due.Sub(now), t.timeout)
+	}
+
+	t.timeoutPublisher.Publish()
+}
+
+// Timeout returns the event that is published on the UI thread when the
+// interval elapsed.
+func (t *Timer) Timeout() *Event {
+	return t.timeoutPublisher.Event()
+}
+
+// Value returns if the *Timer is running.
+func (t *Timer) Value() interface{} {
+	return t.running
+}
+
+// Satisfied returns if the *Timer is running.
+func (t *Timer) Satisfied() bool {
+	return t.running
+}
+
+// Changed returns the event that is published when the *Timer starts or
+// stops.
+func (t *Timer) Changed() *Event {
+	return t.runningChangedPublisher.Event()
+}
+
+// RunningCondition returns the Condition that controls the *Timer, or nil.
+func (t *Timer) RunningCondition() Condition {
+	return t.runningCondition
+}
+
+// SetRunningCondition makes the *Timer run while c is satisfied. Pass nil to
+// control the *Timer by Start and Stop only.
+func (t *Timer) SetRunningCondition(c Condition) {
+	if t.runningCondition != nil {
+		t.runningCondition.Changed().Detach(t.runningConditionChangedHandle)
+	}
+
+	t.runningCondition = c
+
+	if c == nil {
+		return
+	}
+
+	t.runningConditionChangedHandle = c.Changed().Attach(t.applyRunningCondition)
+
+	t.applyRunningCondition()
+}
+
+func (t *Timer) applyRunningCondition() {
+	if satisfied := t.runningCondition.Satisfied(); satisfied && !t.running {
+		t.Start()
+	} else if !satisfied && t.running {
+		t.Stop()
+	}
+}
+
+// Action returns a checkable *Action that starts and stops the *Timer and
+// is checked while the *Timer is running.
+func (t *Timer) Action() *Action {
+	if t.action == nil {
+		t.action = NewAction()
+		t.action.SetCheckable(true)
+		t.action.SetChecked(t.running)
+		t.action.Triggered().Attach(func() {
+			if t.running {
+				t.Stop()
+			} else {
+				t.Start()
+			}
+		})
+	}
+
+	return t.action
+}
+
+// Debouncer calls a function once a burst of triggers has settled for a
+// delay. It must be used from the UI thread.
+type Debouncer struct {
+	clock  Clock
+	delay  time.Duration
+	f      func()
+	cancel func()
+}
+
+// NewDebouncer returns a *Debouncer that calls f on the UI thread, once delay
+// has elapsed since the last call to Trigger.
+func NewDebouncer(delay time.Duration, f func()) *Debouncer {
+	return &Debouncer{clock: defaultClock, delay: delay, f: f}
+}
+
+// SetClock sets the Clock of the *Debouncer. Pass nil for SystemClock.
+func (d *Debouncer) SetClock(clock Clock) {
+	if clock == nil {
+		clock = defaultClock
+	}
+
+	d.clock = clock
+}
+
+// Delay returns the time that must pass without triggers before the function
+// is called.
+func (d *Debouncer) Delay() time.Duration {
+	return d.delay
+}
+
+// SetDelay sets the time that must pass without triggers before the function
+// is called. It applies from the next Trigger on.
+func (d *Debouncer) SetDelay(delay time.Duration) {
+	d.delay = delay
+}
+
+// Trigger (re)starts waiting for the delay.
+func (d *Debouncer) Trigger() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	d.cancel = d.clock.AfterFunc(d.delay, func() {
+		d.cancel = nil
+		d.f()
+	})
+}
+
+// Pending returns if the function will be called.
+func (d *Debouncer) Pending() bool {
+	return d.cancel != nil
+}
+
+// Cancel cancels a pending call.
+func (d *Debouncer) Cancel() {
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+}
+
+// Flush calls the function now, if a call is pending.
+func (d *Debouncer) Flush() {
+	if d.cancel != nil {
+		d.Cancel()
+		d.f()
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testEpoch = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestManualClockAdvance(t *testing.T) {
+	mc := NewManualClock(testEpoch)
+
+	var calls []string
+	var at []time.Duration
+	record := func(name string) func() {
+		return func() {
+			calls = append(calls, name)
+			at = append(at, mc.Now().Sub(testEpoch))
+		}
+	}
+
+	mc.AfterFunc(30*time.Millisecond, record("c"))
+	mc.AfterFunc(10*time.Millisecond, record("a"))
+	mc.AfterFunc(10*time.Millisecond, func() {
+		record("b")()
+		mc.AfterFunc(5*time.Millisecond, record("nested"))
+	})
+	cancel := mc.AfterFunc(20*time.Millisecond, record("canceled"))
+	mc.AfterFunc(50*time.Millisecond, record("late"))
+
+	cancel()
+	cancel()
+
+	mc.Advance(30 * time.Millisecond)
+
+	if want := []string{"a", "b", "nested", "c"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+	if want := []time.Duration{10, 10, 15, 30}; !reflect.DeepEqual(at, scaleDurations(want, time.Millisecond)) {
+		t.Errorf("got times %v, want %v ms", at, want)
+	}
+	if got := mc.Now(); !got.Equal(testEpoch.Add(30 * time.Millisecond)) {
+		t.Errorf("got now %v", got)
+	}
+	if mc.Pending() != 1 {
+		t.Errorf("got %d pending, want 1", mc.Pending())
+	}
+}
+
+func scaleDurations(ds []time.Duration, unit time.Duration) []time.Duration {
+	scaled := make([]time.Duration, len(ds))
+	for i, d := range ds {
+		scaled[i] = d * unit
+	}
+
+	return scaled
+}
+
+func TestTimerRepeating(t *testing.T) {
+	mc := NewManualClock(testEpoch)
+
+	timer := NewTimer(100 * time.Millisecond)
+	timer.SetClock(mc)
+
+	var timeouts int
+	timer.Timeout().Attach(func() { timeouts++ })
+
+	var runningChanges int
+	timer.Changed().Attach(func() { runningChanges++ })
+
+	timer.Start()
+	if !timer.Running() || !timer.Satisfied() {
+		t.Fatal("timer not running after Start")
+	}
+
+	mc.Advance(250 * time.Millisecond)
+	if timeouts != 2 {
+		t.Errorf("got %d timeouts, want 2", timeouts)
+	}
+
+	// Missed timeouts are not caught up on.
+	mc.Advance(time.Second)
+	if timeouts != 3 {
+		t.Errorf("got %d timeouts after a long advance, want 3", timeouts)
+	}
+
+	timer.Stop()
+	mc.Advance(time.Second)
+	if timeouts != 3 || timer.Running() {
+		t.Errorf("timer kept running after Stop")
+	}
+	if mc.Pending() != 0 {
+		t.Errorf("got %d pending after Stop, want 0", mc.Pending())
+	}
+	if runningChanges != 2 {
+		t.Errorf("got %d running changes, want 2", runningChanges)
+	}
+}
+
+func TestTimerSingleShot(t *testing.T) {
+	mc := NewManualClock(testEpoch)
+
+	timer := NewTimer(100 * time.Millisecond)
+	timer.SetClock(mc)
+	timer.SetSingleShot(true)
+
+	var timeouts int
+	timer.Timeout().Attach(func() { timeouts++ })
+
+	timer.Start()
+	mc.Advance(50 * time.Millisecond)
+
+	// SetInterval restarts a running timer.
+	timer.SetInterval(200 * time.Millisecond)
+	mc.Advance(150 * time.Millisecond)
+	if timeouts != 0 {
+		t.Errorf("got %d timeouts before the new interval elapsed, want 0", timeouts)
+	}
+
+	mc.Advance(time.Second)
+	if timeouts != 1 || timer.Running() {
+		t.Errorf("got %d timeouts and running %t, want 1 and false", timeouts, timer.Running())
+	}
+}
+
+func TestTimerRunningCondition(t *testing.T) {
+	mc := NewManualClock(testEpoch)
+
+	timer := NewTimer(100 * time.Millisecond)
+	timer.SetClock(mc)
+
+	cond := NewMutableCondition()
+	timer.SetRunningCondition(cond)
+	if timer.Running() {
+		t.Error("timer running while its condition is not satisfied")
+	}
+
+	cond.SetSatisfied(true)
+	if !timer.Running() {
+		t.Error("timer not running while its condition is satisfied")
+	}
+
+	cond.SetSatisfied(false)
+	if timer.Running() {
+		t.Error("timer running after its condition was unsatisfied")
+	}
+
+	timer.SetRunningCondition(nil)
+	cond.SetSatisfied(true)
+	if timer.Running() {
+		t.Error("timer follows a condition that was removed")
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	mc := NewManualClock(testEpoch)
+
+	var calls int
+	d := NewDebouncer(100*time.Millisecond, func() { calls++ })
+	d.SetClock(mc)
+
+	for i := 0; i < 5; i++ {
+		d.Trigger()
+		mc.Advance(50 * time.Millisecond)
+	}
+	if calls != 0 || !d.Pending() {
+		t.Fatalf("got %d calls and pending %t during a burst, want 0 and true", calls, d.Pending())
+	}
+
+	mc.Advance(50 * time.Millisecond)
+	if calls != 1 || d.Pending() {
+		t.Errorf("got %d calls and pending %t after the burst, want 1 and false", calls, d.Pending())
+	}
+
+	d.Trigger()
+	d.Cancel()
+	mc.Advance(time.Second)
+	if calls != 1 {
+		t.Errorf("got %d calls after Cancel, want 1", calls)
+	}
+
+	d.Trigger()
+	d.Flush()
+	d.Flush()
+	if calls != 2 || d.Pending() || mc.Pending() != 0 {
+		t.Errorf("got %d calls after Flush, want 2 and nothing pending", calls)
+	}
+}
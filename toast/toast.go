@@ -0,0 +1,231 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package toast implements the queueing and positioning of toast
+// notifications.
+//
+// It does not depend on walk, so the logic can be tested on any platform.
+// walk.ToastHost shows the notifications on a form.
+package toast
+
+import (
+	"image"
+	"sort"
+	"time"
+)
+
+// Priority decides which waiting notification is shown next.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// ID identifies a notification in a Queue.
+type ID uint64
+
+type entry struct {
+	id       ID
+	priority Priority
+	duration time.Duration
+	deadline time.Time // Zero while waiting or if the entry doesn't expire.
+}
+
+// Queue decides which notifications are visible and when they expire.
+//
+// At most MaxVisible notifications are visible at a time. Others wait until
+// there is room, those with higher priority first, then in the order they
+// were pushed.
+type Queue struct {
+	maxVisible int
+	visible    []*entry
+	waiting    []*entry
+	nextID     ID
+}
+
+// NewQueue returns a *Queue that shows at most maxVisible notifications at a
+// time. A maxVisible smaller than 1 is treated as 1.
+func NewQueue(maxVisible int) *Queue {
+	q := new(Queue)
+
+	q.SetMaxVisible(maxVisible)
+
+	return q
+}
+
+// MaxVisible returns the maximum number of visible notifications.
+func (q *Queue) MaxVisible() int {
+	return q.maxVisible
+}
+
+// SetMaxVisible sets the maximum number of visible notifications. Visible
+// ones stay visible if there are more than that already.
+func (q *Queue) SetMaxVisible(maxVisible int) {
+	if maxVisible < 1 {
+		maxVisible = 1
+	}
+
+	q.maxVisible = maxVisible
+}
+
+// Push adds a notification and returns its ID. It expires duration after it
+// becomes visible, or never if duration is not positive.
+func (q *Queue) Push(priority Priority, duration time.Duration, now time.Time) ID {
+	q.nextID++
+
+	e := &entry{id: q.nextID, priority: priority, duration: duration}
+
+	// Keep waiting sorted by priority, then by ID.
+	i := sort.Search(len(q.waiting), func(i int) bool {
+		return q.waiting[i].priority < priority
+	})
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = e
+
+	q.promote(now)
+
+	return e.id
+}
+
+// Dismiss removes a visible or waiting notification. It returns false if there
+// is no notification with id.
+func (q *Queue) Dismiss(id ID, now time.Time) bool {
+	if removeEntry(&q.waiting, id) {
+		return true
+	}
+
+	if !removeEntry(&q.visible, id) {
+		return false
+	}
+
+	q.promote(now)
+
+	return true
+}
+
+// Expire removes the visible notifications whose time is up at now and
+// returns their IDs.
+func (q *Queue) Expire(now time.Time) []ID {
+	var expired []ID
+
+	visible := q.visible[:0]
+	for _, e := range q.visible {
+		if !e.deadline.IsZero() && !e.deadline.After(now) {
+			expired = append(expired, e.id)
+		} else {
+			visible = append(visible, e)
+		}
+	}
+	q.visible = visible
+
+	if len(expired) > 0 {
+		q.promote(now)
+	}
+
+	return expired
+}
+
+// NextDeadline returns when the next visible notification expires. ok is false
+// if none will.
+func (q *Queue) NextDeadline() (deadline time.Time, ok bool) {
+	for _, e := range q.visible {
+		if e.deadline.IsZero() {
+			continue
+		}
+
+		if !ok || e.deadline.Before(deadline) {
+			deadline, ok = e.deadline, true
+		}
+	}
+
+	return
+}
+
+// Visible returns the IDs of the visible notifications, oldest first.
+func (q *Queue) Visible() []ID {
+	return ids(q.visible)
+}
+
+// Waiting returns the IDs of the waiting notifications, next first.
+func (q *Queue) Waiting() []ID {
+	return ids(q.waiting)
+}
+
+func (q *Queue) promote(now time.Time) {
+	for len(q.visible) < q.maxVisible && len(q.waiting) > 0 {
+		e := q.waiting[0]
+		q.waiting = q.waiting[1:]
+
+		if e.duration > 0 {
+			e.deadline = now.Add(e.duration)
+		}
+
+		q.visible = append(q.visible, e)
+	}
+}
+
+func removeEntry(entries *[]*entry, id ID) bool {
+	for i, e := range *entries {
+		if e.id == id {
+			*entries = append((*entries)[:i], (*entries)[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func ids(entries []*entry) []ID {
+	ids := make([]ID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.id
+	}
+
+	return ids
+}
+
+// Corner is the corner of an area that notifications stack up from.
+type Corner int
+
+const (
+	BottomRight Corner = iota
+	BottomLeft
+	TopRight
+	TopLeft
+)
+
+// Stack returns the bounds of notifications of the given sizes inside area.
+// The first one is placed in corner, at a distance of margin from the edges,
+// and the others follow away from the corner with spacing between them.
+func Stack(area image.Rectangle, sizes []image.Point, corner Corner, margin, spacing int) []image.Rectangle {
+	bounds := make([]image.Rectangle, len(sizes))
+
+	top := corner == TopLeft || corner == TopRight
+	left := corner == TopLeft || corner == BottomLeft
+
+	y := area.Max.Y - margin
+	if top {
+		y = area.Min.Y + margin
+	}
+
+	for i, size := range sizes {
+		x := area.Max.X - margin - size.X
+		if left {
+			x = area.Min.X + margin
+		}
+
+		if top {
+			bounds[i] = image.Rect(x, y, x+size.X, y+size.Y)
+			y += size.Y + spacing
+		} else {
+			bounds[i] = image.Rect(x, y-size.Y, x+size.X, y)
+			y -= size.Y + spacing
+		}
+	}
+
+	return bounds
+}
@@ -0,0 +1,170 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package toast
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func checkIDs(t *testing.T, what string, got []ID, want ...ID) {
+	t.Helper()
+
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	q := NewQueue(1)
+
+	first := q.Push(PriorityLow, 0, epoch)
+	low := q.Push(PriorityLow, 0, epoch)
+	normal1 := q.Push(PriorityNormal, 0, epoch)
+	high := q.Push(PriorityHigh, 0, epoch)
+	normal2 := q.Push(PriorityNormal, 0, epoch)
+
+	// A visible notification is never displaced by a higher priority one.
+	checkIDs(t, "visible", q.Visible(), first)
+	checkIDs(t, "waiting", q.Waiting(), high, normal1, normal2, low)
+
+	for _, want := range []ID{high, normal1, normal2, low} {
+		q.Dismiss(q.Visible()[0], epoch)
+		checkIDs(t, "visible after dismiss", q.Visible(), want)
+	}
+}
+
+func TestQueueMaxVisible(t *testing.T) {
+	q := NewQueue(0)
+	if q.MaxVisible() != 1 {
+		t.Errorf("got max visible %d, want 1", q.MaxVisible())
+	}
+
+	a := q.Push(PriorityNormal, 0, epoch)
+	b := q.Push(PriorityNormal, 0, epoch)
+
+	q.SetMaxVisible(3)
+	c := q.Push(PriorityNormal, 0, epoch)
+
+	// Raising the maximum takes effect on the next change.
+	checkIDs(t, "visible", q.Visible(), a, b, c)
+
+	// Lowering it keeps visible notifications visible.
+	q.SetMaxVisible(1)
+	d := q.Push(PriorityHigh, 0, epoch)
+	checkIDs(t, "visible after lowering the maximum", q.Visible(), a, b, c)
+	checkIDs(t, "waiting after lowering the maximum", q.Waiting(), d)
+
+	q.Dismiss(a, epoch)
+	checkIDs(t, "visible after dismiss", q.Visible(), b, c)
+}
+
+func TestQueueExpire(t *testing.T) {
+	q := NewQueue(2)
+
+	a := q.Push(PriorityNormal, 3*time.Second, epoch)
+	b := q.Push(PriorityNormal, 0, epoch)
+	c := q.Push(PriorityNormal, 5*time.Second, epoch)
+
+	deadline, ok := q.NextDeadline()
+	if !ok || !deadline.Equal(epoch.Add(3*time.Second)) {
+		t.Errorf("got next deadline %v, %t, want in 3s", deadline, ok)
+	}
+
+	checkIDs(t, "expired early", q.Expire(epoch.Add(2*time.Second)))
+
+	// c becomes visible at 3s, so it expires at 8s.
+	checkIDs(t, "expired", q.Expire(epoch.Add(3*time.Second)), a)
+	checkIDs(t, "visible", q.Visible(), b, c)
+
+	deadline, ok = q.NextDeadline()
+	if !ok || !deadline.Equal(epoch.Add(8*time.Second)) {
+		t.Errorf("got next deadline %v, %t, want in 8s", deadline, ok)
+	}
+
+	checkIDs(t, "expired", q.Expire(epoch.Add(time.Hour)), c)
+	checkIDs(t, "visible", q.Visible(), b)
+
+	if _, ok := q.NextDeadline(); ok {
+		t.Error("got a deadline without expiring notifications")
+	}
+}
+
+func TestQueueDismiss(t *testing.T) {
+	q := NewQueue(1)
+
+	a := q.Push(PriorityNormal, 5*time.Second, epoch)
+	b := q.Push(PriorityNormal, 5*time.Second, epoch)
+	c := q.Push(PriorityNormal, 5*time.Second, epoch)
+
+	// Dismissing a waiting notification doesn't change the visible ones.
+	if !q.Dismiss(b, epoch.Add(time.Second)) {
+		t.Error("dismissing a waiting notification failed")
+	}
+	checkIDs(t, "visible", q.Visible(), a)
+	checkIDs(t, "waiting", q.Waiting(), c)
+
+	// Dismissing a visible one promotes the next, which expires relative to
+	// when it became visible.
+	if !q.Dismiss(a, epoch.Add(2*time.Second)) {
+		t.Error("dismissing a visible notification failed")
+	}
+	checkIDs(t, "visible", q.Visible(), c)
+	checkIDs(t, "waiting", q.Waiting())
+
+	if deadline, _ := q.NextDeadline(); !deadline.Equal(epoch.Add(7 * time.Second)) {
+		t.Errorf("got next deadline %v, want in 7s", deadline)
+	}
+
+	if q.Dismiss(a, epoch) || q.Dismiss(42, epoch) {
+		t.Error("dismissing an unknown notification succeeded")
+	}
+}
+
+func TestStack(t *testing.T) {
+	area := image.Rect(0, 0, 400, 300)
+	sizes := []image.Point{{100, 40}, {120, 50}}
+
+	tests := []struct {
+		corner Corner
+		want   []image.Rectangle
+	}{
+		{BottomRight, []image.Rectangle{
+			image.Rect(290, 250, 390, 290),
+			image.Rect(270, 196, 390, 246),
+		}},
+		{BottomLeft, []image.Rectangle{
+			image.Rect(10, 250, 110, 290),
+			image.Rect(10, 196, 130, 246),
+		}},
+		{TopRight, []image.Rectangle{
+			image.Rect(290, 10, 390, 50),
+			image.Rect(270, 54, 390, 104),
+		}},
+		{TopLeft, []image.Rectangle{
+			image.Rect(10, 10, 110, 50),
+			image.Rect(10, 54, 130, 104),
+		}},
+	}
+
+	for _, test := range tests {
+		got := Stack(area, sizes, test.corner, 10, 4)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("corner %d: got %v, want %v", test.corner, got, test.want)
+		}
+	}
+
+	offset := Stack(area.Add(image.Pt(50, 20)), sizes[:1], TopLeft, 0, 0)
+	if want := image.Rect(50, 20, 150, 60); offset[0] != want {
+		t.Errorf("offset area: got %v, want %v", offset[0], want)
+	}
+}
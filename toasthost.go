@@ -0,0 +1,605 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"image"
+	"time"
+
+	"github.com/lxn/walk/toast"
+	"github.com/lxn/win"
+)
+
+const toastWindowClass = `\o/ Walk_Toast_Class \o/`
+
+func init() {
+	AppendToWalkInit(func() {
+		MustRegisterWindowClass(toastWindowClass)
+	})
+}
+
+const (
+	toastWidth   = 320 // in 1/96" units
+	toastMargin  = 12  // in 1/96" units
+	toastSpacing = 8   // in 1/96" units
+
+	defaultToastDuration = 5 * time.Second
+)
+
+// Toast is a notification that a ToastHost shows on top of a form.
+type Toast struct {
+	Title   string
+	Message string
+
+	// Icon is shown next to the text, if not nil.
+	Icon Image
+
+	// Priority decides which toast is shown next if more are waiting.
+	Priority toast.Priority
+
+	// Duration is how long the toast is shown. 0 means the default duration
+	// of the ToastHost, a negative value means until it is dismissed.
+	Duration time.Duration
+
+	// Actions are shown as buttons. Triggering one dismisses the toast.
+	Actions []*Action
+}
+
+// ToastRecord is an entry of the history of a ToastHost.
+type ToastRecord struct {
+	Toast *Toast
+	Time  time.Time
+}
+
+// ToastHost shows Toasts stacked in a corner of a Form, one after another as
+// room becomes available, and keeps a history of them.
+//
+// A *ToastHost must be used from the UI thread of its form.
+type ToastHost struct {
+	form                    Form
+	clock                   Clock
+	queue                   *toast.Queue
+	id2Window               map[toast.ID]*toastWindow
+	id2Toast                map[toast.ID]*Toast
+	toast2ID                map[*Toast]toast.ID
+	corner                  toast.Corner
+	defaultDuration         time.Duration
+	cancelExpiry            func()
+	history                 []*ToastRecord
+	historyChangedPublisher EventPublisher
+	formBoundsChangedHandle int
+}
+
+// NewToastHost returns a *ToastHost that shows toasts on form.
+func NewToastHost(form Form) *ToastHost {
+	th := &ToastHost{
+		form:            form,
+		clock:           defaultClock,
+		queue:           toast.NewQueue(3),
+		id2Window:       make(map[toast.ID]*toastWindow),
+		id2Toast:        make(map[toast.ID]*Toast),
+		toast2ID:        make(map[*Toast]toast.ID),
+		defaultDuration: defaultToastDuration,
+	}
+
+	th.formBoundsChangedHandle = form.AsWindowBase().BoundsChanged().Attach(th.arrange)
+
+	form.Disposing().Attach(th.Dispose)
+
+	return th
+}
+
+// Dispose removes all toasts.
+func (th *ToastHost) Dispose() {
+	if th.form == nil {
+		return
+	}
+
+	if th.cancelExpiry != nil {
+		th.cancelExpiry()
+		th.cancelExpiry = nil
+	}
+
+	for id, tw := range th.id2Window {
+		tw.Dispose()
+		delete(th.id2Window, id)
+	}
+
+	th.form.AsWindowBase().BoundsChanged().Detach(th.formBoundsChangedHandle)
+	th.form = nil
+}
+
+// Form returns the Form the toasts are shown on.
+func (th *ToastHost) Form() Form {
+	return th.form
+}
+
+// SetClock sets the Clock that decides when toasts expire. Pass nil for
+// SystemClock.
+func (th *ToastHost) SetClock(clock Clock) {
+	if clock == nil {
+		clock = defaultClock
+	}
+
+	th.clock = clock
+
+	th.scheduleExpiry()
+}
+
+// Corner returns the corner of the form the toasts stack up from.
+//
+// By default this is toast.BottomRight.
+func (th *ToastHost) Corner() toast.Corner {
+	return th.corner
+}
+
+// SetCorner sets the corner of the form the toasts stack up from.
+func (th *ToastHost) SetCorner(corner toast.Corner) {
+	th.corner = corner
+
+	th.arrange()
+}
+
+// MaxVisible returns the maximum number of toasts shown at a time.
+//
+// By default this is 3.
+func (th *ToastHost) MaxVisible() int {
+	return th.queue.MaxVisible()
+}
+
+// SetMaxVisible sets the maximum number of toasts shown at a time.
+func (th *ToastHost) SetMaxVisible(maxVisible int) {
+	th.queue.SetMaxVisible(maxVisible)
+}
+
+// DefaultDuration returns how long toasts with a Duration of 0 are shown.
+//
+// By default this is 5 seconds.
+func (th *ToastHost) DefaultDuration() time.Duration {
+	return th.defaultDuration
+}
+
+// SetDefaultDuration sets how long toasts with a Duration of 0 are shown.
+func (th *ToastHost) SetDefaultDuration(duration time.Duration) {
+	th.defaultDuration = duration
+}
+
+// Show queues t to be shown and adds it to the history.
+func (th *ToastHost) Show(t *Toast) error {
+	if th.form == nil {
+		return newError("ToastHost disposed")
+	}
+
+	if _, ok := th.toast2ID[t]; ok {
+		return newError("toast already shown")
+	}
+
+	duration := t.Duration
+	if duration == 0 {
+		duration = th.defaultDuration
+	}
+
+	now := th.clock.Now()
+
+	id := th.queue.Push(t.Priority, duration, now)
+	th.id2Toast[id] = t
+	th.toast2ID[t] = id
+
+	th.history = append(th.history, &ToastRecord{Toast: t, Time: now})
+	th.historyChangedPublisher.Publish()
+
+	return th.update()
+}
+
+// Dismiss removes t, whether it is visible or still waiting.
+func (th *ToastHost) Dismiss(t *Toast) {
+	id, ok := th.toast2ID[t]
+	if !ok {
+		return
+	}
+
+	th.queue.Dismiss(id, th.clock.Now())
+
+	th.update()
+}
+
+// Visible returns the toasts that are currently shown, oldest first.
+func (th *ToastHost) Visible() []*Toast {
+	return th.toasts(th.queue.Visible())
+}
+
+// Waiting returns the toasts that wait to be shown, next first.
+func (th *ToastHost) Waiting() []*Toast {
+	return th.toasts(th.queue.Waiting())
+}
+
+func (th *ToastHost) toasts(ids []toast.ID) []*Toast {
+	toasts := make([]*Toast, len(ids))
+	for i, id := range ids {
+		toasts[i] = th.id2Toast[id]
+	}
+
+	return toasts
+}
+
+// History returns the records of all toasts shown so far, oldest first.
+func (th *ToastHost) History() []*ToastRecord {
+	return th.history
+}
+
+// ClearHistory removes all records from the history.
+func (th *ToastHost) ClearHistory() {
+	th.history = nil
+	th.historyChangedPublisher.Publish()
+}
+
+// HistoryChanged returns the event that is published when the history
+// changes.
+func (th *ToastHost) HistoryChanged() *Event {
+	return th.historyChangedPublisher.Event()
+}
+
+// update makes the toast windows match the queue.
+func (th *ToastHost) update() error {
+	if th.form == nil {
+		return nil
+	}
+
+	visible := make(map[toast.ID]bool)
+	for _, id := range th.queue.Visible() {
+		visible[id] = true
+	}
+
+	for id, tw := range th.id2Window {
+		if !visible[id] {
+			tw.Dispose()
+			delete(th.id2Window, id)
+		}
+	}
+
+	for id := range th.id2Toast {
+		if !visible[id] && !th.isWaiting(id) {
+			delete(th.toast2ID, th.id2Toast[id])
+			delete(th.id2Toast, id)
+		}
+	}
+
+	for _, id := range th.queue.Visible() {
+		if _, ok := th.id2Window[id]; ok {
+			continue
+		}
+
+		tw, err := newToastWindow(th, th.id2Toast[id])
+		if err != nil {
+			// Drop the toast, so others can be shown instead.
+			th.queue.Dismiss(id, th.clock.Now())
+			th.update()
+
+			return err
+		}
+
+		th.id2Window[id] = tw
+	}
+
+	th.arrange()
+
+	th.scheduleExpiry()
+
+	return nil
+}
+
+func (th *ToastHost) isWaiting(id toast.ID) bool {
+	for _, w := range th.queue.Waiting() {
+		if w == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// arrange positions the visible toasts in the client area of the form.
+func (th *ToastHost) arrange() {
+	if th.form == nil || th.form.Handle() == 0 {
+		return
+	}
+
+	ids := th.queue.Visible()
+	if len(ids) == 0 {
+		return
+	}
+
+	var origin win.POINT
+	win.ClientToScreen(th.form.Handle(), &origin)
+
+	cb := th.form.ClientBoundsPixels()
+	area := image.Rect(int(origin.X), int(origin.Y), int(origin.X)+cb.Width, int(origin.Y)+cb.Height)
+
+	dpi := th.form.DPI()
+
+	sizes := make([]image.Point, len(ids))
+	for i, id := range ids {
+		s := th.id2Window[id].SizePixels()
+		sizes[i] = image.Pt(s.Width, s.Height)
+	}
+
+	bounds := toast.Stack(area, sizes, th.corner, IntFrom96DPI(toastMargin, dpi), IntFrom96DPI(toastSpacing, dpi))
+
+	for i, id := range ids {
+		b := bounds[i]
+		th.id2Window[id].showAt(Rectangle{b.Min.X, b.Min.Y, b.Dx(), b.Dy()})
+	}
+}
+
+func (th *ToastHost) scheduleExpiry() {
+	if th.cancelExpiry != nil {
+		th.cancelExpiry()
+		th.cancelExpiry = nil
+	}
+
+	deadline, ok := th.queue.NextDeadline()
+	if !ok {
+		return
+	}
+
+	th.cancelExpiry = th.clock.AfterFunc(deadline.Sub(th.clock.Now()), func() {
+		th.cancelExpiry = nil
+
+		if len(th.queue.Expire(th.clock.Now())) > 0 {
+			th.update()
+		} else {
+			th.scheduleExpiry()
+		}
+	})
+}
+
+// toastWindow is the popup window of a visible Toast.
+type toastWindow struct {
+	FormBase
+	host  *ToastHost
+	toast *Toast
+}
+
+func newToastWindow(host *ToastHost, t *Toast) (*toastWindow, error) {
+	tw := &toastWindow{
+		FormBase: FormBase{
+			owner: host.form,
+		},
+		host:  host,
+		toast: t,
+	}
+
+	if err := InitWindow(
+		tw,
+		host.form,
+		toastWindowClass,
+		win.WS_POPUP|win.WS_BORDER,
+		win.WS_EX_TOOLWINDOW|win.WS_EX_NOACTIVATE); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			tw.Dispose()
+		}
+	}()
+
+	if err := tw.createContent(); err != nil {
+		return nil, err
+	}
+
+	width := IntFrom96DPI(toastWidth, tw.DPI())
+	min := CreateLayoutItemsForContainer(tw.clientComposite).MinSizeForSize(Size{width, 0})
+	cs := Size{width, min.Height}
+
+	tw.proposedSize = tw.sizeFromClientSizePixels(cs)
+	if err := tw.SetSizePixels(tw.proposedSize); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return tw, nil
+}
+
+func (tw *toastWindow) createContent() error {
+	t := tw.toast
+
+	hbl := NewHBoxLayout()
+	if err := tw.SetLayout(hbl); err != nil {
+		return err
+	}
+
+	if t.Icon != nil {
+		iv, err := NewImageView(tw)
+		if err != nil {
+			return err
+		}
+		if err := iv.SetImage(t.Icon); err != nil {
+			return err
+		}
+		iv.SetAlignment(AlignHNearVNear)
+	}
+
+	body, err := NewComposite(tw)
+	if err != nil {
+		return err
+	}
+	vbl := NewVBoxLayout()
+	vbl.SetMargins(Margins{})
+	if err := body.SetLayout(vbl); err != nil {
+		return err
+	}
+
+	if t.Title != "" {
+		title, err := NewTextLabel(body)
+		if err != nil {
+			return err
+		}
+		if err := title.SetText(t.Title); err != nil {
+			return err
+		}
+		base := tw.Font()
+		if font, err := NewFont(base.Family(), base.PointSize(), base.Style()|FontBold); err == nil {
+			title.SetFont(font)
+		}
+	}
+
+	if t.Message != "" {
+		message, err := NewTextLabel(body)
+		if err != nil {
+			return err
+		}
+		if err := message.SetText(t.Message); err != nil {
+			return err
+		}
+	}
+
+	buttons, err := NewComposite(body)
+	if err != nil {
+		return err
+	}
+	bbl := NewHBoxLayout()
+	bbl.SetMargins(Margins{})
+	if err := buttons.SetLayout(bbl); err != nil {
+		return err
+	}
+
+	if _, err := NewHSpacer(buttons); err != nil {
+		return err
+	}
+
+	for _, action := range t.Actions {
+		action := action
+
+		pb, err := NewPushButton(buttons)
+		if err != nil {
+			return err
+		}
+		if err := pb.SetText(action.Text()); err != nil {
+			return err
+		}
+		pb.SetEnabled(action.Enabled())
+		pb.Clicked().Attach(func() {
+			action.raiseTriggered()
+
+			tw.dismissLater()
+		})
+	}
+
+	closePB, err := NewPushButton(buttons)
+	if err != nil {
+		return err
+	}
+	if err := closePB.SetText(tr("Dismiss", "walk")); err != nil {
+		return err
+	}
+	closePB.Clicked().Attach(tw.dismissLater)
+
+	return nil
+}
+
+// dismissLater dismisses the toast after the current message has been
+// handled, because that may come from a button of the toast window.
+func (tw *toastWindow) dismissLater() {
+	tw.Synchronize(func() {
+		tw.host.Dismiss(tw.toast)
+	})
+}
+
+func (tw *toastWindow) showAt(bounds Rectangle) {
+	win.SetWindowPos(
+		tw.hWnd,
+		0,
+		int32(bounds.X),
+		int32(bounds.Y),
+		int32(bounds.Width),
+		int32(bounds.Height),
+		win.SWP_NOACTIVATE|win.SWP_NOZORDER|win.SWP_NOOWNERZORDER)
+
+	if !tw.Visible() {
+		tw.SetVisible(true)
+		tw.startLayout()
+	}
+}
+
+// NewToastHistoryView returns a *TableView in parent that lists the history
+// of host, newest first.
+func NewToastHistoryView(parent Container, host *ToastHost) (*TableView, error) {
+	tv, err := NewTableView(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			tv.Dispose()
+		}
+	}()
+
+	for _, c := range []struct {
+		title string
+		width int
+	}{
+		{"Time", 80},
+		{"Title", 150},
+		{"Message", 250},
+	} {
+		col := NewTableViewColumn()
+		if err := col.SetTitle(c.title); err != nil {
+			return nil, err
+		}
+		if err := col.SetWidth(c.width); err != nil {
+			return nil, err
+		}
+		if err := tv.Columns().Add(col); err != nil {
+			return nil, err
+		}
+	}
+
+	model := &toastHistoryModel{host: host}
+	if err := tv.SetModel(model); err != nil {
+		return nil, err
+	}
+
+	handle := host.HistoryChanged().Attach(model.PublishRowsReset)
+	tv.Disposing().Attach(func() {
+		host.HistoryChanged().Detach(handle)
+	})
+
+	succeeded = true
+
+	return tv, nil
+}
+
+type toastHistoryModel struct {
+	TableModelBase
+	host *ToastHost
+}
+
+func (m *toastHistoryModel) RowCount() int {
+	return len(m.host.history)
+}
+
+func (m *toastHistoryModel) Value(row, col int) interface{} {
+	record := m.host.history[len(m.host.history)-1-row]
+
+	switch col {
+	case 0:
+		return record.Time.Format("15:04:05")
+
+	case 1:
+		return record.Toast.Title
+
+	case 2:
+		return record.Toast.Message
+	}
+
+	return nil
+}
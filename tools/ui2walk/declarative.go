@@ -0,0 +1,999 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// declarativeContext holds state that is shared while generating the
+// declaration of one top level widget.
+type declarativeContext struct {
+	ui            *UI
+	defaultButton *Widget
+	cancelButton  *Widget
+	hasBindings   bool
+}
+
+func declarativeTypeName(widget *Widget, parent *Widget) (typ string, custom bool) {
+	switch widget.Class {
+	case "QCheckBox":
+		typ = "CheckBox"
+
+	case "QComboBox":
+		typ = "ComboBox"
+
+	case "QDateEdit", "QDateTimeEdit":
+		typ = "DateEdit"
+
+	case "QDoubleSpinBox", "QSpinBox":
+		typ = "NumberEdit"
+
+	case "QFrame", "QStackedWidget":
+		typ = "Composite"
+
+	case "QGroupBox":
+		typ = "GroupBox"
+
+	case "QLabel":
+		if isImageLabel(widget) {
+			typ = "ImageView"
+		} else {
+			typ = "Label"
+		}
+
+	case "QLineEdit":
+		typ = "LineEdit"
+
+	case "QListWidget":
+		typ = "ListBox"
+
+	case "QPlainTextEdit", "QTextEdit":
+		typ = "TextEdit"
+
+	case "QProgressBar":
+		typ = "ProgressBar"
+
+	case "QPushButton":
+		typ = "PushButton"
+
+	case "QRadioButton":
+		typ = "RadioButton"
+
+	case "QScrollArea":
+		typ = "ScrollView"
+
+	case "QSlider":
+		typ = "Slider"
+
+	case "QSplitter":
+		if isVertical(widget) {
+			typ = "VSplitter"
+		} else {
+			typ = "HSplitter"
+		}
+
+	case "QTabWidget":
+		typ = "TabWidget"
+
+	case "QTableView", "QTableWidget":
+		typ = "TableView"
+
+	case "QToolButton":
+		typ = "ToolButton"
+
+	case "QTreeView", "QTreeWidget":
+		typ = "TreeView"
+
+	case "QWebView":
+		typ = "WebView"
+
+	case "QWidget":
+		if parent != nil && parent.Class == "QTabWidget" {
+			typ = "TabPage"
+		} else {
+			typ = "Composite"
+		}
+
+	default:
+		// FIXME: We assume this is a custom widget in the same package, that
+		// implements declarative.Widget and has an AssignTo field.
+		typ = widget.Class
+		custom = true
+	}
+
+	return
+}
+
+// bindingPropertyName returns the name of the property, that a "bind"
+// dynamic property of a widget of declarative type typ binds to.
+func bindingPropertyName(typ string) string {
+	switch typ {
+	case "CheckBox":
+		return "Checked"
+
+	case "ComboBox", "NumberEdit", "ProgressBar", "Slider":
+		return "Value"
+
+	case "DateEdit":
+		return "Date"
+
+	case "Label", "LineEdit", "TextEdit":
+		return "Text"
+	}
+
+	return ""
+}
+
+func findProperty(props []*Property, name string) *Property {
+	for _, prop := range props {
+		if prop.Name == name {
+			return prop
+		}
+	}
+
+	return nil
+}
+
+func writeDeclarativeFont(buf *bytes.Buffer, f *Font) {
+	buf.WriteString("Font: declarative.Font{")
+	if f.Family != "" {
+		buf.WriteString(fmt.Sprintf("Family: %q, ", f.Family))
+	}
+	if f.PointSize != 0 {
+		buf.WriteString(fmt.Sprintf("PointSize: %d, ", f.PointSize))
+	}
+	if f.Bold {
+		buf.WriteString("Bold: true, ")
+	}
+	if f.Italic {
+		buf.WriteString("Italic: true, ")
+	}
+	if f.Underline {
+		buf.WriteString("Underline: true, ")
+	}
+	if f.StrikeOut {
+		buf.WriteString("StrikeOut: true, ")
+	}
+	buf.WriteString("},\n")
+}
+
+func writeDeclarativeProperties(buf *bytes.Buffer, ctx *declarativeContext, widget *Widget, typ string, topLevel bool) error {
+	var minSize, maxSize *Size
+	var accessibility []string
+
+	switch typ {
+	case "NumberEdit":
+		if min, max, ok := valueRange(widget); ok {
+			buf.WriteString(fmt.Sprintf("MinValue: %s,\nMaxValue: %s,\n", floatLiteral(min), floatLiteral(max)))
+		}
+
+	case "ProgressBar", "Slider":
+		if min, max, ok := valueRange(widget); ok {
+			buf.WriteString(fmt.Sprintf("MinValue: %d,\nMaxValue: %d,\n", int(min), int(max)))
+		}
+	}
+
+	if typ == "Slider" {
+		if isVertical(widget) {
+			buf.WriteString("Orientation: declarative.Vertical,\n")
+		}
+
+		// Unlike the declarative default, Qt sliders track by default.
+		if prop := findProperty(widget.Property, "tracking"); prop == nil || prop.Bool {
+			buf.WriteString("Tracking: true,\n")
+		}
+	}
+
+	if model := listItemsModel(widget); model != "" {
+		buf.WriteString(fmt.Sprintf("Model: %s,\n", model))
+	}
+
+	for _, prop := range widget.Property {
+		switch prop.Name {
+		case "accessibleDescription":
+			accessibility = append(accessibility, fmt.Sprintf("Description: %s", trString(&prop.String)))
+
+		case "accessibleName":
+			accessibility = append(accessibility, fmt.Sprintf("Name: %s", trString(&prop.String)))
+
+		case "bind":
+			name := bindingPropertyName(typ)
+			if name == "" {
+				warnf(prop.location, "Ignoring unsupported binding for %s", typ)
+				continue
+			}
+
+			buf.WriteString(fmt.Sprintf("%s: declarative.Bind(%q),\n", name, prop.String.Text))
+			ctx.hasBindings = true
+
+		case "buddy", "maximum", "minimum", "orientation", "sizePolicy", "tracking", "widgetResizable":
+			// Handled by prepareUI, the layout, declarativeTypeName or above.
+
+		case "currentIndex":
+			if widget.Class != "QStackedWidget" {
+				warnf(prop.location, "Ignoring unsupported property: '%s'", prop.Name)
+			}
+
+		case "decimals":
+			buf.WriteString(fmt.Sprintf("Decimals: %d,\n", int(prop.Number)))
+
+		case "displayFormat":
+			buf.WriteString(fmt.Sprintf("Format: %q,\n", dateFormatFromQt(prop.Text)))
+
+		case "echoMode":
+			switch prop.Enum {
+			case "QLineEdit::Normal":
+				// nop
+
+			case "QLineEdit::Password":
+				buf.WriteString("PasswordMode: true,\n")
+
+			default:
+				warnf(prop.location, "Ignoring unsupported echoMode: '%s'", prop.Enum)
+			}
+
+		case "enabled":
+			if !prop.Bool {
+				buf.WriteString("Enabled: false,\n")
+			}
+
+		case "font":
+			writeDeclarativeFont(buf, prop.Font)
+
+		case "geometry":
+			if topLevel {
+				buf.WriteString(fmt.Sprintf("Size: declarative.Size{%d, %d},\n", prop.Rect.Width, prop.Rect.Height))
+			}
+
+		case "icon", "pixmap":
+			if prop.image != "" {
+				buf.WriteString(fmt.Sprintf("Image: %q,\n", prop.image))
+			}
+
+		case "maximumSize":
+			maxSize = &prop.Size
+
+		case "minimumSize":
+			minSize = &prop.Size
+
+		case "maxLength":
+			buf.WriteString(fmt.Sprintf("MaxLength: %d,\n", int(prop.Number)))
+
+		case "pageStep":
+			buf.WriteString(fmt.Sprintf("PageSize: %d,\n", int(prop.Number)))
+
+		case "readOnly":
+			if prop.Bool {
+				buf.WriteString("ReadOnly: true,\n")
+			}
+
+		case "singleStep":
+			if typ == "Slider" {
+				buf.WriteString(fmt.Sprintf("LineSize: %d,\n", int(prop.Number)))
+			} else {
+				buf.WriteString(fmt.Sprintf("Increment: %s,\n", floatLiteral(numberValue(prop))))
+			}
+
+		case "text":
+			buf.WriteString(fmt.Sprintf("Text: %s,\n", trString(&prop.String)))
+
+		case "title", "windowTitle":
+			if typ == "Composite" || typ == "TabPage" {
+				continue
+			}
+
+			buf.WriteString(fmt.Sprintf("Title: %s,\n", trString(&prop.String)))
+
+		case "toolTip":
+			buf.WriteString(fmt.Sprintf("ToolTipText: %s,\n", trString(&prop.String)))
+
+		case "value":
+			switch typ {
+			case "NumberEdit":
+				buf.WriteString(fmt.Sprintf("Value: %s,\n", floatLiteral(numberValue(prop))))
+
+			case "ProgressBar", "Slider":
+				buf.WriteString(fmt.Sprintf("Value: %d,\n", int(numberValue(prop))))
+
+			default:
+				warnf(prop.location, "Ignoring unsupported property: '%s'", prop.Name)
+			}
+
+		case "windowIcon":
+			if prop.image == "" {
+				continue
+			}
+
+			if !topLevel || typ == "Composite" {
+				warnf(prop.location, "Ignoring windowIcon, it is only supported for main windows and dialogs")
+				continue
+			}
+
+			buf.WriteString(fmt.Sprintf("Icon: %q,\n", prop.image))
+
+		default:
+			warnf(prop.location, "Ignoring unsupported property: '%s'", prop.Name)
+		}
+	}
+
+	if len(accessibility) > 0 {
+		buf.WriteString(fmt.Sprintf("Accessibility: declarative.Accessibility{%s},\n", strings.Join(accessibility, ", ")))
+	}
+
+	if minSize != nil {
+		buf.WriteString(fmt.Sprintf("MinSize: declarative.Size{%d, %d},\n", minSize.Width, minSize.Height))
+	}
+	if maxSize != nil && maxSize.Width < 16777215 && maxSize.Height < 16777215 {
+		// Qt uses 16777215 (QWIDGETSIZE_MAX) for "unlimited".
+		buf.WriteString(fmt.Sprintf("MaxSize: declarative.Size{%d, %d},\n", maxSize.Width, maxSize.Height))
+	}
+
+	return nil
+}
+
+func writeDeclarativeLayout(buf *bytes.Buffer, ctx *declarativeContext, layout *Layout, parent *Widget) error {
+	var typ string
+	switch layout.Class {
+	case "QFormLayout", "QGridLayout":
+		// A QFormLayout is just a grid with two columns.
+		typ = "Grid"
+
+	case "QHBoxLayout":
+		typ = "HBox"
+
+	case "QVBoxLayout":
+		typ = "VBox"
+
+	default:
+		return errors.New(fmt.Sprintf("unsupported layout type: '%s'", layout.Class))
+	}
+
+	spacing := 6
+	margL, margT, margR, margB := 9, 9, 9, 9
+
+	for _, prop := range layout.Property {
+		switch prop.Name {
+		case "spacing":
+			spacing = int(prop.Number)
+
+		case "leftMargin":
+			margL = int(prop.Number)
+
+		case "topMargin":
+			margT = int(prop.Number)
+
+		case "rightMargin":
+			margR = int(prop.Number)
+
+		case "bottomMargin":
+			margB = int(prop.Number)
+
+		case "margin":
+			m := int(prop.Number)
+			margL, margT, margR, margB = m, m, m, m
+
+		default:
+			warnf(prop.location, "Ignoring unsupported layout property: '%s'", prop.Name)
+		}
+	}
+
+	if layout.RowStretch != "" || layout.ColumnStretch != "" {
+		warnf(layout.location, "Ignoring row and column stretch factors of layout '%s', they are not supported in declarative mode", layout.Name)
+	}
+
+	buf.WriteString(fmt.Sprintf("Layout: declarative.%s{", typ))
+	if margL == 0 && margT == 0 && margR == 0 && margB == 0 {
+		buf.WriteString("MarginsZero: true, ")
+	} else if margL != 9 || margT != 9 || margR != 9 || margB != 9 {
+		buf.WriteString(fmt.Sprintf("Margins: declarative.Margins{%d, %d, %d, %d}, ", margL, margT, margR, margB))
+	}
+	if spacing == 0 {
+		buf.WriteString("SpacingZero: true, ")
+	} else if spacing != 6 {
+		buf.WriteString(fmt.Sprintf("Spacing: %d, ", spacing))
+	}
+	buf.WriteString("},\n")
+
+	buf.WriteString("Children: []declarative.Widget{\n")
+
+	for i, item := range layout.Item {
+		switch {
+		case item.Spacer != nil:
+			if err := writeDeclarativeSpacer(buf, item, typ == "Grid"); err != nil {
+				return err
+			}
+
+		case item.Widget != nil && !item.Widget.ignored:
+			var grid *Item
+			var stretchFactor int
+			if typ == "Grid" {
+				grid = item
+				stretchFactor = gridStretchFactor(item.Widget)
+			} else {
+				stretchFactor = boxStretchFactor(layout, i, item.Widget)
+			}
+
+			if err := writeDeclarativeWidget(buf, ctx, item.Widget, parent, grid, stretchFactor); err != nil {
+				return err
+			}
+		}
+	}
+
+	buf.WriteString("},\n")
+
+	return nil
+}
+
+// gridStretchFactor returns the stretch factor of the size policy of a
+// widget in a grid. In declarative mode, it stretches both the row and the
+// column of the widget.
+func gridStretchFactor(widget *Widget) int {
+	prop := findProperty(widget.Property, "sizePolicy")
+	if prop == nil || prop.SizePolicy == nil {
+		return 0
+	}
+
+	hor, ver := prop.SizePolicy.HorStretch, prop.SizePolicy.VerStretch
+	if hor == ver {
+		return hor
+	}
+
+	factor := hor
+	if ver > factor {
+		factor = ver
+	}
+
+	warnf(prop.location, "Using stretch factor %d of '%s' for both its row and column, separate horizontal and vertical stretch is not supported for grid items in declarative mode", factor, widget.Name)
+
+	return factor
+}
+
+func writeDeclarativeGridRange(buf *bytes.Buffer, item *Item) {
+	for _, f := range []struct {
+		name, value string
+	}{
+		{"Row", item.Row},
+		{"Column", item.Column},
+		{"RowSpan", item.RowSpan},
+		{"ColumnSpan", item.ColSpan},
+	} {
+		if f.value != "" && f.value != "0" && !(strings.HasSuffix(f.name, "Span") && f.value == "1") {
+			buf.WriteString(fmt.Sprintf("%s: %s,\n", f.name, f.value))
+		}
+	}
+}
+
+func writeDeclarativeSpacer(buf *bytes.Buffer, item *Item, grid bool) error {
+	orientation := findProperty(item.Spacer.Property, "orientation")
+	sizeType := findProperty(item.Spacer.Property, "sizeType")
+	sizeHint := findProperty(item.Spacer.Property, "sizeHint")
+
+	typ, size := "VSpacer", 0
+	if orientation != nil && orientation.Enum == "Qt::Horizontal" {
+		typ = "HSpacer"
+	}
+	if sizeType != nil && sizeType.Enum == "QSizePolicy::Fixed" && sizeHint != nil {
+		if typ == "HSpacer" {
+			size = sizeHint.Size.Width
+		} else {
+			size = sizeHint.Size.Height
+		}
+	}
+
+	buf.WriteString(fmt.Sprintf("declarative.%s{\n", typ))
+	if item.Spacer.Name != "" {
+		buf.WriteString(fmt.Sprintf("Name: %q,\n", item.Spacer.Name))
+	}
+	if size > 0 {
+		buf.WriteString(fmt.Sprintf("Size: %d,\n", size))
+	}
+	if grid {
+		writeDeclarativeGridRange(buf, item)
+	}
+	buf.WriteString("},\n")
+
+	return nil
+}
+
+func writeDeclarativeWidget(buf *bytes.Buffer, ctx *declarativeContext, widget *Widget, parent *Widget, gridItem *Item, stretchFactor int) error {
+	typ, custom := declarativeTypeName(widget, parent)
+
+	if custom {
+		buf.WriteString(fmt.Sprintf("%s{\n", typ))
+	} else {
+		buf.WriteString(fmt.Sprintf("declarative.%s{\n", typ))
+	}
+
+	buf.WriteString(fmt.Sprintf("AssignTo: &w.ui.%s,\n", widget.Name))
+	buf.WriteString(fmt.Sprintf("Name: %q,\n", widget.Name))
+
+	if gridItem != nil {
+		writeDeclarativeGridRange(buf, gridItem)
+	}
+	if stretchFactor > 0 {
+		buf.WriteString(fmt.Sprintf("StretchFactor: %d,\n", stretchFactor))
+	}
+
+	if parent != nil && parent.Class == "QStackedWidget" {
+		var currentIndex int
+		if prop := findProperty(parent.Property, "currentIndex"); prop != nil {
+			currentIndex = int(prop.Number)
+		}
+
+		for i, page := range parent.Widget {
+			if page == widget && i != currentIndex {
+				buf.WriteString("Visible: false,\n")
+			}
+		}
+	}
+
+	for _, attr := range widget.Attribute {
+		switch attr.Name {
+		case "title":
+			buf.WriteString(fmt.Sprintf("Title: %s,\n", trString(&attr.String)))
+
+		default:
+			warnf(attr.location, "Ignoring unsupported attribute: '%s'", attr.Name)
+		}
+	}
+
+	if err := writeDeclarativeProperties(buf, ctx, widget, typ, false); err != nil {
+		return err
+	}
+
+	switch widget {
+	case ctx.defaultButton:
+		buf.WriteString("OnClicked: func() { w.Accept() },\n")
+
+	case ctx.cancelButton:
+		buf.WriteString("OnClicked: func() { w.Cancel() },\n")
+	}
+
+	if err := writeDeclarativeChildren(buf, ctx, widget, typ); err != nil {
+		return err
+	}
+
+	buf.WriteString("},\n")
+
+	return nil
+}
+
+func writeDeclarativeChildren(buf *bytes.Buffer, ctx *declarativeContext, widget *Widget, typ string) error {
+	if widget.Layout != nil && !widget.Layout.ignored {
+		return writeDeclarativeLayout(buf, ctx, widget.Layout, widget)
+	}
+
+	var children []*Widget
+	for _, child := range widget.Widget {
+		if child.ignored || child.Class == "QMenuBar" || child.Class == "QStatusBar" {
+			continue
+		}
+
+		children = append(children, child)
+	}
+
+	if len(children) == 0 {
+		return nil
+	}
+
+	switch typ {
+	case "TabWidget":
+		buf.WriteString("Pages: []declarative.TabPage{\n")
+
+	case "Composite", "GroupBox", "HSplitter", "VSplitter", "ScrollView", "TabPage":
+		if widget.Class == "QStackedWidget" {
+			// There is no stacked widget in walk, so we stack the pages in a
+			// VBox and only show the current one.
+			buf.WriteString("Layout: declarative.VBox{MarginsZero: true},\n")
+		}
+
+		buf.WriteString("Children: []declarative.Widget{\n")
+
+	default:
+		warnf(widget.location, "Ignoring children of %s '%s'", widget.Class, widget.Name)
+		return nil
+	}
+
+	for _, child := range children {
+		if widget.Class != "QStackedWidget" && (typ == "Composite" || typ == "GroupBox" || typ == "TabPage") {
+			if prop := findProperty(child.Property, "geometry"); prop != nil {
+				warnf(prop.location, "Ignoring geometry of '%s', absolute positioning is not supported in declarative mode, give '%s' a layout instead", child.Name, widget.Name)
+			}
+		}
+
+		if err := writeDeclarativeWidget(buf, ctx, child, widget, nil, 0); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteString("},\n")
+
+	return nil
+}
+
+func writeDeclarativeMenuItems(buf *bytes.Buffer, menu *Widget, realActions map[string]bool) error {
+	for _, addAction := range menu.AddAction {
+		if addAction.Name == "separator" {
+			buf.WriteString("declarative.Separator{},\n")
+			continue
+		}
+
+		if realActions[addAction.Name] {
+			buf.WriteString(fmt.Sprintf("declarative.ActionRef{&w.ui.actions.%s},\n", addAction.Name))
+			continue
+		}
+
+		for _, submenu := range menu.Widget {
+			if submenu.Name != addAction.Name {
+				continue
+			}
+
+			buf.WriteString("declarative.Menu{\n")
+			if title := findProperty(submenu.Property, "title"); title != nil {
+				buf.WriteString(fmt.Sprintf("Text: %s,\n", trString(&title.String)))
+			}
+			buf.WriteString("Items: []declarative.MenuItem{\n")
+
+			if err := writeDeclarativeMenuItems(buf, submenu, realActions); err != nil {
+				return err
+			}
+
+			buf.WriteString("},\n},\n")
+		}
+	}
+
+	return nil
+}
+
+func writeDeclarativeActions(buf *bytes.Buffer, actions []*Action) {
+	for _, action := range actions {
+		buf.WriteString(fmt.Sprintf("w.ui.actions.%s = walk.NewAction()\n", action.Name))
+
+		for _, prop := range action.Property {
+			switch prop.Name {
+			case "text":
+				buf.WriteString(fmt.Sprintf("w.ui.actions.%s.SetText(%s)\n", action.Name, trString(&prop.String)))
+
+			case "checkable":
+				buf.WriteString(fmt.Sprintf("w.ui.actions.%s.SetCheckable(%t)\n", action.Name, prop.Bool))
+
+			case "enabled":
+				buf.WriteString(fmt.Sprintf("w.ui.actions.%s.SetEnabled(%t)\n", action.Name, prop.Bool))
+
+			case "toolTip":
+				buf.WriteString(fmt.Sprintf("w.ui.actions.%s.SetToolTip(%s)\n", action.Name, trString(&prop.String)))
+
+			case "icon":
+				if prop.image != "" {
+					// declaration can't fail, so a missing image just won't show.
+					buf.WriteString(fmt.Sprintf(
+						`if img, err := walk.Resources.Image(%q); err == nil {
+						w.ui.actions.%s.SetImage(img)
+						}
+						`,
+						prop.image, action.Name))
+				}
+
+			default:
+				warnf(prop.location, "Ignoring unsupported action property: '%s'", prop.Name)
+			}
+		}
+	}
+
+	buf.WriteString("\n")
+}
+
+func generateDeclarativeUICode(buf *bytes.Buffer, ui *UI) error {
+	buf.WriteString(
+		`// This file was created by ui2walk and may be regenerated.
+		// DO NOT EDIT OR YOUR MODIFICATIONS WILL BE LOST!
+
+		package main
+
+		import (
+			"github.com/lxn/walk"
+			"github.com/lxn/walk/declarative"
+		)
+
+		`)
+
+	var embeddedType string
+	switch ui.Widget.Class {
+	case "QMainWindow":
+		embeddedType = "MainWindow"
+
+	case "QDialog":
+		embeddedType = "Dialog"
+
+	case "QWidget":
+		embeddedType = "Composite"
+
+	default:
+		return errors.New(fmt.Sprintf("Top level '%s' currently not supported.", ui.Widget.Class))
+	}
+
+	ctx := &declarativeContext{ui: ui}
+	if embeddedType == "Dialog" {
+		ctx.defaultButton = findWidget(&ui.Widget, "QPushButton", []string{"accept", "ok"})
+		ctx.cancelButton = findWidget(&ui.Widget, "QPushButton", []string{"cancel"})
+	}
+
+	// The declaration is generated first, because it tells us whether we need
+	// a DataBinder.
+	decl := new(bytes.Buffer)
+	if err := writeDeclaration(decl, ctx, embeddedType); err != nil {
+		return err
+	}
+
+	genTypeBaseName := strings.ToLower(ui.Class[:1]) + ui.Class[1:]
+
+	if len(ui.Widget.Action) > 0 {
+		buf.WriteString(fmt.Sprintf("type %sActions struct {\n", genTypeBaseName))
+
+		writeActionDecls(buf, ui.Widget.Action)
+
+		buf.WriteString("}\n\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("type %sUI struct {\n", genTypeBaseName))
+
+	if len(ui.Widget.Action) > 0 {
+		buf.WriteString(fmt.Sprintf("actions %sActions\n", genTypeBaseName))
+	}
+
+	if ctx.hasBindings {
+		buf.WriteString("dataBinder *walk.DataBinder\n")
+	}
+
+	if ui.Widget.Widget != nil {
+		if err := writeWidgetDecls(buf, ui.Widget.Widget, &ui.Widget); err != nil {
+			return err
+		}
+	}
+
+	if ui.Widget.Layout != nil && !ui.Widget.Layout.ignored {
+		if err := writeItemDecls(buf, ui.Widget.Layout.Item, &ui.Widget); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteString("}\n\n")
+
+	buf.Write(decl.Bytes())
+
+	buf.WriteString(fmt.Sprintf(`
+		// initTabOrder brings the widgets of %s into the tab order defined in
+		// Qt Designer. It must be called after creating the declaration.
+		func (w *%s) initTabOrder() error {
+		`,
+		ui.Widget.Name, ui.Widget.Name))
+
+	for i := len(ui.TabStops) - 1; i >= 0; i-- {
+		buf.WriteString(fmt.Sprintf(`if err := w.ui.%s.BringToTop(); err != nil {
+			return err
+		}
+		`,
+			ui.TabStops[i].Name))
+	}
+
+	buf.WriteString("\nreturn nil\n}\n")
+
+	return nil
+}
+
+func writeDeclaration(buf *bytes.Buffer, ctx *declarativeContext, embeddedType string) error {
+	ui := ctx.ui
+
+	buf.WriteString(fmt.Sprintf(
+		`// declaration returns the declarative description of %s. The
+		// widgets it creates will be assigned to the fields of w.ui.
+		func (w *%s) declaration() declarative.%s {
+		`,
+		ui.Widget.Name, ui.Widget.Name, embeddedType))
+
+	if len(ui.Widget.Action) > 0 {
+		writeDeclarativeActions(buf, ui.Widget.Action)
+	}
+
+	body := new(bytes.Buffer)
+
+	body.WriteString(fmt.Sprintf("AssignTo: &w.%s,\n", embeddedType))
+	body.WriteString(fmt.Sprintf("Name: %q,\n", ui.Widget.Name))
+
+	if err := writeDeclarativeProperties(body, ctx, &ui.Widget, embeddedType, true); err != nil {
+		return err
+	}
+
+	switch embeddedType {
+	case "MainWindow":
+		var menuBar *Widget
+		for _, widget := range ui.Widget.Widget {
+			if widget.Class == "QMenuBar" {
+				menuBar = widget
+				break
+			}
+		}
+
+		if menuBar != nil {
+			realActions := make(map[string]bool)
+			for _, action := range ui.Widget.Action {
+				realActions[action.Name] = true
+			}
+
+			body.WriteString("MenuItems: []declarative.MenuItem{\n")
+			if err := writeDeclarativeMenuItems(body, menuBar, realActions); err != nil {
+				return err
+			}
+			body.WriteString("},\n")
+		}
+
+		// The imperative code uses a VBox with zero margins for the central
+		// widget, so we do the same.
+		if ui.Widget.Layout == nil || ui.Widget.Layout.ignored {
+			body.WriteString("Layout: declarative.VBox{MarginsZero: true},\n")
+		}
+
+	case "Dialog":
+		if ctx.defaultButton != nil {
+			body.WriteString(fmt.Sprintf("DefaultButton: &w.ui.%s,\n", ctx.defaultButton.Name))
+		}
+		if ctx.cancelButton != nil {
+			body.WriteString(fmt.Sprintf("CancelButton: &w.ui.%s,\n", ctx.cancelButton.Name))
+		}
+	}
+
+	if ui.Widget.Layout != nil && !ui.Widget.Layout.ignored {
+		if err := writeDeclarativeLayout(body, ctx, ui.Widget.Layout, &ui.Widget); err != nil {
+			return err
+		}
+	} else {
+		var children []*Widget
+		for _, widget := range ui.Widget.Widget {
+			if !widget.ignored && widget.Class != "QMenuBar" && widget.Class != "QStatusBar" {
+				children = append(children, widget)
+			}
+		}
+
+		if len(children) > 0 {
+			body.WriteString("Children: []declarative.Widget{\n")
+			for _, widget := range children {
+				if err := writeDeclarativeWidget(body, ctx, widget, &ui.Widget, nil, 0); err != nil {
+					return err
+				}
+			}
+			body.WriteString("},\n")
+		}
+	}
+
+	if ctx.hasBindings {
+		// The data source is up to the logic code, see declaration().
+		body.WriteString(`DataBinder: declarative.DataBinder{
+			AssignTo:       &w.ui.dataBinder,
+			ErrorPresenter: declarative.ToolTipErrorPresenter{},
+		},
+		`)
+	}
+
+	buf.WriteString(fmt.Sprintf("return declarative.%s{\n", embeddedType))
+	buf.Write(body.Bytes())
+	buf.WriteString("}\n}\n")
+
+	return nil
+}
+
+func generateDeclarativeLogicCode(buf *bytes.Buffer, ui *UI) error {
+	buf.WriteString(
+		`package main
+
+		import (
+			"github.com/lxn/walk"
+		`)
+
+	if ui.Widget.Class == "QWidget" {
+		buf.WriteString(`"github.com/lxn/walk/declarative"
+		`)
+	}
+
+	buf.WriteString(`)
+
+		`)
+
+	var embeddedType string
+	switch ui.Widget.Class {
+	case "QMainWindow":
+		embeddedType = "MainWindow"
+
+	case "QDialog":
+		embeddedType = "Dialog"
+
+	case "QWidget":
+		embeddedType = "Composite"
+
+	default:
+		return errors.New(fmt.Sprintf("Top level '%s' currently not supported.", ui.Widget.Class))
+	}
+
+	name := ui.Widget.Name
+
+	buf.WriteString(fmt.Sprintf(`type %s struct {
+		*walk.%s
+		ui %sUI
+	}
+
+	`,
+		name, embeddedType, strings.ToLower(ui.Class[:1])+ui.Class[1:]))
+
+	const todo = `// TODO: Do further required setup, e.g. for event handling, here.
+	// To use data binding, set decl.DataBinder.DataSource.
+	`
+
+	switch embeddedType {
+	case "MainWindow":
+		buf.WriteString(fmt.Sprintf(`func run%s() (int, error) {
+		mw := new(%s)
+
+		decl := mw.declaration()
+
+		%s
+		if err := decl.Create(); err != nil {
+			return 0, err
+		}
+
+		if err := mw.initTabOrder(); err != nil {
+			return 0, err
+		}
+
+		return mw.Run(), nil
+		}
+		`,
+			name, name, todo))
+
+	case "Dialog":
+		buf.WriteString(fmt.Sprintf(`func run%s(owner walk.Form) (int, error) {
+		dlg := new(%s)
+
+		decl := dlg.declaration()
+
+		%s
+		if err := decl.Create(owner); err != nil {
+			return 0, err
+		}
+
+		if err := dlg.initTabOrder(); err != nil {
+			return 0, err
+		}
+
+		return dlg.Run(), nil
+		}
+		`,
+			name, name, todo))
+
+	case "Composite":
+		buf.WriteString(fmt.Sprintf(`func new%s(parent walk.Container) (*%s, error) {
+		c := new(%s)
+
+		decl := c.declaration()
+
+		%s
+		if err := decl.Create(declarative.NewBuilder(parent)); err != nil {
+			return nil, err
+		}
+
+		if err := c.initTabOrder(); err != nil {
+			return nil, err
+		}
+
+		return c, nil
+		}
+		`,
+			name, name, name, todo))
+	}
+
+	return nil
+}
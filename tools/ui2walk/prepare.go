@@ -0,0 +1,619 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// location is the position of an element in a .ui file. It is embedded in the
+// types of elements that warnings may refer to.
+type location struct {
+	offset int64
+	line   int
+}
+
+func (w *Widget) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type widget Widget
+	w.offset = d.InputOffset()
+	return d.DecodeElement((*widget)(w), &start)
+}
+
+func (l *Layout) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type layout Layout
+	l.offset = d.InputOffset()
+	return d.DecodeElement((*layout)(l), &start)
+}
+
+func (s *Spacer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type spacer Spacer
+	s.offset = d.InputOffset()
+	return d.DecodeElement((*spacer)(s), &start)
+}
+
+func (a *Action) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type action Action
+	a.offset = d.InputOffset()
+	return d.DecodeElement((*action)(a), &start)
+}
+
+func (a *Attribute) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type attribute Attribute
+	a.offset = d.InputOffset()
+	return d.DecodeElement((*attribute)(a), &start)
+}
+
+func (p *Property) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type property Property
+	p.offset = d.InputOffset()
+	return d.DecodeElement((*property)(p), &start)
+}
+
+func (ts *TabStop) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type tabStop TabStop
+	ts.offset = d.InputOffset()
+	return d.DecodeElement((*tabStop)(ts), &start)
+}
+
+func (inc *Include) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type include Include
+	inc.offset = d.InputOffset()
+	return d.DecodeElement((*include)(inc), &start)
+}
+
+// warnf prints a warning about an unsupported construct at loc.
+func warnf(loc location, format string, args ...interface{}) {
+	fmt.Printf("line %d: %s\n", loc.line, fmt.Sprintf(format, args...))
+}
+
+// setLines translates the offsets recorded while decoding into line numbers.
+func setLines(ui *UI, data []byte) {
+	var newlines []int
+	for i, b := range data {
+		if b == '\n' {
+			newlines = append(newlines, i)
+		}
+	}
+
+	set := func(loc *location) {
+		loc.line = sort.SearchInts(newlines, int(loc.offset)) + 1
+	}
+
+	setProps := func(props []*Property) {
+		for _, prop := range props {
+			set(&prop.location)
+		}
+	}
+
+	var setWidget func(widget *Widget)
+	var setLayout func(layout *Layout)
+
+	setItems := func(items []*Item) {
+		for _, item := range items {
+			setProps(item.Property)
+
+			if item.Spacer != nil {
+				set(&item.Spacer.location)
+				setProps(item.Spacer.Property)
+			}
+			if item.Widget != nil {
+				setWidget(item.Widget)
+			}
+			if item.Layout != nil {
+				setLayout(item.Layout)
+			}
+		}
+	}
+
+	setLayout = func(layout *Layout) {
+		set(&layout.location)
+		setProps(layout.Property)
+		setItems(layout.Item)
+	}
+
+	setWidget = func(widget *Widget) {
+		set(&widget.location)
+		setProps(widget.Property)
+
+		for _, attr := range widget.Attribute {
+			set(&attr.location)
+		}
+		for _, action := range widget.Action {
+			set(&action.location)
+			setProps(action.Property)
+		}
+
+		setItems(widget.Item)
+
+		if widget.Layout != nil {
+			setLayout(widget.Layout)
+		}
+
+		for _, child := range widget.Widget {
+			setWidget(child)
+		}
+	}
+
+	setWidget(&ui.Widget)
+
+	for _, ts := range ui.TabStops {
+		set(&ts.location)
+	}
+	for _, inc := range ui.Includes {
+		set(&inc.location)
+	}
+}
+
+// supportedClasses contains the Qt classes ui2walk knows how to translate.
+// Other classes are only accepted if they are declared as custom widgets.
+var supportedClasses = map[string]bool{
+	"QCheckBox":      true,
+	"QComboBox":      true,
+	"QDateEdit":      true,
+	"QDateTimeEdit":  true,
+	"QDoubleSpinBox": true,
+	"QFrame":         true,
+	"QGroupBox":      true,
+	"QLabel":         true,
+	"QLineEdit":      true,
+	"QListWidget":    true,
+	"QMenuBar":       true,
+	"QPlainTextEdit": true,
+	"QProgressBar":   true,
+	"QPushButton":    true,
+	"QRadioButton":   true,
+	"QScrollArea":    true,
+	"QSlider":        true,
+	"QSpinBox":       true,
+	"QSplitter":      true,
+	"QStackedWidget": true,
+	"QStatusBar":     true,
+	"QTabWidget":     true,
+	"QTableView":     true,
+	"QTableWidget":   true,
+	"QTextEdit":      true,
+	"QToolButton":    true,
+	"QTreeView":      true,
+	"QTreeWidget":    true,
+	"QWebView":       true,
+	"QWidget":        true,
+}
+
+var supportedLayoutClasses = map[string]bool{
+	"QFormLayout": true,
+	"QGridLayout": true,
+	"QHBoxLayout": true,
+	"QVBoxLayout": true,
+}
+
+// prepareUI checks ui for constructs we can't translate, marking them as
+// ignored, and does the transformations both code generators depend on:
+//
+// The contents widget of a QScrollArea is merged into the scroll area, buddy
+// labels become accessibility names of their buddies and icons referring to
+// .qrc resources are resolved to file paths relative to dirPath, the directory
+// of the .ui file. The generated code loads those files by means of
+// walk.Resources, so its root directory should match dirPath.
+func prepareUI(ui *UI, dirPath string) error {
+	resources, err := loadResources(ui.Includes, dirPath)
+	if err != nil {
+		return err
+	}
+
+	customClasses := make(map[string]bool)
+	for _, cw := range ui.CustomWidgets.CustomWidget {
+		customClasses[cw.Class] = true
+	}
+
+	name2Widget := make(map[string]*Widget)
+	buddy2Label := make(map[string]*Property)
+
+	var prepareWidget func(widget *Widget)
+	var prepareLayout func(layout *Layout)
+
+	prepareLayout = func(layout *Layout) {
+		if !supportedLayoutClasses[layout.Class] {
+			warnf(layout.location, "Ignoring unsupported layout type: '%s'", layout.Class)
+			layout.ignored = true
+			return
+		}
+
+		for _, item := range layout.Item {
+			if item.Layout != nil {
+				// Walk layouts can't be nested, so we put nested layouts into
+				// composites. Like in Qt, nested layouts have no margins by
+				// default.
+				margin := &Property{Name: "margin", location: item.Layout.location}
+				item.Layout.Property = append([]*Property{margin}, item.Layout.Property...)
+
+				item.Widget = &Widget{
+					Class:    "QWidget",
+					Name:     item.Layout.Name,
+					Layout:   item.Layout,
+					location: item.Layout.location,
+				}
+				item.Layout = nil
+			}
+
+			if item.Widget != nil {
+				prepareWidget(item.Widget)
+			}
+		}
+	}
+
+	prepareWidget = func(widget *Widget) {
+		if widget != &ui.Widget && !supportedClasses[widget.Class] && !customClasses[widget.Class] {
+			warnf(widget.location, "Ignoring unsupported widget class: '%s'", widget.Class)
+			widget.ignored = true
+			return
+		}
+
+		if widget.Class == "QMenuBar" || widget.Class == "QStatusBar" {
+			return
+		}
+
+		name2Widget[widget.Name] = widget
+
+		if widget.Class == "QScrollArea" && len(widget.Widget) == 1 && widget.Widget[0].Class == "QWidget" {
+			contents := widget.Widget[0]
+
+			if contents.Layout == nil && len(contents.Widget) > 0 {
+				warnf(contents.location, "Widgets of QScrollArea '%s' will not be laid out, because it has no layout", widget.Name)
+			}
+
+			widget.Layout = contents.Layout
+			widget.Widget = contents.Widget
+		}
+
+		for _, prop := range widget.Property {
+			switch prop.Name {
+			case "buddy":
+				if widget.Class == "QLabel" {
+					if text := findProperty(widget.Property, "text"); text != nil {
+						buddy2Label[prop.Cstring] = text
+					}
+				}
+
+			case "icon", "pixmap", "windowIcon":
+				prop.image = resolveImage(prop, resources)
+			}
+		}
+
+		if widget.Layout != nil {
+			prepareLayout(widget.Layout)
+		}
+
+		for _, child := range widget.Widget {
+			prepareWidget(child)
+		}
+	}
+
+	prepareWidget(&ui.Widget)
+
+	for _, action := range ui.Widget.Action {
+		if prop := findProperty(action.Property, "icon"); prop != nil {
+			prop.image = resolveImage(prop, resources)
+		}
+	}
+
+	for buddy, text := range buddy2Label {
+		widget := name2Widget[buddy]
+		if widget == nil {
+			warnf(text.location, "Ignoring buddy of label, because widget '%s' was not found", buddy)
+			continue
+		}
+
+		if findProperty(widget.Property, "accessibleName") != nil {
+			continue
+		}
+
+		name := *text
+		name.Name = "accessibleName"
+		name.Text = accessibleNameFromLabel(text.Text)
+
+		widget.Property = append(widget.Property, &name)
+	}
+
+	var tabStops []*TabStop
+	for _, ts := range ui.TabStops {
+		if name2Widget[ts.Name] == nil {
+			warnf(ts.location, "Ignoring tab stop, because widget '%s' was not found", ts.Name)
+			continue
+		}
+
+		tabStops = append(tabStops, ts)
+	}
+	ui.TabStops = tabStops
+
+	return nil
+}
+
+// accessibleNameFromLabel returns the text of a label without mnemonic
+// markers and trailing colon.
+func accessibleNameFromLabel(text string) string {
+	text = strings.Replace(text, "&&", "\x00", -1)
+	text = strings.Replace(text, "&", "", -1)
+	text = strings.Replace(text, "\x00", "&", -1)
+
+	return strings.TrimRight(strings.TrimSpace(text), ":")
+}
+
+type qrcFile struct {
+	QResource []struct {
+		Prefix string `xml:"prefix,attr"`
+		File   []struct {
+			Alias string `xml:"alias,attr"`
+			Path  string `xml:",chardata"`
+		} `xml:"file"`
+	} `xml:"qresource"`
+}
+
+// loadResources reads the .qrc files included by a .ui file and returns a map
+// from resource paths, like ":/icons/open.png", to file paths relative to
+// dirPath.
+func loadResources(includes []*Include, dirPath string) (map[string]string, error) {
+	resources := make(map[string]string)
+
+	for _, inc := range includes {
+		qrcPath := filepath.Join(dirPath, filepath.FromSlash(inc.Location))
+
+		data, err := ioutil.ReadFile(qrcPath)
+		if err != nil {
+			warnf(inc.location, "Ignoring resource file: %v", err)
+			continue
+		}
+
+		var qrc qrcFile
+		if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&qrc); err != nil {
+			return nil, fmt.Errorf("%s: %v", qrcPath, err)
+		}
+
+		qrcDirPath := filepath.Dir(qrcPath)
+
+		for _, res := range qrc.QResource {
+			for _, file := range res.File {
+				filePath := strings.TrimSpace(file.Path)
+
+				name := file.Alias
+				if name == "" {
+					name = filePath
+				}
+
+				relPath, err := filepath.Rel(dirPath, filepath.Join(qrcDirPath, filepath.FromSlash(filePath)))
+				if err != nil {
+					return nil, err
+				}
+
+				resources[":"+path.Join("/", res.Prefix, name)] = filepath.ToSlash(relPath)
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+// resolveImage returns the file path of the image referenced by prop, or ""
+// if there is none.
+func resolveImage(prop *Property, resources map[string]string) string {
+	var ref string
+	switch {
+	case prop.IconSet != nil && prop.IconSet.NormalOff != "":
+		ref = prop.IconSet.NormalOff
+
+	case prop.IconSet != nil:
+		ref = prop.IconSet.Text
+
+	case prop.Pixmap != nil:
+		ref = prop.Pixmap.Text
+	}
+
+	ref = strings.TrimSpace(ref)
+
+	if ref == "" {
+		warnf(prop.location, "Ignoring unsupported %s, only file and resource references are supported", prop.Name)
+		return ""
+	}
+
+	if !strings.HasPrefix(ref, ":") {
+		return ref
+	}
+
+	filePath, ok := resources[":"+path.Clean(ref[1:])]
+	if !ok {
+		warnf(prop.location, "Ignoring %s, resource '%s' was not found", prop.Name, ref)
+		return ""
+	}
+
+	return filePath
+}
+
+// isImageLabel returns if widget is a QLabel that shows a pixmap instead of
+// text. We translate those to ImageViews.
+func isImageLabel(widget *Widget) bool {
+	if widget.Class != "QLabel" {
+		return false
+	}
+
+	prop := findProperty(widget.Property, "pixmap")
+
+	return prop != nil && prop.image != ""
+}
+
+// isVertical returns if widget has a vertical orientation. Like Qt, we assume
+// vertical for QSliders without orientation property.
+func isVertical(widget *Widget) bool {
+	if prop := findProperty(widget.Property, "orientation"); prop != nil {
+		return prop.Enum == "Qt::Vertical"
+	}
+
+	return widget.Class == "QSlider"
+}
+
+// listItemsModel returns a []string literal containing the texts of the items
+// of a QComboBox or QListWidget, or "" if there are none.
+func listItemsModel(widget *Widget) string {
+	if len(widget.Item) == 0 {
+		return ""
+	}
+
+	var texts []string
+	for _, item := range widget.Item {
+		var str String
+		if prop := findProperty(item.Property, "text"); prop != nil {
+			str = prop.String
+		}
+
+		texts = append(texts, trString(&str))
+	}
+
+	return fmt.Sprintf("[]string{%s}", strings.Join(texts, ", "))
+}
+
+// numberValue returns the value of a property holding a <number> or <double>.
+func numberValue(prop *Property) float64 {
+	if prop.Double != 0 {
+		return prop.Double
+	}
+
+	return prop.Number
+}
+
+// floatLiteral formats f so that it is a float64 constant, even if it is
+// whole.
+func floatLiteral(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// valueRange returns the range of the value of a QSlider, QProgressBar,
+// QSpinBox or QDoubleSpinBox, taking Qt defaults into account, and whether
+// its properties specify any.
+func valueRange(widget *Widget) (min, max float64, ok bool) {
+	max = 99
+	switch widget.Class {
+	case "QDoubleSpinBox":
+		max = 99.99
+
+	case "QProgressBar":
+		max = 100
+	}
+
+	if prop := findProperty(widget.Property, "minimum"); prop != nil {
+		min = numberValue(prop)
+		ok = true
+	}
+	if prop := findProperty(widget.Property, "maximum"); prop != nil {
+		max = numberValue(prop)
+		ok = true
+	}
+
+	return
+}
+
+// boxStretchFactor returns the stretch factor Qt Designer assigned to the
+// item at index of a box layout, either by means of the stretch attribute of
+// the layout or the size policy of the item widget.
+func boxStretchFactor(layout *Layout, index int, widget *Widget) int {
+	if layout.Stretch != "" {
+		parts := strings.Split(layout.Stretch, ",")
+		if index < len(parts) {
+			factor, _ := strconv.Atoi(strings.TrimSpace(parts[index]))
+			return factor
+		}
+
+		return 0
+	}
+
+	prop := findProperty(widget.Property, "sizePolicy")
+	if prop == nil || prop.SizePolicy == nil {
+		return 0
+	}
+
+	if layout.Class == "QHBoxLayout" {
+		return prop.SizePolicy.HorStretch
+	}
+
+	return prop.SizePolicy.VerStretch
+}
+
+// stretchFactors parses the rowstretch or columnstretch attribute of a
+// QGridLayout.
+func stretchFactors(attr string) []int {
+	if attr == "" {
+		return nil
+	}
+
+	var factors []int
+	for _, part := range strings.Split(attr, ",") {
+		factor, _ := strconv.Atoi(strings.TrimSpace(part))
+		factors = append(factors, factor)
+	}
+
+	return factors
+}
+
+// dateFormatFromQt translates the displayFormat of a QDateEdit or
+// QDateTimeEdit into the format of a Windows date and time picker.
+func dateFormatFromQt(format string) string {
+	buf := new(bytes.Buffer)
+
+	var literal []rune
+	flushLiteral := func() {
+		if len(literal) > 0 {
+			buf.WriteString("'")
+			buf.WriteString(strings.Replace(string(literal), "'", "''", -1))
+			buf.WriteString("'")
+			literal = nil
+		}
+	}
+
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case 'd', 'M', 'y', 'h', 'H', 'm', 's':
+			flushLiteral()
+			buf.WriteRune(r)
+
+		case 'A', 'a':
+			flushLiteral()
+			if i+1 < len(runes) && (runes[i+1] == 'P' || runes[i+1] == 'p') {
+				i++
+				buf.WriteString("tt")
+			} else {
+				buf.WriteString("t")
+			}
+
+		case 'z':
+			// Milliseconds are not supported by date and time pickers.
+			for i+1 < len(runes) && runes[i+1] == 'z' {
+				i++
+			}
+
+		case '\'':
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				literal = append(literal, runes[i])
+			}
+
+		default:
+			literal = append(literal, r)
+		}
+	}
+
+	flushLiteral()
+
+	return buf.String()
+}
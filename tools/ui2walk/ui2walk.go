@@ -12,6 +12,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
@@ -22,6 +23,7 @@ import (
 
 var forceUpdate *bool = flag.Bool("force", false, "forces code generation for up-to-date files")
 var translatable *bool = flag.Bool("tr", false, "adds calls to a user provided 'func tr(source string, context ...string) string' that returns a translation of the source argument, using provided context args for disambiguation")
+var declarativeMode *bool = flag.Bool("declarative", false, "generates declarative code, i.e. a declarative.MainWindow, Dialog or Composite literal, instead of imperative code")
 
 type String struct {
 	Text         string `xml:"string"`
@@ -33,7 +35,8 @@ type UI struct {
 	Class         string        `xml:"class"`
 	Widget        Widget        `xml:"widget"`
 	CustomWidgets CustomWidgets `xml:"customwidgets"`
-	TabStops      []string      `xml:"tabstops>tabstop"`
+	TabStops      []*TabStop    `xml:"tabstops>tabstop"`
+	Includes      []*Include    `xml:"resources>include"`
 }
 
 type Widget struct {
@@ -45,30 +48,38 @@ type Widget struct {
 	Widget    []*Widget    `xml:"widget"`
 	AddAction []*AddAction `xml:"addaction"`
 	Action    []*Action    `xml:"action"`
+	Item      []*Item      `xml:"item"`
 	ignored   bool
+	location
 }
 
 type Layout struct {
-	Class    string      `xml:"class,attr"`
-	Name     string      `xml:"name,attr"`
-	Stretch  string      `xml:"stretch,attr"`
-	Property []*Property `xml:"property"`
-	Item     []*Item     `xml:"item"`
-	ignored  bool
+	Class         string      `xml:"class,attr"`
+	Name          string      `xml:"name,attr"`
+	Stretch       string      `xml:"stretch,attr"`
+	RowStretch    string      `xml:"rowstretch,attr"`
+	ColumnStretch string      `xml:"columnstretch,attr"`
+	Property      []*Property `xml:"property"`
+	Item          []*Item     `xml:"item"`
+	ignored       bool
+	location
 }
 
 type Item struct {
-	Row     string  `xml:"row,attr"`
-	Column  string  `xml:"column,attr"`
-	RowSpan string  `xml:"rowspan,attr"`
-	ColSpan string  `xml:"colspan,attr"`
-	Widget  *Widget `xml:"widget"`
-	Spacer  *Spacer `xml:"spacer"`
+	Row      string      `xml:"row,attr"`
+	Column   string      `xml:"column,attr"`
+	RowSpan  string      `xml:"rowspan,attr"`
+	ColSpan  string      `xml:"colspan,attr"`
+	Widget   *Widget     `xml:"widget"`
+	Layout   *Layout     `xml:"layout"`
+	Spacer   *Spacer     `xml:"spacer"`
+	Property []*Property `xml:"property"`
 }
 
 type Spacer struct {
 	Name     string      `xml:"name,attr"`
 	Property []*Property `xml:"property"`
+	location
 }
 
 type AddAction struct {
@@ -78,23 +89,32 @@ type AddAction struct {
 type Action struct {
 	Name     string      `xml:"name,attr"`
 	Property []*Property `xml:"property"`
+	location
 }
 
 type Attribute struct {
 	Name string `xml:"name,attr"`
 	String
+	location
 }
 
 type Property struct {
-	Name   string    `xml:"name,attr"`
-	Bool   bool      `xml:"bool"`
-	Enum   string    `xml:"enum"`
-	Font   *Font     `xml:"font"`
-	Number float64   `xml:"number"`
-	Rect   Rectangle `xml:"rect"`
-	Set    string    `xml:"set"`
-	Size   Size      `xml:"size"`
+	Name       string      `xml:"name,attr"`
+	Bool       bool        `xml:"bool"`
+	Cstring    string      `xml:"cstring"`
+	Double     float64     `xml:"double"`
+	Enum       string      `xml:"enum"`
+	Font       *Font       `xml:"font"`
+	IconSet    *IconSet    `xml:"iconset"`
+	Number     float64     `xml:"number"`
+	Pixmap     *Pixmap     `xml:"pixmap"`
+	Rect       Rectangle   `xml:"rect"`
+	Set        string      `xml:"set"`
+	Size       Size        `xml:"size"`
+	SizePolicy *SizePolicy `xml:"sizepolicy"`
 	String
+	image string
+	location
 }
 
 type Font struct {
@@ -118,6 +138,34 @@ type Size struct {
 	Height int `xml:"height"`
 }
 
+type SizePolicy struct {
+	HSizeType  string `xml:"hsizetype,attr"`
+	VSizeType  string `xml:"vsizetype,attr"`
+	HorStretch int    `xml:"horstretch"`
+	VerStretch int    `xml:"verstretch"`
+}
+
+type IconSet struct {
+	Resource  string `xml:"resource,attr"`
+	NormalOff string `xml:"normaloff"`
+	Text      string `xml:",chardata"`
+}
+
+type Pixmap struct {
+	Resource string `xml:"resource,attr"`
+	Text     string `xml:",chardata"`
+}
+
+type TabStop struct {
+	Name string `xml:",chardata"`
+	location
+}
+
+type Include struct {
+	Location string `xml:"location,attr"`
+	location
+}
+
 type CustomWidgets struct {
 	CustomWidget []*CustomWidget `xml:"customwidget"`
 }
@@ -165,12 +213,19 @@ func logFatal(err error) {
 }
 
 func parseUI(reader io.Reader) (*UI, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
 	ui := &UI{}
 
-	if err := xml.NewDecoder(reader).Decode(ui); err != nil {
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(ui); err != nil {
 		return nil, err
 	}
 
+	setLines(ui, data)
+
 	return ui, nil
 }
 
@@ -182,7 +237,7 @@ func writeAttribute(buf *bytes.Buffer, attr *Attribute, qualifiedReceiver string
 			qualifiedReceiver, trString(&attr.String)))
 
 	default:
-		fmt.Printf("Ignoring unsupported attribute: '%s'\n", attr.Name)
+		warnf(attr.location, "Ignoring unsupported attribute: '%s'", attr.Name)
 		return nil
 	}
 
@@ -205,6 +260,34 @@ func writeProperty(buf *bytes.Buffer, prop *Property, qualifiedReceiver string,
 	}
 
 	switch prop.Name {
+	case "accessibleDescription":
+		buf.WriteString(fmt.Sprintf(
+			"if err := %s.Accessibility().SetDescription(%s); err != nil {\nreturn err\n}\n",
+			qualifiedReceiver, trString(&prop.String)))
+
+	case "accessibleName":
+		buf.WriteString(fmt.Sprintf(
+			"if err := %s.Accessibility().SetName(%s); err != nil {\nreturn err\n}\n",
+			qualifiedReceiver, trString(&prop.String)))
+
+	case "buddy", "sizePolicy", "widgetResizable":
+		// Handled by prepareUI or the layout.
+
+	case "currentIndex":
+		switch {
+		case widget != nil && widget.Class == "QComboBox":
+			buf.WriteString(fmt.Sprintf(
+				"if err := %s.SetCurrentIndex(%d); err != nil {\nreturn err\n}\n",
+				qualifiedReceiver, int(prop.Number)))
+
+		case widget != nil && widget.Class == "QStackedWidget":
+			// Handled in writeWidgetInitialization.
+
+		default:
+			warnf(prop.location, "Ignoring unsupported property: '%s'", prop.Name)
+			return nil
+		}
+
 	case "decimals":
 		buf.WriteString(fmt.Sprintf("if err := %s.SetDecimals(%d); err != nil {\nreturn err\n}\n", qualifiedReceiver, int(prop.Number)))
 
@@ -217,7 +300,7 @@ func writeProperty(buf *bytes.Buffer, prop *Property, qualifiedReceiver string,
 			buf.WriteString(fmt.Sprintf("%s.SetPasswordMode(true)\n", qualifiedReceiver))
 
 		default:
-			fmt.Printf("Ignoring unsupported echoMode: '%s'\n", prop.Enum)
+			warnf(prop.location, "Ignoring unsupported echoMode: '%s'", prop.Enum)
 			return nil
 		}
 
@@ -273,6 +356,29 @@ func writeProperty(buf *bytes.Buffer, prop *Property, qualifiedReceiver string,
 				qualifiedReceiver, prop.Rect.X, prop.Rect.Y, prop.Rect.Width, prop.Rect.Height))
 		}
 
+	case "displayFormat":
+		buf.WriteString(fmt.Sprintf(
+			"if err := %s.SetFormat(%q); err != nil {\nreturn err\n}\n",
+			qualifiedReceiver, dateFormatFromQt(prop.Text)))
+
+	case "icon":
+		if prop.image == "" {
+			return nil
+		}
+
+		buf.WriteString(fmt.Sprintf(
+			`if img, err := walk.Resources.Image(%q); err != nil {
+			return err
+			} else if err := %s.SetImage(img); err != nil {
+			return err
+			}
+			`,
+			prop.image, qualifiedReceiver))
+
+	case "maximum", "minimum":
+		// We do these in writeProperties, because they map to a single method
+		// call, that must precede setting the value.
+
 	case "maximumSize", "minimumSize":
 		// We do these two guys in writeProperties, because we want to map them
 		// to a single method call, if both are present.
@@ -280,20 +386,88 @@ func writeProperty(buf *bytes.Buffer, prop *Property, qualifiedReceiver string,
 	case "maxLength":
 		buf.WriteString(fmt.Sprintf("%s.SetMaxLength(%d)\n", qualifiedReceiver, int(prop.Number)))
 
+	case "pageStep":
+		buf.WriteString(fmt.Sprintf("%s.SetPageSize(%d)\n", qualifiedReceiver, int(prop.Number)))
+
+	case "pixmap":
+		if prop.image == "" {
+			return nil
+		}
+
+		buf.WriteString(fmt.Sprintf(
+			`if img, err := walk.Resources.Image(%q); err != nil {
+			return err
+			} else if err := %s.SetImage(img); err != nil {
+			return err
+			}
+			`,
+			prop.image, qualifiedReceiver))
+
 	case "readOnly":
 		buf.WriteString(fmt.Sprintf("%s.SetReadOnly(%t)\n", qualifiedReceiver, prop.Bool))
 
+	case "singleStep":
+		if widget != nil && widget.Class == "QSlider" {
+			buf.WriteString(fmt.Sprintf("%s.SetLineSize(%d)\n", qualifiedReceiver, int(prop.Number)))
+		} else {
+			buf.WriteString(fmt.Sprintf(
+				"if err := %s.SetIncrement(%s); err != nil {\nreturn err\n}\n",
+				qualifiedReceiver, floatLiteral(numberValue(prop))))
+		}
+
 	case "text":
 		buf.WriteString(fmt.Sprintf(
 			"if err := %s.SetText(%s); err != nil {\nreturn err\n}\n",
 			qualifiedReceiver, trString(&prop.String)))
 
+	case "tracking":
+		buf.WriteString(fmt.Sprintf("%s.SetTracking(%t)\n", qualifiedReceiver, prop.Bool))
+
+	case "value":
+		switch {
+		case widget != nil && (widget.Class == "QSlider" || widget.Class == "QProgressBar"):
+			buf.WriteString(fmt.Sprintf("%s.SetValue(%d)\n", qualifiedReceiver, int(numberValue(prop))))
+
+		case widget != nil && (widget.Class == "QSpinBox" || widget.Class == "QDoubleSpinBox"):
+			buf.WriteString(fmt.Sprintf(
+				"if err := %s.SetValue(%s); err != nil {\nreturn err\n}\n",
+				qualifiedReceiver, floatLiteral(numberValue(prop))))
+
+		default:
+			warnf(prop.location, "Ignoring unsupported property: '%s'", prop.Name)
+			return nil
+		}
+
+	case "windowIcon":
+		if prop.image == "" {
+			return nil
+		}
+
+		if qualifiedReceiver != "w" || widget.Class == "QWidget" {
+			warnf(prop.location, "Ignoring windowIcon, it is only supported for main windows and dialogs")
+			return nil
+		}
+
+		buf.WriteString(fmt.Sprintf(
+			`if icon, err := walk.Resources.Icon(%q); err != nil {
+			return err
+			} else if err := %s.SetIcon(icon); err != nil {
+			return err
+			}
+			`,
+			prop.image, qualifiedReceiver))
+
 	case "title", "windowTitle":
 		buf.WriteString(fmt.Sprintf(
 			"if err := %s.SetTitle(%s); err != nil {\nreturn err\n}\n",
 			qualifiedReceiver, trString(&prop.String)))
 
 	case "orientation":
+		if widget != nil && widget.Class == "QSlider" {
+			// Sliders get their orientation on creation.
+			return nil
+		}
+
 		var orientation string
 		switch prop.Enum {
 		case "Qt::Horizontal":
@@ -314,7 +488,7 @@ func writeProperty(buf *bytes.Buffer, prop *Property, qualifiedReceiver string,
 			qualifiedReceiver, orientation))
 
 	default:
-		fmt.Printf("Ignoring unsupported property: '%s'\n", prop.Name)
+		warnf(prop.location, "Ignoring unsupported property: '%s'", prop.Name)
 		return nil
 	}
 
@@ -325,6 +499,20 @@ func writeProperties(buf *bytes.Buffer, props []*Property, qualifiedReceiver str
 	var minSize, maxSize Size
 	var hasMinOrMaxSize bool
 
+	if widget != nil {
+		if min, max, ok := valueRange(widget); ok {
+			switch widget.Class {
+			case "QProgressBar", "QSlider":
+				buf.WriteString(fmt.Sprintf("%s.SetRange(%d, %d)\n", qualifiedReceiver, int(min), int(max)))
+
+			case "QDoubleSpinBox", "QSpinBox":
+				buf.WriteString(fmt.Sprintf(
+					"if err := %s.SetRange(%s, %s); err != nil {\nreturn err\n}\n",
+					qualifiedReceiver, floatLiteral(min), floatLiteral(max)))
+			}
+		}
+	}
+
 	for _, prop := range props {
 		if err := writeProperty(buf, prop, qualifiedReceiver, widget); err != nil {
 			return err
@@ -352,8 +540,8 @@ func writeProperties(buf *bytes.Buffer, props []*Property, qualifiedReceiver str
 	return nil
 }
 
-func writeItemInitializations(buf *bytes.Buffer, items []*Item, parent *Widget, qualifiedParent string, layout string) error {
-	for _, item := range items {
+func writeItemInitializations(buf *bytes.Buffer, items []*Item, parent *Widget, qualifiedParent string, layout string, boxLayout *Layout) error {
+	for i, item := range items {
 		var itemName string
 
 		if item.Spacer != nil {
@@ -415,6 +603,17 @@ func writeItemInitializations(buf *bytes.Buffer, items []*Item, parent *Widget,
 			if err := writeWidgetInitialization(buf, item.Widget, parent, qualifiedParent); err != nil {
 				return err
 			}
+
+			if boxLayout != nil {
+				if factor := boxStretchFactor(boxLayout, i, item.Widget); factor > 0 {
+					buf.WriteString(fmt.Sprintf(
+						`if err := %s.SetStretchFactor(%s, %d); err != nil {
+						return err
+						}
+						`,
+						boxLayout.Name, itemName, factor))
+				}
+			}
 		}
 
 		if layout != "" && itemName != "" && item.Row != "" && item.Column != "" {
@@ -430,16 +629,50 @@ func writeItemInitializations(buf *bytes.Buffer, items []*Item, parent *Widget,
 				}
 				`,
 				layout, itemName, item.Column, item.Row, item.ColSpan, item.RowSpan))
+
+			if item.Widget != nil && !item.Widget.ignored {
+				writeGridItemStretchFactors(buf, layout, item)
+			}
 		}
 	}
 
 	return nil
 }
 
+// writeGridItemStretchFactors makes the stretch of the size policy of the
+// widget of a grid item stretch its column and row. The rowstretch and
+// columnstretch attributes of the layout are applied later, so they take
+// precedence.
+func writeGridItemStretchFactors(buf *bytes.Buffer, layout string, item *Item) {
+	prop := findProperty(item.Widget.Property, "sizePolicy")
+	if prop == nil || prop.SizePolicy == nil {
+		return
+	}
+
+	if factor := prop.SizePolicy.HorStretch; factor > 0 {
+		buf.WriteString(fmt.Sprintf(
+			`if err := %s.SetColumnStretchFactor(%s, %d); err != nil {
+			return err
+			}
+			`,
+			layout, item.Column, factor))
+	}
+
+	if factor := prop.SizePolicy.VerStretch; factor > 0 {
+		buf.WriteString(fmt.Sprintf(
+			`if err := %s.SetRowStretchFactor(%s, %d); err != nil {
+			return err
+			}
+			`,
+			layout, item.Row, factor))
+	}
+}
+
 func writeLayoutInitialization(buf *bytes.Buffer, layout *Layout, parent *Widget, qualifiedParent string) error {
 	var typ string
 	switch layout.Class {
-	case "QGridLayout":
+	case "QFormLayout", "QGridLayout":
+		// A QFormLayout is just a grid with two columns.
 		typ = "GridLayout"
 
 	case "QHBoxLayout":
@@ -485,6 +718,9 @@ func writeLayoutInitialization(buf *bytes.Buffer, layout *Layout, parent *Widget
 		case "margin":
 			m := int(prop.Number)
 			margL, margT, margR, margB = m, m, m, m
+
+		default:
+			warnf(prop.location, "Ignoring unsupported layout property: '%s'", prop.Name)
 		}
 	}
 
@@ -507,14 +743,39 @@ func writeLayoutInitialization(buf *bytes.Buffer, layout *Layout, parent *Widget
 	}
 
 	var layoutName string
+	var boxLayout *Layout
 	if typ == "GridLayout" {
 		layoutName = layout.Name
+	} else {
+		boxLayout = layout
 	}
 
-	if err := writeItemInitializations(buf, layout.Item, parent, qualifiedParent, layoutName); err != nil {
+	if err := writeItemInitializations(buf, layout.Item, parent, qualifiedParent, layoutName, boxLayout); err != nil {
 		return err
 	}
 
+	for i, factor := range stretchFactors(layout.RowStretch) {
+		if factor > 0 {
+			buf.WriteString(fmt.Sprintf(
+				`if err := %s.SetRowStretchFactor(%d, %d); err != nil {
+				return err
+				}
+				`,
+				layout.Name, i, factor))
+		}
+	}
+
+	for i, factor := range stretchFactors(layout.ColumnStretch) {
+		if factor > 0 {
+			buf.WriteString(fmt.Sprintf(
+				`if err := %s.SetColumnStretchFactor(%d, %d); err != nil {
+				return err
+				}
+				`,
+				layout.Name, i, factor))
+		}
+	}
+
 	return nil
 }
 
@@ -530,24 +791,31 @@ func writeWidgetInitialization(buf *bytes.Buffer, widget *Widget, parent *Widget
 	case "QComboBox":
 		typ = "ComboBox"
 
-	case "QDateEdit":
+	case "QDateEdit", "QDateTimeEdit":
 		typ = "DateEdit"
 
 	case "QDoubleSpinBox", "QSpinBox":
 		typ = "NumberEdit"
 
-	case "QFrame":
+	case "QFrame", "QStackedWidget":
 		typ = "Composite"
 
 	case "QGroupBox":
 		typ = "GroupBox"
 
 	case "QLabel":
-		typ = "Label"
+		if isImageLabel(widget) {
+			typ = "ImageView"
+		} else {
+			typ = "Label"
+		}
 
 	case "QLineEdit":
 		typ = "LineEdit"
 
+	case "QListWidget":
+		typ = "ListBox"
+
 	case "QPlainTextEdit", "QTextEdit":
 		typ = "TextEdit"
 
@@ -560,6 +828,12 @@ func writeWidgetInitialization(buf *bytes.Buffer, widget *Widget, parent *Widget
 	case "QRadioButton":
 		typ = "RadioButton"
 
+	case "QScrollArea":
+		typ = "ScrollView"
+
+	case "QSlider":
+		typ = "Slider"
+
 	case "QSplitter":
 		typ = "Splitter"
 
@@ -611,6 +885,15 @@ func writeWidgetInitialization(buf *bytes.Buffer, widget *Widget, parent *Widget
 				}
 				`,
 				widget.Name, receiver))
+		} else if typ == "Slider" && isVertical(widget) {
+			buf.WriteString(fmt.Sprintf(
+				`
+				// %s
+				if %s, err = walk.NewSliderWithOrientation(%s, walk.Vertical); err != nil {
+				return err
+				}
+				`,
+				widget.Name, receiver, qualifiedParent))
 		} else {
 			buf.WriteString(fmt.Sprintf(
 				`
@@ -630,6 +913,12 @@ func writeWidgetInitialization(buf *bytes.Buffer, widget *Widget, parent *Widget
 		return err
 	}
 
+	if model := listItemsModel(widget); model != "" {
+		buf.WriteString(fmt.Sprintf(
+			"if err := %s.SetModel(%s); err != nil {\nreturn err\n}\n",
+			receiver, model))
+	}
+
 	if err := writeProperties(buf, widget.Property, receiver, widget); err != nil {
 		return err
 	}
@@ -649,7 +938,38 @@ func writeWidgetInitialization(buf *bytes.Buffer, widget *Widget, parent *Widget
 			qualifiedParent, receiver))
 	}
 
-	return writeWidgetInitializations(buf, widget.Widget, widget, receiver)
+	if widget.Class == "QStackedWidget" {
+		// There is no stacked widget in walk, so we stack the pages in a
+		// VBoxLayout and only show the current one.
+		buf.WriteString(fmt.Sprintf(
+			`if err := %s.SetLayout(walk.NewVBoxLayout()); err != nil {
+			return err
+			}
+			if err := %s.Layout().SetMargins(walk.Margins{}); err != nil {
+			return err
+			}
+			`,
+			receiver, receiver))
+	}
+
+	if err := writeWidgetInitializations(buf, widget.Widget, widget, receiver); err != nil {
+		return err
+	}
+
+	if widget.Class == "QStackedWidget" {
+		var currentIndex int
+		if prop := findProperty(widget.Property, "currentIndex"); prop != nil {
+			currentIndex = int(prop.Number)
+		}
+
+		for i, page := range widget.Widget {
+			if i != currentIndex && !page.ignored {
+				buf.WriteString(fmt.Sprintf("w.ui.%s.SetVisible(false)\n", page.Name))
+			}
+		}
+	}
+
+	return nil
 }
 
 func writeWidgetInitializations(buf *bytes.Buffer, widgets []*Widget, parent *Widget, qualifiedParent string) error {
@@ -667,6 +987,10 @@ func writeWidgetInitializations(buf *bytes.Buffer, widgets []*Widget, parent *Wi
 }
 
 func writeWidgetDecl(buf *bytes.Buffer, widget *Widget, parent *Widget) error {
+	if widget.ignored {
+		return nil
+	}
+
 	var typ string
 	switch widget.Class {
 	case "QCheckBox":
@@ -675,24 +999,31 @@ func writeWidgetDecl(buf *bytes.Buffer, widget *Widget, parent *Widget) error {
 	case "QComboBox":
 		typ = "walk.ComboBox"
 
-	case "QDateEdit":
+	case "QDateEdit", "QDateTimeEdit":
 		typ = "walk.DateEdit"
 
 	case "QDoubleSpinBox", "QSpinBox":
 		typ = "walk.NumberEdit"
 
-	case "QFrame":
+	case "QFrame", "QStackedWidget":
 		typ = "walk.Composite"
 
 	case "QGroupBox":
 		typ = "walk.GroupBox"
 
 	case "QLabel":
-		typ = "walk.Label"
+		if isImageLabel(widget) {
+			typ = "walk.ImageView"
+		} else {
+			typ = "walk.Label"
+		}
 
 	case "QLineEdit":
 		typ = "walk.LineEdit"
 
+	case "QListWidget":
+		typ = "walk.ListBox"
+
 	case "QPlainTextEdit", "QTextEdit":
 		typ = "walk.TextEdit"
 
@@ -705,6 +1036,12 @@ func writeWidgetDecl(buf *bytes.Buffer, widget *Widget, parent *Widget) error {
 	case "QRadioButton":
 		typ = "walk.RadioButton"
 
+	case "QScrollArea":
+		typ = "walk.ScrollView"
+
+	case "QSlider":
+		typ = "walk.Slider"
+
 	case "QSplitter":
 		typ = "walk.Splitter"
 
@@ -737,8 +1074,10 @@ func writeWidgetDecl(buf *bytes.Buffer, widget *Widget, parent *Widget) error {
 
 	buf.WriteString(fmt.Sprintf("%s *%s\n", widget.Name, typ))
 
-	if widget.Layout != nil {
-		return writeItemDecls(buf, widget.Layout.Item, widget)
+	if widget.Layout != nil && !widget.Layout.ignored {
+		if err := writeItemDecls(buf, widget.Layout.Item, widget); err != nil {
+			return err
+		}
 	}
 
 	return writeWidgetDecls(buf, widget.Widget, widget)
@@ -944,7 +1283,7 @@ func generateUICode(buf *bytes.Buffer, ui *UI) error {
 		}
 	}
 
-	if ui.Widget.Layout != nil {
+	if ui.Widget.Layout != nil && !ui.Widget.Layout.ignored {
 		if err := writeItemDecls(buf, ui.Widget.Layout.Item, &ui.Widget); err != nil {
 			return err
 		}
@@ -1039,7 +1378,7 @@ func generateUICode(buf *bytes.Buffer, ui *UI) error {
 		}
 	}
 
-	if ui.Widget.Layout != nil {
+	if ui.Widget.Layout != nil && !ui.Widget.Layout.ignored {
 		if err := writeLayoutInitialization(buf, ui.Widget.Layout, &ui.Widget, "w"); err != nil {
 			return err
 		}
@@ -1051,7 +1390,7 @@ func generateUICode(buf *bytes.Buffer, ui *UI) error {
 			return err
 		}
 		`,
-			ui.TabStops[i]))
+			ui.TabStops[i].Name))
 	}
 
 	// end func
@@ -1264,13 +1603,22 @@ func processFile(uiFilePath string) error {
 		return err
 	}
 
+	if err := prepareUI(ui, filepath.Dir(uiFilePath)); err != nil {
+		return err
+	}
+
+	generateLogic, generateUI := generateLogicCode, generateUICode
+	if *declarativeMode {
+		generateLogic, generateUI = generateDeclarativeLogicCode, generateDeclarativeUICode
+	}
+
 	goLogicFile, err := os.OpenFile(goLogicFilePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
 	if err == nil {
 		defer goLogicFile.Close()
 
 		buf := new(bytes.Buffer)
 
-		if err := generateLogicCode(buf, ui); err != nil {
+		if err := generateLogic(buf, ui); err != nil {
 			return err
 		}
 
@@ -1290,7 +1638,7 @@ func processFile(uiFilePath string) error {
 
 	buf := new(bytes.Buffer)
 
-	if err := generateUICode(buf, ui); err != nil {
+	if err := generateUI(buf, ui); err != nil {
 		return err
 	}
 
@@ -65,6 +65,8 @@ func NewTreeView(parent Container) (*TreeView, error) {
 		return nil, err
 	}
 
+	tv.ApplySysColors()
+
 	tv.GraphicsEffects().Add(InteractionEffect)
 	tv.GraphicsEffects().Add(FocusEffect)
 
@@ -108,7 +110,7 @@ func (tv *TreeView) Dispose() {
 func (tv *TreeView) SetBackground(bg Brush) {
 	tv.WidgetBase.SetBackground(bg)
 
-	color := Color(win.GetSysColor(win.COLOR_WINDOW))
+	color := App().Theme().Color(ThemeColorWindow)
 
 	if bg != nil {
 		type Colorer interface {
@@ -240,6 +242,81 @@ func (tv *TreeView) EnsureVisible(item TreeItem) error {
 	return nil
 }
 
+// Find makes the next item after the current one current whose text
+// matches text, or the previous item if opts.Backward is set. Items are
+// searched in the order they appear in the tree, expanded or not, and the
+// search wraps around. It returns whether there was a match.
+//
+// With a model that prefers lazy population, the children of items that
+// were never expanded are not searched, so that searching does not load
+// the whole model.
+func (tv *TreeView) Find(text string, opts FindOptions) bool {
+	re, _ := FindRegexp(text, opts)
+	if re == nil || tv.model == nil {
+		return false
+	}
+
+	items := tv.searchableItems()
+	count := len(items)
+
+	pos := -1
+	for i, item := range items {
+		if item == tv.currItem {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 && opts.Backward {
+		pos = 0
+	}
+
+	for i := 0; i < count; i++ {
+		if opts.Backward {
+			pos = (pos + count - 1) % count
+		} else {
+			pos = (pos + 1) % count
+		}
+
+		if item := items[pos]; re.MatchString(item.Text()) {
+			if err := tv.SetCurrentItem(item); err != nil {
+				return false
+			}
+			tv.EnsureVisible(item)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// searchableItems returns the items of the model that Find searches, in
+// the order they appear in the tree.
+func (tv *TreeView) searchableItems() []TreeItem {
+	var items []TreeItem
+
+	var add func(item TreeItem)
+	add = func(item TreeItem) {
+		items = append(items, item)
+
+		if tv.lazyPopulation {
+			if info := tv.item2Info[item]; info == nil || len(info.child2Handle) == 0 {
+				return
+			}
+		}
+
+		for i, n := 0, item.ChildCount(); i < n; i++ {
+			add(item.ChildAt(i))
+		}
+	}
+
+	for i, n := 0, tv.model.RootCount(); i < n; i++ {
+		add(tv.model.RootAt(i))
+	}
+
+	return items
+}
+
 func (tv *TreeView) handleForItem(item TreeItem) (win.HTREEITEM, error) {
 	if item != nil {
 		if info := tv.item2Info[item]; info == nil {
@@ -315,6 +392,25 @@ func (tv *TreeView) insertRoots() error {
 	return nil
 }
 
+func (tv *TreeView) ApplySysColors() {
+	tv.WidgetBase.ApplySysColors()
+
+	// -1 means the system default.
+	bg, text := -1, -1
+
+	if theme := App().Theme(); theme.ProvidesColor(ThemeColorWindow) {
+		bg = int(theme.Color(ThemeColorWindow))
+		text = int(theme.Color(ThemeColorWindowText))
+	}
+
+	if c, ok := tv.Background().(interface{ Color() Color }); ok {
+		bg = int(c.Color())
+	}
+
+	tv.SendMessage(win.TVM_SETBKCOLOR, 0, uintptr(bg))
+	tv.SendMessage(win.TVM_SETTEXTCOLOR, 0, uintptr(text))
+}
+
 func (tv *TreeView) ApplyDPI(dpi int) {
 	tv.WidgetBase.ApplyDPI(dpi)
 
@@ -322,6 +322,13 @@ func walkDescendants(window Window, f func(w Window) bool) {
 		return
 	}
 
+	for _, wb := range childWidgets(window) {
+		walkDescendants(wb.window.(Widget), f)
+	}
+}
+
+// childWidgets returns the widgets walkDescendants descends into from window.
+func childWidgets(window Window) []*WidgetBase {
 	var children []*WidgetBase
 
 	switch w := window.(type) {
@@ -338,14 +345,10 @@ func walkDescendants(window Window, f func(w Window) bool) {
 	case Container:
 		if c := w.Children(); c != nil {
 			children = c.items
-		} else {
-			children = nil
 		}
 	}
 
-	for _, wb := range children {
-		walkDescendants(wb.window.(Widget), f)
-	}
+	return children
 }
 
 func less(a, b interface{}, order SortOrder) bool {
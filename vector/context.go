@@ -0,0 +1,272 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vector implements anti-aliased 2D drawing into images, with
+// paths, fill rules, strokes with joins, caps and dashes, affine transforms,
+// alpha blending and clipping.
+//
+// The rasterizer is pure Go, so drawing code can be tested on any platform.
+// walk.NewBitmapFromVectorDrawing and walk.Bitmap.DrawVector draw into
+// bitmaps that widgets can show.
+package vector
+
+import (
+	"image"
+	"image/color"
+)
+
+// tolerance is how far flattened curves may deviate from the real ones, in
+// device pixels.
+const tolerance = 0.1
+
+type state struct {
+	transform   Matrix
+	fillColor   color.Color
+	strokeColor color.Color
+	stroke      strokeStyle
+	fillRule    FillRule
+	alpha       float64
+	clip        []float32 // Coverage per pixel, or nil. Never modified in place.
+}
+
+// Context draws into an *image.RGBA.
+//
+// Coordinates are in user space, which the transform maps to the pixels of
+// the image. Initially user space is the pixel space of the image, with the
+// origin at the top left corner of its bounds.
+type Context struct {
+	dst   *image.RGBA
+	state state
+	saved []state
+	r     rasterizer
+}
+
+// NewContext returns a *Context that draws into dst.
+func NewContext(dst *image.RGBA) *Context {
+	return &Context{
+		dst: dst,
+		state: state{
+			transform:   Identity(),
+			fillColor:   color.Black,
+			strokeColor: color.Black,
+			stroke: strokeStyle{
+				width:      1,
+				miterLimit: 10,
+			},
+			alpha: 1,
+		},
+	}
+}
+
+// Image returns the image the *Context draws into.
+func (c *Context) Image() *image.RGBA {
+	return c.dst
+}
+
+// Save pushes the current transform, colors, stroke style, fill rule, alpha
+// and clip onto a stack.
+func (c *Context) Save() {
+	c.saved = append(c.saved, c.state)
+}
+
+// Restore pops the state that the last call to Save pushed. It does nothing
+// if the stack is empty.
+func (c *Context) Restore() {
+	if len(c.saved) == 0 {
+		return
+	}
+
+	c.state = c.saved[len(c.saved)-1]
+	c.saved = c.saved[:len(c.saved)-1]
+}
+
+// Transform returns the Matrix that maps user space to pixels.
+func (c *Context) Transform() Matrix {
+	return c.state.transform
+}
+
+// SetTransform sets the Matrix that maps user space to pixels.
+func (c *Context) SetTransform(m Matrix) {
+	c.state.transform = m
+}
+
+// Translate moves user space by tx, ty.
+func (c *Context) Translate(tx, ty float64) {
+	c.state.transform = c.state.transform.Translate(tx, ty)
+}
+
+// Scale scales user space by sx, sy.
+func (c *Context) Scale(sx, sy float64) {
+	c.state.transform = c.state.transform.Scale(sx, sy)
+}
+
+// Rotate rotates user space by angle radians, clockwise.
+func (c *Context) Rotate(angle float64) {
+	c.state.transform = c.state.transform.Rotate(angle)
+}
+
+// SetFillColor sets the color that Fill paints with. Its alpha is respected.
+func (c *Context) SetFillColor(col color.Color) {
+	c.state.fillColor = col
+}
+
+// SetStrokeColor sets the color that Stroke paints with. Its alpha is
+// respected.
+func (c *Context) SetStrokeColor(col color.Color) {
+	c.state.strokeColor = col
+}
+
+// SetAlpha sets an opacity from 0 to 1, that applies to everything drawn.
+func (c *Context) SetAlpha(alpha float64) {
+	c.state.alpha = clamp(alpha, 0, 1)
+}
+
+// SetFillRule sets the rule that Fill and Clip use.
+func (c *Context) SetFillRule(rule FillRule) {
+	c.state.fillRule = rule
+}
+
+// SetLineWidth sets the width of strokes, in user space.
+func (c *Context) SetLineWidth(width float64) {
+	c.state.stroke.width = width
+}
+
+// SetLineJoin sets how the segments of strokes meet.
+func (c *Context) SetLineJoin(join Join) {
+	c.state.stroke.join = join
+}
+
+// SetLineCap sets how the ends of strokes look.
+func (c *Context) SetLineCap(cap Cap) {
+	c.state.stroke.cap = cap
+}
+
+// SetMiterLimit sets the ratio of miter length to line width, above which
+// miter joins are beveled instead.
+func (c *Context) SetMiterLimit(limit float64) {
+	c.state.stroke.miterLimit = limit
+}
+
+// SetDash sets the pattern of dashed strokes. dashes alternate between the
+// lengths of dashes and gaps, starting offset into the pattern. Pass no
+// dashes for solid strokes.
+func (c *Context) SetDash(offset float64, dashes ...float64) {
+	c.state.stroke.dashOffset = offset
+	c.state.stroke.dashes = append([]float64(nil), dashes...)
+}
+
+// Fill paints the inside of p with the fill color.
+func (c *Context) Fill(p *Path) {
+	c.rasterizeFill(p)
+
+	c.paint(c.state.fillRule, c.state.fillColor)
+}
+
+// Stroke paints the outline of p with the stroke color.
+func (c *Context) Stroke(p *Path) {
+	m := c.state.transform
+
+	scale := m.scaleFactor()
+	if scale == 0 {
+		return
+	}
+
+	// Strokes are built in user space, so their width transforms like the
+	// path itself.
+	lines := p.flatten(Identity(), tolerance/scale)
+	polygons := strokePolygons(lines, c.state.stroke, tolerance/scale)
+
+	c.resetRasterizer()
+	for _, polygon := range polygons {
+		for i, pt := range polygon {
+			polygon[i] = m.Apply(pt)
+		}
+
+		c.r.polygon(polygon)
+	}
+
+	c.paint(NonZero, c.state.strokeColor)
+}
+
+// Clip restricts drawing to the inside of p, in addition to the current clip.
+func (c *Context) Clip(p *Path) {
+	c.rasterizeFill(p)
+
+	cov, minY, maxY := c.r.coverage(c.state.fillRule)
+
+	w := c.dst.Rect.Dx()
+	clip := make([]float32, len(cov))
+	for i := minY * w; i < maxY*w; i++ {
+		clip[i] = cov[i]
+		if c.state.clip != nil {
+			clip[i] *= c.state.clip[i]
+		}
+	}
+
+	c.state.clip = clip
+}
+
+// ResetClip removes the clip, so the whole image can be drawn to.
+func (c *Context) ResetClip() {
+	c.state.clip = nil
+}
+
+func (c *Context) resetRasterizer() {
+	c.r.reset(c.dst.Rect.Dx(), c.dst.Rect.Dy())
+}
+
+func (c *Context) rasterizeFill(p *Path) {
+	c.resetRasterizer()
+
+	for _, l := range p.flatten(c.state.transform, tolerance) {
+		c.r.polygon(l.points)
+	}
+}
+
+// paint blends col into the image, weighted by the coverage of the
+// rasterized shape, the clip and the alpha.
+func (c *Context) paint(rule FillRule, col color.Color) {
+	cov, minY, maxY := c.r.coverage(rule)
+
+	if col == nil || c.state.alpha == 0 {
+		return
+	}
+
+	// Premultiplied, from 0 to 0xffff.
+	r, g, b, a := col.RGBA()
+	if a == 0 {
+		return
+	}
+
+	dst := c.dst
+	w := dst.Rect.Dx()
+	clip := c.state.clip
+	alpha := float32(c.state.alpha)
+
+	for y := minY; y < maxY; y++ {
+		pix := dst.Pix[y*dst.Stride : y*dst.Stride+w*4]
+
+		for x := 0; x < w; x++ {
+			i := y*w + x
+
+			f := cov[i] * alpha
+			if clip != nil {
+				f *= clip[i]
+			}
+			if f <= 0 {
+				continue
+			}
+
+			m := uint32(f * 0xffff)
+			sr, sg, sb, sa := r*m/0xffff, g*m/0xffff, b*m/0xffff, a*m/0xffff
+			inv := 0xffff - sa
+
+			p := pix[x*4 : x*4+4 : x*4+4]
+			p[0] = uint8((sr + uint32(p[0])*0x101*inv/0xffff) >> 8)
+			p[1] = uint8((sg + uint32(p[1])*0x101*inv/0xffff) >> 8)
+			p[2] = uint8((sb + uint32(p[2])*0x101*inv/0xffff) >> 8)
+			p[3] = uint8((sa + uint32(p[3])*0x101*inv/0xffff) >> 8)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func newTestContext() *Context {
+	return NewContext(image.NewRGBA(image.Rect(0, 0, 64, 64)))
+}
+
+// area returns the painted area in pixels, i.e. the sum of the coverage of
+// all pixels, in the rectangle r.
+func area(img *image.RGBA, r image.Rectangle) float64 {
+	var sum float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			sum += float64(img.RGBAAt(x, y).A) / 255
+		}
+	}
+
+	return sum
+}
+
+func totalArea(c *Context) float64 {
+	return area(c.Image(), c.Image().Bounds())
+}
+
+func coverageAt(c *Context, x, y int) float64 {
+	return float64(c.Image().RGBAAt(x, y).A) / 255
+}
+
+func checkArea(t *testing.T, what string, got, want, tolerance float64) {
+	t.Helper()
+
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s: got area %.3f, want %.3f ± %.3f", what, got, want, tolerance)
+	}
+}
+
+func TestFillRect(t *testing.T) {
+	c := newTestContext()
+
+	var p Path
+	p.Rect(10, 10, 20, 10)
+	c.Fill(&p)
+
+	checkArea(t, "pixel aligned", totalArea(c), 200, 0.5)
+	if coverageAt(c, 10, 10) != 1 || coverageAt(c, 29, 19) != 1 {
+		t.Error("inside pixels are not fully covered")
+	}
+	if coverageAt(c, 9, 10) != 0 || coverageAt(c, 30, 19) != 0 {
+		t.Error("outside pixels are covered")
+	}
+
+	c = newTestContext()
+	p.Reset()
+	p.Rect(10.5, 10, 5, 1)
+	c.Fill(&p)
+
+	checkArea(t, "half pixel offset", totalArea(c), 5, 0.05)
+	if got := coverageAt(c, 10, 10); math.Abs(got-0.5) > 0.01 {
+		t.Errorf("got edge coverage %.3f, want 0.5", got)
+	}
+}
+
+func TestFillCircle(t *testing.T) {
+	c := newTestContext()
+
+	var p Path
+	p.Circle(32, 32, 20)
+	c.Fill(&p)
+
+	checkArea(t, "circle", totalArea(c), math.Pi*400, math.Pi*400*0.005)
+}
+
+func TestFillRules(t *testing.T) {
+	var p Path
+	p.Rect(10, 10, 40, 40)
+	p.Rect(20, 20, 20, 20)
+
+	c := newTestContext()
+	c.Fill(&p)
+	checkArea(t, "NonZero", totalArea(c), 1600, 1)
+
+	c = newTestContext()
+	c.SetFillRule(EvenOdd)
+	c.Fill(&p)
+	checkArea(t, "EvenOdd", totalArea(c), 1600-400, 1)
+	if coverageAt(c, 30, 30) != 0 {
+		t.Error("EvenOdd filled the hole")
+	}
+
+	// An inner rectangle in the opposite direction cuts a hole with either
+	// rule.
+	p.Reset()
+	p.Rect(10, 10, 40, 40)
+	p.Rect(40, 20, -20, 20)
+
+	c = newTestContext()
+	c.Fill(&p)
+	checkArea(t, "NonZero with reversed hole", totalArea(c), 1200, 1)
+}
+
+func strokeLine(configure func(c *Context), x0, y0, x1, y1 float64) *Context {
+	c := newTestContext()
+	configure(c)
+
+	var p Path
+	p.MoveTo(x0, y0)
+	p.LineTo(x1, y1)
+	c.Stroke(&p)
+
+	return c
+}
+
+func TestStrokeCaps(t *testing.T) {
+	// Round caps are polygons within the flattening tolerance of the circle.
+	tests := []struct {
+		cap             Cap
+		want, tolerance float64
+	}{
+		{ButtCap, 20 * 4, 0.5},
+		{SquareCap, 20*4 + 2*2*4, 0.5},
+		{RoundCap, 20*4 + math.Pi*2*2, 2 * math.Pi * 2 * tolerance},
+	}
+
+	for _, test := range tests {
+		c := strokeLine(func(c *Context) {
+			c.SetLineWidth(4)
+			c.SetLineCap(test.cap)
+		}, 20, 30, 40, 30)
+
+		checkArea(t, "cap", totalArea(c), test.want, test.tolerance)
+	}
+}
+
+func TestStrokeJoins(t *testing.T) {
+	areas := make(map[Join]float64)
+
+	for _, join := range []Join{MiterJoin, RoundJoin, BevelJoin} {
+		c := newTestContext()
+		c.SetLineWidth(4)
+		c.SetLineJoin(join)
+
+		var p Path
+		p.MoveTo(10, 30)
+		p.LineTo(30, 30)
+		p.LineTo(30, 50)
+		c.Stroke(&p)
+
+		areas[join] = totalArea(c)
+	}
+
+	// Two 20x4 legs overlap in a 2x2 square, the outer corner is a 2x2
+	// square for miters, a quarter circle for round joins and a triangle for
+	// bevels.
+	legs := 2*20*4 - 2*2.0
+	checkArea(t, "miter", areas[MiterJoin], legs+4, 0.5)
+	checkArea(t, "round", areas[RoundJoin], legs+math.Pi, 0.5)
+	checkArea(t, "bevel", areas[BevelJoin], legs+2, 0.5)
+
+	// A sharp angle exceeds the miter limit and is beveled.
+	limited := make(map[float64]float64)
+	for _, limit := range []float64{10, 1} {
+		c := newTestContext()
+		c.SetLineWidth(4)
+		c.SetMiterLimit(limit)
+
+		var p Path
+		p.MoveTo(10, 10)
+		p.LineTo(50, 20)
+		p.LineTo(10, 30)
+		c.Stroke(&p)
+
+		limited[limit] = totalArea(c)
+	}
+	if limited[1] >= limited[10]-1 {
+		t.Errorf("miter limit: got area %.3f, want less than %.3f", limited[1], limited[10])
+	}
+}
+
+func TestStrokeDash(t *testing.T) {
+	c := strokeLine(func(c *Context) {
+		c.SetLineWidth(2)
+		c.SetDash(0, 5, 5)
+	}, 10, 30, 50, 30)
+
+	checkArea(t, "dashed", totalArea(c), 4*5*2, 0.5)
+	if coverageAt(c, 12, 30) != 1 || coverageAt(c, 17, 30) != 0 {
+		t.Error("dash pattern doesn't start with a dash")
+	}
+
+	c = strokeLine(func(c *Context) {
+		c.SetLineWidth(2)
+		c.SetDash(5, 5, 5)
+	}, 10, 30, 50, 30)
+
+	if coverageAt(c, 12, 30) != 0 || coverageAt(c, 17, 30) != 1 {
+		t.Error("dash offset is not applied")
+	}
+
+	// Removing the dashes strokes solid lines again.
+	c = strokeLine(func(c *Context) {
+		c.SetLineWidth(2)
+		c.SetDash(0, 5, 5)
+		c.SetDash(0)
+	}, 10, 30, 50, 30)
+
+	checkArea(t, "solid", totalArea(c), 40*2, 0.5)
+}
+
+func TestClip(t *testing.T) {
+	c := newTestContext()
+
+	var clip Path
+	clip.Rect(0, 0, 30, 64)
+
+	c.Save()
+	c.Clip(&clip)
+
+	var clip2 Path
+	clip2.Rect(0, 0, 64, 30)
+	c.Clip(&clip2)
+
+	var p Path
+	p.Rect(10, 10, 40, 40)
+	c.Fill(&p)
+
+	checkArea(t, "clipped twice", totalArea(c), 20*20, 0.5)
+	if coverageAt(c, 35, 20) != 0 || coverageAt(c, 20, 35) != 0 {
+		t.Error("painted outside of the clip")
+	}
+
+	c.Restore()
+
+	c.SetFillColor(color.Black)
+	p.Reset()
+	p.Rect(40, 40, 10, 10)
+	c.Fill(&p)
+
+	if coverageAt(c, 45, 45) != 1 {
+		t.Error("clip still applies after Restore")
+	}
+
+	c.Clip(&clip)
+	c.ResetClip()
+	p.Reset()
+	p.Rect(50, 0, 10, 10)
+	c.Fill(&p)
+
+	if coverageAt(c, 55, 5) != 1 {
+		t.Error("clip still applies after ResetClip")
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	c := newTestContext()
+	c.Translate(10, 20)
+	c.Scale(2, 3)
+
+	var p Path
+	p.Rect(0, 0, 5, 5)
+	c.Fill(&p)
+
+	checkArea(t, "scaled", totalArea(c), 10*15, 0.5)
+	if coverageAt(c, 10, 20) != 1 || coverageAt(c, 19, 34) != 1 || coverageAt(c, 20, 35) != 0 {
+		t.Error("scaled rectangle is misplaced")
+	}
+
+	c = newTestContext()
+	c.Translate(32, 32)
+	c.Rotate(math.Pi / 4)
+	p.Reset()
+	p.Rect(-10, -10, 20, 20)
+	c.Fill(&p)
+
+	checkArea(t, "rotated", totalArea(c), 400, 0.5)
+	if coverageAt(c, 32, 32-13) != 1 || coverageAt(c, 32+10, 32-10) != 0 {
+		t.Error("rotated rectangle is misplaced")
+	}
+
+	// Strokes are transformed like the path.
+	c = strokeLine(func(c *Context) {
+		c.Scale(3, 3)
+		c.SetLineWidth(1)
+	}, 5, 10, 15, 10)
+
+	checkArea(t, "scaled stroke", totalArea(c), 30*3, 0.5)
+
+	// Degenerate transforms draw nothing.
+	c = strokeLine(func(c *Context) {
+		c.SetTransform(Scaling(0, 0))
+	}, 5, 10, 15, 10)
+
+	checkArea(t, "degenerate stroke", totalArea(c), 0, 0)
+}
+
+func TestSaveRestore(t *testing.T) {
+	c := newTestContext()
+
+	c.Restore()
+
+	c.Save()
+	c.Translate(10, 10)
+	c.SetLineWidth(5)
+	c.SetFillRule(EvenOdd)
+	c.Restore()
+
+	if c.Transform() != Identity() || c.state.stroke.width != 1 || c.state.fillRule != NonZero {
+		t.Error("Restore did not restore the state")
+	}
+}
+
+func TestAlphaBlending(t *testing.T) {
+	c := newTestContext()
+
+	var p Path
+	p.Rect(0, 0, 10, 10)
+
+	c.SetFillColor(color.RGBA{0, 0, 255, 255})
+	c.Fill(&p)
+
+	c.SetAlpha(0.5)
+	c.SetFillColor(color.RGBA{255, 0, 0, 255})
+	c.Fill(&p)
+
+	got := c.Image().RGBAAt(5, 5)
+	if !near(got.R, 128) || got.G != 0 || !near(got.B, 127) || got.A != 255 {
+		t.Errorf("got %v, want half red over blue", got)
+	}
+
+	// Premultiplied colors with alpha blend like SetAlpha.
+	c = newTestContext()
+	c.SetFillColor(color.RGBA{0, 64, 0, 128})
+	c.Fill(&p)
+
+	got = c.Image().RGBAAt(5, 5)
+	if got.R != 0 || !near(got.G, 64) || got.B != 0 || !near(got.A, 128) {
+		t.Errorf("got %v, want half transparent green", got)
+	}
+
+	c.SetAlpha(0)
+	c.SetFillColor(color.White)
+	c.Fill(&p)
+
+	if c.Image().RGBAAt(5, 5) != got {
+		t.Error("painted with alpha 0")
+	}
+}
+
+func near(got, want uint8) bool {
+	return got+1 >= want && got <= want+1
+}
+
+func TestMatrixInvert(t *testing.T) {
+	m := Identity().Translate(10, -5).Rotate(0.3).Scale(2, 0.5)
+
+	inv, ok := m.Invert()
+	if !ok {
+		t.Fatal("matrix is not invertible")
+	}
+
+	p := Point{3, 7}
+	if q := inv.Apply(m.Apply(p)); math.Abs(q.X-p.X) > 1e-9 || math.Abs(q.Y-p.Y) > 1e-9 {
+		t.Errorf("got %v, want %v", q, p)
+	}
+
+	if _, ok := Scaling(0, 1).Invert(); ok {
+		t.Error("singular matrix was inverted")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+)
+
+// Point is a point in user or device space.
+type Point struct {
+	X, Y float64
+}
+
+// Matrix is an affine transformation that maps x, y to
+//
+//	x' = A*x + C*y + E
+//	y' = B*x + D*y + F
+type Matrix struct {
+	A, B, C, D, E, F float64
+}
+
+// Identity returns the Matrix that leaves points unchanged.
+func Identity() Matrix {
+	return Matrix{A: 1, D: 1}
+}
+
+// Translation returns a Matrix that moves points by tx, ty.
+func Translation(tx, ty float64) Matrix {
+	return Matrix{A: 1, D: 1, E: tx, F: ty}
+}
+
+// Scaling returns a Matrix that scales points by sx, sy around the origin.
+func Scaling(sx, sy float64) Matrix {
+	return Matrix{A: sx, D: sy}
+}
+
+// Rotation returns a Matrix that rotates points by angle radians around the
+// origin. With y growing downwards, positive angles rotate clockwise.
+func Rotation(angle float64) Matrix {
+	sin, cos := math.Sincos(angle)
+
+	return Matrix{A: cos, B: sin, C: -sin, D: cos}
+}
+
+// Multiply returns the Matrix that applies n first, then m.
+func (m Matrix) Multiply(n Matrix) Matrix {
+	return Matrix{
+		A: m.A*n.A + m.C*n.B,
+		B: m.B*n.A + m.D*n.B,
+		C: m.A*n.C + m.C*n.D,
+		D: m.B*n.C + m.D*n.D,
+		E: m.A*n.E + m.C*n.F + m.E,
+		F: m.B*n.E + m.D*n.F + m.F,
+	}
+}
+
+// Translate returns m with a translation applied before it.
+func (m Matrix) Translate(tx, ty float64) Matrix {
+	return m.Multiply(Translation(tx, ty))
+}
+
+// Scale returns m with a scaling applied before it.
+func (m Matrix) Scale(sx, sy float64) Matrix {
+	return m.Multiply(Scaling(sx, sy))
+}
+
+// Rotate returns m with a rotation applied before it.
+func (m Matrix) Rotate(angle float64) Matrix {
+	return m.Multiply(Rotation(angle))
+}
+
+// Apply returns p transformed by m.
+func (m Matrix) Apply(p Point) Point {
+	return Point{
+		m.A*p.X + m.C*p.Y + m.E,
+		m.B*p.X + m.D*p.Y + m.F,
+	}
+}
+
+// Invert returns the inverse of m. ok is false if m can't be inverted.
+func (m Matrix) Invert() (inv Matrix, ok bool) {
+	det := m.A*m.D - m.B*m.C
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Matrix{}, false
+	}
+
+	return Matrix{
+		A: m.D / det,
+		B: -m.B / det,
+		C: -m.C / det,
+		D: m.A / det,
+		E: (m.C*m.F - m.D*m.E) / det,
+		F: (m.B*m.E - m.A*m.F) / det,
+	}, true
+}
+
+// scaleFactor returns how much m stretches distances at most, roughly.
+func (m Matrix) scaleFactor() float64 {
+	return math.Sqrt(math.Max(m.A*m.A+m.B*m.B, m.C*m.C+m.D*m.D))
+}
@@ -0,0 +1,296 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+)
+
+type opKind int
+
+const (
+	opMove opKind = iota
+	opLine
+	opQuad
+	opCube
+	opClose
+)
+
+type op struct {
+	kind opKind
+	p    [3]Point
+}
+
+// Path is a shape made of subpaths of lines and Bézier curves. The zero
+// value is an empty Path.
+type Path struct {
+	ops     []op
+	start   Point
+	current Point
+	open    bool
+}
+
+// Reset removes all subpaths.
+func (p *Path) Reset() {
+	p.ops = p.ops[:0]
+	p.open = false
+}
+
+// Empty returns if the Path has no subpaths.
+func (p *Path) Empty() bool {
+	return len(p.ops) == 0
+}
+
+// CurrentPoint returns where the next segment starts. ok is false if no
+// subpath was started.
+func (p *Path) CurrentPoint() (x, y float64, ok bool) {
+	return p.current.X, p.current.Y, p.open
+}
+
+// MoveTo starts a new subpath at x, y.
+func (p *Path) MoveTo(x, y float64) {
+	pt := Point{x, y}
+
+	p.ops = append(p.ops, op{kind: opMove, p: [3]Point{pt}})
+	p.start = pt
+	p.current = pt
+	p.open = true
+}
+
+// LineTo adds a line from the current point to x, y. It starts a subpath at
+// x, y if there is none.
+func (p *Path) LineTo(x, y float64) {
+	if !p.open {
+		p.MoveTo(x, y)
+		return
+	}
+
+	pt := Point{x, y}
+
+	p.ops = append(p.ops, op{kind: opLine, p: [3]Point{pt}})
+	p.current = pt
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to x, y with
+// the control point cx, cy.
+func (p *Path) QuadTo(cx, cy, x, y float64) {
+	if !p.open {
+		p.MoveTo(cx, cy)
+	}
+
+	pt := Point{x, y}
+
+	p.ops = append(p.ops, op{kind: opQuad, p: [3]Point{{cx, cy}, pt}})
+	p.current = pt
+}
+
+// CubeTo adds a cubic Bézier curve from the current point to x, y with the
+// control points c1x, c1y and c2x, c2y.
+func (p *Path) CubeTo(c1x, c1y, c2x, c2y, x, y float64) {
+	if !p.open {
+		p.MoveTo(c1x, c1y)
+	}
+
+	pt := Point{x, y}
+
+	p.ops = append(p.ops, op{kind: opCube, p: [3]Point{{c1x, c1y}, {c2x, c2y}, pt}})
+	p.current = pt
+}
+
+// Arc adds an arc of the circle around cx, cy with radius, from angle start
+// to end in radians. Angles grow clockwise from 3 o'clock, with y growing
+// downwards. If end is smaller than start, the arc goes counterclockwise.
+//
+// A line connects the current point to the start of the arc, if there is a
+// subpath already.
+func (p *Path) Arc(cx, cy, radius, start, end float64) {
+	p.EllipticArc(cx, cy, radius, radius, start, end)
+}
+
+// EllipticArc adds an arc of the axis aligned ellipse around cx, cy with the
+// radii rx and ry, like Arc.
+func (p *Path) EllipticArc(cx, cy, rx, ry, start, end float64) {
+	sin, cos := math.Sincos(start)
+	x0, y0 := cx+rx*cos, cy+ry*sin
+
+	if p.open {
+		p.LineTo(x0, y0)
+	} else {
+		p.MoveTo(x0, y0)
+	}
+
+	sweep := end - start
+	if sweep == 0 {
+		return
+	}
+	if sweep > 2*math.Pi {
+		sweep = 2 * math.Pi
+	} else if sweep < -2*math.Pi {
+		sweep = -2 * math.Pi
+	}
+
+	// Approximate by cubic Béziers of at most 90° each.
+	n := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2)))
+	theta := sweep / float64(n)
+	k := 4.0 / 3.0 * math.Tan(theta/4)
+
+	a0 := start
+	for i := 0; i < n; i++ {
+		a1 := a0 + theta
+
+		sin0, cos0 := math.Sincos(a0)
+		sin1, cos1 := math.Sincos(a1)
+
+		p.CubeTo(
+			cx+rx*(cos0-k*sin0), cy+ry*(sin0+k*cos0),
+			cx+rx*(cos1+k*sin1), cy+ry*(sin1-k*cos1),
+			cx+rx*cos1, cy+ry*sin1)
+
+		a0 = a1
+	}
+}
+
+// Close closes the current subpath with a line to its start.
+func (p *Path) Close() {
+	if !p.open {
+		return
+	}
+
+	p.ops = append(p.ops, op{kind: opClose})
+	p.current = p.start
+	p.open = false
+}
+
+// Rect adds a closed subpath for the rectangle at x, y with width and height.
+func (p *Path) Rect(x, y, width, height float64) {
+	p.MoveTo(x, y)
+	p.LineTo(x+width, y)
+	p.LineTo(x+width, y+height)
+	p.LineTo(x, y+height)
+	p.Close()
+}
+
+// RoundedRect adds a closed subpath for the rectangle at x, y with width and
+// height and corners rounded with radius.
+func (p *Path) RoundedRect(x, y, width, height, radius float64) {
+	radius = math.Min(radius, math.Min(math.Abs(width), math.Abs(height))/2)
+	if radius <= 0 {
+		p.Rect(x, y, width, height)
+		return
+	}
+
+	p.open = false
+	p.Arc(x+width-radius, y+radius, radius, -math.Pi/2, 0)
+	p.Arc(x+width-radius, y+height-radius, radius, 0, math.Pi/2)
+	p.Arc(x+radius, y+height-radius, radius, math.Pi/2, math.Pi)
+	p.Arc(x+radius, y+radius, radius, math.Pi, 3*math.Pi/2)
+	p.Close()
+}
+
+// Ellipse adds a closed subpath for the axis aligned ellipse around cx, cy
+// with the radii rx and ry.
+func (p *Path) Ellipse(cx, cy, rx, ry float64) {
+	p.open = false
+	p.EllipticArc(cx, cy, rx, ry, 0, 2*math.Pi)
+	p.Close()
+}
+
+// Circle adds a closed subpath for the circle around cx, cy with radius.
+func (p *Path) Circle(cx, cy, radius float64) {
+	p.Ellipse(cx, cy, radius, radius)
+}
+
+// polyline is a flattened subpath.
+type polyline struct {
+	points []Point
+	closed bool
+}
+
+// flatten returns the subpaths of p transformed by m, with curves replaced by
+// lines that deviate at most about tolerance from them.
+func (p *Path) flatten(m Matrix, tolerance float64) []polyline {
+	var lines []polyline
+	var cur *polyline
+	var last Point
+
+	for _, o := range p.ops {
+		switch o.kind {
+		case opMove:
+			last = m.Apply(o.p[0])
+			lines = append(lines, polyline{points: []Point{last}})
+			cur = &lines[len(lines)-1]
+
+		case opLine:
+			last = m.Apply(o.p[0])
+			cur.points = append(cur.points, last)
+
+		case opQuad:
+			c, end := m.Apply(o.p[0]), m.Apply(o.p[1])
+			cur.points = flattenQuad(cur.points, last, c, end, tolerance)
+			last = end
+
+		case opCube:
+			c1, c2, end := m.Apply(o.p[0]), m.Apply(o.p[1]), m.Apply(o.p[2])
+			cur.points = flattenCube(cur.points, last, c1, c2, end, tolerance)
+			last = end
+
+		case opClose:
+			cur.closed = true
+			last = cur.points[0]
+		}
+	}
+
+	return lines
+}
+
+// segmentCount returns the number of lines that approximate a curve whose
+// control polygon bends by dd within tolerance.
+func segmentCount(dd, tolerance float64) int {
+	n := int(math.Ceil(math.Sqrt(dd / tolerance)))
+	if n < 1 {
+		return 1
+	}
+	if n > 1000 {
+		return 1000
+	}
+
+	return n
+}
+
+func flattenQuad(points []Point, p0, p1, p2 Point, tolerance float64) []Point {
+	ddx, ddy := p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y
+	n := segmentCount(math.Hypot(ddx, ddy)/4, tolerance)
+
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+
+		points = append(points, Point{
+			u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+			u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+		})
+	}
+
+	return points
+}
+
+func flattenCube(points []Point, p0, p1, p2, p3 Point, tolerance float64) []Point {
+	dd := math.Max(
+		math.Hypot(p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y),
+		math.Hypot(p1.X-2*p2.X+p3.X, p1.Y-2*p2.Y+p3.Y))
+	n := segmentCount(dd*3/4, tolerance)
+
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+
+		points = append(points, Point{
+			u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+			u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+		})
+	}
+
+	return points
+}
@@ -0,0 +1,214 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+)
+
+// FillRule decides which parts of a self-intersecting path or of nested
+// subpaths are inside.
+type FillRule int
+
+const (
+	// NonZero fills where the subpaths wind around a point at all.
+	NonZero FillRule = iota
+
+	// EvenOdd fills where the subpaths wind around a point an odd number of
+	// times.
+	EvenOdd
+)
+
+// rasterizer computes the anti-aliased coverage of polygons, by accumulating
+// the signed area that their edges cover in each pixel.
+type rasterizer struct {
+	width  int
+	height int
+	acc    []float32 // width+2 per row, so edges at the right don't spill over.
+	cov    []float32
+	minY   int
+	maxY   int
+}
+
+func (r *rasterizer) reset(width, height int) {
+	if width != r.width || height != r.height {
+		r.width = width
+		r.height = height
+		r.acc = make([]float32, (width+2)*height)
+		r.cov = make([]float32, width*height)
+	}
+
+	r.minY = height
+	r.maxY = 0
+}
+
+// polygon adds the edges of the closed polygon through points.
+func (r *rasterizer) polygon(points []Point) {
+	if len(points) < 2 {
+		return
+	}
+
+	for i := range points {
+		j := i + 1
+		if j == len(points) {
+			j = 0
+		}
+
+		r.line(points[i], points[j])
+	}
+}
+
+func (r *rasterizer) line(p0, p1 Point) {
+	if p0.Y == p1.Y || !finite(p0) || !finite(p1) {
+		return
+	}
+
+	dir := float32(1)
+	if p0.Y > p1.Y {
+		dir = -1
+		p0, p1 = p1, p0
+	}
+
+	height := float64(r.height)
+	if p1.Y <= 0 || p0.Y >= height {
+		return
+	}
+
+	dxdy := (p1.X - p0.X) / (p1.Y - p0.Y)
+
+	x, y0 := p0.X, p0.Y
+	if y0 < 0 {
+		x -= y0 * dxdy
+		y0 = 0
+	}
+	y1 := math.Min(p1.Y, height)
+
+	width := float64(r.width)
+	stride := r.width + 2
+
+	yFirst := int(y0)
+	yLast := int(math.Ceil(y1))
+	if yFirst < r.minY {
+		r.minY = yFirst
+	}
+	if yLast > r.maxY {
+		r.maxY = yLast
+	}
+
+	for y := yFirst; y < yLast; y++ {
+		acc := r.acc[y*stride : (y+1)*stride]
+
+		dy := math.Min(float64(y+1), y1) - math.Max(float64(y), y0)
+		xNext := x + dxdy*dy
+		d := float32(dy) * dir
+
+		// Parts left of the image still count for the winding of the
+		// pixels to their right, so they are moved to the left edge.
+		x0, x1 := clamp(x, 0, width), clamp(xNext, 0, width)
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+
+		x0Floor := math.Floor(x0)
+		x0i := int(x0Floor)
+		x1Ceil := math.Ceil(x1)
+		x1i := int(x1Ceil)
+
+		if x1i <= x0i+1 {
+			// The edge stays within one pixel of the row.
+			xm := float32(0.5*(x0+x1) - x0Floor)
+			acc[x0i] += d - d*xm
+			acc[x0i+1] += d * xm
+		} else {
+			s := 1 / (x1 - x0)
+			x0f := x0 - x0Floor
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := x1 - x1Ceil + 1
+			am := 0.5 * s * x1f * x1f
+
+			acc[x0i] += d * float32(a0)
+
+			if x1i == x0i+2 {
+				acc[x0i+1] += d * float32(1-a0-am)
+			} else {
+				a1 := s * (1.5 - x0f)
+				acc[x0i+1] += d * float32(a1-a0)
+
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					acc[xi] += d * float32(s)
+				}
+
+				a2 := a1 + float64(x1i-x0i-3)*s
+				acc[x1i-1] += d * float32(1-a2-am)
+			}
+
+			acc[x1i] += d * float32(am)
+		}
+
+		x = xNext
+	}
+}
+
+// coverage turns the accumulated edges into the coverage of each pixel, from
+// 0 to 1, and clears them for the next shape. Only the rows from minY to
+// maxY of the result are valid.
+func (r *rasterizer) coverage(rule FillRule) (cov []float32, minY, maxY int) {
+	stride := r.width + 2
+
+	for y := r.minY; y < r.maxY; y++ {
+		acc := r.acc[y*stride : (y+1)*stride]
+		row := r.cov[y*r.width : (y+1)*r.width]
+
+		var sum float32
+		for x := range row {
+			sum += acc[x]
+
+			a := sum
+			if a < 0 {
+				a = -a
+			}
+
+			if rule == EvenOdd {
+				a = float32(math.Mod(float64(a), 2))
+				if a > 1 {
+					a = 2 - a
+				}
+			} else if a > 1 {
+				a = 1
+			}
+
+			row[x] = a
+		}
+
+		for x := range acc {
+			acc[x] = 0
+		}
+	}
+
+	minY, maxY = r.minY, r.maxY
+	if minY > maxY {
+		minY = maxY
+	}
+
+	r.minY = r.height
+	r.maxY = 0
+
+	return r.cov, minY, maxY
+}
+
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+
+	return v
+}
+
+func finite(p Point) bool {
+	return !math.IsNaN(p.X) && !math.IsNaN(p.Y) && !math.IsInf(p.X, 0) && !math.IsInf(p.Y, 0)
+}
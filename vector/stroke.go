@@ -0,0 +1,320 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+)
+
+// Join is how the segments of a stroked path meet.
+type Join int
+
+const (
+	MiterJoin Join = iota
+	RoundJoin
+	BevelJoin
+)
+
+// Cap is how the ends of a stroked open subpath look.
+type Cap int
+
+const (
+	ButtCap Cap = iota
+	RoundCap
+	SquareCap
+)
+
+type strokeStyle struct {
+	width      float64
+	join       Join
+	cap        Cap
+	miterLimit float64
+	dashes     []float64
+	dashOffset float64
+}
+
+// stroker turns polylines into convex polygons that cover their stroke.
+// All polygons are oriented the same way, so filling them with the NonZero
+// rule paints their union.
+type stroker struct {
+	style     strokeStyle
+	halfWidth float64
+	tolerance float64
+	polygons  [][]Point
+}
+
+func strokePolygons(lines []polyline, style strokeStyle, tolerance float64) [][]Point {
+	if style.width <= 0 {
+		return nil
+	}
+
+	s := &stroker{style: style, halfWidth: style.width / 2, tolerance: tolerance}
+
+	if len(style.dashes) > 0 {
+		lines = dash(lines, style.dashes, style.dashOffset)
+	}
+
+	for _, l := range lines {
+		s.polyline(l)
+	}
+
+	return s.polygons
+}
+
+func (s *stroker) add(points ...Point) {
+	// Orient all polygons clockwise, in y-down coordinates.
+	var area float64
+	for i := range points {
+		j := (i + 1) % len(points)
+		area += points[i].X*points[j].Y - points[j].X*points[i].Y
+	}
+	if area < 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+
+	s.polygons = append(s.polygons, points)
+}
+
+func (s *stroker) circle(c Point) {
+	r := s.halfWidth
+
+	n := 8
+	if r > s.tolerance {
+		n = int(math.Ceil(math.Pi / math.Acos(1-s.tolerance/r)))
+	}
+	if n < 8 {
+		n = 8
+	} else if n > 256 {
+		n = 256
+	}
+
+	points := make([]Point, n)
+	for i := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		points[i] = Point{c.X + r*cos, c.Y + r*sin}
+	}
+
+	s.add(points...)
+}
+
+func (s *stroker) polyline(l polyline) {
+	// Drop repeated points, they have no direction.
+	points := make([]Point, 0, len(l.points))
+	for _, p := range l.points {
+		if len(points) == 0 || p != points[len(points)-1] {
+			points = append(points, p)
+		}
+	}
+	if l.closed && len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+
+	if len(points) == 1 {
+		// A dot, which only round and square caps draw.
+		switch s.style.cap {
+		case RoundCap:
+			s.circle(points[0])
+
+		case SquareCap:
+			hw := s.halfWidth
+			p := points[0]
+			s.add(Point{p.X - hw, p.Y - hw}, Point{p.X + hw, p.Y - hw}, Point{p.X + hw, p.Y + hw}, Point{p.X - hw, p.Y + hw})
+		}
+		return
+	}
+	if len(points) == 0 {
+		return
+	}
+
+	closed := l.closed && len(points) > 2
+
+	segmentCount := len(points) - 1
+	if closed {
+		segmentCount++
+	}
+
+	for i := 0; i < segmentCount; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		n := s.normal(a, b)
+
+		s.add(
+			Point{a.X + n.X, a.Y + n.Y},
+			Point{b.X + n.X, b.Y + n.Y},
+			Point{b.X - n.X, b.Y - n.Y},
+			Point{a.X - n.X, a.Y - n.Y})
+	}
+
+	// Joins
+	for i := 0; i < len(points); i++ {
+		if !closed && (i == 0 || i == len(points)-1) {
+			continue
+		}
+
+		prev := points[(i-1+len(points))%len(points)]
+		next := points[(i+1)%len(points)]
+
+		s.join(prev, points[i], next)
+	}
+
+	if !closed {
+		s.cap(points[1], points[0])
+		s.cap(points[len(points)-2], points[len(points)-1])
+	}
+}
+
+// normal returns the vector of length halfWidth perpendicular to a->b.
+func (s *stroker) normal(a, b Point) Point {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := math.Hypot(dx, dy)
+
+	return Point{-dy / l * s.halfWidth, dx / l * s.halfWidth}
+}
+
+func (s *stroker) join(prev, v, next Point) {
+	if s.style.join == RoundJoin {
+		s.circle(v)
+		return
+	}
+
+	d0x, d0y := v.X-prev.X, v.Y-prev.Y
+	d1x, d1y := next.X-v.X, next.Y-v.Y
+
+	cross := d0x*d1y - d0y*d1x
+	if cross == 0 {
+		// Straight on or turning back, neither needs a corner.
+		return
+	}
+
+	n0, n1 := s.normal(prev, v), s.normal(v, next)
+	if cross > 0 {
+		n0 = Point{-n0.X, -n0.Y}
+		n1 = Point{-n1.X, -n1.Y}
+	}
+
+	a := Point{v.X + n0.X, v.Y + n0.Y}
+	b := Point{v.X + n1.X, v.Y + n1.Y}
+
+	if s.style.join == MiterJoin {
+		// The miter tip is along the bisector of the normals, at a distance
+		// of halfWidth / cos(θ/2) from v.
+		mx, my := n0.X+n1.X, n0.Y+n1.Y
+		ml := math.Hypot(mx, my)
+		if ml > 0 {
+			cosHalf := (mx*n0.X + my*n0.Y) / (ml * s.halfWidth)
+
+			if cosHalf > 0 && 1/cosHalf <= s.style.miterLimit {
+				f := s.halfWidth / cosHalf / ml
+				tip := Point{v.X + mx*f, v.Y + my*f}
+
+				s.add(v, a, tip, b)
+				return
+			}
+		}
+	}
+
+	s.add(v, a, b)
+}
+
+// cap adds the cap at the end of the segment from -> end.
+func (s *stroker) cap(from, end Point) {
+	switch s.style.cap {
+	case RoundCap:
+		s.circle(end)
+
+	case SquareCap:
+		n := s.normal(from, end)
+		// The direction of the segment, with length halfWidth.
+		d := Point{n.Y, -n.X}
+
+		s.add(
+			Point{end.X + n.X, end.Y + n.Y},
+			Point{end.X + n.X + d.X, end.Y + n.Y + d.Y},
+			Point{end.X - n.X + d.X, end.Y - n.Y + d.Y},
+			Point{end.X - n.X, end.Y - n.Y})
+	}
+}
+
+// dash splits lines into the open pieces that the dash pattern leaves on.
+// The pattern alternates between lengths on and off, starting at offset.
+func dash(lines []polyline, dashes []float64, offset float64) []polyline {
+	if len(dashes)%2 == 1 {
+		dashes = append(append([]float64(nil), dashes...), dashes...)
+	}
+
+	var total float64
+	for _, d := range dashes {
+		if d < 0 {
+			return lines
+		}
+		total += d
+	}
+	if total <= 0 {
+		return lines
+	}
+
+	var pieces []polyline
+
+	for _, l := range lines {
+		points := l.points
+		if l.closed && len(points) > 1 {
+			points = append(append([]Point(nil), points...), points[0])
+		}
+
+		// Find where in the pattern the line starts.
+		i := 0
+		left := math.Mod(offset, total)
+		if left < 0 {
+			left += total
+		}
+		for left >= dashes[i] {
+			left -= dashes[i]
+			i = (i + 1) % len(dashes)
+		}
+		left = dashes[i] - left
+		on := i%2 == 0
+
+		var piece []Point
+		if on && len(points) > 0 {
+			piece = []Point{points[0]}
+		}
+
+		for j := 1; j < len(points); j++ {
+			a, b := points[j-1], points[j]
+			segLen := math.Hypot(b.X-a.X, b.Y-a.Y)
+			pos := 0.0
+
+			for segLen-pos > left {
+				pos += left
+				t := pos / segLen
+				p := Point{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+
+				if on {
+					piece = append(piece, p)
+					pieces = append(pieces, polyline{points: piece})
+					piece = nil
+				} else {
+					piece = []Point{p}
+				}
+
+				on = !on
+				i = (i + 1) % len(dashes)
+				left = dashes[i]
+			}
+
+			left -= segLen - pos
+			if on {
+				piece = append(piece, b)
+			}
+		}
+
+		if on && len(piece) > 1 {
+			pieces = append(pieces, polyline{points: piece})
+		}
+	}
+
+	return pieces
+}
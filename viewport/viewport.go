@@ -0,0 +1,210 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package viewport implements the computations behind zooming and panning an
+// image in a view: fitting, clamping and finding the visible part.
+//
+// It does not depend on walk, so the logic can be tested on any platform.
+// walk.ImageView shows images in a viewport in ImageViewModeViewport.
+package viewport
+
+import (
+	"math"
+)
+
+// The zoom is kept between MinZoom and MaxZoom.
+const (
+	MinZoom = 1.0 / 64
+	MaxZoom = 64.0
+)
+
+// Size is a size in pixels.
+type Size struct {
+	Width, Height int
+}
+
+// Point is a point in pixels.
+type Point struct {
+	X, Y int
+}
+
+// Rect is a rectangle in pixels.
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// Viewport is the part of a zoomed image that a view shows.
+type Viewport struct {
+	ImageSize Size // At a zoom of 1.
+	ViewSize  Size
+	Zoom      float64
+
+	// The point of the image that is shown at the center of the view, as a
+	// fraction of the image size, so it does not depend on Zoom or DPI.
+	CenterX, CenterY float64
+}
+
+// New returns a Viewport that shows the center of the image at its actual
+// size.
+func New() Viewport {
+	return Viewport{Zoom: 1, CenterX: 0.5, CenterY: 0.5}
+}
+
+// FitZoom returns the zoom at which the whole image fits into the view.
+func (vp *Viewport) FitZoom() float64 {
+	if vp.ImageSize.Width <= 0 || vp.ImageSize.Height <= 0 {
+		return 1
+	}
+
+	return ClampZoom(math.Min(
+		float64(vp.ViewSize.Width)/float64(vp.ImageSize.Width),
+		float64(vp.ViewSize.Height)/float64(vp.ImageSize.Height)))
+}
+
+// FillZoom returns the zoom at which the image covers the whole view.
+func (vp *Viewport) FillZoom() float64 {
+	if vp.ImageSize.Width <= 0 || vp.ImageSize.Height <= 0 {
+		return 1
+	}
+
+	return ClampZoom(math.Max(
+		float64(vp.ViewSize.Width)/float64(vp.ImageSize.Width),
+		float64(vp.ViewSize.Height)/float64(vp.ImageSize.Height)))
+}
+
+// ClampZoom returns zoom limited to the range from MinZoom to MaxZoom. NaN
+// is treated as 1.
+func ClampZoom(zoom float64) float64 {
+	if math.IsNaN(zoom) {
+		return 1
+	}
+
+	return math.Max(MinZoom, math.Min(MaxZoom, zoom))
+}
+
+// zoomedWidth and zoomedHeight return the size of the image at the current
+// zoom.
+func (vp *Viewport) zoomedWidth() float64 {
+	return float64(vp.ImageSize.Width) * vp.Zoom
+}
+
+func (vp *Viewport) zoomedHeight() float64 {
+	return float64(vp.ImageSize.Height) * vp.Zoom
+}
+
+// Bounds returns where the image is drawn, relative to the view.
+func (vp *Viewport) Bounds() Rect {
+	w, h := vp.zoomedWidth(), vp.zoomedHeight()
+
+	return Rect{
+		X:      int(math.Round(float64(vp.ViewSize.Width)/2 - vp.CenterX*w)),
+		Y:      int(math.Round(float64(vp.ViewSize.Height)/2 - vp.CenterY*h)),
+		Width:  int(math.Round(w)),
+		Height: int(math.Round(h)),
+	}
+}
+
+// SetZoomAround sets the zoom, keeping the point of the image at p in the
+// view where it is.
+func (vp *Viewport) SetZoomAround(zoom float64, p Point) {
+	zoom = ClampZoom(zoom)
+
+	oldW, oldH := vp.zoomedWidth(), vp.zoomedHeight()
+	vp.Zoom = zoom
+	newW, newH := vp.zoomedWidth(), vp.zoomedHeight()
+
+	if oldW > 0 && newW > 0 {
+		dx := float64(p.X) - float64(vp.ViewSize.Width)/2
+		vp.CenterX += dx/oldW - dx/newW
+	}
+	if oldH > 0 && newH > 0 {
+		dy := float64(p.Y) - float64(vp.ViewSize.Height)/2
+		vp.CenterY += dy/oldH - dy/newH
+	}
+
+	vp.Clamp()
+}
+
+// PanBy moves the image by dx and dy.
+func (vp *Viewport) PanBy(dx, dy int) {
+	if w := vp.zoomedWidth(); w > 0 {
+		vp.CenterX -= float64(dx) / w
+	}
+	if h := vp.zoomedHeight(); h > 0 {
+		vp.CenterY -= float64(dy) / h
+	}
+
+	vp.Clamp()
+}
+
+// Clamp centers the image along axes where it is smaller than the view, and
+// otherwise keeps the view from showing anything beyond its edges.
+func (vp *Viewport) Clamp() {
+	vp.CenterX = clampCenter(vp.CenterX, float64(vp.ViewSize.Width), vp.zoomedWidth())
+	vp.CenterY = clampCenter(vp.CenterY, float64(vp.ViewSize.Height), vp.zoomedHeight())
+}
+
+func clampCenter(center, view, zoomed float64) float64 {
+	if zoomed <= view {
+		return 0.5
+	}
+
+	half := view / 2 / zoomed
+
+	return math.Max(half, math.Min(1-half, center))
+}
+
+// VisiblePart returns which part src of a bitmap of srcSize pixels, drawn
+// stretched to bounds, is visible in view and where it is drawn to, at dst.
+// Whole pixels of the bitmap are included. ok is false if no part is
+// visible.
+func VisiblePart(bounds, view Rect, srcSize Size) (dst, src Rect, ok bool) {
+	dst.X, dst.Width, src.X, src.Width, ok = visibleSpan(bounds.X, bounds.Width, view.X, view.Width, srcSize.Width)
+	if !ok {
+		return
+	}
+
+	dst.Y, dst.Height, src.Y, src.Height, ok = visibleSpan(bounds.Y, bounds.Height, view.Y, view.Height, srcSize.Height)
+
+	return
+}
+
+// visibleSpan is VisiblePart along one axis.
+func visibleSpan(pos, length, viewPos, viewLength, srcTotal int) (dstPos, dstLength, srcPos, srcLength int, ok bool) {
+	if length <= 0 || srcTotal <= 0 {
+		return
+	}
+
+	start := maxi(pos, viewPos)
+	end := mini(pos+length, viewPos+viewLength)
+	if end <= start {
+		return
+	}
+
+	scale := float64(length) / float64(srcTotal)
+
+	srcStart := int(math.Floor(float64(start-pos) / scale))
+	srcEnd := mini(srcTotal, int(math.Ceil(float64(end-pos)/scale)))
+
+	dstStart := pos + int(math.Round(float64(srcStart)*scale))
+	dstEnd := pos + int(math.Round(float64(srcEnd)*scale))
+
+	return dstStart, dstEnd - dstStart, srcStart, srcEnd - srcStart, true
+}
+
+func mini(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxi(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
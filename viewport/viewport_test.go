@@ -0,0 +1,215 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewport
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFitAndFillZoom(t *testing.T) {
+	tests := []struct {
+		image, view Size
+		fit, fill   float64
+	}{
+		{Size{200, 100}, Size{100, 100}, 0.5, 1},
+		{Size{100, 200}, Size{400, 200}, 1, 4},
+		{Size{50, 50}, Size{100, 100}, 2, 2},
+
+		// Zooms are clamped.
+		{Size{10000, 1}, Size{1, 1000}, MinZoom, MaxZoom},
+
+		// Empty images aren't scaled.
+		{Size{0, 100}, Size{100, 100}, 1, 1},
+		{Size{}, Size{}, 1, 1},
+	}
+
+	for _, test := range tests {
+		vp := Viewport{ImageSize: test.image, ViewSize: test.view}
+
+		if got := vp.FitZoom(); !approxEqual(got, test.fit) {
+			t.Errorf("FitZoom of %v in %v: got %v, want %v", test.image, test.view, got, test.fit)
+		}
+		if got := vp.FillZoom(); !approxEqual(got, test.fill) {
+			t.Errorf("FillZoom of %v in %v: got %v, want %v", test.image, test.view, got, test.fill)
+		}
+	}
+}
+
+func TestClampZoom(t *testing.T) {
+	tests := []struct {
+		zoom, want float64
+	}{
+		{1, 1},
+		{0.5, 0.5},
+		{0, MinZoom},
+		{-3, MinZoom},
+		{1000, MaxZoom},
+		{math.Inf(1), MaxZoom},
+		{math.NaN(), 1},
+	}
+
+	for _, test := range tests {
+		if got := ClampZoom(test.zoom); got != test.want {
+			t.Errorf("ClampZoom(%v): got %v, want %v", test.zoom, got, test.want)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	vp := New()
+	vp.ImageSize = Size{100, 50}
+	vp.ViewSize = Size{200, 200}
+
+	if got, want := vp.Bounds(), (Rect{50, 75, 100, 50}); got != want {
+		t.Errorf("centered: got %v, want %v", got, want)
+	}
+
+	vp.Zoom = 4
+	vp.CenterX, vp.CenterY = 0.25, 0.75
+
+	if got, want := vp.Bounds(), (Rect{0, -50, 400, 200}); got != want {
+		t.Errorf("zoomed: got %v, want %v", got, want)
+	}
+}
+
+func TestSetZoomAroundKeepsPoint(t *testing.T) {
+	vp := New()
+	vp.ImageSize = Size{400, 300}
+	vp.ViewSize = Size{200, 100}
+
+	p := Point{30, 80}
+
+	for _, zoom := range []float64{2, 3.5, 8, 1.25} {
+		before := imagePointAt(&vp, p)
+
+		vp.SetZoomAround(zoom, p)
+
+		if vp.Zoom != zoom {
+			t.Errorf("SetZoomAround(%v): got zoom %v", zoom, vp.Zoom)
+		}
+		if after := imagePointAt(&vp, p); math.Abs(after[0]-before[0]) > 1e-9 || math.Abs(after[1]-before[1]) > 1e-9 {
+			t.Errorf("SetZoomAround(%v): point of image at %v moved from %v to %v", zoom, p, before, after)
+		}
+	}
+}
+
+// imagePointAt returns which point of the image, as a fraction of its size,
+// is shown at p in the view.
+func imagePointAt(vp *Viewport, p Point) [2]float64 {
+	w := float64(vp.ImageSize.Width) * vp.Zoom
+	h := float64(vp.ImageSize.Height) * vp.Zoom
+
+	return [2]float64{
+		vp.CenterX + (float64(p.X)-float64(vp.ViewSize.Width)/2)/w,
+		vp.CenterY + (float64(p.Y)-float64(vp.ViewSize.Height)/2)/h,
+	}
+}
+
+func TestSetZoomAroundClamps(t *testing.T) {
+	vp := New()
+	vp.ImageSize = Size{100, 100}
+	vp.ViewSize = Size{100, 100}
+
+	vp.SetZoomAround(1000, Point{})
+	if vp.Zoom != MaxZoom {
+		t.Errorf("got zoom %v, want %v", vp.Zoom, MaxZoom)
+	}
+
+	vp.SetZoomAround(0.5, Point{})
+	if vp.CenterX != 0.5 || vp.CenterY != 0.5 {
+		t.Errorf("smaller than the view: got center %v, %v, want 0.5, 0.5", vp.CenterX, vp.CenterY)
+	}
+}
+
+func TestPanBy(t *testing.T) {
+	vp := New()
+	vp.ImageSize = Size{100, 100}
+	vp.ViewSize = Size{100, 50}
+	vp.Zoom = 2
+
+	vp.PanBy(-20, 10)
+	if !approxEqual(vp.CenterX, 0.6) || !approxEqual(vp.CenterY, 0.45) {
+		t.Errorf("got center %v, %v, want 0.6, 0.45", vp.CenterX, vp.CenterY)
+	}
+
+	// The view doesn't go beyond the edges of the image.
+	vp.PanBy(-1000, 1000)
+	if !approxEqual(vp.CenterX, 0.75) || !approxEqual(vp.CenterY, 0.125) {
+		t.Errorf("got center %v, %v, want 0.75, 0.125", vp.CenterX, vp.CenterY)
+	}
+	if b := vp.Bounds(); b.X+b.Width != vp.ViewSize.Width || b.Y != 0 {
+		t.Errorf("got bounds %v beyond the edges of the view %v", b, vp.ViewSize)
+	}
+
+	// Empty images don't move.
+	empty := New()
+	empty.PanBy(10, 10)
+	if empty.CenterX != 0.5 || empty.CenterY != 0.5 {
+		t.Errorf("empty image: got center %v, %v, want 0.5, 0.5", empty.CenterX, empty.CenterY)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		image, view  Size
+		zoom         float64
+		center, want [2]float64
+	}{
+		// Images smaller than the view are centered.
+		{Size{50, 50}, Size{100, 100}, 1, [2]float64{0.1, 0.9}, [2]float64{0.5, 0.5}},
+
+		// Larger ones keep the view covered.
+		{Size{200, 400}, Size{100, 100}, 1, [2]float64{0, 1}, [2]float64{0.25, 0.875}},
+		{Size{200, 400}, Size{100, 100}, 1, [2]float64{0.4, 0.5}, [2]float64{0.4, 0.5}},
+
+		// Per axis.
+		{Size{200, 50}, Size{100, 100}, 1, [2]float64{0.9, 0.9}, [2]float64{0.75, 0.5}},
+	}
+
+	for _, test := range tests {
+		vp := Viewport{ImageSize: test.image, ViewSize: test.view, Zoom: test.zoom, CenterX: test.center[0], CenterY: test.center[1]}
+
+		vp.Clamp()
+
+		if !approxEqual(vp.CenterX, test.want[0]) || !approxEqual(vp.CenterY, test.want[1]) {
+			t.Errorf("Clamp of %v in %v at %v: got %v, %v, want %v", test.image, test.view, test.center, vp.CenterX, vp.CenterY, test.want)
+		}
+	}
+}
+
+func TestVisiblePart(t *testing.T) {
+	tests := []struct {
+		bounds, view Rect
+		srcSize      Size
+		dst, src     Rect
+		ok           bool
+	}{
+		// Not zoomed and fully visible.
+		{Rect{10, 10, 50, 40}, Rect{0, 0, 100, 100}, Size{50, 40}, Rect{10, 10, 50, 40}, Rect{0, 0, 50, 40}, true},
+
+		// Zoomed by 10, so only whole pixels around the view are drawn.
+		{Rect{-95, -10, 1000, 1000}, Rect{0, 0, 100, 50}, Size{100, 100}, Rect{-5, 0, 110, 50}, Rect{9, 1, 11, 5}, true},
+
+		// Outside the view.
+		{Rect{200, 0, 50, 50}, Rect{0, 0, 100, 100}, Size{50, 50}, Rect{}, Rect{}, false},
+		{Rect{0, -60, 50, 50}, Rect{0, 0, 100, 100}, Size{50, 50}, Rect{}, Rect{}, false},
+
+		// Empty.
+		{Rect{0, 0, 0, 50}, Rect{0, 0, 100, 100}, Size{50, 50}, Rect{}, Rect{}, false},
+		{Rect{0, 0, 50, 50}, Rect{0, 0, 100, 100}, Size{0, 0}, Rect{}, Rect{}, false},
+	}
+
+	for _, test := range tests {
+		dst, src, ok := VisiblePart(test.bounds, test.view, test.srcSize)
+		if ok != test.ok || ok && (dst != test.dst || src != test.src) {
+			t.Errorf("VisiblePart(%v, %v, %v): got %v, %v, %v, want %v, %v, %v", test.bounds, test.view, test.srcSize, dst, src, ok, test.dst, test.src, test.ok)
+		}
+	}
+}
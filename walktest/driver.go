@@ -0,0 +1,268 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"time"
+)
+
+var ErrTimeout = errors.New("timeout")
+
+// Driver drives the application behind a Backend. Its methods block until
+// the UI thread handled them, so they must not be called on the UI thread
+// itself.
+type Driver struct {
+	backend Backend
+
+	// Timeout is how long WaitUntil waits, and how long any other method
+	// waits for the UI thread to respond, before returning ErrTimeout.
+	Timeout time.Duration
+
+	// PollInterval is how often WaitUntil checks its condition.
+	PollInterval time.Duration
+}
+
+// NewDriver returns a new Driver for backend, with a Timeout of 5 seconds.
+func NewDriver(backend Backend) *Driver {
+	return &Driver{
+		backend:      backend,
+		Timeout:      5 * time.Second,
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+// Backend returns the Backend of the Driver.
+func (d *Driver) Backend() Backend {
+	return d.backend
+}
+
+// Do calls f on the UI thread and waits for it to return.
+//
+// Since functions passed to Synchronize run in order, Do(func() {}) also
+// waits for everything the application synchronized before.
+//
+// If Do returns ErrTimeout, f may still be called later, so it must not write
+// to variables that the caller uses after that.
+func (d *Driver) Do(f func()) error {
+	_, err := d.call(func() (interface{}, error) {
+		f()
+		return nil, nil
+	})
+
+	return err
+}
+
+// call calls f on the UI thread and returns its results. They are handed
+// over only if f returns in time, so unlike the closures passed to Do, f
+// never writes to variables of a caller that gave up on it.
+func (d *Driver) call(f func() (interface{}, error)) (interface{}, error) {
+	type result struct {
+		value interface{}
+		err   error
+	}
+
+	// Buffered, so a late f doesn't block the UI thread.
+	results := make(chan result, 1)
+
+	if err := d.backend.Synchronize(func() {
+		value, err := f()
+		results <- result{value, err}
+	}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case r := <-results:
+		return r.value, r.err
+
+	case <-time.After(d.Timeout):
+		return nil, ErrTimeout
+	}
+}
+
+// Sync waits for the UI thread to handle everything synchronized so far.
+func (d *Driver) Sync() error {
+	return d.Do(func() {})
+}
+
+// WaitUntil calls cond on the UI thread until it returns true, or returns
+// ErrTimeout after d.Timeout.
+func (d *Driver) WaitUntil(cond func() bool) error {
+	_, err := d.waitFor(func() (interface{}, bool) {
+		return nil, cond()
+	})
+
+	return err
+}
+
+// errNotYet is returned by the calls of waitFor, while the condition is not
+// met.
+var errNotYet = errors.New("not yet")
+
+// waitFor is like WaitUntil, but returns the value that f returned along with
+// true.
+func (d *Driver) waitFor(f func() (interface{}, bool)) (interface{}, error) {
+	deadline := time.Now().Add(d.Timeout)
+
+	for {
+		value, err := d.call(func() (interface{}, error) {
+			if value, ok := f(); ok {
+				return value, nil
+			}
+
+			return nil, errNotYet
+		})
+		if err != errNotYet {
+			return value, err
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrTimeout
+		}
+
+		time.Sleep(d.PollInterval)
+	}
+}
+
+// Find returns the window identified by path, see FindByPath.
+func (d *Driver) Find(path string) (Window, error) {
+	value, err := d.call(func() (interface{}, error) {
+		w, err := FindByPath(d.backend.Roots(), path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		return w, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := value.(Window)
+
+	return w, nil
+}
+
+// FindByName returns the window named name, see FindByName.
+func (d *Driver) FindByName(name string) (Window, error) {
+	value, err := d.call(func() (interface{}, error) {
+		w, err := FindByName(d.backend.Roots(), name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		return w, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := value.(Window)
+
+	return w, nil
+}
+
+// WaitFor waits until the window identified by path exists and is visible,
+// e.g. after opening a dialog, and returns it.
+func (d *Driver) WaitFor(path string) (Window, error) {
+	value, err := d.waitFor(func() (interface{}, bool) {
+		w, _ := FindByPath(d.backend.Roots(), path)
+		return w, w != nil && w.Visible()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	w, _ := value.(Window)
+
+	return w, nil
+}
+
+// Path returns the path of w.
+func (d *Driver) Path(w Window) (string, error) {
+	value, err := d.call(func() (interface{}, error) {
+		return Path(d.backend.Roots(), w), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil
+}
+
+// Click simulates a left click at the center of w.
+func (d *Driver) Click(w Window) error {
+	return d.ClickAt(w, LeftButton, image.Point{-1, -1}, 0)
+}
+
+// ClickAt simulates a click of button at pos, relative to the client area of
+// w, while holding mods. A negative pos means the center of w.
+func (d *Driver) ClickAt(w Window, button MouseButton, pos image.Point, mods Modifiers) error {
+	return d.send(w, false, func() []Message {
+		at := pos
+		if at.X < 0 || at.Y < 0 {
+			size := w.ClientSize()
+			at = image.Point{size.X / 2, size.Y / 2}
+		}
+
+		return ClickMessages(button, at, mods)
+	})
+}
+
+// PressKey focuses w and simulates pressing key while holding mods.
+func (d *Driver) PressKey(w Window, key Key, mods Modifiers) error {
+	return d.send(w, true, func() []Message {
+		return KeyMessages(key, mods)
+	})
+}
+
+// TypeText focuses w and simulates typing text.
+func (d *Driver) TypeText(w Window, text string) error {
+	return d.send(w, true, func() []Message {
+		return TextMessages(text)
+	})
+}
+
+// Screenshot returns an image of w.
+func (d *Driver) Screenshot(w Window) (image.Image, error) {
+	value, err := d.call(func() (interface{}, error) {
+		return w.Screenshot()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, _ := value.(image.Image)
+
+	return img, nil
+}
+
+func (d *Driver) send(w Window, focus bool, messages func() []Message) error {
+	if _, err := d.call(func() (interface{}, error) {
+		if !w.Visible() || !w.Enabled() {
+			return nil, fmt.Errorf("%s: window is not visible and enabled", w.Name())
+		}
+
+		if focus {
+			if err := w.SetFocus(); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, msg := range messages() {
+			w.SendMessage(msg.Msg, msg.WParam, msg.LParam)
+		}
+
+		return nil, nil
+	}); err != nil {
+		return err
+	}
+
+	// Handlers may have synchronized more work, e.g. to update other windows.
+	return d.Sync()
+}
@@ -0,0 +1,223 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"time"
+)
+
+func TestDriverClick(t *testing.T) {
+	okPB := NewFakeWindow("Button", "okPB")
+	d := NewDriver(NewFakeBackend(NewFakeWindow("MainWindow", "mainWindow", okPB)))
+
+	var clicks int
+	okPB.OnClick = func() { clicks++ }
+
+	w, err := d.Find("mainWindow/okPB")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Click(w); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []Message
+	d.Do(func() { msgs = okPB.Messages() })
+
+	if clicks != 1 {
+		t.Errorf("got %d clicks, want 1", clicks)
+	}
+
+	center := uintptr(50) | uintptr(15)<<16
+	want := []Message{
+		{wmMouseMove, 0, center},
+		{wmLButtonDown, mkLButton, center},
+		{wmLButtonUp, 0, center},
+	}
+	if !equalMessages(msgs, want) {
+		t.Errorf("got messages %v, want %v", msgs, want)
+	}
+}
+
+func TestDriverClickDisabled(t *testing.T) {
+	okPB := NewFakeWindow("Button", "okPB")
+	mainWindow := NewFakeWindow("MainWindow", "mainWindow", okPB)
+	mainWindow.SetEnabled(false)
+	d := NewDriver(NewFakeBackend(mainWindow))
+
+	okPB.OnClick = func() { t.Error("disabled window was clicked") }
+
+	if err := d.Click(okPB); err == nil {
+		t.Error("got no error clicking a disabled window")
+	}
+}
+
+func TestDriverTypeText(t *testing.T) {
+	nameLE := NewFakeWindow("LineEdit", "nameLE")
+	nameLE.SetEditable(true)
+	otherLE := NewFakeWindow("LineEdit", "otherLE")
+	d := NewDriver(NewFakeBackend(NewFakeWindow("MainWindow", "mainWindow", nameLE, otherLE)))
+
+	d.Do(func() { otherLE.SetFocus() })
+
+	if err := d.TypeText(nameLE, "añ😀\bb"); err != nil {
+		t.Fatal(err)
+	}
+
+	var text string
+	var focused, otherFocused bool
+	d.Do(func() { text, focused, otherFocused = nameLE.Text(), nameLE.Focused(), otherLE.Focused() })
+
+	if want := "añb"; text != want {
+		t.Errorf("got text %q, want %q", text, want)
+	}
+	if !focused || otherFocused {
+		t.Errorf("got focus %t and %t, want nameLE focused only", focused, otherFocused)
+	}
+}
+
+func TestDriverPressKey(t *testing.T) {
+	nameLE := NewFakeWindow("LineEdit", "nameLE")
+	d := NewDriver(NewFakeBackend(NewFakeWindow("MainWindow", "mainWindow", nameLE)))
+
+	const keyA = Key(0x41)
+
+	if err := d.PressKey(nameLE, keyA, ModControl|ModShift); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []Message
+	d.Do(func() { msgs = nameLE.Messages() })
+
+	want := []Message{
+		keyDown(vkControl),
+		keyDown(vkShift),
+		keyDown(keyA),
+		keyUp(keyA),
+		keyUp(vkShift),
+		keyUp(vkControl),
+	}
+	if !equalMessages(msgs, want) {
+		t.Errorf("got messages %v, want %v", msgs, want)
+	}
+}
+
+func TestDriverWaitFor(t *testing.T) {
+	backend := NewFakeBackend(NewFakeWindow("MainWindow", "mainWindow"))
+	d := NewDriver(backend)
+	d.Timeout = time.Second
+
+	dialog := NewFakeWindow("Dialog", "dialog")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		backend.SetRoots(NewFakeWindow("MainWindow", "mainWindow"), dialog)
+	}()
+
+	w, err := d.WaitFor("dialog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != dialog {
+		t.Errorf("got %v, want %v", w, dialog)
+	}
+
+	d.Timeout = 50 * time.Millisecond
+	if _, err := d.WaitFor("missing"); err == nil {
+		t.Error("got no error waiting for a missing window")
+	}
+}
+
+// stalledBackend holds back the functions passed to Synchronize, until they
+// are released, or fails if err is set.
+type stalledBackend struct {
+	*FakeBackend
+	stalled chan func()
+	err     error
+}
+
+func (sb *stalledBackend) Synchronize(f func()) error {
+	if sb.err != nil {
+		return sb.err
+	}
+
+	sb.stalled <- f
+	return nil
+}
+
+func TestDriverTimeout(t *testing.T) {
+	backend := &stalledBackend{
+		FakeBackend: NewFakeBackend(NewFakeWindow("MainWindow", "mainWindow")),
+		stalled:     make(chan func(), 1),
+	}
+	d := NewDriver(backend)
+	d.Timeout = 10 * time.Millisecond
+
+	w, err := d.Find("mainWindow")
+	if err != ErrTimeout {
+		t.Fatalf("got error %v, want ErrTimeout", err)
+	}
+	if w != nil {
+		t.Errorf("got window %v after timeout, want nil", w)
+	}
+
+	// The late call must neither block nor hand over its results.
+	(<-backend.stalled)()
+	if w != nil {
+		t.Errorf("got window %v after late call, want nil", w)
+	}
+
+	backend.err = errors.New("no UI thread")
+	if err := d.Sync(); err != backend.err {
+		t.Errorf("got error %v, want %v", err, backend.err)
+	}
+}
+
+func TestTextMessages(t *testing.T) {
+	msgs := TextMessages("a\r\n😀")
+
+	want := []Message{
+		{wmChar, 'a', 1},
+		keyDown(vkReturn),
+		{wmChar, '\r', 1},
+		keyUp(vkReturn),
+		{wmChar, 0xD83D, 1},
+		{wmChar, 0xDE00, 1},
+	}
+	if !equalMessages(msgs, want) {
+		t.Errorf("got messages %v, want %v", msgs, want)
+	}
+}
+
+func TestClickMessagesRightButton(t *testing.T) {
+	msgs := ClickMessages(RightButton, image.Point{3, 4}, ModShift)
+
+	pos := uintptr(3) | uintptr(4)<<16
+	want := []Message{
+		{wmMouseMove, mkShift, pos},
+		{wmRButtonDown, mkShift | mkRButton, pos},
+		{wmRButtonUp, mkShift, pos},
+	}
+	if !equalMessages(msgs, want) {
+		t.Errorf("got messages %v, want %v", msgs, want)
+	}
+}
+
+func equalMessages(a, b []Message) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,310 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"unicode/utf16"
+)
+
+// FakeBackend is a Backend without any real windows. Functions passed to its
+// Synchronize method run in order on a goroutine of their own, which plays
+// the role of the UI thread.
+type FakeBackend struct {
+	mutex   sync.Mutex
+	roots   []Window
+	queue   []func()
+	wake    chan struct{}
+	started bool
+}
+
+// NewFakeBackend returns a new FakeBackend with roots as top level windows.
+func NewFakeBackend(roots ...*FakeWindow) *FakeBackend {
+	fb := &FakeBackend{wake: make(chan struct{}, 1)}
+
+	fb.SetRoots(roots...)
+
+	return fb
+}
+
+// Roots returns the top level windows.
+func (fb *FakeBackend) Roots() []Window {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	return append([]Window(nil), fb.roots...)
+}
+
+// SetRoots replaces the top level windows, e.g. to simulate opening a dialog.
+func (fb *FakeBackend) SetRoots(roots ...*FakeWindow) {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	fb.roots = fb.roots[:0]
+	for _, root := range roots {
+		fb.roots = append(fb.roots, root)
+	}
+}
+
+func (fb *FakeBackend) Synchronize(f func()) error {
+	fb.mutex.Lock()
+	fb.queue = append(fb.queue, f)
+	if !fb.started {
+		fb.started = true
+		go fb.run()
+	}
+	fb.mutex.Unlock()
+
+	select {
+	case fb.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (fb *FakeBackend) run() {
+	for range fb.wake {
+		for {
+			fb.mutex.Lock()
+			if len(fb.queue) == 0 {
+				fb.mutex.Unlock()
+				break
+			}
+			f := fb.queue[0]
+			fb.queue = fb.queue[1:]
+			fb.mutex.Unlock()
+
+			f()
+		}
+	}
+}
+
+// FakeWindow is a Window for a FakeBackend. It records the messages sent to
+// it and has just enough behavior to test input sequences: Editable windows
+// append typed characters to their text and OnClick is called for left
+// clicks.
+//
+// Like real windows, FakeWindows must only be modified on the UI thread, once
+// they are in use by a Driver.
+type FakeWindow struct {
+	// OnClick, if not nil, is called when the window receives a left button
+	// up message after a left button down message.
+	OnClick func()
+
+	// OnMessage, if not nil, is called for each message sent to the window,
+	// after the default handling. Its result is returned by SendMessage.
+	OnMessage func(msg Message) uintptr
+
+	name       string
+	class      string
+	text       string
+	parent     *FakeWindow
+	children   []*FakeWindow
+	clientSize image.Point
+	image      image.Image
+	messages   []Message
+	hidden     bool
+	disabled   bool
+	editable   bool
+	focused    bool
+	buttonDown bool
+	surrogate  rune
+}
+
+// NewFakeWindow returns a new visible and enabled FakeWindow with a client
+// size of 100x30 pixels.
+func NewFakeWindow(class, name string, children ...*FakeWindow) *FakeWindow {
+	fw := &FakeWindow{
+		name:       name,
+		class:      class,
+		clientSize: image.Point{100, 30},
+	}
+
+	for _, child := range children {
+		fw.AddChild(child)
+	}
+
+	return fw
+}
+
+func (fw *FakeWindow) Name() string {
+	return fw.name
+}
+
+func (fw *FakeWindow) Class() string {
+	return fw.class
+}
+
+func (fw *FakeWindow) Children() []Window {
+	children := make([]Window, len(fw.children))
+	for i, child := range fw.children {
+		children[i] = child
+	}
+
+	return children
+}
+
+// Parent returns the parent window, or nil for top level windows.
+func (fw *FakeWindow) Parent() *FakeWindow {
+	return fw.parent
+}
+
+// AddChild appends child to the children of the window.
+func (fw *FakeWindow) AddChild(child *FakeWindow) {
+	child.parent = fw
+	fw.children = append(fw.children, child)
+}
+
+func (fw *FakeWindow) Text() string {
+	return fw.text
+}
+
+func (fw *FakeWindow) SetText(text string) {
+	fw.text = text
+}
+
+// Visible returns if the window and all of its ancestors are visible.
+func (fw *FakeWindow) Visible() bool {
+	for w := fw; w != nil; w = w.parent {
+		if w.hidden {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (fw *FakeWindow) SetVisible(visible bool) {
+	fw.hidden = !visible
+}
+
+// Enabled returns if the window and all of its ancestors are enabled.
+func (fw *FakeWindow) Enabled() bool {
+	for w := fw; w != nil; w = w.parent {
+		if w.disabled {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (fw *FakeWindow) SetEnabled(enabled bool) {
+	fw.disabled = !enabled
+}
+
+// SetEditable sets whether typed characters are appended to the text.
+func (fw *FakeWindow) SetEditable(editable bool) {
+	fw.editable = editable
+}
+
+func (fw *FakeWindow) ClientSize() image.Point {
+	return fw.clientSize
+}
+
+func (fw *FakeWindow) SetClientSize(size image.Point) {
+	fw.clientSize = size
+}
+
+// Focused returns if the window has the keyboard focus.
+func (fw *FakeWindow) Focused() bool {
+	return fw.focused
+}
+
+// SetFocus moves the keyboard focus within the top level window of fw to fw.
+func (fw *FakeWindow) SetFocus() error {
+	root := fw
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	var unfocus func(w *FakeWindow)
+	unfocus = func(w *FakeWindow) {
+		w.focused = false
+		for _, child := range w.children {
+			unfocus(child)
+		}
+	}
+	unfocus(root)
+
+	fw.focused = true
+
+	return nil
+}
+
+// Messages returns the messages sent to the window so far.
+func (fw *FakeWindow) Messages() []Message {
+	return append([]Message(nil), fw.messages...)
+}
+
+// ClearMessages forgets the messages sent to the window so far.
+func (fw *FakeWindow) ClearMessages() {
+	fw.messages = nil
+}
+
+func (fw *FakeWindow) SendMessage(msg uint32, wParam, lParam uintptr) uintptr {
+	fw.messages = append(fw.messages, Message{msg, wParam, lParam})
+
+	switch msg {
+	case wmLButtonDown:
+		fw.buttonDown = true
+
+	case wmLButtonUp:
+		if fw.buttonDown && fw.OnClick != nil {
+			fw.OnClick()
+		}
+		fw.buttonDown = false
+
+	case wmChar:
+		if fw.editable {
+			switch r := rune(wParam); {
+			case utf16.IsSurrogate(r) && fw.surrogate == 0:
+				fw.surrogate = r
+
+			case utf16.IsSurrogate(r):
+				fw.text += string(utf16.DecodeRune(fw.surrogate, r))
+				fw.surrogate = 0
+
+			case r == '\b':
+				if runes := []rune(fw.text); len(runes) > 0 {
+					fw.text = string(runes[:len(runes)-1])
+				}
+
+			case r >= ' ':
+				fw.text += string(r)
+			}
+		}
+	}
+
+	if fw.OnMessage != nil {
+		return fw.OnMessage(Message{msg, wParam, lParam})
+	}
+
+	return 0
+}
+
+// SetImage sets the image Screenshot returns. Without one, Screenshot
+// returns a white image of the client size.
+func (fw *FakeWindow) SetImage(img image.Image) {
+	fw.image = img
+}
+
+func (fw *FakeWindow) Screenshot() (image.Image, error) {
+	if fw.image != nil {
+		return fw.image, nil
+	}
+
+	img := image.NewRGBA(image.Rectangle{Max: fw.clientSize})
+	for y := 0; y < fw.clientSize.Y; y++ {
+		for x := 0; x < fw.clientSize.X; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	return img, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"image"
+	"unicode/utf16"
+)
+
+// The messages we send. This file has to build on any platform, so we can't
+// use the win package.
+const (
+	wmKeyDown     = 0x0100
+	wmKeyUp       = 0x0101
+	wmChar        = 0x0102
+	wmMouseMove   = 0x0200
+	wmLButtonDown = 0x0201
+	wmLButtonUp   = 0x0202
+	wmRButtonDown = 0x0204
+	wmRButtonUp   = 0x0205
+
+	mkLButton = 0x0001
+	mkRButton = 0x0002
+	mkShift   = 0x0004
+	mkControl = 0x0008
+
+	vkReturn  = 0x0D
+	vkShift   = 0x10
+	vkControl = 0x11
+	vkMenu    = 0x12
+)
+
+// Key is a virtual key code. Its values are the same as the ones of walk.Key,
+// so you can convert them, e.g. walktest.Key(walk.KeyDelete).
+type Key uint16
+
+// Modifiers is a combination of modifier keys. Its values are the same as the
+// ones of walk.Modifiers.
+type Modifiers byte
+
+const (
+	ModShift Modifiers = 1 << iota
+	ModControl
+	ModAlt
+)
+
+// MouseButton identifies a mouse button.
+type MouseButton int
+
+const (
+	LeftButton MouseButton = iota
+	RightButton
+)
+
+// Message is a window message, as sent to a window procedure.
+type Message struct {
+	Msg    uint32
+	WParam uintptr
+	LParam uintptr
+}
+
+// ClickMessages returns the messages that simulate clicking button at pos,
+// which is relative to the client area of the window.
+func ClickMessages(button MouseButton, pos image.Point, mods Modifiers) []Message {
+	down, up, state := uint32(wmLButtonDown), uint32(wmLButtonUp), uintptr(mkLButton)
+	if button == RightButton {
+		down, up, state = wmRButtonDown, wmRButtonUp, mkRButton
+	}
+
+	var modState uintptr
+	if mods&ModShift != 0 {
+		modState |= mkShift
+	}
+	if mods&ModControl != 0 {
+		modState |= mkControl
+	}
+
+	lParam := uintptr(uint16(pos.X)) | uintptr(uint16(pos.Y))<<16
+
+	return []Message{
+		{wmMouseMove, modState, lParam},
+		{down, modState | state, lParam},
+		{up, modState, lParam},
+	}
+}
+
+// KeyMessages returns the messages that simulate pressing and releasing key
+// while holding mods.
+//
+// Note that walk.ModifiersDown and friends ask the system for the state of
+// the modifier keys, which these messages don't change.
+func KeyMessages(key Key, mods Modifiers) []Message {
+	var modKeys []Key
+	if mods&ModControl != 0 {
+		modKeys = append(modKeys, vkControl)
+	}
+	if mods&ModShift != 0 {
+		modKeys = append(modKeys, vkShift)
+	}
+	if mods&ModAlt != 0 {
+		modKeys = append(modKeys, vkMenu)
+	}
+
+	var msgs []Message
+
+	for _, mk := range modKeys {
+		msgs = append(msgs, keyDown(mk))
+	}
+
+	msgs = append(msgs, keyDown(key))
+	if key == vkReturn && mods == 0 {
+		msgs = append(msgs, Message{wmChar, '\r', 1})
+	}
+	msgs = append(msgs, keyUp(key))
+
+	for i := len(modKeys) - 1; i >= 0; i-- {
+		msgs = append(msgs, keyUp(modKeys[i]))
+	}
+
+	return msgs
+}
+
+// TextMessages returns the messages that simulate typing text. Newlines are
+// typed as the return key.
+func TextMessages(text string) []Message {
+	var msgs []Message
+
+	for _, r := range text {
+		switch r {
+		case '\r':
+			// Typed as part of "\n".
+
+		case '\n':
+			msgs = append(msgs, KeyMessages(vkReturn, 0)...)
+
+		default:
+			for _, u := range utf16.Encode([]rune{r}) {
+				msgs = append(msgs, Message{wmChar, uintptr(u), 1})
+			}
+		}
+	}
+
+	return msgs
+}
+
+func keyDown(key Key) Message {
+	return Message{wmKeyDown, uintptr(key), 1}
+}
+
+func keyUp(key Key) Message {
+	// Previous key state and transition state bits set.
+	return Message{wmKeyUp, uintptr(key), 1 | 1<<30 | 1<<31}
+}
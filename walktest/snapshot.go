@@ -0,0 +1,190 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// TB is the part of testing.TB we need. We don't import the testing package
+// of the standard library, so it doesn't register its flags in applications
+// using this package.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Diff describes the differences between two images.
+type Diff struct {
+	// Pixels is the number of pixels that differ by more than the tolerance.
+	Pixels int
+
+	// Bounds is the smallest rectangle containing all differing pixels.
+	Bounds image.Rectangle
+
+	// Image shows differing pixels in red, over a faded version of the
+	// expected image. It is nil if the images have different sizes.
+	Image *image.RGBA
+
+	// SizeMismatch is true if the images have different sizes, in which case
+	// all other fields are zero.
+	SizeMismatch bool
+}
+
+// Equal returns if no differences were found.
+func (d *Diff) Equal() bool {
+	return !d.SizeMismatch && d.Pixels == 0
+}
+
+func (d *Diff) String() string {
+	if d.SizeMismatch {
+		return "images have different sizes"
+	}
+
+	return fmt.Sprintf("%d pixels differ within %v", d.Pixels, d.Bounds)
+}
+
+// CompareImages compares want and got pixel by pixel. Color channels that
+// differ by no more than tolerance, on a scale from 0 to 255, are considered
+// equal, which allows for small differences in anti-aliasing.
+func CompareImages(want, got image.Image, tolerance uint8) *Diff {
+	wb, gb := want.Bounds(), got.Bounds()
+	if wb.Dx() != gb.Dx() || wb.Dy() != gb.Dy() {
+		return &Diff{SizeMismatch: true}
+	}
+
+	diff := &Diff{Image: image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))}
+
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			wc := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+			gc := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+
+			if channelsDiffer(wc, gc, tolerance) {
+				diff.Pixels++
+				diff.Bounds = diff.Bounds.Union(image.Rect(x, y, x+1, y+1))
+				diff.Image.Set(x, y, color.NRGBA{255, 0, 0, 255})
+			} else {
+				// Faded, so the differences stand out.
+				gray := color.GrayModel.Convert(wc).(color.Gray)
+				v := 192 + gray.Y/4
+				diff.Image.Set(x, y, color.NRGBA{v, v, v, 255})
+			}
+		}
+	}
+
+	return diff
+}
+
+func channelsDiffer(a, b color.NRGBA, tolerance uint8) bool {
+	differ := func(x, y uint8) bool {
+		if x > y {
+			return x-y > tolerance
+		}
+
+		return y-x > tolerance
+	}
+
+	return differ(a.R, b.R) || differ(a.G, b.G) || differ(a.B, b.B) || differ(a.A, b.A)
+}
+
+// Snapshots compares screenshots with golden PNG files.
+type Snapshots struct {
+	// Dir is the directory containing the golden files, usually "testdata".
+	Dir string
+
+	// Tolerance is passed to CompareImages.
+	Tolerance uint8
+
+	// Update makes Match write screenshots as new golden files instead of
+	// comparing them. Bind it to a flag of your tests.
+	Update bool
+}
+
+// Match compares img with the golden file for name and reports a test error
+// if they differ. In that case, the image and the diff are written next to
+// the golden file, with ".got.png" and ".diff.png" suffixes. Missing golden
+// files are created.
+func (s *Snapshots) Match(t TB, name string, img image.Image) {
+	t.Helper()
+
+	path := filepath.Join(s.Dir, name+".png")
+
+	want, err := readPNG(path)
+	if os.IsNotExist(err) || s.Update {
+		if err := writePNG(path, img); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+		return
+	}
+
+	diff := CompareImages(want, img, s.Tolerance)
+	if diff.Equal() {
+		return
+	}
+
+	if err := writePNG(filepath.Join(s.Dir, name+".got.png"), img); err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+	}
+	if diff.Image != nil {
+		if err := writePNG(filepath.Join(s.Dir, name+".diff.png"), diff.Image); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+	}
+
+	t.Errorf("snapshot %s: %v", name, diff)
+}
+
+// MatchWindow takes a screenshot of w by means of d and compares it, see
+// Match.
+func (s *Snapshots) MatchWindow(t TB, d *Driver, name string, w Window) {
+	t.Helper()
+
+	img, err := d.Screenshot(w)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+		return
+	}
+
+	s.Match(t, name, img)
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestImage(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestCompareImages(t *testing.T) {
+	want := newTestImage(4, 3, color.NRGBA{100, 100, 100, 255})
+
+	got := newTestImage(4, 3, color.NRGBA{102, 100, 98, 255})
+	if diff := CompareImages(want, got, 2); !diff.Equal() {
+		t.Errorf("within tolerance: %v", diff)
+	}
+
+	got.Set(1, 2, color.NRGBA{103, 100, 100, 255})
+	got.Set(3, 0, color.NRGBA{0, 0, 0, 255})
+	diff := CompareImages(want, got, 2)
+	if diff.Equal() || diff.Pixels != 2 {
+		t.Errorf("got %d differing pixels, want 2", diff.Pixels)
+	}
+	if wantBounds := image.Rect(1, 0, 4, 3); diff.Bounds != wantBounds {
+		t.Errorf("got bounds %v, want %v", diff.Bounds, wantBounds)
+	}
+	if c := diff.Image.RGBAAt(1, 2); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("got diff color %v, want red", c)
+	}
+
+	// Bounds with different origins are compared relative to their origins.
+	shifted := newTestImage(4, 3, color.NRGBA{100, 100, 100, 255}).SubImage(image.Rect(0, 0, 4, 3))
+	if diff := CompareImages(want, shifted, 0); !diff.Equal() {
+		t.Errorf("same image: %v", diff)
+	}
+
+	if diff := CompareImages(want, newTestImage(3, 3, color.White), 255); !diff.SizeMismatch || diff.Equal() {
+		t.Errorf("different sizes: got %v", diff)
+	}
+}
+
+type recordingTB struct {
+	errors []string
+	fatals []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) Fatalf(format string, args ...interface{}) {
+	tb.fatals = append(tb.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestSnapshotsMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "walktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &Snapshots{Dir: dir}
+
+	okPB := NewFakeWindow("Button", "okPB")
+	d := NewDriver(NewFakeBackend(okPB))
+
+	// A missing golden file is created.
+	tb := new(recordingTB)
+	s.MatchWindow(tb, d, "okPB", okPB)
+	if len(tb.errors) != 0 || len(tb.fatals) != 0 {
+		t.Fatalf("creating golden file: %v %v", tb.errors, tb.fatals)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "okPB.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	tb = new(recordingTB)
+	s.MatchWindow(tb, d, "okPB", okPB)
+	if len(tb.errors) != 0 || len(tb.fatals) != 0 {
+		t.Errorf("matching golden file: %v %v", tb.errors, tb.fatals)
+	}
+
+	d.Do(func() { okPB.SetImage(newTestImage(100, 30, color.Black)) })
+
+	tb = new(recordingTB)
+	s.MatchWindow(tb, d, "okPB", okPB)
+	if len(tb.errors) != 1 {
+		t.Errorf("got errors %v, want one", tb.errors)
+	}
+	for _, suffix := range []string{".got.png", ".diff.png"} {
+		if _, err := os.Stat(filepath.Join(dir, "okPB"+suffix)); err != nil {
+			t.Error(err)
+		}
+	}
+}
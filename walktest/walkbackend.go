@@ -0,0 +1,133 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walktest
+
+import (
+	"errors"
+	"image"
+	"reflect"
+
+	"github.com/lxn/walk"
+)
+
+type walkBackend struct {
+	forms []walk.Form
+}
+
+// NewBackend returns a Backend for the walk application that owns forms,
+// which become the top level windows of the Backend. At least one form is
+// required. Forms that have been disposed of are ignored.
+func NewBackend(forms ...walk.Form) Backend {
+	return &walkBackend{forms: forms}
+}
+
+func (wb *walkBackend) Roots() []Window {
+	var roots []Window
+
+	for _, form := range wb.forms {
+		if !form.IsDisposed() {
+			roots = append(roots, walkWindow{form})
+		}
+	}
+
+	return roots
+}
+
+// errNoForms is returned by Synchronize after all forms have been disposed of.
+var errNoForms = errors.New("walktest: all forms have been disposed of")
+
+// Synchronize uses the first form that has not been disposed of, so the
+// message loop wakes up to call f.
+func (wb *walkBackend) Synchronize(f func()) error {
+	for _, form := range wb.forms {
+		if !form.IsDisposed() {
+			form.Synchronize(f)
+			return nil
+		}
+	}
+
+	return errNoForms
+}
+
+// walkWindow is a value type, so two of them are equal if they wrap the same
+// walk.Window, which Path relies on.
+type walkWindow struct {
+	window walk.Window
+}
+
+// WindowOf returns the Window for a walk.Window, e.g. to pass it to the
+// methods of a Driver.
+func WindowOf(window walk.Window) Window {
+	return walkWindow{window}
+}
+
+func (ww walkWindow) Name() string {
+	return ww.window.Name()
+}
+
+func (ww walkWindow) Class() string {
+	t := reflect.TypeOf(ww.window)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+func (ww walkWindow) Children() []Window {
+	container, ok := ww.window.(walk.Container)
+	if !ok || container.Children() == nil {
+		return nil
+	}
+
+	list := container.Children()
+	children := make([]Window, list.Len())
+	for i := range children {
+		children[i] = walkWindow{list.At(i)}
+	}
+
+	return children
+}
+
+func (ww walkWindow) Text() string {
+	if t, ok := ww.window.(interface{ Text() string }); ok {
+		return t.Text()
+	}
+
+	return ""
+}
+
+func (ww walkWindow) Visible() bool {
+	return ww.window.Visible()
+}
+
+func (ww walkWindow) Enabled() bool {
+	return ww.window.Enabled()
+}
+
+func (ww walkWindow) ClientSize() image.Point {
+	s := ww.window.ClientBoundsPixels().Size()
+
+	return image.Point{s.Width, s.Height}
+}
+
+func (ww walkWindow) SetFocus() error {
+	return ww.window.SetFocus()
+}
+
+func (ww walkWindow) SendMessage(msg uint32, wParam, lParam uintptr) uintptr {
+	return ww.window.SendMessage(msg, wParam, lParam)
+}
+
+func (ww walkWindow) Screenshot() (image.Image, error) {
+	img, err := ww.window.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package walktest drives walk applications from end-to-end tests.
+//
+// A Driver finds windows by name or path, simulates mouse clicks, key presses
+// and text entry by sending messages to their window procedures and waits for
+// the UI thread to catch up. It talks to the application by means of a
+// Backend. NewBackend returns one for walk windows, while FakeBackend allows
+// to test code built on top of this package without creating any windows.
+//
+// Unlike the rest of walk, only the walk Backend is restricted to Windows.
+package walktest
+
+import (
+	"errors"
+	"image"
+	"strings"
+)
+
+var (
+	ErrNotFound  = errors.New("no matching window found")
+	ErrAmbiguous = errors.New("more than one matching window found")
+)
+
+// Window is the view a Driver has on a window of the application under test.
+//
+// Except for Name, Class and Children, which are expected to be stable, the
+// methods are only called on the UI thread, i.e. from functions passed to the
+// Synchronize method of the Backend.
+type Window interface {
+	// Name returns the name of the window, as set with walk.Window.SetName.
+	Name() string
+
+	// Class returns the type name of the window, e.g. "PushButton".
+	Class() string
+
+	// Children returns the child windows.
+	Children() []Window
+
+	// Text returns the text of the window, or "" if it has none.
+	Text() string
+
+	// Visible returns if the window is visible.
+	Visible() bool
+
+	// Enabled returns if the window is enabled.
+	Enabled() bool
+
+	// ClientSize returns the size of the client area in native pixels.
+	ClientSize() image.Point
+
+	// SetFocus sets the keyboard focus to the window.
+	SetFocus() error
+
+	// SendMessage sends a message to the window procedure.
+	SendMessage(msg uint32, wParam, lParam uintptr) uintptr
+
+	// Screenshot returns an image of the window.
+	Screenshot() (image.Image, error)
+}
+
+// Backend connects a Driver to the application under test.
+type Backend interface {
+	// Roots returns the top level windows.
+	Roots() []Window
+
+	// Synchronize arranges for f to be called on the UI thread. It does not
+	// wait for f to return. It returns an error if there is no UI thread
+	// left to call f, e.g. because all windows have been disposed of.
+	Synchronize(f func()) error
+}
+
+// Path returns the path of w, i.e. the names of w and its ancestors, starting
+// with a top level window and separated by slashes, like
+// "mainWindow/settingsGroupBox/okPB". It returns "" if w is not a descendant
+// of one of roots.
+func Path(roots []Window, w Window) string {
+	for _, root := range roots {
+		if names := pathNames(root, w); names != nil {
+			return strings.Join(names, "/")
+		}
+	}
+
+	return ""
+}
+
+func pathNames(parent, w Window) []string {
+	if parent == w {
+		return []string{w.Name()}
+	}
+
+	for _, child := range parent.Children() {
+		if names := pathNames(child, w); names != nil {
+			return append([]string{parent.Name()}, names...)
+		}
+	}
+
+	return nil
+}
+
+// FindAll returns all windows below roots, in depth first order, for which
+// match returns true.
+func FindAll(roots []Window, match func(w Window) bool) []Window {
+	var found []Window
+
+	var visit func(w Window)
+	visit = func(w Window) {
+		if match(w) {
+			found = append(found, w)
+		}
+
+		for _, child := range w.Children() {
+			visit(child)
+		}
+	}
+
+	for _, root := range roots {
+		visit(root)
+	}
+
+	return found
+}
+
+// FindByName returns the window named name. It returns ErrNotFound or
+// ErrAmbiguous unless there is exactly one.
+func FindByName(roots []Window, name string) (Window, error) {
+	return findOne(FindAll(roots, func(w Window) bool {
+		return w.Name() == name
+	}))
+}
+
+// FindByPath returns the window identified by path, see Path. A path
+// segment of "*" matches any name and a leading "**/" matches any number of
+// ancestors, so "**/okPB" is equivalent to FindByName(roots, "okPB"). It
+// returns ErrNotFound or ErrAmbiguous unless there is exactly one match.
+func FindByPath(roots []Window, path string) (Window, error) {
+	anyAncestors := strings.HasPrefix(path, "**/")
+	if anyAncestors {
+		path = path[len("**/"):]
+	}
+
+	segments := strings.Split(path, "/")
+
+	var found []Window
+
+	var visit func(w Window, segments []string)
+	visit = func(w Window, segments []string) {
+		if segments[0] == "*" || segments[0] == w.Name() {
+			if len(segments) == 1 {
+				found = append(found, w)
+			} else {
+				for _, child := range w.Children() {
+					visit(child, segments[1:])
+				}
+			}
+		}
+	}
+
+	if anyAncestors {
+		for _, w := range FindAll(roots, func(Window) bool { return true }) {
+			visit(w, segments)
+		}
+	} else {
+		for _, root := range roots {
+			visit(root, segments)
+		}
+	}
+
+	return findOne(found)
+}
+
+func findOne(found []Window) (Window, error) {
+	switch len(found) {
+	case 0:
+		return nil, ErrNotFound
+
+	case 1:
+		return found[0], nil
+	}
+
+	return nil, ErrAmbiguous
+}
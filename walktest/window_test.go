@@ -0,0 +1,81 @@
+// Copyright 2019 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walktest
+
+import (
+	"testing"
+)
+
+func newTestRoots() (roots []Window, okPB, cancelPB *FakeWindow) {
+	okPB = NewFakeWindow("Button", "okPB")
+	cancelPB = NewFakeWindow("Button", "cancelPB")
+
+	mainWindow := NewFakeWindow("MainWindow", "mainWindow",
+		NewFakeWindow("GroupBox", "settingsGroupBox",
+			NewFakeWindow("LineEdit", "nameLE"),
+			okPB),
+		NewFakeWindow("Composite", "buttons",
+			NewFakeWindow("LineEdit", "nameLE"),
+			cancelPB))
+
+	return []Window{mainWindow}, okPB, cancelPB
+}
+
+func TestPath(t *testing.T) {
+	roots, okPB, _ := newTestRoots()
+
+	if got, want := Path(roots, okPB), "mainWindow/settingsGroupBox/okPB"; got != want {
+		t.Errorf("Path: got %q, want %q", got, want)
+	}
+
+	if got := Path(roots, NewFakeWindow("Button", "okPB")); got != "" {
+		t.Errorf("Path of unrelated window: got %q, want \"\"", got)
+	}
+}
+
+func TestFindByPath(t *testing.T) {
+	roots, okPB, cancelPB := newTestRoots()
+
+	tests := []struct {
+		path string
+		want Window
+		err  error
+	}{
+		{"mainWindow/settingsGroupBox/okPB", okPB, nil},
+		{"mainWindow/*/cancelPB", cancelPB, nil},
+		{"**/okPB", okPB, nil},
+		{"**/buttons/cancelPB", cancelPB, nil},
+		{"mainWindow/okPB", nil, ErrNotFound},
+		{"**/missingPB", nil, ErrNotFound},
+		{"mainWindow/*/nameLE", nil, ErrAmbiguous},
+		{"**/nameLE", nil, ErrAmbiguous},
+	}
+
+	for _, test := range tests {
+		got, err := FindByPath(roots, test.path)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.path, err, test.err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	roots, okPB, _ := newTestRoots()
+
+	if got, err := FindByName(roots, "okPB"); err != nil || got != okPB {
+		t.Errorf("okPB: got %v, %v, want %v", got, err, okPB)
+	}
+
+	if _, err := FindByName(roots, "nameLE"); err != ErrAmbiguous {
+		t.Errorf("nameLE: got error %v, want %v", err, ErrAmbiguous)
+	}
+
+	if _, err := FindByName(roots, "missing"); err != ErrNotFound {
+		t.Errorf("missing: got error %v, want %v", err, ErrNotFound)
+	}
+}
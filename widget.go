@@ -99,6 +99,7 @@ type WidgetBase struct {
 	graphicsEffects             *WidgetGraphicsEffectList
 	alignment                   Alignment2D
 	alwaysConsumeSpace          bool
+	geometryAnimation           *PropertyAnimation
 }
 
 // InitWidget initializes a Widget.
@@ -166,6 +167,10 @@ func (wb *WidgetBase) Dispose() {
 		tt.RemoveTool(wb.window.(Widget))
 	}
 
+	if wb.geometryAnimation != nil {
+		wb.geometryAnimation.Stop()
+	}
+
 	wb.WindowBase.Dispose()
 }
 
@@ -232,7 +237,7 @@ func (wb *WidgetBase) Font() *Font {
 		return wb.parent.Font()
 	}
 
-	return defaultFont
+	return themeDefaultFont()
 }
 
 func (wb *WidgetBase) applyFont(font *Font) {
@@ -441,6 +441,10 @@ type WindowBase struct {
 	visible                   bool
 	enabled                   bool
 	acc                       *Accessibility
+	themeAppName              string
+	darkThemed                bool
+	styleClasses              []string
+	appliedStyle              *appliedStyle
 }
 
 var (
@@ -655,7 +659,7 @@ func initWindowWithCfg(cfg *windowCfg) error {
 		}
 	}
 
-	SetWindowFont(wb.hWnd, defaultFont)
+	SetWindowFont(wb.hWnd, themeDefaultFont())
 
 	if form, ok := cfg.Window.(Form); ok {
 		if fb := form.AsFormBase(); fb != nil {
@@ -671,6 +675,10 @@ func initWindowWithCfg(cfg *windowCfg) error {
 				return err
 			}
 		}
+
+		if theme := App().Theme(); theme.Dark() {
+			wb.applyWindowTheme(theme)
+		}
 	}
 
 	wb.enabledProperty = NewBoolProperty(
@@ -881,6 +889,10 @@ func (wb *WindowBase) Dispose() {
 		wb.background.detachWindow(wb)
 	}
 
+	if as := wb.appliedStyle; as != nil && as.brush != nil {
+		as.brush.Dispose()
+	}
+
 	hWnd := wb.hWnd
 	if hWnd != 0 {
 		wb.disposingPublisher.Publish()
@@ -1122,7 +1134,7 @@ func (wb *WindowBase) Font() *Font {
 		return wb.font
 	}
 
-	return defaultFont
+	return themeDefaultFont()
 }
 
 // SetFont sets the *Font of the *WindowBase.
@@ -1979,6 +1991,13 @@ func (wb *WindowBase) CreateCanvas() (*Canvas, error) {
 }
 
 func (wb *WindowBase) setTheme(appName string) error {
+	wb.themeAppName = appName
+
+	if theme := App().Theme(); theme.Dark() {
+		wb.applyWindowTheme(theme)
+		return nil
+	}
+
 	if hr := win.SetWindowTheme(wb.hWnd, syscall.StringToUTF16Ptr(appName), nil); win.FAILED(hr) {
 		return errorFromHRESULT("SetWindowTheme", hr)
 	}
@@ -2280,9 +2299,11 @@ func (wb *WindowBase) handleWMCTLCOLOR(wParam, lParam uintptr) uintptr {
 	} else if tc, ok := wnd.(TextColorer); ok {
 		color := tc.TextColor()
 		if color == 0 {
-			color = Color(win.GetSysColor(win.COLOR_WINDOWTEXT))
+			color = App().Theme().Color(ThemeColorWindowText)
 		}
 		win.SetTextColor(hdc, win.COLORREF(color))
+	} else if theme := App().Theme(); theme.ProvidesColor(ThemeColorWindowText) {
+		win.SetTextColor(hdc, win.COLORREF(theme.Color(ThemeColorWindowText)))
 	}
 
 	if bg, wnd := wnd.AsWindowBase().backgroundEffective(); bg != nil {
@@ -96,6 +96,7 @@ type WindowGroup struct {
 	activeForm      Form
 	oleInit         bool
 	accPropServices *win.IAccPropServices
+	animations      *animationDriver
 
 	syncMutex           sync.Mutex
 	syncFuncs           []func()                   // Functions queued to run on the group's thread
@@ -301,8 +302,23 @@ func (g *WindowGroup) ignore(delta int) {
 	}
 }
 
+// animationDriver returns the driver of the animations running on the
+// group's thread, creating it if necessary.
+func (g *WindowGroup) animationDriver() *animationDriver {
+	if g.animations == nil {
+		g.animations = new(animationDriver)
+	}
+
+	return g.animations
+}
+
 // dispose releases any resources consumed by the group.
 func (g *WindowGroup) dispose() {
+	if g.animations != nil {
+		g.animations.stopAll()
+		g.animations = nil
+	}
+
 	if g.accPropServices != nil {
 		g.accPropServices.Release()
 		g.accPropServices = nil